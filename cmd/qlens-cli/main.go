@@ -0,0 +1,435 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+const usage = `qlens-cli - command-line client for the QLens gateway
+
+Usage:
+  qlens-cli <command> [flags] [prompt]
+
+Commands:
+  complete   Create a chat completion (use --stream for incremental output)
+  embed      Create embeddings for one or more inputs
+  models     List models available through the gateway
+  tokens     Estimate the token count of a prompt
+
+Prompts are taken from the trailing arguments, from --file, or from stdin.
+Run "qlens-cli <command> --help" for command flags.
+`
+
+// clientOptions holds the connection flags shared by every subcommand
+type clientOptions struct {
+	gatewayURL string
+	tenantID   string
+	userID     string
+	apiKey     string
+	timeout    time.Duration
+}
+
+func (o *clientOptions) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.gatewayURL, "gateway", getEnvOrDefault("QLENS_GATEWAY_URL", "http://localhost:8080"), "gateway base URL (env QLENS_GATEWAY_URL)")
+	fs.StringVar(&o.tenantID, "tenant", os.Getenv("QLENS_TENANT_ID"), "tenant ID sent as X-Tenant-ID (env QLENS_TENANT_ID)")
+	fs.StringVar(&o.userID, "user", os.Getenv("QLENS_USER_ID"), "user ID sent as X-User-ID (env QLENS_USER_ID)")
+	fs.StringVar(&o.apiKey, "api-key", os.Getenv("QLENS_API_KEY"), "API key sent as X-API-Key (env QLENS_API_KEY)")
+	fs.DurationVar(&o.timeout, "timeout", 2*time.Minute, "request timeout")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "complete":
+		err = runComplete(ctx, os.Args[2:])
+	case "embed":
+		err = runEmbed(ctx, os.Args[2:])
+	case "models":
+		err = runModels(ctx, os.Args[2:])
+	case "tokens":
+		err = runTokens(os.Args[2:])
+	case "help", "-h", "--help":
+		fmt.Fprint(os.Stdout, usage)
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runComplete(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("complete", flag.ExitOnError)
+	var opts clientOptions
+	opts.register(fs)
+	model := fs.String("model", "", "model to use (gateway default when empty)")
+	system := fs.String("system", "", "optional system prompt")
+	file := fs.String("file", "", "read the prompt from a file instead of arguments/stdin")
+	stream := fs.Bool("stream", false, "stream tokens to the terminal as they arrive")
+	maxTokens := fs.Int("max-tokens", 0, "maximum tokens to generate")
+	temperature := fs.Float64("temperature", -1, "sampling temperature")
+	rawJSON := fs.Bool("json", false, "print the full JSON response")
+	fs.Parse(args)
+
+	prompt, err := readPrompt(fs.Args(), *file)
+	if err != nil {
+		return err
+	}
+
+	req := types.CompletionRequest{
+		Model:    *model,
+		Messages: buildMessages(*system, prompt),
+		Stream:   *stream,
+		TenantID: domain.TenantID(opts.tenantID),
+		UserID:   domain.UserID(opts.userID),
+	}
+	if *maxTokens > 0 {
+		req.MaxTokens = maxTokens
+	}
+	if *temperature >= 0 {
+		req.Temperature = temperature
+	}
+
+	client := newGatewayClient(opts)
+
+	if req.Stream {
+		return client.streamCompletion(ctx, &req, os.Stdout)
+	}
+
+	var resp types.CompletionResponse
+	if err := client.do(ctx, http.MethodPost, "/v1/completions", &req, &resp); err != nil {
+		return err
+	}
+
+	if *rawJSON {
+		return printJSON(resp)
+	}
+
+	for _, choice := range resp.Choices {
+		fmt.Fprintln(os.Stdout, messageText(choice.Message))
+	}
+	fmt.Fprintf(os.Stderr, "\n[%s via %s] prompt=%d completion=%d total=%d tokens\n",
+		resp.Model, resp.Provider, resp.Usage.PromptTokens, resp.Usage.CompletionTokens, resp.Usage.TotalTokens)
+	return nil
+}
+
+func runEmbed(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("embed", flag.ExitOnError)
+	var opts clientOptions
+	opts.register(fs)
+	model := fs.String("model", "", "embedding model to use")
+	file := fs.String("file", "", "read inputs from a file, one per line")
+	fs.Parse(args)
+
+	var inputs []string
+	if fs.NArg() > 0 {
+		inputs = fs.Args()
+	} else {
+		text, err := readPrompt(nil, *file)
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				inputs = append(inputs, line)
+			}
+		}
+	}
+	if len(inputs) == 0 {
+		return fmt.Errorf("no input provided")
+	}
+
+	req := types.EmbeddingRequest{
+		Model:    *model,
+		Input:    inputs,
+		TenantID: domain.TenantID(opts.tenantID),
+		UserID:   domain.UserID(opts.userID),
+	}
+
+	var resp types.EmbeddingResponse
+	if err := newGatewayClient(opts).do(ctx, http.MethodPost, "/v1/embeddings", &req, &resp); err != nil {
+		return err
+	}
+	return printJSON(resp)
+}
+
+func runModels(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("models", flag.ExitOnError)
+	var opts clientOptions
+	opts.register(fs)
+	provider := fs.String("provider", "", "only list models from this provider")
+	capability := fs.String("capability", "", "only list models with this capability")
+	rawJSON := fs.Bool("json", false, "print the full JSON response")
+	fs.Parse(args)
+
+	path := "/v1/models"
+	query := make([]string, 0, 2)
+	if *provider != "" {
+		query = append(query, "provider="+*provider)
+	}
+	if *capability != "" {
+		query = append(query, "capability="+*capability)
+	}
+	if len(query) > 0 {
+		path += "?" + strings.Join(query, "&")
+	}
+
+	var resp types.ModelsResponse
+	if err := newGatewayClient(opts).do(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return err
+	}
+
+	if *rawJSON {
+		return printJSON(resp)
+	}
+
+	for _, model := range resp.Data {
+		fmt.Fprintf(os.Stdout, "%-40s %-14s %-10s ctx=%d\n", model.ID, model.Provider, model.Status, model.ContextLength)
+	}
+	return nil
+}
+
+// runTokens prints an offline estimate; the gateway reports exact usage
+// with every completion
+func runTokens(args []string) error {
+	fs := flag.NewFlagSet("tokens", flag.ExitOnError)
+	file := fs.String("file", "", "read the prompt from a file instead of arguments/stdin")
+	fs.Parse(args)
+
+	prompt, err := readPrompt(fs.Args(), *file)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "characters: %d\nwords: %d\nestimated tokens: %d\n",
+		len([]rune(prompt)), len(strings.Fields(prompt)), domain.EstimateTextTokens(len(prompt)))
+	return nil
+}
+
+// gatewayClient is a minimal HTTP client for the gateway API
+type gatewayClient struct {
+	opts       clientOptions
+	httpClient *http.Client
+}
+
+func newGatewayClient(opts clientOptions) *gatewayClient {
+	return &gatewayClient{
+		opts:       opts,
+		httpClient: &http.Client{Timeout: opts.timeout},
+	}
+}
+
+func (c *gatewayClient) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.opts.gatewayURL, "/")+path, reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.opts.tenantID != "" {
+		req.Header.Set("X-Tenant-ID", c.opts.tenantID)
+	}
+	if c.opts.userID != "" {
+		req.Header.Set("X-User-ID", c.opts.userID)
+	}
+	if c.opts.apiKey != "" {
+		req.Header.Set("X-API-Key", c.opts.apiKey)
+	}
+
+	return req, nil
+}
+
+func (c *gatewayClient) do(ctx context.Context, method, path string, body, out interface{}) error {
+	req, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// streamCompletion writes streamed content to w as it arrives
+func (c *gatewayClient) streamCompletion(ctx context.Context, body *types.CompletionRequest, w io.Writer) error {
+	req, err := c.newRequest(ctx, http.MethodPost, "/v1/completions", body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	// Streams may legitimately outlive the request timeout; rely on ctx instead
+	httpClient := *c.httpClient
+	httpClient.Timeout = 0
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return decodeError(resp)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			fmt.Fprintln(w)
+			return nil
+		}
+
+		var chunk struct {
+			domain.StreamResponse
+			Error *types.StreamError `json:"error,omitempty"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("invalid stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			fmt.Fprintln(w)
+			return fmt.Errorf("%s: %s", chunk.Error.Type, chunk.Error.Message)
+		}
+
+		for _, choice := range chunk.Choices {
+			if choice.Index == 0 {
+				fmt.Fprint(w, messageText(choice.Message))
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("stream interrupted: %w", err)
+	}
+	return fmt.Errorf("stream ended without completion marker")
+}
+
+func decodeError(resp *http.Response) error {
+	var body struct {
+		Error types.StreamError `json:"error"`
+	}
+	data, _ := io.ReadAll(resp.Body)
+	if err := json.Unmarshal(data, &body); err != nil || body.Error.Message == "" {
+		return fmt.Errorf("gateway returned %s: %s", resp.Status, strings.TrimSpace(string(data)))
+	}
+	return fmt.Errorf("gateway returned %s: %s (%s)", resp.Status, body.Error.Message, body.Error.Type)
+}
+
+// readPrompt resolves the prompt from arguments, a file, or stdin in that order
+func readPrompt(args []string, file string) (string, error) {
+	if len(args) > 0 {
+		return strings.Join(args, " "), nil
+	}
+
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		return string(data), nil
+	}
+
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return "", fmt.Errorf("no prompt provided: pass it as an argument, with --file, or on stdin")
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read stdin: %w", err)
+	}
+	return string(data), nil
+}
+
+func buildMessages(system, prompt string) []domain.Message {
+	messages := make([]domain.Message, 0, 2)
+	if system != "" {
+		messages = append(messages, textMessage(domain.MessageRoleSystem, system))
+	}
+	return append(messages, textMessage(domain.MessageRoleUser, prompt))
+}
+
+func textMessage(role domain.MessageRole, text string) domain.Message {
+	return domain.Message{
+		Role:    role,
+		Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: text}},
+	}
+}
+
+func messageText(msg domain.Message) string {
+	var sb strings.Builder
+	for _, part := range msg.Content {
+		if part.Type == domain.ContentTypeText {
+			sb.WriteString(part.Text)
+		}
+	}
+	return sb.String()
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -19,39 +19,95 @@ import (
 	"github.com/quantum-suite/platform/pkg/qlens-types"
 )
 
+// providerHealthInterval is how often the provider health reported at
+// /metrics is refreshed
+const providerHealthInterval = 30 * time.Second
+
+// Client is the part of the QLens client the server calls
+type Client interface {
+	CreateCompletion(ctx context.Context, req *types.CompletionRequest) (*types.CompletionResponse, error)
+	CreateCompletionStream(ctx context.Context, req *types.CompletionRequest) (<-chan types.StreamResponse, error)
+	CreateEmbeddings(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error)
+	ListModels(ctx context.Context, opts *types.ListModelsOptions) (*types.ModelsResponse, error)
+	HealthCheck(ctx context.Context) (*types.HealthResponse, error)
+}
+
 // Server represents the QLens HTTP server
 type Server struct {
-	client *qlens.QLens
-	router *gin.Engine
-	port   string
+	client    Client
+	templates *qlens.TemplateStore
+	metrics   *serverMetrics
+	router    *gin.Engine
+	port      string
+	stop      chan struct{}
 }
 
 // NewServer creates a new QLens HTTP server
-func NewServer(client *qlens.QLens, port string) *Server {
+func NewServer(client Client, port string) *Server {
 	if port == "" {
 		port = "8105"
 	}
 
 	server := &Server{
-		client: client,
-		port:   port,
+		client:    client,
+		templates: qlens.NewTemplateStore(),
+		metrics:   newServerMetrics(),
+		port:      port,
+		stop:      make(chan struct{}),
 	}
 
 	// Setup Gin router
 	gin.SetMode(gin.ReleaseMode)
 	server.router = gin.New()
-	server.router.Use(gin.Logger(), gin.Recovery())
+	server.router.Use(gin.Logger(), gin.Recovery(), server.metrics.middleware())
 	server.setupRoutes()
 
 	return server
 }
 
-// Start starts the HTTP server
+// Start starts the HTTP server and the provider health refresh
 func (s *Server) Start() error {
+	go s.watchProviderHealth(providerHealthInterval)
+
 	log.Printf("Starting QLens server on port %s", s.port)
 	return s.router.Run(":" + s.port)
 }
 
+// Stop ends the provider health refresh
+func (s *Server) Stop() {
+	close(s.stop)
+}
+
+// watchProviderHealth refreshes the provider health gauges every interval,
+// so scrapes never wait on the providers
+func (s *Server) watchProviderHealth(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.refreshProviderHealth()
+		select {
+		case <-ticker.C:
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// refreshProviderHealth checks the providers and records their health. A
+// failed check leaves the last health recorded.
+func (s *Server) refreshProviderHealth() {
+	ctx, cancel := context.WithTimeout(context.Background(), providerHealthInterval)
+	defer cancel()
+
+	health, err := s.client.HealthCheck(ctx)
+	if err != nil {
+		log.Printf("Failed to refresh provider health for metrics: %v", err)
+		return
+	}
+	s.metrics.recordProviderHealth(health)
+}
+
 // setupRoutes configures all HTTP routes
 func (s *Server) setupRoutes() {
 	// Health endpoint
@@ -66,12 +122,21 @@ func (s *Server) setupRoutes() {
 	// Embeddings endpoints
 	s.router.POST("/embeddings", s.handleCreateEmbeddings)
 
+	// OpenAI-compatible endpoints
+	s.router.GET("/v1/models", s.handleOpenAIListModels)
+	s.router.POST("/v1/chat/completions", s.handleOpenAIChatCompletion)
+	s.router.POST("/v1/embeddings", s.handleOpenAIEmbeddings)
+
 	// Metrics endpoint
-	s.router.GET("/metrics", s.handleMetrics)
+	s.router.GET("/metrics", gin.WrapH(s.metrics.handler()))
 
-	// Template endpoints (placeholder for future implementation)
+	// Template endpoints
 	s.router.GET("/templates", s.handleListTemplates)
 	s.router.POST("/templates", s.handleCreateTemplate)
+	s.router.GET("/templates/:id", s.handleGetTemplate)
+	s.router.PUT("/templates/:id", s.handleUpdateTemplate)
+	s.router.GET("/templates/:id/versions", s.handleListTemplateVersions)
+	s.router.POST("/templates/:id/rollback/:version", s.handleRollbackTemplate)
 	s.router.POST("/templates/:id/render", s.handleRenderTemplate)
 
 	// Usage endpoints
@@ -93,6 +158,7 @@ func (s *Server) handleHealth(c *gin.Context) {
 		})
 		return
 	}
+	s.metrics.recordProviderHealth(health)
 	
 	status := http.StatusOK
 	if health.Status != "healthy" {
@@ -156,16 +222,13 @@ func (s *Server) handleCreateCompletion(c *gin.Context) {
 		s.handleError(c, err)
 		return
 	}
+	s.metrics.recordTokens(response.Provider, response.Model, response.Usage.TotalTokens, response.CacheHit)
 	
 	c.JSON(http.StatusOK, response)
 }
 
 // Handle streaming completions
 func (s *Server) handleStreamingCompletion(ctx context.Context, req *types.CompletionRequest, c *gin.Context) {
-	// Set headers for Server-Sent Events
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-	c.Header("Connection", "keep-alive")
 	c.Header("Access-Control-Allow-Origin", "*")
 	
 	streamChan, err := s.client.CreateCompletionStream(ctx, req)
@@ -174,6 +237,11 @@ func (s *Server) handleStreamingCompletion(ctx context.Context, req *types.Compl
 		return
 	}
 	
+	// Set headers for Server-Sent Events once the stream is open
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	
 	// Stream responses
 	for {
 		select {
@@ -232,41 +300,166 @@ func (s *Server) handleCreateEmbeddings(c *gin.Context) {
 		s.handleError(c, err)
 		return
 	}
+	s.metrics.recordTokens(response.Provider, response.Model, response.Usage.TotalTokens, false)
 	
 	c.JSON(http.StatusOK, response)
 }
 
-// Metrics handler
-func (s *Server) handleMetrics(c *gin.Context) {
-	// For now, return a simple health status
-	// In a full implementation, this would return Prometheus metrics
-	c.Header("Content-Type", "text/plain")
-	c.String(http.StatusOK, "# QLens Metrics\n# Implementation pending\n")
+// Template handlers
+
+// RenderTemplateRequest is the body accepted by the render endpoint. A zero
+// version renders the template's current version.
+type RenderTemplateRequest struct {
+	Variables map[string]interface{} `json:"variables"`
+	Version   int64                  `json:"version,omitempty"`
 }
 
-// Template handlers (placeholders)
 func (s *Server) handleListTemplates(c *gin.Context) {
+	tenantID, ok := s.requireTenant(c)
+	if !ok {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"object": "list",
-		"data":   []interface{}{},
+		"data":   s.templates.List(tenantID),
 	})
 }
 
 func (s *Server) handleCreateTemplate(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error": gin.H{
-			"type":    "not_implemented",
-			"message": "Template creation not yet implemented",
-		},
+	tenantID, ok := s.requireTenant(c)
+	if !ok {
+		return
+	}
+
+	var input qlens.TemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		s.handleError(c, &types.QLensError{
+			Type:    types.ErrorTypeInvalidRequest,
+			Message: "Invalid request format",
+			Details: map[string]interface{}{"error": err.Error()},
+		})
+		return
+	}
+
+	template, err := s.templates.Create(tenantID, domain.UserID(c.GetHeader("X-User-ID")), input)
+	if err != nil {
+		s.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+func (s *Server) handleGetTemplate(c *gin.Context) {
+	tenantID, ok := s.requireTenant(c)
+	if !ok {
+		return
+	}
+
+	template, err := s.templates.Get(tenantID, c.Param("id"))
+	if err != nil {
+		s.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func (s *Server) handleUpdateTemplate(c *gin.Context) {
+	tenantID, ok := s.requireTenant(c)
+	if !ok {
+		return
+	}
+
+	var input qlens.TemplateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		s.handleError(c, &types.QLensError{
+			Type:    types.ErrorTypeInvalidRequest,
+			Message: "Invalid request format",
+			Details: map[string]interface{}{"error": err.Error()},
+		})
+		return
+	}
+
+	template, err := s.templates.Update(tenantID, c.Param("id"), domain.UserID(c.GetHeader("X-User-ID")), input)
+	if err != nil {
+		s.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func (s *Server) handleListTemplateVersions(c *gin.Context) {
+	tenantID, ok := s.requireTenant(c)
+	if !ok {
+		return
+	}
+
+	versions, err := s.templates.Versions(tenantID, c.Param("id"))
+	if err != nil {
+		s.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"object": "list",
+		"data":   versions,
 	})
 }
 
+func (s *Server) handleRollbackTemplate(c *gin.Context) {
+	tenantID, ok := s.requireTenant(c)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.ParseInt(c.Param("version"), 10, 64)
+	if err != nil {
+		s.handleError(c, &types.QLensError{
+			Type:    types.ErrorTypeInvalidRequest,
+			Message: "Version must be an integer",
+			Details: map[string]interface{}{"version": c.Param("version")},
+		})
+		return
+	}
+
+	template, err := s.templates.Rollback(tenantID, c.Param("id"), version, domain.UserID(c.GetHeader("X-User-ID")))
+	if err != nil {
+		s.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
 func (s *Server) handleRenderTemplate(c *gin.Context) {
-	c.JSON(http.StatusNotImplemented, gin.H{
-		"error": gin.H{
-			"type":    "not_implemented",
-			"message": "Template rendering not yet implemented",
-		},
+	tenantID, ok := s.requireTenant(c)
+	if !ok {
+		return
+	}
+
+	var req RenderTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.handleError(c, &types.QLensError{
+			Type:    types.ErrorTypeInvalidRequest,
+			Message: "Invalid request format",
+			Details: map[string]interface{}{"error": err.Error()},
+		})
+		return
+	}
+
+	rendered, version, err := s.templates.Render(tenantID, c.Param("id"), req.Version, req.Variables)
+	if err != nil {
+		s.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"template_id": c.Param("id"),
+		"version":     version,
+		"content":     rendered,
 	})
 }
 
@@ -339,6 +532,20 @@ func (s *Server) enrichEmbeddingRequestContext(req *types.EmbeddingRequest, c *g
 	}
 }
 
+// requireTenant reads the tenant from the X-Tenant-ID header; templates are
+// always tenant-scoped so the header is mandatory
+func (s *Server) requireTenant(c *gin.Context) (domain.TenantID, bool) {
+	tenantID := c.GetHeader("X-Tenant-ID")
+	if tenantID == "" {
+		s.handleError(c, &types.QLensError{
+			Type:    types.ErrorTypeInvalidRequest,
+			Message: "X-Tenant-ID header is required",
+		})
+		return "", false
+	}
+	return domain.TenantID(tenantID), true
+}
+
 func (s *Server) handleError(c *gin.Context, err error) {
 	// Convert QLens errors to HTTP responses
 	if qlensErr, ok := err.(*types.QLensError); ok {
@@ -374,6 +581,8 @@ func (s *Server) getHTTPStatusFromError(errorType string) int {
 		return http.StatusUnauthorized
 	case types.ErrorTypeAuthorizationError:
 		return http.StatusForbidden
+	case types.ErrorTypeNotFound:
+		return http.StatusNotFound
 	case types.ErrorTypeRateLimitExceeded:
 		return http.StatusTooManyRequests
 	case types.ErrorTypeProviderError:
@@ -427,6 +636,7 @@ func main() {
 	<-quit
 	
 	log.Println("Shutting down QLens server...")
+	server.Stop()
 	
 	// Graceful shutdown
 	// TODO: Implement proper HTTP server shutdown if using http.Server directly
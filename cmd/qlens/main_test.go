@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+// fakeClient answers the server's calls with canned responses and counts
+// them
+type fakeClient struct {
+	mu                sync.Mutex
+	completion        *types.CompletionResponse
+	stream            []types.StreamResponse
+	embeddings        *types.EmbeddingResponse
+	models            *types.ModelsResponse
+	health            *types.HealthResponse
+	err               error
+	requests          []*types.CompletionRequest
+	embeddingRequests []*types.EmbeddingRequest
+	healthCalls       int
+}
+
+func (f *fakeClient) CreateCompletion(ctx context.Context, req *types.CompletionRequest) (*types.CompletionResponse, error) {
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+	return f.completion, f.err
+}
+
+func (f *fakeClient) CreateCompletionStream(ctx context.Context, req *types.CompletionRequest) (<-chan types.StreamResponse, error) {
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	f.mu.Unlock()
+	if f.err != nil {
+		return nil, f.err
+	}
+	ch := make(chan types.StreamResponse, len(f.stream))
+	for _, chunk := range f.stream {
+		ch <- chunk
+	}
+	close(ch)
+	return ch, nil
+}
+
+func (f *fakeClient) CreateEmbeddings(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+	f.mu.Lock()
+	f.embeddingRequests = append(f.embeddingRequests, req)
+	f.mu.Unlock()
+	return f.embeddings, f.err
+}
+
+func (f *fakeClient) ListModels(ctx context.Context, opts *types.ListModelsOptions) (*types.ModelsResponse, error) {
+	return f.models, f.err
+}
+
+func (f *fakeClient) HealthCheck(ctx context.Context) (*types.HealthResponse, error) {
+	f.mu.Lock()
+	f.healthCalls++
+	f.mu.Unlock()
+	return f.health, f.err
+}
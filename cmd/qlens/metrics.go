@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+// serverMetrics holds the Prometheus registry the server exposes at /metrics
+type serverMetrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	providerTokens  *prometheus.CounterVec
+	providerHealth  *prometheus.GaugeVec
+}
+
+func newServerMetrics() *serverMetrics {
+	m := &serverMetrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "qlens_requests_total",
+				Help: "HTTP requests by method, endpoint and status code",
+			},
+			[]string{"method", "endpoint", "status"},
+		),
+		requestDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "qlens_request_duration_seconds",
+				Help:    "HTTP request duration in seconds by method and endpoint",
+				Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+			},
+			[]string{"method", "endpoint"},
+		),
+		providerTokens: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "qlens_provider_tokens_total",
+				Help: "Tokens used by each provider, by provider and model",
+			},
+			[]string{"provider", "model"},
+		),
+		providerHealth: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "qlens_provider_health_status",
+				Help: "Provider health status (1=healthy, 0=unhealthy)",
+			},
+			[]string{"provider"},
+		),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.requestDuration,
+		m.providerTokens,
+		m.providerHealth,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// middleware counts and times every request by its route, so requests for
+// different IDs share a series
+func (m *serverMetrics) middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		endpoint := c.FullPath()
+		if endpoint == "" {
+			endpoint = "unmatched"
+		}
+		method := c.Request.Method
+		m.requests.WithLabelValues(method, endpoint, strconv.Itoa(c.Writer.Status())).Inc()
+		m.requestDuration.WithLabelValues(method, endpoint).Observe(time.Since(start).Seconds())
+	}
+}
+
+// recordTokens counts the tokens a provider used; cached answers used none
+func (m *serverMetrics) recordTokens(provider domain.Provider, model string, tokens int, cacheHit bool) {
+	if cacheHit || tokens <= 0 {
+		return
+	}
+	m.providerTokens.WithLabelValues(string(provider), model).Add(float64(tokens))
+}
+
+// recordProviderHealth sets each provider's health from a health check.
+// Only healthy providers count as healthy; degraded ones do not.
+func (m *serverMetrics) recordProviderHealth(health *types.HealthResponse) {
+	for provider, providerHealth := range health.Providers {
+		value := 0.0
+		if providerHealth.Status == domain.ProviderHealthHealthy {
+			value = 1
+		}
+		m.providerHealth.WithLabelValues(string(provider)).Set(value)
+	}
+}
+
+// handler serves the registry in the Prometheus exposition format
+func (m *serverMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
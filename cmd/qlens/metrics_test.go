@@ -0,0 +1,71 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+func TestServer_ScrapeMetrics(t *testing.T) {
+	client := &fakeClient{
+		completion: &types.CompletionResponse{
+			ID:       "resp-1",
+			Model:    "gpt-4o",
+			Provider: domain.ProviderOpenAI,
+			Usage:    domain.Usage{PromptTokens: 80, CompletionTokens: 40, TotalTokens: 120},
+		},
+		health: &types.HealthResponse{
+			Status: "degraded",
+			Providers: map[domain.Provider]types.ProviderHealth{
+				domain.ProviderOpenAI:     {Status: domain.ProviderHealthHealthy},
+				domain.ProviderAWSBedrock: {Status: domain.ProviderHealthUnhealthy},
+			},
+		},
+	}
+	server := NewServer(client, "")
+
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/completions",
+		strings.NewReader(`{"model":"gpt-4o","messages":[{"role":"user","content":[{"type":"text","text":"Hello"}]}]}`)))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Provider health is refreshed in the background, not by the scrape
+	server.refreshProviderHealth()
+	w = httptest.NewRecorder()
+	server.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, client.healthCalls)
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(w.Body)
+	require.NoError(t, err)
+
+	requests := families["qlens_requests_total"]
+	require.NotNil(t, requests)
+	require.Len(t, requests.GetMetric(), 1)
+	labels := map[string]string{}
+	for _, pair := range requests.GetMetric()[0].GetLabel() {
+		labels[pair.GetName()] = pair.GetValue()
+	}
+	assert.Equal(t, map[string]string{"method": "POST", "endpoint": "/completions", "status": "200"}, labels)
+
+	tokens := families["qlens_provider_tokens_total"]
+	require.NotNil(t, tokens)
+	assert.Equal(t, 120.0, tokens.GetMetric()[0].GetCounter().GetValue())
+
+	health := map[string]float64{}
+	for _, metric := range families["qlens_provider_health_status"].GetMetric() {
+		health[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+	}
+	assert.Equal(t, map[string]float64{"openai": 1, "aws-bedrock": 0}, health)
+
+	assert.Contains(t, families, "go_goroutines")
+}
@@ -0,0 +1,598 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+// OpenAI-compatible endpoints, so OpenAI client libraries can use QLens by
+// changing only their base URL. QLens-specific inputs (tenant, user,
+// priority, caching) come from the same headers as the native endpoints;
+// QLens-specific outputs are returned as response headers, which OpenAI
+// clients ignore.
+
+// Response headers carrying the fields the OpenAI schema has no place for
+const (
+	openAIRequestIDHeader         = "X-Request-ID"
+	openAIProviderHeader          = "X-QLens-Provider"
+	openAIProviderRequestIDHeader = "X-Provider-Request-ID"
+	openAICostHeader              = "X-QLens-Cost-USD"
+	openAICacheHitHeader          = "X-QLens-Cache-Hit"
+)
+
+// OpenAIChatCompletionRequest is the OpenAI chat completion request. Provider
+// is a QLens extension for pinning the provider.
+type OpenAIChatCompletionRequest struct {
+	Model               string          `json:"model" binding:"required"`
+	Messages            []OpenAIMessage `json:"messages" binding:"required"`
+	MaxTokens           *int            `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int            `json:"max_completion_tokens,omitempty"`
+	Temperature         *float64        `json:"temperature,omitempty"`
+	TopP                *float64        `json:"top_p,omitempty"`
+	N                   *int            `json:"n,omitempty"`
+	Stop                OpenAIStrings   `json:"stop,omitempty"`
+	PresencePenalty     *float64        `json:"presence_penalty,omitempty"`
+	FrequencyPenalty    *float64        `json:"frequency_penalty,omitempty"`
+	Stream              bool            `json:"stream,omitempty"`
+	User                string          `json:"user,omitempty"`
+	Tools               json.RawMessage `json:"tools,omitempty"`
+	Provider            domain.Provider `json:"provider,omitempty"`
+}
+
+// OpenAIMessage is a chat message. Content is either a string or an array of
+// content parts; assistant messages that only call tools have none.
+type OpenAIMessage struct {
+	Role       string           `json:"role"`
+	Content    *string          `json:"-"`
+	Parts      []OpenAIPart     `json:"-"`
+	Name       string           `json:"name,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+	ToolCalls  []OpenAIToolCall `json:"tool_calls,omitempty"`
+}
+
+// OpenAIPart is one part of multi-modal message content
+type OpenAIPart struct {
+	Type     string           `json:"type"`
+	Text     string           `json:"text,omitempty"`
+	ImageURL *domain.ImageURL `json:"image_url,omitempty"`
+}
+
+// OpenAIToolCall is a tool call made by the assistant. Index is only sent in
+// stream deltas, where it identifies the call a fragment belongs to.
+type OpenAIToolCall struct {
+	Index    *int                `json:"index,omitempty"`
+	ID       string              `json:"id,omitempty"`
+	Type     string              `json:"type,omitempty"`
+	Function domain.FunctionCall `json:"function"`
+}
+
+// UnmarshalJSON accepts content as a string, an array of parts or null
+func (m *OpenAIMessage) UnmarshalJSON(data []byte) error {
+	type message OpenAIMessage
+	var raw struct {
+		message
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*m = OpenAIMessage(raw.message)
+
+	content := bytes.TrimSpace(raw.Content)
+	switch {
+	case len(content) == 0 || bytes.Equal(content, []byte("null")):
+		return nil
+	case content[0] == '[':
+		return json.Unmarshal(content, &m.Parts)
+	default:
+		return json.Unmarshal(content, &m.Content)
+	}
+}
+
+// MarshalJSON writes content as a string, or null when there is none
+func (m OpenAIMessage) MarshalJSON() ([]byte, error) {
+	type message OpenAIMessage
+	return json.Marshal(struct {
+		message
+		Content *string `json:"content"`
+	}{message(m), m.Content})
+}
+
+// OpenAIStrings accepts a single string or an array of strings, as stop
+// sequences and embedding inputs are sent
+type OpenAIStrings []string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *OpenAIStrings) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = OpenAIStrings{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// OpenAIChatCompletionResponse is the OpenAI chat completion response
+type OpenAIChatCompletionResponse struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []OpenAIChoice `json:"choices"`
+	Usage   OpenAIUsage    `json:"usage"`
+}
+
+// OpenAIChoice is one completion choice
+type OpenAIChoice struct {
+	Index        int           `json:"index"`
+	Message      OpenAIMessage `json:"message"`
+	FinishReason *string       `json:"finish_reason"`
+}
+
+// OpenAIUsage reports token usage in the OpenAI schema
+type OpenAIUsage struct {
+	PromptTokens            int                  `json:"prompt_tokens"`
+	CompletionTokens        int                  `json:"completion_tokens"`
+	TotalTokens             int                  `json:"total_tokens"`
+	CompletionTokensDetails *OpenAITokensDetails `json:"completion_tokens_details,omitempty"`
+}
+
+// OpenAITokensDetails breaks down completion tokens
+type OpenAITokensDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
+}
+
+// OpenAIChatCompletionChunk is one server-sent event of a streamed completion
+type OpenAIChatCompletionChunk struct {
+	ID      string              `json:"id"`
+	Object  string              `json:"object"`
+	Created int64               `json:"created"`
+	Model   string              `json:"model"`
+	Choices []OpenAIChunkChoice `json:"choices"`
+}
+
+// OpenAIChunkChoice is a choice's delta within a chunk
+type OpenAIChunkChoice struct {
+	Index        int         `json:"index"`
+	Delta        OpenAIDelta `json:"delta"`
+	FinishReason *string     `json:"finish_reason"`
+}
+
+// OpenAIDelta is the incremental message content of a chunk
+type OpenAIDelta struct {
+	Role      string           `json:"role,omitempty"`
+	Content   *string          `json:"content,omitempty"`
+	Refusal   *string          `json:"refusal,omitempty"`
+	ToolCalls []OpenAIToolCall `json:"tool_calls,omitempty"`
+}
+
+// OpenAIEmbeddingRequest is the OpenAI embeddings request. Input is a string
+// or an array of strings. /v1/embeddings used to serve the native request,
+// so its fields are still accepted as QLens extensions.
+type OpenAIEmbeddingRequest struct {
+	Model          string        `json:"model" binding:"required"`
+	Input          OpenAIStrings `json:"input" binding:"required"`
+	EncodingFormat string        `json:"encoding_format,omitempty"`
+	Dimensions     *int          `json:"dimensions,omitempty"`
+	User           string        `json:"user,omitempty"`
+
+	Provider        domain.Provider                  `json:"provider,omitempty"`
+	Representations []domain.EmbeddingRepresentation `json:"representations,omitempty"`
+	TenantID        domain.TenantID                  `json:"tenant_id,omitempty"`
+	UserID          domain.UserID                    `json:"user_id,omitempty"`
+	RequestID       string                           `json:"request_id,omitempty"`
+	Metadata        map[string]interface{}           `json:"metadata,omitempty"`
+	BatchSize       int                              `json:"batch_size,omitempty"`
+	Priority        domain.Priority                  `json:"priority,omitempty"`
+}
+
+// OpenAIEmbeddingResponse is the OpenAI embeddings response. It also carries
+// the rest of the native response, which OpenAI clients ignore, so callers
+// of the native shape at /v1/embeddings keep working.
+type OpenAIEmbeddingResponse struct {
+	Object string                `json:"object"`
+	Data   []OpenAIEmbedding     `json:"data"`
+	Model  string                `json:"model"`
+	Usage  domain.EmbeddingUsage `json:"usage"`
+
+	Provider          domain.Provider                      `json:"provider,omitempty"`
+	ResponseTime      time.Duration                        `json:"response_time"`
+	RequestID         string                               `json:"request_id,omitempty"`
+	ProviderRequestID string                               `json:"provider_request_id,omitempty"`
+	Representations   []domain.EmbeddingRepresentationData `json:"representations,omitempty"`
+}
+
+// OpenAIEmbedding is one embedding, as floats or, for encoding_format
+// base64, as base64 of little-endian float32s. Cached is a QLens extension.
+type OpenAIEmbedding struct {
+	Object    string      `json:"object"`
+	Embedding interface{} `json:"embedding"`
+	Index     int         `json:"index"`
+	Cached    bool        `json:"cached"`
+}
+
+// OpenAIModel is a model in the OpenAI models list
+type OpenAIModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// handleOpenAIChatCompletion serves POST /v1/chat/completions
+func (s *Server) handleOpenAIChatCompletion(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var openAIReq OpenAIChatCompletionRequest
+	if err := c.ShouldBindJSON(&openAIReq); err != nil {
+		s.handleError(c, &types.QLensError{
+			Type:    types.ErrorTypeInvalidRequest,
+			Message: "Invalid request format",
+			Details: map[string]interface{}{"error": err.Error()},
+		})
+		return
+	}
+
+	req, err := fromOpenAIChatRequest(&openAIReq)
+	if err != nil {
+		s.handleError(c, err)
+		return
+	}
+	s.enrichRequestContext(req, c)
+
+	if req.Stream {
+		s.handleOpenAIChatCompletionStream(ctx, req, c)
+		return
+	}
+
+	response, err := s.client.CreateCompletion(ctx, req)
+	if err != nil {
+		s.handleError(c, err)
+		return
+	}
+	s.metrics.recordTokens(response.Provider, response.Model, response.Usage.TotalTokens, response.CacheHit)
+
+	setOpenAIHeaders(c, response.RequestID, response.Provider, response.ProviderRequestID)
+	c.Header(openAICostHeader, strconv.FormatFloat(response.Usage.CostUSD, 'f', -1, 64))
+	c.Header(openAICacheHitHeader, strconv.FormatBool(response.CacheHit))
+	c.JSON(http.StatusOK, toOpenAIChatResponse(response))
+}
+
+// handleOpenAIChatCompletionStream streams chat completion chunks, ending
+// with the data: [DONE] sentinel OpenAI clients wait for
+func (s *Server) handleOpenAIChatCompletionStream(ctx context.Context, req *types.CompletionRequest, c *gin.Context) {
+	streamChan, err := s.client.CreateCompletionStream(ctx, req)
+	if err != nil {
+		s.handleError(c, err)
+		return
+	}
+
+	setOpenAIHeaders(c, req.RequestID, req.Provider, "")
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	writeEvent := func(event interface{}) {
+		data, _ := json.Marshal(event)
+		c.Writer.Write([]byte(fmt.Sprintf("data: %s\n\n", data)))
+		c.Writer.Flush()
+	}
+
+	for {
+		select {
+		case response, ok := <-streamChan:
+			if !ok {
+				c.Writer.Write([]byte("data: [DONE]\n\n"))
+				c.Writer.Flush()
+				return
+			}
+
+			if response.Error != nil {
+				writeEvent(gin.H{"error": gin.H{
+					"type":    response.Error.Type,
+					"message": response.Error.Message,
+					"code":    response.Error.Code,
+				}})
+				return
+			}
+
+			if len(response.Choices) > 0 {
+				writeEvent(toOpenAIChunk(response, req.Model))
+			}
+
+			if response.Done {
+				c.Writer.Write([]byte("data: [DONE]\n\n"))
+				c.Writer.Flush()
+				return
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// handleOpenAIEmbeddings serves POST /v1/embeddings
+func (s *Server) handleOpenAIEmbeddings(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var openAIReq OpenAIEmbeddingRequest
+	if err := c.ShouldBindJSON(&openAIReq); err != nil {
+		s.handleError(c, &types.QLensError{
+			Type:    types.ErrorTypeInvalidRequest,
+			Message: "Invalid request format",
+			Details: map[string]interface{}{"error": err.Error()},
+		})
+		return
+	}
+	if openAIReq.EncodingFormat != "" && openAIReq.EncodingFormat != "float" && openAIReq.EncodingFormat != "base64" {
+		s.handleError(c, invalidOpenAIRequest("encoding_format must be float or base64", "encoding_format"))
+		return
+	}
+
+	req := &types.EmbeddingRequest{
+		Model:           openAIReq.Model,
+		Provider:        openAIReq.Provider,
+		Input:           openAIReq.Input,
+		Dimensions:      openAIReq.Dimensions,
+		User:            openAIReq.User,
+		Representations: openAIReq.Representations,
+		TenantID:        openAIReq.TenantID,
+		UserID:          openAIReq.UserID,
+		RequestID:       openAIReq.RequestID,
+		Metadata:        openAIReq.Metadata,
+		BatchSize:       openAIReq.BatchSize,
+		Priority:        openAIReq.Priority,
+	}
+	s.enrichEmbeddingRequestContext(req, c)
+
+	response, err := s.client.CreateEmbeddings(ctx, req)
+	if err != nil {
+		s.handleError(c, err)
+		return
+	}
+	s.metrics.recordTokens(response.Provider, response.Model, response.Usage.TotalTokens, false)
+
+	data := make([]OpenAIEmbedding, len(response.Data))
+	for i, embedding := range response.Data {
+		data[i] = OpenAIEmbedding{Object: "embedding", Embedding: embedding.Embedding, Index: embedding.Index, Cached: embedding.Cached}
+		if openAIReq.EncodingFormat == "base64" {
+			data[i].Embedding = domain.EncodeEmbeddingBase64(embedding.Embedding)
+		}
+	}
+
+	setOpenAIHeaders(c, response.RequestID, response.Provider, response.ProviderRequestID)
+	c.Header(openAICostHeader, strconv.FormatFloat(response.Usage.CostUSD, 'f', -1, 64))
+	c.JSON(http.StatusOK, OpenAIEmbeddingResponse{
+		Object:            "list",
+		Data:              data,
+		Model:             response.Model,
+		Usage:             response.Usage,
+		Provider:          response.Provider,
+		ResponseTime:      response.ResponseTime,
+		RequestID:         response.RequestID,
+		ProviderRequestID: response.ProviderRequestID,
+		Representations:   response.Representations,
+	})
+}
+
+// handleOpenAIListModels serves GET /v1/models
+func (s *Server) handleOpenAIListModels(c *gin.Context) {
+	models, err := s.client.ListModels(c.Request.Context(), &types.ListModelsOptions{})
+	if err != nil {
+		s.handleError(c, err)
+		return
+	}
+
+	data := make([]OpenAIModel, len(models.Data))
+	for i, model := range models.Data {
+		data[i] = OpenAIModel{ID: model.ID, Object: "model", OwnedBy: string(model.Provider)}
+	}
+	c.JSON(http.StatusOK, gin.H{"object": "list", "data": data})
+}
+
+// fromOpenAIChatRequest maps an OpenAI request onto a QLens request. Options
+// QLens cannot honour are rejected rather than silently dropped.
+func fromOpenAIChatRequest(openAIReq *OpenAIChatCompletionRequest) (*types.CompletionRequest, error) {
+	if openAIReq.N != nil && *openAIReq.N != 1 {
+		return nil, invalidOpenAIRequest("only n=1 is supported", "n")
+	}
+	if len(openAIReq.Tools) > 0 && !bytes.Equal(bytes.TrimSpace(openAIReq.Tools), []byte("null")) {
+		return nil, invalidOpenAIRequest("tools are not supported by this server", "tools")
+	}
+
+	req := &types.CompletionRequest{
+		Model:            openAIReq.Model,
+		Provider:         openAIReq.Provider,
+		MaxTokens:        openAIReq.MaxTokens,
+		Temperature:      openAIReq.Temperature,
+		TopP:             openAIReq.TopP,
+		Stream:           openAIReq.Stream,
+		Stop:             openAIReq.Stop,
+		PresencePenalty:  openAIReq.PresencePenalty,
+		FrequencyPenalty: openAIReq.FrequencyPenalty,
+		User:             openAIReq.User,
+	}
+	// max_completion_tokens replaces max_tokens in newer clients
+	if openAIReq.MaxCompletionTokens != nil {
+		req.MaxTokens = openAIReq.MaxCompletionTokens
+	}
+
+	for _, msg := range openAIReq.Messages {
+		message := domain.Message{
+			Role:       domain.MessageRole(msg.Role),
+			Name:       msg.Name,
+			ToolCallID: msg.ToolCallID,
+		}
+		if msg.Content != nil {
+			message.Content = []domain.ContentPart{{Type: domain.ContentTypeText, Text: *msg.Content}}
+		}
+		for _, part := range msg.Parts {
+			message.Content = append(message.Content, domain.ContentPart{
+				Type:     domain.ContentType(part.Type),
+				Text:     part.Text,
+				ImageURL: part.ImageURL,
+			})
+		}
+		for _, call := range msg.ToolCalls {
+			message.ToolCalls = append(message.ToolCalls, domain.ToolCall{ID: call.ID, Type: call.Type, Function: call.Function})
+		}
+		req.Messages = append(req.Messages, message)
+	}
+
+	return req, nil
+}
+
+// toOpenAIChatResponse maps a QLens response onto the OpenAI schema
+func toOpenAIChatResponse(response *types.CompletionResponse) OpenAIChatCompletionResponse {
+	choices := make([]OpenAIChoice, len(response.Choices))
+	for i, choice := range response.Choices {
+		message := OpenAIMessage{Role: string(choice.Message.Role)}
+		if text, ok := messageText(choice.Message); ok {
+			message.Content = &text
+		}
+		message.ToolCalls = toOpenAIToolCalls(choice.Message.ToolCalls, false)
+
+		choices[i] = OpenAIChoice{
+			Index:        choice.Index,
+			Message:      message,
+			FinishReason: openAIFinishReason(choice.FinishReason),
+		}
+	}
+
+	usage := OpenAIUsage{
+		PromptTokens:     response.Usage.PromptTokens,
+		CompletionTokens: response.Usage.CompletionTokens,
+		TotalTokens:      response.Usage.TotalTokens,
+	}
+	if response.Usage.ReasoningTokens > 0 {
+		usage.CompletionTokensDetails = &OpenAITokensDetails{ReasoningTokens: response.Usage.ReasoningTokens}
+	}
+
+	return OpenAIChatCompletionResponse{
+		ID:      response.ID,
+		Object:  "chat.completion",
+		Created: response.Created,
+		Model:   response.Model,
+		Choices: choices,
+		Usage:   usage,
+	}
+}
+
+// toOpenAIChunk maps a QLens stream chunk onto the OpenAI schema
+func toOpenAIChunk(response types.StreamResponse, model string) OpenAIChatCompletionChunk {
+	if response.Model != "" {
+		model = response.Model
+	}
+
+	choices := make([]OpenAIChunkChoice, len(response.Choices))
+	for i, choice := range response.Choices {
+		delta := OpenAIDelta{
+			Content:   choice.Delta.Content,
+			Refusal:   choice.Delta.Refusal,
+			ToolCalls: toOpenAIToolCalls(choice.Delta.ToolCalls, true),
+		}
+		if choice.Delta.Role != nil {
+			delta.Role = string(*choice.Delta.Role)
+		}
+
+		var finishReason *string
+		if choice.FinishReason != nil {
+			finishReason = openAIFinishReason(*choice.FinishReason)
+		}
+		choices[i] = OpenAIChunkChoice{Index: choice.Index, Delta: delta, FinishReason: finishReason}
+	}
+
+	return OpenAIChatCompletionChunk{
+		ID:      response.ID,
+		Object:  "chat.completion.chunk",
+		Created: response.Created,
+		Model:   model,
+		Choices: choices,
+	}
+}
+
+// toOpenAIToolCalls maps tool calls, numbering them when they are deltas
+func toOpenAIToolCalls(calls []domain.ToolCall, indexed bool) []OpenAIToolCall {
+	var openAICalls []OpenAIToolCall
+	for i, call := range calls {
+		openAICall := OpenAIToolCall{ID: call.ID, Type: call.Type, Function: call.Function}
+		if openAICall.Type == "" {
+			openAICall.Type = "function"
+		}
+		if indexed {
+			index := i
+			openAICall.Index = &index
+		}
+		openAICalls = append(openAICalls, openAICall)
+	}
+	return openAICalls
+}
+
+// messageText joins a message's text parts, reporting false when it has none
+func messageText(message domain.Message) (string, bool) {
+	var text strings.Builder
+	found := false
+	for _, part := range message.Content {
+		if part.Type == domain.ContentTypeText {
+			text.WriteString(part.Text)
+			found = true
+		}
+	}
+	return text.String(), found
+}
+
+// openAIFinishReason maps finish reasons OpenAI does not define onto the
+// nearest one it does
+func openAIFinishReason(reason domain.FinishReason) *string {
+	if reason == "" {
+		return nil
+	}
+
+	mapped := string(reason)
+	switch reason {
+	case domain.FinishReasonCostLimit:
+		mapped = string(domain.FinishReasonLength)
+	case domain.FinishReasonRefusal:
+		mapped = string(domain.FinishReasonContentFilter)
+	}
+	return &mapped
+}
+
+// setOpenAIHeaders returns the QLens fields the OpenAI schema cannot carry
+func setOpenAIHeaders(c *gin.Context, requestID string, provider domain.Provider, providerRequestID string) {
+	if requestID != "" {
+		c.Header(openAIRequestIDHeader, requestID)
+	}
+	if provider != "" {
+		c.Header(openAIProviderHeader, string(provider))
+	}
+	if providerRequestID != "" {
+		c.Header(openAIProviderRequestIDHeader, providerRequestID)
+	}
+}
+
+// invalidOpenAIRequest rejects a request parameter, naming it the way OpenAI
+// errors do
+func invalidOpenAIRequest(message, param string) *types.QLensError {
+	return &types.QLensError{
+		Type:    types.ErrorTypeInvalidRequest,
+		Message: message,
+		Details: map[string]interface{}{"param": param},
+	}
+}
@@ -0,0 +1,396 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+func serveOpenAI(t *testing.T, client *fakeClient, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	server := NewServer(client, "")
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant-ID", "tenant-a")
+	w := httptest.NewRecorder()
+	server.router.ServeHTTP(w, req)
+	return w
+}
+
+func textMessage(role domain.MessageRole, text string) domain.Message {
+	return domain.Message{Role: role, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: text}}}
+}
+
+func stringPtr(s string) *string { return &s }
+
+func TestOpenAIChatCompletion_Responses(t *testing.T) {
+	tests := []struct {
+		name     string
+		response *types.CompletionResponse
+		want     string
+	}{
+		{
+			name: "text",
+			response: &types.CompletionResponse{
+				ID: "resp-1", Created: 1700000000, Model: "gpt-4o",
+				Choices: []domain.Choice{{Message: textMessage(domain.MessageRoleAssistant, "Hi"), FinishReason: domain.FinishReasonStop}},
+				Usage:   domain.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+			},
+			want: `{"id":"resp-1","object":"chat.completion","created":1700000000,"model":"gpt-4o",
+				"choices":[{"index":0,"message":{"role":"assistant","content":"Hi"},"finish_reason":"stop"}],
+				"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`,
+		},
+		{
+			name: "tool calls without text",
+			response: &types.CompletionResponse{
+				ID: "resp-2", Model: "gpt-4o",
+				Choices: []domain.Choice{{
+					Message: domain.Message{Role: domain.MessageRoleAssistant, ToolCalls: []domain.ToolCall{
+						{ID: "call_1", Function: domain.FunctionCall{Name: "lookup", Arguments: `{"q":"x"}`}},
+					}},
+					FinishReason: domain.FinishReasonToolCalls,
+				}},
+			},
+			want: `{"id":"resp-2","object":"chat.completion","created":0,"model":"gpt-4o",
+				"choices":[{"index":0,"message":{"role":"assistant","content":null,"tool_calls":[
+					{"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{\"q\":\"x\"}"}}]},
+					"finish_reason":"tool_calls"}],
+				"usage":{"prompt_tokens":0,"completion_tokens":0,"total_tokens":0}}`,
+		},
+		{
+			name: "reasoning tokens and a finish reason OpenAI lacks",
+			response: &types.CompletionResponse{
+				ID: "resp-3", Model: "o3",
+				Choices: []domain.Choice{{Message: textMessage(domain.MessageRoleAssistant, "Partial"), FinishReason: domain.FinishReasonCostLimit}},
+				Usage:   domain.Usage{PromptTokens: 10, CompletionTokens: 50, TotalTokens: 60, ReasoningTokens: 40},
+			},
+			want: `{"id":"resp-3","object":"chat.completion","created":0,"model":"o3",
+				"choices":[{"index":0,"message":{"role":"assistant","content":"Partial"},"finish_reason":"length"}],
+				"usage":{"prompt_tokens":10,"completion_tokens":50,"total_tokens":60,"completion_tokens_details":{"reasoning_tokens":40}}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response := *tt.response
+			response.Provider = domain.ProviderOpenAI
+			response.RequestID = "req-1"
+			response.ProviderRequestID = "chatcmpl-upstream"
+			response.Usage.CostUSD = 0.002
+			client := &fakeClient{completion: &response}
+
+			w := serveOpenAI(t, client, http.MethodPost, "/v1/chat/completions",
+				`{"model":"gpt-4o","messages":[{"role":"user","content":"Hello"}]}`)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.JSONEq(t, tt.want, w.Body.String())
+
+			assert.Equal(t, "req-1", w.Header().Get(openAIRequestIDHeader))
+			assert.Equal(t, "openai", w.Header().Get(openAIProviderHeader))
+			assert.Equal(t, "chatcmpl-upstream", w.Header().Get(openAIProviderRequestIDHeader))
+			assert.Equal(t, "0.002", w.Header().Get(openAICostHeader))
+			assert.Equal(t, "false", w.Header().Get(openAICacheHitHeader))
+		})
+	}
+}
+
+func TestOpenAIChatCompletion_MapsRequest(t *testing.T) {
+	client := &fakeClient{completion: &types.CompletionResponse{}}
+
+	w := serveOpenAI(t, client, http.MethodPost, "/v1/chat/completions", `{
+		"model": "gpt-4o",
+		"provider": "openai",
+		"max_tokens": 100,
+		"max_completion_tokens": 200,
+		"stop": "END",
+		"tools": null,
+		"messages": [
+			{"role": "system", "content": "Be brief"},
+			{"role": "user", "content": [{"type": "text", "text": "What is this?"}, {"type": "image_url", "image_url": {"url": "https://example.com/a.png"}}]},
+			{"role": "assistant", "content": null, "tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "lookup", "arguments": "{}"}}]},
+			{"role": "tool", "tool_call_id": "call_1", "content": "a cat"}
+		]
+	}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.Len(t, client.requests, 1)
+
+	req := client.requests[0]
+	assert.Equal(t, domain.ProviderOpenAI, req.Provider)
+	assert.Equal(t, domain.TenantID("tenant-a"), req.TenantID)
+	require.NotNil(t, req.MaxTokens)
+	assert.Equal(t, 200, *req.MaxTokens, "max_completion_tokens replaces max_tokens")
+	assert.Equal(t, []string{"END"}, req.Stop)
+	assert.Equal(t, []domain.Message{
+		textMessage(domain.MessageRoleSystem, "Be brief"),
+		{Role: domain.MessageRoleUser, Content: []domain.ContentPart{
+			{Type: domain.ContentTypeText, Text: "What is this?"},
+			{Type: "image_url", ImageURL: &domain.ImageURL{URL: "https://example.com/a.png"}},
+		}},
+		{Role: domain.MessageRoleAssistant, ToolCalls: []domain.ToolCall{
+			{ID: "call_1", Type: "function", Function: domain.FunctionCall{Name: "lookup", Arguments: "{}"}},
+		}},
+		{Role: "tool", ToolCallID: "call_1", Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "a cat"}}},
+	}, req.Messages)
+}
+
+func TestOpenAIChatCompletion_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		clientErr error
+		status    int
+		errType   string
+		param     string
+	}{
+		{
+			name:    "malformed body",
+			body:    `{"messages":[{"role":"user","content":"Hi"}]}`,
+			status:  http.StatusBadRequest,
+			errType: types.ErrorTypeInvalidRequest,
+		},
+		{
+			name:    "several choices",
+			body:    `{"model":"gpt-4o","n":2,"messages":[{"role":"user","content":"Hi"}]}`,
+			status:  http.StatusBadRequest,
+			errType: types.ErrorTypeInvalidRequest,
+			param:   "n",
+		},
+		{
+			name:    "tools",
+			body:    `{"model":"gpt-4o","tools":[{"type":"function","function":{"name":"lookup"}}],"messages":[{"role":"user","content":"Hi"}]}`,
+			status:  http.StatusBadRequest,
+			errType: types.ErrorTypeInvalidRequest,
+			param:   "tools",
+		},
+		{
+			name:      "rate limited upstream",
+			body:      `{"model":"gpt-4o","messages":[{"role":"user","content":"Hi"}]}`,
+			clientErr: &types.QLensError{Type: types.ErrorTypeRateLimitExceeded, Message: "slow down"},
+			status:    http.StatusTooManyRequests,
+			errType:   types.ErrorTypeRateLimitExceeded,
+		},
+		{
+			name:      "provider failure",
+			body:      `{"model":"gpt-4o","messages":[{"role":"user","content":"Hi"}]}`,
+			clientErr: &types.QLensError{Type: types.ErrorTypeProviderError, Message: "bad gateway"},
+			status:    http.StatusBadGateway,
+			errType:   types.ErrorTypeProviderError,
+		},
+		{
+			name:      "unexpected error",
+			body:      `{"model":"gpt-4o","messages":[{"role":"user","content":"Hi"}]}`,
+			clientErr: assert.AnError,
+			status:    http.StatusInternalServerError,
+			errType:   types.ErrorTypeInternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{err: tt.clientErr}
+
+			w := serveOpenAI(t, client, http.MethodPost, "/v1/chat/completions", tt.body)
+			require.Equal(t, tt.status, w.Code, w.Body.String())
+
+			var body struct {
+				Error struct {
+					Type    string                 `json:"type"`
+					Details map[string]interface{} `json:"details"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.errType, body.Error.Type)
+			if tt.param != "" {
+				assert.Equal(t, tt.param, body.Error.Details["param"])
+			}
+			if tt.clientErr == nil {
+				assert.Empty(t, client.requests, "rejected requests are not sent upstream")
+			}
+		})
+	}
+}
+
+func TestOpenAIChatCompletion_Stream(t *testing.T) {
+	role := domain.MessageRoleAssistant
+	stop := domain.FinishReasonStop
+	toolCalls := domain.FinishReasonToolCalls
+
+	tests := []struct {
+		name   string
+		chunks []types.StreamResponse
+		want   []string
+	}{
+		{
+			name: "content",
+			chunks: []types.StreamResponse{
+				{ID: "resp-1", Created: 1700000000, Choices: []types.StreamChoice{{Delta: types.StreamDelta{Role: &role, Content: stringPtr("Hel")}}}},
+				{ID: "resp-1", Created: 1700000000, Choices: []types.StreamChoice{{Delta: types.StreamDelta{Content: stringPtr("lo")}, FinishReason: &stop}}},
+				{Done: true},
+			},
+			want: []string{
+				`{"id":"resp-1","object":"chat.completion.chunk","created":1700000000,"model":"gpt-4o","choices":[{"index":0,"delta":{"role":"assistant","content":"Hel"},"finish_reason":null}]}`,
+				`{"id":"resp-1","object":"chat.completion.chunk","created":1700000000,"model":"gpt-4o","choices":[{"index":0,"delta":{"content":"lo"},"finish_reason":"stop"}]}`,
+				`[DONE]`,
+			},
+		},
+		{
+			name: "tool call deltas are indexed",
+			chunks: []types.StreamResponse{
+				{ID: "resp-2", Model: "gpt-4o-2024-08-06", Choices: []types.StreamChoice{{Delta: types.StreamDelta{ToolCalls: []domain.ToolCall{
+					{ID: "call_1", Function: domain.FunctionCall{Name: "lookup", Arguments: "{}"}},
+					{ID: "call_2", Type: "function", Function: domain.FunctionCall{Name: "search", Arguments: `{"q":1}`}},
+				}}, FinishReason: &toolCalls}}},
+			},
+			want: []string{
+				`{"id":"resp-2","object":"chat.completion.chunk","created":0,"model":"gpt-4o-2024-08-06","choices":[{"index":0,"delta":{"tool_calls":[
+					{"index":0,"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{}"}},
+					{"index":1,"id":"call_2","type":"function","function":{"name":"search","arguments":"{\"q\":1}"}}]},"finish_reason":"tool_calls"}]}`,
+				`[DONE]`,
+			},
+		},
+		{
+			name: "error ends the stream without done",
+			chunks: []types.StreamResponse{
+				{ID: "resp-3", Choices: []types.StreamChoice{{Delta: types.StreamDelta{Content: stringPtr("Hi")}}}},
+				{Error: &types.StreamError{Type: types.ErrorTypeProviderError, Message: "upstream closed", Code: "stream_interrupted"}},
+				{Done: true},
+			},
+			want: []string{
+				`{"id":"resp-3","object":"chat.completion.chunk","created":0,"model":"gpt-4o","choices":[{"index":0,"delta":{"content":"Hi"},"finish_reason":null}]}`,
+				`{"error":{"type":"provider_error","message":"upstream closed","code":"stream_interrupted"}}`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{stream: tt.chunks}
+
+			w := serveOpenAI(t, client, http.MethodPost, "/v1/chat/completions",
+				`{"model":"gpt-4o","stream":true,"messages":[{"role":"user","content":"Hi"}]}`)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+			var events []string
+			for _, line := range strings.Split(w.Body.String(), "\n") {
+				if data, ok := strings.CutPrefix(line, "data: "); ok {
+					events = append(events, data)
+				}
+			}
+			require.Len(t, events, len(tt.want), w.Body.String())
+			for i, want := range tt.want {
+				if want == "[DONE]" {
+					assert.Equal(t, want, events[i])
+					continue
+				}
+				assert.JSONEq(t, want, events[i])
+			}
+		})
+	}
+}
+
+func TestOpenAIEmbeddings(t *testing.T) {
+	response := &types.EmbeddingResponse{
+		Object:            "list",
+		Model:             "text-embedding-3-small",
+		Provider:          domain.ProviderOpenAI,
+		Data:              []domain.Embedding{{Object: "embedding", Embedding: []float64{0.5, -1}, Index: 0, Cached: true}},
+		Usage:             domain.EmbeddingUsage{PromptTokens: 3, TotalTokens: 3, CostUSD: 0.0001, CachedInputs: 1},
+		RequestID:         "req-1",
+		ProviderRequestID: "emb-upstream",
+	}
+
+	tests := []struct {
+		name  string
+		body  string
+		input []string
+		check func(t *testing.T, body map[string]interface{}, req *types.EmbeddingRequest)
+	}{
+		{
+			name:  "single input as floats",
+			body:  `{"model":"text-embedding-3-small","input":"Hello"}`,
+			input: []string{"Hello"},
+			check: func(t *testing.T, body map[string]interface{}, req *types.EmbeddingRequest) {
+				data := body["data"].([]interface{})[0].(map[string]interface{})
+				assert.Equal(t, []interface{}{0.5, -1.0}, data["embedding"])
+				assert.Equal(t, map[string]interface{}{"prompt_tokens": 3.0, "total_tokens": 3.0, "cost_usd": 0.0001, "cached_inputs": 1.0}, body["usage"])
+			},
+		},
+		{
+			name:  "base64",
+			body:  `{"model":"text-embedding-3-small","input":["Hello","World"],"encoding_format":"base64"}`,
+			input: []string{"Hello", "World"},
+			check: func(t *testing.T, body map[string]interface{}, req *types.EmbeddingRequest) {
+				data := body["data"].([]interface{})[0].(map[string]interface{})
+				assert.Equal(t, domain.EncodeEmbeddingBase64([]float64{0.5, -1}), data["embedding"])
+			},
+		},
+		{
+			name:  "native request fields",
+			body:  `{"model":"text-embedding-3-small","input":["Hello"],"tenant_id":"ignored","user_id":"user-1","priority":"high","representations":[{"dimensions":1}]}`,
+			input: []string{"Hello"},
+			check: func(t *testing.T, body map[string]interface{}, req *types.EmbeddingRequest) {
+				assert.Equal(t, domain.TenantID("tenant-a"), req.TenantID, "the header takes precedence")
+				assert.Equal(t, domain.UserID("user-1"), req.UserID)
+				assert.Equal(t, domain.Priority("high"), req.Priority)
+				assert.Equal(t, []domain.EmbeddingRepresentation{{Dimensions: 1}}, req.Representations)
+
+				// Callers of the native shape still find its fields
+				assert.Equal(t, "openai", body["provider"])
+				assert.Equal(t, "req-1", body["request_id"])
+				assert.Equal(t, "emb-upstream", body["provider_request_id"])
+				data := body["data"].([]interface{})[0].(map[string]interface{})
+				assert.Equal(t, true, data["cached"])
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeClient{embeddings: response}
+
+			w := serveOpenAI(t, client, http.MethodPost, "/v1/embeddings", tt.body)
+			require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+			require.Len(t, client.embeddingRequests, 1)
+			assert.Equal(t, tt.input, client.embeddingRequests[0].Input)
+			assert.Equal(t, "0.0001", w.Header().Get(openAICostHeader))
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, "list", body["object"])
+			assert.Equal(t, "text-embedding-3-small", body["model"])
+			tt.check(t, body, client.embeddingRequests[0])
+		})
+	}
+}
+
+func TestOpenAIEmbeddings_RejectsUnknownEncoding(t *testing.T) {
+	client := &fakeClient{}
+
+	w := serveOpenAI(t, client, http.MethodPost, "/v1/embeddings",
+		`{"model":"text-embedding-3-small","input":"Hello","encoding_format":"int8"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"param":"encoding_format"`)
+	assert.Empty(t, client.embeddingRequests)
+}
+
+func TestOpenAIListModels(t *testing.T) {
+	client := &fakeClient{models: &types.ModelsResponse{Data: []types.Model{
+		{ID: "gpt-4o", Provider: domain.ProviderOpenAI},
+		{ID: "anthropic.claude-3-sonnet", Provider: domain.ProviderAWSBedrock},
+	}}}
+
+	w := serveOpenAI(t, client, http.MethodGet, "/v1/models", "")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"object":"list","data":[
+		{"id":"gpt-4o","object":"model","created":0,"owned_by":"openai"},
+		{"id":"anthropic.claude-3-sonnet","object":"model","created":0,"owned_by":"aws-bedrock"}]}`, w.Body.String())
+}
@@ -0,0 +1,25 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ChoiceID names a response's choice. It depends only on the response ID and
+// the choice's index, so every chunk of a stream carries the same ID for the
+// same choice however the provider interleaves them.
+func ChoiceID(responseID string, index int) string {
+	if responseID == "" {
+		return fmt.Sprintf("choice-%d", index)
+	}
+	return fmt.Sprintf("%s-%d", responseID, index)
+}
+
+// OrderChoices sorts choices by index, as some providers return them out of
+// order with n > 1, and gives each its stable ID
+func OrderChoices(responseID string, choices []Choice) {
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].Index < choices[j].Index })
+	for i := range choices {
+		choices[i].ID = ChoiceID(responseID, choices[i].Index)
+	}
+}
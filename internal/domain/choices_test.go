@@ -0,0 +1,20 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrderChoices(t *testing.T) {
+	choices := []Choice{textChoice(2, "c"), textChoice(0, "a"), textChoice(1, "b")}
+
+	OrderChoices("chatcmpl-1", choices)
+
+	for i, choice := range choices {
+		assert.Equal(t, i, choice.Index)
+		assert.Equal(t, ChoiceID("chatcmpl-1", i), choice.ID)
+	}
+	assert.Equal(t, "chatcmpl-1-2", choices[2].ID)
+	assert.Equal(t, "choice-1", ChoiceID("", 1))
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "strconv"
+
+// ContextUtilizationHeader warns the client that the prompt filled most of
+// the model's context window, leaving little room for the response
+const ContextUtilizationHeader = "X-Context-Utilization-Warning"
+
+// ContextUtilizationWarning is the ContextUtilizationHeader value for a
+// prompt filling the given fraction of the context window
+func ContextUtilizationWarning(utilization float64) string {
+	return strconv.FormatFloat(utilization, 'f', 2, 64)
+}
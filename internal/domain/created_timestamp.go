@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// Reasons a provider's Created timestamp is replaced
+const (
+	CreatedMissing = "missing"
+	CreatedSkewed  = "skewed"
+)
+
+// NormalizeCreated checks a provider's Created epoch seconds against now. A
+// missing timestamp, or one further than maxSkew from now, is replaced with
+// now and the reason is returned; otherwise created is returned unchanged
+// with an empty reason. A maxSkew of 0 accepts any positive timestamp.
+func NormalizeCreated(created int64, now time.Time, maxSkew time.Duration) (int64, string) {
+	if created <= 0 {
+		return now.Unix(), CreatedMissing
+	}
+	if maxSkew > 0 {
+		// Compared in seconds, since absurd values such as milliseconds
+		// would overflow a Duration
+		skew, limit := created-now.Unix(), int64(maxSkew/time.Second)
+		if skew > limit || skew < -limit {
+			return now.Unix(), CreatedSkewed
+		}
+	}
+	return created, ""
+}
@@ -0,0 +1,35 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeCreated(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	tests := []struct {
+		name     string
+		created  int64
+		maxSkew  time.Duration
+		expected int64
+		reason   string
+	}{
+		{"within skew", now.Unix() - 60, 5 * time.Minute, now.Unix() - 60, ""},
+		{"missing", 0, 5 * time.Minute, now.Unix(), CreatedMissing},
+		{"negative", -1, 0, now.Unix(), CreatedMissing},
+		{"in the future", now.Unix() + 3600, 5 * time.Minute, now.Unix(), CreatedSkewed},
+		{"in the past", now.Unix() - 3600, 5 * time.Minute, now.Unix(), CreatedSkewed},
+		{"milliseconds", now.UnixMilli(), 5 * time.Minute, now.Unix(), CreatedSkewed},
+		{"skew check disabled", now.Unix() - 3600, 0, now.Unix() - 3600, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created, reason := NormalizeCreated(tt.created, now, tt.maxSkew)
+			assert.Equal(t, tt.expected, created)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
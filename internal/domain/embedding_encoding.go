@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Embedding encoding formats a request may ask for with encoding_format
+const (
+	EmbeddingEncodingFloat  = "float"
+	EmbeddingEncodingBase64 = "base64"
+)
+
+// EncodeEmbeddingBase64 encodes an embedding as OpenAI does for
+// encoding_format base64: little-endian float32s
+func EncodeEmbeddingBase64(embedding []float64) string {
+	buf := make([]byte, 4*len(embedding))
+	for i, value := range embedding {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(float32(value)))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
+
+// DecodeEmbeddingBase64 decodes an embedding encoded with
+// EncodeEmbeddingBase64
+func DecodeEmbeddingBase64(encoded string) ([]float64, error) {
+	buf, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64 embedding: %w", err)
+	}
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("invalid base64 embedding: %d bytes is not a whole number of float32s", len(buf))
+	}
+
+	embedding := make([]float64, len(buf)/4)
+	for i := range embedding {
+		embedding[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:])))
+	}
+	return embedding, nil
+}
+
+// SetEncoding sets the form the embedding is returned in. Base64 keeps the
+// float values so the embedding can still be inspected, but only the
+// encoded form is written to JSON.
+func (e *Embedding) SetEncoding(format string) {
+	if format != EmbeddingEncodingBase64 {
+		e.Base64 = ""
+		return
+	}
+	if e.Base64 == "" {
+		e.Base64 = EncodeEmbeddingBase64(e.Embedding)
+	}
+}
+
+// embeddingJSON is an embedding as written to JSON, where the vector is an
+// array of floats or a base64 string
+type embeddingJSON struct {
+	Object    string          `json:"object"`
+	Embedding json.RawMessage `json:"embedding"`
+	Index     int             `json:"index"`
+	Cached    bool            `json:"cached"`
+}
+
+// MarshalJSON writes the vector as base64 when the embedding was encoded
+// with SetEncoding, and as floats otherwise
+func (e Embedding) MarshalJSON() ([]byte, error) {
+	var vector interface{} = e.Embedding
+	if e.Base64 != "" {
+		vector = e.Base64
+	}
+	raw, err := json.Marshal(vector)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(embeddingJSON{Object: e.Object, Embedding: raw, Index: e.Index, Cached: e.Cached})
+}
+
+// UnmarshalJSON accepts the vector as floats or as base64, which is decoded
+// so Embedding is always set
+func (e *Embedding) UnmarshalJSON(data []byte) error {
+	var decoded embeddingJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	*e = Embedding{Object: decoded.Object, Index: decoded.Index, Cached: decoded.Cached}
+
+	if len(decoded.Embedding) == 0 || string(decoded.Embedding) == "null" {
+		return nil
+	}
+	if decoded.Embedding[0] != '"' {
+		return json.Unmarshal(decoded.Embedding, &e.Embedding)
+	}
+	if err := json.Unmarshal(decoded.Embedding, &e.Base64); err != nil {
+		return err
+	}
+	embedding, err := DecodeEmbeddingBase64(e.Base64)
+	if err != nil {
+		return err
+	}
+	e.Embedding = embedding
+	return nil
+}
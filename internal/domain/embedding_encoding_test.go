@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmbeddingBase64_RoundTrip(t *testing.T) {
+	embedding := []float64{0.5, -1.25, 3}
+
+	decoded, err := DecodeEmbeddingBase64(EncodeEmbeddingBase64(embedding))
+	require.NoError(t, err)
+	assert.Equal(t, embedding, decoded)
+
+	_, err = DecodeEmbeddingBase64("AAA=")
+	assert.Error(t, err)
+	_, err = DecodeEmbeddingBase64("not base64")
+	assert.Error(t, err)
+}
+
+func TestEmbedding_JSON(t *testing.T) {
+	embedding := Embedding{Object: "embedding", Embedding: []float64{0.5, -1.25}, Index: 1}
+
+	data, err := json.Marshal(embedding)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"object": "embedding", "embedding": [0.5, -1.25], "index": 1, "cached": false}`, string(data))
+
+	embedding.SetEncoding(EmbeddingEncodingBase64)
+	data, err = json.Marshal(embedding)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"object": "embedding", "embedding": "AAAAPwAAoL8=", "index": 1, "cached": false}`, string(data))
+
+	// Base64 is decoded on the way in so the floats are always available
+	var decoded Embedding
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, embedding, decoded)
+
+	decoded.SetEncoding(EmbeddingEncodingFloat)
+	assert.Empty(t, decoded.Base64)
+}
@@ -0,0 +1,91 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// MaxEmbeddingRepresentations is the most representations one embedding
+// request may ask for
+const MaxEmbeddingRepresentations = 8
+
+// EmbeddingRepresentation is an extra form an embedding request wants its
+// vectors in. Representations are derived from the vectors the provider
+// returned, so asking for several costs no extra provider calls.
+type EmbeddingRepresentation struct {
+	// EncodingFormat is float or base64, as for the request itself
+	EncodingFormat string `json:"encoding_format,omitempty"`
+
+	// Dimensions keeps only the first dimensions of each vector, which
+	// models trained for shortening such as text-embedding-3 support. Zero
+	// keeps every dimension.
+	Dimensions int `json:"dimensions,omitempty"`
+
+	// Normalize scales each vector, after truncation, to unit length
+	Normalize bool `json:"normalize,omitempty"`
+}
+
+// EmbeddingRepresentationData holds a response's vectors in one requested
+// representation, in the same order as the response's Data
+type EmbeddingRepresentationData struct {
+	EmbeddingRepresentation
+	Data []Embedding `json:"data"`
+}
+
+// Validate reports a representation a request cannot be answered with
+func (r EmbeddingRepresentation) Validate() error {
+	switch r.EncodingFormat {
+	case "", EmbeddingEncodingFloat, EmbeddingEncodingBase64:
+	default:
+		return fmt.Errorf("encoding_format must be float or base64")
+	}
+	if r.Dimensions < 0 {
+		return fmt.Errorf("dimensions must not be negative")
+	}
+	return nil
+}
+
+// DeriveEmbeddingRepresentations computes each representation from the
+// float values of data. It fails when a representation asks for more
+// dimensions than the vectors have.
+func DeriveEmbeddingRepresentations(data []Embedding, representations []EmbeddingRepresentation) ([]EmbeddingRepresentationData, error) {
+	derived := make([]EmbeddingRepresentationData, len(representations))
+	for i, representation := range representations {
+		derived[i] = EmbeddingRepresentationData{EmbeddingRepresentation: representation, Data: make([]Embedding, len(data))}
+		for j, embedding := range data {
+			vector, err := representation.apply(embedding.Embedding)
+			if err != nil {
+				return nil, err
+			}
+			derived[i].Data[j] = Embedding{Object: embedding.Object, Embedding: vector, Index: embedding.Index, Cached: embedding.Cached}
+			derived[i].Data[j].SetEncoding(representation.EncodingFormat)
+		}
+	}
+	return derived, nil
+}
+
+// apply returns a copy of vector truncated and normalized as the
+// representation asks
+func (r EmbeddingRepresentation) apply(vector []float64) ([]float64, error) {
+	size := len(vector)
+	if r.Dimensions > 0 {
+		if r.Dimensions > size {
+			return nil, fmt.Errorf("representation asks for %d dimensions but the embeddings have %d", r.Dimensions, size)
+		}
+		size = r.Dimensions
+	}
+	derived := append([]float64(nil), vector[:size]...)
+
+	if r.Normalize {
+		var norm float64
+		for _, value := range derived {
+			norm += value * value
+		}
+		if norm = math.Sqrt(norm); norm > 0 {
+			for k := range derived {
+				derived[k] /= norm
+			}
+		}
+	}
+	return derived, nil
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeriveEmbeddingRepresentations(t *testing.T) {
+	data := []Embedding{
+		{Object: "embedding", Embedding: []float64{3, 4, 12}, Index: 0},
+		{Object: "embedding", Embedding: []float64{0, 0, 1}, Index: 1, Cached: true},
+	}
+
+	derived, err := DeriveEmbeddingRepresentations(data, []EmbeddingRepresentation{
+		{EncodingFormat: EmbeddingEncodingBase64},
+		{Dimensions: 2, Normalize: true},
+	})
+	require.NoError(t, err)
+	require.Len(t, derived, 2)
+
+	// Full vectors, encoded
+	require.Len(t, derived[0].Data, 2)
+	assert.Equal(t, EncodeEmbeddingBase64([]float64{3, 4, 12}), derived[0].Data[0].Base64)
+	assert.True(t, derived[0].Data[1].Cached)
+
+	// Truncated, then normalized; a zero vector stays zero
+	assert.Equal(t, []float64{0.6, 0.8}, derived[1].Data[0].Embedding)
+	assert.Equal(t, []float64{0, 0}, derived[1].Data[1].Embedding)
+	assert.Empty(t, derived[1].Data[0].Base64)
+	assert.Equal(t, 1, derived[1].Data[1].Index)
+
+	// The base vectors are left untouched
+	assert.Equal(t, []float64{3, 4, 12}, data[0].Embedding)
+	assert.Empty(t, data[0].Base64)
+}
+
+func TestDeriveEmbeddingRepresentations_RejectsExtraDimensions(t *testing.T) {
+	_, err := DeriveEmbeddingRepresentations([]Embedding{{Embedding: []float64{1, 2}}}, []EmbeddingRepresentation{{Dimensions: 3}})
+	assert.Error(t, err)
+}
+
+func TestEmbeddingRepresentation_Validate(t *testing.T) {
+	assert.NoError(t, EmbeddingRepresentation{EncodingFormat: EmbeddingEncodingFloat, Dimensions: 256}.Validate())
+	assert.Error(t, EmbeddingRepresentation{EncodingFormat: "binary"}.Validate())
+	assert.Error(t, EmbeddingRepresentation{Dimensions: -1}.Validate())
+}
@@ -379,6 +379,17 @@ type PromptTemplateUsed struct {
 	RequestID  string   `json:"request_id"`
 }
 
+// PromptTemplateVersionCreated is emitted whenever a template update or
+// rollback appends a new immutable version
+type PromptTemplateVersionCreated struct {
+	BaseDomainEvent
+	TemplateID      string   `json:"template_id"`
+	TenantID        TenantID `json:"tenant_id"`
+	TemplateVersion int64    `json:"template_version"`
+	RolledBackFrom  int64    `json:"rolled_back_from,omitempty"`
+	CreatedBy       UserID   `json:"created_by"`
+}
+
 type ProviderHealthChanged struct {
 	BaseDomainEvent
 	Provider     string  `json:"provider"`
@@ -506,6 +517,7 @@ var EventRegistry = map[string]func() DomainEvent{
 	"EmbeddingRequestFailed":           func() DomainEvent { return &EmbeddingRequestFailed{} },
 	"PromptTemplateCreated":            func() DomainEvent { return &PromptTemplateCreated{} },
 	"PromptTemplateUsed":               func() DomainEvent { return &PromptTemplateUsed{} },
+	"PromptTemplateVersionCreated":     func() DomainEvent { return &PromptTemplateVersionCreated{} },
 	"ProviderHealthChanged":            func() DomainEvent { return &ProviderHealthChanged{} },
 	"ModelRegistered":                  func() DomainEvent { return &ModelRegistered{} },
 	"ModelStatusChanged":               func() DomainEvent { return &ModelStatusChanged{} },
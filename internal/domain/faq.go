@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"math"
+	"time"
+)
+
+// FAQEntry is a canonical question an operator registered for a tenant,
+// with the answer returned to prompts asking the same thing
+type FAQEntry struct {
+	ID       string   `json:"id"`
+	TenantID TenantID `json:"tenant_id"`
+	Question string   `json:"question"`
+	Answer   string   `json:"answer"`
+
+	// EmbeddingModel produced Embedding; prompts are only compared with
+	// entries embedded by the same model
+	EmbeddingModel string    `json:"embedding_model"`
+	Embedding      []float64 `json:"embedding,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateFAQEntryRequest registers a canonical question and its answer
+type CreateFAQEntryRequest struct {
+	Question string `json:"question"`
+	Answer   string `json:"answer"`
+	// DataResidency keeps embedding the question within the tenant's
+	// region; the gateway sets the tenant's own
+	DataResidency string `json:"data_residency,omitempty"`
+}
+
+// FAQEntryList is a tenant's FAQ entries, without their embeddings
+type FAQEntryList struct {
+	Data []FAQEntry `json:"data"`
+}
+
+// MetadataKeyFAQMatch holds a FAQMatch when a completion was answered from
+// the tenant's FAQ instead of by a provider
+const MetadataKeyFAQMatch = "faq_match"
+
+// FAQMatch identifies the FAQ entry that answered a completion
+type FAQMatch struct {
+	EntryID    string  `json:"entry_id"`
+	Question   string  `json:"question"`
+	Similarity float64 `json:"similarity"`
+}
+
+// CosineSimilarity returns the cosine of the angle between two vectors, or 0
+// when their lengths differ or either is zero
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
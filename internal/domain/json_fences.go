@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// MetadataKeyJSONCleanup records, on a response to a JSON request, whether
+// markdown fences or prose around the JSON were stripped from its content
+const MetadataKeyJSONCleanup = "json_cleanup"
+
+// jsonFencePattern matches a markdown code fence with an optional language
+// tag, capturing its body
+var jsonFencePattern = regexp.MustCompile("(?s)```[A-Za-z0-9_-]*[ \t]*\\r?\\n?(.*?)```")
+
+// StripJSONFences extracts the JSON a model wrapped in a markdown code fence
+// or surrounded with prose, and reports whether it changed the text. Text
+// that is already JSON, or that holds no valid JSON to extract, is returned
+// unchanged.
+func StripJSONFences(text string) (string, bool) {
+	trimmed := strings.TrimSpace(text)
+	if json.Valid([]byte(trimmed)) {
+		return text, false
+	}
+
+	for _, match := range jsonFencePattern.FindAllStringSubmatch(trimmed, -1) {
+		if body := strings.TrimSpace(match[1]); json.Valid([]byte(body)) {
+			return body, true
+		}
+	}
+
+	// A response cut off at max_tokens may open a fence it never closes
+	if strings.HasPrefix(trimmed, "```") {
+		if newline := strings.IndexByte(trimmed, '\n'); newline >= 0 {
+			if body := strings.TrimSpace(trimmed[newline+1:]); json.Valid([]byte(body)) {
+				return body, true
+			}
+		}
+	}
+
+	// Prose around a bare object or array
+	start := strings.IndexAny(trimmed, "{[")
+	if start < 0 {
+		return text, false
+	}
+	closing := "}"
+	if trimmed[start] == '[' {
+		closing = "]"
+	}
+	end := strings.LastIndex(trimmed, closing)
+	if end <= start {
+		return text, false
+	}
+	if body := trimmed[start : end+1]; json.Valid([]byte(body)) {
+		return body, true
+	}
+	return text, false
+}
+
+// StripJSONFences applies StripJSONFences to the text of every choice and
+// records in the metadata whether any content was cleaned up
+func (r *CompletionResponse) StripJSONFences() bool {
+	cleaned := false
+	for i := range r.Choices {
+		content := r.Choices[i].Message.Content
+		for j := range content {
+			if content[j].Type != ContentTypeText {
+				continue
+			}
+			if text, stripped := StripJSONFences(content[j].Text); stripped {
+				content[j].Text = text
+				cleaned = true
+			}
+		}
+	}
+
+	if r.Metadata == nil {
+		r.Metadata = make(map[string]interface{})
+	}
+	r.Metadata[MetadataKeyJSONCleanup] = cleaned
+	return cleaned
+}
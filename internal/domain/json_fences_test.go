@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripJSONFences(t *testing.T) {
+	cases := []struct {
+		name     string
+		text     string
+		want     string
+		stripped bool
+	}{
+		{"plain json", `{"a": 1}`, `{"a": 1}`, false},
+		{"json fence", "```json\n{\"a\": 1}\n```", `{"a": 1}`, true},
+		{"bare fence", "```\n[1, 2]\n```", `[1, 2]`, true},
+		{"fence with prose", "Here is the result:\n\n```json\n{\"a\": 1}\n```\nLet me know!", `{"a": 1}`, true},
+		{"unterminated fence", "```json\n{\"a\": 1}\n", `{"a": 1}`, true},
+		{"prose around object", `Sure! {"a": {"b": 2}} Hope that helps.`, `{"a": {"b": 2}}`, true},
+		{"no json", "I cannot help with that.", "I cannot help with that.", false},
+		{"invalid json in fence", "```json\n{\"a\": }\n```", "```json\n{\"a\": }\n```", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			text, stripped := StripJSONFences(tc.text)
+			assert.Equal(t, tc.want, text)
+			assert.Equal(t, tc.stripped, stripped)
+		})
+	}
+}
+
+func TestCompletionResponse_StripJSONFences(t *testing.T) {
+	response := &CompletionResponse{Choices: []Choice{{
+		Message: Message{Role: MessageRoleAssistant, Content: []ContentPart{{Type: ContentTypeText, Text: "```json\n{\"ok\": true}\n```"}}},
+	}}}
+
+	assert.True(t, response.StripJSONFences())
+	assert.Equal(t, `{"ok": true}`, response.Choices[0].Message.Content[0].Text)
+	assert.Equal(t, true, response.Metadata[MetadataKeyJSONCleanup])
+
+	assert.False(t, response.StripJSONFences())
+	assert.Equal(t, false, response.Metadata[MetadataKeyJSONCleanup])
+}
@@ -0,0 +1,44 @@
+package domain
+
+// KillSwitch stops all traffic to a provider, to a model at every provider,
+// or to a model at one provider. Requests are routed around what it covers,
+// or rejected when nothing else can serve them.
+type KillSwitch struct {
+	Provider Provider `json:"provider,omitempty"`
+	Model    string   `json:"model,omitempty"`
+}
+
+// Covers reports whether the switch stops the model at the provider
+func (k KillSwitch) Covers(provider Provider, model string) bool {
+	return (k.Provider == "" || k.Provider == provider) && (k.Model == "" || k.Model == model)
+}
+
+// String names the switch as configured: "provider", "provider/model", or
+// "*/model" for a model at every provider
+func (k KillSwitch) String() string {
+	switch {
+	case k.Model == "":
+		return string(k.Provider)
+	case k.Provider == "":
+		return "*/" + k.Model
+	default:
+		return string(k.Provider) + "/" + k.Model
+	}
+}
+
+// SetKillSwitchRequest turns a kill switch on or off
+type SetKillSwitchRequest struct {
+	KillSwitch
+	Killed bool `json:"killed"`
+}
+
+// KillSwitchList is the kill switches in effect
+type KillSwitchList struct {
+	// Data are the switches toggled through the admin API, shared by every
+	// router replica
+	Data []KillSwitch `json:"data"`
+
+	// Configured are the switches set in the configuration, which the admin
+	// API cannot turn off
+	Configured []KillSwitch `json:"configured"`
+}
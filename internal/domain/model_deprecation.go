@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// MetadataKeyModelDeprecation holds a ModelDeprecation when the requested
+// model is deprecated
+const MetadataKeyModelDeprecation = "model_deprecation"
+
+// What the router did with a request for a deprecated model
+const (
+	ModelDeprecationWarned   = "warned"
+	ModelDeprecationMigrated = "migrated"
+)
+
+// ModelDeprecation records that a request named a deprecated model, and
+// whether the router served it or migrated it to the model's successor
+type ModelDeprecation struct {
+	Model     string `json:"model"`
+	Action    string `json:"action"`
+	Successor string `json:"successor,omitempty"`
+}
+
+// Warning is the value of the response's Warning header, a miscellaneous
+// persistent warning (299) from an unnamed agent
+func (d *ModelDeprecation) Warning() string {
+	text := fmt.Sprintf("model %s is deprecated", d.Model)
+	switch {
+	case d.Action == ModelDeprecationMigrated:
+		text += fmt.Sprintf("; the request was served by %s", d.Successor)
+	case d.Successor != "":
+		text += fmt.Sprintf("; use %s", d.Successor)
+	}
+	return fmt.Sprintf("299 - %q", text)
+}
+
+// ModelDeprecationFrom returns the deprecation recorded in response
+// metadata, whether set by the router in process or decoded from its JSON
+func ModelDeprecationFrom(metadata map[string]interface{}) (*ModelDeprecation, bool) {
+	switch value := metadata[MetadataKeyModelDeprecation].(type) {
+	case *ModelDeprecation:
+		return value, value != nil
+	case map[string]interface{}:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, false
+		}
+		var deprecation ModelDeprecation
+		if err := json.Unmarshal(data, &deprecation); err != nil || deprecation.Model == "" {
+			return nil, false
+		}
+		return &deprecation, true
+	}
+	return nil, false
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelDeprecationFrom_DecodedMetadata(t *testing.T) {
+	response := &CompletionResponse{Metadata: map[string]interface{}{
+		MetadataKeyModelDeprecation: &ModelDeprecation{Model: "gpt-35-turbo", Action: ModelDeprecationMigrated, Successor: "gpt-4o-mini"},
+	}}
+	data, err := json.Marshal(response)
+	require.NoError(t, err)
+
+	var decoded CompletionResponse
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	deprecation, ok := ModelDeprecationFrom(decoded.Metadata)
+	require.True(t, ok)
+	assert.Equal(t, response.Metadata[MetadataKeyModelDeprecation], deprecation)
+
+	_, ok = ModelDeprecationFrom(map[string]interface{}{MetadataKeyModelDeprecation: "deprecated"})
+	assert.False(t, ok)
+	_, ok = ModelDeprecationFrom(nil)
+	assert.False(t, ok)
+}
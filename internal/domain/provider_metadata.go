@@ -0,0 +1,71 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"unicode/utf8"
+)
+
+// Limits OpenAI places on the metadata of a stored completion
+const (
+	MaxProviderMetadataPairs       = 16
+	MaxProviderMetadataKeyLength   = 64
+	MaxProviderMetadataValueLength = 512
+)
+
+// ProviderMetadata returns the request metadata a provider keeps with a
+// stored completion: the string values, without entries the provider would
+// reject. Only the first MaxProviderMetadataPairs keys in sorted order are
+// kept. It returns nil when nothing is left.
+func ProviderMetadata(requestMetadata map[string]interface{}) map[string]string {
+	keys := make([]string, 0, len(requestMetadata))
+	for key, value := range requestMetadata {
+		if text, ok := value.(string); ok && providerMetadataValid(key, text) {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	sort.Strings(keys)
+	if len(keys) > MaxProviderMetadataPairs {
+		keys = keys[:MaxProviderMetadataPairs]
+	}
+
+	metadata := make(map[string]string, len(keys))
+	for _, key := range keys {
+		metadata[key] = requestMetadata[key].(string)
+	}
+	return metadata
+}
+
+// ValidateProviderMetadata checks the string entries of request metadata
+// against the provider's limits, so clients learn their metadata would be
+// cut instead of finding it missing from the provider's dashboard. Entries
+// with other values are not sent to providers and are not checked.
+func ValidateProviderMetadata(metadata map[string]interface{}) error {
+	count := 0
+	for key, value := range metadata {
+		text, ok := value.(string)
+		if !ok {
+			continue
+		}
+		count++
+		if key == "" || utf8.RuneCountInString(key) > MaxProviderMetadataKeyLength {
+			return fmt.Errorf("metadata key %q must be 1 to %d characters", key, MaxProviderMetadataKeyLength)
+		}
+		if utf8.RuneCountInString(text) > MaxProviderMetadataValueLength {
+			return fmt.Errorf("metadata value of %q exceeds %d characters", key, MaxProviderMetadataValueLength)
+		}
+	}
+	if count > MaxProviderMetadataPairs {
+		return fmt.Errorf("metadata has %d string entries, at most %d are allowed", count, MaxProviderMetadataPairs)
+	}
+	return nil
+}
+
+func providerMetadataValid(key, value string) bool {
+	return key != "" &&
+		utf8.RuneCountInString(key) <= MaxProviderMetadataKeyLength &&
+		utf8.RuneCountInString(value) <= MaxProviderMetadataValueLength
+}
@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderMetadata_KeepsStringsTheProviderAccepts(t *testing.T) {
+	metadata := ProviderMetadata(map[string]interface{}{
+		"experiment":            "baseline",
+		"benchmark":             true,
+		"attempt":               2,
+		strings.Repeat("k", 65): "too long a key",
+		"notes":                 strings.Repeat("x", 513),
+	})
+	assert.Equal(t, map[string]string{"experiment": "baseline"}, metadata)
+
+	assert.Nil(t, ProviderMetadata(map[string]interface{}{"benchmark": true}))
+	assert.Nil(t, ProviderMetadata(nil))
+}
+
+func TestProviderMetadata_KeepsFirstKeysInOrder(t *testing.T) {
+	all := make(map[string]interface{})
+	for i := 0; i < 20; i++ {
+		all[fmt.Sprintf("key-%02d", i)] = "value"
+	}
+
+	metadata := ProviderMetadata(all)
+	assert.Len(t, metadata, MaxProviderMetadataPairs)
+	assert.Contains(t, metadata, "key-15")
+	assert.NotContains(t, metadata, "key-16")
+}
+
+func TestValidateProviderMetadata(t *testing.T) {
+	assert.NoError(t, ValidateProviderMetadata(map[string]interface{}{"experiment": "baseline", "benchmark": true}))
+	assert.NoError(t, ValidateProviderMetadata(map[string]interface{}{"note": strings.Repeat("é", 512)}), "lengths are counted in characters")
+
+	assert.Error(t, ValidateProviderMetadata(map[string]interface{}{strings.Repeat("k", 65): "v"}))
+	assert.Error(t, ValidateProviderMetadata(map[string]interface{}{"note": strings.Repeat("x", 513)}))
+
+	tooMany := make(map[string]interface{})
+	for i := 0; i <= MaxProviderMetadataPairs; i++ {
+		tooMany[fmt.Sprintf("key-%d", i)] = "value"
+	}
+	assert.Error(t, ValidateProviderMetadata(tooMany))
+}
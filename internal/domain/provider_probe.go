@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// ProviderProbe is the result of actively probing one provider on demand
+type ProviderProbe struct {
+	Provider Provider `json:"provider"`
+	Healthy  bool     `json:"healthy"`
+
+	// HealthStatus is the status routing currently uses for the provider,
+	// which the probe does not change
+	HealthStatus ProviderHealthStatus `json:"health_status,omitempty"`
+
+	LatencyMs int64 `json:"latency_ms"`
+
+	// StatusCode is the HTTP status the probe's outcome maps to
+	StatusCode int    `json:"status_code"`
+	ErrorType  string `json:"error_type,omitempty"`
+
+	// Error is the provider client's unredacted error
+	Error string `json:"error,omitempty"`
+
+	// Models are the models the provider listed, showing which are reachable
+	Models      []string `json:"models,omitempty"`
+	ModelsError string   `json:"models_error,omitempty"`
+
+	CheckedAt time.Time `json:"checked_at"`
+}
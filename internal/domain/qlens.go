@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"strings"
 	"time"
 )
 
@@ -33,8 +34,10 @@ const (
 type ContentType string
 
 const (
-	ContentTypeText     ContentType = "text"
-	ContentTypeImageURL ContentType = "image_url"
+	ContentTypeText      ContentType = "text"
+	ContentTypeImageURL  ContentType = "image_url"
+	ContentTypeReasoning ContentType = "reasoning"
+	ContentTypeRefusal   ContentType = "refusal"
 )
 
 // Message roles
@@ -45,6 +48,10 @@ const (
 	MessageRoleUser      MessageRole = "user"
 	MessageRoleAssistant MessageRole = "assistant"
 	MessageRoleTool      MessageRole = "tool"
+
+	// MessageRoleDeveloper takes the place of system messages for models
+	// that do not accept them, such as OpenAI's reasoning models
+	MessageRoleDeveloper MessageRole = "developer"
 )
 
 // Finish reasons
@@ -56,6 +63,8 @@ const (
 	FinishReasonToolCalls     FinishReason = "tool_calls"
 	FinishReasonContentFilter FinishReason = "content_filter"
 	FinishReasonFunctionCall  FinishReason = "function_call"
+	FinishReasonRefusal       FinishReason = "refusal"
+	FinishReasonCostLimit     FinishReason = "cost_limit"
 )
 
 // Provider health status
@@ -159,6 +168,19 @@ type FunctionCall struct {
 	Arguments string `json:"arguments"`
 }
 
+// Tool represents a tool the model may call
+type Tool struct {
+	Type     string             `json:"type"`
+	Function FunctionDefinition `json:"function"`
+}
+
+// FunctionDefinition describes a callable function
+type FunctionDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
 // LLMResponse represents the response from an LLM
 type LLMResponse struct {
 	ID       string    `json:"id"`
@@ -174,6 +196,7 @@ type LLMResponse struct {
 // Choice represents a completion choice
 type Choice struct {
 	Index        int           `json:"index"`
+	ID           string        `json:"id,omitempty"` // Stable across a stream's chunks; see ChoiceID
 	Message      Message       `json:"message"`
 	FinishReason FinishReason  `json:"finish_reason"`
 	LogProbs     interface{}   `json:"logprobs,omitempty"`
@@ -186,6 +209,10 @@ type Usage struct {
 	TotalTokens      int     `json:"total_tokens"`
 	CostUSD          float64 `json:"cost_usd,omitempty"`
 	CacheHit         bool    `json:"cache_hit,omitempty"`
+
+	// ReasoningTokens is the portion of CompletionTokens spent on hidden
+	// reasoning (o-series models, Claude extended thinking)
+	ReasoningTokens int `json:"reasoning_tokens,omitempty"`
 }
 
 // RequestError represents an error in processing a request
@@ -210,6 +237,16 @@ type EmbeddingRequest struct {
 	EncodingFormat  string      `json:"encoding_format,omitempty"`
 	Dimensions      *int        `json:"dimensions,omitempty"`
 	User            string      `json:"user,omitempty"`
+
+	// Representations asks for the vectors in further forms, derived from
+	// the single set the provider returns and listed in the response's
+	// Representations in the same order
+	Representations []EmbeddingRepresentation `json:"representations,omitempty"`
+
+	// DataResidency restricts the request to providers whose endpoints
+	// serve from the region (see ProviderConfig.DataRegion)
+	DataResidency string `json:"data_residency,omitempty"`
+
 	Status          RequestStatus `json:"status"`
 	SubmittedAt     time.Time   `json:"submitted_at"`
 	CompletedAt     *time.Time  `json:"completed_at,omitempty"`
@@ -225,13 +262,51 @@ type EmbeddingResponse struct {
 	Model    string      `json:"model"`
 	Provider Provider    `json:"provider"`
 	Usage    EmbeddingUsage `json:"usage"`
+
+	// RequestID is the gateway's correlation ID for the request
+	RequestID string `json:"request_id,omitempty"`
+
+	// ProviderRequestID is the ID the provider assigned to the call
+	ProviderRequestID string `json:"provider_request_id,omitempty"`
+
+	// Metadata carries routing details such as a substituted model
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// Representations holds the vectors in each form the request's
+	// Representations asked for
+	Representations []EmbeddingRepresentationData `json:"representations,omitempty"`
 }
 
+// Metadata keys set when a fallback model served an embedding request. The
+// vectors have the requested size but come from a different embedding space,
+// so callers should not mix them with vectors from the requested model.
+const (
+	MetadataKeyFallbackFrom  = "fallback_from"
+	MetadataKeyFallbackModel = "fallback_model"
+)
+
+// MetadataKeyEmbeddingBatches counts the provider calls an embedding request
+// was split into when it had more inputs than one call may carry
+const MetadataKeyEmbeddingBatches = "embedding_batches"
+
+// EmbeddingModelAuto asks the router for the best scoring embedding model
+// whose vectors have the request's dimensions, when embedding cost routing
+// is enabled. The response names the model that served the request.
+const EmbeddingModelAuto = "auto"
+
 // Embedding represents a single embedding
 type Embedding struct {
 	Object    string    `json:"object"`
 	Embedding []float64 `json:"embedding"`
 	Index     int       `json:"index"`
+
+	// Cached is set when the embedding was served from the embedding store
+	// rather than computed (and billed) by the provider
+	Cached bool `json:"cached"`
+
+	// Base64 is the embedding as base64 of little-endian float32s, set by
+	// SetEncoding for encoding_format base64. It replaces the floats in JSON.
+	Base64 string `json:"-"`
 }
 
 // EmbeddingUsage represents embedding token usage
@@ -239,6 +314,10 @@ type EmbeddingUsage struct {
 	PromptTokens int     `json:"prompt_tokens"`
 	TotalTokens  int     `json:"total_tokens"`
 	CostUSD      float64 `json:"cost_usd,omitempty"`
+
+	// CachedInputs counts inputs served from the embedding store; they are
+	// not included in the token counts or cost
+	CachedInputs int `json:"cached_inputs,omitempty"`
 }
 
 // Model represents an available LLM model
@@ -253,13 +332,51 @@ type Model struct {
 	Pricing      ModelPricing `json:"pricing"`
 	Status       ModelStatus  `json:"status"`
 	IsActive     bool         `json:"is_active"`
+
+	// Deployment tells on-demand models from ones served by reserved
+	// capacity; empty when the provider does not distinguish them
+	Deployment ModelDeployment `json:"deployment,omitempty"`
 }
 
+// ModelDeployment describes how a model's capacity is bought
+type ModelDeployment string
+
+const (
+	// ModelDeploymentOnDemand models are billed per token
+	ModelDeploymentOnDemand ModelDeployment = "on_demand"
+	// ModelDeploymentProvisioned models run on reserved throughput billed
+	// at a flat rate, so their per-token price is usually zero
+	ModelDeploymentProvisioned ModelDeployment = "provisioned"
+)
+
 // ModelPricing represents model pricing information
 type ModelPricing struct {
 	InputTokenCost  float64 `json:"input_token_cost"`
 	OutputTokenCost float64 `json:"output_token_cost"`
 	Unit           string  `json:"unit"`
+
+	// ReasoningTokenCost prices reasoning tokens; zero means output pricing
+	ReasoningTokenCost float64 `json:"reasoning_token_cost,omitempty"`
+}
+
+// PricingUnitThousandTokens marks costs quoted per 1K tokens; any other unit
+// is per token
+const PricingUnitThousandTokens = "1K tokens"
+
+// PerThousandTokens returns the input, output and reasoning costs per 1K
+// tokens whatever unit the pricing is quoted in. Reasoning falls back to the
+// output cost when it is not priced separately.
+func (p ModelPricing) PerThousandTokens() (input, output, reasoning float64) {
+	scale := 1000.0
+	if p.Unit == PricingUnitThousandTokens {
+		scale = 1
+	}
+
+	reasoningCost := p.ReasoningTokenCost
+	if reasoningCost == 0 {
+		reasoningCost = p.OutputTokenCost
+	}
+	return p.InputTokenCost * scale, p.OutputTokenCost * scale, reasoningCost * scale
 }
 
 // PromptTemplate represents a reusable prompt template
@@ -271,6 +388,7 @@ type PromptTemplate struct {
 	Category    string              `json:"category"`
 	Tags        []string            `json:"tags"`
 	Content     string              `json:"content"`
+	Engine      string              `json:"engine,omitempty"` // Template engine rendering Content
 	Variables   []TemplateVariable  `json:"variables"`
 	CreatedBy   UserID              `json:"created_by"`
 	IsPublic    bool                `json:"is_public"`
@@ -299,6 +417,11 @@ type ProviderConfig struct {
 	HealthStatus ProviderHealthStatus   `json:"health_status"`
 	Latency      float64               `json:"latency_ms"`
 	ErrorRate    float64               `json:"error_rate"`
+
+	// DataRegion is the data-residency region the provider's endpoint
+	// serves from, such as "eu". Providers without one never serve requests
+	// restricted to a region.
+	DataRegion string `json:"data_region,omitempty"`
 }
 
 // RateLimitConfig represents rate limiting configuration
@@ -493,4 +616,123 @@ func (pc *ProviderConfig) UpdateHealth(status ProviderHealthStatus, latency, err
 	pc.ErrorRate = errorRate
 	pc.LastHealthCheck = time.Now()
 	pc.updatedAt = time.Now()
-}
\ No newline at end of file
+}
+
+// HasCapability reports whether the model supports the given capability
+func (m *Model) HasCapability(capability Capability) bool {
+	for _, c := range m.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// CompletionCost prices completion usage. Reasoning tokens are counted within
+// CompletionTokens and billed at the reasoning rate, or the output rate when
+// the model has no separate reasoning price.
+func (p ModelPricing) CompletionCost(usage Usage) float64 {
+	reasoningTokens := usage.ReasoningTokens
+	if reasoningTokens > usage.CompletionTokens {
+		reasoningTokens = usage.CompletionTokens
+	}
+
+	reasoningRate := p.ReasoningTokenCost
+	if reasoningRate == 0 {
+		reasoningRate = p.OutputTokenCost
+	}
+
+	return float64(usage.PromptTokens)*p.InputTokenCost +
+		float64(usage.CompletionTokens-reasoningTokens)*p.OutputTokenCost +
+		float64(reasoningTokens)*reasoningRate
+}
+
+// NormalizeToolCalls gives tool-call responses one shape across providers.
+// Empty text parts are dropped and Content is left as an explicit empty
+// slice, never nil, so clients can tell "no content because of tool calls"
+// from a missing field. A stop finish reason becomes tool_calls.
+func (c *Choice) NormalizeToolCalls() {
+	if len(c.Message.ToolCalls) == 0 {
+		return
+	}
+
+	content := make([]ContentPart, 0, len(c.Message.Content))
+	for _, part := range c.Message.Content {
+		if part.Type == ContentTypeText && part.Text == "" {
+			continue
+		}
+		content = append(content, part)
+	}
+	c.Message.Content = content
+
+	if c.FinishReason == "" || c.FinishReason == FinishReasonStop {
+		c.FinishReason = FinishReasonToolCalls
+	}
+}
+
+// Repair fixes token counts a provider reported inconsistently, so a bad
+// payload cannot turn into negative or understated billing. Negative counts
+// are zeroed, reasoning tokens are capped at completion tokens, and a total
+// below prompt plus completion is recomputed. It returns the JSON names of
+// the fields it changed.
+func (u *Usage) Repair() []string {
+	var repaired []string
+	zeroNegative := func(field string, value *int) {
+		if *value < 0 {
+			*value = 0
+			repaired = append(repaired, field)
+		}
+	}
+
+	zeroNegative("prompt_tokens", &u.PromptTokens)
+	zeroNegative("completion_tokens", &u.CompletionTokens)
+	zeroNegative("total_tokens", &u.TotalTokens)
+	zeroNegative("reasoning_tokens", &u.ReasoningTokens)
+
+	if u.ReasoningTokens > u.CompletionTokens {
+		u.ReasoningTokens = u.CompletionTokens
+		repaired = append(repaired, "reasoning_tokens")
+	}
+	if sum := u.PromptTokens + u.CompletionTokens; u.TotalTokens < sum {
+		u.TotalTokens = sum
+		repaired = append(repaired, "total_tokens")
+	}
+
+	return repaired
+}
+
+// TokenLimitParam names the request field a model uses to cap output tokens
+type TokenLimitParam string
+
+const (
+	TokenLimitParamMaxTokens           TokenLimitParam = "max_tokens"
+	TokenLimitParamMaxCompletionTokens TokenLimitParam = "max_completion_tokens"
+)
+
+// openAITokenLimitParams maps OpenAI model families to their token limit
+// field. Reasoning-era models reject max_tokens.
+var openAITokenLimitParams = []struct {
+	prefix string
+	param  TokenLimitParam
+}{
+	{"o1", TokenLimitParamMaxCompletionTokens},
+	{"o3", TokenLimitParamMaxCompletionTokens},
+	{"o4", TokenLimitParamMaxCompletionTokens},
+	{"gpt-5", TokenLimitParamMaxCompletionTokens},
+	{"gpt-4", TokenLimitParamMaxTokens},
+	{"gpt-35", TokenLimitParamMaxTokens},
+	{"gpt-3.5", TokenLimitParamMaxTokens},
+}
+
+// OpenAITokenLimitParam returns the token limit field an OpenAI-compatible
+// model expects. Unknown models use max_tokens, which older deployments and
+// API versions still require.
+func OpenAITokenLimitParam(model string) TokenLimitParam {
+	model = strings.ToLower(model)
+	for _, entry := range openAITokenLimitParams {
+		if strings.HasPrefix(model, entry.prefix) {
+			return entry.param
+		}
+	}
+	return TokenLimitParamMaxTokens
+}
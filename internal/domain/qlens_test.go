@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModelPricing_CompletionCost(t *testing.T) {
+	pricing := ModelPricing{InputTokenCost: 0.001, OutputTokenCost: 0.002}
+
+	usage := Usage{PromptTokens: 100, CompletionTokens: 50}
+	assert.InDelta(t, 0.2, pricing.CompletionCost(usage), 1e-9)
+
+	// Reasoning tokens are part of the completion and default to output pricing
+	usage.ReasoningTokens = 30
+	assert.InDelta(t, 0.2, pricing.CompletionCost(usage), 1e-9)
+
+	// A separate reasoning rate applies only to the reasoning share
+	pricing.ReasoningTokenCost = 0.004
+	assert.InDelta(t, 0.1+20*0.002+30*0.004, pricing.CompletionCost(usage), 1e-9)
+}
+
+func TestModelPricing_CompletionCostCapsReasoning(t *testing.T) {
+	pricing := ModelPricing{OutputTokenCost: 0.002, ReasoningTokenCost: 0.004}
+
+	usage := Usage{CompletionTokens: 10, ReasoningTokens: 25}
+	assert.InDelta(t, 10*0.004, pricing.CompletionCost(usage), 1e-9)
+}
+
+func TestOpenAITokenLimitParam(t *testing.T) {
+	assert.Equal(t, TokenLimitParamMaxCompletionTokens, OpenAITokenLimitParam("o1-mini"))
+	assert.Equal(t, TokenLimitParamMaxCompletionTokens, OpenAITokenLimitParam("gpt-5-mini-2025-08-07"))
+	assert.Equal(t, TokenLimitParamMaxTokens, OpenAITokenLimitParam("gpt-4o"))
+	assert.Equal(t, TokenLimitParamMaxTokens, OpenAITokenLimitParam("gpt-35-turbo"))
+	assert.Equal(t, TokenLimitParamMaxTokens, OpenAITokenLimitParam("my-custom-model"))
+}
+
+func TestChoice_NormalizeToolCalls(t *testing.T) {
+	toolCall := ToolCall{ID: "call_1", Type: "function", Function: FunctionCall{Name: "lookup", Arguments: "{}"}}
+
+	choice := Choice{
+		Message: Message{
+			Content:   []ContentPart{{Type: ContentTypeReasoning, Text: "thinking"}, {Type: ContentTypeText}},
+			ToolCalls: []ToolCall{toolCall},
+		},
+		FinishReason: FinishReasonStop,
+	}
+	choice.NormalizeToolCalls()
+	assert.Equal(t, FinishReasonToolCalls, choice.FinishReason)
+	assert.Equal(t, []ContentPart{{Type: ContentTypeReasoning, Text: "thinking"}}, choice.Message.Content)
+
+	// A truncated tool call keeps its length finish reason
+	truncated := Choice{Message: Message{ToolCalls: []ToolCall{toolCall}}, FinishReason: FinishReasonLength}
+	truncated.NormalizeToolCalls()
+	assert.Equal(t, FinishReasonLength, truncated.FinishReason)
+	assert.NotNil(t, truncated.Message.Content)
+
+	// Choices without tool calls are untouched
+	plain := Choice{FinishReason: FinishReasonStop}
+	plain.NormalizeToolCalls()
+	assert.Nil(t, plain.Message.Content)
+	assert.Equal(t, FinishReasonStop, plain.FinishReason)
+}
+
+func TestUsage_Repair(t *testing.T) {
+	usage := Usage{PromptTokens: -5, CompletionTokens: 10, TotalTokens: 3, ReasoningTokens: 40}
+	repaired := usage.Repair()
+	assert.Equal(t, Usage{PromptTokens: 0, CompletionTokens: 10, TotalTokens: 10, ReasoningTokens: 10}, usage)
+	assert.Equal(t, []string{"prompt_tokens", "reasoning_tokens", "total_tokens"}, repaired)
+
+	// Consistent usage is untouched; a total above the sum is kept
+	valid := Usage{PromptTokens: 5, CompletionTokens: 10, TotalTokens: 20, ReasoningTokens: 4}
+	assert.Empty(t, valid.Repair())
+	assert.Equal(t, 20, valid.TotalTokens)
+}
+
+func TestCompletionRequest_Prefill(t *testing.T) {
+	text := func(role MessageRole, text string) Message {
+		return Message{Role: role, Content: []ContentPart{{Type: ContentTypeText, Text: text}}}
+	}
+
+	req := &CompletionRequest{Messages: []Message{text(MessageRoleUser, "Count to three"), text(MessageRoleAssistant, "One,")}}
+	prefill, ok := req.Prefill()
+	assert.True(t, ok)
+	assert.Equal(t, "One,", prefill)
+
+	// A lone assistant message, a user turn or a tool call is not a prefill
+	for _, messages := range [][]Message{
+		{text(MessageRoleAssistant, "One,")},
+		{text(MessageRoleAssistant, "One,"), text(MessageRoleUser, "Go on")},
+		{text(MessageRoleUser, "Weather?"), {Role: MessageRoleAssistant, ToolCalls: []ToolCall{{ID: "call-1"}}}},
+	} {
+		_, ok := (&CompletionRequest{Messages: messages}).Prefill()
+		assert.False(t, ok)
+	}
+}
@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/quantum-suite/platform/pkg/shared/errors"
@@ -13,6 +14,7 @@ type CompletionRequest struct {
 	Provider         Provider            `json:"provider,omitempty"`
 	Model            string              `json:"model"`
 	Messages         []Message           `json:"messages"`
+	Tools            []Tool              `json:"tools,omitempty"`
 	MaxTokens        *int                `json:"max_tokens,omitempty"`
 	Temperature      *float64            `json:"temperature,omitempty"`
 	TopP             *float64            `json:"top_p,omitempty"`
@@ -26,6 +28,112 @@ type CompletionRequest struct {
 	CacheEnabled     bool                `json:"cache_enabled"`
 	CacheTTL         time.Duration       `json:"cache_ttl"`
 	Metadata         map[string]interface{} `json:"metadata,omitempty"`
+	ResponseFormat   *ResponseFormat     `json:"response_format,omitempty"`
+	StreamOptions    *StreamOptions      `json:"stream_options,omitempty"`
+
+	// Per-request provider API version overrides, validated by the provider
+	// client against its allowlist
+	AzureAPIVersion  string `json:"azure_api_version,omitempty"`
+	AnthropicVersion string `json:"anthropic_version,omitempty"`
+
+	// IncludeRawResponse asks the provider client to retain the untranslated
+	// upstream body (debug only, see MetadataKeyRawProviderResponse)
+	IncludeRawResponse bool `json:"include_raw_response,omitempty"`
+
+	// ExplainRouting asks the router to describe how it chose the provider
+	// (debug only, see MetadataKeyRouting)
+	ExplainRouting bool `json:"explain_routing,omitempty"`
+
+	// MaxCostUSD caps the estimated cost of the request; streams are cut off
+	// once they reach it. Zero means no ceiling.
+	MaxCostUSD float64 `json:"max_cost_usd,omitempty"`
+
+	// ParamProfile names a configured set of sampling parameters applied by
+	// the router; parameters set on the request take precedence
+	ParamProfile string `json:"param_profile,omitempty"`
+
+	// VerboseUsage asks for the prompt tokens to be attributed per message
+	// in the response's metadata (see MetadataKeyUsageBreakdown)
+	VerboseUsage bool `json:"verbose_usage,omitempty"`
+
+	// StreamPassthrough asks for a stream to be relayed as the provider's
+	// own chunks (see StreamResponse.Raw)
+	StreamPassthrough bool `json:"stream_passthrough,omitempty"`
+
+	// StaleOnError accepts a cached response past its TTL when every
+	// provider fails (see MetadataKeyStale)
+	StaleOnError bool `json:"stale_on_error,omitempty"`
+
+	// DataResidency restricts the request to providers whose endpoints
+	// serve from the region (see ProviderConfig.DataRegion)
+	DataResidency string `json:"data_residency,omitempty"`
+
+	// Store asks providers that support it to keep the completion for
+	// their dashboards and evals, tagged with the string metadata (see
+	// ProviderMetadata)
+	Store *bool `json:"store,omitempty"`
+}
+
+// HasImageContent reports whether any message carries image parts
+func (r *CompletionRequest) HasImageContent() bool {
+	for _, msg := range r.Messages {
+		for _, part := range msg.Content {
+			if part.Type == ContentTypeImageURL {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Prefill returns the text of a trailing assistant message. Such a message
+// is a prefill: the model continues it instead of starting its own turn.
+func (r *CompletionRequest) Prefill() (string, bool) {
+	if len(r.Messages) < 2 {
+		return "", false
+	}
+	last := r.Messages[len(r.Messages)-1]
+	if last.Role != MessageRoleAssistant || len(last.ToolCalls) > 0 {
+		return "", false
+	}
+
+	text := ""
+	for _, part := range last.Content {
+		if part.Type == ContentTypeText {
+			text += part.Text
+		}
+	}
+	return text, text != ""
+}
+
+// ResponseFormatType identifies the requested output format
+type ResponseFormatType string
+
+const (
+	ResponseFormatText       ResponseFormatType = "text"
+	ResponseFormatJSONObject ResponseFormatType = "json_object"
+	ResponseFormatJSONSchema ResponseFormatType = "json_schema"
+)
+
+// ResponseFormat constrains the shape of the model output
+type ResponseFormat struct {
+	Type       ResponseFormatType     `json:"type"`
+	JSONSchema map[string]interface{} `json:"json_schema,omitempty"`
+}
+
+// IsJSON reports whether the format requests structured JSON output
+func (f *ResponseFormat) IsJSON() bool {
+	return f != nil && (f.Type == ResponseFormatJSONObject || f.Type == ResponseFormatJSONSchema)
+}
+
+// StreamOptions controls optional streaming behaviour
+type StreamOptions struct {
+	// PartialJSON enables incremental well-formedness checks of streamed JSON output
+	PartialJSON bool `json:"partial_json,omitempty"`
+
+	// MaxTokensPerSecond throttles delivery of streamed tokens; zero disables
+	// throttling unless the tenant has a configured cap
+	MaxTokensPerSecond float64 `json:"max_tokens_per_second,omitempty"`
 }
 
 // CompletionResponse represents a completion response
@@ -38,6 +146,135 @@ type CompletionResponse struct {
 	Choices  []Choice                `json:"choices"`
 	Usage    Usage                   `json:"usage"`
 	Metadata map[string]interface{}  `json:"metadata,omitempty"`
+
+	// RequestID is the gateway's correlation ID for the request
+	RequestID string `json:"request_id,omitempty"`
+
+	// ProviderRequestID is the ID the provider assigned to the call, for
+	// finding it in the provider's logs
+	ProviderRequestID string `json:"provider_request_id,omitempty"`
+
+	// ReceivedAt is when the router produced the response, by its own
+	// clock. Unlike Created it never depends on the provider's clock.
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// MetadataKeyRawProviderResponse holds the provider's raw response body
+const MetadataKeyRawProviderResponse = "raw_provider_response"
+
+// AttachRawResponse stores the provider's untranslated response body in the
+// response metadata. Bodies that are not valid JSON are stored as strings.
+func (r *CompletionResponse) AttachRawResponse(raw []byte) {
+	if r.Metadata == nil {
+		r.Metadata = make(map[string]interface{})
+	}
+	if json.Valid(raw) {
+		r.Metadata[MetadataKeyRawProviderResponse] = json.RawMessage(append([]byte(nil), raw...))
+		return
+	}
+	r.Metadata[MetadataKeyRawProviderResponse] = string(raw)
+}
+
+// MetadataKeyStale is set to true on a cached response served because every
+// provider failed
+const MetadataKeyStale = "stale"
+
+// MetadataKeyRoutingScores holds the scores of the providers considered when
+// the request asked for provider "auto", or of the models and providers
+// considered for an embedding request routed by cost
+const MetadataKeyRoutingScores = "routing_scores"
+
+// MetadataKeyContextUtilization holds the fraction of the model's context
+// window the prompt filled, when it passed the configured warning threshold
+const MetadataKeyContextUtilization = "context_utilization"
+
+// MetadataKeyMaxTokensAdjustment holds a MaxTokensAdjustment when the router
+// set or clamped the request's max_tokens
+const MetadataKeyMaxTokensAdjustment = "max_tokens_adjustment"
+
+// MetadataKeyTransformWarnings lists the changes the router made to the
+// request for the model, such as parameters it dropped because the model
+// rejects them
+const MetadataKeyTransformWarnings = "transform_warnings"
+
+// Ways the router adjusts max_tokens
+const (
+	MaxTokensDefaulted = "default"
+	MaxTokensClamped   = "clamped"
+)
+
+// MaxTokensAdjustment records how the router changed a request's max_tokens.
+// Requested is the client's value when it was clamped.
+type MaxTokensAdjustment struct {
+	Action    string `json:"action"`
+	MaxTokens int    `json:"max_tokens"`
+	Requested int    `json:"requested,omitempty"`
+}
+
+// ProviderScore is one provider's score for provider "auto". Cost and
+// latency are normalized against the most expensive and slowest candidate,
+// the error rate is the recent fraction of failed requests, and Score is
+// their weighted sum. The lowest score is selected.
+type ProviderScore struct {
+	Provider  Provider `json:"provider"`
+	// Model is set on the scores of embedding routes, which may differ in
+	// model as well as provider
+	Model     string   `json:"model,omitempty"`
+	Cost      float64  `json:"cost"`
+	Latency   float64  `json:"latency"`
+	ErrorRate float64  `json:"error_rate"`
+	Score     float64  `json:"score"`
+	Selected  bool     `json:"selected"`
+}
+
+// MetadataKeyRouting holds a RoutingExplanation when the request asked for
+// its routing to be explained
+const MetadataKeyRouting = "routing"
+
+// How the router picked the provider among the eligible ones
+const (
+	RoutingStrategyPinned        = "pinned"
+	RoutingStrategyAuto          = "auto"
+	RoutingStrategyOnlyCandidate = "only_candidate"
+	RoutingStrategyDefaultOrder  = "default_order"
+	RoutingStrategyLoadBalancer  = "load_balancer"
+)
+
+// Filters that rule a provider out of routing, checked in this order
+const (
+	RoutingExcludedNotRequested  = "not_requested"
+	RoutingExcludedDisabled      = "disabled"
+	RoutingExcludedUnhealthy     = "unhealthy"
+	RoutingExcludedModel         = "model_not_served"
+	RoutingExcludedDataResidency = "data_residency"
+	RoutingExcludedKillSwitch    = "kill_switch"
+)
+
+// RoutingExplanation describes how the router chose the provider for a
+// request: every configured provider it considered, the filter that
+// excluded each one it did not route to, and why the selected one won
+type RoutingExplanation struct {
+	Model      string             `json:"model"`
+	Strategy   string             `json:"strategy"`
+	Selected   Provider           `json:"selected"`
+	Rationale  string             `json:"rationale"`
+	Candidates []RoutingCandidate `json:"candidates"`
+}
+
+// RoutingCandidate is one provider as the router saw it. LatencyMs is the
+// tracked average, unset until the provider has served requests, and
+// CostPer1K the model's input plus output price at the provider. Providers
+// that passed every filter carry their ProviderScore, computed as for
+// provider "auto" whichever strategy picked the provider.
+type RoutingCandidate struct {
+	Provider  Provider       `json:"provider"`
+	Health    ProviderHealthStatus `json:"health"`
+	LatencyMs float64        `json:"latency_ms,omitempty"`
+	CostPer1K float64        `json:"cost_per_1k,omitempty"`
+	ErrorRate float64        `json:"error_rate"`
+	Score     *ProviderScore `json:"score,omitempty"`
+	Excluded  string         `json:"excluded,omitempty"`
+	Selected  bool           `json:"selected"`
 }
 
 // StreamResponse represents a streaming response chunk
@@ -50,6 +287,52 @@ type StreamResponse struct {
 	Choices  []Choice                `json:"choices,omitempty"`
 	Done     bool                    `json:"done,omitempty"`
 	Error    *errors.QLensError      `json:"error,omitempty"`
+
+	// Partial JSON validation (only set when StreamOptions.PartialJSON is enabled)
+	PartialValid *bool          `json:"partial_valid,omitempty"`
+	Validation   *JSONValidation `json:"validation,omitempty"`
+
+	// ProviderRequestID is the ID the provider assigned to the stream
+	ProviderRequestID string `json:"provider_request_id,omitempty"`
+
+	// Usage is the provider's token count for the whole stream, on the
+	// chunk that reports it (providers that do report it send it once, at
+	// the end)
+	Usage *Usage `json:"usage,omitempty"`
+
+	// Final marks the event the router sends last before [DONE], carrying
+	// every choice's finish reason and the usage whichever chunks the
+	// provider reported them in
+	Final bool `json:"final,omitempty"`
+
+	// Metadata is set on the final event, with the stream's time to first
+	// token
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+
+	// ToolCallsAssembled marks the event carrying each choice's tool calls,
+	// reassembled by the router from the provider's fragments, with their
+	// arguments checked to be JSON
+	ToolCallsAssembled bool `json:"tool_calls_assembled,omitempty"`
+
+	// Raw is the chunk exactly as the provider sent it, kept for
+	// pass-through streams by providers that speak the OpenAI schema
+	Raw json.RawMessage `json:"raw,omitempty"`
+}
+
+// StreamOutcome describes how a completion stream terminated
+type StreamOutcome string
+
+const (
+	StreamOutcomeCompleted     StreamOutcome = "completed"
+	StreamOutcomeCancelled     StreamOutcome = "cancelled"
+	StreamOutcomeProviderError StreamOutcome = "provider_error"
+	StreamOutcomeCostLimit     StreamOutcome = "cost_limit"
+)
+
+// JSONValidation is the final validation result for streamed JSON output
+type JSONValidation struct {
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
 }
 
 // Note: EmbeddingRequest and EmbeddingResponse are already defined in qlens.go
@@ -64,6 +347,10 @@ type ListModelsOptions struct {
 type ModelsResponse struct {
 	Object string  `json:"object"`
 	Data   []Model `json:"data"`
+
+	// RegistryVersion identifies the contents of the router's model
+	// registry, so replicas routing on different views can be told apart
+	RegistryVersion string `json:"registry_version,omitempty"`
 }
 
 // HealthResponse represents a health check response
@@ -72,6 +359,9 @@ type HealthResponse struct {
 	Timestamp time.Time                          `json:"timestamp"`
 	Services  map[string]ServiceHealth           `json:"services"`
 	Providers map[string]ProviderHealth          `json:"providers"`
+
+	// RegistryVersion identifies the contents of the router's model registry
+	RegistryVersion string `json:"registry_version,omitempty"`
 }
 
 // ServiceHealth represents the health status of a service
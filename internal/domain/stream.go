@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// MetadataKeyStreamCollected is set on a completion that was streamed from
+// the provider and collected into a single response
+const MetadataKeyStreamCollected = "stream_collected"
+
+// MetadataKeyTimeToFirstToken is set on the final event of a stream, and on
+// a collected stream's response, to the milliseconds between the router
+// calling the provider and the first content arriving
+const MetadataKeyTimeToFirstToken = "time_to_first_token_ms"
+
+// CollectStream reads a completion stream to the end and assembles the chunks
+// into a single response. Text is concatenated per choice, tool call
+// fragments without an ID extend the previous call, and the last finish
+// reason wins. An error chunk, or the context ending first, fails the
+// collection. Usage is the provider's reported usage, or zero when the
+// provider reports none. Metadata on the chunks, such as the final event's
+// time to first token, is kept.
+func CollectStream(ctx context.Context, stream <-chan *StreamResponse) (*CompletionResponse, error) {
+	response := &CompletionResponse{Object: "chat.completion"}
+	choices := make(map[int]*collectedChoice)
+
+	for {
+		select {
+		case chunk, ok := <-stream:
+			if !ok {
+				return response.withChoices(choices), nil
+			}
+			if chunk.Error != nil {
+				return nil, chunk.Error
+			}
+
+			if response.ID == "" {
+				response.ID = chunk.ID
+			}
+			if response.Created == 0 {
+				response.Created = chunk.Created
+			}
+			if response.Model == "" {
+				response.Model = chunk.Model
+			}
+			if response.Provider == "" {
+				response.Provider = chunk.Provider
+			}
+			if response.ProviderRequestID == "" {
+				response.ProviderRequestID = chunk.ProviderRequestID
+			}
+			if chunk.Usage != nil {
+				response.Usage = *chunk.Usage
+			}
+			for key, value := range chunk.Metadata {
+				if response.Metadata == nil {
+					response.Metadata = make(map[string]interface{})
+				}
+				response.Metadata[key] = value
+			}
+
+			for _, choice := range chunk.Choices {
+				collected, ok := choices[choice.Index]
+				if !ok {
+					collected = &collectedChoice{}
+					choices[choice.Index] = collected
+				}
+				collected.add(choice)
+			}
+
+			if chunk.Done {
+				return response.withChoices(choices), nil
+			}
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// collectedChoice accumulates one choice's deltas
+type collectedChoice struct {
+	role         MessageRole
+	text         strings.Builder
+	parts        []ContentPart
+	toolCalls    []ToolCall
+	finishReason FinishReason
+}
+
+func (c *collectedChoice) add(choice Choice) {
+	if c.role == "" {
+		c.role = choice.Message.Role
+	}
+	for _, part := range choice.Message.Content {
+		if part.Type == ContentTypeText {
+			c.text.WriteString(part.Text)
+			continue
+		}
+		c.parts = append(c.parts, part)
+	}
+	for _, call := range choice.Message.ToolCalls {
+		if call.ID == "" && len(c.toolCalls) > 0 {
+			last := &c.toolCalls[len(c.toolCalls)-1]
+			last.Function.Name += call.Function.Name
+			last.Function.Arguments += call.Function.Arguments
+			continue
+		}
+		c.toolCalls = append(c.toolCalls, call)
+	}
+	if choice.FinishReason != "" {
+		c.finishReason = choice.FinishReason
+	}
+}
+
+// withChoices sets the response's choices in index order
+func (r *CompletionResponse) withChoices(choices map[int]*collectedChoice) *CompletionResponse {
+	r.Choices = make([]Choice, 0, len(choices))
+	for index, collected := range choices {
+		role := collected.role
+		if role == "" {
+			role = MessageRoleAssistant
+		}
+
+		var content []ContentPart
+		if collected.text.Len() > 0 {
+			content = append(content, ContentPart{Type: ContentTypeText, Text: collected.text.String()})
+		}
+		content = append(content, collected.parts...)
+
+		r.Choices = append(r.Choices, Choice{
+			Index: index,
+			ID:    ChoiceID(r.ID, index),
+			Message: Message{
+				Role:      role,
+				Content:   content,
+				ToolCalls: collected.toolCalls,
+			},
+			FinishReason: collected.finishReason,
+		})
+	}
+	sort.Slice(r.Choices, func(i, j int) bool { return r.Choices[i].Index < r.Choices[j].Index })
+	return r
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+func streamOf(chunks ...*StreamResponse) <-chan *StreamResponse {
+	ch := make(chan *StreamResponse, len(chunks))
+	for _, chunk := range chunks {
+		ch <- chunk
+	}
+	close(ch)
+	return ch
+}
+
+func textChoice(index int, text string) Choice {
+	return Choice{Index: index, Message: Message{Content: []ContentPart{{Type: ContentTypeText, Text: text}}}}
+}
+
+func TestCollectStream(t *testing.T) {
+	stream := streamOf(
+		&StreamResponse{ID: "chatcmpl-1", Model: "gpt-4", Provider: ProviderAzureOpenAI, ProviderRequestID: "req-1", Choices: []Choice{textChoice(1, "Good"), textChoice(0, "Hel")}},
+		&StreamResponse{Choices: []Choice{textChoice(0, "lo"), textChoice(1, "bye")}},
+		&StreamResponse{Choices: []Choice{{Index: 0, FinishReason: FinishReasonStop}, {Index: 1, FinishReason: FinishReasonLength}}},
+		&StreamResponse{Done: true},
+	)
+
+	response, err := CollectStream(context.Background(), stream)
+	require.NoError(t, err)
+
+	assert.Equal(t, "chatcmpl-1", response.ID)
+	assert.Equal(t, "chat.completion", response.Object)
+	assert.Equal(t, "gpt-4", response.Model)
+	assert.Equal(t, ProviderAzureOpenAI, response.Provider)
+	assert.Equal(t, "req-1", response.ProviderRequestID)
+
+	require.Len(t, response.Choices, 2)
+	assert.Equal(t, 0, response.Choices[0].Index)
+	assert.Equal(t, "chatcmpl-1-0", response.Choices[0].ID)
+	assert.Equal(t, "chatcmpl-1-1", response.Choices[1].ID)
+	assert.Equal(t, MessageRoleAssistant, response.Choices[0].Message.Role)
+	assert.Equal(t, "Hello", response.Choices[0].Message.Content[0].Text)
+	assert.Equal(t, FinishReasonStop, response.Choices[0].FinishReason)
+	assert.Equal(t, "Goodbye", response.Choices[1].Message.Content[0].Text)
+	assert.Equal(t, FinishReasonLength, response.Choices[1].FinishReason)
+	assert.Nil(t, response.Metadata)
+}
+
+func TestCollectStream_KeepsFinalEventMetadata(t *testing.T) {
+	stream := streamOf(
+		&StreamResponse{ID: "chatcmpl-1", Choices: []Choice{textChoice(0, "Hi")}},
+		&StreamResponse{Final: true, Metadata: map[string]interface{}{MetadataKeyTimeToFirstToken: 120}},
+		&StreamResponse{Done: true},
+	)
+
+	response, err := CollectStream(context.Background(), stream)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{MetadataKeyTimeToFirstToken: 120}, response.Metadata)
+}
+
+func TestCollectStream_ToolCallFragments(t *testing.T) {
+	call := func(id, name, arguments string) *StreamResponse {
+		return &StreamResponse{Choices: []Choice{{Message: Message{ToolCalls: []ToolCall{{ID: id, Function: FunctionCall{Name: name, Arguments: arguments}}}}}}}
+	}
+	stream := streamOf(
+		call("call-1", "lookup", `{"q":`),
+		call("", "", `"go"}`),
+		call("call-2", "fetch", `{}`),
+	)
+
+	response, err := CollectStream(context.Background(), stream)
+	require.NoError(t, err)
+
+	calls := response.Choices[0].Message.ToolCalls
+	require.Len(t, calls, 2)
+	assert.Equal(t, `{"q":"go"}`, calls[0].Function.Arguments)
+	assert.Equal(t, "fetch", calls[1].Function.Name)
+}
+
+func TestCollectStream_Error(t *testing.T) {
+	providerErr := errors.ProviderError("azure-openai", "stream reset", nil)
+	stream := streamOf(&StreamResponse{Choices: []Choice{textChoice(0, "Hel")}}, &StreamResponse{Error: providerErr})
+
+	_, err := CollectStream(context.Background(), stream)
+	assert.Equal(t, providerErr, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = CollectStream(ctx, make(chan *StreamResponse))
+	assert.ErrorIs(t, err, context.Canceled)
+}
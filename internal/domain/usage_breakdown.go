@@ -0,0 +1,150 @@
+package domain
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// MetadataKeyUsageBreakdown holds a UsageBreakdown when the request asked
+// for verbose usage
+const MetadataKeyUsageBreakdown = "usage_breakdown"
+
+// Token estimates for prompt content. Text is counted at roughly four
+// characters per token, the heuristic behind every token estimate in the
+// platform; images count as a low-detail image does on OpenAI models.
+const (
+	messageTokenOverhead = 4
+	imageTokenEstimate   = 85
+)
+
+// Sources of the token counts in a UsageBreakdown
+const (
+	UsageBreakdownProvider = "provider"
+	UsageBreakdownEstimate = "estimate"
+)
+
+// UsageBreakdown attributes a completion's prompt tokens to the messages and
+// tool definitions of its request. Providers only report totals, so each
+// part's share is estimated; when the provider reported prompt tokens the
+// shares are scaled to add up to exactly that number.
+type UsageBreakdown struct {
+	// Source is provider when the shares add up to the provider's prompt
+	// tokens, or estimate when the provider reported none
+	Source       string          `json:"source"`
+	PromptTokens int             `json:"prompt_tokens"`
+	Messages     []MessageTokens `json:"messages"`
+	// Tools is the share of the tool definitions sent with the request
+	Tools  int            `json:"tools,omitempty"`
+	ByRole map[string]int `json:"by_role"`
+}
+
+// MessageTokens is one message's share of the prompt tokens
+type MessageTokens struct {
+	Index  int         `json:"index"`
+	Role   MessageRole `json:"role"`
+	Name   string      `json:"name,omitempty"`
+	Tokens int         `json:"tokens"`
+}
+
+// NewUsageBreakdown attributes promptTokens, as reported by the provider, to
+// the request's messages and tools. A promptTokens of zero reports the
+// estimates as they are.
+func NewUsageBreakdown(req *CompletionRequest, promptTokens int) *UsageBreakdown {
+	estimates := make([]int, len(req.Messages)+1)
+	for i, msg := range req.Messages {
+		estimates[i] = EstimateMessageTokens(msg)
+	}
+	estimates[len(req.Messages)] = estimateToolTokens(req.Tools)
+
+	breakdown := &UsageBreakdown{Source: UsageBreakdownEstimate, ByRole: make(map[string]int)}
+	if promptTokens > 0 {
+		estimates = scaleTokens(estimates, promptTokens)
+		breakdown.Source = UsageBreakdownProvider
+	}
+
+	breakdown.Messages = make([]MessageTokens, len(req.Messages))
+	for i, msg := range req.Messages {
+		breakdown.Messages[i] = MessageTokens{Index: i, Role: msg.Role, Name: msg.Name, Tokens: estimates[i]}
+		breakdown.ByRole[string(msg.Role)] += estimates[i]
+		breakdown.PromptTokens += estimates[i]
+	}
+	breakdown.Tools = estimates[len(req.Messages)]
+	breakdown.PromptTokens += breakdown.Tools
+	return breakdown
+}
+
+// EstimateTextTokens approximates the tokens in chars characters of text
+func EstimateTextTokens(chars int) int {
+	return (chars + 3) / 4
+}
+
+// EstimatePromptTokens approximates the prompt tokens of a conversation,
+// message by message as EstimateMessageTokens counts them
+func EstimatePromptTokens(messages []Message) int {
+	tokens := 0
+	for _, msg := range messages {
+		tokens += EstimateMessageTokens(msg)
+	}
+	return tokens
+}
+
+// EstimateMessageTokens approximates the prompt tokens one message costs:
+// its text, images, tool calls and name, plus the chat format's overhead
+func EstimateMessageTokens(msg Message) int {
+	chars, tokens := len(msg.Name), messageTokenOverhead
+	for _, part := range msg.Content {
+		chars += len(part.Text)
+		if part.Type == ContentTypeImageURL {
+			tokens += imageTokenEstimate
+		}
+	}
+	for _, call := range msg.ToolCalls {
+		chars += len(call.Function.Name) + len(call.Function.Arguments)
+	}
+	return tokens + EstimateTextTokens(chars)
+}
+
+// estimateToolTokens approximates the prompt tokens of tool definitions from
+// the size of their JSON
+func estimateToolTokens(tools []Tool) int {
+	if len(tools) == 0 {
+		return 0
+	}
+	data, err := json.Marshal(tools)
+	if err != nil {
+		return 0
+	}
+	return EstimateTextTokens(len(data))
+}
+
+// scaleTokens scales estimates in proportion so they add up to total,
+// handing the tokens lost to rounding to the largest remainders
+func scaleTokens(estimates []int, total int) []int {
+	sum := 0
+	for _, estimate := range estimates {
+		sum += estimate
+	}
+	if sum == 0 {
+		return estimates
+	}
+
+	scaled := make([]int, len(estimates))
+	remainders := make([]int, len(estimates))
+	assigned := 0
+	for i, estimate := range estimates {
+		share := estimate * total
+		scaled[i], remainders[i] = share/sum, share%sum
+		assigned += scaled[i]
+	}
+
+	order := make([]int, len(estimates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for i := 0; assigned < total; i++ {
+		scaled[order[i%len(order)]]++
+		assigned++
+	}
+	return scaled
+}
@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func usageBreakdownRequest() *CompletionRequest {
+	text := func(role MessageRole, s string) Message {
+		return Message{Role: role, Content: []ContentPart{{Type: ContentTypeText, Text: s}}}
+	}
+	return &CompletionRequest{
+		Messages: []Message{
+			text(MessageRoleSystem, strings.Repeat("s", 400)),
+			text(MessageRoleUser, strings.Repeat("u", 40)),
+			text(MessageRoleAssistant, strings.Repeat("a", 80)),
+			text(MessageRoleTool, strings.Repeat("t", 1200)),
+		},
+		Tools: []Tool{{Type: "function", Function: FunctionDefinition{Name: "lookup", Description: "Look something up"}}},
+	}
+}
+
+func TestNewUsageBreakdown_ScalesToReportedPromptTokens(t *testing.T) {
+	breakdown := NewUsageBreakdown(usageBreakdownRequest(), 1000)
+
+	assert.Equal(t, UsageBreakdownProvider, breakdown.Source)
+	assert.Equal(t, 1000, breakdown.PromptTokens)
+	sum := breakdown.Tools
+	for _, msg := range breakdown.Messages {
+		sum += msg.Tokens
+	}
+	assert.Equal(t, 1000, sum)
+	assert.Positive(t, breakdown.Tools)
+
+	// The tool result is the largest message and keeps the largest share
+	assert.Greater(t, breakdown.ByRole[string(MessageRoleTool)], breakdown.ByRole[string(MessageRoleSystem)])
+	assert.Greater(t, breakdown.ByRole[string(MessageRoleSystem)], breakdown.ByRole[string(MessageRoleUser)])
+	assert.Equal(t, breakdown.Messages[3].Tokens, breakdown.ByRole[string(MessageRoleTool)])
+}
+
+func TestNewUsageBreakdown_EstimatesWithoutReportedTokens(t *testing.T) {
+	req := usageBreakdownRequest()
+	breakdown := NewUsageBreakdown(req, 0)
+
+	assert.Equal(t, UsageBreakdownEstimate, breakdown.Source)
+	assert.Equal(t, 104, breakdown.Messages[0].Tokens)
+	assert.Equal(t, 14, breakdown.Messages[1].Tokens)
+	assert.Equal(t, EstimateMessageTokens(req.Messages[3]), breakdown.Messages[3].Tokens)
+}
+
+func TestEstimateMessageTokens_CountsImagesAndToolCalls(t *testing.T) {
+	msg := Message{
+		Role:      MessageRoleAssistant,
+		Content:   []ContentPart{{Type: ContentTypeImageURL, ImageURL: &ImageURL{URL: "data:image/png;base64," + strings.Repeat("A", 10000)}}},
+		ToolCalls: []ToolCall{{Function: FunctionCall{Name: "lookup", Arguments: `{"q":"x"}`}}},
+	}
+
+	// The image's data is not counted as text
+	assert.Equal(t, messageTokenOverhead+imageTokenEstimate+4, EstimateMessageTokens(msg))
+}
+
+func TestEstimatePromptTokens_SumsMessages(t *testing.T) {
+	messages := []Message{
+		{Role: MessageRoleSystem, Content: []ContentPart{{Type: ContentTypeText, Text: "Be brief."}}},
+		{Role: MessageRoleUser, Content: []ContentPart{{Type: ContentTypeText, Text: "Hello"}}},
+	}
+
+	assert.Equal(t, EstimateMessageTokens(messages[0])+EstimateMessageTokens(messages[1]), EstimatePromptTokens(messages))
+	assert.Equal(t, 2*messageTokenOverhead+3+2, EstimatePromptTokens(messages))
+	assert.Zero(t, EstimateTextTokens(0))
+	assert.Equal(t, 1, EstimateTextTokens(1))
+}
@@ -3,16 +3,22 @@ package providers
 import (
 	"context"
 	"encoding/json"
+	goerrors "errors"
 	"fmt"
 	"os"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/credentials"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	bedrocktypes "github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 	"github.com/google/uuid"
 	"github.com/quantum-suite/platform/internal/domain"
 	"github.com/quantum-suite/platform/pkg/shared/errors"
@@ -20,10 +26,13 @@ import (
 )
 
 type AWSBedrockClient struct {
-	client *bedrockruntime.Client
-	region string
-	logger logger.Logger
-	models []domain.Model
+	client                   *bedrockruntime.Client
+	region                   string
+	logger                   logger.Logger
+	models                   []domain.Model
+	modelConfigs             map[string]BedrockModelConfig
+	allowedAnthropicVersions map[string]bool
+	healthProbe              HealthProbe
 }
 
 type AWSBedrockConfig struct {
@@ -32,12 +41,45 @@ type AWSBedrockConfig struct {
 	SecretAccessKey string                    `json:"secret_access_key"`
 	SessionToken    string                    `json:"session_token"`
 	Models          []BedrockModelConfig      `json:"models"`
+
+	// AllowedAnthropicVersions lists the anthropic_version values a request
+	// may pin; the default version is always allowed
+	AllowedAnthropicVersions []string `json:"allowed_anthropic_versions,omitempty"`
+
+	// UserAgent is appended to the AWS SDK user agent; defaults to QLens/<version>
+	UserAgent string `json:"user_agent,omitempty"`
+
+	// CustomHeaders are sent with every request. They are added before
+	// signing and may not replace the SigV4 headers.
+	CustomHeaders map[string]string `json:"custom_headers,omitempty"`
+
+	// HealthProbe is the request HealthCheck sends; defaults to
+	// HealthProbeList, which is not billed
+	HealthProbe HealthProbe `json:"health_probe,omitempty"`
 }
 
 type BedrockModelConfig struct {
 	ID      string `json:"id"`
 	ModelID string `json:"model_id"`
 	Name    string `json:"name"`
+
+	// ProvisionedThroughputARN invokes the model through purchased
+	// provisioned throughput instead of on demand. ModelID still names the
+	// underlying foundation model.
+	ProvisionedThroughputARN string `json:"provisioned_throughput_arn,omitempty"`
+
+	// Pricing overrides the per-token price. Provisioned throughput is
+	// billed by the hour, so it is priced at zero per token unless set.
+	Pricing *domain.ModelPricing `json:"pricing,omitempty"`
+}
+
+// invocationID is the ModelId to invoke: the provisioned throughput if the
+// model has one, otherwise the foundation model
+func (m BedrockModelConfig) invocationID() string {
+	if m.ProvisionedThroughputARN != "" {
+		return m.ProvisionedThroughputARN
+	}
+	return m.ModelID
 }
 
 type claudeRequest struct {
@@ -49,6 +91,11 @@ type claudeRequest struct {
 	System           string          `json:"system,omitempty"`
 	Stop             []string        `json:"stop_sequences,omitempty"`
 	Stream           bool            `json:"stream,omitempty"`
+	Metadata         *claudeMetadata `json:"metadata,omitempty"`
+}
+
+type claudeMetadata struct {
+	UserID string `json:"user_id,omitempty"`
 }
 
 type claudeMessage struct {
@@ -64,13 +111,22 @@ type claudeResponse struct {
 	Model        string              `json:"model"`
 	StopReason   string              `json:"stop_reason"`
 	StopSequence string              `json:"stop_sequence,omitempty"`
-	Usage        claudeUsage         `json:"usage"`
+	Usage        *claudeUsage        `json:"usage"`
 	Error        *claudeError        `json:"error,omitempty"`
 }
 
 type claudeContent struct {
-	Type string `json:"type"`
-	Text string `json:"text"`
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	Thinking string `json:"thinking,omitempty"`
+
+	// tool_use blocks
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// StopReason is set on the delta of a streamed message_delta event
+	StopReason string `json:"stop_reason,omitempty"`
 }
 
 type claudeUsage struct {
@@ -90,6 +146,15 @@ type claudeStreamResponse struct {
 	Usage        *claudeUsage    `json:"usage,omitempty"`
 	StopReason   string          `json:"stop_reason,omitempty"`
 	StopSequence string          `json:"stop_sequence,omitempty"`
+
+	// InvocationMetrics is added by Bedrock to the message_stop event
+	InvocationMetrics *bedrockInvocationMetrics `json:"amazon-bedrock-invocationMetrics,omitempty"`
+}
+
+// bedrockInvocationMetrics is Bedrock's own count of a streamed invocation
+type bedrockInvocationMetrics struct {
+	InputTokenCount  int `json:"inputTokenCount"`
+	OutputTokenCount int `json:"outputTokenCount"`
 }
 
 const (
@@ -157,13 +222,45 @@ func NewAWSBedrockClient(bedrockConfig AWSBedrockConfig, logger logger.Logger) (
 		)
 	}
 
-	client := bedrockruntime.NewFromConfig(cfg)
+	if err := validateCustomHeaders("aws bedrock", bedrockConfig.CustomHeaders,
+		"Authorization", "X-Amz-Date", "X-Amz-Security-Token", "X-Amz-Content-Sha256"); err != nil {
+		return nil, err
+	}
+
+	client := bedrockruntime.NewFromConfig(cfg, func(o *bedrockruntime.Options) {
+		o.APIOptions = append(o.APIOptions, awsmiddleware.AddUserAgentKey(resolveUserAgent(bedrockConfig.UserAgent)))
+		for name, value := range bedrockConfig.CustomHeaders {
+			o.APIOptions = append(o.APIOptions, smithyhttp.SetHeaderValue(name, value))
+		}
+	})
+
+	allowedAnthropicVersions := map[string]bool{claudeAnthropicVersion: true}
+	for _, version := range bedrockConfig.AllowedAnthropicVersions {
+		allowedAnthropicVersions[version] = true
+	}
+
+	healthProbe, err := parseHealthProbe("aws bedrock", bedrockConfig.HealthProbe,
+		HealthProbeList, HealthProbeCountTokens, HealthProbeCompletion)
+	if err != nil {
+		return nil, err
+	}
+
+	modelConfigs := make(map[string]BedrockModelConfig, len(bedrockConfig.Models))
+	for _, modelConfig := range bedrockConfig.Models {
+		if arn := modelConfig.ProvisionedThroughputARN; arn != "" && !strings.HasPrefix(arn, "arn:") {
+			return nil, errors.ConfigurationError(fmt.Sprintf("invalid provisioned throughput ARN for bedrock model %s: %s", modelConfig.ID, arn))
+		}
+		modelConfigs[modelConfig.ID] = modelConfig
+	}
 
 	return &AWSBedrockClient{
-		client: client,
-		region: bedrockConfig.Region,
-		logger: logger,
-		models: generateBedrockModelList(bedrockConfig.Models),
+		client:                   client,
+		region:                   bedrockConfig.Region,
+		logger:                   logger,
+		models:                   generateBedrockModelList(bedrockConfig.Models),
+		modelConfigs:             modelConfigs,
+		allowedAnthropicVersions: allowedAnthropicVersions,
+		healthProbe:              healthProbe,
 	}, nil
 }
 
@@ -180,6 +277,15 @@ func generateBedrockModelList(modelConfigs []BedrockModelConfig) []domain.Model
 			}
 		}
 
+		deployment := domain.ModelDeploymentOnDemand
+		if modelConfig.ProvisionedThroughputARN != "" {
+			deployment = domain.ModelDeploymentProvisioned
+			pricing = domain.ModelPricing{Unit: "token"}
+		}
+		if modelConfig.Pricing != nil {
+			pricing = *modelConfig.Pricing
+		}
+
 		capabilities := []domain.Capability{domain.CapabilityCompletion}
 		contextLength := 200000
 
@@ -197,6 +303,7 @@ func generateBedrockModelList(modelConfigs []BedrockModelConfig) []domain.Model
 			Pricing:       pricing,
 			Status:        domain.ModelStatusAvailable,
 			IsActive:      true,
+			Deployment:    deployment,
 		}
 		model.BaseEntity = domain.NewBaseEntity()
 
@@ -206,13 +313,30 @@ func generateBedrockModelList(modelConfigs []BedrockModelConfig) []domain.Model
 	return models
 }
 
+// CreateCompletion invokes the request's Claude model
 func (c *AWSBedrockClient) CreateCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+	scope := completionScope(req)
+	response, err := c.createCompletion(ctx, req, scope)
+	if err != nil {
+		scope.logger(c.logger).Warn("AWS Bedrock completion failed", logger.F("model", req.Model), logger.F("error", err))
+		return nil, scope.attribute(err)
+	}
+	return response, nil
+}
+
+func (c *AWSBedrockClient) createCompletion(ctx context.Context, req *domain.CompletionRequest, scope requestScope) (*domain.CompletionResponse, error) {
 	modelID := c.findModelID(req.Model)
 	if modelID == "" {
 		return nil, errors.ValidationError("model not found", "model")
 	}
 
+	anthropicVersion, err := c.resolveAnthropicVersion(req)
+	if err != nil {
+		return nil, err
+	}
+
 	claudeReq := c.convertCompletionRequest(req)
+	claudeReq.AnthropicVersion = anthropicVersion
 	
 	body, err := json.Marshal(claudeReq)
 	if err != nil {
@@ -236,20 +360,48 @@ func (c *AWSBedrockClient) CreateCompletion(ctx context.Context, req *domain.Com
 		return nil, errors.ProviderError("bedrock", "failed to parse response", err)
 	}
 
+	requestID, _ := awsmiddleware.GetRequestIDMetadata(result.ResultMetadata)
 	if claudeResp.Error != nil {
-		return nil, errors.ProviderError("bedrock", claudeResp.Error.Message, nil)
+		return nil, withProviderRequestID(errors.ProviderError("bedrock", claudeResp.Error.Message, nil), requestID)
 	}
 
-	return c.convertCompletionResponse(&claudeResp, req.Model), nil
+	response, err := c.convertCompletionResponse(&claudeResp, req.Model, scope.logger(c.logger))
+	if err != nil {
+		return nil, withProviderRequestID(err, requestID)
+	}
+	response.ProviderRequestID = requestID
+	if req.IncludeRawResponse {
+		response.AttachRawResponse(result.Body)
+	}
+
+	return response, nil
 }
 
+// CreateCompletionStream invokes the request's Claude model with a response
+// stream. Errors in the stream carry the same attribution as errors opening it.
 func (c *AWSBedrockClient) CreateCompletionStream(ctx context.Context, req *domain.CompletionRequest) (<-chan *domain.StreamResponse, error) {
+	scope := completionScope(req)
+	stream, err := c.createCompletionStream(ctx, req, scope)
+	if err != nil {
+		scope.logger(c.logger).Warn("AWS Bedrock completion stream failed", logger.F("model", req.Model), logger.F("error", err))
+		return nil, scope.attribute(err)
+	}
+	return stream, nil
+}
+
+func (c *AWSBedrockClient) createCompletionStream(ctx context.Context, req *domain.CompletionRequest, scope requestScope) (<-chan *domain.StreamResponse, error) {
 	modelID := c.findModelID(req.Model)
 	if modelID == "" {
 		return nil, errors.ValidationError("model not found", "model")
 	}
 
+	anthropicVersion, err := c.resolveAnthropicVersion(req)
+	if err != nil {
+		return nil, err
+	}
+
 	claudeReq := c.convertCompletionRequest(req)
+	claudeReq.AnthropicVersion = anthropicVersion
 	claudeReq.Stream = true
 	
 	body, err := json.Marshal(claudeReq)
@@ -269,11 +421,11 @@ func (c *AWSBedrockClient) CreateCompletionStream(ctx context.Context, req *doma
 		return nil, c.handleAWSError(err)
 	}
 
-	return c.processStreamResponse(result, req.Model), nil
+	return c.processStreamResponse(result, req.Model, scope), nil
 }
 
 func (c *AWSBedrockClient) CreateEmbeddings(ctx context.Context, req *domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
-	return nil, errors.InternalError("embeddings not supported by bedrock claude models", nil)
+	return nil, embeddingScope(req).attribute(errors.InternalError("embeddings not supported by bedrock claude models", nil))
 }
 
 func (c *AWSBedrockClient) ListModels(ctx context.Context) ([]domain.Model, error) {
@@ -285,33 +437,11 @@ func (c *AWSBedrockClient) HealthCheck(ctx context.Context) error {
 		return fmt.Errorf("no models configured")
 	}
 
-	modelID := c.findModelID(c.models[0].ModelID)
-	if modelID == "" {
+	modelConfig, ok := c.modelConfigs[c.models[0].ModelID]
+	if !ok {
 		return fmt.Errorf("invalid model configuration")
 	}
-
-	testReq := &claudeRequest{
-		AnthropicVersion: claudeAnthropicVersion,
-		MaxTokens:        1,
-		Messages: []claudeMessage{
-			{
-				Role:    "user",
-				Content: "test",
-			},
-		},
-	}
-
-	body, err := json.Marshal(testReq)
-	if err != nil {
-		return err
-	}
-
-	input := &bedrockruntime.InvokeModelInput{
-		ModelId:     aws.String(modelID),
-		ContentType: aws.String("application/json"),
-		Accept:      aws.String("application/json"),
-		Body:        body,
-	}
+	modelID := modelConfig.invocationID()
 
 	// Implement retry with exponential backoff for AWS Bedrock health check
 	maxRetries := 3
@@ -331,7 +461,7 @@ func (c *AWSBedrockClient) HealthCheck(ctx context.Context) error {
 		// Create a new timeout context for each attempt
 		attemptCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
 		
-		result, err := c.client.InvokeModel(attemptCtx, input)
+		err := c.sendHealthProbe(attemptCtx, modelConfig)
 		cancel()
 		
 		if err != nil {
@@ -370,30 +500,92 @@ func (c *AWSBedrockClient) HealthCheck(ctx context.Context) error {
 			continue // Retry on network errors, throttling, etc.
 		}
 
-		// Success - validate the response
-		if result != nil && len(result.Body) > 0 {
-			if attempt > 0 {
-				c.logger.Info("AWS Bedrock health check succeeded on retry",
-					logger.F("attempt", attempt+1),
-					logger.F("model", modelID),
-				)
-			}
-			return nil
+		if attempt > 0 {
+			c.logger.Info("AWS Bedrock health check succeeded on retry",
+				logger.F("attempt", attempt+1),
+				logger.F("model", modelID),
+			)
 		}
-		
-		// Unexpected empty response, but don't fail immediately
-		c.logger.Warn("AWS Bedrock returned empty response on health check",
-			logger.F("attempt", attempt+1),
-			logger.F("model", modelID),
-		)
+		return nil
 	}
 
 	return fmt.Errorf("bedrock health check failed after %d attempts", maxRetries)
 }
 
+// sendHealthProbe sends the configured health probe once. Only the
+// completion probe is billed; it asks for a single token and carries the
+// probe marker as its user ID.
+func (c *AWSBedrockClient) sendHealthProbe(ctx context.Context, modelConfig BedrockModelConfig) error {
+	probeReq := &claudeRequest{
+		AnthropicVersion: claudeAnthropicVersion,
+		MaxTokens:        1,
+		Messages:         []claudeMessage{{Role: "user", Content: "ping"}},
+		Metadata:         &claudeMetadata{UserID: healthProbeMarker},
+	}
+	body, err := json.Marshal(probeReq)
+	if err != nil {
+		return err
+	}
+
+	switch c.healthProbe {
+	case HealthProbeCountTokens:
+		// Tokens are counted for the foundation model, even when it is
+		// invoked through provisioned throughput
+		_, err := c.client.CountTokens(ctx, &bedrockruntime.CountTokensInput{
+			ModelId: aws.String(modelConfig.ModelID),
+			Input:   &bedrocktypes.CountTokensInputMemberInvokeModel{Value: bedrocktypes.InvokeModelTokensRequest{Body: body}},
+		})
+		return err
+
+	case HealthProbeCompletion:
+		result, err := c.client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+			ModelId:     aws.String(modelConfig.invocationID()),
+			ContentType: aws.String("application/json"),
+			Accept:      aws.String("application/json"),
+			Body:        body,
+		})
+		if err != nil {
+			return err
+		}
+		if len(result.Body) == 0 {
+			return fmt.Errorf("bedrock returned an empty health check response")
+		}
+		return nil
+
+	default:
+		_, err := c.client.ListAsyncInvokes(ctx, &bedrockruntime.ListAsyncInvokesInput{MaxResults: aws.Int32(1)})
+		return err
+	}
+}
+
+// WarmConnection lists a single async invocation, which is not billed. It
+// resolves and signs with the client's credentials and opens a connection,
+// so the first completion skips both. Unlike HealthCheck it does not retry.
+func (c *AWSBedrockClient) WarmConnection(ctx context.Context) error {
+	_, err := c.client.ListAsyncInvokes(ctx, &bedrockruntime.ListAsyncInvokesInput{MaxResults: aws.Int32(1)})
+	return err
+}
+
+// resolveAnthropicVersion returns the request's pinned anthropic_version, or
+// the Bedrock default when none is set
+func (c *AWSBedrockClient) resolveAnthropicVersion(req *domain.CompletionRequest) (string, error) {
+	if req.AnthropicVersion == "" {
+		return claudeAnthropicVersion, nil
+	}
+	if !c.allowedAnthropicVersions[req.AnthropicVersion] {
+		return "", errors.NewError(errors.ErrorTypeValidation, fmt.Sprintf("anthropic version %s is not allowed", req.AnthropicVersion)).
+			WithDetail("field", "anthropic_version").
+			WithDetail("provider", string(domain.ProviderAWSBedrock)).
+			Build()
+	}
+	return req.AnthropicVersion, nil
+}
+
 func (c *AWSBedrockClient) convertCompletionRequest(req *domain.CompletionRequest) *claudeRequest {
 	messages := []claudeMessage{}
-	systemMessage := ""
+	// Claude takes a single system prompt, so every system message is kept,
+	// in order, as its own paragraph
+	var systemMessages []string
 
 	for _, msg := range req.Messages {
 		content := ""
@@ -404,7 +596,9 @@ func (c *AWSBedrockClient) convertCompletionRequest(req *domain.CompletionReques
 		}
 
 		if msg.Role == domain.MessageRoleSystem {
-			systemMessage = content
+			if content != "" {
+				systemMessages = append(systemMessages, content)
+			}
 		} else {
 			role := string(msg.Role)
 			if role == "assistant" {
@@ -420,6 +614,13 @@ func (c *AWSBedrockClient) convertCompletionRequest(req *domain.CompletionReques
 		}
 	}
 
+	// A trailing assistant message is continued as a partial turn, which
+	// Anthropic rejects if it ends in whitespace
+	if _, ok := req.Prefill(); ok && len(messages) > 0 {
+		last := &messages[len(messages)-1]
+		last.Content = strings.TrimRightFunc(last.Content, unicode.IsSpace)
+	}
+
 	maxTokens := 4096
 	if req.MaxTokens != nil {
 		maxTokens = *req.MaxTokens
@@ -434,41 +635,78 @@ func (c *AWSBedrockClient) convertCompletionRequest(req *domain.CompletionReques
 		Stop:             req.Stop,
 	}
 
-	if systemMessage != "" {
-		claudeReq.System = systemMessage
+	if len(systemMessages) > 0 {
+		claudeReq.System = strings.Join(systemMessages, "\n\n")
 	}
 
 	return claudeReq
 }
 
-func (c *AWSBedrockClient) convertCompletionResponse(claudeResp *claudeResponse, modelID string) *domain.CompletionResponse {
+func (c *AWSBedrockClient) convertCompletionResponse(claudeResp *claudeResponse, modelID string, log logger.Logger) (*domain.CompletionResponse, error) {
+	if len(claudeResp.Content) == 0 && claudeResp.StopReason == "" {
+		providerErr := errors.ProviderError("bedrock", "bedrock returned no content", nil)
+		providerErr.Details["model"] = modelID
+		providerErr.Details["response_id"] = claudeResp.ID
+		return nil, providerErr
+	}
+
 	content := ""
-	if len(claudeResp.Content) > 0 {
-		content = claudeResp.Content[0].Text
+	parts := []domain.ContentPart{}
+	var toolCalls []domain.ToolCall
+	thinkingChars := 0
+	for _, block := range claudeResp.Content {
+		switch block.Type {
+		case "tool_use":
+			arguments := string(block.Input)
+			if arguments == "" {
+				arguments = "{}"
+			}
+			toolCalls = append(toolCalls, domain.ToolCall{
+				ID:   block.ID,
+				Type: "function",
+				Function: domain.FunctionCall{
+					Name:      block.Name,
+					Arguments: arguments,
+				},
+			})
+		case "thinking":
+			// Extended thinking traces are surfaced as reasoning parts
+			parts = append(parts, domain.ContentPart{
+				Type: domain.ContentTypeReasoning,
+				Text: block.Thinking,
+			})
+			thinkingChars += len([]rune(block.Thinking))
+		case "text", "":
+			content += block.Text
+		}
 	}
 
 	message := domain.Message{
 		Role: domain.MessageRoleAssistant,
-		Content: []domain.ContentPart{
-			{
-				Type: domain.ContentTypeText,
-				Text: content,
-			},
-		},
+		Content: append(parts, domain.ContentPart{
+			Type: domain.ContentTypeText,
+			Text: content,
+		}),
+		ToolCalls: toolCalls,
 	}
 
 	choice := domain.Choice{
 		Index:        0,
+		ID:           domain.ChoiceID(claudeResp.ID, 0),
 		Message:      message,
 		FinishReason: c.convertFinishReason(claudeResp.StopReason),
 	}
+	choice.NormalizeToolCalls()
 
-	usage := domain.Usage{
-		PromptTokens:     claudeResp.Usage.InputTokens,
-		CompletionTokens: claudeResp.Usage.OutputTokens,
-		TotalTokens:      claudeResp.Usage.InputTokens + claudeResp.Usage.OutputTokens,
-		CostUSD:          c.calculateCost(c.findModelID(modelID), claudeResp.Usage),
+	usage := domain.Usage{}
+	if claudeResp.Usage != nil {
+		usage.PromptTokens = claudeResp.Usage.InputTokens
+		usage.CompletionTokens = claudeResp.Usage.OutputTokens
+		usage.TotalTokens = claudeResp.Usage.InputTokens + claudeResp.Usage.OutputTokens
+		usage.ReasoningTokens = estimateThinkingTokens(thinkingChars, claudeResp.Usage.OutputTokens)
 	}
+	checkUsage(log, domain.ProviderAWSBedrock, modelID, &usage, claudeResp.Usage == nil)
+	usage.CostUSD = c.modelCost(modelID, usage)
 
 	return &domain.CompletionResponse{
 		ID:       claudeResp.ID,
@@ -478,11 +716,14 @@ func (c *AWSBedrockClient) convertCompletionResponse(claudeResp *claudeResponse,
 		Provider: domain.ProviderAWSBedrock,
 		Choices:  []domain.Choice{choice},
 		Usage:    usage,
-	}
+	}, nil
 }
 
-func (c *AWSBedrockClient) processStreamResponse(stream *bedrockruntime.InvokeModelWithResponseStreamOutput, modelID string) <-chan *domain.StreamResponse {
+func (c *AWSBedrockClient) processStreamResponse(stream *bedrockruntime.InvokeModelWithResponseStreamOutput, modelID string, scope requestScope) <-chan *domain.StreamResponse {
 	ch := make(chan *domain.StreamResponse)
+	requestID, _ := awsmiddleware.GetRequestIDMetadata(stream.ResultMetadata)
+	// Every chunk carries the same ID, so the choice keeps one ID throughout
+	streamID := uuid.New().String()
 
 	go func() {
 		defer close(ch)
@@ -493,44 +734,81 @@ func (c *AWSBedrockClient) processStreamResponse(stream *bedrockruntime.InvokeMo
 				var streamResp claudeStreamResponse
 				if err := json.Unmarshal(v.Value.Bytes, &streamResp); err != nil {
 					ch <- &domain.StreamResponse{
-						Error: errors.ProviderError("bedrock", "failed to parse stream response", err),
+						Error:             scope.attributeStreamError(errors.ProviderError("bedrock", "failed to parse stream response", err)),
+						ProviderRequestID: requestID,
 					}
 					return
 				}
 
 				if streamResp.Type == "content_block_delta" && streamResp.Delta != nil {
+					part := domain.ContentPart{
+						Type: domain.ContentTypeText,
+						Text: streamResp.Delta.Text,
+					}
+					if streamResp.Delta.Type == "thinking_delta" {
+						part = domain.ContentPart{
+							Type: domain.ContentTypeReasoning,
+							Text: streamResp.Delta.Thinking,
+						}
+					}
+
 					message := domain.Message{
-						Role: domain.MessageRoleAssistant,
-						Content: []domain.ContentPart{
-							{
-								Type: domain.ContentTypeText,
-								Text: streamResp.Delta.Text,
-							},
-						},
+						Role:    domain.MessageRoleAssistant,
+						Content: []domain.ContentPart{part},
 					}
 
+					// Claude returns a single choice; the event's index is
+					// its content block's, not a choice's
 					choice := domain.Choice{
-						Index:   streamResp.Index,
+						Index:   0,
+						ID:      domain.ChoiceID(streamID, 0),
 						Message: message,
 					}
 
 					ch <- &domain.StreamResponse{
-						ID:       uuid.New().String(),
+						ID:                streamID,
+						Object:            "chat.completion.chunk",
+						Created:           time.Now().Unix(),
+						Model:             modelID,
+						Provider:          domain.ProviderAWSBedrock,
+						Choices:           []domain.Choice{choice},
+						ProviderRequestID: requestID,
+					}
+				} else if streamResp.Type == "message_delta" && streamResp.Delta != nil && streamResp.Delta.StopReason != "" {
+					// The stop reason arrives after the content, in an event of its own
+					ch <- &domain.StreamResponse{
+						ID:       streamID,
 						Object:   "chat.completion.chunk",
 						Created:  time.Now().Unix(),
 						Model:    modelID,
 						Provider: domain.ProviderAWSBedrock,
-						Choices:  []domain.Choice{choice},
+						Choices: []domain.Choice{{
+							ID:           domain.ChoiceID(streamID, 0),
+							Message:      domain.Message{Role: domain.MessageRoleAssistant},
+							FinishReason: c.convertFinishReason(streamResp.Delta.StopReason),
+						}},
+						ProviderRequestID: requestID,
 					}
 				} else if streamResp.Type == "message_stop" {
-					ch <- &domain.StreamResponse{Done: true}
+					done := &domain.StreamResponse{Done: true, ProviderRequestID: requestID}
+					if metrics := streamResp.InvocationMetrics; metrics != nil {
+						usage := domain.Usage{
+							PromptTokens:     metrics.InputTokenCount,
+							CompletionTokens: metrics.OutputTokenCount,
+							TotalTokens:      metrics.InputTokenCount + metrics.OutputTokenCount,
+						}
+						usage.CostUSD = c.modelCost(modelID, usage)
+						done.Usage = &usage
+					}
+					ch <- done
 					return
 				}
 
 			default:
 				// Handle error cases - stream ended or error occurred
 				ch <- &domain.StreamResponse{
-					Error: errors.ProviderError("bedrock", "stream processing error", nil),
+					Error:             scope.attributeStreamError(errors.ProviderError("bedrock", "stream processing error", nil)),
+					ProviderRequestID: requestID,
 				}
 				return
 			}
@@ -540,13 +818,14 @@ func (c *AWSBedrockClient) processStreamResponse(stream *bedrockruntime.InvokeMo
 	return ch
 }
 
+// findModelID returns the Bedrock ModelId to invoke for a local model ID,
+// or "" if the model is not configured
 func (c *AWSBedrockClient) findModelID(localID string) string {
-	for _, model := range c.models {
-		if model.ModelID == localID {
-			return strings.Replace(model.Description, "AWS Bedrock ", "", 1)
-		}
+	modelConfig, ok := c.modelConfigs[localID]
+	if !ok {
+		return ""
 	}
-	return ""
+	return modelConfig.invocationID()
 }
 
 func (c *AWSBedrockClient) convertFinishReason(stopReason string) domain.FinishReason {
@@ -557,21 +836,56 @@ func (c *AWSBedrockClient) convertFinishReason(stopReason string) domain.FinishR
 		return domain.FinishReasonLength
 	case "stop_sequence":
 		return domain.FinishReasonStop
+	case "refusal":
+		return domain.FinishReasonRefusal
+	case "tool_use":
+		return domain.FinishReasonToolCalls
 	default:
 		return domain.FinishReasonStop
 	}
 }
 
-func (c *AWSBedrockClient) calculateCost(modelID string, usage claudeUsage) float64 {
+// modelCost prices a completion of a local model. Provisioned models and
+// models with configured pricing use the registry price; on-demand models
+// use the published per-token price of their foundation model.
+func (c *AWSBedrockClient) modelCost(localID string, usage domain.Usage) float64 {
+	modelConfig, ok := c.modelConfigs[localID]
+	if !ok {
+		return 0
+	}
+	if modelConfig.ProvisionedThroughputARN == "" && modelConfig.Pricing == nil {
+		return c.calculateCost(modelConfig.ModelID, usage)
+	}
+
+	for _, model := range c.models {
+		if model.ModelID == localID {
+			return model.Pricing.CompletionCost(usage)
+		}
+	}
+	return 0
+}
+
+func (c *AWSBedrockClient) calculateCost(modelID string, usage domain.Usage) float64 {
 	pricing, exists := bedrockModelPricing[modelID]
 	if !exists {
 		return 0
 	}
 
-	inputCost := float64(usage.InputTokens) * pricing.InputTokenCost
-	outputCost := float64(usage.OutputTokens) * pricing.OutputTokenCost
+	return pricing.CompletionCost(usage)
+}
 
-	return inputCost + outputCost
+// estimateThinkingTokens approximates the thinking share of output tokens.
+// Claude bills thinking as output but does not report it separately, so the
+// trace length is estimated as text, capped at the output total.
+func estimateThinkingTokens(thinkingChars, outputTokens int) int {
+	if thinkingChars == 0 {
+		return 0
+	}
+	tokens := domain.EstimateTextTokens(thinkingChars)
+	if tokens > outputTokens {
+		return outputTokens
+	}
+	return tokens
 }
 
 func (c *AWSBedrockClient) handleAWSError(err error) error {
@@ -579,10 +893,34 @@ func (c *AWSBedrockClient) handleAWSError(err error) error {
 		return nil
 	}
 
+	// Failed calls still carry the HTTP status and the request ID AWS assigned them
+	var respErr *awshttp.ResponseError
+	if goerrors.As(err, &respErr) {
+		return withProviderStatus(withProviderRequestID(c.classifyAWSError(err), respErr.ServiceRequestID()), respErr.HTTPStatusCode())
+	}
+	return c.classifyAWSError(err)
+}
+
+// classifyAWSError maps an AWS SDK error to a QLens error
+func (c *AWSBedrockClient) classifyAWSError(err error) error {
+
+	// Modeled API errors carry a stable code and a message worth showing
+	// the caller, so map those before falling back to string matching
+	var apiErr smithy.APIError
+	if goerrors.As(err, &apiErr) {
+		if mapped := bedrockAPIError(apiErr, err); mapped != nil {
+			return mapped.WithProviderDetails("", apiErr.ErrorCode(), "")
+		}
+	}
+
 	errStr := err.Error()
 	
 	if strings.Contains(errStr, "throttling") || strings.Contains(errStr, "rate") {
-		return errors.InternalError("aws bedrock rate limit exceeded", nil)
+		return errors.NewError(errors.ErrorTypeTooManyRequests, "aws bedrock rate limit exceeded").
+			WithDetail("provider", "aws-bedrock").
+			WithInternal(err).
+			WithRetryable(true).
+			Build()
 	}
 	
 	if strings.Contains(errStr, "unauthorized") || strings.Contains(errStr, "access denied") {
@@ -594,4 +932,33 @@ func (c *AWSBedrockClient) handleAWSError(err error) error {
 	}
 
 	return errors.ProviderError("bedrock", "aws bedrock error: " + errStr, err)
-}
\ No newline at end of file
+}
+
+// bedrockAPIError maps a Bedrock error code to a QLens error, or returns nil
+// for codes without a specific mapping
+func bedrockAPIError(apiErr smithy.APIError, err error) *errors.QLensError {
+	message := apiErr.ErrorMessage()
+
+	switch apiErr.ErrorCode() {
+	case "ThrottlingException", "ServiceQuotaExceededException":
+		if message == "" {
+			message = "aws bedrock rate limit exceeded"
+		}
+		return errors.NewError(errors.ErrorTypeTooManyRequests, message).
+			WithDetail("provider", "aws-bedrock").
+			WithInternal(err).
+			WithRetryable(true).
+			Build()
+	case "AccessDeniedException", "UnrecognizedClientException":
+		if message == "" {
+			message = "aws bedrock authentication failed"
+		}
+		return errors.AuthenticationError(message)
+	case "ValidationException":
+		if message == "" {
+			message = "aws bedrock validation error"
+		}
+		return errors.ValidationError(message, "request")
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+const testProvisionedARN = "arn:aws:bedrock:us-east-1:123456789012:provisioned-model/abc123"
+
+// newProvisionedTestClient serves claude-3-sonnet on demand and
+// claude-3-haiku through provisioned throughput
+func newProvisionedTestClient(haikuPricing *domain.ModelPricing) *AWSBedrockClient {
+	modelConfigs := []BedrockModelConfig{
+		{ID: "claude-3-sonnet", ModelID: "anthropic.claude-3-sonnet-20240229-v1:0", Name: "Claude 3 Sonnet"},
+		{
+			ID:                       "claude-3-haiku",
+			ModelID:                  "anthropic.claude-3-haiku-20240307-v1:0",
+			Name:                     "Claude 3 Haiku",
+			ProvisionedThroughputARN: testProvisionedARN,
+			Pricing:                  haikuPricing,
+		},
+	}
+
+	client := &AWSBedrockClient{
+		models:       generateBedrockModelList(modelConfigs),
+		modelConfigs: make(map[string]BedrockModelConfig),
+	}
+	for _, modelConfig := range modelConfigs {
+		client.modelConfigs[modelConfig.ID] = modelConfig
+	}
+	return client
+}
+
+func TestBedrockProvisionedThroughput_ModelList(t *testing.T) {
+	client := newProvisionedTestClient(nil)
+	require.Len(t, client.models, 2)
+
+	onDemand, provisioned := client.models[0], client.models[1]
+	assert.Equal(t, domain.ModelDeploymentOnDemand, onDemand.Deployment)
+	assert.Greater(t, onDemand.Pricing.InputTokenCost, 0.0)
+
+	assert.Equal(t, domain.ModelDeploymentProvisioned, provisioned.Deployment)
+	assert.Zero(t, provisioned.Pricing.InputTokenCost)
+	assert.Zero(t, provisioned.Pricing.OutputTokenCost)
+	// Capabilities still come from the foundation model
+	assert.Contains(t, provisioned.Capabilities, domain.CapabilityVision)
+}
+
+func TestBedrockProvisionedThroughput_InvokesARN(t *testing.T) {
+	client := newProvisionedTestClient(nil)
+
+	assert.Equal(t, "anthropic.claude-3-sonnet-20240229-v1:0", client.findModelID("claude-3-sonnet"))
+	assert.Equal(t, testProvisionedARN, client.findModelID("claude-3-haiku"))
+	assert.Empty(t, client.findModelID("unknown"))
+}
+
+func TestBedrockProvisionedThroughput_Cost(t *testing.T) {
+	usage := domain.Usage{PromptTokens: 1000, CompletionTokens: 500}
+
+	client := newProvisionedTestClient(nil)
+	assert.Greater(t, client.modelCost("claude-3-sonnet", usage), 0.0)
+	assert.Zero(t, client.modelCost("claude-3-haiku", usage))
+
+	// An amortized per-token price can be configured instead
+	client = newProvisionedTestClient(&domain.ModelPricing{InputTokenCost: 0.000001, OutputTokenCost: 0.000002, Unit: "token"})
+	assert.InDelta(t, 0.001+0.001, client.modelCost("claude-3-haiku", usage), 1e-9)
+}
+
+func TestNewAWSBedrockClient_RejectsInvalidProvisionedARN(t *testing.T) {
+	_, err := NewAWSBedrockClient(AWSBedrockConfig{
+		Region: "us-east-1",
+		Models: []BedrockModelConfig{{ID: "claude-3-haiku", ModelID: "anthropic.claude-3-haiku-20240307-v1:0", ProvisionedThroughputARN: "abc123"}},
+	}, logger.NewNoop())
+
+	require.Error(t, err)
+	assert.True(t, errors.IsType(err, errors.ErrorTypeConfiguration))
+}
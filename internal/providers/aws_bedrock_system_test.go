@@ -0,0 +1,30 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+func TestBedrockConvertCompletionRequest_MergesSystemMessages(t *testing.T) {
+	client := &AWSBedrockClient{}
+	text := func(role domain.MessageRole, text string) domain.Message {
+		return domain.Message{Role: role, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: text}}}
+	}
+
+	claudeReq := client.convertCompletionRequest(&domain.CompletionRequest{
+		Model: "claude-3-sonnet",
+		Messages: []domain.Message{
+			text(domain.MessageRoleSystem, "You are a helpful assistant."),
+			text(domain.MessageRoleSystem, "Answer in French."),
+			text(domain.MessageRoleUser, "Hello"),
+		},
+	})
+
+	assert.Equal(t, "You are a helpful assistant.\n\nAnswer in French.", claudeReq.System)
+	require.Len(t, claudeReq.Messages, 1)
+	assert.Equal(t, "user", claudeReq.Messages[0].Role)
+}
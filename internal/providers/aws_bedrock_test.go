@@ -5,7 +5,6 @@ import (
 	"testing"
 
 	"github.com/quantum-suite/platform/internal/domain"
-	"github.com/quantum-suite/platform/internal/services/router"
 	"github.com/quantum-suite/platform/pkg/shared/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -119,7 +118,7 @@ func TestBedrockConvertCompletionRequest(t *testing.T) {
 	}
 	require.NoError(t, err)
 
-	req := &router.CompletionRequest{
+	req := &domain.CompletionRequest{
 		Model: "claude-3-sonnet",
 		Messages: []domain.Message{
 			{
@@ -236,9 +235,9 @@ func TestBedrockCalculateCost(t *testing.T) {
 	}
 	require.NoError(t, err)
 
-	usage := claudeUsage{
-		InputTokens:  1000,
-		OutputTokens: 500,
+	usage := domain.Usage{
+		PromptTokens:     1000,
+		CompletionTokens: 500,
 	}
 
 	cost := client.calculateCost("anthropic.claude-3-sonnet-20240229-v1:0", usage)
@@ -304,7 +303,7 @@ func TestBedrockCreateEmbeddings(t *testing.T) {
 	}
 	require.NoError(t, err)
 
-	req := &router.EmbeddingRequest{
+	req := &domain.EmbeddingRequest{
 		Model: "claude-3-sonnet",
 		Input: []string{"test input"},
 	}
@@ -0,0 +1,142 @@
+package providers
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// azureDeploymentCooldown is how long a deployment that throttled or ran
+// out of capacity is tried only after its healthy siblings
+const azureDeploymentCooldown = 30 * time.Second
+
+var azureDeploymentFailovers = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_provider_azure_deployment_failovers_total",
+		Help: "Azure OpenAI requests moved off a deployment that throttled or ran out of capacity",
+	},
+	[]string{"model", "deployment"},
+)
+
+// azureDeploymentPool spreads the requests for a model over the Azure
+// deployments serving it. Deployments have independent capacity, so one
+// that throttles is put on cooldown and the request moves to the next.
+type azureDeploymentPool struct {
+	deployments map[string][]string
+
+	mu        sync.Mutex
+	next      map[string]int
+	coolUntil map[string]time.Time
+	now       func() time.Time
+}
+
+// newAzureDeploymentPool returns the pool for the configured failover
+// deployments, or nil when no model has any
+func newAzureDeploymentPool(deployments map[string][]string) *azureDeploymentPool {
+	pools := make(map[string][]string)
+	for model, names := range deployments {
+		if len(names) > 0 {
+			pools[model] = append([]string(nil), names...)
+		}
+	}
+	if len(pools) == 0 {
+		return nil
+	}
+	return &azureDeploymentPool{
+		deployments: pools,
+		next:        make(map[string]int),
+		coolUntil:   make(map[string]time.Time),
+		now:         time.Now,
+	}
+}
+
+// order returns the deployments to try for a model: those not cooling down
+// in round-robin order, then those cooling down, soonest recovered first. A
+// model without failover deployments is its own deployment.
+func (p *azureDeploymentPool) order(model string) []string {
+	if p == nil || len(p.deployments[model]) == 0 {
+		return []string{model}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	names := p.deployments[model]
+	start := p.next[model] % len(names)
+	p.next[model] = start + 1
+
+	now := p.now()
+	var healthy, cooling []string
+	for i := range names {
+		name := names[(start+i)%len(names)]
+		if now.Before(p.coolUntil[name]) {
+			cooling = append(cooling, name)
+		} else {
+			healthy = append(healthy, name)
+		}
+	}
+	sort.SliceStable(cooling, func(i, j int) bool { return p.coolUntil[cooling[i]].Before(p.coolUntil[cooling[j]]) })
+	return append(healthy, cooling...)
+}
+
+// cool puts a deployment on cooldown
+func (p *azureDeploymentPool) cool(deployment string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.coolUntil[deployment] = p.now().Add(azureDeploymentCooldown)
+}
+
+// restore takes a deployment that answered off cooldown
+func (p *azureDeploymentPool) restore(deployment string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.coolUntil, deployment)
+}
+
+// each calls fn with the deployments for a model until one succeeds. Only
+// throttling and capacity errors move on to the next deployment; any other
+// error, or the last deployment's, is returned.
+func (p *azureDeploymentPool) each(model string, log logger.Logger, fn func(deployment string) error) error {
+	deployments := p.order(model)
+	var err error
+	for i, deployment := range deployments {
+		if err = fn(deployment); err == nil {
+			if p != nil {
+				p.restore(deployment)
+			}
+			return nil
+		}
+		if p == nil || !isAzureCapacityError(err) {
+			return err
+		}
+		p.cool(deployment)
+		if i == len(deployments)-1 {
+			break
+		}
+		azureDeploymentFailovers.WithLabelValues(model, deployment).Inc()
+		if log != nil {
+			log.Warn("Azure OpenAI deployment out of capacity, trying the next",
+				logger.F("model", model),
+				logger.F("deployment", deployment),
+				logger.F("next_deployment", deployments[i+1]),
+				logger.F("error", err))
+		}
+	}
+	return err
+}
+
+// isAzureCapacityError reports whether a deployment failed for lack of
+// capacity rather than because of the request
+func isAzureCapacityError(err error) bool {
+	qlensErr := errors.FromError(err)
+	switch qlensErr.ProviderStatus {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+		return true
+	}
+	return qlensErr.Type == errors.ErrorTypeTooManyRequests
+}
@@ -0,0 +1,119 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// azureDeploymentServer answers completions per deployment with the given
+// status and records the deployments called
+func azureDeploymentServer(t *testing.T, statuses map[string]int) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var calls []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deployment := strings.Split(strings.TrimPrefix(r.URL.Path, "/openai/deployments/"), "/")[0]
+		mu.Lock()
+		calls = append(calls, deployment)
+		status := statuses[deployment]
+		mu.Unlock()
+
+		if status != 0 && status != http.StatusOK {
+			w.WriteHeader(status)
+			w.Write([]byte(`{"error":{"message":"deployment unavailable","code":"429"}}`))
+			return
+		}
+		w.Write([]byte(`{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		called := calls
+		calls = nil
+		return called
+	}
+}
+
+func newFailoverTestClient(t *testing.T, endpoint string) *AzureOpenAIClient {
+	client, err := NewAzureOpenAIClient(AzureOpenAIConfig{
+		Endpoint:           endpoint,
+		APIKey:             "secret",
+		Deployments:        map[string]string{"gpt-4o": "gpt-4o"},
+		DeploymentFailover: map[string][]string{"gpt-4o": {"gpt-4o-east", "gpt-4o-west"}},
+	}, logger.NewNoop())
+	require.NoError(t, err)
+	return client
+}
+
+func failoverTestRequest() *domain.CompletionRequest {
+	return &domain.CompletionRequest{
+		Model:    "gpt-4o",
+		Messages: []domain.Message{{Role: domain.MessageRoleUser, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "hello"}}}},
+	}
+}
+
+func TestAzureOpenAI_DeploymentFailoverOnThrottling(t *testing.T) {
+	statuses := map[string]int{"gpt-4o-east": http.StatusTooManyRequests}
+	server, calls := azureDeploymentServer(t, statuses)
+	client := newFailoverTestClient(t, server.URL)
+
+	response, err := client.CreateCompletion(context.Background(), failoverTestRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4o", response.Model)
+	assert.Equal(t, []string{"gpt-4o-east", "gpt-4o-west"}, calls())
+
+	// The throttled deployment cools down, so it is not tried first while
+	// its sibling answers
+	for i := 0; i < 2; i++ {
+		_, err = client.CreateCompletion(context.Background(), failoverTestRequest())
+		require.NoError(t, err)
+		assert.Equal(t, []string{"gpt-4o-west"}, calls())
+	}
+
+	// Once the cooldown is over requests rotate across both again
+	client.deploymentPool.now = func() time.Time { return time.Now().Add(2 * azureDeploymentCooldown) }
+	statuses["gpt-4o-east"] = http.StatusOK
+	var served []string
+	for i := 0; i < 2; i++ {
+		_, err = client.CreateCompletion(context.Background(), failoverTestRequest())
+		require.NoError(t, err)
+		served = append(served, calls()...)
+	}
+	assert.ElementsMatch(t, []string{"gpt-4o-east", "gpt-4o-west"}, served)
+}
+
+func TestAzureOpenAI_DeploymentFailoverOnlyForCapacityErrors(t *testing.T) {
+	server, calls := azureDeploymentServer(t, map[string]int{"gpt-4o-east": http.StatusBadRequest, "gpt-4o-west": http.StatusBadRequest})
+	client := newFailoverTestClient(t, server.URL)
+
+	_, err := client.CreateCompletion(context.Background(), failoverTestRequest())
+	require.Error(t, err)
+	assert.Len(t, calls(), 1)
+}
+
+func TestAzureOpenAI_DeploymentFailoverReturnsLastError(t *testing.T) {
+	server, calls := azureDeploymentServer(t, map[string]int{"gpt-4o-east": http.StatusServiceUnavailable, "gpt-4o-west": http.StatusTooManyRequests})
+	client := newFailoverTestClient(t, server.URL)
+
+	_, err := client.CreateCompletion(context.Background(), failoverTestRequest())
+	require.Error(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, errors.FromError(err).ProviderStatus)
+	assert.Len(t, calls(), 2)
+
+	// Models without failover deployments are their own deployment
+	_, err = client.CreateCompletion(context.Background(), &domain.CompletionRequest{Model: "gpt-4"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt-4"}, calls())
+}
@@ -1,6 +1,7 @@
 package providers
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"crypto/tls"
@@ -19,12 +20,18 @@ import (
 )
 
 type AzureOpenAIClient struct {
-	endpoint   string
-	apiKey     string
-	apiVersion string
-	httpClient *http.Client
-	logger     logger.Logger
-	models     []domain.Model
+	endpoint           string
+	apiKey             string
+	apiVersion         string
+	allowedAPIVersions map[string]bool
+	deployments        map[string]string
+	userAgent          string
+	customHeaders      map[string]string
+	httpClient         *http.Client
+	logger             logger.Logger
+	models             []domain.Model
+	healthProbe        HealthProbe
+	deploymentPool     *azureDeploymentPool
 }
 
 type AzureOpenAIConfig struct {
@@ -32,24 +39,69 @@ type AzureOpenAIConfig struct {
 	APIKey      string            `json:"api_key"`
 	APIVersion  string            `json:"api_version"`
 	Deployments map[string]string `json:"deployments"`
+
+	// DeploymentFailover lists, per model, the deployments serving it.
+	// Requests rotate among them and move to the next when one throttles or
+	// runs out of capacity.
+	DeploymentFailover map[string][]string `json:"deployment_failover,omitempty"`
+
+	// AllowedAPIVersions lists the versions a request may pin; the default
+	// API version is always allowed
+	AllowedAPIVersions []string `json:"allowed_api_versions,omitempty"`
+
+	// UserAgent overrides the default QLens/<version> user agent
+	UserAgent string `json:"user_agent,omitempty"`
+
+	// CustomHeaders are sent with every request, e.g. for enterprise proxies.
+	// They may not replace the api-key, Authorization or Content-Type headers.
+	CustomHeaders map[string]string `json:"custom_headers,omitempty"`
+
+	// HealthProbe is the request HealthCheck sends; defaults to
+	// HealthProbeList, which is not billed
+	HealthProbe HealthProbe `json:"health_probe,omitempty"`
 }
 
 type azureOpenAIRequest struct {
-	Model            string                 `json:"model,omitempty"`
-	Messages         []azureOpenAIMessage   `json:"messages"`
-	MaxTokens        *int                   `json:"max_tokens,omitempty"`
-	Temperature      *float64               `json:"temperature,omitempty"`
-	TopP             *float64               `json:"top_p,omitempty"`
-	Stop             []string               `json:"stop,omitempty"`
-	PresencePenalty  *float64               `json:"presence_penalty,omitempty"`
-	FrequencyPenalty *float64               `json:"frequency_penalty,omitempty"`
-	User             string                 `json:"user,omitempty"`
-	Stream           bool                   `json:"stream"`
+	Model               string                    `json:"model,omitempty"`
+	Messages            []azureOpenAIMessage      `json:"messages"`
+	MaxTokens           *int                      `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int                      `json:"max_completion_tokens,omitempty"`
+	Temperature         *float64                  `json:"temperature,omitempty"`
+	TopP                *float64                  `json:"top_p,omitempty"`
+	Stop                []string                  `json:"stop,omitempty"`
+	PresencePenalty     *float64                  `json:"presence_penalty,omitempty"`
+	FrequencyPenalty    *float64                  `json:"frequency_penalty,omitempty"`
+	User                string                    `json:"user,omitempty"`
+	Stream              bool                      `json:"stream"`
+	ResponseFormat      *domain.ResponseFormat    `json:"response_format,omitempty"`
+	Tools               []domain.Tool             `json:"tools,omitempty"`
+	StreamOptions       *azureOpenAIStreamOptions `json:"stream_options,omitempty"`
+	Store               *bool                     `json:"store,omitempty"`
+	Metadata            map[string]string         `json:"metadata,omitempty"`
 }
 
+// azureOpenAIStreamOptions asks for the stream's usage in a final chunk
+type azureOpenAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// azurePrefillInstruction emulates an assistant prefill. Unlike a native
+// prefill, the response includes the prefilled text.
+const azurePrefillInstruction = "Begin your response with exactly the following text and continue from it, without repeating or commenting on it:\n\n%s"
+
+// azureStreamUsageAPIVersion is the first API version that accepts
+// stream_options; older versions reject the request
+const azureStreamUsageAPIVersion = "2024-09-01"
+
+// azureStoredCompletionsAPIVersion is the first API version that accepts
+// store and metadata; older versions reject the request
+const azureStoredCompletionsAPIVersion = "2024-10-01"
+
 type azureOpenAIMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role      string            `json:"role"`
+	Content   string            `json:"content"`
+	Refusal   string            `json:"refusal,omitempty"`
+	ToolCalls []domain.ToolCall `json:"tool_calls,omitempty"`
 }
 
 type azureOpenAIResponse struct {
@@ -58,7 +110,7 @@ type azureOpenAIResponse struct {
 	Created int64                 `json:"created"`
 	Model   string                `json:"model"`
 	Choices []azureOpenAIChoice   `json:"choices"`
-	Usage   azureOpenAIUsage      `json:"usage"`
+	Usage   *azureOpenAIUsage     `json:"usage"`
 	Error   *azureOpenAIError     `json:"error,omitempty"`
 }
 
@@ -70,17 +122,28 @@ type azureOpenAIChoice struct {
 }
 
 type azureOpenAIUsage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens            int                      `json:"prompt_tokens"`
+	CompletionTokens        int                      `json:"completion_tokens"`
+	TotalTokens             int                      `json:"total_tokens"`
+	CompletionTokensDetails *azureOpenAITokenDetails `json:"completion_tokens_details,omitempty"`
+}
+
+type azureOpenAITokenDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
 }
 
 type azureOpenAIError struct {
 	Type    string `json:"type"`
 	Code    string `json:"code"`
+	Param   string `json:"param"`
 	Message string `json:"message"`
 }
 
+// providerError converts an error object returned inside a response body
+func (e *azureOpenAIError) providerError() *errors.QLensError {
+	return errors.ProviderError("azure-openai", e.Message, nil).WithProviderDetails(e.Param, e.Code, e.Type)
+}
+
 type azureOpenAIEmbeddingRequest struct {
 	Input          []string `json:"input"`
 	Model          string   `json:"model"`
@@ -93,7 +156,7 @@ type azureOpenAIEmbeddingResponse struct {
 	Object string                     `json:"object"`
 	Data   []azureOpenAIEmbeddingData `json:"data"`
 	Model  string                     `json:"model"`
-	Usage  azureOpenAIUsage           `json:"usage"`
+	Usage  *azureOpenAIUsage          `json:"usage"`
 	Error  *azureOpenAIError          `json:"error,omitempty"`
 }
 
@@ -109,6 +172,18 @@ const (
 	azureOpenAITimeout           = 30 * time.Second
 )
 
+// azureOpenAIDefaultAllowedAPIVersions are the API versions requests may pin
+// when no explicit allowlist is configured
+var azureOpenAIDefaultAllowedAPIVersions = []string{
+	"2024-02-01",
+	"2024-02-15-preview",
+	"2024-06-01",
+	"2024-08-01-preview",
+	"2024-10-21",
+	"2024-12-01-preview",
+	"2025-01-01-preview",
+}
+
 var azureOpenAIModelPricing = map[string]domain.ModelPricing{
 	"gpt-4": {
 		InputTokenCost:  0.00003,
@@ -175,6 +250,10 @@ func NewAzureOpenAIClient(config AzureOpenAIConfig, logger logger.Logger) (*Azur
 		return nil, errors.ConfigurationError("azure openai endpoint and api key are required")
 	}
 
+	if err := validateCustomHeaders("azure openai", config.CustomHeaders, "api-key", "Authorization", "Content-Type"); err != nil {
+		return nil, err
+	}
+
 	// Create production-grade HTTP client with connection pooling and DNS caching
 	transport := &http.Transport{
 		DialContext: (&net.Dialer{
@@ -197,16 +276,36 @@ func NewAzureOpenAIClient(config AzureOpenAIConfig, logger logger.Logger) (*Azur
 		ForceAttemptHTTP2:     true,             // Prefer HTTP/2
 	}
 
+	healthProbe, err := parseHealthProbe("azure openai", config.HealthProbe, HealthProbeList, HealthProbeCompletion)
+	if err != nil {
+		return nil, err
+	}
+
+	allowedVersions := config.AllowedAPIVersions
+	if len(allowedVersions) == 0 {
+		allowedVersions = azureOpenAIDefaultAllowedAPIVersions
+	}
+	allowedAPIVersions := map[string]bool{config.APIVersion: true}
+	for _, version := range allowedVersions {
+		allowedAPIVersions[version] = true
+	}
+
 	client := &AzureOpenAIClient{
-		endpoint:   strings.TrimRight(config.Endpoint, "/"),
-		apiKey:     config.APIKey,
-		apiVersion: config.APIVersion,
+		endpoint:           strings.TrimRight(config.Endpoint, "/"),
+		apiKey:             config.APIKey,
+		apiVersion:         config.APIVersion,
+		allowedAPIVersions: allowedAPIVersions,
+		deployments:        config.Deployments,
+		userAgent:          resolveUserAgent(config.UserAgent),
+		customHeaders:      config.CustomHeaders,
 		httpClient: &http.Client{
 			Timeout:   azureOpenAITimeout,
 			Transport: transport,
 		},
-		logger: logger,
-		models: generateModelList(config.Deployments),
+		logger:      logger,
+		models:         generateModelList(config.Deployments),
+		healthProbe:    healthProbe,
+		deploymentPool: newAzureDeploymentPool(config.DeploymentFailover),
 	}
 
 	return client, nil
@@ -266,11 +365,33 @@ func generateModelList(deployments map[string]string) []domain.Model {
 	return models
 }
 
+// CreateCompletion sends a chat completion to the request's deployment
 func (c *AzureOpenAIClient) CreateCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+	scope := completionScope(req)
+	var response *domain.CompletionResponse
+	err := c.deploymentPool.each(req.Model, c.logger, func(deployment string) error {
+		var err error
+		response, err = c.createCompletion(ctx, req, deployment, scope)
+		return err
+	})
+	if err != nil {
+		scope.logger(c.logger).Warn("Azure OpenAI completion failed", logger.F("model", req.Model), logger.F("error", err))
+		return nil, scope.attribute(err)
+	}
+	return response, nil
+}
+
+func (c *AzureOpenAIClient) createCompletion(ctx context.Context, req *domain.CompletionRequest, deployment string, scope requestScope) (*domain.CompletionResponse, error) {
+	apiVersion, err := c.resolveAPIVersion(req)
+	if err != nil {
+		return nil, err
+	}
+
 	azureReq := c.convertCompletionRequest(req)
+	applyStoredCompletion(azureReq, req, apiVersion)
 	
 	url := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
-		c.endpoint, req.Model, c.apiVersion)
+		c.endpoint, deployment, apiVersion)
 
 	body, err := json.Marshal(azureReq)
 	if err != nil {
@@ -283,12 +404,14 @@ func (c *AzureOpenAIClient) CreateCompletion(ctx context.Context, req *domain.Co
 	}
 
 	c.setHeaders(httpReq)
+	setClientRequestID(httpReq, azureClientRequestIDHeader, req.RequestID)
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, errors.ProviderError("azure-openai", "azure openai request failed", err)
 	}
 	defer resp.Body.Close()
+	requestID := providerRequestID(resp.Header)
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -296,7 +419,7 @@ func (c *AzureOpenAIClient) CreateCompletion(ctx context.Context, req *domain.Co
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleHTTPError(resp.StatusCode, respBody)
+		return nil, withProviderRequestID(c.handleHTTPError(resp.StatusCode, respBody), requestID)
 	}
 
 	var azureResp azureOpenAIResponse
@@ -305,18 +428,54 @@ func (c *AzureOpenAIClient) CreateCompletion(ctx context.Context, req *domain.Co
 	}
 
 	if azureResp.Error != nil {
-		return nil, errors.ProviderError("azure-openai", azureResp.Error.Message, nil)
+		return nil, withProviderRequestID(azureResp.Error.providerError(), requestID)
 	}
 
-	return c.convertCompletionResponse(&azureResp, req.Model), nil
+	response, err := c.convertCompletionResponse(&azureResp, req.Model, scope.logger(c.logger))
+	if err != nil {
+		return nil, withProviderRequestID(err, requestID)
+	}
+	response.ProviderRequestID = requestID
+	if req.IncludeRawResponse {
+		response.AttachRawResponse(respBody)
+	}
+
+	return response, nil
 }
 
+// CreateCompletionStream opens a streaming chat completion. Errors in the
+// stream carry the same attribution as errors opening it.
 func (c *AzureOpenAIClient) CreateCompletionStream(ctx context.Context, req *domain.CompletionRequest) (<-chan *domain.StreamResponse, error) {
+	scope := completionScope(req)
+	var stream <-chan *domain.StreamResponse
+	err := c.deploymentPool.each(req.Model, c.logger, func(deployment string) error {
+		var err error
+		stream, err = c.createCompletionStream(ctx, req, deployment, scope)
+		return err
+	})
+	if err != nil {
+		scope.logger(c.logger).Warn("Azure OpenAI completion stream failed", logger.F("model", req.Model), logger.F("error", err))
+		return nil, scope.attribute(err)
+	}
+	return stream, nil
+}
+
+func (c *AzureOpenAIClient) createCompletionStream(ctx context.Context, req *domain.CompletionRequest, deployment string, scope requestScope) (<-chan *domain.StreamResponse, error) {
+	apiVersion, err := c.resolveAPIVersion(req)
+	if err != nil {
+		return nil, err
+	}
+
 	azureReq := c.convertCompletionRequest(req)
+	applyStoredCompletion(azureReq, req, apiVersion)
 	azureReq.Stream = true
+	// API versions are dates, so they compare as strings
+	if apiVersion >= azureStreamUsageAPIVersion {
+		azureReq.StreamOptions = &azureOpenAIStreamOptions{IncludeUsage: true}
+	}
 
 	url := fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
-		c.endpoint, req.Model, c.apiVersion)
+		c.endpoint, deployment, apiVersion)
 
 	body, err := json.Marshal(azureReq)
 	if err != nil {
@@ -329,6 +488,7 @@ func (c *AzureOpenAIClient) CreateCompletionStream(ctx context.Context, req *dom
 	}
 
 	c.setHeaders(httpReq)
+	setClientRequestID(httpReq, azureClientRequestIDHeader, req.RequestID)
 	httpReq.Header.Set("Accept", "text/event-stream")
 
 	resp, err := c.httpClient.Do(httpReq)
@@ -339,23 +499,48 @@ func (c *AzureOpenAIClient) CreateCompletionStream(ctx context.Context, req *dom
 	if resp.StatusCode != http.StatusOK {
 		defer resp.Body.Close()
 		respBody, _ := io.ReadAll(resp.Body)
-		return nil, c.handleHTTPError(resp.StatusCode, respBody)
+		return nil, withProviderRequestID(c.handleHTTPError(resp.StatusCode, respBody), providerRequestID(resp.Header))
+	}
+
+	// A JSON body in place of the event stream is an error
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, withProviderRequestID(c.handleHTTPError(resp.StatusCode, respBody), providerRequestID(resp.Header))
 	}
 
-	return c.processStreamResponse(resp, req.Model), nil
+	return c.processStreamResponse(resp, req.Model, scope, req.StreamPassthrough), nil
 }
 
+// CreateEmbeddings embeds the request's inputs with its deployment
 func (c *AzureOpenAIClient) CreateEmbeddings(ctx context.Context, req *domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	scope := embeddingScope(req)
+	var response *domain.EmbeddingResponse
+	err := c.deploymentPool.each(req.Model, c.logger, func(deployment string) error {
+		var err error
+		response, err = c.createEmbeddings(ctx, req, deployment, scope)
+		return err
+	})
+	if err != nil {
+		scope.logger(c.logger).Warn("Azure OpenAI embeddings failed", logger.F("model", req.Model), logger.F("error", err))
+		return nil, scope.attribute(err)
+	}
+	return response, nil
+}
+
+func (c *AzureOpenAIClient) createEmbeddings(ctx context.Context, req *domain.EmbeddingRequest, deployment string, scope requestScope) (*domain.EmbeddingResponse, error) {
 	azureReq := azureOpenAIEmbeddingRequest{
 		Input:          req.Input,
 		Model:          req.Model,
-		EncodingFormat: req.EncodingFormat,
+		// Vectors are always fetched as floats and encoded for the client
+		// in convertEmbeddingResponse
+		EncodingFormat: domain.EmbeddingEncodingFloat,
 		Dimensions:     req.Dimensions,
 		User:           req.User,
 	}
 
 	url := fmt.Sprintf("%s/openai/deployments/%s/embeddings?api-version=%s",
-		c.endpoint, req.Model, c.apiVersion)
+		c.endpoint, deployment, c.apiVersion)
 
 	body, err := json.Marshal(azureReq)
 	if err != nil {
@@ -368,12 +553,14 @@ func (c *AzureOpenAIClient) CreateEmbeddings(ctx context.Context, req *domain.Em
 	}
 
 	c.setHeaders(httpReq)
+	setClientRequestID(httpReq, azureClientRequestIDHeader, req.RequestID)
 
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return nil, errors.ProviderError("azure-openai", "azure openai embeddings request failed", err)
 	}
 	defer resp.Body.Close()
+	requestID := providerRequestID(resp.Header)
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -381,7 +568,7 @@ func (c *AzureOpenAIClient) CreateEmbeddings(ctx context.Context, req *domain.Em
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, c.handleHTTPError(resp.StatusCode, respBody)
+		return nil, withProviderRequestID(c.handleHTTPError(resp.StatusCode, respBody), requestID)
 	}
 
 	var azureResp azureOpenAIEmbeddingResponse
@@ -390,10 +577,15 @@ func (c *AzureOpenAIClient) CreateEmbeddings(ctx context.Context, req *domain.Em
 	}
 
 	if azureResp.Error != nil {
-		return nil, errors.ProviderError("azure-openai", azureResp.Error.Message, nil)
+		return nil, withProviderRequestID(azureResp.Error.providerError(), requestID)
 	}
 
-	return c.convertEmbeddingResponse(&azureResp), nil
+	response, err := c.convertEmbeddingResponse(&azureResp, len(req.Input), req.EncodingFormat, scope.logger(c.logger))
+	if err != nil {
+		return nil, withProviderRequestID(err, requestID)
+	}
+	response.ProviderRequestID = requestID
+	return response, nil
 }
 
 func (c *AzureOpenAIClient) ListModels(ctx context.Context) ([]domain.Model, error) {
@@ -401,8 +593,6 @@ func (c *AzureOpenAIClient) ListModels(ctx context.Context) ([]domain.Model, err
 }
 
 func (c *AzureOpenAIClient) HealthCheck(ctx context.Context) error {
-	url := fmt.Sprintf("%s/openai/models?api-version=%s", c.endpoint, c.apiVersion)
-	
 	// Implement retry with exponential backoff
 	maxRetries := 3
 	baseDelay := 100 * time.Millisecond
@@ -418,13 +608,11 @@ func (c *AzureOpenAIClient) HealthCheck(ctx context.Context) error {
 			}
 		}
 		
-		httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		httpReq, err := c.newHealthProbeRequest(ctx)
 		if err != nil {
-			continue // Retry on request creation failure
+			return err
 		}
 
-		c.setHeaders(httpReq)
-
 		resp, err := c.httpClient.Do(httpReq)
 		if err != nil {
 			c.logger.Debug("Azure OpenAI health check attempt failed",
@@ -437,6 +625,7 @@ func (c *AzureOpenAIClient) HealthCheck(ctx context.Context) error {
 			continue // Retry on network error
 		}
 		
+		io.Copy(io.Discard, resp.Body)
 		resp.Body.Close()
 
 		if resp.StatusCode == http.StatusOK {
@@ -455,6 +644,93 @@ func (c *AzureOpenAIClient) HealthCheck(ctx context.Context) error {
 	return fmt.Errorf("health check failed after %d attempts", maxRetries)
 }
 
+// newHealthProbeRequest builds the configured health probe. The list probe
+// is not billed; the completion probe asks the first deployment by name for a
+// single token and carries the probe marker as its user.
+func (c *AzureOpenAIClient) newHealthProbeRequest(ctx context.Context) (*http.Request, error) {
+	if c.healthProbe != HealthProbeCompletion {
+		httpReq, err := http.NewRequestWithContext(ctx, "GET",
+			fmt.Sprintf("%s/openai/models?api-version=%s", c.endpoint, c.apiVersion), nil)
+		if err != nil {
+			return nil, err
+		}
+		c.setHeaders(httpReq)
+		return httpReq, nil
+	}
+
+	if len(c.models) == 0 {
+		return nil, fmt.Errorf("no deployments configured")
+	}
+	deployment := c.models[0].ModelID
+	for _, model := range c.models[1:] {
+		if model.ModelID < deployment {
+			deployment = model.ModelID
+		}
+	}
+
+	maxTokens := 1
+	probeReq := c.convertCompletionRequest(&domain.CompletionRequest{
+		Model:     deployment,
+		Messages:  []domain.Message{{Role: domain.MessageRoleUser, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "ping"}}}},
+		MaxTokens: &maxTokens,
+		User:      healthProbeMarker,
+	})
+	body, err := json.Marshal(probeReq)
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST",
+		fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s", c.endpoint, deployment, c.apiVersion),
+		bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	c.setHeaders(httpReq)
+	return httpReq, nil
+}
+
+// WarmConnection sends a single models request, which is not billed, to keep
+// an idle connection in the pool. Unlike HealthCheck it does not retry.
+func (c *AzureOpenAIClient) WarmConnection(ctx context.Context) error {
+	url := fmt.Sprintf("%s/openai/models?api-version=%s", c.endpoint, c.apiVersion)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	c.setHeaders(httpReq)
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Drain the body so the connection goes back to the pool
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("keepalive failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// resolveAPIVersion returns the request's pinned API version, or the client
+// default when none is set
+func (c *AzureOpenAIClient) resolveAPIVersion(req *domain.CompletionRequest) (string, error) {
+	if req.AzureAPIVersion == "" {
+		return c.apiVersion, nil
+	}
+	if !c.allowedAPIVersions[req.AzureAPIVersion] {
+		return "", errors.NewError(errors.ErrorTypeValidation, fmt.Sprintf("azure api version %s is not allowed", req.AzureAPIVersion)).
+			WithDetail("field", "azure_api_version").
+			WithDetail("provider", string(domain.ProviderAzureOpenAI)).
+			Build()
+	}
+	return req.AzureAPIVersion, nil
+}
+
 func (c *AzureOpenAIClient) convertCompletionRequest(req *domain.CompletionRequest) *azureOpenAIRequest {
 	messages := make([]azureOpenAIMessage, len(req.Messages))
 	for i, msg := range req.Messages {
@@ -471,9 +747,17 @@ func (c *AzureOpenAIClient) convertCompletionRequest(req *domain.CompletionReque
 		}
 	}
 
-	return &azureOpenAIRequest{
+	// Azure OpenAI cannot continue an assistant message, so a prefill is
+	// emulated by instructing the model to begin its response with it
+	if prefill, ok := req.Prefill(); ok {
+		messages[len(messages)-1] = azureOpenAIMessage{
+			Role:    string(domain.MessageRoleSystem),
+			Content: fmt.Sprintf(azurePrefillInstruction, prefill),
+		}
+	}
+
+	azureReq := &azureOpenAIRequest{
 		Messages:         messages,
-		MaxTokens:        req.MaxTokens,
 		Temperature:      req.Temperature,
 		TopP:             req.TopP,
 		Stop:             req.Stop,
@@ -481,14 +765,58 @@ func (c *AzureOpenAIClient) convertCompletionRequest(req *domain.CompletionReque
 		FrequencyPenalty: req.FrequencyPenalty,
 		User:             req.User,
 		Stream:           req.Stream,
+		ResponseFormat:   req.ResponseFormat,
+		Tools:            req.Tools,
+	}
+
+	// Deployments are named freely; the token limit field depends on the
+	// underlying model
+	modelName := req.Model
+	if deployed, ok := c.deployments[req.Model]; ok {
+		modelName = deployed
+	}
+	if domain.OpenAITokenLimitParam(modelName) == domain.TokenLimitParamMaxCompletionTokens {
+		azureReq.MaxCompletionTokens = req.MaxTokens
+	} else {
+		azureReq.MaxTokens = req.MaxTokens
+	}
+
+	return azureReq
+}
+
+// applyStoredCompletion asks Azure to store the completion, with the
+// request's string metadata, when the request opted in. API versions that
+// predate stored completions get neither field, and metadata is only sent
+// with store since it only tags stored completions.
+func applyStoredCompletion(azureReq *azureOpenAIRequest, req *domain.CompletionRequest, apiVersion string) {
+	// API versions are dates, so they compare as strings
+	if req.Store == nil || apiVersion < azureStoredCompletionsAPIVersion {
+		return
+	}
+	azureReq.Store = req.Store
+	if *req.Store {
+		azureReq.Metadata = domain.ProviderMetadata(req.Metadata)
 	}
 }
 
-func (c *AzureOpenAIClient) convertCompletionResponse(azureResp *azureOpenAIResponse, modelID string) *domain.CompletionResponse {
+func (c *AzureOpenAIClient) convertCompletionResponse(azureResp *azureOpenAIResponse, modelID string, log logger.Logger) (*domain.CompletionResponse, error) {
+	if len(azureResp.Choices) == 0 {
+		providerErr := errors.ProviderError("azure-openai", "azure openai returned no choices", nil)
+		providerErr.Details["model"] = modelID
+		providerErr.Details["response_id"] = azureResp.ID
+		return nil, providerErr
+	}
+
 	choices := make([]domain.Choice, len(azureResp.Choices))
 	for i, choice := range azureResp.Choices {
+		role := domain.MessageRole(choice.Message.Role)
+		if role == "" {
+			recordResponseAnomaly(log, domain.ProviderAzureOpenAI, modelID, "choices.message.role")
+			role = domain.MessageRoleAssistant
+		}
+
 		message := domain.Message{
-			Role: domain.MessageRole(choice.Message.Role),
+			Role: role,
 			Content: []domain.ContentPart{
 				{
 					Type: domain.ContentTypeText,
@@ -496,20 +824,41 @@ func (c *AzureOpenAIClient) convertCompletionResponse(azureResp *azureOpenAIResp
 				},
 			},
 		}
+		finishReason := domain.FinishReason(choice.FinishReason)
+
+		// Refusals carry no content; surface them as their own part and reason
+		if choice.Message.Refusal != "" {
+			message.Content = []domain.ContentPart{
+				{
+					Type: domain.ContentTypeRefusal,
+					Text: choice.Message.Refusal,
+				},
+			}
+			finishReason = domain.FinishReasonRefusal
+		}
+
+		message.ToolCalls = choice.Message.ToolCalls
 
 		choices[i] = domain.Choice{
 			Index:        choice.Index,
 			Message:      message,
-			FinishReason: domain.FinishReason(choice.FinishReason),
+			FinishReason: finishReason,
 		}
+		choices[i].NormalizeToolCalls()
 	}
-
-	usage := domain.Usage{
-		PromptTokens:     azureResp.Usage.PromptTokens,
-		CompletionTokens: azureResp.Usage.CompletionTokens,
-		TotalTokens:      azureResp.Usage.TotalTokens,
-		CostUSD:          c.calculateCost(modelID, azureResp.Usage),
+	domain.OrderChoices(azureResp.ID, choices)
+
+	usage := domain.Usage{}
+	if azureResp.Usage != nil {
+		usage.PromptTokens = azureResp.Usage.PromptTokens
+		usage.CompletionTokens = azureResp.Usage.CompletionTokens
+		usage.TotalTokens = azureResp.Usage.TotalTokens
+		if details := azureResp.Usage.CompletionTokensDetails; details != nil {
+			usage.ReasoningTokens = details.ReasoningTokens
+		}
 	}
+	checkUsage(log, domain.ProviderAzureOpenAI, modelID, &usage, azureResp.Usage == nil)
+	usage.CostUSD = c.calculateCost(modelID, usage)
 
 	return &domain.CompletionResponse{
 		ID:       azureResp.ID,
@@ -519,23 +868,51 @@ func (c *AzureOpenAIClient) convertCompletionResponse(azureResp *azureOpenAIResp
 		Provider: domain.ProviderAzureOpenAI,
 		Choices:  choices,
 		Usage:    usage,
-	}
+	}, nil
 }
 
-func (c *AzureOpenAIClient) convertEmbeddingResponse(azureResp *azureOpenAIEmbeddingResponse) *domain.EmbeddingResponse {
+func (c *AzureOpenAIClient) convertEmbeddingResponse(azureResp *azureOpenAIEmbeddingResponse, inputs int, encodingFormat string, log logger.Logger) (*domain.EmbeddingResponse, error) {
+	if len(azureResp.Data) != inputs {
+		providerErr := errors.ProviderError("azure-openai", fmt.Sprintf("azure openai returned %d embeddings for %d inputs", len(azureResp.Data), inputs), nil)
+		providerErr.Details["model"] = azureResp.Model
+		return nil, providerErr
+	}
+
 	data := make([]domain.Embedding, len(azureResp.Data))
 	for i, item := range azureResp.Data {
+		if len(item.Embedding) == 0 || item.Index < 0 || item.Index >= inputs {
+			providerErr := errors.ProviderError("azure-openai", "azure openai returned a malformed embedding", nil)
+			providerErr.Details["model"] = azureResp.Model
+			providerErr.Details["index"] = item.Index
+			return nil, providerErr
+		}
 		data[i] = domain.Embedding{
 			Object:    item.Object,
 			Index:     item.Index,
 			Embedding: item.Embedding,
 		}
+		data[i].SetEncoding(encodingFormat)
+	}
+
+	var reported azureOpenAIUsage
+	if azureResp.Usage != nil {
+		reported = *azureResp.Usage
+	} else {
+		recordResponseAnomaly(log, domain.ProviderAzureOpenAI, azureResp.Model, "usage")
+	}
+	if reported.PromptTokens < 0 {
+		recordResponseAnomaly(log, domain.ProviderAzureOpenAI, azureResp.Model, "usage.prompt_tokens")
+		reported.PromptTokens = 0
+	}
+	if reported.TotalTokens < reported.PromptTokens {
+		recordResponseAnomaly(log, domain.ProviderAzureOpenAI, azureResp.Model, "usage.total_tokens")
+		reported.TotalTokens = reported.PromptTokens
 	}
 
 	usage := domain.EmbeddingUsage{
-		PromptTokens: azureResp.Usage.PromptTokens,
-		TotalTokens:  azureResp.Usage.TotalTokens,
-		CostUSD:      c.calculateEmbeddingCost(azureResp.Model, azureResp.Usage),
+		PromptTokens: reported.PromptTokens,
+		TotalTokens:  reported.TotalTokens,
+		CostUSD:      c.calculateEmbeddingCost(azureResp.Model, reported),
 	}
 
 	return &domain.EmbeddingResponse{
@@ -544,29 +921,38 @@ func (c *AzureOpenAIClient) convertEmbeddingResponse(azureResp *azureOpenAIEmbed
 		Model:    azureResp.Model,
 		Provider: domain.ProviderAzureOpenAI,
 		Usage:    usage,
-	}
+	}, nil
 }
 
-func (c *AzureOpenAIClient) processStreamResponse(resp *http.Response, modelID string) <-chan *domain.StreamResponse {
+// azureMaxStreamChunkBytes bounds one event of a completion stream
+const azureMaxStreamChunkBytes = 1 << 20
+
+// processStreamResponse relays the chunks of an event stream. With raw set
+// each chunk also keeps the bytes Azure sent, for pass-through streams.
+func (c *AzureOpenAIClient) processStreamResponse(resp *http.Response, modelID string, scope requestScope, raw bool) <-chan *domain.StreamResponse {
 	ch := make(chan *domain.StreamResponse)
+	requestID := providerRequestID(resp.Header)
 
 	go func() {
 		defer close(ch)
 		defer resp.Body.Close()
 
-		decoder := json.NewDecoder(resp.Body)
-		
+		// Events are read line by line; a chunk may be larger than the
+		// scanner's default token size
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 0, 64*1024), azureMaxStreamChunkBytes)
+
 		for {
-			var line string
-			if err := decoder.Decode(&line); err != nil {
-				if err != io.EOF {
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
 					ch <- &domain.StreamResponse{
-						Error: errors.ProviderError("azure-openai", "failed to read stream", err),
+						Error: scope.attributeStreamError(errors.ProviderError("azure-openai", "failed to read stream", err)),
 					}
 				}
 				return
 			}
 
+			line := scanner.Text()
 			if !strings.HasPrefix(line, "data: ") {
 				continue
 			}
@@ -584,12 +970,17 @@ func (c *AzureOpenAIClient) processStreamResponse(resp *http.Response, modelID s
 
 			if azureResp.Error != nil {
 				ch <- &domain.StreamResponse{
-					Error: errors.ProviderError("azure-openai", azureResp.Error.Message, nil),
+					Error:             scope.attributeStreamError(azureResp.Error.providerError()),
+					ProviderRequestID: requestID,
 				}
 				return
 			}
 
 			streamResp := c.convertStreamResponse(&azureResp, modelID)
+			streamResp.ProviderRequestID = requestID
+			if raw {
+				streamResp.Raw = json.RawMessage(data)
+			}
 			ch <- streamResp
 		}
 	}()
@@ -600,19 +991,24 @@ func (c *AzureOpenAIClient) processStreamResponse(resp *http.Response, modelID s
 func (c *AzureOpenAIClient) convertStreamResponse(azureResp *azureOpenAIResponse, modelID string) *domain.StreamResponse {
 	choices := make([]domain.Choice, len(azureResp.Choices))
 	for i, choice := range azureResp.Choices {
-		content := ""
+		part := domain.ContentPart{Type: domain.ContentTypeText}
 		if choice.Delta != nil {
-			content = choice.Delta.Content
+			part.Text = choice.Delta.Content
+			// Refusal deltas stream as their own parts; the finish reason
+			// is left as sent so clients still see where the stream ends
+			if choice.Delta.Refusal != "" {
+				part = domain.ContentPart{Type: domain.ContentTypeRefusal, Text: choice.Delta.Refusal}
+			}
 		}
 
 		message := domain.Message{
-			Role: domain.MessageRoleAssistant,
-			Content: []domain.ContentPart{
-				{
-					Type: domain.ContentTypeText,
-					Text: content,
-				},
-			},
+			Role:    domain.MessageRoleAssistant,
+			Content: []domain.ContentPart{part},
+		}
+		// Tool calls stream as fragments, the first of each call carrying
+		// its ID; the router reassembles them
+		if choice.Delta != nil {
+			message.ToolCalls = choice.Delta.ToolCalls
 		}
 
 		choices[i] = domain.Choice{
@@ -621,8 +1017,9 @@ func (c *AzureOpenAIClient) convertStreamResponse(azureResp *azureOpenAIResponse
 			FinishReason: domain.FinishReason(choice.FinishReason),
 		}
 	}
+	domain.OrderChoices(azureResp.ID, choices)
 
-	return &domain.StreamResponse{
+	streamResp := &domain.StreamResponse{
 		ID:       azureResp.ID,
 		Object:   azureResp.Object,
 		Created:  azureResp.Created,
@@ -630,18 +1027,30 @@ func (c *AzureOpenAIClient) convertStreamResponse(azureResp *azureOpenAIResponse
 		Provider: domain.ProviderAzureOpenAI,
 		Choices:  choices,
 	}
+
+	// The usage chunk requested by stream_options has no choices
+	if azureResp.Usage != nil {
+		usage := domain.Usage{
+			PromptTokens:     azureResp.Usage.PromptTokens,
+			CompletionTokens: azureResp.Usage.CompletionTokens,
+			TotalTokens:      azureResp.Usage.TotalTokens,
+		}
+		if details := azureResp.Usage.CompletionTokensDetails; details != nil {
+			usage.ReasoningTokens = details.ReasoningTokens
+		}
+		usage.CostUSD = c.calculateCost(modelID, usage)
+		streamResp.Usage = &usage
+	}
+	return streamResp
 }
 
-func (c *AzureOpenAIClient) calculateCost(modelID string, usage azureOpenAIUsage) float64 {
+func (c *AzureOpenAIClient) calculateCost(modelID string, usage domain.Usage) float64 {
 	pricing, exists := azureOpenAIModelPricing[modelID]
 	if !exists {
 		return 0
 	}
 
-	inputCost := float64(usage.PromptTokens) * pricing.InputTokenCost
-	outputCost := float64(usage.CompletionTokens) * pricing.OutputTokenCost
-
-	return inputCost + outputCost
+	return pricing.CompletionCost(usage)
 }
 
 func (c *AzureOpenAIClient) calculateEmbeddingCost(modelID string, usage azureOpenAIUsage) float64 {
@@ -654,27 +1063,38 @@ func (c *AzureOpenAIClient) calculateEmbeddingCost(modelID string, usage azureOp
 }
 
 func (c *AzureOpenAIClient) setHeaders(req *http.Request) {
+	applyCustomHeaders(req, c.customHeaders)
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("api-key", c.apiKey)
-	req.Header.Set("User-Agent", "QLens/1.0")
+	req.Header.Set("User-Agent", c.userAgent)
 }
 
 func (c *AzureOpenAIClient) handleHTTPError(statusCode int, body []byte) error {
-	var azureError azureOpenAIError
-	if err := json.Unmarshal(body, &azureError); err == nil && azureError.Message != "" {
+	var envelope struct {
+		Error *azureOpenAIError `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Error != nil && envelope.Error.Message != "" {
+		azureError := envelope.Error
+
+		var qlensErr *errors.QLensError
 		switch statusCode {
 		case http.StatusUnauthorized:
-			return errors.AuthenticationError(azureError.Message)
+			qlensErr = errors.AuthenticationError(azureError.Message)
 		case http.StatusForbidden:
-			return errors.AuthorizationError(azureError.Message)
+			qlensErr = errors.AuthorizationError(azureError.Message)
 		case http.StatusTooManyRequests:
-			return errors.NewError(errors.ErrorTypeTooManyRequests, azureError.Message).WithRetryable(true).Build()
+			qlensErr = errors.NewError(errors.ErrorTypeTooManyRequests, azureError.Message).WithRetryable(true).Build()
 		case http.StatusBadRequest:
-			return errors.ValidationError(azureError.Message, "request")
+			field := azureError.Param
+			if field == "" {
+				field = "request"
+			}
+			qlensErr = errors.ValidationError(azureError.Message, field)
 		default:
-			return errors.ProviderError("azure-openai", azureError.Message, nil)
+			qlensErr = errors.ProviderError("azure-openai", azureError.Message, nil)
 		}
+		return qlensErr.WithProviderDetails(azureError.Param, azureError.Code, azureError.Type).WithProviderStatus(statusCode)
 	}
 
-	return errors.ProviderError("azure-openai", fmt.Sprintf("azure openai api error: %d", statusCode), nil)
+	return errors.ProviderError("azure-openai", fmt.Sprintf("azure openai api error: %d", statusCode), nil).WithProviderStatus(statusCode)
 }
\ No newline at end of file
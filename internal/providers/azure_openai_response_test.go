@@ -0,0 +1,69 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+func TestAzureOpenAIConvertCompletionResponse_Refusal(t *testing.T) {
+	client := &AzureOpenAIClient{}
+
+	var azureResp azureOpenAIResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "chatcmpl-1",
+		"choices": [{
+			"index": 0,
+			"message": {"role": "assistant", "content": null, "refusal": "I can't help with that."},
+			"finish_reason": "stop"
+		}]
+	}`), &azureResp))
+
+	response, err := client.convertCompletionResponse(&azureResp, "gpt-4o", client.logger)
+	require.NoError(t, err)
+	require.Len(t, response.Choices, 1)
+
+	choice := response.Choices[0]
+	assert.Equal(t, domain.FinishReasonRefusal, choice.FinishReason)
+	assert.Equal(t, []domain.ContentPart{{Type: domain.ContentTypeRefusal, Text: "I can't help with that."}}, choice.Message.Content)
+}
+
+func TestAzureOpenAIConvertCompletionResponse_NoChoices(t *testing.T) {
+	client := &AzureOpenAIClient{}
+
+	response, err := client.convertCompletionResponse(&azureOpenAIResponse{ID: "chatcmpl-2"}, "gpt-4o", client.logger)
+	assert.Nil(t, response)
+	require.Error(t, err)
+	assert.True(t, errors.IsType(err, errors.ErrorTypeProviderError))
+}
+
+func TestAzureOpenAIConvertStreamResponse_RefusalDelta(t *testing.T) {
+	client := &AzureOpenAIClient{}
+
+	chunk := client.convertStreamResponse(&azureOpenAIResponse{
+		Choices: []azureOpenAIChoice{{Delta: &azureOpenAIMessage{Refusal: "I can't"}}},
+	}, "gpt-4o")
+	require.Len(t, chunk.Choices, 1)
+	assert.Equal(t, domain.ContentTypeRefusal, chunk.Choices[0].Message.Content[0].Type)
+
+	// Keep-alive chunks without choices convert cleanly
+	assert.Empty(t, client.convertStreamResponse(&azureOpenAIResponse{}, "gpt-4o").Choices)
+}
+
+func TestAzureOpenAIConvertStreamResponse_Usage(t *testing.T) {
+	client := &AzureOpenAIClient{}
+
+	chunk := client.convertStreamResponse(&azureOpenAIResponse{
+		Usage: &azureOpenAIUsage{PromptTokens: 10, CompletionTokens: 20, TotalTokens: 30},
+	}, "gpt-4o")
+	require.NotNil(t, chunk.Usage)
+	assert.Equal(t, 30, chunk.Usage.TotalTokens)
+	assert.Greater(t, chunk.Usage.CostUSD, 0.0)
+
+	assert.Nil(t, client.convertStreamResponse(&azureOpenAIResponse{}, "gpt-4o").Usage)
+}
@@ -8,7 +8,7 @@ import (
 	"testing"
 
 	"github.com/quantum-suite/platform/internal/domain"
-	"github.com/quantum-suite/platform/internal/services/router"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
 	"github.com/quantum-suite/platform/pkg/shared/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -90,7 +90,7 @@ func TestAzureOpenAIClient_CreateCompletion(t *testing.T) {
 					FinishReason: "stop",
 				},
 			},
-			Usage: azureOpenAIUsage{
+			Usage: &azureOpenAIUsage{
 				PromptTokens:     10,
 				CompletionTokens: 5,
 				TotalTokens:      15,
@@ -115,7 +115,7 @@ func TestAzureOpenAIClient_CreateCompletion(t *testing.T) {
 	client, err := NewAzureOpenAIClient(config, log)
 	require.NoError(t, err)
 
-	req := &router.CompletionRequest{
+	req := &domain.CompletionRequest{
 		TenantID: domain.TenantID("test-tenant"),
 		UserID:   domain.UserID("test-user"),
 		Model:    "gpt-4",
@@ -170,7 +170,7 @@ func TestAzureOpenAIClient_CreateCompletionError(t *testing.T) {
 	client, err := NewAzureOpenAIClient(config, log)
 	require.NoError(t, err)
 
-	req := &router.CompletionRequest{
+	req := &domain.CompletionRequest{
 		Model: "invalid-model",
 		Messages: []domain.Message{
 			{
@@ -188,7 +188,7 @@ func TestAzureOpenAIClient_CreateCompletionError(t *testing.T) {
 	response, err := client.CreateCompletion(context.Background(), req)
 	assert.Error(t, err)
 	assert.Nil(t, response)
-	assert.Contains(t, err.Error(), "invalid_model")
+	assert.Equal(t, "invalid_model", errors.FromError(err).PublicError().Details[errors.DetailProviderCode])
 }
 
 func TestAzureOpenAIClient_CreateEmbeddings(t *testing.T) {
@@ -207,7 +207,7 @@ func TestAzureOpenAIClient_CreateEmbeddings(t *testing.T) {
 				},
 			},
 			Model: "text-embedding-ada-002",
-			Usage: azureOpenAIUsage{
+			Usage: &azureOpenAIUsage{
 				PromptTokens: 5,
 				TotalTokens:  5,
 			},
@@ -231,7 +231,7 @@ func TestAzureOpenAIClient_CreateEmbeddings(t *testing.T) {
 	client, err := NewAzureOpenAIClient(config, log)
 	require.NoError(t, err)
 
-	req := &router.EmbeddingRequest{
+	req := &domain.EmbeddingRequest{
 		Model: "text-embedding-ada-002",
 		Input: []string{"test input"},
 	}
@@ -274,7 +274,7 @@ func TestAzureOpenAIClient_ListModels(t *testing.T) {
 func TestAzureOpenAIClient_HealthCheck(t *testing.T) {
 	// Create mock server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path == "/openai/deployments" {
+		if r.URL.Path == "/openai/models" {
 			w.WriteHeader(http.StatusOK)
 			json.NewEncoder(w).Encode(map[string]interface{}{
 				"data": []interface{}{},
@@ -337,7 +337,7 @@ func TestConvertCompletionRequest(t *testing.T) {
 	client, err := NewAzureOpenAIClient(config, log)
 	require.NoError(t, err)
 
-	req := &router.CompletionRequest{
+	req := &domain.CompletionRequest{
 		Model: "gpt-4",
 		Messages: []domain.Message{
 			{
@@ -0,0 +1,135 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+const azureStreamChunk = `{"id":"chatcmpl-1","object":"chat.completion.chunk","created":1700000000,"model":"gpt-4","system_fingerprint":"fp_1","choices":[{"index":0,"delta":{"content":"Hi"},"logprobs":null,"finish_reason":null}]}`
+
+func collectAzureStream(t *testing.T, passthrough bool) []*domain.StreamResponse {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Write([]byte("data: " + azureStreamChunk + "\n\n"))
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	client, err := NewAzureOpenAIClient(AzureOpenAIConfig{
+		Endpoint:    server.URL,
+		APIKey:      "secret",
+		Deployments: map[string]string{"gpt-4": "gpt-4"},
+	}, logger.NewNoop())
+	require.NoError(t, err)
+
+	stream, err := client.CreateCompletionStream(context.Background(), &domain.CompletionRequest{
+		Model:             "gpt-4",
+		Messages:          []domain.Message{{Role: domain.MessageRoleUser, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "hello"}}}},
+		StreamPassthrough: passthrough,
+	})
+	require.NoError(t, err)
+
+	var chunks []*domain.StreamResponse
+	for chunk := range stream {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+func TestAzureOpenAI_StreamReadsEvents(t *testing.T) {
+	chunks := collectAzureStream(t, false)
+	require.Len(t, chunks, 2)
+	require.Nil(t, chunks[0].Error)
+	assert.Equal(t, "Hi", chunks[0].Choices[0].Message.Content[0].Text)
+	assert.Empty(t, chunks[0].Raw)
+	assert.True(t, chunks[1].Done)
+}
+
+func TestAzureOpenAI_StreamPassthroughKeepsChunkBytes(t *testing.T) {
+	chunks := collectAzureStream(t, true)
+	require.Len(t, chunks, 2)
+
+	// Fields the translated chunk drops survive in the raw bytes
+	assert.Equal(t, azureStreamChunk, string(chunks[0].Raw))
+	assert.Equal(t, "Hi", chunks[0].Choices[0].Message.Content[0].Text)
+	assert.True(t, chunks[1].Done)
+}
+
+func TestAzureOpenAI_StreamKeepsToolCallFragments(t *testing.T) {
+	var chunk azureOpenAIResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"lookup","arguments":"{\"q\":"}}]}}]}`), &chunk))
+
+	response := (&AzureOpenAIClient{}).convertStreamResponse(&chunk, "gpt-4")
+	calls := response.Choices[0].Message.ToolCalls
+	require.Len(t, calls, 1)
+	assert.Equal(t, "call_1", calls[0].ID)
+	assert.Equal(t, "lookup", calls[0].Function.Name)
+	assert.Equal(t, `{"q":`, calls[0].Function.Arguments)
+}
+
+func TestAzureOpenAI_StreamDemultiplexesInterleavedChoices(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range []string{
+			`{"id":"chatcmpl-2","choices":[{"index":1,"delta":{"content":"Good"}},{"index":0,"delta":{"content":"Hel"}}]}`,
+			`{"id":"chatcmpl-2","choices":[{"index":0,"delta":{"content":"lo"}}]}`,
+			`{"id":"chatcmpl-2","choices":[{"index":1,"delta":{"content":"bye"},"finish_reason":"stop"}]}`,
+			`{"id":"chatcmpl-2","choices":[{"index":0,"delta":{},"finish_reason":"length"}]}`,
+		} {
+			w.Write([]byte("data: " + chunk + "\n\n"))
+		}
+		w.Write([]byte("data: [DONE]\n\n"))
+	}))
+	defer server.Close()
+
+	client, err := NewAzureOpenAIClient(AzureOpenAIConfig{
+		Endpoint:    server.URL,
+		APIKey:      "secret",
+		Deployments: map[string]string{"gpt-4": "gpt-4"},
+	}, logger.NewNoop())
+	require.NoError(t, err)
+
+	stream, err := client.CreateCompletionStream(context.Background(), &domain.CompletionRequest{
+		Model:    "gpt-4",
+		Messages: []domain.Message{{Role: domain.MessageRoleUser, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "hello"}}}},
+	})
+	require.NoError(t, err)
+
+	// Chunks are relayed with their choices in index order and stable IDs
+	var chunks []*domain.StreamResponse
+	for chunk := range stream {
+		chunks = append(chunks, chunk)
+	}
+	require.Len(t, chunks, 5)
+	assert.Equal(t, []int{0, 1}, []int{chunks[0].Choices[0].Index, chunks[0].Choices[1].Index})
+	assert.Equal(t, "chatcmpl-2-1", chunks[0].Choices[1].ID)
+	assert.Equal(t, "chatcmpl-2-1", chunks[2].Choices[0].ID)
+
+	collected, err := domain.CollectStream(context.Background(), streamOfChunks(chunks))
+	require.NoError(t, err)
+	require.Len(t, collected.Choices, 2)
+	assert.Equal(t, "Hello", collected.Choices[0].Message.Content[0].Text)
+	assert.Equal(t, domain.FinishReasonLength, collected.Choices[0].FinishReason)
+	assert.Equal(t, "chatcmpl-2-0", collected.Choices[0].ID)
+	assert.Equal(t, "Goodbye", collected.Choices[1].Message.Content[0].Text)
+	assert.Equal(t, domain.FinishReasonStop, collected.Choices[1].FinishReason)
+	assert.Equal(t, "chatcmpl-2-1", collected.Choices[1].ID)
+}
+
+func streamOfChunks(chunks []*domain.StreamResponse) <-chan *domain.StreamResponse {
+	ch := make(chan *domain.StreamResponse, len(chunks))
+	for _, chunk := range chunks {
+		ch <- chunk
+	}
+	close(ch)
+	return ch
+}
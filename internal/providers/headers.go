@@ -0,0 +1,83 @@
+package providers
+
+import (
+	goerrors "errors"
+	"fmt"
+	"net/http"
+
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/version"
+)
+
+// resolveUserAgent returns the configured user agent, or QLens/<version> when
+// none is configured
+func resolveUserAgent(configured string) string {
+	return version.UserAgent(configured)
+}
+
+// validateCustomHeaders rejects custom headers that would replace a header
+// the client manages itself, such as its authentication header
+func validateCustomHeaders(provider string, custom map[string]string, protected ...string) error {
+	for name := range custom {
+		for _, reserved := range protected {
+			if http.CanonicalHeaderKey(name) == http.CanonicalHeaderKey(reserved) {
+				return errors.ConfigurationError(fmt.Sprintf("%s custom header %s would override a header managed by the client", provider, name))
+			}
+		}
+	}
+	return nil
+}
+
+// applyCustomHeaders sets the configured custom headers. Clients call it
+// before setting their own headers so managed headers always win.
+func applyCustomHeaders(req *http.Request, custom map[string]string) {
+	for name, value := range custom {
+		req.Header.Set(name, value)
+	}
+}
+
+// azureClientRequestIDHeader carries the gateway's request ID to Azure, which
+// records it against the call in its own logs
+const azureClientRequestIDHeader = "x-ms-client-request-id"
+
+// providerRequestIDHeaders are the response headers providers return their
+// own request ID in, in order of preference
+var providerRequestIDHeaders = []string{"x-request-id", "apim-request-id", "request-id"}
+
+// setClientRequestID sends the gateway's request ID with a provider call
+func setClientRequestID(req *http.Request, header, requestID string) {
+	if requestID != "" {
+		req.Header.Set(header, requestID)
+	}
+}
+
+// providerRequestID returns the provider's ID for a call from its response
+// headers, or "" when the provider sent none
+func providerRequestID(header http.Header) string {
+	for _, name := range providerRequestIDHeaders {
+		if value := header.Get(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// withProviderRequestID adds the provider's ID for a failed call to the
+// error's details so clients can quote it to the provider
+func withProviderRequestID(err error, requestID string) error {
+	var qlensErr *errors.QLensError
+	if goerrors.As(err, &qlensErr) {
+		qlensErr.WithProviderRequestID(requestID)
+	}
+	return err
+}
+
+// withProviderStatus records the HTTP status the provider answered a failed
+// call with, so retry policies can match on it
+func withProviderStatus(err error, statusCode int) error {
+	var qlensErr *errors.QLensError
+	if goerrors.As(err, &qlensErr) {
+		qlensErr.WithProviderStatus(statusCode)
+	}
+	return err
+}
@@ -0,0 +1,96 @@
+package providers
+
+import (
+	"context"
+	goerrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+	"github.com/quantum-suite/platform/pkg/shared/version"
+)
+
+func TestResolveUserAgent(t *testing.T) {
+	original := version.Version
+	version.Version = "2.3.1"
+	defer func() { version.Version = original }()
+
+	assert.Equal(t, "QLens/2.3.1", resolveUserAgent(""))
+	assert.Equal(t, "AcmeProxy QLens/2.3.1", resolveUserAgent("AcmeProxy QLens/{version}"))
+	assert.Equal(t, "custom-agent", resolveUserAgent("custom-agent"))
+}
+
+func TestAzureOpenAISetHeaders_CustomHeaders(t *testing.T) {
+	client, err := NewAzureOpenAIClient(AzureOpenAIConfig{
+		Endpoint:      "https://test.openai.azure.com",
+		APIKey:        "secret",
+		UserAgent:     "acme-gateway",
+		CustomHeaders: map[string]string{"X-Org-ID": "acme"},
+	}, logger.NewNoop())
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("POST", "https://test.openai.azure.com", nil)
+	client.setHeaders(req)
+
+	assert.Equal(t, "acme", req.Header.Get("X-Org-ID"))
+	assert.Equal(t, "secret", req.Header.Get("api-key"))
+	assert.Equal(t, "acme-gateway", req.Header.Get("User-Agent"))
+}
+
+func TestAzureOpenAI_RejectsAuthHeaderOverride(t *testing.T) {
+	_, err := NewAzureOpenAIClient(AzureOpenAIConfig{
+		Endpoint:      "https://test.openai.azure.com",
+		APIKey:        "secret",
+		CustomHeaders: map[string]string{"API-KEY": "other"},
+	}, logger.NewNoop())
+
+	require.Error(t, err)
+	assert.True(t, errors.IsType(err, errors.ErrorTypeConfiguration))
+}
+
+func TestAzureOpenAI_PropagatesRequestIDs(t *testing.T) {
+	var clientRequestID string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientRequestID = r.Header.Get("x-ms-client-request-id")
+		w.Header().Set("apim-request-id", "azure-req-1")
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			w.Write([]byte(`{"error":{"message":"deployment not found","code":"DeploymentNotFound"}}`))
+			return
+		}
+		w.Write([]byte(`{"id":"chatcmpl-1","model":"gpt-4","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewAzureOpenAIClient(AzureOpenAIConfig{
+		Endpoint:    server.URL,
+		APIKey:      "secret",
+		Deployments: map[string]string{"gpt-4": "gpt-4"},
+	}, logger.NewNoop())
+	require.NoError(t, err)
+
+	req := &domain.CompletionRequest{
+		RequestID: "req_123",
+		Model:     "gpt-4",
+		Messages:  []domain.Message{{Role: domain.MessageRoleUser, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "hello"}}}},
+	}
+
+	response, err := client.CreateCompletion(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "req_123", clientRequestID)
+	assert.Equal(t, "azure-req-1", response.ProviderRequestID)
+
+	// Failed calls carry the provider's ID as a public detail
+	status = http.StatusNotFound
+	_, err = client.CreateCompletion(context.Background(), req)
+	var qlensErr *errors.QLensError
+	require.True(t, goerrors.As(err, &qlensErr))
+	assert.Equal(t, "azure-req-1", qlensErr.PublicError().Details[errors.DetailProviderRequestID])
+}
@@ -0,0 +1,44 @@
+package providers
+
+import (
+	"fmt"
+
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// HealthProbe selects the request a provider client's HealthCheck sends
+type HealthProbe string
+
+const (
+	// HealthProbeList sends a metadata request the provider does not bill:
+	// Azure OpenAI lists models and AWS Bedrock lists async invocations. It
+	// checks the endpoint and credentials but not any one model.
+	HealthProbeList HealthProbe = "list"
+
+	// HealthProbeCountTokens counts the tokens of a one-word prompt on the
+	// first configured model. AWS Bedrock does not bill it, but only some
+	// models support it.
+	HealthProbeCountTokens HealthProbe = "count_tokens"
+
+	// HealthProbeCompletion asks the first configured model for one token.
+	// The provider bills it, but it checks that the model itself answers.
+	HealthProbeCompletion HealthProbe = "completion"
+)
+
+// healthProbeMarker tags billed probe requests so they can be told apart
+// from real traffic in provider logs
+const healthProbeMarker = "qlens-health-probe"
+
+// parseHealthProbe returns the configured probe, defaulting to
+// HealthProbeList, or an error when the provider does not support it
+func parseHealthProbe(provider string, probe HealthProbe, supported ...HealthProbe) (HealthProbe, error) {
+	if probe == "" {
+		return HealthProbeList, nil
+	}
+	for _, candidate := range supported {
+		if probe == candidate {
+			return probe, nil
+		}
+	}
+	return "", errors.ConfigurationError(fmt.Sprintf("unsupported %s health probe: %s", provider, probe))
+}
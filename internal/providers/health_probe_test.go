@@ -0,0 +1,114 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestAzureOpenAIHealthCheck_Probes(t *testing.T) {
+	var method, path string
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		body = nil
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	config := AzureOpenAIConfig{
+		Endpoint:    server.URL,
+		APIKey:      "secret",
+		Deployments: map[string]string{"gpt-4o-mini": "gpt-4o-mini-2024-07-18", "gpt-4o": "gpt-4o-2024-05-13"},
+	}
+
+	// The default probe lists models, which is not billed
+	client, err := NewAzureOpenAIClient(config, logger.NewNoop())
+	require.NoError(t, err)
+	require.NoError(t, client.HealthCheck(context.Background()))
+	assert.Equal(t, http.MethodGet, method)
+	assert.Equal(t, "/openai/models", path)
+
+	config.HealthProbe = HealthProbeCompletion
+	client, err = NewAzureOpenAIClient(config, logger.NewNoop())
+	require.NoError(t, err)
+	require.NoError(t, client.HealthCheck(context.Background()))
+	assert.Equal(t, http.MethodPost, method)
+	assert.Equal(t, "/openai/deployments/gpt-4o/chat/completions", path)
+	assert.Equal(t, float64(1), body["max_tokens"])
+	assert.Equal(t, healthProbeMarker, body["user"])
+}
+
+func TestParseHealthProbe(t *testing.T) {
+	probe, err := parseHealthProbe("azure openai", "", HealthProbeList, HealthProbeCompletion)
+	require.NoError(t, err)
+	assert.Equal(t, HealthProbeList, probe)
+
+	_, err = NewAzureOpenAIClient(AzureOpenAIConfig{
+		Endpoint:    "https://test.openai.azure.com",
+		APIKey:      "secret",
+		HealthProbe: HealthProbeCountTokens,
+	}, logger.NewNoop())
+	require.Error(t, err)
+	assert.True(t, errors.IsType(err, errors.ErrorTypeConfiguration))
+}
+
+// connectionWarmer matches the router's ConnectionWarmer, which every
+// router-side provider client implements
+type connectionWarmer interface {
+	WarmConnection(ctx context.Context) error
+}
+
+func TestAzureOpenAIWarmConnection_ListsModels(t *testing.T) {
+	var method, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewAzureOpenAIClient(AzureOpenAIConfig{Endpoint: server.URL, APIKey: "secret"}, logger.NewNoop())
+	require.NoError(t, err)
+
+	var warmer connectionWarmer = client
+	require.NoError(t, warmer.WarmConnection(context.Background()))
+	assert.Equal(t, http.MethodGet, method)
+	assert.Equal(t, "/openai/models", path)
+}
+
+func TestAWSBedrockWarmConnection_ListsAsyncInvokes(t *testing.T) {
+	var method, path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, path = r.Method, r.URL.Path
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"asyncInvokeSummaries":[]}`))
+	}))
+	defer server.Close()
+
+	client := &AWSBedrockClient{
+		client: bedrockruntime.New(bedrockruntime.Options{
+			Region:       "us-east-1",
+			BaseEndpoint: aws.String(server.URL),
+			Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+		}),
+		logger: logger.NewNoop(),
+	}
+
+	var warmer connectionWarmer = client
+	require.NoError(t, warmer.WarmConnection(context.Background()))
+	assert.Equal(t, http.MethodGet, method)
+	assert.Equal(t, "/async-invoke", path)
+}
@@ -0,0 +1,46 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+func prefilledRequest(prefill string) *domain.CompletionRequest {
+	return &domain.CompletionRequest{
+		Model: "gpt-4o",
+		Messages: []domain.Message{
+			{Role: domain.MessageRoleUser, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "List three colours as JSON"}}},
+			{Role: domain.MessageRoleAssistant, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: prefill}}},
+		},
+	}
+}
+
+func TestBedrockConvertCompletionRequest_Prefill(t *testing.T) {
+	client := &AWSBedrockClient{}
+
+	claudeReq := client.convertCompletionRequest(prefilledRequest("[\"red\", \n"))
+	require.Len(t, claudeReq.Messages, 2)
+	assert.Equal(t, "assistant", claudeReq.Messages[1].Role)
+	assert.Equal(t, "[\"red\",", claudeReq.Messages[1].Content, "trailing whitespace is trimmed")
+}
+
+func TestAzureOpenAIConvertCompletionRequest_EmulatesPrefill(t *testing.T) {
+	client := &AzureOpenAIClient{}
+
+	azureReq := client.convertCompletionRequest(prefilledRequest("[\"red\","))
+	require.Len(t, azureReq.Messages, 2)
+	assert.Equal(t, "user", azureReq.Messages[0].Role)
+	assert.Equal(t, "system", azureReq.Messages[1].Role)
+	assert.Contains(t, azureReq.Messages[1].Content, "[\"red\",")
+
+	// A conversation that does not end in an assistant message is unchanged
+	req := prefilledRequest("")
+	req.Messages = req.Messages[:1]
+	azureReq = client.convertCompletionRequest(req)
+	require.Len(t, azureReq.Messages, 1)
+	assert.Equal(t, "user", azureReq.Messages[0].Role)
+}
@@ -0,0 +1,92 @@
+package providers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/smithy-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestAzureHandleHTTPError_PassesProviderDetails(t *testing.T) {
+	client := &AzureOpenAIClient{}
+	body := []byte(`{"error":{"message":"Invalid value for 'temperature'","type":"invalid_request_error","param":"temperature","code":"invalid_value"}}`)
+
+	err := client.handleHTTPError(http.StatusBadRequest, body)
+
+	qlensErr := errors.FromError(err)
+	assert.Equal(t, errors.ErrorTypeValidation, qlensErr.Type)
+	assert.Equal(t, "Invalid value for 'temperature'", qlensErr.Message)
+
+	public := qlensErr.PublicError()
+	assert.Equal(t, "temperature", public.Details["field"])
+	assert.Equal(t, "temperature", public.Details[errors.DetailProviderParam])
+	assert.Equal(t, "invalid_value", public.Details[errors.DetailProviderCode])
+	assert.Equal(t, "invalid_request_error", public.Details[errors.DetailProviderType])
+}
+
+func TestAzureHandleHTTPError_UnparseableBody(t *testing.T) {
+	client := &AzureOpenAIClient{}
+
+	err := client.handleHTTPError(http.StatusBadGateway, []byte("upstream unavailable"))
+
+	assert.True(t, errors.IsType(err, errors.ErrorTypeProviderError))
+	assert.Contains(t, err.Error(), "502")
+}
+
+func TestBedrockHandleAWSError_APIErrors(t *testing.T) {
+	client := &AWSBedrockClient{}
+
+	tests := []struct {
+		code      string
+		errorType errors.ErrorType
+	}{
+		{"ValidationException", errors.ErrorTypeValidation},
+		{"ThrottlingException", errors.ErrorTypeTooManyRequests},
+		{"AccessDeniedException", errors.ErrorTypeAuthentication},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			apiErr := &smithy.GenericAPIError{Code: tt.code, Message: "maxTokens must be at most 4096"}
+
+			err := client.handleAWSError(fmt.Errorf("operation error Bedrock Runtime: InvokeModel: %w", apiErr))
+
+			qlensErr := errors.FromError(err)
+			require.NotNil(t, qlensErr)
+			assert.Equal(t, tt.errorType, qlensErr.Type)
+			assert.Equal(t, "maxTokens must be at most 4096", qlensErr.Message)
+			assert.Equal(t, tt.code, qlensErr.PublicError().Details[errors.DetailProviderCode])
+		})
+	}
+}
+
+func TestAzureCreateCompletionStream_JSONErrorBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error":{"message":"content filtered","code":"content_filter"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewAzureOpenAIClient(AzureOpenAIConfig{
+		Endpoint:    server.URL,
+		APIKey:      "secret",
+		Deployments: map[string]string{"gpt-4": "gpt-4"},
+	}, logger.NewNoop())
+	require.NoError(t, err)
+
+	stream, err := client.CreateCompletionStream(context.Background(), &domain.CompletionRequest{Model: "gpt-4", Stream: true})
+	assert.Nil(t, stream)
+	require.Error(t, err)
+	assert.True(t, errors.IsType(err, errors.ErrorTypeProviderError))
+	assert.Contains(t, err.Error(), "content filtered")
+	assert.Equal(t, "content_filter", errors.FromError(err).PublicError().Details[errors.DetailProviderCode])
+}
@@ -0,0 +1,846 @@
+package providers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+	"github.com/quantum-suite/platform/pkg/shared/version"
+)
+
+const (
+	anthropicBaseURL = "https://api.anthropic.com/v1"
+
+	// anthropicAPIVersion is the Messages API version the client speaks
+	anthropicAPIVersion = "2023-06-01"
+
+	// anthropicDefaultModel is used when a request does not name a model
+	anthropicDefaultModel = "claude-3-5-sonnet-latest"
+
+	// anthropicDefaultMaxTokens is sent when a request sets no max_tokens,
+	// which the Messages API requires
+	anthropicDefaultMaxTokens = 4096
+
+	// anthropicRequestIDHeader carries Anthropic's own ID for the call
+	anthropicRequestIDHeader = "Request-Id"
+)
+
+// anthropicPricingTable holds the published per-token prices of Claude
+// models. Dated model IDs match the family key they contain.
+var anthropicPricingTable = PricingTable{
+	"claude-3-haiku":    {ContextLength: 200000, Pricing: tokenPricing(0.25/1e6, 1.25/1e6)},
+	"claude-3-5-haiku":  {ContextLength: 200000, Pricing: tokenPricing(0.8/1e6, 4/1e6)},
+	"claude-haiku-4":    {ContextLength: 200000, Pricing: tokenPricing(1/1e6, 5/1e6)},
+	"claude-3-sonnet":   {ContextLength: 200000, Pricing: tokenPricing(3/1e6, 15/1e6)},
+	"claude-3-5-sonnet": {ContextLength: 200000, Pricing: tokenPricing(3/1e6, 15/1e6)},
+	"claude-3-7-sonnet": {ContextLength: 200000, Pricing: tokenPricing(3/1e6, 15/1e6)},
+	"claude-sonnet-4":   {ContextLength: 200000, Pricing: tokenPricing(3/1e6, 15/1e6)},
+	"claude-3-opus":     {ContextLength: 200000, Pricing: tokenPricing(15/1e6, 75/1e6)},
+	"claude-opus-4":     {ContextLength: 200000, Pricing: tokenPricing(15/1e6, 75/1e6)},
+}
+
+// AnthropicClient implements the ProviderClient interface against the
+// Anthropic Messages API
+type AnthropicClient struct {
+	config     types.ProviderConfig
+	models     PricingTable
+	httpClient *http.Client
+	baseURL    string
+	apiKey     string
+}
+
+// NewAnthropicClient creates a new Anthropic client. Base URL and model
+// specs in config override the defaults.
+func NewAnthropicClient(config types.ProviderConfig) *AnthropicClient {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = anthropicBaseURL
+	}
+
+	timeout := config.Timeout
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+
+	return &AnthropicClient{
+		config:  config,
+		models:  anthropicPricingTable.merge(config.Models),
+		baseURL: baseURL,
+		apiKey:  config.APIKey,
+		httpClient: &http.Client{
+			Timeout: timeout,
+		},
+	}
+}
+
+// Provider returns the provider type
+func (c *AnthropicClient) Provider() domain.Provider {
+	return domain.ProviderAnthropic
+}
+
+// Name returns the provider name
+func (c *AnthropicClient) Name() string {
+	return "Anthropic"
+}
+
+// CreateCompletion creates a completion using the Messages API
+func (c *AnthropicClient) CreateCompletion(ctx context.Context, req *types.CompletionRequest) (*types.CompletionResponse, error) {
+	start := time.Now()
+
+	respData, header, err := c.doRequest(ctx, "POST", "/messages", c.convertCompletionRequest(req), req.RequestID)
+	if err != nil {
+		attributeError(err, req.TenantID, req.UserID, req.RequestID)
+		return nil, fmt.Errorf("Anthropic API request failed: %w", err)
+	}
+
+	var anthropicResp AnthropicMessageResponse
+	if err := json.Unmarshal(respData, &anthropicResp); err != nil {
+		return nil, fmt.Errorf("failed to parse Anthropic response: %w", err)
+	}
+
+	response, err := c.convertCompletionResponse(&anthropicResp, req.RequestID, time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+	response.ProviderRequestID = header.Get(anthropicRequestIDHeader)
+	if req.IncludeRawResponse {
+		response.AttachRawResponse(respData)
+	}
+
+	return response, nil
+}
+
+// CreateCompletionStream creates a streaming completion using the Messages
+// API's server-sent events
+func (c *AnthropicClient) CreateCompletionStream(ctx context.Context, req *types.CompletionRequest) (<-chan types.StreamResponse, error) {
+	anthropicReq := c.convertCompletionRequest(req)
+	anthropicReq.Stream = true
+
+	reqBody, err := json.Marshal(anthropicReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/messages", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	c.setHeaders(httpReq)
+	setClientRequestID(httpReq, req.RequestID)
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+
+	// Errors are typed the same as for non-streaming calls
+	if resp.StatusCode != http.StatusOK || isJSONResponse(resp.Header) {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, attributeError(c.responseError(resp, body), req.TenantID, req.UserID, req.RequestID)
+	}
+
+	streamChan := make(chan types.StreamResponse)
+	go c.handleStream(ctx, resp.Body, streamChan, req.RequestID, resp.Header.Get(anthropicRequestIDHeader))
+
+	return streamChan, nil
+}
+
+// CreateEmbeddings is not supported: Anthropic has no embeddings API
+func (c *AnthropicClient) CreateEmbeddings(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+	return nil, &types.QLensError{
+		Type:      types.ErrorTypeInvalidRequest,
+		Message:   "Anthropic does not provide embeddings",
+		Provider:  c.Provider(),
+		RequestID: req.RequestID,
+	}
+}
+
+// ListModels lists the models available to the API key
+func (c *AnthropicClient) ListModels(ctx context.Context) ([]types.Model, error) {
+	respData, err := c.makeRequest(ctx, "GET", "/models?limit=1000")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list Anthropic models: %w", err)
+	}
+
+	var anthropicResp AnthropicModelsResponse
+	if err := json.Unmarshal(respData, &anthropicResp); err != nil {
+		return nil, fmt.Errorf("failed to parse models response: %w", err)
+	}
+
+	models := make([]types.Model, 0, len(anthropicResp.Data))
+	for _, anthropicModel := range anthropicResp.Data {
+		models = append(models, c.convertModel(&anthropicModel))
+	}
+
+	return models, nil
+}
+
+// GetModel gets a specific model
+func (c *AnthropicClient) GetModel(ctx context.Context, modelID string) (*types.Model, error) {
+	respData, err := c.makeRequest(ctx, "GET", "/models/"+url.PathEscape(modelID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Anthropic model: %w", err)
+	}
+
+	var anthropicModel AnthropicModel
+	if err := json.Unmarshal(respData, &anthropicModel); err != nil {
+		return nil, fmt.Errorf("failed to parse model response: %w", err)
+	}
+
+	model := c.convertModel(&anthropicModel)
+	return &model, nil
+}
+
+// HealthCheck performs a health check by listing models, which is not billed
+func (c *AnthropicClient) HealthCheck(ctx context.Context) error {
+	_, err := c.makeRequest(ctx, "GET", "/models?limit=1")
+	return err
+}
+
+// WarmConnection sends a single models request, which is not billed, to
+// open a pooled connection before the first real request needs one
+func (c *AnthropicClient) WarmConnection(ctx context.Context) error {
+	_, err := c.makeRequest(ctx, "GET", "/models?limit=1")
+	return err
+}
+
+// Configure updates the client configuration
+func (c *AnthropicClient) Configure(config types.ProviderConfig) error {
+	c.config = config
+	c.apiKey = config.APIKey
+	c.models = anthropicPricingTable.merge(config.Models)
+
+	if config.BaseURL != "" {
+		c.baseURL = config.BaseURL
+	}
+
+	if config.Timeout > 0 {
+		c.httpClient.Timeout = config.Timeout
+	}
+
+	return nil
+}
+
+// GetConfig returns the current configuration
+func (c *AnthropicClient) GetConfig() types.ProviderConfig {
+	return c.config
+}
+
+// Close cleans up resources
+func (c *AnthropicClient) Close() error {
+	c.httpClient.CloseIdleConnections()
+	return nil
+}
+
+// Helper methods
+
+func (c *AnthropicClient) makeRequest(ctx context.Context, method, path string) ([]byte, error) {
+	respBody, _, err := c.doRequest(ctx, method, path, nil, "")
+	return respBody, err
+}
+
+// doRequest makes an API request tagged with the caller's request ID and
+// returns the response headers along with the body
+func (c *AnthropicClient) doRequest(ctx context.Context, method, path string, body interface{}, requestID string) ([]byte, http.Header, error) {
+	var reqBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+		reqBody = bytes.NewReader(jsonBody)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+
+	c.setHeaders(req)
+	setClientRequestID(req, requestID)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil, c.responseError(resp, respBody)
+	}
+
+	return respBody, resp.Header, nil
+}
+
+// responseError converts a failed response into a QLensError typed by its
+// status, or failing that by Anthropic's error type
+func (c *AnthropicClient) responseError(resp *http.Response, body []byte) error {
+	details := map[string]interface{}{}
+	if providerID := resp.Header.Get(anthropicRequestIDHeader); providerID != "" {
+		details["provider_request_id"] = providerID
+	}
+
+	var anthropicErr AnthropicError
+	if err := json.Unmarshal(body, &anthropicErr); err != nil || anthropicErr.Error.Message == "" {
+		message := strings.TrimSpace(string(body))
+		if len(message) > maxErrorBodyLength {
+			message = message[:maxErrorBodyLength]
+		}
+		if message == "" {
+			message = http.StatusText(resp.StatusCode)
+		}
+		return &types.QLensError{
+			Type:       errorTypeForResponse(resp.StatusCode, ""),
+			Message:    fmt.Sprintf("Anthropic API error (%d): %s", resp.StatusCode, message),
+			Details:    details,
+			Provider:   c.Provider(),
+			StatusCode: resp.StatusCode,
+		}
+	}
+
+	details["provider_type"] = anthropicErr.Error.Type
+	return &types.QLensError{
+		Type:       errorTypeForResponse(resp.StatusCode, anthropicErr.Error.Type),
+		Message:    anthropicErr.Error.Message,
+		Details:    details,
+		Provider:   c.Provider(),
+		StatusCode: resp.StatusCode,
+	}
+}
+
+func (c *AnthropicClient) setHeaders(req *http.Request) {
+	// Custom headers go first so they can never replace the auth header
+	for name, value := range c.config.CustomHeaders {
+		req.Header.Set(name, value)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", version.UserAgent(c.config.UserAgent))
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", anthropicAPIVersion)
+}
+
+// handleStream translates the Messages API's events into stream responses.
+// Text arrives in content_block_delta events, the stop reason in
+// message_delta and the end of the stream in message_stop. Tool calls are
+// sent whole once their block ends, with the argument fragments joined.
+// Usage is gathered from message_start and message_delta and sent with the
+// final chunk.
+func (c *AnthropicClient) handleStream(ctx context.Context, body io.ReadCloser, streamChan chan<- types.StreamResponse, requestID, providerRequestID string) {
+	defer close(streamChan)
+	defer body.Close()
+
+	var messageID, model string
+	var usage AnthropicUsage
+	toolCalls := make(map[int]*domain.ToolCall)
+
+	emit := func(resp types.StreamResponse) bool {
+		resp.RequestID = requestID
+		resp.ProviderRequestID = providerRequestID
+		select {
+		case streamChan <- resp:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+	send := func(choice types.StreamChoice) bool {
+		choice.ID = domain.ChoiceID(messageID, 0)
+		return emit(types.StreamResponse{
+			ID:       messageID,
+			Object:   "chat.completion.chunk",
+			Created:  time.Now().Unix(),
+			Model:    model,
+			Provider: c.Provider(),
+			Choices:  []types.StreamChoice{choice},
+		})
+	}
+	fail := func(errorType, message string) {
+		emit(types.StreamResponse{
+			ID:    messageID,
+			Error: &types.StreamError{Type: errorType, Message: message},
+		})
+	}
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+
+		var event AnthropicStreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			fail(types.ErrorTypeProviderError, fmt.Sprintf("Failed to parse stream event: %v", err))
+			return
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				messageID, model = event.Message.ID, event.Message.Model
+				usage = event.Message.Usage
+			}
+			role := domain.MessageRoleAssistant
+			if !send(types.StreamChoice{Delta: types.StreamDelta{Role: &role}}) {
+				return
+			}
+		case "content_block_start":
+			if event.ContentBlock != nil && event.ContentBlock.Type == "tool_use" {
+				toolCalls[event.Index] = &domain.ToolCall{
+					ID:       event.ContentBlock.ID,
+					Type:     "function",
+					Function: domain.FunctionCall{Name: event.ContentBlock.Name},
+				}
+			}
+		case "content_block_delta":
+			if event.Delta == nil {
+				continue
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				text := event.Delta.Text
+				if !send(types.StreamChoice{Delta: types.StreamDelta{Content: &text}}) {
+					return
+				}
+			case "input_json_delta":
+				if toolCall, ok := toolCalls[event.Index]; ok {
+					toolCall.Function.Arguments += event.Delta.PartialJSON
+				}
+			}
+		case "content_block_stop":
+			toolCall, ok := toolCalls[event.Index]
+			if !ok {
+				continue
+			}
+			delete(toolCalls, event.Index)
+			if toolCall.Function.Arguments == "" {
+				toolCall.Function.Arguments = "{}"
+			}
+			if !send(types.StreamChoice{Delta: types.StreamDelta{ToolCalls: []domain.ToolCall{*toolCall}}}) {
+				return
+			}
+		case "message_delta":
+			// The counts in message_delta are cumulative
+			if event.Usage != nil {
+				if event.Usage.InputTokens > 0 {
+					usage.InputTokens = event.Usage.InputTokens
+				}
+				usage.OutputTokens = event.Usage.OutputTokens
+			}
+			if event.Delta == nil || event.Delta.StopReason == "" {
+				continue
+			}
+			reason := convertAnthropicStopReason(event.Delta.StopReason)
+			if !send(types.StreamChoice{FinishReason: &reason}) {
+				return
+			}
+		case "message_stop":
+			streamUsage := domain.Usage{
+				PromptTokens:     usage.InputTokens,
+				CompletionTokens: usage.OutputTokens,
+				TotalTokens:      usage.InputTokens + usage.OutputTokens,
+			}
+			checkUsage(c.Provider(), &streamUsage, false)
+			streamUsage.CostUSD = c.calculateCost(model, streamUsage)
+			emit(types.StreamResponse{
+				ID:    messageID,
+				Model: model,
+				Done:  true,
+				Usage: &streamUsage,
+			})
+			return
+		case "error":
+			// Failures after the stream has started, such as overloading,
+			// are sent as an event in place of the content
+			if event.Error != nil {
+				fail(errorTypeForResponse(http.StatusOK, event.Error.Type), event.Error.Message)
+			} else {
+				fail(types.ErrorTypeProviderError, "Anthropic stream error")
+			}
+			return
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		fail(types.ErrorTypeProviderError, fmt.Sprintf("Stream reading error: %v", err))
+		return
+	}
+	fail(types.ErrorTypeProviderError, "Stream ended before the response completed")
+}
+
+// Conversion methods
+
+// convertCompletionRequest builds a Messages API request. Anthropic takes a
+// single top-level system prompt, so every system message is kept, in
+// order, as its own paragraph, as on the Bedrock Claude path. Tool results
+// are sent back as tool_result blocks of a user turn.
+func (c *AnthropicClient) convertCompletionRequest(req *types.CompletionRequest) *AnthropicMessageRequest {
+	anthropicReq := &AnthropicMessageRequest{
+		Model:       req.Model,
+		Messages:    []AnthropicMessage{},
+		MaxTokens:   anthropicDefaultMaxTokens,
+		Temperature: req.Temperature,
+		TopP:        req.TopP,
+		Stop:        req.Stop,
+		Stream:      req.Stream,
+	}
+	if anthropicReq.Model == "" {
+		anthropicReq.Model = anthropicDefaultModel
+	}
+	if req.MaxTokens != nil {
+		anthropicReq.MaxTokens = *req.MaxTokens
+	}
+	if req.User != "" {
+		anthropicReq.Metadata = &AnthropicMetadata{UserID: req.User}
+	}
+
+	var systemMessages []string
+	for _, msg := range req.Messages {
+		switch msg.Role {
+		case domain.MessageRoleSystem:
+			if text := messageText(msg); text != "" {
+				systemMessages = append(systemMessages, text)
+			}
+		case domain.MessageRoleTool:
+			anthropicReq.Messages = append(anthropicReq.Messages, AnthropicMessage{
+				Role: "user",
+				Content: []AnthropicContentBlock{{
+					Type:      "tool_result",
+					ToolUseID: msg.ToolCallID,
+					Content:   messageText(msg),
+				}},
+			})
+		case domain.MessageRoleAssistant:
+			anthropicReq.Messages = append(anthropicReq.Messages, AnthropicMessage{
+				Role:    "assistant",
+				Content: c.convertContent(msg),
+			})
+		default:
+			anthropicReq.Messages = append(anthropicReq.Messages, AnthropicMessage{
+				Role:    "user",
+				Content: c.convertContent(msg),
+			})
+		}
+	}
+	if len(systemMessages) > 0 {
+		anthropicReq.System = strings.Join(systemMessages, "\n\n")
+	}
+
+	// A trailing assistant message is continued as a partial turn, which
+	// Anthropic rejects if it ends in whitespace
+	if n := len(anthropicReq.Messages); n > 1 && anthropicReq.Messages[n-1].Role == "assistant" {
+		blocks := anthropicReq.Messages[n-1].Content
+		if last := len(blocks) - 1; last >= 0 && blocks[last].Type == "text" {
+			blocks[last].Text = strings.TrimRightFunc(blocks[last].Text, unicode.IsSpace)
+		}
+	}
+
+	return anthropicReq
+}
+
+// convertContent converts a message's text and image parts, and an
+// assistant's tool calls, into content blocks
+func (c *AnthropicClient) convertContent(msg domain.Message) []AnthropicContentBlock {
+	blocks := []AnthropicContentBlock{}
+	for _, part := range msg.Content {
+		switch part.Type {
+		case domain.ContentTypeText:
+			if part.Text != "" {
+				blocks = append(blocks, AnthropicContentBlock{Type: "text", Text: part.Text})
+			}
+		case domain.ContentTypeImageURL:
+			if part.ImageURL != nil {
+				blocks = append(blocks, AnthropicContentBlock{Type: "image", Source: anthropicImageSource(part.ImageURL.URL)})
+			}
+		}
+	}
+
+	for _, toolCall := range msg.ToolCalls {
+		input := json.RawMessage(toolCall.Function.Arguments)
+		if !json.Valid(input) {
+			input = json.RawMessage("{}")
+		}
+		blocks = append(blocks, AnthropicContentBlock{
+			Type:  "tool_use",
+			ID:    toolCall.ID,
+			Name:  toolCall.Function.Name,
+			Input: input,
+		})
+	}
+	return blocks
+}
+
+// anthropicImageSource sends a data URL's image inline and any other URL by
+// reference
+func anthropicImageSource(imageURL string) *AnthropicImageSource {
+	if rest, ok := strings.CutPrefix(imageURL, "data:"); ok {
+		if mediaType, data, ok := strings.Cut(rest, ";base64,"); ok {
+			return &AnthropicImageSource{Type: "base64", MediaType: mediaType, Data: data}
+		}
+	}
+	return &AnthropicImageSource{Type: "url", URL: imageURL}
+}
+
+func (c *AnthropicClient) convertCompletionResponse(resp *AnthropicMessageResponse, requestID string, responseTime time.Duration) (*types.CompletionResponse, error) {
+	if len(resp.Content) == 0 && resp.StopReason == "" {
+		return nil, &types.QLensError{
+			Type:      types.ErrorTypeProviderError,
+			Message:   "Anthropic returned no content",
+			Provider:  c.Provider(),
+			RequestID: requestID,
+			Details: map[string]interface{}{
+				"model":       resp.Model,
+				"response_id": resp.ID,
+			},
+		}
+	}
+
+	text := ""
+	parts := []domain.ContentPart{}
+	var toolCalls []domain.ToolCall
+	for _, block := range resp.Content {
+		switch block.Type {
+		case "text":
+			text += block.Text
+		case "thinking":
+			parts = append(parts, domain.ContentPart{Type: domain.ContentTypeReasoning, Text: block.Thinking})
+		case "tool_use":
+			arguments := string(block.Input)
+			if arguments == "" {
+				arguments = "{}"
+			}
+			toolCalls = append(toolCalls, domain.ToolCall{
+				ID:   block.ID,
+				Type: "function",
+				Function: domain.FunctionCall{
+					Name:      block.Name,
+					Arguments: arguments,
+				},
+			})
+		}
+	}
+	if text != "" {
+		parts = append(parts, domain.ContentPart{Type: domain.ContentTypeText, Text: text})
+	}
+
+	choice := domain.Choice{
+		Index: 0,
+		ID:    domain.ChoiceID(resp.ID, 0),
+		Message: domain.Message{
+			Role:      domain.MessageRoleAssistant,
+			Content:   parts,
+			ToolCalls: toolCalls,
+		},
+		FinishReason: convertAnthropicStopReason(resp.StopReason),
+	}
+	choice.NormalizeToolCalls()
+
+	usage := domain.Usage{
+		PromptTokens:     resp.Usage.InputTokens,
+		CompletionTokens: resp.Usage.OutputTokens,
+		TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
+	}
+	checkUsage(c.Provider(), &usage, false)
+	usage.CostUSD = c.calculateCost(resp.Model, usage)
+
+	return &types.CompletionResponse{
+		ID:           resp.ID,
+		Object:       "chat.completion",
+		Created:      time.Now().Unix(),
+		Model:        resp.Model,
+		Provider:     c.Provider(),
+		Choices:      []domain.Choice{choice},
+		Usage:        usage,
+		ResponseTime: responseTime,
+		RequestID:    requestID,
+	}, nil
+}
+
+// convertAnthropicStopReason maps a Messages API stop reason to a finish
+// reason
+func convertAnthropicStopReason(stopReason string) domain.FinishReason {
+	switch stopReason {
+	case "max_tokens":
+		return domain.FinishReasonLength
+	case "tool_use":
+		return domain.FinishReasonToolCalls
+	case "refusal":
+		return domain.FinishReasonRefusal
+	default:
+		// end_turn, stop_sequence and pause_turn
+		return domain.FinishReasonStop
+	}
+}
+
+func (c *AnthropicClient) convertModel(anthropicModel *AnthropicModel) types.Model {
+	name := anthropicModel.DisplayName
+	if name == "" {
+		name = anthropicModel.ID
+	}
+
+	contextLength, ok := c.models.contextLength(anthropicModel.ID)
+	if !ok {
+		contextLength = 200000
+	}
+
+	capabilities := []domain.Capability{domain.CapabilityCompletion, domain.CapabilityVision, domain.CapabilityFunctionCalling}
+	if configured, ok := c.models.capabilities(anthropicModel.ID); ok {
+		capabilities = configured
+	}
+
+	pricing, _ := c.models.pricing(anthropicModel.ID)
+
+	return types.Model{
+		ID:            anthropicModel.ID,
+		Provider:      c.Provider(),
+		Name:          name,
+		Description:   fmt.Sprintf("Anthropic %s model", name),
+		Capabilities:  capabilities,
+		ContextLength: contextLength,
+		Pricing:       pricing,
+		Status:        domain.ModelStatusAvailable,
+		ProviderData: map[string]interface{}{
+			"created_at": anthropicModel.CreatedAt,
+			"type":       anthropicModel.Type,
+		},
+	}
+}
+
+// calculateCost prices a completion from the pricing table; models it does
+// not cover cost nothing
+func (c *AnthropicClient) calculateCost(model string, usage domain.Usage) float64 {
+	pricing, ok := c.models.pricing(model)
+	if !ok {
+		return 0
+	}
+	return pricing.CompletionCost(usage)
+}
+
+// Anthropic API types
+
+type AnthropicMessageRequest struct {
+	Model       string             `json:"model"`
+	Messages    []AnthropicMessage `json:"messages"`
+	System      string             `json:"system,omitempty"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature *float64           `json:"temperature,omitempty"`
+	TopP        *float64           `json:"top_p,omitempty"`
+	Stop        []string           `json:"stop_sequences,omitempty"`
+	Stream      bool               `json:"stream,omitempty"`
+	Metadata    *AnthropicMetadata `json:"metadata,omitempty"`
+}
+
+type AnthropicMetadata struct {
+	UserID string `json:"user_id,omitempty"`
+}
+
+type AnthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []AnthropicContentBlock `json:"content"`
+}
+
+type AnthropicContentBlock struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Thinking string `json:"thinking,omitempty"`
+
+	// image blocks
+	Source *AnthropicImageSource `json:"source,omitempty"`
+
+	// tool_use blocks
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// tool_result blocks
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+type AnthropicImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
+
+type AnthropicMessageResponse struct {
+	ID           string                  `json:"id"`
+	Type         string                  `json:"type"`
+	Role         string                  `json:"role"`
+	Model        string                  `json:"model"`
+	Content      []AnthropicContentBlock `json:"content"`
+	StopReason   string                  `json:"stop_reason"`
+	StopSequence string                  `json:"stop_sequence,omitempty"`
+	Usage        AnthropicUsage          `json:"usage"`
+}
+
+type AnthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type AnthropicStreamEvent struct {
+	Type         string                    `json:"type"`
+	Index        int                       `json:"index"`
+	Message      *AnthropicMessageResponse `json:"message,omitempty"`
+	ContentBlock *AnthropicContentBlock    `json:"content_block,omitempty"`
+	Delta        *AnthropicStreamDelta     `json:"delta,omitempty"`
+	Usage        *AnthropicUsage           `json:"usage,omitempty"`
+	Error        *AnthropicErrorDetail     `json:"error,omitempty"`
+}
+
+type AnthropicStreamDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	PartialJSON string `json:"partial_json,omitempty"`
+	StopReason  string `json:"stop_reason,omitempty"`
+}
+
+type AnthropicModelsResponse struct {
+	Data    []AnthropicModel `json:"data"`
+	HasMore bool             `json:"has_more"`
+}
+
+type AnthropicModel struct {
+	ID          string `json:"id"`
+	Type        string `json:"type"`
+	DisplayName string `json:"display_name"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type AnthropicError struct {
+	Type  string               `json:"type"`
+	Error AnthropicErrorDetail `json:"error"`
+}
+
+type AnthropicErrorDetail struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
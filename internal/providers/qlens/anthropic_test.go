@@ -0,0 +1,337 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+	"github.com/quantum-suite/platform/pkg/shared/version"
+)
+
+func textMessage(role domain.MessageRole, text string) domain.Message {
+	return domain.Message{Role: role, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: text}}}
+}
+
+func TestAnthropicConvertCompletionRequest(t *testing.T) {
+	client := NewAnthropicClient(types.ProviderConfig{})
+	toolUse := domain.Message{
+		Role:      domain.MessageRoleAssistant,
+		ToolCalls: []domain.ToolCall{{ID: "toolu_1", Type: "function", Function: domain.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}}},
+	}
+	toolResult := textMessage(domain.MessageRoleTool, "18C and sunny")
+	toolResult.ToolCallID = "toolu_1"
+	image := domain.Message{Role: domain.MessageRoleUser, Content: []domain.ContentPart{
+		{Type: domain.ContentTypeText, Text: "And this?"},
+		{Type: domain.ContentTypeImageURL, ImageURL: &domain.ImageURL{URL: "data:image/png;base64,iVBORw0KGgo="}},
+	}}
+
+	req := client.convertCompletionRequest(&types.CompletionRequest{
+		Model: "claude-3-5-sonnet-20241022",
+		Messages: []domain.Message{
+			textMessage(domain.MessageRoleSystem, "You are terse."),
+			textMessage(domain.MessageRoleUser, "Weather in Paris?"),
+			toolUse,
+			toolResult,
+			textMessage(domain.MessageRoleSystem, "Answer in French."),
+			image,
+			textMessage(domain.MessageRoleAssistant, "Voici "),
+		},
+		User: "user-1",
+	})
+
+	// System messages move to the top-level prompt, in order
+	assert.Equal(t, "You are terse.\n\nAnswer in French.", req.System)
+	assert.Equal(t, anthropicDefaultMaxTokens, req.MaxTokens)
+	assert.Equal(t, "user-1", req.Metadata.UserID)
+	require.Len(t, req.Messages, 5)
+
+	assert.Equal(t, "assistant", req.Messages[1].Role)
+	assert.Equal(t, "tool_use", req.Messages[1].Content[0].Type)
+	assert.JSONEq(t, `{"city":"Paris"}`, string(req.Messages[1].Content[0].Input))
+
+	assert.Equal(t, "user", req.Messages[2].Role)
+	assert.Equal(t, AnthropicContentBlock{Type: "tool_result", ToolUseID: "toolu_1", Content: "18C and sunny"}, req.Messages[2].Content[0])
+
+	assert.Equal(t, &AnthropicImageSource{Type: "base64", MediaType: "image/png", Data: "iVBORw0KGgo="}, req.Messages[3].Content[1].Source)
+
+	// The prefill is trimmed, as Anthropic rejects trailing whitespace
+	assert.Equal(t, "Voici", req.Messages[4].Content[0].Text)
+}
+
+func TestAnthropicCreateCompletion(t *testing.T) {
+	var headers http.Header
+	var body AnthropicMessageRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/messages", r.URL.Path)
+		headers = r.Header.Clone()
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Request-Id", "req_anthropic_1")
+		w.Write([]byte(`{
+			"id": "msg_1",
+			"type": "message",
+			"role": "assistant",
+			"model": "claude-3-5-sonnet-20241022",
+			"content": [
+				{"type": "text", "text": "Let me check."},
+				{"type": "tool_use", "id": "toolu_1", "name": "get_weather", "input": {"city": "Paris"}}
+			],
+			"stop_reason": "tool_use",
+			"usage": {"input_tokens": 1000, "output_tokens": 1000}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(types.ProviderConfig{APIKey: "secret", BaseURL: server.URL})
+	maxTokens := 256
+	response, err := client.CreateCompletion(context.Background(), &types.CompletionRequest{
+		Model:     "claude-3-5-sonnet-20241022",
+		Messages:  []domain.Message{textMessage(domain.MessageRoleUser, "Weather in Paris?")},
+		MaxTokens: &maxTokens,
+		RequestID: "req_123",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "secret", headers.Get("x-api-key"))
+	assert.Equal(t, anthropicAPIVersion, headers.Get("anthropic-version"))
+	assert.Equal(t, version.UserAgent(""), headers.Get("User-Agent"))
+	assert.Equal(t, "req_123", headers.Get("X-Client-Request-Id"))
+	assert.Equal(t, 256, body.MaxTokens)
+
+	assert.Equal(t, domain.ProviderAnthropic, response.Provider)
+	assert.Equal(t, "req_anthropic_1", response.ProviderRequestID)
+	require.Len(t, response.Choices, 1)
+	choice := response.Choices[0]
+	assert.Equal(t, domain.FinishReasonToolCalls, choice.FinishReason)
+	assert.Equal(t, "Let me check.", choice.Message.Content[0].Text)
+	require.Len(t, choice.Message.ToolCalls, 1)
+	assert.Equal(t, "get_weather", choice.Message.ToolCalls[0].Function.Name)
+	assert.JSONEq(t, `{"city":"Paris"}`, choice.Message.ToolCalls[0].Function.Arguments)
+
+	assert.Equal(t, 2000, response.Usage.TotalTokens)
+	assert.InDelta(t, 0.018, response.Usage.CostUSD, 1e-9)
+}
+
+func TestAnthropicCreateCompletion_TypedErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantType string
+	}{
+		{name: "invalid request", status: http.StatusBadRequest, body: `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: field required"}}`, wantType: types.ErrorTypeInvalidRequest},
+		{name: "overloaded", status: 529, body: `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, wantType: types.ErrorTypeProviderUnavailable},
+		{name: "rate limited", status: http.StatusTooManyRequests, body: `{"type":"error","error":{"type":"rate_limit_error","message":"Slow down"}}`, wantType: types.ErrorTypeRateLimitExceeded},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewAnthropicClient(types.ProviderConfig{APIKey: "secret", BaseURL: server.URL})
+			req := &types.CompletionRequest{Model: "claude-3-haiku-20240307", TenantID: "tenant-a"}
+
+			_, completionErr := client.CreateCompletion(context.Background(), req)
+			_, streamErr := client.CreateCompletionStream(context.Background(), req)
+			for _, err := range []error{completionErr, streamErr} {
+				var qlensErr *types.QLensError
+				require.True(t, errors.As(err, &qlensErr), "expected a QLensError, got %v", err)
+				assert.Equal(t, tt.wantType, qlensErr.Type)
+				assert.Equal(t, domain.TenantID("tenant-a"), qlensErr.TenantID)
+			}
+		})
+	}
+}
+
+func TestAnthropicCreateCompletionStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body AnthropicMessageRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.True(t, body.Stream)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, `event: message_start
+data: {"type":"message_start","message":{"id":"msg_1","model":"claude-3-5-sonnet-20241022","usage":{"input_tokens":10,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: ping
+data: {"type":"ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hel"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"lo"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: content_block_start
+data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"\"Paris\"}"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":1}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":15}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`)
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(types.ProviderConfig{APIKey: "secret", BaseURL: server.URL})
+	stream, err := client.CreateCompletionStream(context.Background(), &types.CompletionRequest{
+		Model:    "claude-3-5-sonnet-20241022",
+		Messages: []domain.Message{textMessage(domain.MessageRoleUser, "Weather in Paris?")},
+	})
+	require.NoError(t, err)
+
+	var text string
+	var toolCalls []domain.ToolCall
+	var finish *domain.FinishReason
+	var done bool
+	var usage *domain.Usage
+	for chunk := range stream {
+		require.Nil(t, chunk.Error)
+		if chunk.Done {
+			done = true
+			usage = chunk.Usage
+			continue
+		}
+		assert.Equal(t, "msg_1-0", chunk.Choices[0].ID)
+		delta := chunk.Choices[0].Delta
+		if delta.Content != nil {
+			text += *delta.Content
+		}
+		toolCalls = append(toolCalls, delta.ToolCalls...)
+		if chunk.Choices[0].FinishReason != nil {
+			finish = chunk.Choices[0].FinishReason
+		}
+	}
+
+	assert.True(t, done)
+	assert.Equal(t, "Hello", text)
+	require.Len(t, toolCalls, 1)
+	assert.Equal(t, "toolu_1", toolCalls[0].ID)
+	assert.Equal(t, `{"city":"Paris"}`, toolCalls[0].Function.Arguments)
+	require.NotNil(t, finish)
+	assert.Equal(t, domain.FinishReasonToolCalls, *finish)
+
+	require.NotNil(t, usage)
+	assert.Equal(t, 10, usage.PromptTokens)
+	assert.Equal(t, 15, usage.CompletionTokens)
+	assert.Equal(t, 25, usage.TotalTokens)
+	assert.Greater(t, usage.CostUSD, 0.0)
+}
+
+func TestAnthropicHandleStream_ErrorEvent(t *testing.T) {
+	client := NewAnthropicClient(types.ProviderConfig{})
+	body := io.NopCloser(strings.NewReader("event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"))
+	streamChan := make(chan types.StreamResponse, 1)
+
+	client.handleStream(context.Background(), body, streamChan, "req-1", "")
+
+	chunk := <-streamChan
+	require.NotNil(t, chunk.Error)
+	assert.Equal(t, types.ErrorTypeProviderUnavailable, chunk.Error.Type)
+	assert.Equal(t, "Overloaded", chunk.Error.Message)
+}
+
+func TestAnthropicHandleStream_EndsWithoutMessageStop(t *testing.T) {
+	client := NewAnthropicClient(types.ProviderConfig{})
+	body := io.NopCloser(strings.NewReader("data: {\"type\":\"message_start\",\"message\":{\"id\":\"msg_1\"}}\n\n"))
+	streamChan := make(chan types.StreamResponse, 2)
+
+	client.handleStream(context.Background(), body, streamChan, "req-1", "")
+
+	<-streamChan
+	chunk := <-streamChan
+	require.NotNil(t, chunk.Error)
+	assert.Equal(t, "Stream ended before the response completed", chunk.Error.Message)
+	assert.Equal(t, "req-1", chunk.RequestID)
+	assert.False(t, chunk.Done)
+}
+
+func TestAnthropicHandleStream_StopsWhenContextCancelled(t *testing.T) {
+	client := NewAnthropicClient(types.ProviderConfig{})
+	body := io.NopCloser(strings.NewReader("data: {\"type\":\"message_stop\"}\n\n"))
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Nobody reads the stream, so the final send must give up once the
+	// context is cancelled rather than block
+	streamChan := make(chan types.StreamResponse)
+	finished := make(chan struct{})
+	go func() {
+		client.handleStream(ctx, body, streamChan, "req-1", "")
+		close(finished)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-finished:
+	case <-time.After(time.Second):
+		t.Fatal("handleStream blocked after the context was cancelled")
+	}
+}
+
+func TestConvertAnthropicStopReason(t *testing.T) {
+	assert.Equal(t, domain.FinishReasonStop, convertAnthropicStopReason("end_turn"))
+	assert.Equal(t, domain.FinishReasonStop, convertAnthropicStopReason("stop_sequence"))
+	assert.Equal(t, domain.FinishReasonLength, convertAnthropicStopReason("max_tokens"))
+	assert.Equal(t, domain.FinishReasonToolCalls, convertAnthropicStopReason("tool_use"))
+	assert.Equal(t, domain.FinishReasonRefusal, convertAnthropicStopReason("refusal"))
+}
+
+func TestAnthropicClient_Pricing(t *testing.T) {
+	client := NewAnthropicClient(types.ProviderConfig{
+		Models: map[string]types.ModelSpec{
+			"claude-3-opus": {Pricing: &domain.ModelPricing{InputTokenCost: 1, OutputTokenCost: 2, Unit: "token"}},
+		},
+	})
+	usage := domain.Usage{PromptTokens: 1000, CompletionTokens: 1000}
+
+	// Dated IDs use their family's price, which configuration can override
+	assert.InDelta(t, 0.0015, client.calculateCost("claude-3-haiku-20240307", usage), 1e-9)
+	assert.InDelta(t, 3000, client.calculateCost("claude-3-opus-20240229", usage), 1e-9)
+	assert.Zero(t, client.calculateCost("claude-instant-1.2", usage))
+}
+
+func TestAnthropicWarmConnection(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.RequestURI()+" "+r.Header.Get("X-Api-Key"))
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewAnthropicClient(types.ProviderConfig{APIKey: "secret", BaseURL: server.URL})
+	require.NoError(t, client.WarmConnection(context.Background()))
+	assert.Equal(t, []string{"GET /models?limit=1 secret"}, requests)
+}
@@ -5,29 +5,49 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/quantum-suite/platform/internal/domain"
 	"github.com/quantum-suite/platform/pkg/qlens-types"
+	"github.com/quantum-suite/platform/pkg/shared/version"
 )
 
-// OpenAIClient implements the ProviderClient interface for OpenAI
-type OpenAIClient struct {
+// OpenAICompatibleClient implements the ProviderClient interface for any API
+// that speaks the OpenAI wire format. The profile supplies the provider's
+// base URL, auth header style and pricing table.
+type OpenAICompatibleClient struct {
 	config     types.ProviderConfig
+	profile    OpenAICompatibleProfile
+	models     PricingTable
 	httpClient *http.Client
 	baseURL    string
 	apiKey     string
 }
 
+// OpenAIClient is the OpenAI-compatible client preconfigured for OpenAI
+type OpenAIClient = OpenAICompatibleClient
+
 // NewOpenAIClient creates a new OpenAI client
 func NewOpenAIClient(config types.ProviderConfig) *OpenAIClient {
+	return NewOpenAICompatibleClient(OpenAIProfile, config)
+}
+
+// NewOpenAICompatibleClient creates a client for the API described by the
+// profile. Base URL, auth style and model specs in config override it.
+func NewOpenAICompatibleClient(profile OpenAICompatibleProfile, config types.ProviderConfig) *OpenAICompatibleClient {
+	if config.Provider != "" {
+		profile.Provider = config.Provider
+	}
+
 	baseURL := config.BaseURL
 	if baseURL == "" {
-		baseURL = "https://api.openai.com/v1"
+		baseURL = profile.BaseURL
 	}
 
 	timeout := config.Timeout
@@ -35,8 +55,10 @@ func NewOpenAIClient(config types.ProviderConfig) *OpenAIClient {
 		timeout = 30 * time.Second
 	}
 
-	return &OpenAIClient{
+	return &OpenAICompatibleClient{
 		config:  config,
+		profile: profile,
+		models:  profile.Models.merge(config.Models),
 		baseURL: baseURL,
 		apiKey:  config.APIKey,
 		httpClient: &http.Client{
@@ -46,58 +68,91 @@ func NewOpenAIClient(config types.ProviderConfig) *OpenAIClient {
 }
 
 // Provider returns the provider type
-func (c *OpenAIClient) Provider() domain.Provider {
-	return domain.ProviderOpenAI
+func (c *OpenAICompatibleClient) Provider() domain.Provider {
+	return c.profile.Provider
 }
 
 // Name returns the provider name
-func (c *OpenAIClient) Name() string {
-	return "OpenAI"
+func (c *OpenAICompatibleClient) Name() string {
+	return c.profile.Name
 }
 
 // CreateCompletion creates a completion using OpenAI API
-func (c *OpenAIClient) CreateCompletion(ctx context.Context, req *types.CompletionRequest) (*types.CompletionResponse, error) {
+func (c *OpenAICompatibleClient) CreateCompletion(ctx context.Context, req *types.CompletionRequest) (*types.CompletionResponse, error) {
+	if c.apiFormat(req.Model) == types.APIFormatResponses {
+		return c.createResponse(ctx, req)
+	}
+
 	start := time.Now()
 
 	// Convert request to OpenAI format
 	openAIReq := c.convertCompletionRequest(req)
 
 	// Make API request
-	respData, err := c.makeRequest(ctx, "POST", "/chat/completions", openAIReq)
+	respData, header, err := c.doRequest(ctx, "POST", "/chat/completions", openAIReq, req.RequestID)
 	if err != nil {
-		return nil, fmt.Errorf("OpenAI API request failed: %w", err)
+		attributeError(err, req.TenantID, req.UserID, req.RequestID)
+		return nil, fmt.Errorf("%s API request failed: %w", c.profile.Name, err)
 	}
 
 	// Parse OpenAI response
 	var openAIResp OpenAIChatCompletionResponse
 	if err := json.Unmarshal(respData, &openAIResp); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI response: %w", err)
+		return nil, fmt.Errorf("failed to parse %s response: %w", c.profile.Name, err)
 	}
 
 	// Convert to QLens response
-	response := c.convertCompletionResponse(&openAIResp, req.RequestID, time.Since(start))
+	response, err := c.convertCompletionResponse(&openAIResp, req.RequestID, time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+	response.ProviderRequestID = header.Get(providerRequestIDHeader)
+	if req.IncludeRawResponse {
+		response.AttachRawResponse(respData)
+	}
 
 	return response, nil
 }
 
 // CreateCompletionStream creates a streaming completion using OpenAI API
-func (c *OpenAIClient) CreateCompletionStream(ctx context.Context, req *types.CompletionRequest) (<-chan types.StreamResponse, error) {
+func (c *OpenAICompatibleClient) CreateCompletionStream(ctx context.Context, req *types.CompletionRequest) (<-chan types.StreamResponse, error) {
+	if c.apiFormat(req.Model) == types.APIFormatResponses {
+		return c.createResponseStream(ctx, req)
+	}
+
 	// Convert request to OpenAI format with streaming enabled
 	openAIReq := c.convertCompletionRequest(req)
 	openAIReq.Stream = true
 
+	resp, err := c.openStream(ctx, "/chat/completions", openAIReq, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create stream channel
+	streamChan := make(chan types.StreamResponse)
+
+	go c.handleStream(ctx, resp.Body, streamChan, req.RequestID, resp.Header.Get(providerRequestIDHeader))
+
+	return streamChan, nil
+}
+
+// openStream starts a streaming request and returns the response once the
+// provider has accepted it
+func (c *OpenAICompatibleClient) openStream(ctx context.Context, path string, body interface{}, req *types.CompletionRequest) (*http.Response, error) {
 	// Create HTTP request
-	reqBody, err := json.Marshal(openAIReq)
+	reqBody, err := json.Marshal(body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(reqBody))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewReader(reqBody))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	c.setHeaders(httpReq)
+	setClientRequestID(httpReq, req.RequestID)
 	httpReq.Header.Set("Accept", "text/event-stream")
 
 	// Make request
@@ -106,50 +161,51 @@ func (c *OpenAIClient) CreateCompletionStream(ctx context.Context, req *types.Co
 		return nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
+	// Errors are typed the same as for non-streaming calls. Some providers
+	// answer a stream request with a plain JSON error body and a 200.
+	if resp.StatusCode != http.StatusOK || isJSONResponse(resp.Header) {
 		defer resp.Body.Close()
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("OpenAI API error: %s", string(body))
+		return nil, attributeError(c.responseError(resp, body), req.TenantID, req.UserID, req.RequestID)
 	}
 
-	// Create stream channel
-	streamChan := make(chan types.StreamResponse)
-
-	go c.handleStream(ctx, resp.Body, streamChan, req.RequestID)
-
-	return streamChan, nil
+	return resp, nil
 }
 
 // CreateEmbeddings creates embeddings using OpenAI API
-func (c *OpenAIClient) CreateEmbeddings(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+func (c *OpenAICompatibleClient) CreateEmbeddings(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
 	start := time.Now()
 
 	// Convert request to OpenAI format
 	openAIReq := c.convertEmbeddingRequest(req)
 
 	// Make API request
-	respData, err := c.makeRequest(ctx, "POST", "/embeddings", openAIReq)
+	respData, header, err := c.doRequest(ctx, "POST", "/embeddings", openAIReq, req.RequestID)
 	if err != nil {
-		return nil, fmt.Errorf("OpenAI API request failed: %w", err)
+		attributeError(err, req.TenantID, req.UserID, req.RequestID)
+		return nil, fmt.Errorf("%s API request failed: %w", c.profile.Name, err)
 	}
 
 	// Parse OpenAI response
 	var openAIResp OpenAIEmbeddingResponse
 	if err := json.Unmarshal(respData, &openAIResp); err != nil {
-		return nil, fmt.Errorf("failed to parse OpenAI embedding response: %w", err)
+		return nil, fmt.Errorf("failed to parse %s embedding response: %w", c.profile.Name, err)
 	}
 
 	// Convert to QLens response
-	response := c.convertEmbeddingResponse(&openAIResp, req.RequestID, time.Since(start))
-
+	response, err := c.convertEmbeddingResponse(&openAIResp, len(req.Input), req.EncodingFormat, req.RequestID, time.Since(start))
+	if err != nil {
+		return nil, err
+	}
+	response.ProviderRequestID = header.Get(providerRequestIDHeader)
 	return response, nil
 }
 
 // ListModels lists available models from OpenAI
-func (c *OpenAIClient) ListModels(ctx context.Context) ([]types.Model, error) {
+func (c *OpenAICompatibleClient) ListModels(ctx context.Context) ([]types.Model, error) {
 	respData, err := c.makeRequest(ctx, "GET", "/models", nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list OpenAI models: %w", err)
+		return nil, fmt.Errorf("failed to list %s models: %w", c.profile.Name, err)
 	}
 
 	var openAIResp OpenAIModelsResponse
@@ -167,10 +223,10 @@ func (c *OpenAIClient) ListModels(ctx context.Context) ([]types.Model, error) {
 }
 
 // GetModel gets a specific model from OpenAI
-func (c *OpenAIClient) GetModel(ctx context.Context, modelID string) (*types.Model, error) {
+func (c *OpenAICompatibleClient) GetModel(ctx context.Context, modelID string) (*types.Model, error) {
 	respData, err := c.makeRequest(ctx, "GET", "/models/"+modelID, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get OpenAI model: %w", err)
+		return nil, fmt.Errorf("failed to get %s model: %w", c.profile.Name, err)
 	}
 
 	var openAIModel OpenAIModel
@@ -183,13 +239,20 @@ func (c *OpenAIClient) GetModel(ctx context.Context, modelID string) (*types.Mod
 }
 
 // HealthCheck performs a health check against OpenAI API
-func (c *OpenAIClient) HealthCheck(ctx context.Context) error {
+func (c *OpenAICompatibleClient) HealthCheck(ctx context.Context) error {
+	_, err := c.makeRequest(ctx, "GET", "/models", nil)
+	return err
+}
+
+// WarmConnection sends a single models request, which is not billed, to
+// open a pooled connection before the first real request needs one
+func (c *OpenAICompatibleClient) WarmConnection(ctx context.Context) error {
 	_, err := c.makeRequest(ctx, "GET", "/models", nil)
 	return err
 }
 
 // Configure updates the client configuration
-func (c *OpenAIClient) Configure(config types.ProviderConfig) error {
+func (c *OpenAICompatibleClient) Configure(config types.ProviderConfig) error {
 	c.config = config
 	c.apiKey = config.APIKey
 
@@ -205,12 +268,12 @@ func (c *OpenAIClient) Configure(config types.ProviderConfig) error {
 }
 
 // GetConfig returns the current configuration
-func (c *OpenAIClient) GetConfig() types.ProviderConfig {
+func (c *OpenAICompatibleClient) GetConfig() types.ProviderConfig {
 	return c.config
 }
 
 // Close cleans up resources
-func (c *OpenAIClient) Close() error {
+func (c *OpenAICompatibleClient) Close() error {
 	// Close HTTP client if needed
 	c.httpClient.CloseIdleConnections()
 	return nil
@@ -218,57 +281,200 @@ func (c *OpenAIClient) Close() error {
 
 // Helper methods
 
-func (c *OpenAIClient) makeRequest(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+func (c *OpenAICompatibleClient) makeRequest(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	respBody, _, err := c.doRequest(ctx, method, path, body, "")
+	return respBody, err
+}
+
+// doRequest makes an API request tagged with the caller's request ID and
+// returns the response headers along with the body
+func (c *OpenAICompatibleClient) doRequest(ctx context.Context, method, path string, body interface{}, requestID string) ([]byte, http.Header, error) {
 	var reqBody io.Reader
 	if body != nil {
 		jsonBody, err := json.Marshal(body)
 		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+			return nil, nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
 		reqBody = bytes.NewReader(jsonBody)
 	}
 
 	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+		return nil, nil, fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	c.setHeaders(req)
+	setClientRequestID(req, requestID)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return nil, nil, fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	respBody, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		var openAIErr OpenAIError
-		if err := json.Unmarshal(respBody, &openAIErr); err == nil {
-			return nil, &types.QLensError{
-				Type:     types.ErrorTypeProviderError,
-				Message:  openAIErr.Error.Message,
-				Code:     openAIErr.Error.Code,
-				Provider: domain.ProviderOpenAI,
-			}
+		return nil, nil, c.responseError(resp, respBody)
+	}
+
+	return respBody, resp.Header, nil
+}
+
+// maxErrorBodyLength bounds how much of an unparseable error body is quoted
+// in the error message
+const maxErrorBodyLength = 512
+
+// responseError converts a failed provider response into a QLensError typed
+// by its status, so retries and HTTP status mapping treat every call alike
+func (c *OpenAICompatibleClient) responseError(resp *http.Response, body []byte) error {
+	var openAIErr OpenAIError
+	if err := json.Unmarshal(body, &openAIErr); err != nil || openAIErr.Error.Message == "" {
+		message := strings.TrimSpace(string(body))
+		if len(message) > maxErrorBodyLength {
+			message = message[:maxErrorBodyLength]
+		}
+		if message == "" {
+			message = http.StatusText(resp.StatusCode)
+		}
+		return &types.QLensError{
+			Type:       errorTypeForResponse(resp.StatusCode, ""),
+			Message:    fmt.Sprintf("%s API error (%d): %s", c.profile.Name, resp.StatusCode, message),
+			Details:    withProviderRequestID(nil, resp.Header),
+			Provider:   c.Provider(),
+			StatusCode: resp.StatusCode,
 		}
-		return nil, fmt.Errorf("OpenAI API error: %s", string(respBody))
 	}
 
-	return respBody, nil
+	return &types.QLensError{
+		Type:       errorTypeForResponse(resp.StatusCode, openAIErr.Error.Type),
+		Message:    openAIErr.Error.Message,
+		Code:       openAIErr.Error.Code,
+		Details:    withProviderRequestID(openAIErr.details(), resp.Header),
+		Provider:   c.Provider(),
+		StatusCode: resp.StatusCode,
+	}
+}
+
+// errorTypeForResponse maps a provider's HTTP status, or failing that its
+// error type, to a QLens error type
+func errorTypeForResponse(statusCode int, providerType string) string {
+	switch {
+	case statusCode == http.StatusBadRequest || statusCode == http.StatusUnprocessableEntity:
+		return types.ErrorTypeInvalidRequest
+	case statusCode == http.StatusUnauthorized:
+		return types.ErrorTypeAuthenticationError
+	case statusCode == http.StatusForbidden:
+		return types.ErrorTypeAuthorizationError
+	case statusCode == http.StatusNotFound:
+		return types.ErrorTypeNotFound
+	case statusCode == http.StatusTooManyRequests:
+		return types.ErrorTypeRateLimitExceeded
+	case statusCode == http.StatusRequestTimeout || statusCode == http.StatusGatewayTimeout:
+		return types.ErrorTypeTimeout
+	case statusCode >= http.StatusInternalServerError:
+		return types.ErrorTypeProviderUnavailable
+	}
+
+	switch providerType {
+	case "invalid_request_error":
+		return types.ErrorTypeInvalidRequest
+	case "authentication_error":
+		return types.ErrorTypeAuthenticationError
+	case "permission_error":
+		return types.ErrorTypeAuthorizationError
+	case "not_found_error":
+		return types.ErrorTypeNotFound
+	case "rate_limit_error":
+		return types.ErrorTypeRateLimitExceeded
+	case "server_error", "overloaded_error", "api_error":
+		return types.ErrorTypeProviderUnavailable
+	}
+	return types.ErrorTypeProviderError
 }
 
-func (c *OpenAIClient) setHeaders(req *http.Request) {
+// withProviderRequestID adds the provider's ID for a failed call to an
+// error's details
+func withProviderRequestID(details map[string]interface{}, header http.Header) map[string]interface{} {
+	providerID := header.Get(providerRequestIDHeader)
+	if providerID == "" {
+		return details
+	}
+	if details == nil {
+		details = make(map[string]interface{})
+	}
+	details["provider_request_id"] = providerID
+	return details
+}
+
+// isJSONResponse reports whether a response is a plain JSON body rather than
+// an SSE stream
+func isJSONResponse(header http.Header) bool {
+	return strings.HasPrefix(header.Get("Content-Type"), "application/json")
+}
+
+const (
+	// clientRequestIDHeader sends the caller's request ID to the provider,
+	// which records it against the call
+	clientRequestIDHeader = "X-Client-Request-Id"
+
+	// providerRequestIDHeader carries the provider's own ID for the call
+	providerRequestIDHeader = "X-Request-Id"
+)
+
+// attributeError records the tenant, user and request a failed call was made
+// for on a QLensError, keeping values already set. Other errors are
+// returned unchanged.
+func attributeError(err error, tenantID domain.TenantID, userID domain.UserID, requestID string) error {
+	var qlensErr *types.QLensError
+	if !errors.As(err, &qlensErr) {
+		return err
+	}
+	if qlensErr.TenantID == "" {
+		qlensErr.TenantID = tenantID
+	}
+	if qlensErr.UserID == "" {
+		qlensErr.UserID = userID
+	}
+	if qlensErr.RequestID == "" {
+		qlensErr.RequestID = requestID
+	}
+	return err
+}
+
+// setClientRequestID tags a provider call with the caller's request ID
+func setClientRequestID(req *http.Request, requestID string) {
+	if requestID != "" {
+		req.Header.Set(clientRequestIDHeader, requestID)
+	}
+}
+
+func (c *OpenAICompatibleClient) setHeaders(req *http.Request) {
+	// Custom headers go first so they can never replace the auth header
+	for name, value := range c.config.CustomHeaders {
+		req.Header.Set(name, value)
+	}
+
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-	req.Header.Set("User-Agent", "QLens/1.0.0")
+	req.Header.Set("User-Agent", version.UserAgent(c.config.UserAgent))
+
+	authStyle := c.config.AuthStyle
+	if authStyle == "" {
+		authStyle = c.profile.AuthStyle
+	}
+	switch authStyle {
+	case types.AuthStyleAPIKey:
+		req.Header.Set("api-key", c.apiKey)
+	case types.AuthStyleNone:
+	default:
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
 }
 
-func (c *OpenAIClient) handleStream(ctx context.Context, body io.ReadCloser, streamChan chan<- types.StreamResponse, requestID string) {
+func (c *OpenAICompatibleClient) handleStream(ctx context.Context, body io.ReadCloser, streamChan chan<- types.StreamResponse, requestID, providerRequestID string) {
 	defer close(streamChan)
 	defer body.Close()
 
@@ -288,8 +494,25 @@ func (c *OpenAIClient) handleStream(ctx context.Context, body io.ReadCloser, str
 		data := strings.TrimPrefix(line, "data: ")
 		if data == "[DONE]" {
 			streamChan <- types.StreamResponse{
-				Done:      true,
-				RequestID: requestID,
+				Done:              true,
+				RequestID:         requestID,
+				ProviderRequestID: providerRequestID,
+			}
+			return
+		}
+
+		// Providers report failures after the stream has started as an
+		// error object in place of a chunk
+		var openAIErr OpenAIError
+		if err := json.Unmarshal([]byte(data), &openAIErr); err == nil && openAIErr.Error.Message != "" {
+			streamChan <- types.StreamResponse{
+				Error: &types.StreamError{
+					Type:    errorTypeForResponse(http.StatusOK, openAIErr.Error.Type),
+					Message: openAIErr.Error.Message,
+					Code:    openAIErr.Error.Code,
+				},
+				RequestID:         requestID,
+				ProviderRequestID: providerRequestID,
 			}
 			return
 		}
@@ -306,6 +529,7 @@ func (c *OpenAIClient) handleStream(ctx context.Context, body io.ReadCloser, str
 		}
 
 		streamResp := c.convertStreamChunk(&chunk, requestID)
+		streamResp.ProviderRequestID = providerRequestID
 		streamChan <- streamResp
 	}
 
@@ -321,7 +545,7 @@ func (c *OpenAIClient) handleStream(ctx context.Context, body io.ReadCloser, str
 
 // Conversion methods
 
-func (c *OpenAIClient) convertCompletionRequest(req *types.CompletionRequest) *OpenAIChatCompletionRequest {
+func (c *OpenAICompatibleClient) convertCompletionRequest(req *types.CompletionRequest) *OpenAIChatCompletionRequest {
 	openAIReq := &OpenAIChatCompletionRequest{
 		Model:    req.Model,
 		Messages: make([]OpenAIMessage, len(req.Messages)),
@@ -330,7 +554,7 @@ func (c *OpenAIClient) convertCompletionRequest(req *types.CompletionRequest) *O
 
 	// Set default model if not specified
 	if openAIReq.Model == "" {
-		openAIReq.Model = "gpt-3.5-turbo"
+		openAIReq.Model = c.profile.DefaultModel
 	}
 
 	// Convert messages
@@ -340,7 +564,12 @@ func (c *OpenAIClient) convertCompletionRequest(req *types.CompletionRequest) *O
 
 	// Set optional parameters
 	if req.MaxTokens != nil {
-		openAIReq.MaxTokens = req.MaxTokens
+		// Newer models only accept max_completion_tokens
+		if c.profile.TokenLimitParam != nil && c.profile.TokenLimitParam(openAIReq.Model) == domain.TokenLimitParamMaxCompletionTokens {
+			openAIReq.MaxCompletionTokens = req.MaxTokens
+		} else {
+			openAIReq.MaxTokens = req.MaxTokens
+		}
 	}
 	if req.Temperature != nil {
 		openAIReq.Temperature = req.Temperature
@@ -361,10 +590,18 @@ func (c *OpenAIClient) convertCompletionRequest(req *types.CompletionRequest) *O
 		openAIReq.User = req.User
 	}
 
+	// Metadata only tags stored completions
+	if c.profile.StoredCompletions && req.Store != nil {
+		openAIReq.Store = req.Store
+		if *req.Store {
+			openAIReq.Metadata = domain.ProviderMetadata(req.Metadata)
+		}
+	}
+
 	return openAIReq
 }
 
-func (c *OpenAIClient) convertMessage(msg domain.Message) OpenAIMessage {
+func (c *OpenAICompatibleClient) convertMessage(msg domain.Message) OpenAIMessage {
 	openAIMsg := OpenAIMessage{
 		Role: string(msg.Role),
 	}
@@ -408,7 +645,7 @@ func (c *OpenAIClient) convertMessage(msg domain.Message) OpenAIMessage {
 	return openAIMsg
 }
 
-func (c *OpenAIClient) convertContentPart(part domain.ContentPart) OpenAIContentPart {
+func (c *OpenAICompatibleClient) convertContentPart(part domain.ContentPart) OpenAIContentPart {
 	switch part.Type {
 	case domain.ContentTypeText:
 		return OpenAIContentPart{
@@ -431,7 +668,20 @@ func (c *OpenAIClient) convertContentPart(part domain.ContentPart) OpenAIContent
 	}
 }
 
-func (c *OpenAIClient) convertCompletionResponse(resp *OpenAIChatCompletionResponse, requestID string, responseTime time.Duration) *types.CompletionResponse {
+func (c *OpenAICompatibleClient) convertCompletionResponse(resp *OpenAIChatCompletionResponse, requestID string, responseTime time.Duration) (*types.CompletionResponse, error) {
+	if len(resp.Choices) == 0 {
+		return nil, &types.QLensError{
+			Type:      types.ErrorTypeProviderError,
+			Message:   fmt.Sprintf("%s returned no choices", c.profile.Name),
+			Provider:  c.Provider(),
+			RequestID: requestID,
+			Details: map[string]interface{}{
+				"model":       resp.Model,
+				"response_id": resp.ID,
+			},
+		}
+	}
+
 	choices := make([]domain.Choice, len(resp.Choices))
 	for i, choice := range resp.Choices {
 		choices[i] = domain.Choice{
@@ -439,33 +689,49 @@ func (c *OpenAIClient) convertCompletionResponse(resp *OpenAIChatCompletionRespo
 			Message:      c.convertResponseMessage(choice.Message),
 			FinishReason: domain.FinishReason(choice.FinishReason),
 		}
+		if choice.Message.Refusal != "" {
+			choices[i].FinishReason = domain.FinishReasonRefusal
+		}
+		choices[i].NormalizeToolCalls()
 	}
+	domain.OrderChoices(resp.ID, choices)
+
+	var usage domain.Usage
+	if resp.Usage != nil {
+		usage = domain.Usage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+			TotalTokens:      resp.Usage.TotalTokens,
+		}
+		if details := resp.Usage.CompletionTokensDetails; details != nil {
+			usage.ReasoningTokens = details.ReasoningTokens
+		}
+	}
+	checkUsage(c.Provider(), &usage, resp.Usage == nil)
 
 	// Calculate cost based on usage
-	cost := c.calculateCost(resp.Model, resp.Usage.PromptTokens, resp.Usage.CompletionTokens)
+	usage.CostUSD = c.calculateCost(resp.Model, usage)
 
 	return &types.CompletionResponse{
 		ID:       resp.ID,
 		Object:   resp.Object,
 		Created:  resp.Created,
 		Model:    resp.Model,
-		Provider: domain.ProviderOpenAI,
+		Provider: c.Provider(),
 		Choices:  choices,
-		Usage: domain.Usage{
-			PromptTokens:     resp.Usage.PromptTokens,
-			CompletionTokens: resp.Usage.CompletionTokens,
-			TotalTokens:      resp.Usage.TotalTokens,
-			CostUSD:          cost,
-		},
+		Usage:        usage,
 		ResponseTime: responseTime,
 		RequestID:    requestID,
-	}
+	}, nil
 }
 
-func (c *OpenAIClient) convertResponseMessage(msg OpenAIMessage) domain.Message {
+func (c *OpenAICompatibleClient) convertResponseMessage(msg OpenAIMessage) domain.Message {
 	message := domain.Message{
 		Role: domain.MessageRole(msg.Role),
 	}
+	if message.Role == "" {
+		message.Role = domain.MessageRoleAssistant
+	}
 
 	// Convert content
 	if msg.Content != "" {
@@ -477,6 +743,14 @@ func (c *OpenAIClient) convertResponseMessage(msg OpenAIMessage) domain.Message
 		}
 	}
 
+	// Refusals are returned instead of content
+	if msg.Refusal != "" {
+		message.Content = append(message.Content, domain.ContentPart{
+			Type: domain.ContentTypeRefusal,
+			Text: msg.Refusal,
+		})
+	}
+
 	// Convert tool calls
 	if len(msg.ToolCalls) > 0 {
 		message.ToolCalls = make([]domain.ToolCall, len(msg.ToolCalls))
@@ -495,11 +769,12 @@ func (c *OpenAIClient) convertResponseMessage(msg OpenAIMessage) domain.Message
 	return message
 }
 
-func (c *OpenAIClient) convertStreamChunk(chunk *OpenAIChatCompletionChunk, requestID string) types.StreamResponse {
+func (c *OpenAICompatibleClient) convertStreamChunk(chunk *OpenAIChatCompletionChunk, requestID string) types.StreamResponse {
 	choices := make([]types.StreamChoice, len(chunk.Choices))
 	for i, choice := range chunk.Choices {
 		streamChoice := types.StreamChoice{
 			Index: choice.Index,
+			ID:    domain.ChoiceID(chunk.ID, choice.Index),
 			Delta: types.StreamDelta{},
 		}
 
@@ -512,6 +787,10 @@ func (c *OpenAIClient) convertStreamChunk(chunk *OpenAIChatCompletionChunk, requ
 			streamChoice.Delta.Content = &choice.Delta.Content
 		}
 
+		if choice.Delta.Refusal != "" {
+			streamChoice.Delta.Refusal = &choice.Delta.Refusal
+		}
+
 		if choice.FinishReason != "" {
 			reason := domain.FinishReason(choice.FinishReason)
 			streamChoice.FinishReason = &reason
@@ -519,20 +798,22 @@ func (c *OpenAIClient) convertStreamChunk(chunk *OpenAIChatCompletionChunk, requ
 
 		choices[i] = streamChoice
 	}
+	// Choices of a chunk may arrive in any order with n > 1
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].Index < choices[j].Index })
 
 	return types.StreamResponse{
 		ID:        chunk.ID,
 		Object:    chunk.Object,
 		Created:   chunk.Created,
 		Model:     chunk.Model,
-		Provider:  domain.ProviderOpenAI,
+		Provider:  c.Provider(),
 		Choices:   choices,
 		Done:      false,
 		RequestID: requestID,
 	}
 }
 
-func (c *OpenAIClient) convertEmbeddingRequest(req *types.EmbeddingRequest) *OpenAIEmbeddingRequest {
+func (c *OpenAICompatibleClient) convertEmbeddingRequest(req *types.EmbeddingRequest) *OpenAIEmbeddingRequest {
 	openAIReq := &OpenAIEmbeddingRequest{
 		Model: req.Model,
 		Input: req.Input,
@@ -540,12 +821,12 @@ func (c *OpenAIClient) convertEmbeddingRequest(req *types.EmbeddingRequest) *Ope
 
 	// Set default model if not specified
 	if openAIReq.Model == "" {
-		openAIReq.Model = "text-embedding-ada-002"
+		openAIReq.Model = c.profile.DefaultEmbeddingModel
 	}
 
-	if req.EncodingFormat != "" {
-		openAIReq.EncodingFormat = req.EncodingFormat
-	}
+	// Vectors are always fetched as floats and encoded for the caller in
+	// convertEmbeddingResponse
+	openAIReq.EncodingFormat = domain.EmbeddingEncodingFloat
 
 	if req.Dimensions != nil {
 		openAIReq.Dimensions = req.Dimensions
@@ -558,24 +839,55 @@ func (c *OpenAIClient) convertEmbeddingRequest(req *types.EmbeddingRequest) *Ope
 	return openAIReq
 }
 
-func (c *OpenAIClient) convertEmbeddingResponse(resp *OpenAIEmbeddingResponse, requestID string, responseTime time.Duration) *types.EmbeddingResponse {
+func (c *OpenAICompatibleClient) convertEmbeddingResponse(resp *OpenAIEmbeddingResponse, inputs int, encodingFormat, requestID string, responseTime time.Duration) (*types.EmbeddingResponse, error) {
+	if len(resp.Data) != inputs {
+		return nil, &types.QLensError{
+			Type:      types.ErrorTypeProviderError,
+			Message:   fmt.Sprintf("%s returned %d embeddings for %d inputs", c.profile.Name, len(resp.Data), inputs),
+			Provider:  c.Provider(),
+			RequestID: requestID,
+			Details: map[string]interface{}{
+				"model": resp.Model,
+			},
+		}
+	}
+
 	embeddings := make([]domain.Embedding, len(resp.Data))
 	for i, emb := range resp.Data {
+		if len(emb.Embedding) == 0 || emb.Index < 0 || emb.Index >= inputs {
+			return nil, &types.QLensError{
+				Type:      types.ErrorTypeProviderError,
+				Message:   fmt.Sprintf("%s returned a malformed embedding", c.profile.Name),
+				Provider:  c.Provider(),
+				RequestID: requestID,
+				Details: map[string]interface{}{
+					"model": resp.Model,
+					"index": emb.Index,
+				},
+			}
+		}
 		embeddings[i] = domain.Embedding{
 			Object:    emb.Object,
 			Embedding: emb.Embedding,
 			Index:     emb.Index,
 		}
+		embeddings[i].SetEncoding(encodingFormat)
 	}
 
 	// Calculate cost for embeddings
+	if resp.Usage.PromptTokens < 0 {
+		resp.Usage.PromptTokens = 0
+	}
+	if resp.Usage.TotalTokens < resp.Usage.PromptTokens {
+		resp.Usage.TotalTokens = resp.Usage.PromptTokens
+	}
 	cost := c.calculateEmbeddingCost(resp.Model, resp.Usage.TotalTokens)
 
 	return &types.EmbeddingResponse{
 		Object:   resp.Object,
 		Data:     embeddings,
 		Model:    resp.Model,
-		Provider: domain.ProviderOpenAI,
+		Provider: c.Provider(),
 		Usage: domain.EmbeddingUsage{
 			PromptTokens: resp.Usage.PromptTokens,
 			TotalTokens:  resp.Usage.TotalTokens,
@@ -583,10 +895,10 @@ func (c *OpenAIClient) convertEmbeddingResponse(resp *OpenAIEmbeddingResponse, r
 		},
 		ResponseTime: responseTime,
 		RequestID:    requestID,
-	}
+	}, nil
 }
 
-func (c *OpenAIClient) convertModel(openAIModel *OpenAIModel) types.Model {
+func (c *OpenAICompatibleClient) convertModel(openAIModel *OpenAIModel) types.Model {
 	// Determine capabilities based on model ID
 	capabilities := c.getModelCapabilities(openAIModel.ID)
 
@@ -595,9 +907,9 @@ func (c *OpenAIClient) convertModel(openAIModel *OpenAIModel) types.Model {
 
 	return types.Model{
 		ID:            openAIModel.ID,
-		Provider:      domain.ProviderOpenAI,
+		Provider:      c.Provider(),
 		Name:          openAIModel.ID, // OpenAI uses ID as display name
-		Description:   fmt.Sprintf("OpenAI %s model", openAIModel.ID),
+		Description:   fmt.Sprintf("%s %s model", c.profile.Name, openAIModel.ID),
 		Capabilities:  capabilities,
 		ContextLength: c.getModelContextLength(openAIModel.ID),
 		Pricing:       pricing,
@@ -612,25 +924,15 @@ func (c *OpenAIClient) convertModel(openAIModel *OpenAIModel) types.Model {
 	}
 }
 
-func (c *OpenAIClient) getModelCapabilities(modelID string) []domain.Capability {
-	capabilities := []domain.Capability{}
-
-	// Determine capabilities based on model name patterns
-	if strings.Contains(modelID, "gpt") {
-		capabilities = append(capabilities, domain.CapabilityCompletion)
-		if strings.Contains(modelID, "gpt-4") {
-			capabilities = append(capabilities, domain.CapabilityVision)
-		}
-		capabilities = append(capabilities, domain.CapabilityFunctionCalling)
-	}
-
-	if strings.Contains(modelID, "code") || strings.Contains(modelID, "codex") {
-		capabilities = append(capabilities, domain.CapabilityCode)
-		capabilities = append(capabilities, domain.CapabilityCompletion)
+func (c *OpenAICompatibleClient) getModelCapabilities(modelID string) []domain.Capability {
+	// Configured capabilities take precedence over name-based inference
+	if capabilities, ok := c.models.capabilities(modelID); ok {
+		return capabilities
 	}
 
-	if strings.Contains(modelID, "embedding") {
-		capabilities = append(capabilities, domain.CapabilityEmbedding)
+	var capabilities []domain.Capability
+	if c.profile.Capabilities != nil {
+		capabilities = c.profile.Capabilities(modelID)
 	}
 
 	// Default to completion if no specific capabilities detected
@@ -641,96 +943,41 @@ func (c *OpenAIClient) getModelCapabilities(modelID string) []domain.Capability
 	return capabilities
 }
 
-func (c *OpenAIClient) getModelPricing(modelID string) domain.ModelPricing {
-	// Simplified pricing based on known OpenAI models (as of knowledge cutoff)
-	// In production, this should be loaded from a configuration file or API
-	pricingMap := map[string]domain.ModelPricing{
-		"gpt-4": {
-			InputTokenCost:  0.03 / 1000,  // $0.03 per 1k tokens
-			OutputTokenCost: 0.06 / 1000,  // $0.06 per 1k tokens
-			Unit:            "token",
-		},
-		"gpt-4-turbo": {
-			InputTokenCost:  0.01 / 1000,  // $0.01 per 1k tokens
-			OutputTokenCost: 0.03 / 1000,  // $0.03 per 1k tokens
-			Unit:            "token",
-		},
-		"gpt-3.5-turbo": {
-			InputTokenCost:  0.0015 / 1000, // $0.0015 per 1k tokens
-			OutputTokenCost: 0.002 / 1000,  // $0.002 per 1k tokens
-			Unit:            "token",
-		},
-		"text-embedding-ada-002": {
-			InputTokenCost:  0.0001 / 1000, // $0.0001 per 1k tokens
-			OutputTokenCost: 0,
-			Unit:            "token",
-		},
-		"text-embedding-3-small": {
-			InputTokenCost:  0.00002 / 1000, // $0.00002 per 1k tokens
-			OutputTokenCost: 0,
-			Unit:            "token",
-		},
-		"text-embedding-3-large": {
-			InputTokenCost:  0.00013 / 1000, // $0.00013 per 1k tokens
-			OutputTokenCost: 0,
-			Unit:            "token",
-		},
-	}
-
-	// Check for exact match first
-	if pricing, exists := pricingMap[modelID]; exists {
+func (c *OpenAICompatibleClient) getModelPricing(modelID string) domain.ModelPricing {
+	if pricing, ok := c.models.pricing(modelID); ok {
 		return pricing
 	}
+	return c.profile.DefaultPricing
+}
 
-	// Check for partial matches
-	for model, pricing := range pricingMap {
-		if strings.Contains(modelID, model) {
-			return pricing
-		}
-	}
-
-	// Default pricing if model not found
-	return domain.ModelPricing{
-		InputTokenCost:  0.002 / 1000,
-		OutputTokenCost: 0.002 / 1000,
-		Unit:            "token",
+func (c *OpenAICompatibleClient) getModelContextLength(modelID string) int {
+	if length, ok := c.models.contextLength(modelID); ok {
+		return length
 	}
+	return c.profile.DefaultContextLength
 }
 
-func (c *OpenAIClient) getModelContextLength(modelID string) int {
-	contextLengths := map[string]int{
-		"gpt-4":              8192,
-		"gpt-4-turbo":        128000,
-		"gpt-4-32k":          32768,
-		"gpt-3.5-turbo":      4096,
-		"gpt-3.5-turbo-16k":  16384,
-		"text-davinci-003":   4097,
-		"text-davinci-002":   4097,
-		"code-davinci-002":   8001,
+// apiFormat returns the API a model's completions are sent to: the model's
+// configured format, else the client's, else chat completions
+func (c *OpenAICompatibleClient) apiFormat(modelID string) types.APIFormat {
+	if modelID == "" {
+		modelID = c.profile.DefaultModel
 	}
-
-	// Check for exact match first
-	if length, exists := contextLengths[modelID]; exists {
-		return length
+	if format, ok := c.models.apiFormat(modelID); ok {
+		return format
 	}
-
-	// Check for partial matches
-	for model, length := range contextLengths {
-		if strings.Contains(modelID, model) {
-			return length
-		}
+	if c.config.APIFormat != "" {
+		return c.config.APIFormat
 	}
-
-	// Default context length
-	return 4096
+	return types.APIFormatChatCompletions
 }
 
-func (c *OpenAIClient) calculateCost(model string, promptTokens, completionTokens int) float64 {
+func (c *OpenAICompatibleClient) calculateCost(model string, usage domain.Usage) float64 {
 	pricing := c.getModelPricing(model)
-	return float64(promptTokens)*pricing.InputTokenCost + float64(completionTokens)*pricing.OutputTokenCost
+	return pricing.CompletionCost(usage)
 }
 
-func (c *OpenAIClient) calculateEmbeddingCost(model string, totalTokens int) float64 {
+func (c *OpenAICompatibleClient) calculateEmbeddingCost(model string, totalTokens int) float64 {
 	pricing := c.getModelPricing(model)
 	return float64(totalTokens) * pricing.InputTokenCost
 }
@@ -738,25 +985,73 @@ func (c *OpenAIClient) calculateEmbeddingCost(model string, totalTokens int) flo
 // OpenAI API types
 
 type OpenAIChatCompletionRequest struct {
-	Model            string         `json:"model"`
-	Messages         []OpenAIMessage `json:"messages"`
-	MaxTokens        *int           `json:"max_tokens,omitempty"`
-	Temperature      *float64       `json:"temperature,omitempty"`
-	TopP             *float64       `json:"top_p,omitempty"`
-	Stream           bool           `json:"stream,omitempty"`
-	Stop             []string       `json:"stop,omitempty"`
-	PresencePenalty  *float64       `json:"presence_penalty,omitempty"`
-	FrequencyPenalty *float64       `json:"frequency_penalty,omitempty"`
-	User             string         `json:"user,omitempty"`
+	Model               string            `json:"model"`
+	Messages            []OpenAIMessage   `json:"messages"`
+	MaxTokens           *int              `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int              `json:"max_completion_tokens,omitempty"`
+	Temperature         *float64          `json:"temperature,omitempty"`
+	TopP                *float64          `json:"top_p,omitempty"`
+	Stream              bool              `json:"stream,omitempty"`
+	Stop                []string          `json:"stop,omitempty"`
+	PresencePenalty     *float64          `json:"presence_penalty,omitempty"`
+	FrequencyPenalty    *float64          `json:"frequency_penalty,omitempty"`
+	User                string            `json:"user,omitempty"`
+	Store               *bool             `json:"store,omitempty"`
+	Metadata            map[string]string `json:"metadata,omitempty"`
 }
 
+// OpenAIMessage is a chat message. Its content is sent and received as
+// either a string or an array of parts, so MarshalJSON and UnmarshalJSON map
+// the JSON content field to Content or MultiContent.
 type OpenAIMessage struct {
 	Role         string              `json:"role"`
-	Content      string              `json:"content,omitempty"`
-	MultiContent []OpenAIContentPart `json:"content,omitempty"`
+	Content      string              `json:"-"`
+	MultiContent []OpenAIContentPart `json:"-"`
 	Name         string              `json:"name,omitempty"`
 	ToolCallID   string              `json:"tool_call_id,omitempty"`
 	ToolCalls    []OpenAIToolCall    `json:"tool_calls,omitempty"`
+	Refusal      string              `json:"refusal,omitempty"`
+}
+
+// openAIMessageFields has OpenAIMessage's fields without its methods
+type openAIMessageFields OpenAIMessage
+
+// MarshalJSON sends MultiContent as the content array when it is set, and
+// Content as the content string otherwise
+func (m OpenAIMessage) MarshalJSON() ([]byte, error) {
+	var content interface{}
+	if len(m.MultiContent) > 0 {
+		content = m.MultiContent
+	} else if m.Content != "" {
+		content = m.Content
+	}
+	return json.Marshal(struct {
+		openAIMessageFields
+		Content interface{} `json:"content,omitempty"`
+	}{openAIMessageFields(m), content})
+}
+
+// UnmarshalJSON reads a string content into Content and an array of parts
+// into MultiContent; null content leaves both empty
+func (m *OpenAIMessage) UnmarshalJSON(data []byte) error {
+	var message struct {
+		openAIMessageFields
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &message); err != nil {
+		return err
+	}
+	*m = OpenAIMessage(message.openAIMessageFields)
+
+	content := bytes.TrimSpace(message.Content)
+	switch {
+	case len(content) == 0 || bytes.Equal(content, []byte("null")):
+		return nil
+	case content[0] == '[':
+		return json.Unmarshal(content, &m.MultiContent)
+	default:
+		return json.Unmarshal(content, &m.Content)
+	}
 }
 
 type OpenAIContentPart struct {
@@ -787,7 +1082,7 @@ type OpenAIChatCompletionResponse struct {
 	Created int64                `json:"created"`
 	Model   string               `json:"model"`
 	Choices []OpenAIChoice       `json:"choices"`
-	Usage   OpenAIUsage          `json:"usage"`
+	Usage   *OpenAIUsage         `json:"usage"`
 }
 
 type OpenAIChoice struct {
@@ -797,9 +1092,14 @@ type OpenAIChoice struct {
 }
 
 type OpenAIUsage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens            int                 `json:"prompt_tokens"`
+	CompletionTokens        int                 `json:"completion_tokens"`
+	TotalTokens             int                 `json:"total_tokens"`
+	CompletionTokensDetails *OpenAITokenDetails `json:"completion_tokens_details,omitempty"`
+}
+
+type OpenAITokenDetails struct {
+	ReasoningTokens int `json:"reasoning_tokens"`
 }
 
 type OpenAIChatCompletionChunk struct {
@@ -819,6 +1119,7 @@ type OpenAIStreamChoice struct {
 type OpenAIStreamDelta struct {
 	Role      string             `json:"role,omitempty"`
 	Content   string             `json:"content,omitempty"`
+	Refusal   string             `json:"refusal,omitempty"`
 	ToolCalls []OpenAIToolCall   `json:"tool_calls,omitempty"`
 }
 
@@ -867,5 +1168,25 @@ type OpenAIError struct {
 		Message string `json:"message"`
 		Type    string `json:"type"`
 		Code    string `json:"code"`
+		Param   string `json:"param"`
 	} `json:"error"`
+}
+
+// details keeps the fields that tell a caller which part of the request the
+// provider rejected
+func (e *OpenAIError) details() map[string]interface{} {
+	details := map[string]interface{}{}
+	if e.Error.Param != "" {
+		details["param"] = e.Error.Param
+	}
+	if e.Error.Code != "" {
+		details["provider_code"] = e.Error.Code
+	}
+	if e.Error.Type != "" {
+		details["provider_type"] = e.Error.Type
+	}
+	if len(details) == 0 {
+		return nil
+	}
+	return details
 }
\ No newline at end of file
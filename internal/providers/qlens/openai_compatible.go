@@ -0,0 +1,250 @@
+package providers
+
+import (
+	"strings"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+// OpenAICompatibleProfile describes an API that speaks the OpenAI chat
+// completions wire format. Fields set in a provider's ProviderConfig take
+// precedence over the profile.
+type OpenAICompatibleProfile struct {
+	Provider  domain.Provider
+	Name      string
+	BaseURL   string
+	AuthStyle types.AuthStyle
+
+	// Models used when a request does not name one
+	DefaultModel          string
+	DefaultEmbeddingModel string
+
+	// TokenLimitParam picks between max_tokens and max_completion_tokens;
+	// when nil max_tokens is always sent
+	TokenLimitParam func(model string) domain.TokenLimitParam
+
+	// StoredCompletions is set for APIs that accept store and metadata;
+	// other APIs never get them
+	StoredCompletions bool
+
+	// Capabilities infers capabilities for models the pricing table does
+	// not describe; when nil such models only report completion
+	Capabilities func(modelID string) []domain.Capability
+
+	// Models is the built-in pricing table, extended by ProviderConfig.Models
+	Models               PricingTable
+	DefaultPricing       domain.ModelPricing
+	DefaultContextLength int
+}
+
+// OpenAIProfile is the profile behind NewOpenAIClient
+var OpenAIProfile = OpenAICompatibleProfile{
+	Provider:              domain.ProviderOpenAI,
+	Name:                  "OpenAI",
+	BaseURL:               "https://api.openai.com/v1",
+	AuthStyle:             types.AuthStyleBearer,
+	DefaultModel:          "gpt-3.5-turbo",
+	DefaultEmbeddingModel: "text-embedding-ada-002",
+	TokenLimitParam:       domain.OpenAITokenLimitParam,
+	StoredCompletions:     true,
+	Capabilities:          openAIModelCapabilities,
+	Models:                openAIPricingTable,
+	DefaultPricing: domain.ModelPricing{
+		InputTokenCost:  0.002 / 1000,
+		OutputTokenCost: 0.002 / 1000,
+		Unit:            "token",
+	},
+	DefaultContextLength: 4096,
+}
+
+// Third-party providers publish prices that change often, so they ship
+// without a built-in pricing table; configure ProviderConfig.Models to have
+// their usage costed.
+var (
+	GroqProfile = OpenAICompatibleProfile{
+		Provider:             "groq",
+		Name:                 "Groq",
+		BaseURL:              "https://api.groq.com/openai/v1",
+		AuthStyle:            types.AuthStyleBearer,
+		DefaultContextLength: 8192,
+	}
+
+	TogetherProfile = OpenAICompatibleProfile{
+		Provider:             "together",
+		Name:                 "Together AI",
+		BaseURL:              "https://api.together.xyz/v1",
+		AuthStyle:            types.AuthStyleBearer,
+		DefaultContextLength: 8192,
+	}
+
+	FireworksProfile = OpenAICompatibleProfile{
+		Provider:             "fireworks",
+		Name:                 "Fireworks AI",
+		BaseURL:              "https://api.fireworks.ai/inference/v1",
+		AuthStyle:            types.AuthStyleBearer,
+		DefaultContextLength: 8192,
+	}
+)
+
+// LocalProfile describes a self-hosted server speaking the OpenAI wire
+// format, such as Ollama, vLLM or LM Studio. Its base URL must come from the
+// provider config, and it is called without credentials unless the config
+// sets an auth style.
+var LocalProfile = OpenAICompatibleProfile{
+	Provider:             domain.ProviderLocal,
+	Name:                 "Local",
+	AuthStyle:            types.AuthStyleNone,
+	DefaultContextLength: 4096,
+}
+
+var openAICompatibleProfiles = map[domain.Provider]OpenAICompatibleProfile{
+	OpenAIProfile.Provider:    OpenAIProfile,
+	GroqProfile.Provider:      GroqProfile,
+	TogetherProfile.Provider:  TogetherProfile,
+	FireworksProfile.Provider: FireworksProfile,
+}
+
+// LookupOpenAICompatibleProfile returns the built-in profile registered
+// under a provider name
+func LookupOpenAICompatibleProfile(provider domain.Provider) (OpenAICompatibleProfile, bool) {
+	profile, ok := openAICompatibleProfiles[provider]
+	return profile, ok
+}
+
+// ServesModel reports whether an OpenAI-compatible provider has an entry
+// for modelID in its profile's pricing table or configured models. Entries
+// match the way pricing is looked up, so a provider is only routed the
+// models it prices.
+func ServesModel(provider domain.Provider, config types.ProviderConfig, modelID string) bool {
+	var table PricingTable
+	if profile, ok := LookupOpenAICompatibleProfile(provider); ok {
+		table = profile.Models
+	}
+	_, ok := table.merge(config.Models).lookup(modelID, func(types.ModelSpec) bool { return true })
+	return ok
+}
+
+// GenericOpenAICompatibleProfile describes an OpenAI-compatible API with no
+// built-in profile; its base URL must come from the provider config
+func GenericOpenAICompatibleProfile(provider domain.Provider) OpenAICompatibleProfile {
+	return OpenAICompatibleProfile{
+		Provider:             provider,
+		Name:                 string(provider),
+		AuthStyle:            types.AuthStyleBearer,
+		DefaultContextLength: 4096,
+	}
+}
+
+// PricingTable maps model IDs to their specs. A model without an exact entry
+// uses the longest key it contains, resolved per field.
+type PricingTable map[string]types.ModelSpec
+
+// merge returns a table with the overrides layered over t
+func (t PricingTable) merge(overrides map[string]types.ModelSpec) PricingTable {
+	merged := make(PricingTable, len(t)+len(overrides))
+	for model, spec := range t {
+		merged[model] = spec
+	}
+	for model, spec := range overrides {
+		base := merged[model]
+		if spec.Capabilities != nil {
+			base.Capabilities = spec.Capabilities
+		}
+		if spec.ContextLength > 0 {
+			base.ContextLength = spec.ContextLength
+		}
+		if spec.Pricing != nil {
+			base.Pricing = spec.Pricing
+		}
+		if spec.APIFormat != "" {
+			base.APIFormat = spec.APIFormat
+		}
+		merged[model] = base
+	}
+	return merged
+}
+
+// lookup returns the most specific entry for modelID that satisfies has
+func (t PricingTable) lookup(modelID string, has func(types.ModelSpec) bool) (types.ModelSpec, bool) {
+	if spec, ok := t[modelID]; ok && has(spec) {
+		return spec, true
+	}
+
+	var best string
+	for model, spec := range t {
+		if has(spec) && strings.Contains(modelID, model) && len(model) > len(best) {
+			best = model
+		}
+	}
+	if best == "" {
+		return types.ModelSpec{}, false
+	}
+	return t[best], true
+}
+
+func (t PricingTable) pricing(modelID string) (domain.ModelPricing, bool) {
+	spec, ok := t.lookup(modelID, func(s types.ModelSpec) bool { return s.Pricing != nil })
+	if !ok {
+		return domain.ModelPricing{}, false
+	}
+	return *spec.Pricing, true
+}
+
+func (t PricingTable) contextLength(modelID string) (int, bool) {
+	spec, ok := t.lookup(modelID, func(s types.ModelSpec) bool { return s.ContextLength > 0 })
+	return spec.ContextLength, ok
+}
+
+func (t PricingTable) capabilities(modelID string) ([]domain.Capability, bool) {
+	spec, ok := t.lookup(modelID, func(s types.ModelSpec) bool { return len(s.Capabilities) > 0 })
+	return spec.Capabilities, ok
+}
+
+func (t PricingTable) apiFormat(modelID string) (types.APIFormat, bool) {
+	spec, ok := t.lookup(modelID, func(s types.ModelSpec) bool { return s.APIFormat != "" })
+	return spec.APIFormat, ok
+}
+
+func tokenPricing(input, output float64) *domain.ModelPricing {
+	return &domain.ModelPricing{InputTokenCost: input, OutputTokenCost: output, Unit: "token"}
+}
+
+// Simplified pricing based on known OpenAI models (as of knowledge cutoff)
+var openAIPricingTable = PricingTable{
+	"gpt-4":                  {ContextLength: 8192, Pricing: tokenPricing(0.03/1000, 0.06/1000)},
+	"gpt-4-turbo":            {ContextLength: 128000, Pricing: tokenPricing(0.01/1000, 0.03/1000)},
+	"gpt-4-32k":              {ContextLength: 32768},
+	"gpt-3.5-turbo":          {ContextLength: 4096, Pricing: tokenPricing(0.0015/1000, 0.002/1000)},
+	"gpt-3.5-turbo-16k":      {ContextLength: 16384},
+	"text-davinci-003":       {ContextLength: 4097},
+	"text-davinci-002":       {ContextLength: 4097},
+	"code-davinci-002":       {ContextLength: 8001},
+	"text-embedding-ada-002": {Pricing: tokenPricing(0.0001/1000, 0)},
+	"text-embedding-3-small": {Pricing: tokenPricing(0.00002/1000, 0)},
+	"text-embedding-3-large": {Pricing: tokenPricing(0.00013/1000, 0)},
+}
+
+// openAIModelCapabilities infers capabilities from OpenAI model name patterns
+func openAIModelCapabilities(modelID string) []domain.Capability {
+	capabilities := []domain.Capability{}
+
+	if strings.Contains(modelID, "gpt") {
+		capabilities = append(capabilities, domain.CapabilityCompletion)
+		if strings.Contains(modelID, "gpt-4") {
+			capabilities = append(capabilities, domain.CapabilityVision)
+		}
+		capabilities = append(capabilities, domain.CapabilityFunctionCalling)
+	}
+
+	if strings.Contains(modelID, "code") || strings.Contains(modelID, "codex") {
+		capabilities = append(capabilities, domain.CapabilityCode)
+		capabilities = append(capabilities, domain.CapabilityCompletion)
+	}
+
+	if strings.Contains(modelID, "embedding") {
+		capabilities = append(capabilities, domain.CapabilityEmbedding)
+	}
+
+	return capabilities
+}
@@ -0,0 +1,128 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/providers/registry"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+func TestOpenAICompatibleClient_ProfileAndAuthStyle(t *testing.T) {
+	var authorization, apiKey string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		apiKey = r.Header.Get("api-key")
+		w.Write([]byte(`{"id":"chatcmpl-1","model":"llama-3.1-70b-versatile","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1000,"completion_tokens":1000,"total_tokens":2000}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAICompatibleClient(GroqProfile, types.ProviderConfig{
+		APIKey:  "secret",
+		BaseURL: server.URL,
+	})
+	assert.Equal(t, domain.Provider("groq"), client.Provider())
+	assert.Equal(t, "Groq", client.Name())
+
+	response, err := client.CreateCompletion(context.Background(), &types.CompletionRequest{Model: "llama-3.1-70b-versatile"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.Provider("groq"), response.Provider)
+	assert.Equal(t, "Bearer secret", authorization)
+
+	client = NewOpenAICompatibleClient(TogetherProfile, types.ProviderConfig{
+		APIKey:    "secret",
+		BaseURL:   server.URL,
+		AuthStyle: types.AuthStyleAPIKey,
+	})
+	_, err = client.CreateCompletion(context.Background(), &types.CompletionRequest{Model: "llama-3.1-70b-versatile"})
+	require.NoError(t, err)
+	assert.Empty(t, authorization)
+	assert.Equal(t, "secret", apiKey)
+}
+
+func TestOpenAICompatibleClient_ConfiguredModels(t *testing.T) {
+	client := NewOpenAICompatibleClient(FireworksProfile, types.ProviderConfig{
+		Models: map[string]types.ModelSpec{
+			"llama-v3p1-70b": {
+				Capabilities:  []domain.Capability{domain.CapabilityCompletion, domain.CapabilityFunctionCalling},
+				ContextLength: 131072,
+				Pricing:       &domain.ModelPricing{InputTokenCost: 0.9 / 1e6, OutputTokenCost: 0.9 / 1e6, Unit: "token"},
+			},
+		},
+	})
+
+	model := "accounts/fireworks/models/llama-v3p1-70b-instruct"
+	assert.Equal(t, 131072, client.getModelContextLength(model))
+	assert.Equal(t, []domain.Capability{domain.CapabilityCompletion, domain.CapabilityFunctionCalling}, client.getModelCapabilities(model))
+	assert.InDelta(t, 0.0018, client.calculateCost(model, domain.Usage{PromptTokens: 1000, CompletionTokens: 1000}), 1e-9)
+
+	// Unknown models fall back to the profile defaults
+	assert.Equal(t, FireworksProfile.DefaultContextLength, client.getModelContextLength("mixtral-8x7b"))
+	assert.Zero(t, client.calculateCost("mixtral-8x7b", domain.Usage{PromptTokens: 1000}))
+}
+
+func TestOpenAIClient_PricingTableOverride(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{
+		Models: map[string]types.ModelSpec{
+			"gpt-4-turbo": {Pricing: &domain.ModelPricing{InputTokenCost: 1, OutputTokenCost: 2, Unit: "token"}},
+		},
+	})
+
+	// The most specific entry wins and unset fields keep the built-in value
+	assert.Equal(t, 1.0, client.getModelPricing("gpt-4-turbo-2024-04-09").InputTokenCost)
+	assert.Equal(t, 128000, client.getModelContextLength("gpt-4-turbo-2024-04-09"))
+	assert.Equal(t, 0.03/1000, client.getModelPricing("gpt-4-0613").InputTokenCost)
+
+	// Context length and pricing resolve independently
+	assert.Equal(t, 32768, client.getModelContextLength("gpt-4-32k"))
+	assert.Equal(t, 0.03/1000, client.getModelPricing("gpt-4-32k").InputTokenCost)
+
+	assert.Contains(t, client.getModelCapabilities("gpt-4o"), domain.CapabilityVision)
+}
+
+func TestOpenAICompatibleClient_StoredCompletions(t *testing.T) {
+	store := true
+	req := &types.CompletionRequest{
+		Model:    "gpt-4o",
+		Store:    &store,
+		Metadata: map[string]interface{}{"experiment": "baseline", "benchmark": true},
+	}
+
+	openAIReq := NewOpenAICompatibleClient(OpenAIProfile, types.ProviderConfig{}).convertCompletionRequest(req)
+	assert.Equal(t, &store, openAIReq.Store)
+	assert.Equal(t, map[string]string{"experiment": "baseline"}, openAIReq.Metadata)
+
+	// APIs without stored completions never get the fields
+	openAIReq = NewOpenAICompatibleClient(GroqProfile, types.ProviderConfig{}).convertCompletionRequest(req)
+	assert.Nil(t, openAIReq.Store)
+	assert.Nil(t, openAIReq.Metadata)
+}
+
+func TestLocalSDKClient(t *testing.T) {
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		w.Write([]byte(`{"id":"chatcmpl-1","model":"llama3.1","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	factory, ok := registry.Lookup(domain.ProviderLocal)
+	require.True(t, ok)
+
+	// A local server has no default address
+	_, err := factory.SDK(domain.ProviderLocal, types.ProviderConfig{})
+	assert.Error(t, err)
+
+	client, err := factory.SDK(domain.ProviderLocal, types.ProviderConfig{BaseURL: server.URL})
+	require.NoError(t, err)
+	response, err := client.CreateCompletion(context.Background(), &types.CompletionRequest{Model: "llama3.1"})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderLocal, response.Provider)
+	assert.Empty(t, authorization)
+}
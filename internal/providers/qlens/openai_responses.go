@@ -0,0 +1,487 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+// The Responses API (POST /responses) takes a list of input items instead of
+// chat messages and returns an output list of messages, function calls and
+// reasoning items. Completions for models configured with APIFormatResponses
+// are translated to and from it here; callers see the same CompletionResponse
+// and stream chunks as for chat completions.
+
+// createResponse sends a completion request to the Responses API
+func (c *OpenAICompatibleClient) createResponse(ctx context.Context, req *types.CompletionRequest) (*types.CompletionResponse, error) {
+	start := time.Now()
+
+	responsesReq, err := c.convertResponsesRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respData, header, err := c.doRequest(ctx, "POST", "/responses", responsesReq, req.RequestID)
+	if err != nil {
+		attributeError(err, req.TenantID, req.UserID, req.RequestID)
+		return nil, fmt.Errorf("%s API request failed: %w", c.profile.Name, err)
+	}
+
+	var responsesResp OpenAIResponsesResponse
+	if err := json.Unmarshal(respData, &responsesResp); err != nil {
+		return nil, fmt.Errorf("failed to parse %s response: %w", c.profile.Name, err)
+	}
+
+	response, err := c.convertResponsesResponse(&responsesResp, req.RequestID, time.Since(start))
+	if err != nil {
+		return nil, attributeError(err, req.TenantID, req.UserID, req.RequestID)
+	}
+	response.ProviderRequestID = header.Get(providerRequestIDHeader)
+	if req.IncludeRawResponse {
+		response.AttachRawResponse(respData)
+	}
+
+	return response, nil
+}
+
+// createResponseStream streams a completion from the Responses API,
+// translating its typed events into chat completion stream chunks
+func (c *OpenAICompatibleClient) createResponseStream(ctx context.Context, req *types.CompletionRequest) (<-chan types.StreamResponse, error) {
+	responsesReq, err := c.convertResponsesRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	responsesReq.Stream = true
+
+	resp, err := c.openStream(ctx, "/responses", responsesReq, req)
+	if err != nil {
+		return nil, err
+	}
+
+	streamChan := make(chan types.StreamResponse)
+
+	go c.handleResponsesStream(ctx, resp.Body, streamChan, req.RequestID, resp.Header.Get(providerRequestIDHeader))
+
+	return streamChan, nil
+}
+
+func (c *OpenAICompatibleClient) handleResponsesStream(ctx context.Context, body io.ReadCloser, streamChan chan<- types.StreamResponse, requestID, providerRequestID string) {
+	defer close(streamChan)
+	defer body.Close()
+
+	// Every chunk repeats the response's ID and model, which only the
+	// response.created event carries
+	chunk := types.StreamResponse{
+		Object:            "chat.completion.chunk",
+		Provider:          c.Provider(),
+		RequestID:         requestID,
+		ProviderRequestID: providerRequestID,
+	}
+	send := func(delta types.StreamDelta, finishReason *domain.FinishReason) {
+		next := chunk
+		next.Choices = []types.StreamChoice{{Index: 0, Delta: delta, FinishReason: finishReason}}
+		streamChan <- next
+	}
+	fail := func(errorType, message, code string) {
+		next := chunk
+		next.Error = &types.StreamError{Type: errorType, Message: message, Code: code}
+		streamChan <- next
+	}
+
+	scanner := bufio.NewScanner(body)
+	for scanner.Scan() {
+		select {
+		case <-ctx.Done():
+			return
+		default:
+		}
+
+		// Each event's type is repeated in its data, so "event:" lines
+		// can be skipped
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		var event OpenAIResponsesStreamEvent
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &event); err != nil {
+			fail(types.ErrorTypeProviderError, fmt.Sprintf("Failed to parse stream event: %v", err), "")
+			return
+		}
+
+		switch event.Type {
+		case "response.created":
+			if event.Response != nil {
+				chunk.ID = event.Response.ID
+				chunk.Model = event.Response.Model
+				chunk.Created = event.Response.CreatedAt
+			}
+			role := domain.MessageRoleAssistant
+			send(types.StreamDelta{Role: &role}, nil)
+
+		case "response.output_text.delta":
+			delta := event.Delta
+			send(types.StreamDelta{Content: &delta}, nil)
+
+		case "response.refusal.delta":
+			delta := event.Delta
+			send(types.StreamDelta{Refusal: &delta}, nil)
+
+		case "response.completed", "response.incomplete":
+			reason := domain.FinishReasonStop
+			if event.Response != nil {
+				reason = responsesFinishReason(event.Response)
+			}
+			send(types.StreamDelta{}, &reason)
+
+			done := chunk
+			done.Done = true
+			streamChan <- done
+			return
+
+		case "response.failed":
+			message := "response failed"
+			var code string
+			if event.Response != nil && event.Response.Error != nil {
+				message = event.Response.Error.Message
+				code = event.Response.Error.Code
+			}
+			fail(types.ErrorTypeProviderError, message, code)
+			return
+
+		case "error":
+			fail(errorTypeForResponse(http.StatusOK, event.Code), event.Message, event.Code)
+			return
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		fail(types.ErrorTypeProviderError, fmt.Sprintf("Stream reading error: %v", err), "")
+		return
+	}
+	fail(types.ErrorTypeProviderError, "Stream ended before the response completed", "")
+}
+
+// convertResponsesRequest maps a completion request onto the Responses API.
+// Stop sequences and penalties have no equivalent there and are rejected
+// rather than silently dropped.
+func (c *OpenAICompatibleClient) convertResponsesRequest(req *types.CompletionRequest) (*OpenAIResponsesRequest, error) {
+	if len(req.Stop) > 0 || req.PresencePenalty != nil || req.FrequencyPenalty != nil {
+		return nil, &types.QLensError{
+			Type:      types.ErrorTypeInvalidRequest,
+			Message:   "stop, presence_penalty and frequency_penalty are not supported by the Responses API",
+			Provider:  c.Provider(),
+			RequestID: req.RequestID,
+		}
+	}
+
+	// Requests are not retained by the provider for later retrieval
+	store := false
+	responsesReq := &OpenAIResponsesRequest{
+		Model:           req.Model,
+		Input:           make([]OpenAIResponsesInputItem, 0, len(req.Messages)),
+		MaxOutputTokens: req.MaxTokens,
+		Temperature:     req.Temperature,
+		TopP:            req.TopP,
+		Stream:          req.Stream,
+		Store:           &store,
+		User:            req.User,
+	}
+
+	// Set default model if not specified
+	if responsesReq.Model == "" {
+		responsesReq.Model = c.profile.DefaultModel
+	}
+
+	for _, msg := range req.Messages {
+		responsesReq.Input = append(responsesReq.Input, c.convertResponsesInput(msg)...)
+	}
+
+	return responsesReq, nil
+}
+
+// convertResponsesInput converts one message into input items. Tool results
+// and the assistant's tool calls are items of their own rather than parts of
+// a message.
+func (c *OpenAICompatibleClient) convertResponsesInput(msg domain.Message) []OpenAIResponsesInputItem {
+	if msg.ToolCallID != "" {
+		return []OpenAIResponsesInputItem{{
+			Type:   "function_call_output",
+			CallID: msg.ToolCallID,
+			Output: messageText(msg),
+		}}
+	}
+
+	var items []OpenAIResponsesInputItem
+	if len(msg.Content) > 0 {
+		content := make([]OpenAIResponsesContent, 0, len(msg.Content))
+		for _, part := range msg.Content {
+			content = append(content, convertResponsesContent(msg.Role, part))
+		}
+		items = append(items, OpenAIResponsesInputItem{
+			Type:    "message",
+			Role:    string(msg.Role),
+			Content: content,
+		})
+	}
+
+	for _, toolCall := range msg.ToolCalls {
+		items = append(items, OpenAIResponsesInputItem{
+			Type:      "function_call",
+			CallID:    toolCall.ID,
+			Name:      toolCall.Function.Name,
+			Arguments: toolCall.Function.Arguments,
+		})
+	}
+
+	return items
+}
+
+// convertResponsesContent converts a content part. Earlier assistant turns
+// are sent back as output content, everything else as input content.
+func convertResponsesContent(role domain.MessageRole, part domain.ContentPart) OpenAIResponsesContent {
+	if role == domain.MessageRoleAssistant {
+		if part.Type == domain.ContentTypeRefusal {
+			return OpenAIResponsesContent{Type: "refusal", Refusal: part.Text}
+		}
+		return OpenAIResponsesContent{Type: "output_text", Text: part.Text}
+	}
+
+	if part.Type == domain.ContentTypeImageURL && part.ImageURL != nil {
+		return OpenAIResponsesContent{
+			Type:     "input_image",
+			ImageURL: part.ImageURL.URL,
+			Detail:   part.ImageURL.Detail,
+		}
+	}
+	return OpenAIResponsesContent{Type: "input_text", Text: part.Text}
+}
+
+// messageText joins a message's text parts
+func messageText(msg domain.Message) string {
+	var text strings.Builder
+	for _, part := range msg.Content {
+		if part.Type == domain.ContentTypeText {
+			text.WriteString(part.Text)
+		}
+	}
+	return text.String()
+}
+
+// convertResponsesResponse folds a response's output items into a single
+// choice: output text becomes the message content and function calls become
+// tool calls. Reasoning items are not returned.
+func (c *OpenAICompatibleClient) convertResponsesResponse(resp *OpenAIResponsesResponse, requestID string, responseTime time.Duration) (*types.CompletionResponse, error) {
+	if resp.Status == "failed" {
+		message := fmt.Sprintf("%s response failed", c.profile.Name)
+		var code string
+		if resp.Error != nil {
+			message = resp.Error.Message
+			code = resp.Error.Code
+		}
+		return nil, &types.QLensError{
+			Type:      types.ErrorTypeProviderError,
+			Message:   message,
+			Code:      code,
+			Provider:  c.Provider(),
+			RequestID: requestID,
+			Details: map[string]interface{}{
+				"model":       resp.Model,
+				"response_id": resp.ID,
+			},
+		}
+	}
+
+	message := domain.Message{Role: domain.MessageRoleAssistant}
+	var text strings.Builder
+	hasText := false
+	for _, item := range resp.Output {
+		switch item.Type {
+		case "message":
+			for _, part := range item.Content {
+				switch part.Type {
+				case "output_text":
+					text.WriteString(part.Text)
+					hasText = true
+				case "refusal":
+					message.Content = append(message.Content, domain.ContentPart{
+						Type: domain.ContentTypeRefusal,
+						Text: part.Refusal,
+					})
+				}
+			}
+		case "function_call":
+			message.ToolCalls = append(message.ToolCalls, domain.ToolCall{
+				ID:   item.CallID,
+				Type: "function",
+				Function: domain.FunctionCall{
+					Name:      item.Name,
+					Arguments: item.Arguments,
+				},
+			})
+		}
+	}
+	if hasText {
+		message.Content = append([]domain.ContentPart{{Type: domain.ContentTypeText, Text: text.String()}}, message.Content...)
+	}
+
+	if len(message.Content) == 0 && len(message.ToolCalls) == 0 && resp.Status != "incomplete" {
+		return nil, &types.QLensError{
+			Type:      types.ErrorTypeProviderError,
+			Message:   fmt.Sprintf("%s returned no output", c.profile.Name),
+			Provider:  c.Provider(),
+			RequestID: requestID,
+			Details: map[string]interface{}{
+				"model":       resp.Model,
+				"response_id": resp.ID,
+			},
+		}
+	}
+
+	choice := domain.Choice{
+		Index:        0,
+		Message:      message,
+		FinishReason: responsesFinishReason(resp),
+	}
+	choice.NormalizeToolCalls()
+
+	usage := domain.Usage{
+		PromptTokens:     resp.Usage.InputTokens,
+		CompletionTokens: resp.Usage.OutputTokens,
+		TotalTokens:      resp.Usage.TotalTokens,
+	}
+	if details := resp.Usage.OutputTokensDetails; details != nil {
+		usage.ReasoningTokens = details.ReasoningTokens
+	}
+	checkUsage(c.Provider(), &usage, false)
+	usage.CostUSD = c.calculateCost(resp.Model, usage)
+
+	return &types.CompletionResponse{
+		ID:           resp.ID,
+		Object:       "chat.completion",
+		Created:      resp.CreatedAt,
+		Model:        resp.Model,
+		Provider:     c.Provider(),
+		Choices:      []domain.Choice{choice},
+		Usage:        usage,
+		ResponseTime: responseTime,
+		RequestID:    requestID,
+	}, nil
+}
+
+// responsesFinishReason derives a chat finish reason from a response's
+// status and output
+func responsesFinishReason(resp *OpenAIResponsesResponse) domain.FinishReason {
+	if resp.Status == "incomplete" && resp.IncompleteDetails != nil {
+		switch resp.IncompleteDetails.Reason {
+		case "max_output_tokens":
+			return domain.FinishReasonLength
+		case "content_filter":
+			return domain.FinishReasonContentFilter
+		}
+	}
+
+	for _, item := range resp.Output {
+		if item.Type == "function_call" {
+			return domain.FinishReasonToolCalls
+		}
+		for _, part := range item.Content {
+			if part.Type == "refusal" {
+				return domain.FinishReasonRefusal
+			}
+		}
+	}
+	return domain.FinishReasonStop
+}
+
+// OpenAI Responses API types
+
+type OpenAIResponsesRequest struct {
+	Model           string                     `json:"model"`
+	Input           []OpenAIResponsesInputItem `json:"input"`
+	MaxOutputTokens *int                       `json:"max_output_tokens,omitempty"`
+	Temperature     *float64                   `json:"temperature,omitempty"`
+	TopP            *float64                   `json:"top_p,omitempty"`
+	Stream          bool                       `json:"stream,omitempty"`
+	Store           *bool                      `json:"store,omitempty"`
+	User            string                     `json:"user,omitempty"`
+}
+
+// OpenAIResponsesInputItem is a message, a function call the model made
+// earlier, or the output of that call
+type OpenAIResponsesInputItem struct {
+	Type      string                   `json:"type"`
+	Role      string                   `json:"role,omitempty"`
+	Content   []OpenAIResponsesContent `json:"content,omitempty"`
+	CallID    string                   `json:"call_id,omitempty"`
+	Name      string                   `json:"name,omitempty"`
+	Arguments string                   `json:"arguments,omitempty"`
+	Output    string                   `json:"output,omitempty"`
+}
+
+type OpenAIResponsesContent struct {
+	Type     string `json:"type"`
+	Text     string `json:"text,omitempty"`
+	Refusal  string `json:"refusal,omitempty"`
+	ImageURL string `json:"image_url,omitempty"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+type OpenAIResponsesResponse struct {
+	ID                string                      `json:"id"`
+	Object            string                      `json:"object"`
+	CreatedAt         int64                       `json:"created_at"`
+	Model             string                      `json:"model"`
+	Status            string                      `json:"status"`
+	IncompleteDetails *OpenAIResponsesIncomplete  `json:"incomplete_details,omitempty"`
+	Error             *OpenAIResponsesError       `json:"error,omitempty"`
+	Output            []OpenAIResponsesOutputItem `json:"output"`
+	Usage             OpenAIResponsesUsage        `json:"usage"`
+}
+
+type OpenAIResponsesIncomplete struct {
+	Reason string `json:"reason"`
+}
+
+type OpenAIResponsesError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type OpenAIResponsesOutputItem struct {
+	Type      string                   `json:"type"`
+	ID        string                   `json:"id"`
+	Role      string                   `json:"role,omitempty"`
+	Content   []OpenAIResponsesContent `json:"content,omitempty"`
+	CallID    string                   `json:"call_id,omitempty"`
+	Name      string                   `json:"name,omitempty"`
+	Arguments string                   `json:"arguments,omitempty"`
+}
+
+type OpenAIResponsesUsage struct {
+	InputTokens         int                 `json:"input_tokens"`
+	OutputTokens        int                 `json:"output_tokens"`
+	TotalTokens         int                 `json:"total_tokens"`
+	OutputTokensDetails *OpenAITokenDetails `json:"output_tokens_details,omitempty"`
+}
+
+// OpenAIResponsesStreamEvent is one server-sent event of a streamed
+// response. Only the fields of the event types the client handles are
+// decoded.
+type OpenAIResponsesStreamEvent struct {
+	Type     string                   `json:"type"`
+	Delta    string                   `json:"delta,omitempty"`
+	Response *OpenAIResponsesResponse `json:"response,omitempty"`
+
+	// Set on "error" events
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,210 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+func TestOpenAIAPIFormat_PerModel(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{
+		Models: map[string]types.ModelSpec{"o3-pro": {APIFormat: types.APIFormatResponses}},
+	})
+	assert.Equal(t, types.APIFormatResponses, client.apiFormat("o3-pro-2025-06-10"))
+	assert.Equal(t, types.APIFormatChatCompletions, client.apiFormat("gpt-4o"))
+
+	client = NewOpenAIClient(types.ProviderConfig{APIFormat: types.APIFormatResponses})
+	assert.Equal(t, types.APIFormatResponses, client.apiFormat("gpt-4o"))
+}
+
+func TestOpenAIConvertResponsesRequest(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{})
+	maxTokens := 100
+
+	req, err := client.convertResponsesRequest(&types.CompletionRequest{
+		Model:     "o3-pro",
+		MaxTokens: &maxTokens,
+		Messages: []domain.Message{
+			{Role: domain.MessageRoleSystem, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "Be brief."}}},
+			{Role: domain.MessageRoleUser, Content: []domain.ContentPart{
+				{Type: domain.ContentTypeText, Text: "What is this?"},
+				{Type: domain.ContentTypeImageURL, ImageURL: &domain.ImageURL{URL: "https://example.com/cat.png"}},
+			}},
+			{Role: domain.MessageRoleAssistant, ToolCalls: []domain.ToolCall{{ID: "call_1", Type: "function", Function: domain.FunctionCall{Name: "lookup", Arguments: "{}"}}}},
+			{Role: domain.MessageRoleTool, ToolCallID: "call_1", Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "a cat"}}},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, &maxTokens, req.MaxOutputTokens)
+	require.NotNil(t, req.Store)
+	assert.False(t, *req.Store)
+	assert.Equal(t, []OpenAIResponsesInputItem{
+		{Type: "message", Role: "system", Content: []OpenAIResponsesContent{{Type: "input_text", Text: "Be brief."}}},
+		{Type: "message", Role: "user", Content: []OpenAIResponsesContent{
+			{Type: "input_text", Text: "What is this?"},
+			{Type: "input_image", ImageURL: "https://example.com/cat.png"},
+		}},
+		{Type: "function_call", CallID: "call_1", Name: "lookup", Arguments: "{}"},
+		{Type: "function_call_output", CallID: "call_1", Output: "a cat"},
+	}, req.Input)
+}
+
+func TestOpenAIConvertResponsesRequest_RejectsUnsupportedParameters(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{})
+
+	_, err := client.convertResponsesRequest(&types.CompletionRequest{Model: "o3-pro", Stop: []string{"\n"}})
+
+	var qlensErr *types.QLensError
+	require.True(t, errors.As(err, &qlensErr))
+	assert.Equal(t, types.ErrorTypeInvalidRequest, qlensErr.Type)
+}
+
+func TestOpenAIConvertResponsesResponse(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{})
+
+	var resp OpenAIResponsesResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "resp_1",
+		"object": "response",
+		"created_at": 1700000000,
+		"model": "o3-pro",
+		"status": "completed",
+		"output": [
+			{"type": "reasoning", "id": "rs_1"},
+			{"type": "message", "id": "msg_1", "role": "assistant", "content": [
+				{"type": "output_text", "text": "Checking "},
+				{"type": "output_text", "text": "the weather."}
+			]},
+			{"type": "function_call", "id": "fc_1", "call_id": "call_1", "name": "get_weather", "arguments": "{\"city\":\"Paris\"}"}
+		],
+		"usage": {"input_tokens": 10, "output_tokens": 20, "total_tokens": 30, "output_tokens_details": {"reasoning_tokens": 12}}
+	}`), &resp))
+
+	response, err := client.convertResponsesResponse(&resp, "req-1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "resp_1", response.ID)
+	assert.Equal(t, int64(1700000000), response.Created)
+	require.Len(t, response.Choices, 1)
+
+	choice := response.Choices[0]
+	assert.Equal(t, domain.FinishReasonToolCalls, choice.FinishReason)
+	assert.Equal(t, []domain.ContentPart{{Type: domain.ContentTypeText, Text: "Checking the weather."}}, choice.Message.Content)
+	require.Len(t, choice.Message.ToolCalls, 1)
+	assert.Equal(t, "call_1", choice.Message.ToolCalls[0].ID)
+	assert.Equal(t, "get_weather", choice.Message.ToolCalls[0].Function.Name)
+	assert.Equal(t, 30, response.Usage.TotalTokens)
+	assert.Equal(t, 12, response.Usage.ReasoningTokens)
+}
+
+func TestOpenAIConvertResponsesResponse_Incomplete(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{})
+
+	response, err := client.convertResponsesResponse(&OpenAIResponsesResponse{
+		ID:                "resp_2",
+		Status:            "incomplete",
+		IncompleteDetails: &OpenAIResponsesIncomplete{Reason: "max_output_tokens"},
+		Output: []OpenAIResponsesOutputItem{{
+			Type:    "message",
+			Content: []OpenAIResponsesContent{{Type: "output_text", Text: "Once upon"}},
+		}},
+	}, "req-2", 0)
+	require.NoError(t, err)
+	assert.Equal(t, domain.FinishReasonLength, response.Choices[0].FinishReason)
+
+	_, err = client.convertResponsesResponse(&OpenAIResponsesResponse{
+		ID:     "resp_3",
+		Status: "failed",
+		Error:  &OpenAIResponsesError{Code: "server_error", Message: "The model failed"},
+	}, "req-3", 0)
+	var qlensErr *types.QLensError
+	require.True(t, errors.As(err, &qlensErr))
+	assert.Equal(t, "The model failed", qlensErr.Message)
+}
+
+func TestOpenAICreateCompletion_ResponsesFormat(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{"id":"resp_1","model":"o3-pro","status":"completed","output":[{"type":"message","content":[{"type":"output_text","text":"hi"}]}],"usage":{"input_tokens":1,"output_tokens":1,"total_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(types.ProviderConfig{
+		APIKey:  "secret",
+		BaseURL: server.URL,
+		Models:  map[string]types.ModelSpec{"o3-pro": {APIFormat: types.APIFormatResponses}},
+	})
+	response, err := client.CreateCompletion(context.Background(), &types.CompletionRequest{Model: "o3-pro"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "/responses", path)
+	assert.Equal(t, "hi", response.Choices[0].Message.Content[0].Text)
+	assert.Equal(t, domain.FinishReasonStop, response.Choices[0].FinishReason)
+}
+
+func TestOpenAIHandleResponsesStream(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{})
+	body := io.NopCloser(strings.NewReader(strings.Join([]string{
+		"event: response.created",
+		`data: {"type":"response.created","response":{"id":"resp_1","model":"o3-pro","created_at":1700000000,"status":"in_progress"}}`,
+		"",
+		"event: response.output_text.delta",
+		`data: {"type":"response.output_text.delta","delta":"Hel"}`,
+		"",
+		`data: {"type":"response.output_text.delta","delta":"lo"}`,
+		"",
+		`data: {"type":"response.completed","response":{"id":"resp_1","status":"completed","output":[{"type":"message","content":[{"type":"output_text","text":"Hello"}]}]}}`,
+		"",
+	}, "\n")))
+	streamChan := make(chan types.StreamResponse, 10)
+
+	client.handleResponsesStream(context.Background(), body, streamChan, "req-1", "")
+
+	var chunks []types.StreamResponse
+	for chunk := range streamChan {
+		chunks = append(chunks, chunk)
+	}
+	require.Len(t, chunks, 5)
+
+	assert.Equal(t, domain.MessageRoleAssistant, *chunks[0].Choices[0].Delta.Role)
+	assert.Equal(t, "Hel", *chunks[1].Choices[0].Delta.Content)
+	assert.Equal(t, "lo", *chunks[2].Choices[0].Delta.Content)
+	assert.Equal(t, "resp_1", chunks[2].ID)
+	assert.Equal(t, "o3-pro", chunks[2].Model)
+	assert.Equal(t, domain.FinishReasonStop, *chunks[3].Choices[0].FinishReason)
+	assert.True(t, chunks[4].Done)
+}
+
+func TestOpenAIHandleResponsesStream_Errors(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{})
+
+	body := io.NopCloser(strings.NewReader(`data: {"type":"error","code":"rate_limit_error","message":"Rate limit reached"}` + "\n"))
+	streamChan := make(chan types.StreamResponse, 1)
+	client.handleResponsesStream(context.Background(), body, streamChan, "req-1", "")
+
+	chunk := <-streamChan
+	require.NotNil(t, chunk.Error)
+	assert.Equal(t, types.ErrorTypeRateLimitExceeded, chunk.Error.Type)
+
+	// A stream cut off before the response completed is not a success
+	body = io.NopCloser(strings.NewReader(`data: {"type":"response.output_text.delta","delta":"Hel"}` + "\n"))
+	streamChan = make(chan types.StreamResponse, 2)
+	client.handleResponsesStream(context.Background(), body, streamChan, "req-1", "")
+
+	<-streamChan
+	chunk = <-streamChan
+	require.NotNil(t, chunk.Error)
+	assert.Equal(t, types.ErrorTypeProviderError, chunk.Error.Type)
+}
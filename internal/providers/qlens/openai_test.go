@@ -0,0 +1,387 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+	"github.com/quantum-suite/platform/pkg/shared/version"
+)
+
+func TestOpenAIConvertCompletionResponse_Refusal(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{})
+
+	var resp OpenAIChatCompletionResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "chatcmpl-1",
+		"model": "gpt-4o",
+		"choices": [{
+			"index": 0,
+			"message": {"role": "assistant", "content": null, "refusal": "I can't help with that."},
+			"finish_reason": "stop"
+		}]
+	}`), &resp))
+
+	response, err := client.convertCompletionResponse(&resp, "req-1", 0)
+	require.NoError(t, err)
+	require.Len(t, response.Choices, 1)
+
+	choice := response.Choices[0]
+	assert.Equal(t, domain.FinishReasonRefusal, choice.FinishReason)
+	assert.Equal(t, []domain.ContentPart{{Type: domain.ContentTypeRefusal, Text: "I can't help with that."}}, choice.Message.Content)
+}
+
+func TestOpenAIConvertCompletionResponse_ToolCallsOnly(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{})
+
+	// Some compatible providers report stop even when only tools were called
+	var resp OpenAIChatCompletionResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "chatcmpl-1",
+		"model": "llama-3.1-70b-versatile",
+		"choices": [{
+			"index": 0,
+			"message": {
+				"role": "assistant",
+				"content": null,
+				"tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{}"}}]
+			},
+			"finish_reason": "stop"
+		}]
+	}`), &resp))
+
+	response, err := client.convertCompletionResponse(&resp, "req-1", 0)
+	require.NoError(t, err)
+
+	choice := response.Choices[0]
+	assert.Equal(t, domain.FinishReasonToolCalls, choice.FinishReason)
+	require.Len(t, choice.Message.ToolCalls, 1)
+	require.NotNil(t, choice.Message.Content)
+	assert.Empty(t, choice.Message.Content)
+}
+
+func TestOpenAIConvertCompletionResponse_NoChoices(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{})
+
+	response, err := client.convertCompletionResponse(&OpenAIChatCompletionResponse{ID: "chatcmpl-2", Choices: []OpenAIChoice{}}, "req-2", 0)
+	assert.Nil(t, response)
+
+	qlensErr, ok := err.(*types.QLensError)
+	require.True(t, ok, "expected a QLensError, got %v", err)
+	assert.Equal(t, types.ErrorTypeProviderError, qlensErr.Type)
+	assert.Equal(t, "chatcmpl-2", qlensErr.Details["response_id"])
+}
+
+func TestOpenAIConvertCompletionResponse_MissingUsage(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{})
+	missing := responseAnomalies.WithLabelValues(string(domain.ProviderOpenAI), "usage")
+	before := testutil.ToFloat64(missing)
+
+	var resp OpenAIChatCompletionResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"id": "chatcmpl-1", "model": "gpt-4o", "choices": [{"message": {"content": "hi"}}], "usage": null}`), &resp))
+
+	response, err := client.convertCompletionResponse(&resp, "req-1", 0)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Usage{}, response.Usage)
+	assert.Equal(t, "hi", response.Choices[0].Message.Content[0].Text)
+	assert.Equal(t, before+1, testutil.ToFloat64(missing))
+}
+
+func TestOpenAIMessage_ContentJSON(t *testing.T) {
+	data, err := json.Marshal(OpenAIMessage{Role: "user", Content: "Hello"})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"role": "user", "content": "Hello"}`, string(data))
+
+	data, err = json.Marshal(OpenAIMessage{Role: "user", MultiContent: []OpenAIContentPart{{Type: "text", Text: "Hello"}}})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"role": "user", "content": [{"type": "text", "text": "Hello"}]}`, string(data))
+
+	var message OpenAIMessage
+	require.NoError(t, json.Unmarshal([]byte(`{"role": "assistant", "content": "Hi"}`), &message))
+	assert.Equal(t, OpenAIMessage{Role: "assistant", Content: "Hi"}, message)
+
+	message = OpenAIMessage{}
+	require.NoError(t, json.Unmarshal([]byte(`{"role": "assistant", "content": [{"type": "text", "text": "Hi"}]}`), &message))
+	assert.Equal(t, []OpenAIContentPart{{Type: "text", Text: "Hi"}}, message.MultiContent)
+
+	message = OpenAIMessage{}
+	require.NoError(t, json.Unmarshal([]byte(`{"role": "assistant", "content": null, "tool_calls": [{"id": "call_1"}]}`), &message))
+	assert.Empty(t, message.Content)
+	assert.Len(t, message.ToolCalls, 1)
+}
+
+// malformedOpenAIPayloads are valid JSON but break the shape OpenAI
+// documents: null or missing fields, wrong counts and empty collections
+var malformedOpenAIPayloads = []string{
+	`{}`,
+	`{"choices": null, "usage": null}`,
+	`{"choices": [null]}`,
+	`{"choices": [{"message": null, "finish_reason": null}]}`,
+	`{"choices": [{"message": {"role": null, "content": null, "tool_calls": null}}]}`,
+	`{"choices": [{"message": {"content": [null, {"type": "text"}]}}]}`,
+	`{"choices": [{"message": {"tool_calls": [null, {"function": null}]}}]}`,
+	`{"choices": [{"message": {"content": "hi"}}], "usage": {"prompt_tokens": -5, "completion_tokens": 3, "total_tokens": 1}}`,
+	`{"choices": [{"message": {"content": "hi"}}], "usage": {"completion_tokens": 2, "completion_tokens_details": {"reasoning_tokens": 9}}}`,
+}
+
+func FuzzOpenAIConvertCompletionResponse(f *testing.F) {
+	for _, payload := range malformedOpenAIPayloads {
+		f.Add([]byte(payload))
+	}
+
+	client := NewOpenAIClient(types.ProviderConfig{})
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		var resp OpenAIChatCompletionResponse
+		if err := json.Unmarshal(payload, &resp); err != nil {
+			t.Skip()
+		}
+
+		response, err := client.convertCompletionResponse(&resp, "req-1", 0)
+		if err != nil {
+			assert.Nil(t, response)
+			return
+		}
+		require.NotEmpty(t, response.Choices)
+
+		usage := response.Usage
+		assert.GreaterOrEqual(t, usage.PromptTokens, 0)
+		assert.GreaterOrEqual(t, usage.CompletionTokens, 0)
+		assert.GreaterOrEqual(t, usage.ReasoningTokens, 0)
+		assert.LessOrEqual(t, usage.ReasoningTokens, usage.CompletionTokens)
+		assert.GreaterOrEqual(t, usage.TotalTokens, usage.PromptTokens+usage.CompletionTokens)
+		assert.GreaterOrEqual(t, usage.CostUSD, 0.0)
+		for _, choice := range response.Choices {
+			assert.NotEmpty(t, choice.Message.Role)
+		}
+	})
+}
+
+func TestOpenAIMakeRequest_ErrorDetails(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"error":{"message":"Invalid 'messages[0].role'","type":"invalid_request_error","param":"messages[0].role","code":"invalid_value"}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(types.ProviderConfig{APIKey: "secret", BaseURL: server.URL})
+	_, err := client.CreateCompletion(context.Background(), &types.CompletionRequest{Model: "gpt-4o"})
+
+	var qlensErr *types.QLensError
+	require.True(t, errors.As(err, &qlensErr), "expected a QLensError, got %v", err)
+	assert.Equal(t, "invalid_value", qlensErr.Code)
+	assert.Equal(t, map[string]interface{}{
+		"param":         "messages[0].role",
+		"provider_code": "invalid_value",
+		"provider_type": "invalid_request_error",
+	}, qlensErr.Details)
+}
+
+func TestOpenAICreateCompletion_RequestIDs(t *testing.T) {
+	var clientRequestID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clientRequestID = r.Header.Get("X-Client-Request-Id")
+		w.Header().Set("X-Request-Id", "openai-req-1")
+		w.Write([]byte(`{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(types.ProviderConfig{APIKey: "secret", BaseURL: server.URL})
+	response, err := client.CreateCompletion(context.Background(), &types.CompletionRequest{Model: "gpt-4o", RequestID: "req_123"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "req_123", clientRequestID)
+	assert.Equal(t, "req_123", response.RequestID)
+	assert.Equal(t, "openai-req-1", response.ProviderRequestID)
+}
+
+func TestOpenAICreateCompletion_UserAgent(t *testing.T) {
+	original := version.Version
+	version.Version = "2.3.1"
+	defer func() { version.Version = original }()
+
+	var userAgents []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgents = append(userAgents, r.Header.Get("User-Agent"))
+		w.Write([]byte(`{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	for _, configured := range []string{"", "AcmeProxy QLens/{version}"} {
+		client := NewOpenAIClient(types.ProviderConfig{APIKey: "secret", BaseURL: server.URL, UserAgent: configured})
+		_, err := client.CreateCompletion(context.Background(), &types.CompletionRequest{Model: "gpt-4o"})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []string{"QLens/2.3.1", "AcmeProxy QLens/2.3.1"}, userAgents)
+}
+
+func TestOpenAICreateCompletion_RawResponse(t *testing.T) {
+	body := `{"id":"chatcmpl-1","model":"gpt-4o","choices":[{"index":0,"message":{"role":"assistant","content":"hi"},"finish_reason":"stop"}],"usage":{"prompt_tokens":1,"completion_tokens":1,"total_tokens":2},"system_fingerprint":"fp_1"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(types.ProviderConfig{APIKey: "secret", BaseURL: server.URL})
+	response, err := client.CreateCompletion(context.Background(), &types.CompletionRequest{Model: "gpt-4o", IncludeRawResponse: true})
+	require.NoError(t, err)
+	raw, ok := response.Metadata[domain.MetadataKeyRawProviderResponse].(json.RawMessage)
+	require.True(t, ok)
+	assert.JSONEq(t, body, string(raw))
+
+	response, err = client.CreateCompletion(context.Background(), &types.CompletionRequest{Model: "gpt-4o"})
+	require.NoError(t, err)
+	assert.NotContains(t, response.Metadata, domain.MetadataKeyRawProviderResponse)
+}
+
+func TestOpenAICreateCompletion_AttributesErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"Rate limit reached","type":"requests"}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(types.ProviderConfig{APIKey: "secret", BaseURL: server.URL})
+	req := &types.CompletionRequest{Model: "gpt-4o", TenantID: "tenant-a", UserID: "user-1", RequestID: "req_123"}
+
+	_, completionErr := client.CreateCompletion(context.Background(), req)
+	_, streamErr := client.CreateCompletionStream(context.Background(), req)
+	for _, err := range []error{completionErr, streamErr} {
+		var qlensErr *types.QLensError
+		require.True(t, errors.As(err, &qlensErr))
+		assert.Equal(t, domain.TenantID("tenant-a"), qlensErr.TenantID)
+		assert.Equal(t, domain.UserID("user-1"), qlensErr.UserID)
+		assert.Equal(t, "req_123", qlensErr.RequestID)
+
+		// Attribution stays out of the serialized error
+		data, marshalErr := json.Marshal(qlensErr)
+		require.NoError(t, marshalErr)
+		assert.NotContains(t, string(data), "tenant-a")
+	}
+}
+
+func TestOpenAICreateCompletionStream_TypedErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantType    string
+	}{
+		{name: "rate limited", status: http.StatusTooManyRequests, contentType: "application/json", body: `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`, wantType: types.ErrorTypeRateLimitExceeded},
+		{name: "bad request", status: http.StatusBadRequest, contentType: "application/json", body: `{"error":{"message":"Invalid model","type":"invalid_request_error"}}`, wantType: types.ErrorTypeInvalidRequest},
+		{name: "unavailable with html body", status: http.StatusServiceUnavailable, contentType: "text/html", body: `<html>down</html>`, wantType: types.ErrorTypeProviderUnavailable},
+		{name: "json error with 200", status: http.StatusOK, contentType: "application/json", body: `{"error":{"message":"Overloaded","type":"overloaded_error"}}`, wantType: types.ErrorTypeProviderUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewOpenAIClient(types.ProviderConfig{APIKey: "secret", BaseURL: server.URL})
+			stream, err := client.CreateCompletionStream(context.Background(), &types.CompletionRequest{Model: "gpt-4o"})
+			assert.Nil(t, stream)
+
+			var qlensErr *types.QLensError
+			require.True(t, errors.As(err, &qlensErr), "expected a QLensError, got %v", err)
+			assert.Equal(t, tt.wantType, qlensErr.Type)
+
+			// Streaming and non-streaming calls fail the same way
+			_, err = client.CreateCompletion(context.Background(), &types.CompletionRequest{Model: "gpt-4o"})
+			if tt.status != http.StatusOK {
+				var completionErr *types.QLensError
+				require.True(t, errors.As(err, &completionErr))
+				assert.Equal(t, qlensErr.Type, completionErr.Type)
+				assert.Equal(t, qlensErr.Message, completionErr.Message)
+			}
+		})
+	}
+}
+
+func TestOpenAIHandleStream_MidStreamError(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{})
+	body := io.NopCloser(strings.NewReader("data: {\"error\":{\"message\":\"Rate limit reached\",\"type\":\"rate_limit_error\"}}\n\n"))
+	streamChan := make(chan types.StreamResponse, 1)
+
+	client.handleStream(context.Background(), body, streamChan, "req-1", "")
+
+	chunk := <-streamChan
+	require.NotNil(t, chunk.Error)
+	assert.Equal(t, types.ErrorTypeRateLimitExceeded, chunk.Error.Type)
+	assert.Equal(t, "Rate limit reached", chunk.Error.Message)
+}
+
+func TestOpenAIConvertCompletionResponse_OrdersChoices(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{})
+
+	var resp OpenAIChatCompletionResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "chatcmpl-1",
+		"model": "gpt-4o",
+		"choices": [
+			{"index": 1, "message": {"role": "assistant"}, "finish_reason": "length"},
+			{"index": 0, "message": {"role": "assistant"}, "finish_reason": "stop"}
+		]
+	}`), &resp))
+
+	response, err := client.convertCompletionResponse(&resp, "req-1", 0)
+	require.NoError(t, err)
+	require.Len(t, response.Choices, 2)
+	assert.Equal(t, domain.FinishReasonStop, response.Choices[0].FinishReason)
+	assert.Equal(t, domain.FinishReasonLength, response.Choices[1].FinishReason)
+	assert.Equal(t, "chatcmpl-1-0", response.Choices[0].ID)
+	assert.Equal(t, "chatcmpl-1-1", response.Choices[1].ID)
+}
+
+func TestOpenAIConvertStreamChunk_InterleavedChoices(t *testing.T) {
+	client := NewOpenAIClient(types.ProviderConfig{})
+
+	var first, second OpenAIChatCompletionChunk
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"chatcmpl-1","choices":[{"index":1,"delta":{"content":"Good"}},{"index":0,"delta":{"content":"Hel"}}]}`), &first))
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"chatcmpl-1","choices":[{"index":0,"delta":{"content":"lo"}},{"index":1,"delta":{"content":"bye"}}]}`), &second))
+
+	texts := make(map[string]string)
+	for _, chunk := range []*OpenAIChatCompletionChunk{&first, &second} {
+		converted := client.convertStreamChunk(chunk, "req-1")
+		require.Len(t, converted.Choices, 2)
+		assert.Equal(t, 0, converted.Choices[0].Index)
+		for _, choice := range converted.Choices {
+			texts[choice.ID] += *choice.Delta.Content
+		}
+	}
+	assert.Equal(t, map[string]string{"chatcmpl-1-0": "Hello", "chatcmpl-1-1": "Goodbye"}, texts)
+}
+
+func TestOpenAIWarmConnection(t *testing.T) {
+	var requests []string
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+r.Header.Get("Authorization"))
+		w.WriteHeader(status)
+		w.Write([]byte(`{"object":"list","data":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient(types.ProviderConfig{APIKey: "secret", BaseURL: server.URL})
+	require.NoError(t, client.WarmConnection(context.Background()))
+	assert.Equal(t, []string{"GET /models Bearer secret"}, requests)
+
+	status = http.StatusUnauthorized
+	assert.Error(t, client.WarmConnection(context.Background()))
+}
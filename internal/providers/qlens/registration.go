@@ -0,0 +1,42 @@
+package providers
+
+import (
+	"fmt"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/providers/registry"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+func init() {
+	for provider, profile := range openAICompatibleProfiles {
+		registry.RegisterProviderFactory(provider, registry.Factory{SDK: openAICompatibleFactory(profile)})
+	}
+	registry.RegisterProviderFactory(domain.ProviderAnthropic, registry.Factory{SDK: newAnthropicSDKClient})
+	registry.RegisterProviderFactory(domain.ProviderLocal, registry.Factory{SDK: newLocalSDKClient})
+}
+
+// newAnthropicSDKClient builds the SDK's Anthropic Messages API client
+func newAnthropicSDKClient(provider domain.Provider, config types.ProviderConfig) (types.ProviderClient, error) {
+	return NewAnthropicClient(config), nil
+}
+
+// newLocalSDKClient builds the SDK's client for a self-hosted
+// OpenAI-compatible server, which has no default address
+func newLocalSDKClient(provider domain.Provider, config types.ProviderConfig) (types.ProviderClient, error) {
+	if config.BaseURL == "" {
+		return nil, fmt.Errorf("provider %s requires a base URL", provider)
+	}
+	return openAICompatibleFactory(LocalProfile)(provider, config)
+}
+
+// openAICompatibleFactory builds SDK clients for an OpenAI-compatible API
+// described by profile
+func openAICompatibleFactory(profile OpenAICompatibleProfile) registry.SDKFactory {
+	return func(provider domain.Provider, config types.ProviderConfig) (types.ProviderClient, error) {
+		if config.Provider == "" {
+			config.Provider = provider
+		}
+		return NewOpenAICompatibleClient(profile, config), nil
+	}
+}
@@ -0,0 +1,27 @@
+package providers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+var responseAnomalies = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_sdk_provider_response_anomalies_total",
+		Help: "Provider responses to the SDK clients with a missing or inconsistent field that was defaulted or repaired",
+	},
+	[]string{"provider", "field"},
+)
+
+// checkUsage repairs inconsistent token counts, counting each fix. Missing
+// usage is reported as zero rather than failing a response that otherwise
+// succeeded.
+func checkUsage(provider domain.Provider, usage *domain.Usage, missing bool) {
+	if missing {
+		responseAnomalies.WithLabelValues(string(provider), "usage").Inc()
+	}
+	for _, field := range usage.Repair() {
+		responseAnomalies.WithLabelValues(string(provider), "usage."+field).Inc()
+	}
+}
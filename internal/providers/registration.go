@@ -0,0 +1,83 @@
+package providers
+
+import (
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/providers/registry"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func init() {
+	registry.RegisterProviderFactory(domain.ProviderAzureOpenAI, registry.Factory{Service: newAzureOpenAIServiceClient})
+	registry.RegisterProviderFactory(domain.ProviderAWSBedrock, registry.Factory{Service: newAWSBedrockServiceClient})
+}
+
+// newAzureOpenAIServiceClient builds the router's Azure OpenAI client
+func newAzureOpenAIServiceClient(provider domain.Provider, config env.ProviderConfig, log logger.Logger) (registry.Client, error) {
+	settings, err := config.AzureOpenAISettings()
+	if err != nil {
+		return nil, err
+	}
+	azureConfig := AzureOpenAIConfig{
+		Endpoint:   config.BaseURL,
+		APIKey:     config.APIKey,
+		APIVersion: "2024-02-15-preview", // Stable API version
+		Deployments: map[string]string{
+			"gpt-35-turbo": "gpt-35-turbo-0125",
+			"gpt-4":        "gpt-4-turbo-2024-04-09",
+			"gpt-4o":       "gpt-4o-2024-05-13",
+			"gpt-4o-mini":  "gpt-4o-mini-2024-07-18",
+			"gpt-5":        "gpt-5-2025-08-07",
+			"gpt-5-mini":   "gpt-5-mini-2025-08-07",
+		},
+	}
+	azureConfig.UserAgent = config.UserAgent
+	azureConfig.CustomHeaders = config.CustomHeaders
+	azureConfig.AllowedAPIVersions = settings.AllowedAPIVersions
+	azureConfig.HealthProbe = HealthProbe(settings.HealthProbe)
+	azureConfig.DeploymentFailover = settings.DeploymentFailover
+	return NewAzureOpenAIClient(azureConfig, log)
+}
+
+// newAWSBedrockServiceClient builds the router's AWS Bedrock client
+func newAWSBedrockServiceClient(provider domain.Provider, config env.ProviderConfig, log logger.Logger) (registry.Client, error) {
+	settings, err := config.AWSBedrockSettings()
+	if err != nil {
+		return nil, err
+	}
+	models := []BedrockModelConfig{
+		{
+			ID:      "claude-3.7-sonnet",
+			ModelID: "anthropic.claude-3-7-sonnet-20250219-v1:0",
+			Name:    "Claude 3.7 Sonnet",
+		},
+		{
+			ID:      "claude-3-sonnet",
+			ModelID: "anthropic.claude-3-sonnet-20240229-v1:0",
+			Name:    "Claude 3 Sonnet",
+		},
+		{
+			ID:      "claude-3-haiku",
+			ModelID: "anthropic.claude-3-haiku-20240307-v1:0",
+			Name:    "Claude 3 Haiku",
+		},
+	}
+
+	bedrockConfig := AWSBedrockConfig{
+		Region:          settings.Region,
+		AccessKeyID:     config.APIKey, // Using APIKey field
+		SecretAccessKey: config.SecretKey,
+		SessionToken:    "",
+		Models:          models,
+		UserAgent:       config.UserAgent,
+		CustomHeaders:   config.CustomHeaders,
+	}
+	bedrockConfig.AllowedAnthropicVersions = settings.AllowedAnthropicVersions
+	bedrockConfig.HealthProbe = HealthProbe(settings.HealthProbe)
+	// Models with purchased capacity are invoked through their
+	// provisioned throughput ARN
+	for i := range bedrockConfig.Models {
+		bedrockConfig.Models[i].ProvisionedThroughputARN = settings.ProvisionedThroughput[bedrockConfig.Models[i].ID]
+	}
+	return NewAWSBedrockClient(bedrockConfig, log)
+}
@@ -0,0 +1,79 @@
+package registry
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// Client is the interface the router service calls a provider through
+type Client interface {
+	CreateCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error)
+	CreateCompletionStream(ctx context.Context, req *domain.CompletionRequest) (<-chan *domain.StreamResponse, error)
+	CreateEmbeddings(ctx context.Context, req *domain.EmbeddingRequest) (*domain.EmbeddingResponse, error)
+	ListModels(ctx context.Context) ([]domain.Model, error)
+	HealthCheck(ctx context.Context) error
+}
+
+// ServiceFactory builds the client the router service uses for a provider
+// from its environment configuration
+type ServiceFactory func(provider domain.Provider, config env.ProviderConfig, log logger.Logger) (Client, error)
+
+// SDKFactory builds the client the qlens SDK calls a provider with directly
+type SDKFactory func(provider domain.Provider, config types.ProviderConfig) (types.ProviderClient, error)
+
+// Factory is how a provider adapter builds its clients. An adapter only
+// available to one side leaves the other constructor nil.
+type Factory struct {
+	Service ServiceFactory
+	SDK     SDKFactory
+}
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[domain.Provider]Factory)
+)
+
+// RegisterProviderFactory registers how the clients of a provider are built.
+// Adapters call it from init, so adding a provider needs no change to the
+// router or the SDK. Constructors the factory sets replace those registered
+// before for the provider; ones it leaves nil are kept, so the service and
+// SDK adapters of a provider can register from different packages.
+func RegisterProviderFactory(provider domain.Provider, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	registered := factories[provider]
+	if factory.Service != nil {
+		registered.Service = factory.Service
+	}
+	if factory.SDK != nil {
+		registered.SDK = factory.SDK
+	}
+	factories[provider] = registered
+}
+
+// Lookup returns the factory registered for a provider
+func Lookup(provider domain.Provider) (Factory, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	factory, ok := factories[provider]
+	return factory, ok
+}
+
+// Providers lists the providers with a registered factory, sorted by name
+func Providers() []domain.Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+	providers := make([]domain.Provider, 0, len(factories))
+	for provider := range factories {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+	return providers
+}
@@ -0,0 +1,31 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestRegisterProviderFactory_KeepsConstructorsLeftNil(t *testing.T) {
+	provider := domain.Provider("registry-test")
+	service := func(domain.Provider, env.ProviderConfig, logger.Logger) (Client, error) { return nil, nil }
+	sdk := func(domain.Provider, types.ProviderConfig) (types.ProviderClient, error) { return nil, nil }
+
+	_, ok := Lookup(provider)
+	assert.False(t, ok)
+
+	RegisterProviderFactory(provider, Factory{Service: service})
+	RegisterProviderFactory(provider, Factory{SDK: sdk})
+
+	factory, ok := Lookup(provider)
+	require.True(t, ok)
+	assert.NotNil(t, factory.Service)
+	assert.NotNil(t, factory.SDK)
+	assert.Contains(t, Providers(), provider)
+}
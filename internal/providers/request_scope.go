@@ -0,0 +1,56 @@
+package providers
+
+import (
+	goerrors "errors"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// requestScope identifies the tenant, user and gateway request a provider
+// call is made for, so provider logs and errors can be attributed when many
+// tenants share a client
+type requestScope struct {
+	tenantID  domain.TenantID
+	userID    domain.UserID
+	requestID string
+}
+
+func completionScope(req *domain.CompletionRequest) requestScope {
+	return requestScope{tenantID: req.TenantID, userID: req.UserID, requestID: req.RequestID}
+}
+
+func embeddingScope(req *domain.EmbeddingRequest) requestScope {
+	return requestScope{tenantID: req.TenantID, userID: req.UserID, requestID: req.RequestID}
+}
+
+// logger returns log with the scope's tenant, user and correlation ID
+// attached
+func (s requestScope) logger(log logger.Logger) logger.Logger {
+	if s.tenantID != "" {
+		log = log.WithTenant(string(s.tenantID))
+	}
+	if s.userID != "" {
+		log = log.WithUser(string(s.userID))
+	}
+	if s.requestID != "" {
+		log = log.WithCorrelationID(s.requestID)
+	}
+	return log
+}
+
+// attribute records the scope on a provider error. Errors that are not
+// QLensErrors are returned unchanged.
+func (s requestScope) attribute(err error) error {
+	var qlensErr *errors.QLensError
+	if goerrors.As(err, &qlensErr) {
+		qlensErr.WithRequestContext(string(s.tenantID), string(s.userID), s.requestID)
+	}
+	return err
+}
+
+// attributeStreamError is attribute for the typed error of a stream chunk
+func (s requestScope) attributeStreamError(err *errors.QLensError) *errors.QLensError {
+	return err.WithRequestContext(string(s.tenantID), string(s.userID), s.requestID)
+}
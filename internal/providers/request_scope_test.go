@@ -0,0 +1,74 @@
+package providers
+
+import (
+	"context"
+	goerrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestAzureOpenAI_AttributesErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"message":"deployment not found","code":"DeploymentNotFound"}}`))
+	}))
+	defer server.Close()
+
+	client, err := NewAzureOpenAIClient(AzureOpenAIConfig{
+		Endpoint:    server.URL,
+		APIKey:      "secret",
+		Deployments: map[string]string{"gpt-4": "gpt-4"},
+	}, logger.NewNoop())
+	require.NoError(t, err)
+
+	req := &domain.CompletionRequest{
+		TenantID:  "tenant-a",
+		UserID:    "user-1",
+		RequestID: "req_123",
+		Model:     "gpt-4",
+		Messages:  []domain.Message{{Role: domain.MessageRoleUser, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "hello"}}}},
+	}
+
+	for name, call := range map[string]func() error{
+		"completion": func() error {
+			_, err := client.CreateCompletion(context.Background(), req)
+			return err
+		},
+		"stream": func() error {
+			_, err := client.CreateCompletionStream(context.Background(), req)
+			return err
+		},
+		"embeddings": func() error {
+			_, err := client.CreateEmbeddings(context.Background(), &domain.EmbeddingRequest{
+				TenantID: "tenant-a", UserID: "user-1", RequestID: "req_123", Model: "gpt-4", Input: []string{"hello"},
+			})
+			return err
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var qlensErr *errors.QLensError
+			require.True(t, goerrors.As(call(), &qlensErr))
+			assert.Equal(t, "tenant-a", qlensErr.TenantID)
+			assert.Equal(t, "user-1", qlensErr.UserID)
+			assert.Equal(t, "req_123", qlensErr.RequestID)
+		})
+	}
+}
+
+func TestRequestScope_KeepsExistingAttribution(t *testing.T) {
+	err := errors.NewError(errors.ErrorTypeProviderError, "failed").WithTenant("tenant-b").Build()
+
+	completionScope(&domain.CompletionRequest{TenantID: "tenant-a", UserID: "user-1"}).attribute(err)
+	assert.Equal(t, "tenant-b", err.TenantID)
+	assert.Equal(t, "user-1", err.UserID)
+
+	assert.NoError(t, requestScope{tenantID: "tenant-a"}.attribute(nil))
+}
@@ -0,0 +1,41 @@
+package providers
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+var providerResponseAnomalies = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_provider_response_anomalies_total",
+		Help: "Provider responses with a missing or inconsistent field that was defaulted or repaired",
+	},
+	[]string{"provider", "field"},
+)
+
+// recordResponseAnomaly counts and logs a provider response field that had
+// to be defaulted or repaired
+func recordResponseAnomaly(log logger.Logger, provider domain.Provider, model, field string) {
+	providerResponseAnomalies.WithLabelValues(string(provider), field).Inc()
+	if log != nil {
+		log.Warn("Provider response field missing or inconsistent",
+			logger.F("provider", provider),
+			logger.F("model", model),
+			logger.F("field", field),
+		)
+	}
+}
+
+// checkUsage repairs inconsistent token counts, recording each fix. Missing
+// usage is reported as zero rather than failing a response that otherwise
+// succeeded.
+func checkUsage(log logger.Logger, provider domain.Provider, model string, usage *domain.Usage, missing bool) {
+	if missing {
+		recordResponseAnomaly(log, provider, model, "usage")
+	}
+	for _, field := range usage.Repair() {
+		recordResponseAnomaly(log, provider, model, "usage."+field)
+	}
+}
@@ -0,0 +1,151 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// malformedCompletionPayloads are valid JSON but break the shape providers
+// document: null or missing fields, wrong counts and empty collections
+var malformedCompletionPayloads = []string{
+	`{}`,
+	`{"choices": null, "usage": null}`,
+	`{"choices": [null]}`,
+	`{"choices": [{"message": null, "delta": null, "finish_reason": null}]}`,
+	`{"choices": [{"message": {"role": null, "content": null, "tool_calls": null}}]}`,
+	`{"choices": [{"message": {"tool_calls": [null, {"function": null}]}}]}`,
+	`{"choices": [{"message": {"content": "hi"}}], "usage": {"prompt_tokens": -5, "completion_tokens": 3, "total_tokens": 1}}`,
+	`{"choices": [{"message": {"content": "hi"}}], "usage": {"completion_tokens": 2, "completion_tokens_details": {"reasoning_tokens": 9}}}`,
+	`{"content": null, "stop_reason": null, "usage": null}`,
+	`{"content": [null, {"type": "tool_use", "input": null}], "stop_reason": "tool_use"}`,
+	`{"content": [{"type": "thinking", "thinking": "hmm"}], "stop_reason": "end_turn", "usage": {"input_tokens": -1, "output_tokens": -2}}`,
+	`{"content": [{"type": "unknown"}], "stop_reason": "end_turn"}`,
+}
+
+// assertSaneCompletion checks the invariants every converted response must
+// hold whatever the provider sent
+func assertSaneCompletion(t *testing.T, response *domain.CompletionResponse, err error) {
+	t.Helper()
+
+	if err != nil {
+		assert.Nil(t, response)
+		return
+	}
+	require.NotNil(t, response)
+	require.NotEmpty(t, response.Choices)
+
+	usage := response.Usage
+	assert.GreaterOrEqual(t, usage.PromptTokens, 0)
+	assert.GreaterOrEqual(t, usage.CompletionTokens, 0)
+	assert.GreaterOrEqual(t, usage.ReasoningTokens, 0)
+	assert.LessOrEqual(t, usage.ReasoningTokens, usage.CompletionTokens)
+	assert.GreaterOrEqual(t, usage.TotalTokens, usage.PromptTokens+usage.CompletionTokens)
+	assert.GreaterOrEqual(t, usage.CostUSD, 0.0)
+
+	for _, choice := range response.Choices {
+		assert.NotEmpty(t, choice.Message.Role)
+	}
+}
+
+func FuzzAzureOpenAIConvertCompletionResponse(f *testing.F) {
+	for _, payload := range malformedCompletionPayloads {
+		f.Add([]byte(payload))
+	}
+
+	client := &AzureOpenAIClient{}
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		var azureResp azureOpenAIResponse
+		if err := json.Unmarshal(payload, &azureResp); err != nil {
+			t.Skip()
+		}
+
+		response, err := client.convertCompletionResponse(&azureResp, "gpt-4o", client.logger)
+		assertSaneCompletion(t, response, err)
+	})
+}
+
+func FuzzBedrockConvertCompletionResponse(f *testing.F) {
+	for _, payload := range malformedCompletionPayloads {
+		f.Add([]byte(payload))
+	}
+
+	client := &AWSBedrockClient{}
+	f.Fuzz(func(t *testing.T, payload []byte) {
+		var claudeResp claudeResponse
+		if err := json.Unmarshal(payload, &claudeResp); err != nil {
+			t.Skip()
+		}
+
+		response, err := client.convertCompletionResponse(&claudeResp, "claude-3-haiku", client.logger)
+		assertSaneCompletion(t, response, err)
+	})
+}
+
+func TestAzureOpenAIConvertCompletionResponse_MissingUsage(t *testing.T) {
+	client := &AzureOpenAIClient{}
+	missing := providerResponseAnomalies.WithLabelValues(string(domain.ProviderAzureOpenAI), "usage")
+	before := testutil.ToFloat64(missing)
+
+	var azureResp azureOpenAIResponse
+	require.NoError(t, json.Unmarshal([]byte(`{"id": "chatcmpl-1", "choices": [{"message": {"content": "hi"}}], "usage": null}`), &azureResp))
+
+	response, err := client.convertCompletionResponse(&azureResp, "gpt-4o", client.logger)
+	require.NoError(t, err)
+	assert.Equal(t, domain.Usage{}, response.Usage)
+	assert.Equal(t, domain.MessageRoleAssistant, response.Choices[0].Message.Role)
+	assert.Equal(t, before+1, testutil.ToFloat64(missing))
+}
+
+func TestBedrockConvertCompletionResponse_NoContent(t *testing.T) {
+	client := &AWSBedrockClient{}
+
+	response, err := client.convertCompletionResponse(&claudeResponse{ID: "msg_1"}, "claude-3-haiku", client.logger)
+	assert.Nil(t, response)
+	require.Error(t, err)
+}
+
+func TestAzureOpenAIConvertEmbeddingResponse_Malformed(t *testing.T) {
+	client := &AzureOpenAIClient{}
+
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{name: "fewer embeddings than inputs", payload: `{"data": [{"index": 0, "embedding": [0.1]}]}`},
+		{name: "null embedding", payload: `{"data": [{"index": 0, "embedding": null}, {"index": 1, "embedding": [0.1]}]}`},
+		{name: "index out of range", payload: `{"data": [{"index": 0, "embedding": [0.1]}, {"index": 7, "embedding": [0.1]}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var azureResp azureOpenAIEmbeddingResponse
+			require.NoError(t, json.Unmarshal([]byte(tt.payload), &azureResp))
+
+			response, err := client.convertEmbeddingResponse(&azureResp, 2, "", client.logger)
+			assert.Nil(t, response)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestAzureOpenAIConvertEmbeddingResponse_Base64(t *testing.T) {
+	client := &AzureOpenAIClient{}
+	payload := `{"data": [{"index": 0, "embedding": [0.5, -1.25]}], "usage": {"prompt_tokens": 2, "total_tokens": 2}}`
+
+	var azureResp azureOpenAIEmbeddingResponse
+	require.NoError(t, json.Unmarshal([]byte(payload), &azureResp))
+
+	response, err := client.convertEmbeddingResponse(&azureResp, 1, domain.EmbeddingEncodingBase64, client.logger)
+	require.NoError(t, err)
+	assert.Equal(t, domain.EncodeEmbeddingBase64([]float64{0.5, -1.25}), response.Data[0].Base64)
+
+	response, err = client.convertEmbeddingResponse(&azureResp, 1, domain.EmbeddingEncodingFloat, client.logger)
+	require.NoError(t, err)
+	assert.Empty(t, response.Data[0].Base64)
+}
@@ -0,0 +1,39 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+func TestApplyStoredCompletion(t *testing.T) {
+	store := true
+	req := prefilledRequest("")
+	req.Store = &store
+	req.Metadata = map[string]interface{}{"experiment": "baseline", "benchmark": true}
+
+	azureReq := &azureOpenAIRequest{}
+	applyStoredCompletion(azureReq, req, "2024-10-01-preview")
+	assert.Equal(t, &store, azureReq.Store)
+	assert.Equal(t, map[string]string{"experiment": "baseline"}, azureReq.Metadata)
+
+	// Older API versions reject the fields
+	azureReq = &azureOpenAIRequest{}
+	applyStoredCompletion(azureReq, req, "2024-06-01")
+	assert.Nil(t, azureReq.Store)
+	assert.Nil(t, azureReq.Metadata)
+
+	// Metadata only goes with a stored completion
+	store = false
+	azureReq = &azureOpenAIRequest{}
+	applyStoredCompletion(azureReq, req, "2024-10-21")
+	assert.Equal(t, &store, azureReq.Store)
+	assert.Nil(t, azureReq.Metadata)
+
+	azureReq = &azureOpenAIRequest{}
+	applyStoredCompletion(azureReq, &domain.CompletionRequest{Metadata: req.Metadata}, "2024-10-21")
+	assert.Nil(t, azureReq.Store)
+	assert.Nil(t, azureReq.Metadata)
+}
@@ -0,0 +1,67 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+func TestAzureOpenAIConvertCompletionResponse_ToolCallsOnly(t *testing.T) {
+	client := &AzureOpenAIClient{}
+
+	var azureResp azureOpenAIResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "chatcmpl-1",
+		"choices": [{
+			"index": 0,
+			"message": {
+				"role": "assistant",
+				"content": null,
+				"tool_calls": [{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Paris\"}"}}]
+			},
+			"finish_reason": "tool_calls"
+		}]
+	}`), &azureResp))
+
+	response, err := client.convertCompletionResponse(&azureResp, "gpt-4o", client.logger)
+	require.NoError(t, err)
+	assertToolCallOnlyChoice(t, response.Choices[0], `{"city":"Paris"}`)
+}
+
+func TestBedrockConvertCompletionResponse_ToolCallsOnly(t *testing.T) {
+	client := &AWSBedrockClient{}
+
+	var claudeResp claudeResponse
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"id": "msg_1",
+		"content": [{"type": "tool_use", "id": "call_1", "name": "get_weather", "input": {"city": "Paris"}}],
+		"stop_reason": "tool_use",
+		"usage": {"input_tokens": 10, "output_tokens": 5}
+	}`), &claudeResp))
+
+	response, err := client.convertCompletionResponse(&claudeResp, "claude-3-haiku", client.logger)
+	require.NoError(t, err)
+	require.Len(t, response.Choices, 1)
+	assertToolCallOnlyChoice(t, response.Choices[0], `{"city": "Paris"}`)
+}
+
+func assertToolCallOnlyChoice(t *testing.T, choice domain.Choice, arguments string) {
+	t.Helper()
+
+	assert.Equal(t, domain.FinishReasonToolCalls, choice.FinishReason)
+	require.Len(t, choice.Message.ToolCalls, 1)
+	assert.Equal(t, "call_1", choice.Message.ToolCalls[0].ID)
+	assert.Equal(t, "get_weather", choice.Message.ToolCalls[0].Function.Name)
+	assert.Equal(t, arguments, choice.Message.ToolCalls[0].Function.Arguments)
+
+	// Content is an explicit empty list so it serializes as [] rather than null
+	require.NotNil(t, choice.Message.Content)
+	assert.Empty(t, choice.Message.Content)
+	encoded, err := json.Marshal(choice.Message)
+	require.NoError(t, err)
+	assert.Contains(t, string(encoded), `"content":[]`)
+}
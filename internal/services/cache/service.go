@@ -1,6 +1,7 @@
 package cache
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	goerrors "errors"
@@ -29,6 +30,9 @@ type CacheStore interface {
 	Delete(ctx context.Context, key string) error
 	Clear(ctx context.Context) error
 	Stats(ctx context.Context) (*CacheStats, error)
+	// CompareAndSwap sets key to value only if its current value is old, or
+	// if it is absent when old is nil, reporting whether it did
+	CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error)
 }
 
 type CacheStats struct {
@@ -47,12 +51,21 @@ type CacheRequest struct {
 	TTL   time.Duration `json:"ttl,omitempty"`
 }
 
+// CompareAndSwapRequest sets a system entry only if it still holds Old;
+// without Old, only if it is absent
+type CompareAndSwapRequest struct {
+	Key   string          `json:"key" binding:"required"`
+	Old   json.RawMessage `json:"old,omitempty"`
+	Value json.RawMessage `json:"value" binding:"required"`
+}
+
 type CacheResponse struct {
 	Key      string      `json:"key"`
 	Value    interface{} `json:"value,omitempty"`
 	Found    bool        `json:"found"`
 	Cached   bool        `json:"cached,omitempty"`
 	TTL      time.Duration `json:"ttl,omitempty"`
+	Swapped  bool        `json:"swapped,omitempty"`
 }
 
 type HealthResponse struct {
@@ -115,6 +128,14 @@ func (s *Service) setupRouter() {
 		api.DELETE("", s.handleClear)
 		api.GET("/stats", s.handleStats)
 	}
+
+	// Internal state of the platform's own services, kept apart from tenants'
+	// entries and never expired
+	system := s.router.Group("/internal/v1/system-cache")
+	{
+		system.GET("/:key", s.handleSystemGet)
+		system.POST("/swap", s.handleSystemSwap)
+	}
 }
 
 func (s *Service) Handler() http.Handler {
@@ -311,6 +332,76 @@ func (s *Service) handleStats(c *gin.Context) {
 	c.JSON(http.StatusOK, stats)
 }
 
+// systemCacheKey places a key in the system key space, which no tenant's
+// scoped keys share
+func systemCacheKey(key string) string {
+	return "system:" + key
+}
+
+func (s *Service) handleSystemGet(c *gin.Context) {
+	key := c.Param("key")
+
+	value, found, err := s.store.Get(c.Request.Context(), systemCacheKey(key))
+	if err != nil {
+		s.respondWithError(c, errors.InternalError("cache get failed", err))
+		return
+	}
+
+	// The value is returned as stored, so it can be passed back as the old
+	// value of a swap
+	response := CacheResponse{
+		Key:   key,
+		Found: found,
+	}
+	if found && len(value) > 0 {
+		response.Value = json.RawMessage(value)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+func (s *Service) handleSystemSwap(c *gin.Context) {
+	var req CompareAndSwapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondWithError(c, errors.ValidationError("invalid request format", "body"))
+		return
+	}
+
+	value, err := compactJSON(req.Value)
+	if err != nil {
+		s.respondWithError(c, errors.ValidationError("invalid value format", "value"))
+		return
+	}
+	var old []byte
+	if len(req.Old) > 0 {
+		if old, err = compactJSON(req.Old); err != nil {
+			s.respondWithError(c, errors.ValidationError("invalid old value format", "old"))
+			return
+		}
+	}
+
+	swapped, err := s.store.CompareAndSwap(c.Request.Context(), systemCacheKey(req.Key), old, value, 0)
+	if err != nil {
+		s.respondWithError(c, errors.InternalError("cache swap failed", err))
+		return
+	}
+
+	s.logger.Debug("System cache swap",
+		logger.F("key", req.Key),
+		logger.F("swapped", swapped))
+
+	c.JSON(http.StatusOK, CacheResponse{Key: req.Key, Swapped: swapped})
+}
+
+// compactJSON strips insignificant whitespace so values compare as they are
+// stored
+func compactJSON(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
 func (s *Service) respondWithError(c *gin.Context, err error) {
 	var qlensErr *errors.QLensError
 	if !goerrors.As(err, &qlensErr) {
@@ -417,6 +508,34 @@ func (m *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl tim
 	return nil
 }
 
+func (m *MemoryStore) CompareAndSwap(ctx context.Context, key string, old, value []byte, ttl time.Duration) (bool, error) {
+	now := time.Now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.data[key]
+	if exists && !entry.expiresAt.IsZero() && entry.expiresAt.Before(now) {
+		exists = false
+	}
+	switch {
+	case old == nil && exists:
+		return false, nil
+	case old != nil && (!exists || !bytes.Equal(entry.value, old)):
+		return false, nil
+	}
+
+	swapped := &cacheEntry{
+		value:     append([]byte(nil), value...),
+		createdAt: now,
+	}
+	if ttl > 0 {
+		swapped.expiresAt = now.Add(ttl)
+	}
+	m.data[key] = swapped
+	return true, nil
+}
+
 func (m *MemoryStore) Delete(ctx context.Context, key string) error {
 	m.mu.Lock()
 	delete(m.data, key)
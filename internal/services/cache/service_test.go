@@ -2,6 +2,7 @@ package cache
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -9,418 +10,353 @@ import (
 	"testing"
 	"time"
 
-	"github.com/quantum-suite/platform/internal/domain"
 	"github.com/quantum-suite/platform/pkg/shared/env"
 	"github.com/quantum-suite/platform/pkg/shared/logger"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestNewCacheService(t *testing.T) {
+func newTestService(t *testing.T) *Service {
+	t.Helper()
+
 	config := &env.Config{
-		Environment: env.EnvironmentDevelopment,
+		Environment: env.Development,
 		ServiceName: "test-cache",
-		Port:        "8082",
+		Port:        8082,
 		Logging: env.LoggingConfig{
-			Level:      "debug",
+			Level:      "error",
 			Format:     "json",
 			Structured: true,
 		},
 		Cache: env.CacheConfig{
 			Type:    "memory",
-			TTL:     300,
+			TTL:     5 * time.Minute,
 			MaxSize: 1000,
 		},
 	}
 
-	log := logger.NewNoop()
-	service, err := NewService(config, log)
+	service, err := NewService(config, logger.NewNoop())
 	require.NoError(t, err)
-	assert.NotNil(t, service)
-	
-	err = service.Close()
-	assert.NoError(t, err)
+	t.Cleanup(func() { service.Close() })
+	return service
 }
 
-func TestCacheServiceHealthCheck(t *testing.T) {
-	config := &env.Config{
-		Environment: env.EnvironmentDevelopment,
-		ServiceName: "test-cache",
-		Port:        "8082",
-		Logging: env.LoggingConfig{
-			Level:      "error",
-			Format:     "json",
-			Structured: true,
-		},
-		Cache: env.CacheConfig{
-			Type:    "memory",
-			TTL:     300,
-			MaxSize: 1000,
-		},
+// doRequest sends a request to the service as the given tenant, or as no
+// tenant when tenantID is empty, and decodes the JSON response into out
+func doRequest(t *testing.T, service *Service, method, path, tenantID string, body interface{}, out interface{}) int {
+	t.Helper()
+
+	var reader *bytes.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(data)
+	} else {
+		reader = bytes.NewReader(nil)
 	}
 
-	log := logger.NewNoop()
-	service, err := NewService(config, log)
-	require.NoError(t, err)
-	defer service.Close()
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if tenantID != "" {
+		req.Header.Set("X-Tenant-ID", tenantID)
+	}
 
-	server := httptest.NewServer(service.Handler())
-	defer server.Close()
+	w := httptest.NewRecorder()
+	service.Handler().ServeHTTP(w, req)
 
-	resp, err := http.Get(server.URL + "/health")
-	require.NoError(t, err)
-	defer resp.Body.Close()
+	if out != nil {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), out), w.Body.String())
+	}
+	return w.Code
+}
 
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+func TestNewCacheService(t *testing.T) {
+	service := newTestService(t)
+	assert.NotNil(t, service)
+	assert.NoError(t, service.Close())
+}
+
+func TestCacheServiceHealthCheck(t *testing.T) {
+	service := newTestService(t)
 
 	var healthResp map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&healthResp)
-	require.NoError(t, err)
+	status := doRequest(t, service, http.MethodGet, "/health", "", nil, &healthResp)
 
-	assert.Contains(t, healthResp, "status")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "healthy", healthResp["status"])
 	assert.Contains(t, healthResp, "timestamp")
+	assert.Contains(t, healthResp, "stats")
 }
 
 func TestCacheServiceReadiness(t *testing.T) {
-	config := &env.Config{
-		Environment: env.EnvironmentDevelopment,
-		ServiceName: "test-cache",
-		Port:        "8082",
-		Logging: env.LoggingConfig{
-			Level:      "error",
-			Format:     "json",
-			Structured: true,
-		},
-		Cache: env.CacheConfig{
-			Type:    "memory",
-			TTL:     300,
-			MaxSize: 1000,
-		},
-	}
-
-	log := logger.NewNoop()
-	service, err := NewService(config, log)
-	require.NoError(t, err)
-	defer service.Close()
-
-	server := httptest.NewServer(service.Handler())
-	defer server.Close()
-
-	resp, err := http.Get(server.URL + "/health/ready")
-	require.NoError(t, err)
-	defer resp.Body.Close()
-
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	service := newTestService(t)
 
 	var readinessResp map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&readinessResp)
-	require.NoError(t, err)
+	status := doRequest(t, service, http.MethodGet, "/health/ready", "", nil, &readinessResp)
 
+	assert.Equal(t, http.StatusOK, status)
 	assert.Equal(t, "ready", readinessResp["status"])
 }
 
 func TestCacheServiceSetAndGet(t *testing.T) {
-	config := &env.Config{
-		Environment: env.EnvironmentDevelopment,
-		ServiceName: "test-cache",
-		Port:        "8082",
-		Logging: env.LoggingConfig{
-			Level:      "error",
-			Format:     "json",
-			Structured: true,
-		},
-		Cache: env.CacheConfig{
-			Type:    "memory",
-			TTL:     300,
-			MaxSize: 1000,
-		},
-	}
+	service := newTestService(t)
 
-	log := logger.NewNoop()
-	service, err := NewService(config, log)
-	require.NoError(t, err)
-	defer service.Close()
-
-	server := httptest.NewServer(service.Handler())
-	defer server.Close()
-
-	client := &http.Client{}
-
-	// Test SET operation
-	setReq := CacheSetRequest{
-		Key:       "test-key",
-		TenantID:  domain.TenantID("test-tenant"),
-		Value: CacheValue{
-			Response: domain.LLMResponse{
-				ID:      "test-response",
-				Object:  "chat.completion",
-				Created: time.Now().Unix(),
-				Model:   "gpt-4",
-				Choices: []domain.Choice{
-					{
-						Index: 0,
-						Message: domain.Message{
-							Role: domain.MessageRoleAssistant,
-							Content: []domain.ContentPart{
-								{Type: domain.ContentTypeText, Text: "Hello"},
-							},
-						},
-					},
-				},
-				Usage: domain.Usage{
-					PromptTokens:     10,
-					CompletionTokens: 5,
-					TotalTokens:      15,
-				},
-			},
-		},
-		TTL: 300,
+	setReq := CacheRequest{
+		Key:   "test-key",
+		Value: map[string]interface{}{"id": "test-response", "model": "gpt-4"},
+		TTL:   5 * time.Minute,
 	}
+	var setResp CacheResponse
+	status := doRequest(t, service, http.MethodPost, "/internal/v1/cache", "test-tenant", setReq, &setResp)
+	require.Equal(t, http.StatusOK, status)
+	assert.True(t, setResp.Cached)
+	assert.Equal(t, "test-key", setResp.Key)
+
+	var getResp CacheResponse
+	status = doRequest(t, service, http.MethodGet, "/internal/v1/cache/test-key", "test-tenant", nil, &getResp)
+	require.Equal(t, http.StatusOK, status)
+	assert.True(t, getResp.Found)
+	value, ok := getResp.Value.(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "test-response", value["id"])
+
+	// Another tenant cannot see the entry
+	var otherResp CacheResponse
+	status = doRequest(t, service, http.MethodGet, "/internal/v1/cache/test-key", "other-tenant", nil, &otherResp)
+	require.Equal(t, http.StatusOK, status)
+	assert.False(t, otherResp.Found)
+}
 
-	setReqJSON, err := json.Marshal(setReq)
-	require.NoError(t, err)
-
-	req, err := http.NewRequest("POST", server.URL+"/internal/v1/cache", bytes.NewBuffer(setReqJSON))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
+func TestCacheServiceDelete(t *testing.T) {
+	service := newTestService(t)
 
-	resp, err := client.Do(req)
-	require.NoError(t, err)
-	defer resp.Body.Close()
+	setReq := CacheRequest{Key: "test-delete-key", Value: "cached"}
+	status := doRequest(t, service, http.MethodPost, "/internal/v1/cache", "test-tenant", setReq, nil)
+	require.Equal(t, http.StatusOK, status)
 
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var deleteResp map[string]interface{}
+	status = doRequest(t, service, http.MethodDelete, "/internal/v1/cache/test-delete-key", "test-tenant", nil, &deleteResp)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, true, deleteResp["deleted"])
 
-	var setResp CacheSetResponse
-	err = json.NewDecoder(resp.Body).Decode(&setResp)
-	require.NoError(t, err)
-	assert.True(t, setResp.Success)
+	var getResp CacheResponse
+	doRequest(t, service, http.MethodGet, "/internal/v1/cache/test-delete-key", "test-tenant", nil, &getResp)
+	assert.False(t, getResp.Found)
+}
 
-	// Test GET operation
-	getURL := server.URL + "/internal/v1/cache/test-key?tenant_id=test-tenant"
-	resp, err = client.Get(getURL)
-	require.NoError(t, err)
-	defer resp.Body.Close()
+func TestCacheServiceClear(t *testing.T) {
+	service := newTestService(t)
 
-	if resp.StatusCode == http.StatusOK {
-		var getResp CacheGetResponse
-		err = json.NewDecoder(resp.Body).Decode(&getResp)
-		require.NoError(t, err)
-		assert.True(t, getResp.Found)
-		assert.Equal(t, "test-response", getResp.Value.Response.ID)
+	for i := 0; i < 3; i++ {
+		setReq := CacheRequest{Key: fmt.Sprintf("test-key-%d", i), Value: i}
+		status := doRequest(t, service, http.MethodPost, "/internal/v1/cache", "test-tenant-clear", setReq, nil)
+		require.Equal(t, http.StatusOK, status)
 	}
+
+	var clearResp map[string]interface{}
+	status := doRequest(t, service, http.MethodDelete, "/internal/v1/cache", "", nil, &clearResp)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, true, clearResp["cleared"])
+
+	var stats CacheStats
+	doRequest(t, service, http.MethodGet, "/internal/v1/cache/stats", "", nil, &stats)
+	assert.Equal(t, int64(0), stats.Keys)
 }
 
-func TestCacheServiceDelete(t *testing.T) {
-	config := &env.Config{
-		Environment: env.EnvironmentDevelopment,
-		ServiceName: "test-cache",
-		Port:        "8082",
-		Logging: env.LoggingConfig{
-			Level:      "error",
-			Format:     "json",
-			Structured: true,
-		},
-		Cache: env.CacheConfig{
-			Type:    "memory",
-			TTL:     300,
-			MaxSize: 1000,
-		},
-	}
+func TestCacheServiceStats(t *testing.T) {
+	service := newTestService(t)
 
-	log := logger.NewNoop()
-	service, err := NewService(config, log)
-	require.NoError(t, err)
-	defer service.Close()
+	doRequest(t, service, http.MethodPost, "/internal/v1/cache", "test-tenant", CacheRequest{Key: "k", Value: "v"}, nil)
+	doRequest(t, service, http.MethodGet, "/internal/v1/cache/k", "test-tenant", nil, nil)
+	doRequest(t, service, http.MethodGet, "/internal/v1/cache/missing", "test-tenant", nil, nil)
 
-	server := httptest.NewServer(service.Handler())
-	defer server.Close()
+	var stats CacheStats
+	status := doRequest(t, service, http.MethodGet, "/internal/v1/cache/stats", "", nil, &stats)
+	require.Equal(t, http.StatusOK, status)
 
-	client := &http.Client{}
+	assert.Equal(t, int64(1), stats.Keys)
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.Misses)
+	assert.InDelta(t, 0.5, stats.HitRate, 1e-9)
+}
 
-	// First set a value
-	setReq := CacheSetRequest{
-		Key:      "test-delete-key",
-		TenantID: domain.TenantID("test-tenant"),
-		Value: CacheValue{
-			Response: domain.LLMResponse{
-				ID: "test-response",
-			},
-		},
-		TTL: 300,
-	}
+func TestCacheServiceMissingTenantID(t *testing.T) {
+	service := newTestService(t)
 
-	setReqJSON, err := json.Marshal(setReq)
-	require.NoError(t, err)
+	status := doRequest(t, service, http.MethodGet, "/internal/v1/cache/test-key", "", nil, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
 
-	req, err := http.NewRequest("POST", server.URL+"/internal/v1/cache", bytes.NewBuffer(setReqJSON))
-	require.NoError(t, err)
-	req.Header.Set("Content-Type", "application/json")
+	status = doRequest(t, service, http.MethodPost, "/internal/v1/cache", "", CacheRequest{Key: "k", Value: "v"}, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
 
-	resp, err := client.Do(req)
-	require.NoError(t, err)
-	resp.Body.Close()
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	status = doRequest(t, service, http.MethodDelete, "/internal/v1/cache/test-key", "", nil, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
 
-	// Now delete it
-	deleteURL := server.URL + "/internal/v1/cache/test-delete-key?tenant_id=test-tenant"
-	req, err = http.NewRequest("DELETE", deleteURL, nil)
-	require.NoError(t, err)
+func TestMemoryStoreCompareAndSwap(t *testing.T) {
+	ctx := context.Background()
 
-	resp, err = client.Do(req)
-	require.NoError(t, err)
-	defer resp.Body.Close()
+	t.Run("sets an absent key when old is nil", func(t *testing.T) {
+		store := NewMemoryStore(logger.NewNoop())
 
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+		swapped, err := store.CompareAndSwap(ctx, "k", nil, []byte("v1"), 0)
+		require.NoError(t, err)
+		assert.True(t, swapped)
 
-	var deleteResp CacheDeleteResponse
-	err = json.NewDecoder(resp.Body).Decode(&deleteResp)
-	require.NoError(t, err)
-	assert.True(t, deleteResp.Success)
-}
+		value, found, err := store.Get(ctx, "k")
+		require.NoError(t, err)
+		assert.True(t, found)
+		assert.Equal(t, []byte("v1"), value)
+	})
 
-func TestCacheServiceClearTenant(t *testing.T) {
-	config := &env.Config{
-		Environment: env.EnvironmentDevelopment,
-		ServiceName: "test-cache",
-		Port:        "8082",
-		Logging: env.LoggingConfig{
-			Level:      "error",
-			Format:     "json",
-			Structured: true,
-		},
-		Cache: env.CacheConfig{
-			Type:    "memory",
-			TTL:     300,
-			MaxSize: 1000,
-		},
-	}
+	t.Run("refuses to create a key that exists", func(t *testing.T) {
+		store := NewMemoryStore(logger.NewNoop())
+		require.NoError(t, store.Set(ctx, "k", []byte("v1"), 0))
 
-	log := logger.NewNoop()
-	service, err := NewService(config, log)
-	require.NoError(t, err)
-	defer service.Close()
+		swapped, err := store.CompareAndSwap(ctx, "k", nil, []byte("v2"), 0)
+		require.NoError(t, err)
+		assert.False(t, swapped)
 
-	server := httptest.NewServer(service.Handler())
-	defer server.Close()
+		value, _, _ := store.Get(ctx, "k")
+		assert.Equal(t, []byte("v1"), value)
+	})
 
-	client := &http.Client{}
+	t.Run("swaps when the current value matches", func(t *testing.T) {
+		store := NewMemoryStore(logger.NewNoop())
+		require.NoError(t, store.Set(ctx, "k", []byte("v1"), 0))
 
-	// Set multiple values for the tenant
-	tenantID := domain.TenantID("test-tenant-clear")
-	
-	for i := 0; i < 3; i++ {
-		setReq := CacheSetRequest{
-			Key:      fmt.Sprintf("test-key-%d", i),
-			TenantID: tenantID,
-			Value: CacheValue{
-				Response: domain.LLMResponse{
-					ID: fmt.Sprintf("test-response-%d", i),
-				},
-			},
-			TTL: 300,
-		}
+		swapped, err := store.CompareAndSwap(ctx, "k", []byte("v1"), []byte("v2"), 0)
+		require.NoError(t, err)
+		assert.True(t, swapped)
+
+		value, _, _ := store.Get(ctx, "k")
+		assert.Equal(t, []byte("v2"), value)
+	})
 
-		setReqJSON, err := json.Marshal(setReq)
+	t.Run("refuses when the current value differs", func(t *testing.T) {
+		store := NewMemoryStore(logger.NewNoop())
+		require.NoError(t, store.Set(ctx, "k", []byte("v2"), 0))
+
+		swapped, err := store.CompareAndSwap(ctx, "k", []byte("v1"), []byte("v3"), 0)
 		require.NoError(t, err)
+		assert.False(t, swapped)
+
+		value, _, _ := store.Get(ctx, "k")
+		assert.Equal(t, []byte("v2"), value)
+	})
+
+	t.Run("refuses when the key is missing", func(t *testing.T) {
+		store := NewMemoryStore(logger.NewNoop())
 
-		req, err := http.NewRequest("POST", server.URL+"/internal/v1/cache", bytes.NewBuffer(setReqJSON))
+		swapped, err := store.CompareAndSwap(ctx, "k", []byte("v1"), []byte("v2"), 0)
 		require.NoError(t, err)
-		req.Header.Set("Content-Type", "application/json")
+		assert.False(t, swapped)
+
+		_, found, _ := store.Get(ctx, "k")
+		assert.False(t, found)
+	})
+
+	t.Run("treats an expired key as absent", func(t *testing.T) {
+		store := NewMemoryStore(logger.NewNoop())
+		require.NoError(t, store.Set(ctx, "k", []byte("v1"), time.Millisecond))
+		time.Sleep(5 * time.Millisecond)
 
-		resp, err := client.Do(req)
+		swapped, err := store.CompareAndSwap(ctx, "k", []byte("v1"), []byte("v2"), 0)
 		require.NoError(t, err)
-		resp.Body.Close()
-		assert.Equal(t, http.StatusOK, resp.StatusCode)
-	}
+		assert.False(t, swapped, "an expired value must not match")
 
-	// Clear tenant cache
-	clearURL := fmt.Sprintf("%s/internal/v1/cache/tenant/%s", server.URL, tenantID)
-	req, err := http.NewRequest("DELETE", clearURL, nil)
-	require.NoError(t, err)
+		swapped, err = store.CompareAndSwap(ctx, "k", nil, []byte("v2"), 0)
+		require.NoError(t, err)
+		assert.True(t, swapped, "an expired key can be created again")
 
-	resp, err := client.Do(req)
-	require.NoError(t, err)
-	defer resp.Body.Close()
+		value, found, _ := store.Get(ctx, "k")
+		assert.True(t, found)
+		assert.Equal(t, []byte("v2"), value)
+	})
 
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	t.Run("applies the ttl to the swapped value", func(t *testing.T) {
+		store := NewMemoryStore(logger.NewNoop())
 
-	var clearResp CacheClearResponse
-	err = json.NewDecoder(resp.Body).Decode(&clearResp)
-	require.NoError(t, err)
-	assert.True(t, clearResp.Success)
-	assert.Greater(t, clearResp.ClearedCount, 0)
+		swapped, err := store.CompareAndSwap(ctx, "k", nil, []byte("v1"), time.Millisecond)
+		require.NoError(t, err)
+		require.True(t, swapped)
+		time.Sleep(5 * time.Millisecond)
+
+		_, found, _ := store.Get(ctx, "k")
+		assert.False(t, found)
+	})
 }
 
-func TestCacheServiceStats(t *testing.T) {
-	config := &env.Config{
-		Environment: env.EnvironmentDevelopment,
-		ServiceName: "test-cache",
-		Port:        "8082",
-		Logging: env.LoggingConfig{
-			Level:      "error",
-			Format:     "json",
-			Structured: true,
-		},
-		Cache: env.CacheConfig{
-			Type:    "memory",
-			TTL:     300,
-			MaxSize: 1000,
-		},
+func TestCacheServiceSystemSwap(t *testing.T) {
+	service := newTestService(t)
+	swap := func(old, value string) (int, CacheResponse) {
+		req := map[string]interface{}{"key": "leader", "value": json.RawMessage(value)}
+		if old != "" {
+			req["old"] = json.RawMessage(old)
+		}
+		var resp CacheResponse
+		status := doRequest(t, service, http.MethodPost, "/internal/v1/system-cache/swap", "", req, &resp)
+		return status, resp
 	}
 
-	log := logger.NewNoop()
-	service, err := NewService(config, log)
-	require.NoError(t, err)
-	defer service.Close()
+	// Created when absent, then refused once it exists
+	status, resp := swap("", `{"holder":"a"}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.True(t, resp.Swapped)
 
-	server := httptest.NewServer(service.Handler())
-	defer server.Close()
+	_, resp = swap("", `{"holder":"b"}`)
+	assert.False(t, resp.Swapped)
 
-	resp, err := http.Get(server.URL + "/internal/v1/cache/stats")
-	require.NoError(t, err)
-	defer resp.Body.Close()
+	// A stale old value conflicts
+	_, resp = swap(`{"holder":"b"}`, `{"holder":"c"}`)
+	assert.False(t, resp.Swapped)
 
-	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	// The value read back can be passed as old, whatever its whitespace
+	var getResp struct {
+		Found bool            `json:"found"`
+		Value json.RawMessage `json:"value"`
+	}
+	status = doRequest(t, service, http.MethodGet, "/internal/v1/system-cache/leader", "", nil, &getResp)
+	require.Equal(t, http.StatusOK, status)
+	require.True(t, getResp.Found)
+	assert.JSONEq(t, `{"holder":"a"}`, string(getResp.Value))
+
+	_, resp = swap(`{ "holder": "a" }`, `{"holder":"c"}`)
+	assert.True(t, resp.Swapped)
+
+	// System entries are not visible to tenants
+	var tenantResp CacheResponse
+	doRequest(t, service, http.MethodGet, "/internal/v1/cache/leader", "system", nil, &tenantResp)
+	assert.False(t, tenantResp.Found)
+}
 
-	var statsResp CacheStatsResponse
-	err = json.NewDecoder(resp.Body).Decode(&statsResp)
-	require.NoError(t, err)
+func TestCacheServiceSystemSwapMissingKey(t *testing.T) {
+	service := newTestService(t)
 
-	// Stats should be present
-	assert.GreaterOrEqual(t, statsResp.TotalEntries, 0)
-	assert.GreaterOrEqual(t, statsResp.HitCount, int64(0))
-	assert.GreaterOrEqual(t, statsResp.MissCount, int64(0))
-}
+	var getResp CacheResponse
+	status := doRequest(t, service, http.MethodGet, "/internal/v1/system-cache/absent", "", nil, &getResp)
+	require.Equal(t, http.StatusOK, status)
+	assert.False(t, getResp.Found)
 
-func TestCacheServiceInvalidTenantID(t *testing.T) {
-	config := &env.Config{
-		Environment: env.EnvironmentDevelopment,
-		ServiceName: "test-cache",
-		Port:        "8082",
-		Logging: env.LoggingConfig{
-			Level:      "error",
-			Format:     "json",
-			Structured: true,
-		},
-		Cache: env.CacheConfig{
-			Type:    "memory",
-			TTL:     300,
-			MaxSize: 1000,
-		},
-	}
+	// Swapping from a value the key never held fails without creating it
+	var resp CacheResponse
+	req := map[string]interface{}{"key": "absent", "old": json.RawMessage(`1`), "value": json.RawMessage(`2`)}
+	status = doRequest(t, service, http.MethodPost, "/internal/v1/system-cache/swap", "", req, &resp)
+	require.Equal(t, http.StatusOK, status)
+	assert.False(t, resp.Swapped)
 
-	log := logger.NewNoop()
-	service, err := NewService(config, log)
-	require.NoError(t, err)
-	defer service.Close()
+	doRequest(t, service, http.MethodGet, "/internal/v1/system-cache/absent", "", nil, &getResp)
+	assert.False(t, getResp.Found)
+}
 
-	server := httptest.NewServer(service.Handler())
-	defer server.Close()
+func TestCacheServiceSystemSwapInvalidRequest(t *testing.T) {
+	service := newTestService(t)
 
-	// Test GET without tenant_id
-	resp, err := http.Get(server.URL + "/internal/v1/cache/test-key")
-	require.NoError(t, err)
-	defer resp.Body.Close()
+	// Missing value
+	status := doRequest(t, service, http.MethodPost, "/internal/v1/system-cache/swap", "", map[string]interface{}{"key": "k"}, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
 
-	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
-}
\ No newline at end of file
+	// Missing key
+	status = doRequest(t, service, http.MethodPost, "/internal/v1/system-cache/swap", "", map[string]interface{}{"value": 1}, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
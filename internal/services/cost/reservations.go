@@ -0,0 +1,66 @@
+package cost
+
+import (
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// budgetReservationTTL is how long a reservation holds budget when the
+// request it was made for is never tracked or released, for instance
+// because the router lost track of it
+const budgetReservationTTL = 10 * time.Minute
+
+// BudgetReservation is the estimated cost of an in-flight request, held
+// against the global and tenant budgets from the budget check until the
+// request's actual cost is tracked
+type BudgetReservation struct {
+	id       uint64
+	tenantID domain.TenantID
+	amount   float64
+	expires  time.Time
+}
+
+// ReleaseReservation gives back the budget held for a request that failed.
+// Releasing a nil or already settled reservation does nothing.
+func (s *CostService) ReleaseReservation(reservation *BudgetReservation) {
+	if reservation == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.reservations, reservation.id)
+}
+
+// reserve holds an amount of budget for a tenant's request. Callers hold
+// s.mu.
+func (s *CostService) reserve(tenantID domain.TenantID, amount float64, now time.Time) *BudgetReservation {
+	if s.reservations == nil {
+		s.reservations = make(map[uint64]*BudgetReservation)
+	}
+	s.nextReservation++
+	reservation := &BudgetReservation{
+		id:       s.nextReservation,
+		tenantID: tenantID,
+		amount:   amount,
+		expires:  now.Add(budgetReservationTTL),
+	}
+	s.reservations[reservation.id] = reservation
+	return reservation
+}
+
+// reservedCost drops expired reservations and returns the budget the
+// remaining ones hold globally and for a tenant. Callers hold s.mu.
+func (s *CostService) reservedCost(tenantID domain.TenantID, now time.Time) (global, tenant float64) {
+	for id, reservation := range s.reservations {
+		if now.After(reservation.expires) {
+			delete(s.reservations, id)
+			continue
+		}
+		global += reservation.amount
+		if reservation.tenantID == tenantID {
+			tenant += reservation.amount
+		}
+	}
+	return global, tenant
+}
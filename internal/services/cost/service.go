@@ -29,6 +29,11 @@ type CostService struct {
 	requestCount    int64
 	totalCostToday  float64
 	lastReset       time.Time
+	
+	// Estimated costs of requests that passed the budget check and have
+	// not been tracked yet
+	reservations    map[uint64]*BudgetReservation
+	nextReservation uint64
 }
 
 // TenantCostTracker tracks costs per tenant
@@ -120,14 +125,20 @@ func NewCostService(logger logger.Logger, config *BudgetConfiguration) *CostServ
 		budgetLimits:    config,
 		alertThresholds: getDefaultAlertThresholds(),
 		lastReset:       time.Now().Truncate(24 * time.Hour),
+		reservations:    make(map[uint64]*BudgetReservation),
 	}
 }
 
-// TrackRequest records cost and usage for a request
+// TrackRequest records cost and usage for a request. The request's budget
+// reservation, if any, is settled: its actual cost replaces the estimate.
 func (s *CostService) TrackRequest(ctx context.Context, req *CostTrackingRequest) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	if req.Reservation != nil {
+		delete(s.reservations, req.Reservation.id)
+	}
+
 	now := time.Now()
 	
 	// Check if we need to reset daily counters
@@ -178,6 +189,9 @@ type CostTrackingRequest struct {
 	Success       bool              `json:"success"`
 	RequestID     string            `json:"request_id"`
 	Timestamp     time.Time         `json:"timestamp"`
+
+	// Reservation is the budget CheckBudgetCompliance held for the request
+	Reservation   *BudgetReservation `json:"-"`
 }
 
 // GetTenantUsage returns usage statistics for a tenant
@@ -218,24 +232,35 @@ type GlobalUsageStats struct {
 	LastUpdated       time.Time `json:"last_updated"`
 }
 
-// CheckBudgetCompliance checks if a request would exceed budget limits
-func (s *CostService) CheckBudgetCompliance(tenantID domain.TenantID, estimatedCost float64) error {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// CheckBudgetCompliance checks if a request would exceed budget limits and,
+// if not, reserves its estimated cost. Requests in flight count against the
+// budgets through their reservations, so concurrent requests cannot all pass
+// on the same remaining budget. The reservation is settled by TrackRequest,
+// given back by ReleaseReservation when the request fails, and otherwise
+// expires.
+func (s *CostService) CheckBudgetCompliance(tenantID domain.TenantID, estimatedCost float64) (*BudgetReservation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	globalReserved, tenantReserved := s.reservedCost(tenantID, now)
 
 	// Check global budget
-	if s.totalCostToday+estimatedCost > s.budgetLimits.GlobalDailyLimit {
-		return errors.NewError(errors.ErrorTypeQuotaExceeded, "global daily budget limit exceeded").Build()
+	if s.totalCostToday+globalReserved+estimatedCost > s.budgetLimits.GlobalDailyLimit {
+		return nil, errors.NewError(errors.ErrorTypeQuotaExceeded, "global daily budget limit exceeded").Build()
 	}
 
-	// Check tenant budget
+	// Check tenant budget; a tenant with no tracked usage yet may already
+	// have requests in flight
+	tenantCost, tenantLimit := 0.0, s.budgetLimits.TenantDailyLimit
 	if tracker, exists := s.tenantUsage[tenantID]; exists {
-		if tracker.DailyCost+estimatedCost > tracker.BudgetLimit {
-			return errors.NewError(errors.ErrorTypeQuotaExceeded, fmt.Sprintf("tenant daily budget limit exceeded: $%.4f", tracker.BudgetLimit)).Build()
-		}
+		tenantCost, tenantLimit = tracker.DailyCost, tracker.BudgetLimit
+	}
+	if tenantCost+tenantReserved+estimatedCost > tenantLimit {
+		return nil, errors.NewError(errors.ErrorTypeQuotaExceeded, fmt.Sprintf("tenant daily budget limit exceeded: $%.4f", tenantLimit)).Build()
 	}
 
-	return nil
+	return s.reserve(tenantID, estimatedCost, now), nil
 }
 
 // Helper methods
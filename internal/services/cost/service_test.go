@@ -0,0 +1,88 @@
+package cost
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func newTestCostService() *CostService {
+	return NewCostService(logger.NewNoop(), &BudgetConfiguration{
+		GlobalDailyLimit: 100,
+		TenantDailyLimit: 10,
+	})
+}
+
+func TestCheckBudgetCompliance_ConcurrentRequestsCannotOverspend(t *testing.T) {
+	s := newTestCostService()
+
+	var allowed atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.CheckBudgetCompliance("tenant-a", 1); err == nil {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(10), allowed.Load())
+}
+
+func TestCheckBudgetCompliance_TrackingSettlesReservation(t *testing.T) {
+	s := newTestCostService()
+
+	reservation, err := s.CheckBudgetCompliance("tenant-a", 8)
+	require.NoError(t, err)
+	_, err = s.CheckBudgetCompliance("tenant-a", 3)
+	require.Error(t, err, "the reservation holds the tenant's budget")
+
+	// The actual cost replaces the estimate
+	require.NoError(t, s.TrackRequest(context.Background(), &CostTrackingRequest{
+		TenantID: "tenant-a", ModelID: "gpt-4", Cost: 2, Timestamp: time.Now(), Reservation: reservation,
+	}))
+	_, err = s.CheckBudgetCompliance("tenant-a", 8)
+	require.NoError(t, err)
+	_, err = s.CheckBudgetCompliance("tenant-a", 1)
+	require.Error(t, err)
+}
+
+func TestCheckBudgetCompliance_FailedRequestsGiveBudgetBack(t *testing.T) {
+	s := newTestCostService()
+
+	reservation, err := s.CheckBudgetCompliance("tenant-a", 10)
+	require.NoError(t, err)
+	s.ReleaseReservation(reservation)
+	s.ReleaseReservation(nil)
+
+	reservation, err = s.CheckBudgetCompliance("tenant-a", 10)
+	require.NoError(t, err)
+
+	// Reservations nobody settles expire
+	reservation.expires = time.Now().Add(-time.Second)
+	_, err = s.CheckBudgetCompliance("tenant-a", 10)
+	require.NoError(t, err)
+	assert.Len(t, s.reservations, 1)
+}
+
+func TestCheckBudgetCompliance_GlobalBudgetCountsEveryTenant(t *testing.T) {
+	s := newTestCostService()
+	s.budgetLimits.GlobalDailyLimit = 15
+
+	_, err := s.CheckBudgetCompliance("tenant-a", 10)
+	require.NoError(t, err)
+	_, err = s.CheckBudgetCompliance("tenant-b", 10)
+	require.Error(t, err)
+	_, err = s.CheckBudgetCompliance("tenant-b", 5)
+	require.NoError(t, err)
+}
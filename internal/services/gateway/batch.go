@@ -0,0 +1,609 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// batchRetryBackoff is the initial delay before retrying a throttled item;
+// it doubles on every further attempt
+var batchRetryBackoff = time.Second
+
+// batchCancelPollInterval is how often the replica running a batch checks
+// whether another replica cancelled it, and how often a cancelling replica
+// checks whether the batch wound down
+var batchCancelPollInterval = time.Second
+
+// batchPersistInterval is how often a running batch's changes are saved to
+// the cache. Saving after every item change would rewrite the whole job,
+// with every response collected so far, once per change.
+var batchPersistInterval = time.Second
+
+// batchState guards a batch job that is updated by its workers while it is
+// being polled. Changes are also saved to the cache, at most once every
+// batchPersistInterval, so any replica can answer polls and cancellations.
+type batchState struct {
+	mu  sync.Mutex
+	job BatchJob
+	// changed is set by updates not yet saved to the cache
+	changed bool
+
+	// saveMu orders saves so an older snapshot never overwrites a newer one
+	saveMu sync.Mutex
+
+	// cancel stops the batch's workers; done is closed once they have all
+	// returned and the job reached its final status
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// snapshot returns a copy of the job that is safe to serialize
+func (b *batchState) snapshot() BatchJob {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	job := b.job
+	job.Items = append([]BatchItem(nil), b.job.Items...)
+	return job
+}
+
+// takeChanged reports whether the job changed since it was last asked,
+// clearing the flag
+func (b *batchState) takeChanged() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	changed := b.changed
+	b.changed = false
+	return changed
+}
+
+// update applies a change to one item and refreshes the job counters
+func (b *batchState) update(index int, mutate func(item *BatchItem)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	mutate(&b.job.Items[index])
+	b.changed = true
+
+	b.job.Completed, b.job.Failed, b.job.Cancelled = 0, 0, 0
+	b.job.Usage = domain.Usage{}
+	for _, item := range b.job.Items {
+		switch item.Status {
+		case domain.RequestStatusCompleted:
+			b.job.Completed++
+			if item.Response != nil {
+				b.job.Usage.PromptTokens += item.Response.Usage.PromptTokens
+				b.job.Usage.CompletionTokens += item.Response.Usage.CompletionTokens
+				b.job.Usage.TotalTokens += item.Response.Usage.TotalTokens
+				b.job.Usage.CostUSD += item.Response.Usage.CostUSD
+			}
+		case domain.RequestStatusFailed:
+			b.job.Failed++
+		case domain.RequestStatusCancelled:
+			b.job.Cancelled++
+		}
+	}
+}
+
+// CreateBatch godoc
+// @Summary Submit a batch of chat completions
+// @Description Queue independent chat completion requests for asynchronous processing with bounded concurrency. Poll the returned job for per-item results.
+// @Tags batches
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Security TenantID
+// @Param request body BatchRequest true "Batch of chat completion requests (streaming is not supported)"
+// @Success 202 {object} BatchJob "Batch accepted"
+// @Failure 400 {object} ErrorResponse "Bad request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Router /v1/batches [post]
+func (s *Service) handleCreateBatch(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var batchReq BatchRequest
+	if err := c.ShouldBindJSON(&batchReq); err != nil {
+		s.respondWithError(c, errors.ValidationError("invalid request format", "body"))
+		return
+	}
+
+	if len(batchReq.Requests) == 0 {
+		s.respondWithError(c, errors.ValidationError("requests must not be empty", "requests"))
+		return
+	}
+	if max := s.currentConfig().Batch.MaxItems; max > 0 && len(batchReq.Requests) > max {
+		s.respondWithError(c, errors.ValidationError(fmt.Sprintf("a batch may contain at most %d requests", max), "requests"))
+		return
+	}
+
+	// Validate every item up front so a bad batch is rejected as a whole
+	reqs := make([]*domain.CompletionRequest, len(batchReq.Requests))
+	for i := range batchReq.Requests {
+		req, err := s.convertToDomainRequest(&batchReq.Requests[i])
+		if err != nil {
+			s.respondWithError(c, err)
+			return
+		}
+
+		s.enrichCompletionRequest(req, c)
+		req.RequestID = fmt.Sprintf("%s-%d", req.RequestID, i)
+
+		if req.Stream {
+			err = errors.ValidationError(fmt.Sprintf("requests[%d]: streaming is not supported in batches", i), "requests")
+		} else if err = s.validateCompletionRequest(req); err != nil {
+			err = errors.ValidationError(fmt.Sprintf("requests[%d]: %s", i, errors.FromError(err).Message), "requests")
+		} else if _, err = s.newCostCeiling(ctx, req); errors.IsType(err, errors.ErrorTypeValidation) {
+			err = errors.ValidationError(fmt.Sprintf("requests[%d]: %s", i, errors.FromError(err).Message), "requests")
+		}
+		if err != nil {
+			s.respondWithError(c, err)
+			return
+		}
+		reqs[i] = req
+	}
+
+	// The batch outlives the submitting request, until it is cancelled
+	batchCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	state := newBatchState(len(reqs))
+	state.cancel = cancel
+
+	tenantID := c.GetString("tenant_id")
+	if err := s.saveBatch(ctx, tenantID, state.snapshot()); err != nil {
+		cancel()
+		s.respondWithError(c, errors.InternalError("failed to store batch job", err))
+		return
+	}
+	s.trackBatch(tenantID, state)
+
+	go func() {
+		defer cancel()
+		s.runBatch(batchCtx, tenantID, state, reqs)
+	}()
+
+	s.logger.Info("Batch submitted",
+		logger.F("batch_id", state.job.ID),
+		logger.F("tenant_id", tenantID),
+		logger.F("items", len(reqs)))
+
+	c.JSON(http.StatusAccepted, state.snapshot())
+}
+
+// GetBatch godoc
+// @Summary Get batch status
+// @Description Return the status of a batch job and the result or error of every item
+// @Tags batches
+// @Produce json
+// @Security BearerAuth
+// @Security TenantID
+// @Param id path string true "Batch ID"
+// @Success 200 {object} BatchJob "Batch status"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Batch not found"
+// @Router /v1/batches/{id} [get]
+func (s *Service) handleGetBatch(c *gin.Context) {
+	id := c.Param("id")
+	tenantID := c.GetString("tenant_id")
+
+	// A batch running on this replica is reported as it is now, ahead of
+	// its next save to the cache
+	if state := s.runningBatch(tenantID, id); state != nil {
+		c.JSON(http.StatusOK, state.snapshot())
+		return
+	}
+
+	job, err := s.loadBatch(c.Request.Context(), tenantID, id)
+	if err != nil {
+		s.respondWithError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// CancelBatch godoc
+// @Summary Cancel a batch
+// @Description Stop dispatching a batch's remaining items and cancel those in flight. Returns the job with the results of the items that finished; cancelling a finished batch leaves it unchanged.
+// @Tags batches
+// @Produce json
+// @Security BearerAuth
+// @Security TenantID
+// @Param id path string true "Batch ID"
+// @Success 200 {object} BatchJob "Batch status after cancellation"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Batch not found"
+// @Router /v1/batches/{id} [delete]
+func (s *Service) handleCancelBatch(c *gin.Context) {
+	ctx := c.Request.Context()
+	id := c.Param("id")
+	tenantID := c.GetString("tenant_id")
+
+	job, err := s.loadBatch(ctx, tenantID, id)
+	if err != nil {
+		s.respondWithError(c, err)
+		return
+	}
+
+	// Cancelling again, or after the batch finished, is a no-op
+	if job.CompletedAt == nil {
+		// The batch may be running on another replica, which watches for
+		// this marker
+		if err := s.cacheClient.Set(ctx, batchCancelKey(tenantID, id), true, s.currentConfig().Batch.Retention); err != nil {
+			s.respondWithError(c, errors.InternalError("failed to cancel batch job", err))
+			return
+		}
+
+		// Wait for in-flight items to wind down so the response shows the
+		// final state of every item
+		job, err = s.awaitBatch(ctx, tenantID, id)
+		if err != nil {
+			s.respondWithError(c, err)
+			return
+		}
+	}
+
+	s.logger.Info("Batch cancelled",
+		logger.F("batch_id", job.ID),
+		logger.F("status", job.Status),
+		logger.F("completed", job.Completed),
+		logger.F("cancelled", job.Cancelled))
+
+	c.JSON(http.StatusOK, job)
+}
+
+// runBatch processes every item with bounded concurrency. Throttled items
+// are retried with backoff; once the tenant's budget is exhausted the
+// remaining items fail without being sent upstream. Once ctx is cancelled
+// no further items are dispatched and those in flight are abandoned.
+func (s *Service) runBatch(ctx context.Context, tenantID string, state *batchState, reqs []*domain.CompletionRequest) {
+	defer close(state.done)
+	defer s.forgetBatch(tenantID, state)
+
+	// Metrics and the job's state are recorded even after cancellation
+	recordCtx := context.WithoutCancel(ctx)
+	update := state.update
+
+	go s.watchBatchCancel(ctx, tenantID, state, batchCancelPollInterval)
+
+	stopPersisting := make(chan struct{})
+	persisted := make(chan struct{})
+	go func() {
+		defer close(persisted)
+		s.persistBatchChanges(recordCtx, tenantID, state, batchPersistInterval, stopPersisting)
+	}()
+
+	state.mu.Lock()
+	state.job.Status = domain.RequestStatusProcessing
+	state.mu.Unlock()
+	s.persistBatch(recordCtx, tenantID, state)
+
+	concurrency := s.currentConfig().Batch.MaxConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	slots := make(chan struct{}, concurrency)
+
+	var (
+		wg        sync.WaitGroup
+		budgetErr atomic.Pointer[errors.QLensError]
+	)
+	for i, req := range reqs {
+		slots <- struct{}{}
+
+		if ctx.Err() != nil {
+			update(i, func(item *BatchItem) { item.Status = domain.RequestStatusCancelled })
+			<-slots
+			continue
+		}
+		if exhausted := budgetErr.Load(); exhausted != nil {
+			update(i, func(item *BatchItem) {
+				item.Status = domain.RequestStatusFailed
+				item.Error = exhausted
+			})
+			<-slots
+			continue
+		}
+
+		// The submission itself was counted as the first item
+		if i > 0 {
+			if err := s.takeBatchRateLimit(ctx, tenantID); err != nil {
+				update(i, func(item *BatchItem) { item.Status = domain.RequestStatusCancelled })
+				<-slots
+				continue
+			}
+		}
+
+		wg.Add(1)
+		go func(i int, req *domain.CompletionRequest) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			update(i, func(item *BatchItem) { item.Status = domain.RequestStatusProcessing })
+
+			start := time.Now()
+			response, err := s.completeBatchItem(ctx, req)
+			if err != nil && ctx.Err() != nil {
+				s.metricsClient.RecordRequest(recordCtx, "POST", "/v1/batches", "cancelled", time.Since(start))
+				update(i, func(item *BatchItem) { item.Status = domain.RequestStatusCancelled })
+				return
+			}
+			if err != nil {
+				public := errors.FromError(err).PublicError()
+				if errors.IsType(err, errors.ErrorTypeBudgetExceeded) {
+					budgetErr.CompareAndSwap(nil, public)
+				}
+				s.metricsClient.RecordRequest(recordCtx, "POST", "/v1/batches", "error", time.Since(start))
+				update(i, func(item *BatchItem) {
+					item.Status = domain.RequestStatusFailed
+					item.Error = public
+				})
+				return
+			}
+
+			s.metricsClient.RecordRequest(recordCtx, "POST", "/v1/batches", "success", time.Since(start))
+			// Batch items only count against the tenant's own buckets
+			s.chargeRateLimitTokens(tenantID, "", response.Usage.TotalTokens)
+			update(i, func(item *BatchItem) {
+				item.Status = domain.RequestStatusCompleted
+				item.Response = response
+			})
+		}(i, req)
+	}
+	wg.Wait()
+	close(stopPersisting)
+	<-persisted
+
+	state.mu.Lock()
+	now := time.Now()
+	state.job.CompletedAt = &now
+	switch {
+	case state.job.Cancelled > 0:
+		state.job.Status = domain.RequestStatusCancelled
+		state.job.CancelledAt = &now
+	case state.job.Failed == state.job.Total:
+		state.job.Status = domain.RequestStatusFailed
+	default:
+		state.job.Status = domain.RequestStatusCompleted
+	}
+	state.mu.Unlock()
+
+	// Always save the final state, which also restarts the retention
+	// window from completion
+	s.persistBatch(recordCtx, tenantID, state)
+
+	job := state.snapshot()
+	s.logger.Info("Batch finished",
+		logger.F("batch_id", job.ID),
+		logger.F("tenant_id", tenantID),
+		logger.F("status", job.Status),
+		logger.F("completed", job.Completed),
+		logger.F("failed", job.Failed),
+		logger.F("cancelled", job.Cancelled))
+}
+
+// completeBatchItem routes one item, retrying while upstream is throttling
+func (s *Service) completeBatchItem(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+	backoff := batchRetryBackoff
+	for attempt := 0; ; attempt++ {
+		response, err := s.routerClient.RouteCompletion(ctx, req)
+		if err == nil || attempt >= s.currentConfig().Batch.MaxRetries || !isBatchThrottled(err) {
+			return response, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+		backoff *= 2
+	}
+}
+
+// takeBatchRateLimit counts a batch item against the tenant's rate limits
+// like any other request, waiting for the window to reset while the tenant
+// is over either limit
+func (s *Service) takeBatchRateLimit(ctx context.Context, tenantID string) error {
+	for {
+		config := s.currentConfig()
+		if config.RateLimitRequestsPerMinute <= 0 && config.RateLimitTokensPerMinute <= 0 {
+			return nil
+		}
+
+		_, reset, allowed := s.takeRateLimit(tenantID,
+			config.RateLimitRequestsPerMinute, config.RateLimitTokensPerMinute, time.Now())
+		if allowed {
+			return nil
+		}
+
+		timer := time.NewTimer(reset)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+func isBatchThrottled(err error) bool {
+	return errors.IsType(err, errors.ErrorTypeTooManyRequests) ||
+		errors.IsType(err, errors.ErrorTypeProviderLimit)
+}
+
+func newBatchState(total int) *batchState {
+	items := make([]BatchItem, total)
+	for i := range items {
+		items[i] = BatchItem{Index: i, Status: domain.RequestStatusPending}
+	}
+
+	return &batchState{
+		job: BatchJob{
+			ID:        "batch_" + uuid.New().String(),
+			Object:    "batch",
+			Status:    domain.RequestStatusPending,
+			Total:     total,
+			CreatedAt: time.Now(),
+			Items:     items,
+		},
+		cancel: func() {},
+		done:   make(chan struct{}),
+	}
+}
+
+func batchCacheKey(tenantID, id string) string {
+	return fmt.Sprintf("batch:%s:%s", tenantID, id)
+}
+
+// batchCancelKey marks a batch as cancelled for the replica running it
+func batchCancelKey(tenantID, id string) string {
+	return fmt.Sprintf("batch-cancel:%s:%s", tenantID, id)
+}
+
+func (s *Service) saveBatch(ctx context.Context, tenantID string, job BatchJob) error {
+	return s.cacheClient.Set(ctx, batchCacheKey(tenantID, job.ID), job, s.currentConfig().Batch.Retention)
+}
+
+// persistBatch saves the job's current state, logging rather than failing
+// the batch when the cache is unavailable
+func (s *Service) persistBatch(ctx context.Context, tenantID string, state *batchState) {
+	state.saveMu.Lock()
+	defer state.saveMu.Unlock()
+
+	job := state.snapshot()
+	if err := s.saveBatch(ctx, tenantID, job); err != nil {
+		s.logger.Error("Failed to store batch job",
+			logger.F("batch_id", job.ID),
+			logger.F("error", err))
+	}
+}
+
+// persistBatchChanges saves the job every interval in which its items
+// changed, until stop is closed
+func (s *Service) persistBatchChanges(ctx context.Context, tenantID string, state *batchState, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if state.takeChanged() {
+				s.persistBatch(ctx, tenantID, state)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// loadBatch only finds batches submitted by the same tenant. Jobs stored
+// by a cache that serializes its values are decoded back into a BatchJob.
+func (s *Service) loadBatch(ctx context.Context, tenantID, id string) (BatchJob, error) {
+	value, found, err := s.cacheClient.Get(ctx, batchCacheKey(tenantID, id))
+	if err != nil {
+		return BatchJob{}, errors.InternalError("failed to load batch job", err)
+	}
+	if !found {
+		return BatchJob{}, errors.NotFoundError("batch", id)
+	}
+
+	if job, ok := value.(BatchJob); ok {
+		return job, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return BatchJob{}, errors.InternalError("failed to decode batch job", err)
+	}
+	var job BatchJob
+	if err := json.Unmarshal(data, &job); err != nil || job.ID != id {
+		return BatchJob{}, errors.InternalError("failed to decode batch job", err)
+	}
+	return job, nil
+}
+
+// trackBatch records a batch running on this replica so it can be
+// cancelled without waiting for the cancel marker to be noticed
+func (s *Service) trackBatch(tenantID string, state *batchState) {
+	s.batchMu.Lock()
+	defer s.batchMu.Unlock()
+
+	if s.batches == nil {
+		s.batches = make(map[string]*batchState)
+	}
+	s.batches[batchCacheKey(tenantID, state.job.ID)] = state
+}
+
+func (s *Service) forgetBatch(tenantID string, state *batchState) {
+	s.batchMu.Lock()
+	defer s.batchMu.Unlock()
+	delete(s.batches, batchCacheKey(tenantID, state.job.ID))
+}
+
+func (s *Service) runningBatch(tenantID, id string) *batchState {
+	s.batchMu.Lock()
+	defer s.batchMu.Unlock()
+	return s.batches[batchCacheKey(tenantID, id)]
+}
+
+// watchBatchCancel cancels a running batch once any replica has marked it
+// cancelled
+func (s *Service) watchBatchCancel(ctx context.Context, tenantID string, state *batchState, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		case <-state.done:
+			return
+		}
+
+		if _, found, err := s.cacheClient.Get(ctx, batchCancelKey(tenantID, state.job.ID)); err == nil && found {
+			state.cancel()
+			return
+		}
+	}
+}
+
+// awaitBatch returns a cancelled batch once it has wound down, or as it is
+// when ctx ends first. A batch running on this replica is cancelled
+// directly; one running elsewhere is polled until its final state is saved.
+func (s *Service) awaitBatch(ctx context.Context, tenantID, id string) (BatchJob, error) {
+	if state := s.runningBatch(tenantID, id); state != nil {
+		state.cancel()
+		select {
+		case <-state.done:
+		case <-ctx.Done():
+		}
+		return s.loadBatch(context.WithoutCancel(ctx), tenantID, id)
+	}
+
+	ticker := time.NewTicker(batchCancelPollInterval)
+	defer ticker.Stop()
+	for {
+		job, err := s.loadBatch(context.WithoutCancel(ctx), tenantID, id)
+		if err != nil || job.CompletedAt != nil {
+			return job, err
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return job, nil
+		}
+	}
+}
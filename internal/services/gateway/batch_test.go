@@ -0,0 +1,363 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/services/gateway/clients"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// batchRouterClient answers completions through a per-test function. Call
+// number hang instead blocks until its context is cancelled, closing
+// hanging once it has started.
+type batchRouterClient struct {
+	fakeRouterClient
+	mu      sync.Mutex
+	calls   int
+	respond func(call int, req *domain.CompletionRequest) (*domain.CompletionResponse, error)
+	hang    int
+	hanging chan struct{}
+}
+
+func (b *batchRouterClient) RouteCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+	b.mu.Lock()
+	b.calls++
+	call := b.calls
+	b.mu.Unlock()
+
+	if call == b.hang {
+		close(b.hanging)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return b.respond(call, req)
+}
+
+func newBatchTestRouter(t *testing.T, concurrency int, router *batchRouterClient) *gin.Engine {
+	return batchTestEngine(newBatchTestService(t, concurrency, router))
+}
+
+func newBatchTestService(t *testing.T, concurrency int, router *batchRouterClient) *Service {
+	gin.SetMode(gin.TestMode)
+	originalBackoff, originalPoll, originalPersist := batchRetryBackoff, batchCancelPollInterval, batchPersistInterval
+	batchRetryBackoff, batchCancelPollInterval, batchPersistInterval = time.Millisecond, time.Millisecond, time.Millisecond
+	t.Cleanup(func() {
+		batchRetryBackoff, batchCancelPollInterval, batchPersistInterval = originalBackoff, originalPoll, originalPersist
+	})
+
+	return &Service{
+		config: &env.Config{Batch: env.BatchConfig{
+			MaxItems:       10,
+			MaxConcurrency: concurrency,
+			MaxRetries:     2,
+			Retention:      time.Hour,
+		}},
+		logger:        logger.NewNoop(),
+		routerClient:  router,
+		cacheClient:   clients.NewSimpleCacheClient(logger.NewNoop()),
+		metricsClient: &fakeMetricsClient{},
+	}
+}
+
+func batchTestEngine(service *Service) *gin.Engine {
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set("tenant_id", c.GetHeader("X-Tenant-ID"))
+		c.Set("correlation_id", "req-1")
+	})
+	engine.Use(service.rateLimitMiddleware())
+	engine.POST("/v1/batches", service.handleCreateBatch)
+	engine.GET("/v1/batches/:id", service.handleGetBatch)
+	engine.DELETE("/v1/batches/:id", service.handleCancelBatch)
+	return engine
+}
+
+func submitBatch(t *testing.T, engine *gin.Engine, tenant string, requests ...ChatCompletionRequest) (int, BatchJob) {
+	body, _ := json.Marshal(BatchRequest{Requests: requests})
+	req := httptest.NewRequest(http.MethodPost, "/v1/batches", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Tenant-ID", tenant)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var job BatchJob
+	json.Unmarshal(w.Body.Bytes(), &job)
+	return w.Code, job
+}
+
+func pollBatch(t *testing.T, engine *gin.Engine, tenant, id string) (int, BatchJob) {
+	req := httptest.NewRequest(http.MethodGet, "/v1/batches/"+id, nil)
+	req.Header.Set("X-Tenant-ID", tenant)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var job BatchJob
+	json.Unmarshal(w.Body.Bytes(), &job)
+	return w.Code, job
+}
+
+func cancelBatch(t *testing.T, engine *gin.Engine, tenant, id string) (int, BatchJob) {
+	req := httptest.NewRequest(http.MethodDelete, "/v1/batches/"+id, nil)
+	req.Header.Set("X-Tenant-ID", tenant)
+
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var job BatchJob
+	json.Unmarshal(w.Body.Bytes(), &job)
+	return w.Code, job
+}
+
+func waitForBatch(t *testing.T, engine *gin.Engine, tenant, id string) BatchJob {
+	var job BatchJob
+	require.Eventually(t, func() bool {
+		_, job = pollBatch(t, engine, tenant, id)
+		return job.CompletedAt != nil
+	}, 2*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func batchItem(content string) ChatCompletionRequest {
+	return ChatCompletionRequest{
+		Model:    "gpt-4",
+		Messages: []Message{{Role: "user", Content: content}},
+	}
+}
+
+func TestBatch_ProcessesItemsAndRetriesThrottling(t *testing.T) {
+	router := &batchRouterClient{respond: func(call int, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+		if call == 1 {
+			return nil, errors.RateLimitError(10, time.Now())
+		}
+		return &domain.CompletionResponse{ID: req.RequestID, Model: req.Model}, nil
+	}}
+	engine := newBatchTestRouter(t, 1, router)
+
+	status, job := submitBatch(t, engine, "tenant-a", batchItem("one"), batchItem("two"))
+	require.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, 2, job.Total)
+
+	job = waitForBatch(t, engine, "tenant-a", job.ID)
+	assert.Equal(t, domain.RequestStatusCompleted, job.Status)
+	assert.Equal(t, 2, job.Completed)
+	for i, item := range job.Items {
+		assert.Equal(t, domain.RequestStatusCompleted, item.Status)
+		require.NotNil(t, item.Response)
+		assert.Equal(t, []string{"req-1-0", "req-1-1"}[i], item.Response.ID)
+	}
+	assert.Equal(t, 3, router.calls, "the throttled item is retried")
+}
+
+// batchSaveCountingCache counts the saves of batch jobs
+type batchSaveCountingCache struct {
+	CacheClient
+	mu    sync.Mutex
+	saves int
+}
+
+func (c *batchSaveCountingCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if strings.HasPrefix(key, "batch:") {
+		c.mu.Lock()
+		c.saves++
+		c.mu.Unlock()
+	}
+	return c.CacheClient.Set(ctx, key, value, ttl)
+}
+
+func TestBatch_SavesChangesPeriodically(t *testing.T) {
+	router := &batchRouterClient{respond: func(call int, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+		return &domain.CompletionResponse{ID: req.RequestID, Model: req.Model}, nil
+	}}
+	service := newBatchTestService(t, 10, router)
+	cache := &batchSaveCountingCache{CacheClient: service.cacheClient}
+	service.cacheClient = cache
+	engine := batchTestEngine(service)
+
+	// No periodic save falls within the test, so the item changes only
+	// reach the cache with the final save
+	batchPersistInterval = time.Hour
+	items := make([]ChatCompletionRequest, 10)
+	for i := range items {
+		items[i] = batchItem("item")
+	}
+	status, job := submitBatch(t, engine, "tenant-a", items...)
+	require.Equal(t, http.StatusAccepted, status)
+
+	job = waitForBatch(t, engine, "tenant-a", job.ID)
+	assert.Equal(t, 10, job.Completed)
+	require.Eventually(t, func() bool { return service.runningBatch("tenant-a", job.ID) == nil }, time.Second, time.Millisecond)
+
+	// Submission, processing and the final state
+	cache.mu.Lock()
+	assert.Equal(t, 3, cache.saves)
+	cache.mu.Unlock()
+
+	job, err := service.loadBatch(context.Background(), "tenant-a", job.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 10, job.Completed)
+	assert.NotNil(t, job.CompletedAt)
+}
+
+func TestBatch_StopsWhenBudgetExhausted(t *testing.T) {
+	router := &batchRouterClient{respond: func(call int, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+		return nil, errors.BudgetExceededError(100, 100)
+	}}
+	engine := newBatchTestRouter(t, 1, router)
+
+	_, job := submitBatch(t, engine, "tenant-a", batchItem("one"), batchItem("two"), batchItem("three"))
+	job = waitForBatch(t, engine, "tenant-a", job.ID)
+
+	assert.Equal(t, domain.RequestStatusFailed, job.Status)
+	assert.Equal(t, 3, job.Failed)
+	assert.Equal(t, 1, router.calls, "items after the budget is exhausted are not sent upstream")
+	for _, item := range job.Items {
+		require.NotNil(t, item.Error)
+		assert.Equal(t, errors.ErrorTypeBudgetExceeded, item.Error.Type)
+	}
+}
+
+func TestBatch_RejectsInvalidItems(t *testing.T) {
+	router := &batchRouterClient{}
+	engine := newBatchTestRouter(t, 1, router)
+
+	streaming := batchItem("one")
+	streaming.Stream = true
+	status, _ := submitBatch(t, engine, "tenant-a", batchItem("ok"), streaming)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _ = submitBatch(t, engine, "tenant-a", ChatCompletionRequest{Messages: []Message{{Role: "user", Content: "hi"}}})
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Zero(t, router.calls)
+}
+
+func TestBatch_ScopedToTenant(t *testing.T) {
+	router := &batchRouterClient{respond: func(call int, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+		return &domain.CompletionResponse{}, nil
+	}}
+	engine := newBatchTestRouter(t, 1, router)
+
+	_, job := submitBatch(t, engine, "tenant-a", batchItem("one"))
+	waitForBatch(t, engine, "tenant-a", job.ID)
+
+	status, _ := pollBatch(t, engine, "tenant-b", job.ID)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestBatch_Cancel(t *testing.T) {
+	router := &batchRouterClient{
+		respond: func(call int, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+			return &domain.CompletionResponse{ID: req.RequestID, Usage: domain.Usage{TotalTokens: 30, CostUSD: 0.01}}, nil
+		},
+		hang:    2,
+		hanging: make(chan struct{}),
+	}
+	engine := newBatchTestRouter(t, 1, router)
+
+	_, job := submitBatch(t, engine, "tenant-a", batchItem("one"), batchItem("two"), batchItem("three"))
+	<-router.hanging
+
+	status, job := cancelBatch(t, engine, "tenant-a", job.ID)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, domain.RequestStatusCancelled, job.Status)
+	assert.NotNil(t, job.CancelledAt)
+	assert.Equal(t, 1, job.Completed)
+	assert.Equal(t, 2, job.Cancelled)
+	assert.Equal(t, 30, job.Usage.TotalTokens, "the item that finished is still charged")
+	require.NotNil(t, job.Items[0].Response)
+	assert.Equal(t, domain.RequestStatusCancelled, job.Items[1].Status)
+	assert.Equal(t, domain.RequestStatusCancelled, job.Items[2].Status)
+	assert.Equal(t, 2, router.calls, "no item is dispatched after cancellation")
+
+	// Cancelling again returns the same job
+	status, again := cancelBatch(t, engine, "tenant-a", job.ID)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, job.Status, again.Status)
+	assert.True(t, job.CancelledAt.Equal(*again.CancelledAt))
+}
+
+func TestBatch_CancelFinishedBatch(t *testing.T) {
+	router := &batchRouterClient{respond: func(call int, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+		return &domain.CompletionResponse{}, nil
+	}}
+	engine := newBatchTestRouter(t, 1, router)
+
+	_, job := submitBatch(t, engine, "tenant-a", batchItem("one"))
+	waitForBatch(t, engine, "tenant-a", job.ID)
+
+	status, job := cancelBatch(t, engine, "tenant-a", job.ID)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, domain.RequestStatusCompleted, job.Status)
+	assert.Nil(t, job.CancelledAt)
+
+	status, _ = cancelBatch(t, engine, "tenant-b", job.ID)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+func TestBatch_ChargesEachItemAgainstRateLimit(t *testing.T) {
+	router := &batchRouterClient{respond: func(call int, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+		return &domain.CompletionResponse{ID: req.RequestID}, nil
+	}}
+	service := newBatchTestService(t, 1, router)
+	service.config.RateLimitRequestsPerMinute = 2
+	engine := batchTestEngine(service)
+
+	_, job := submitBatch(t, engine, "tenant-a", batchItem("one"), batchItem("two"), batchItem("three"))
+
+	// The submission and the second item use up the window; the third item
+	// waits for it to reset
+	require.Eventually(t, func() bool {
+		job, err := service.loadBatch(context.Background(), "tenant-a", job.ID)
+		return err == nil && job.Completed == 2
+	}, 2*time.Second, 5*time.Millisecond)
+	status, _ := pollBatch(t, engine, "tenant-a", job.ID)
+	assert.Equal(t, http.StatusTooManyRequests, status)
+
+	service.rateLimitMu.Lock()
+	service.rateWindows = nil
+	service.rateLimitMu.Unlock()
+
+	status, job = cancelBatch(t, engine, "tenant-a", job.ID)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, domain.RequestStatusCancelled, job.Items[2].Status)
+	assert.Equal(t, 2, router.calls)
+}
+
+func TestBatch_PollAndCancelFromAnotherReplica(t *testing.T) {
+	router := &batchRouterClient{hang: 1, hanging: make(chan struct{})}
+	running := newBatchTestService(t, 1, router)
+	other := newBatchTestService(t, 1, router)
+	other.cacheClient = running.cacheClient
+	runningEngine, otherEngine := batchTestEngine(running), batchTestEngine(other)
+
+	_, job := submitBatch(t, runningEngine, "tenant-a", batchItem("one"), batchItem("two"))
+	<-router.hanging
+
+	require.Eventually(t, func() bool {
+		_, job = pollBatch(t, otherEngine, "tenant-a", job.ID)
+		return job.Items[0].Status == domain.RequestStatusProcessing
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, domain.RequestStatusProcessing, job.Status)
+
+	status, job := cancelBatch(t, otherEngine, "tenant-a", job.ID)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, domain.RequestStatusCancelled, job.Status)
+	assert.Equal(t, 2, job.Cancelled)
+	assert.Equal(t, 1, router.calls)
+}
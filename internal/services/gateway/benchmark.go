@@ -0,0 +1,334 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+const (
+	defaultBenchmarkRequests    = 10
+	maxBenchmarkRequests        = 500
+	defaultBenchmarkConcurrency = 2
+	maxBenchmarkConcurrency     = 50
+	defaultBenchmarkMaxTokens   = 64
+	maxBenchmarkMaxTokens       = 4096
+
+	// defaultBenchmarkPrompt asks for a short answer of fairly stable length
+	// so runs against different models are comparable
+	defaultBenchmarkPrompt = "Explain in one paragraph how a hash map handles collisions."
+
+	// benchmarkWarning is returned with every result so nobody mistakes the
+	// numbers for a dry run
+	benchmarkWarning = "benchmark requests were sent to the provider and are billed like any other traffic"
+)
+
+// handleBenchmark godoc
+// @Summary Benchmark a model
+// @Description Runs concurrent canned completions against a model and reports latency percentiles, tokens per second, error rate and cost. WARNING: this generates real, billed provider load. Requests count against the tenant's budget, bypass the response cache, and the run stops early once the budget or max_cost_usd is reached.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param X-Admin-Key header string true "Admin API key"
+// @Param request body BenchmarkRequest true "Benchmark settings; confirm_provider_load must be true"
+// @Success 200 {object} BenchmarkResult "Benchmark results"
+// @Failure 400 {object} ErrorResponse "Invalid settings, unknown model or estimated cost over max_cost_usd"
+// @Failure 403 {object} ErrorResponse "Admin key missing or invalid"
+// @Router /v1/internal/benchmark [post]
+func (s *Service) handleBenchmark(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var benchReq BenchmarkRequest
+	if err := c.ShouldBindJSON(&benchReq); err != nil {
+		s.respondWithError(c, errors.ValidationError("invalid request format", "body"))
+		return
+	}
+	if err := normalizeBenchmarkRequest(&benchReq); err != nil {
+		s.respondWithError(c, err)
+		return
+	}
+
+	template := &domain.CompletionRequest{
+		TenantID:  domain.TenantID(c.GetString("tenant_id")),
+		UserID:    domain.UserID(c.GetString("user_id")),
+		Provider:  domain.Provider(benchReq.Provider),
+		Model:     benchReq.Model,
+		MaxTokens: &benchReq.MaxTokens,
+		RequestID: c.GetString("correlation_id"),
+		Messages: []domain.Message{{
+			Role:    domain.MessageRoleUser,
+			Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: benchReq.Prompt}},
+		}},
+		Metadata: map[string]interface{}{"benchmark": true},
+	}
+
+	model, err := s.benchmarkModel(ctx, template)
+	if err != nil {
+		s.respondWithError(c, err)
+		return
+	}
+
+	// Refuse runs whose worst case is already over the caller's cap
+	perRequest := model.Pricing.CompletionCost(domain.Usage{
+		PromptTokens:     domain.EstimatePromptTokens(template.Messages),
+		CompletionTokens: benchReq.MaxTokens,
+	})
+	estimated := perRequest * float64(benchReq.Requests)
+	if benchReq.MaxCostUSD > 0 && estimated > benchReq.MaxCostUSD {
+		s.respondWithError(c, ceilingExceededError(estimated, benchReq.MaxCostUSD))
+		return
+	}
+
+	s.logger.Warn("Benchmark generating real provider load",
+		logger.F("model", benchReq.Model),
+		logger.F("provider", model.Provider),
+		logger.F("requests", benchReq.Requests),
+		logger.F("concurrency", benchReq.Concurrency),
+		logger.F("estimated_max_cost_usd", estimated))
+
+	result := s.runBenchmark(ctx, &benchReq, template, model)
+	result.EstimatedMaxCostUSD = estimated
+
+	s.logger.Info("Benchmark finished",
+		logger.F("model", result.Model),
+		logger.F("succeeded", result.Succeeded),
+		logger.F("failed", result.Failed),
+		logger.F("cost_usd", result.CostUSD),
+		logger.F("stopped_reason", result.StoppedReason))
+
+	c.JSON(http.StatusOK, result)
+}
+
+// normalizeBenchmarkRequest applies defaults and bounds to the settings
+func normalizeBenchmarkRequest(req *BenchmarkRequest) error {
+	if !req.ConfirmProviderLoad {
+		return errors.ValidationError(
+			"benchmarks send real, billed requests to the provider; set confirm_provider_load to true", "confirm_provider_load")
+	}
+	if req.Model == "" {
+		return errors.ValidationError("model is required", "model")
+	}
+
+	if req.Requests == 0 {
+		req.Requests = defaultBenchmarkRequests
+	}
+	if req.Concurrency == 0 {
+		req.Concurrency = defaultBenchmarkConcurrency
+	}
+	if req.MaxTokens == 0 {
+		req.MaxTokens = defaultBenchmarkMaxTokens
+	}
+	if req.Prompt == "" {
+		req.Prompt = defaultBenchmarkPrompt
+	}
+
+	switch {
+	case req.Requests < 1 || req.Requests > maxBenchmarkRequests:
+		return errors.ValidationError(fmt.Sprintf("requests must be between 1 and %d", maxBenchmarkRequests), "requests")
+	case req.Concurrency < 1 || req.Concurrency > maxBenchmarkConcurrency:
+		return errors.ValidationError(fmt.Sprintf("concurrency must be between 1 and %d", maxBenchmarkConcurrency), "concurrency")
+	case req.MaxTokens < 1 || req.MaxTokens > maxBenchmarkMaxTokens:
+		return errors.ValidationError(fmt.Sprintf("max_tokens must be between 1 and %d", maxBenchmarkMaxTokens), "max_tokens")
+	case req.MaxCostUSD < 0:
+		return errors.ValidationError("max_cost_usd must not be negative", "max_cost_usd")
+	}
+
+	if req.Concurrency > req.Requests {
+		req.Concurrency = req.Requests
+	}
+	return nil
+}
+
+// benchmarkModel looks the model up in the router's registry, which prices
+// the run and confirms the model exists before any load is sent
+func (s *Service) benchmarkModel(ctx context.Context, req *domain.CompletionRequest) (domain.Model, error) {
+	models, err := s.routerClient.ListModels(ctx, &domain.ListModelsOptions{Provider: req.Provider})
+	if err != nil {
+		return domain.Model{}, errors.InternalError("failed to load models", err)
+	}
+
+	for _, model := range models.Data {
+		if model.ModelID == req.Model {
+			return model, nil
+		}
+	}
+	return domain.Model{}, errors.ValidationError(fmt.Sprintf("unknown model %s", req.Model), "model")
+}
+
+// benchmarkSample is the outcome of one benchmark request
+type benchmarkSample struct {
+	latency          time.Duration
+	completionTokens int
+	totalTokens      int
+	costUSD          float64
+	errType          errors.ErrorType
+}
+
+// runBenchmark sends the canned requests with bounded concurrency. No new
+// requests start once the budget is exhausted, the spend reaches
+// MaxCostUSD or the caller disconnects.
+func (s *Service) runBenchmark(ctx context.Context, benchReq *BenchmarkRequest, template *domain.CompletionRequest, model domain.Model) *BenchmarkResult {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		samples  []benchmarkSample
+		spent    float64
+		stopped  string
+		slots    = make(chan struct{}, benchReq.Concurrency)
+		runStart = time.Now()
+	)
+
+	stopReason := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return stopped
+	}
+
+	for i := 0; i < benchReq.Requests; i++ {
+		slots <- struct{}{}
+
+		if ctx.Err() != nil {
+			mu.Lock()
+			if stopped == "" {
+				stopped = "cancelled"
+			}
+			mu.Unlock()
+		}
+		if stopReason() != "" {
+			<-slots
+			break
+		}
+
+		req := *template
+		req.RequestID = fmt.Sprintf("%s-bench-%d", template.RequestID, i)
+
+		wg.Add(1)
+		go func(req *domain.CompletionRequest) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			start := time.Now()
+			response, err := s.routerClient.RouteCompletion(ctx, req)
+			sample := benchmarkSample{latency: time.Since(start)}
+			if err != nil {
+				sample.errType = errors.FromError(err).Type
+			} else {
+				sample.completionTokens = response.Usage.CompletionTokens
+				sample.totalTokens = response.Usage.TotalTokens
+				sample.costUSD = response.Usage.CostUSD
+				if sample.costUSD == 0 {
+					sample.costUSD = model.Pricing.CompletionCost(response.Usage)
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			samples = append(samples, sample)
+			spent += sample.costUSD
+			switch {
+			case stopped != "":
+			case isBudgetExhausted(err):
+				stopped = "budget_exceeded"
+			case benchReq.MaxCostUSD > 0 && spent >= benchReq.MaxCostUSD:
+				stopped = "max_cost_reached"
+			}
+		}(&req)
+	}
+	wg.Wait()
+
+	result := summarizeBenchmark(samples, time.Since(runStart))
+	result.Model = benchReq.Model
+	result.Provider = string(model.Provider)
+	result.Requested = benchReq.Requests
+	result.Concurrency = benchReq.Concurrency
+	result.StoppedReason = stopped
+	result.Warning = benchmarkWarning
+	return result
+}
+
+// isBudgetExhausted reports whether an error means the tenant or platform
+// budget has no room for more requests
+func isBudgetExhausted(err error) bool {
+	return errors.IsType(err, errors.ErrorTypeBudgetExceeded) || errors.IsType(err, errors.ErrorTypeQuotaExceeded)
+}
+
+// summarizeBenchmark computes the statistics of a run. Latency covers every
+// request; tokens per second only the successful ones.
+func summarizeBenchmark(samples []benchmarkSample, elapsed time.Duration) *BenchmarkResult {
+	result := &BenchmarkResult{
+		Sent:       len(samples),
+		DurationMs: float64(elapsed) / float64(time.Millisecond),
+	}
+	if len(samples) == 0 {
+		return result
+	}
+
+	latencies := make([]time.Duration, len(samples))
+	var total time.Duration
+	var rateSum float64
+	for i, sample := range samples {
+		latencies[i] = sample.latency
+		total += sample.latency
+		result.CostUSD += sample.costUSD
+
+		if sample.errType != "" {
+			result.Failed++
+			if result.Errors == nil {
+				result.Errors = make(map[string]int)
+			}
+			result.Errors[string(sample.errType)]++
+			continue
+		}
+
+		result.Succeeded++
+		result.CompletionTokens += sample.completionTokens
+		result.TotalTokens += sample.totalTokens
+		if sample.latency > 0 {
+			rateSum += float64(sample.completionTokens) / sample.latency.Seconds()
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	result.ErrorRate = float64(result.Failed) / float64(len(samples))
+	result.Latency = BenchmarkLatency{
+		MinMs:  durationMs(latencies[0]),
+		MeanMs: durationMs(total / time.Duration(len(latencies))),
+		P50Ms:  durationMs(percentile(latencies, 50)),
+		P90Ms:  durationMs(percentile(latencies, 90)),
+		P99Ms:  durationMs(percentile(latencies, 99)),
+		MaxMs:  durationMs(latencies[len(latencies)-1]),
+	}
+	if result.Succeeded > 0 {
+		result.TokensPerSecond = rateSum / float64(result.Succeeded)
+	}
+	if elapsed > 0 {
+		result.ThroughputTokensPerSecond = float64(result.CompletionTokens) / elapsed.Seconds()
+		result.RequestsPerSecond = float64(len(samples)) / elapsed.Seconds()
+	}
+	return result
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(float64(len(sorted))*p/100)) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
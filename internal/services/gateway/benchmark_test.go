@@ -0,0 +1,131 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// budgetRouterClient succeeds until its budget of calls is spent
+type budgetRouterClient struct {
+	*fakeRouterClient
+	mu      sync.Mutex
+	calls   int
+	allowed int
+}
+
+func (b *budgetRouterClient) RouteCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.calls++
+	if b.calls > b.allowed {
+		return nil, errors.NewError(errors.ErrorTypeQuotaExceeded, "tenant daily budget limit exceeded").Build()
+	}
+	return b.completion, nil
+}
+
+var benchmarkTestModel = domain.Model{
+	ModelID:  "gpt-4o",
+	Provider: domain.ProviderAzureOpenAI,
+	Pricing:  domain.ModelPricing{InputTokenCost: 0.001, OutputTokenCost: 0.002, Unit: "token"},
+}
+
+func runBenchmarkRequest(service *Service, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/v1/internal/benchmark", bytes.NewBufferString(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	service.handleBenchmark(c)
+	return w
+}
+
+func TestHandleBenchmark_RequiresConfirmation(t *testing.T) {
+	service, router := newCapabilityTestService(benchmarkTestModel)
+
+	w := runBenchmarkRequest(service, `{"model":"gpt-4o"}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "confirm_provider_load")
+	assert.Zero(t, router.listCalls)
+}
+
+func TestHandleBenchmark_ReportsResults(t *testing.T) {
+	service, router := newCapabilityTestService(benchmarkTestModel)
+	router.completion = &domain.CompletionResponse{
+		Usage: domain.Usage{PromptTokens: 20, CompletionTokens: 10, TotalTokens: 30},
+	}
+
+	w := runBenchmarkRequest(service, `{"model":"gpt-4o","requests":5,"concurrency":2,"confirm_provider_load":true}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result BenchmarkResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, 5, result.Sent)
+	assert.Equal(t, 5, result.Succeeded)
+	assert.Zero(t, result.ErrorRate)
+	assert.Equal(t, 50, result.CompletionTokens)
+	// Cost falls back to registry pricing when the response carries none
+	assert.InDelta(t, 5*(20*0.001+10*0.002), result.CostUSD, 1e-9)
+	assert.Equal(t, "azure-openai", result.Provider)
+	assert.NotEmpty(t, result.Warning)
+	assert.Empty(t, result.StoppedReason)
+}
+
+func TestHandleBenchmark_StopsWhenBudgetExhausted(t *testing.T) {
+	service, fake := newCapabilityTestService(benchmarkTestModel)
+	fake.completion = &domain.CompletionResponse{Usage: domain.Usage{CompletionTokens: 10, TotalTokens: 30}}
+	router := &budgetRouterClient{fakeRouterClient: fake, allowed: 2}
+	service.routerClient = router
+
+	w := runBenchmarkRequest(service, `{"model":"gpt-4o","requests":10,"concurrency":1,"confirm_provider_load":true}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result BenchmarkResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, "budget_exceeded", result.StoppedReason)
+	assert.Equal(t, 3, router.calls)
+	assert.Equal(t, 2, result.Succeeded)
+	assert.Equal(t, 1, result.Errors[string(errors.ErrorTypeQuotaExceeded)])
+}
+
+func TestHandleBenchmark_RejectsRunsOverMaxCost(t *testing.T) {
+	service, _ := newCapabilityTestService(benchmarkTestModel)
+
+	w := runBenchmarkRequest(service, `{"model":"gpt-4o","requests":100,"max_tokens":1000,"max_cost_usd":0.5,"confirm_provider_load":true}`)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "max_cost_usd")
+}
+
+func TestSummarizeBenchmark_Percentiles(t *testing.T) {
+	var samples []benchmarkSample
+	for i := 1; i <= 100; i++ {
+		samples = append(samples, benchmarkSample{latency: time.Duration(i) * time.Millisecond, completionTokens: 10})
+	}
+	samples = append(samples, benchmarkSample{latency: time.Millisecond, errType: errors.ErrorTypeTimeout})
+
+	result := summarizeBenchmark(samples, time.Second)
+
+	assert.Equal(t, 101, result.Sent)
+	assert.Equal(t, 1, result.Failed)
+	assert.InDelta(t, 1.0/101, result.ErrorRate, 1e-9)
+	assert.Equal(t, 1.0, result.Latency.MinMs)
+	assert.Equal(t, 50.0, result.Latency.P50Ms)
+	assert.Equal(t, 90.0, result.Latency.P90Ms)
+	assert.Equal(t, 99.0, result.Latency.P99Ms)
+	assert.Equal(t, 100.0, result.Latency.MaxMs)
+	assert.Equal(t, 1000.0, result.ThroughputTokensPerSecond)
+}
@@ -0,0 +1,209 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/services/gateway/clients"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// fakeRouterClient is a minimal RouterClient for handler-level tests
+type fakeRouterClient struct {
+	models       []domain.Model
+	listCalls    int
+	completion   *domain.CompletionResponse
+	embedding    *domain.EmbeddingResponse
+	stream       []*domain.StreamResponse
+	reload       *env.ReloadResult
+	config       *env.Config
+	probe        *domain.ProviderProbe
+	faq          []domain.FAQEntry
+	faqRequest   *domain.CreateFAQEntryRequest
+	killed       []domain.KillSwitch
+	health       *domain.HealthResponse
+	healthChecks atomic.Int32
+	err          error
+}
+
+func (f *fakeRouterClient) RouteCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+	return f.completion, f.err
+}
+
+func (f *fakeRouterClient) RouteCompletionStream(ctx context.Context, req *domain.CompletionRequest) (<-chan *domain.StreamResponse, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	ch := make(chan *domain.StreamResponse, len(f.stream))
+	for _, chunk := range f.stream {
+		ch <- chunk
+	}
+	close(ch)
+	return ch, nil
+}
+
+func (f *fakeRouterClient) RouteEmbedding(ctx context.Context, req *domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	return f.embedding, f.err
+}
+
+func (f *fakeRouterClient) ListModels(ctx context.Context, opts *domain.ListModelsOptions) (*domain.ModelsResponse, error) {
+	f.listCalls++
+	return &domain.ModelsResponse{Object: "list", Data: f.models}, nil
+}
+
+func (f *fakeRouterClient) HealthCheck(ctx context.Context) (*domain.HealthResponse, error) {
+	f.healthChecks.Add(1)
+	if f.health != nil {
+		return f.health, nil
+	}
+	return &domain.HealthResponse{Status: "healthy"}, nil
+}
+
+func (f *fakeRouterClient) GetGlobalUsage(ctx context.Context) (*clients.GlobalUsageStats, error) {
+	return nil, f.err
+}
+
+func (f *fakeRouterClient) GetTenantUsage(ctx context.Context, tenantID string, period string) (*clients.TenantUsageStats, error) {
+	return nil, f.err
+}
+
+func (f *fakeRouterClient) GetCostSummary(ctx context.Context) (*clients.CostSummaryStats, error) {
+	return nil, f.err
+}
+
+func (f *fakeRouterClient) ReloadConfig(ctx context.Context) (*env.ReloadResult, error) {
+	return f.reload, f.err
+}
+
+func (f *fakeRouterClient) GetConfig(ctx context.Context) (*env.Config, error) {
+	return f.config, f.err
+}
+
+func (f *fakeRouterClient) ProbeProvider(ctx context.Context, provider domain.Provider) (*domain.ProviderProbe, error) {
+	return f.probe, f.err
+}
+
+func (f *fakeRouterClient) ListFAQEntries(ctx context.Context, tenantID domain.TenantID) (*domain.FAQEntryList, error) {
+	list := &domain.FAQEntryList{Data: []domain.FAQEntry{}}
+	for _, entry := range f.faq {
+		if entry.TenantID == tenantID {
+			list.Data = append(list.Data, entry)
+		}
+	}
+	return list, f.err
+}
+
+func (f *fakeRouterClient) CreateFAQEntry(ctx context.Context, tenantID domain.TenantID, req *domain.CreateFAQEntryRequest) (*domain.FAQEntry, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.faqRequest = req
+	entry := domain.FAQEntry{ID: "faq_1", TenantID: tenantID, Question: req.Question, Answer: req.Answer}
+	f.faq = append(f.faq, entry)
+	return &entry, nil
+}
+
+func (f *fakeRouterClient) DeleteFAQEntry(ctx context.Context, tenantID domain.TenantID, id string) error {
+	for i, entry := range f.faq {
+		if entry.TenantID == tenantID && entry.ID == id {
+			f.faq = append(f.faq[:i], f.faq[i+1:]...)
+			return nil
+		}
+	}
+	return errors.NotFoundError("faq entry", id)
+}
+
+func (f *fakeRouterClient) ListKillSwitches(ctx context.Context) (*domain.KillSwitchList, error) {
+	return &domain.KillSwitchList{Data: append([]domain.KillSwitch{}, f.killed...), Configured: []domain.KillSwitch{}}, f.err
+}
+
+func (f *fakeRouterClient) SetKillSwitch(ctx context.Context, req *domain.SetKillSwitchRequest) (*domain.KillSwitchList, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	remaining := []domain.KillSwitch{}
+	for _, sw := range f.killed {
+		if sw != req.KillSwitch {
+			remaining = append(remaining, sw)
+		}
+	}
+	if req.Killed {
+		remaining = append(remaining, req.KillSwitch)
+	}
+	f.killed = remaining
+	return f.ListKillSwitches(ctx)
+}
+
+func newCapabilityTestService(models ...domain.Model) (*Service, *fakeRouterClient) {
+	router := &fakeRouterClient{models: models}
+	return &Service{config: &env.Config{}, logger: logger.NewNoop(), routerClient: router}, router
+}
+
+func TestMessageUnmarshal_MultiModalContent(t *testing.T) {
+	var msg Message
+	err := json.Unmarshal([]byte(`{"role":"user","content":[{"type":"text","text":"Describe"},{"type":"image_url","image_url":{"url":"https://example.com/a.png"}}]}`), &msg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Describe", msg.Content)
+	require.Len(t, msg.Parts, 2)
+	assert.Equal(t, "https://example.com/a.png", msg.Parts[1].ImageURL.URL)
+
+	err = json.Unmarshal([]byte(`{"role":"user","content":"hello"}`), &msg)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", msg.Content)
+	assert.Empty(t, msg.Parts)
+}
+
+func TestValidateModelCapabilities(t *testing.T) {
+	textOnly := domain.Model{ModelID: "gpt-35-turbo", Capabilities: []domain.Capability{domain.CapabilityCompletion}}
+	vision := domain.Model{ModelID: "gpt-4o", Capabilities: []domain.Capability{domain.CapabilityCompletion, domain.CapabilityVision}}
+
+	imageRequest := func(model string) *domain.CompletionRequest {
+		return &domain.CompletionRequest{
+			Model: model,
+			Messages: []domain.Message{{
+				Role:    domain.MessageRoleUser,
+				Content: []domain.ContentPart{{Type: domain.ContentTypeImageURL, ImageURL: &domain.ImageURL{URL: "https://example.com/a.png"}}},
+			}},
+		}
+	}
+
+	t.Run("text only request skips registry lookup", func(t *testing.T) {
+		service, router := newCapabilityTestService(textOnly)
+		req := &domain.CompletionRequest{Model: "gpt-35-turbo", Messages: []domain.Message{{Role: domain.MessageRoleUser, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "hi"}}}}}
+		assert.NoError(t, service.validateModelCapabilities(context.Background(), req))
+		assert.Equal(t, 0, router.listCalls)
+	})
+
+	t.Run("image parts require vision", func(t *testing.T) {
+		service, _ := newCapabilityTestService(textOnly, vision)
+
+		err := service.validateModelCapabilities(context.Background(), imageRequest("gpt-35-turbo"))
+		require.Error(t, err)
+		qlensErr, ok := err.(*errors.QLensError)
+		require.True(t, ok)
+		assert.Equal(t, errors.ErrorTypeValidation, qlensErr.Type)
+		assert.Contains(t, qlensErr.Message, "vision")
+		assert.Equal(t, "gpt-35-turbo", qlensErr.PublicError().Details["model"])
+
+		assert.NoError(t, service.validateModelCapabilities(context.Background(), imageRequest("gpt-4o")))
+	})
+
+	t.Run("tools require function calling", func(t *testing.T) {
+		service, _ := newCapabilityTestService(vision)
+		req := imageRequest("gpt-4o")
+		req.Tools = []domain.Tool{{Type: "function", Function: domain.FunctionDefinition{Name: "lookup"}}}
+
+		err := service.validateModelCapabilities(context.Background(), req)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), string(domain.CapabilityFunctionCalling))
+	})
+}
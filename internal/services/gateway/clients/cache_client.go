@@ -78,6 +78,22 @@ func (c *SimpleCacheClient) Set(ctx context.Context, key string, value interface
 	return nil
 }
 
+// SetIfAbsent stores a value with TTL only if the key holds no unexpired
+// entry, reporting whether it did
+func (c *SimpleCacheClient) SetIfAbsent(ctx context.Context, key string, value interface{}, ttl time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if entry, exists := c.cache[key]; exists && !entry.IsExpired() {
+		return false, nil
+	}
+	c.cache[key] = &CacheEntry{
+		Value:     value,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	return true, nil
+}
+
 // Delete removes a value from cache
 func (c *SimpleCacheClient) Delete(ctx context.Context, key string) error {
 	c.mu.Lock()
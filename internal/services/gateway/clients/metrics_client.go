@@ -8,15 +8,28 @@ import (
 
 	"github.com/prometheus/client_golang/api"
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/prometheus/common/model"
 	"github.com/quantum-suite/platform/pkg/shared/logger"
 )
 
-// PrometheusMetricsClient implements MetricsClient interface using Prometheus API
+// PrometheusMetricsClient implements MetricsClient interface using Prometheus API.
+// It records metrics into its own registry, which Handler serves for
+// Prometheus to scrape, and queries the Prometheus server for aggregates.
 type PrometheusMetricsClient struct {
 	client api.Client
 	v1API  v1.API
 	logger logger.Logger
+
+	registry         *prometheus.Registry
+	requests         *prometheus.CounterVec
+	requestDuration  *prometheus.HistogramVec
+	providerRequests *prometheus.CounterVec
+	providerLatency  *prometheus.HistogramVec
+	providerTokens   *prometheus.CounterVec
+	providerHealth   *prometheus.GaugeVec
 }
 
 // NewPrometheusMetricsClient creates a new Prometheus-based metrics client
@@ -31,11 +44,74 @@ func NewPrometheusMetricsClient(prometheusURL string, log logger.Logger) (*Prome
 		return nil, fmt.Errorf("failed to create prometheus client: %w", err)
 	}
 
-	return &PrometheusMetricsClient{
-		client: client,
-		v1API:  v1.NewAPI(client),
-		logger: log.WithField("component", "metrics_client"),
-	}, nil
+	m := &PrometheusMetricsClient{
+		client:   client,
+		v1API:    v1.NewAPI(client),
+		logger:   log.WithField("component", "metrics_client"),
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "qlens_requests_total",
+				Help: "Gateway requests by method, endpoint and status",
+			},
+			[]string{"method", "endpoint", "status"},
+		),
+		requestDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "qlens_request_duration_seconds",
+				Help:    "Gateway request duration in seconds by method and endpoint",
+				Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+			},
+			[]string{"method", "endpoint"},
+		),
+		providerRequests: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "qlens_provider_requests_total",
+				Help: "Requests served by each provider, by provider, model and status",
+			},
+			[]string{"provider", "model", "status"},
+		),
+		providerLatency: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "qlens_provider_latency_seconds",
+				Help:    "Provider request duration in seconds by provider and model",
+				Buckets: []float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+			},
+			[]string{"provider", "model"},
+		),
+		providerTokens: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "qlens_provider_tokens_total",
+				Help: "Tokens used by each provider, by provider and model",
+			},
+			[]string{"provider", "model"},
+		),
+		providerHealth: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "qlens_provider_health_status",
+				Help: "Provider health status (1=healthy, 0=unhealthy)",
+			},
+			[]string{"provider"},
+		),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.requestDuration,
+		m.providerRequests,
+		m.providerLatency,
+		m.providerTokens,
+		m.providerHealth,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m, nil
+}
+
+// Handler serves the recorded metrics in the Prometheus exposition format
+func (m *PrometheusMetricsClient) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
 }
 
 // RecordRequest records a request metric
@@ -45,9 +121,9 @@ func (m *PrometheusMetricsClient) RecordRequest(ctx context.Context, method, end
 		logger.F("endpoint", endpoint),
 		logger.F("status", status),
 		logger.F("duration", duration))
-	
-	// In a real implementation, this would increment counters and record histograms
-	// For now, we'll just log the metric
+
+	m.requests.WithLabelValues(method, endpoint, status).Inc()
+	m.requestDuration.WithLabelValues(method, endpoint).Observe(duration.Seconds())
 	return nil
 }
 
@@ -59,8 +135,22 @@ func (m *PrometheusMetricsClient) RecordProviderRequest(ctx context.Context, pro
 		logger.F("status", status),
 		logger.F("duration", duration),
 		logger.F("tokens", tokens))
-	
-	// In a real implementation, this would increment provider-specific counters
+
+	m.providerRequests.WithLabelValues(provider, model, status).Inc()
+	m.providerLatency.WithLabelValues(provider, model).Observe(duration.Seconds())
+	if tokens > 0 {
+		m.providerTokens.WithLabelValues(provider, model).Add(float64(tokens))
+	}
+	return nil
+}
+
+// RecordProviderHealth records whether a provider is currently healthy
+func (m *PrometheusMetricsClient) RecordProviderHealth(ctx context.Context, provider string, healthy bool) error {
+	value := 0.0
+	if healthy {
+		value = 1
+	}
+	m.providerHealth.WithLabelValues(provider).Set(value)
 	return nil
 }
 
@@ -4,11 +4,14 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	goerrors "errors"
 	"fmt"
 	"net/http"
+	neturl "net/url"
 	"time"
 
 	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
 	"github.com/quantum-suite/platform/pkg/shared/errors"
 	"github.com/quantum-suite/platform/pkg/shared/logger"
 )
@@ -166,13 +169,22 @@ func (c *HTTPRouterClient) RouteCompletionStream(ctx context.Context, req *domai
 		for {
 			var streamResp domain.StreamResponse
 			if err := decoder.Decode(&streamResp); err != nil {
-				ch <- &domain.StreamResponse{
-					Error: errors.InternalError("stream decode error", err),
+				streamErr := errors.InternalError("stream decode error", err)
+				if goerrors.Is(ctx.Err(), context.Canceled) {
+					streamErr = errors.CancelledError("completion stream", ctx.Err())
+				}
+				select {
+				case ch <- &domain.StreamResponse{Error: streamErr}:
+				case <-ctx.Done():
 				}
 				return
 			}
 			
-			ch <- &streamResp
+			select {
+			case ch <- &streamResp:
+			case <-ctx.Done():
+				return
+			}
 			
 			if streamResp.Done {
 				return
@@ -394,8 +406,244 @@ func (c *HTTPRouterClient) GetCostSummary(ctx context.Context) (*CostSummaryStat
 	return &stats, nil
 }
 
-// handleHTTPError converts HTTP errors to QLens errors
+// ReloadConfig asks the router to re-read its configuration
+func (c *HTTPRouterClient) ReloadConfig(ctx context.Context) (*env.ReloadResult, error) {
+	url := fmt.Sprintf("%s/internal/v1/reload", c.baseURL)
+	
+	// Create HTTP request
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return nil, errors.InternalError("failed to create request", err)
+	}
+	
+	// Send request
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, errors.InternalError("router request failed", err)
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleHTTPError(resp)
+	}
+	
+	var result env.ReloadResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.InternalError("failed to decode response", err)
+	}
+	
+	return &result, nil
+}
+
+// GetConfig fetches the router's effective configuration, with secrets
+// redacted
+func (c *HTTPRouterClient) GetConfig(ctx context.Context) (*env.Config, error) {
+	url := fmt.Sprintf("%s/internal/v1/config", c.baseURL)
+	
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, errors.InternalError("failed to create request", err)
+	}
+	
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, errors.InternalError("router request failed", err)
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleHTTPError(resp)
+	}
+	
+	var config env.Config
+	if err := json.NewDecoder(resp.Body).Decode(&config); err != nil {
+		return nil, errors.InternalError("failed to decode response", err)
+	}
+	
+	return &config, nil
+}
+
+// ProbeProvider asks the router to health check one provider now
+func (c *HTTPRouterClient) ProbeProvider(ctx context.Context, provider domain.Provider) (*domain.ProviderProbe, error) {
+	url := fmt.Sprintf("%s/internal/v1/providers/%s/probe", c.baseURL, provider)
+	
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, errors.InternalError("failed to create request", err)
+	}
+	
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, errors.InternalError("router request failed", err)
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleHTTPError(resp)
+	}
+	
+	var probe domain.ProviderProbe
+	if err := json.NewDecoder(resp.Body).Decode(&probe); err != nil {
+		return nil, errors.InternalError("failed to decode response", err)
+	}
+	
+	return &probe, nil
+}
+
+// ListFAQEntries lists a tenant's FAQ entries
+func (c *HTTPRouterClient) ListFAQEntries(ctx context.Context, tenantID domain.TenantID) (*domain.FAQEntryList, error) {
+	url := fmt.Sprintf("%s/internal/v1/faq/%s", c.baseURL, neturl.PathEscape(string(tenantID)))
+	
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, errors.InternalError("failed to create request", err)
+	}
+	
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, errors.InternalError("router request failed", err)
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleHTTPError(resp)
+	}
+	
+	var list domain.FAQEntryList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, errors.InternalError("failed to decode response", err)
+	}
+	
+	return &list, nil
+}
+
+// CreateFAQEntry registers a canonical question and answer for a tenant
+func (c *HTTPRouterClient) CreateFAQEntry(ctx context.Context, tenantID domain.TenantID, req *domain.CreateFAQEntryRequest) (*domain.FAQEntry, error) {
+	url := fmt.Sprintf("%s/internal/v1/faq/%s", c.baseURL, neturl.PathEscape(string(tenantID)))
+	
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.InternalError("failed to marshal request", err)
+	}
+	
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, errors.InternalError("failed to create request", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, errors.InternalError("router request failed", err)
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusCreated {
+		return nil, c.handleHTTPError(resp)
+	}
+	
+	var entry domain.FAQEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entry); err != nil {
+		return nil, errors.InternalError("failed to decode response", err)
+	}
+	
+	return &entry, nil
+}
+
+// DeleteFAQEntry removes one of a tenant's FAQ entries
+func (c *HTTPRouterClient) DeleteFAQEntry(ctx context.Context, tenantID domain.TenantID, id string) error {
+	url := fmt.Sprintf("%s/internal/v1/faq/%s/%s", c.baseURL, neturl.PathEscape(string(tenantID)), neturl.PathEscape(id))
+	
+	httpReq, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
+	if err != nil {
+		return errors.InternalError("failed to create request", err)
+	}
+	
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return errors.InternalError("router request failed", err)
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusNoContent {
+		return c.handleHTTPError(resp)
+	}
+	return nil
+}
+
+// ListKillSwitches lists the kill switches in effect
+func (c *HTTPRouterClient) ListKillSwitches(ctx context.Context) (*domain.KillSwitchList, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/internal/v1/kill-switches", nil)
+	if err != nil {
+		return nil, errors.InternalError("failed to create request", err)
+	}
+	
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, errors.InternalError("router request failed", err)
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleHTTPError(resp)
+	}
+	
+	var list domain.KillSwitchList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, errors.InternalError("failed to decode response", err)
+	}
+	
+	return &list, nil
+}
+
+// SetKillSwitch turns a kill switch on or off on every router replica
+func (c *HTTPRouterClient) SetKillSwitch(ctx context.Context, req *domain.SetKillSwitchRequest) (*domain.KillSwitchList, error) {
+	jsonData, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.InternalError("failed to marshal request", err)
+	}
+	
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/internal/v1/kill-switches", bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, errors.InternalError("failed to create request", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, errors.InternalError("router request failed", err)
+	}
+	defer resp.Body.Close()
+	
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.handleHTTPError(resp)
+	}
+	
+	var list domain.KillSwitchList
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, errors.InternalError("failed to decode response", err)
+	}
+	
+	return &list, nil
+}
+
+// handleHTTPError converts HTTP errors to QLens errors. The router's own
+// error body is kept when it has one, so provider details such as the
+// rejected param reach the client.
 func (c *HTTPRouterClient) handleHTTPError(resp *http.Response) error {
+	var errResp struct {
+		Error *errors.QLensError `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&errResp); err == nil && errResp.Error != nil && errResp.Error.Message != "" {
+		routerErr := errResp.Error
+		return errors.NewError(routerErr.Type, routerErr.Message).
+			WithCode(routerErr.Code).
+			WithDetails(routerErr.Details).
+			WithStatusCode(resp.StatusCode).
+			WithRetryable(resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable).
+			Build()
+	}
+
 	switch resp.StatusCode {
 	case http.StatusBadRequest:
 		return errors.ValidationError("router service: bad request", "request")
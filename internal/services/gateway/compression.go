@@ -0,0 +1,172 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+const defaultMaxDecompressedBodyBytes = 10 * 1024 * 1024 // 10MB
+
+// compressionMiddleware decompresses gzip request bodies and gzip-encodes
+// responses for clients that accept it. SSE streams are never compressed.
+func (s *Service) compressionMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.currentConfig().CompressionEnabled {
+			c.Next()
+			return
+		}
+
+		if strings.Contains(strings.ToLower(c.GetHeader("Content-Encoding")), "gzip") {
+			if err := s.decompressRequestBody(c); err != nil {
+				s.respondWithError(c, err)
+				c.Abort()
+				return
+			}
+		}
+
+		if !acceptsGzip(c.GetHeader("Accept-Encoding")) {
+			c.Next()
+			return
+		}
+
+		writer := &gzipResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		defer writer.Close()
+
+		c.Next()
+	}
+}
+
+// decompressRequestBody replaces the request body with its decompressed form,
+// refusing bodies that expand beyond the configured limit
+func (s *Service) decompressRequestBody(c *gin.Context) error {
+	limit := s.currentConfig().MaxDecompressedBodyBytes
+	if limit <= 0 {
+		limit = defaultMaxDecompressedBodyBytes
+	}
+
+	reader, err := gzip.NewReader(c.Request.Body)
+	if err != nil {
+		return errors.ValidationError("invalid gzip request body", "body")
+	}
+	defer reader.Close()
+
+	// Read one byte past the limit so oversized bodies can be detected
+	body, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	if err != nil {
+		return errors.ValidationError("invalid gzip request body", "body")
+	}
+	if int64(len(body)) > limit {
+		return errors.NewError(errors.ErrorTypeValidation, fmt.Sprintf("decompressed request body exceeds %d bytes", limit)).
+			WithCode(errors.CodeRequestBodyTooLarge).
+			WithDetail("field", "body").
+			WithStatusCode(http.StatusRequestEntityTooLarge).
+			Build()
+	}
+
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	c.Request.ContentLength = int64(len(body))
+	c.Request.Header.Del("Content-Encoding")
+	c.Request.Header.Del("Content-Length")
+
+	return nil
+}
+
+// acceptsGzip reports whether an Accept-Encoding header lets the response be
+// gzipped: gzip, or * when gzip is not listed, with a q-value above zero
+func acceptsGzip(acceptEncoding string) bool {
+	gzipQuality, wildcardQuality := -1.0, -1.0
+	for _, encoding := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(encoding, ";")
+		switch name = strings.TrimSpace(name); {
+		case strings.EqualFold(name, "gzip"):
+			gzipQuality = encodingQuality(params)
+		case name == "*":
+			wildcardQuality = encodingQuality(params)
+		}
+	}
+
+	if gzipQuality >= 0 {
+		return gzipQuality > 0
+	}
+	return wildcardQuality > 0
+}
+
+// encodingQuality returns the q-value among an Accept-Encoding entry's
+// parameters, 1 when it has none. A malformed q-value counts as a refusal.
+func encodingQuality(params string) float64 {
+	for _, param := range strings.Split(params, ";") {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(key), "q") {
+			continue
+		}
+		quality, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || quality < 0 || quality > 1 {
+			return 0
+		}
+		return quality
+	}
+	return 1
+}
+
+// gzipResponseWriter defers the compression decision until the first body
+// write, when the handler has set the response content type
+type gzipResponseWriter struct {
+	gin.ResponseWriter
+	gz       *gzip.Writer
+	decided  bool
+	compress bool
+}
+
+func (w *gzipResponseWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+
+	header := w.Header()
+	if strings.HasPrefix(header.Get("Content-Type"), "text/event-stream") || header.Get("Content-Encoding") != "" {
+		return
+	}
+
+	w.compress = true
+	header.Set("Content-Encoding", "gzip")
+	header.Add("Vary", "Accept-Encoding")
+	header.Del("Content-Length")
+	w.gz = gzip.NewWriter(w.ResponseWriter)
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	w.decide()
+	if w.compress {
+		return w.gz.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *gzipResponseWriter) WriteString(data string) (int, error) {
+	return w.Write([]byte(data))
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	w.ResponseWriter.Flush()
+}
+
+// Close flushes any buffered compressed output
+func (w *gzipResponseWriter) Close() error {
+	if w.gz != nil {
+		return w.gz.Close()
+	}
+	return nil
+}
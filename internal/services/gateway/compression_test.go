@@ -0,0 +1,156 @@
+package gateway
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func newCompressionTestRouter(maxBytes int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	service := &Service{
+		config: &env.Config{CompressionEnabled: true, MaxDecompressedBodyBytes: maxBytes},
+		logger: logger.NewNoop(),
+	}
+
+	router := gin.New()
+	router.Use(service.compressionMiddleware())
+	router.POST("/echo", func(c *gin.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.JSON(http.StatusOK, gin.H{"body": string(body)})
+	})
+	router.GET("/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.Writer.Write([]byte("data: [DONE]\n\n"))
+		c.Writer.Flush()
+	})
+	return router
+}
+
+func gzipBytes(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	_, err := gz.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+func TestCompressionMiddleware_DecompressesRequest(t *testing.T) {
+	router := newCompressionTestRouter(1024)
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader(gzipBytes(t, []byte("hello"))))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"body":"hello"`)
+}
+
+func TestCompressionMiddleware_RejectsSizeBomb(t *testing.T) {
+	router := newCompressionTestRouter(1024)
+
+	payload := gzipBytes(t, bytes.Repeat([]byte("a"), 64*1024))
+	req := httptest.NewRequest(http.MethodPost, "/echo", bytes.NewReader(payload))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	assert.Contains(t, w.Body.String(), errors.CodeRequestBodyTooLarge)
+	info, ok := errors.DescribeErrorCode(errors.ErrorTypeValidation, errors.CodeRequestBodyTooLarge)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, info.StatusCode)
+}
+
+func TestCompressionMiddleware_RejectsInvalidGzip(t *testing.T) {
+	router := newCompressionTestRouter(1024)
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("not gzip"))
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCompressionMiddleware_CompressesResponse(t *testing.T) {
+	router := newCompressionTestRouter(1024)
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("plain"))
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	reader, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"body":"plain"`)
+}
+
+func TestCompressionMiddleware_SkipsEventStreams(t *testing.T) {
+	router := newCompressionTestRouter(1024)
+
+	req := httptest.NewRequest(http.MethodGet, "/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Equal(t, "data: [DONE]\n\n", w.Body.String())
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"GZIP", true},
+		{"deflate, gzip;q=0.5", true},
+		{"gzip;q=0", false},
+		{"gzip; q=0.000", false},
+		{"gzip;Q=0", false},
+		{"gzip;q=0.001", true},
+		{"gzip;level=1;q=0", false},
+		{"gzip;q=invalid", false},
+		{"gzip;q=2", false},
+		{"identity", false},
+		{"*", true},
+		{"*;q=0", false},
+		{"*, gzip;q=0", false},
+		{"gzip;q=0.8, *;q=0", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, acceptsGzip(tt.header), "Accept-Encoding: %q", tt.header)
+	}
+}
+
+func TestCompressionMiddleware_HonoursGzipRefusal(t *testing.T) {
+	router := newCompressionTestRouter(1024)
+
+	req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader("plain"))
+	req.Header.Set("Accept-Encoding", "gzip;q=0, identity")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Body.String(), `"body":"plain"`)
+}
@@ -0,0 +1,16 @@
+package gateway
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// setContextUtilizationHeader passes on the router's context window warning,
+// when the response carries one
+func setContextUtilizationHeader(c *gin.Context, response *domain.CompletionResponse) {
+	utilization, ok := response.Metadata[domain.MetadataKeyContextUtilization].(float64)
+	if !ok {
+		return
+	}
+	c.Header(domain.ContextUtilizationHeader, domain.ContextUtilizationWarning(utilization))
+}
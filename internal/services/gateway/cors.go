@@ -0,0 +1,49 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// corsAllowedHeaders are the request headers browsers may send to the API
+var corsAllowedHeaders = []string{"Origin", "Content-Type", "Authorization", "X-API-Key", "X-User-ID", "X-Tenant-ID", "X-Request-ID"}
+
+// corsMiddleware answers CORS preflights and sets the CORS headers on every
+// response, matching the gateway's standalone server
+func (s *Service) corsMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Access-Control-Allow-Origin", "*")
+		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		c.Header("Access-Control-Allow-Headers", corsAllowHeaders(c.GetHeader("Access-Control-Request-Headers")))
+		c.Header("Access-Control-Expose-Headers", "X-Request-ID, X-Provider-Request-ID")
+
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
+
+// corsAllowHeaders returns the headers a preflight asked for that the API
+// accepts, in the spelling the browser used, or every accepted header when
+// the preflight lists none
+func corsAllowHeaders(requested string) string {
+	if requested == "" {
+		return strings.Join(corsAllowedHeaders, ", ")
+	}
+
+	var allowed []string
+	for _, name := range strings.Split(requested, ",") {
+		name = strings.TrimSpace(name)
+		for _, accepted := range corsAllowedHeaders {
+			if strings.EqualFold(name, accepted) {
+				allowed = append(allowed, name)
+				break
+			}
+		}
+	}
+	return strings.Join(allowed, ", ")
+}
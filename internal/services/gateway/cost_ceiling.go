@@ -0,0 +1,125 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// costCeiling tracks the estimated cost of a request against its
+// MaxCostUSD. Token counts are estimates, so the ceiling is enforced to
+// within one streamed chunk.
+type costCeiling struct {
+	limit            float64
+	pricing          domain.ModelPricing
+	promptTokens     int
+	completionTokens int
+}
+
+// newCostCeiling prices the request's model and rejects the request when
+// its estimated cost already exceeds the ceiling. The configured MaxCostUSD
+// replaces a higher ceiling or none. It returns nil when no ceiling applies.
+//
+// Non-streaming requests cannot be cut off part way, so when they do not set
+// max_tokens it is capped to what the remaining budget pays for.
+func (s *Service) newCostCeiling(ctx context.Context, req *domain.CompletionRequest) (*costCeiling, error) {
+	if limit := s.currentConfig().MaxCostUSD; limit > 0 && (req.MaxCostUSD <= 0 || limit < req.MaxCostUSD) {
+		req.MaxCostUSD = limit
+	}
+	if req.MaxCostUSD <= 0 {
+		return nil, nil
+	}
+
+	pricing, err := s.modelPricing(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ceiling := &costCeiling{
+		limit:        req.MaxCostUSD,
+		pricing:      pricing,
+		promptTokens: domain.EstimatePromptTokens(req.Messages),
+	}
+
+	estimated := ceiling.estimate()
+	if !req.Stream && req.MaxTokens != nil {
+		estimated += float64(*req.MaxTokens) * pricing.OutputTokenCost
+	}
+	if estimated > ceiling.limit {
+		return nil, ceilingExceededError(estimated, ceiling.limit)
+	}
+
+	if !req.Stream && req.MaxTokens == nil && pricing.OutputTokenCost > 0 {
+		affordable := int(math.Floor((ceiling.limit - estimated) / pricing.OutputTokenCost))
+		if affordable < 1 {
+			return nil, ceilingExceededError(estimated+pricing.OutputTokenCost, ceiling.limit)
+		}
+		req.MaxTokens = &affordable
+	}
+
+	return ceiling, nil
+}
+
+// Add records streamed completion tokens and reports whether the ceiling
+// has been reached
+func (c *costCeiling) Add(tokens int) bool {
+	c.completionTokens += tokens
+	return c.estimate() >= c.limit
+}
+
+// estimate returns the estimated cost of the tokens seen so far
+func (c *costCeiling) estimate() float64 {
+	return c.pricing.CompletionCost(domain.Usage{
+		PromptTokens:     c.promptTokens,
+		CompletionTokens: c.completionTokens,
+	})
+}
+
+// modelPricing looks up the requested model in the router's registry. A
+// ceiling cannot be enforced without pricing, so unknown models are rejected.
+func (s *Service) modelPricing(ctx context.Context, req *domain.CompletionRequest) (domain.ModelPricing, error) {
+	models, err := s.routerClient.ListModels(ctx, &domain.ListModelsOptions{Provider: req.Provider})
+	if err != nil {
+		return domain.ModelPricing{}, errors.InternalError("failed to load model pricing", err)
+	}
+
+	for _, model := range models.Data {
+		if model.ModelID == req.Model {
+			return model.Pricing, nil
+		}
+	}
+
+	validationErr := errors.ValidationError(
+		fmt.Sprintf("max_cost_usd cannot be enforced: no pricing is known for model %s", req.Model), "max_cost_usd")
+	validationErr.Details["model"] = req.Model
+	return domain.ModelPricing{}, validationErr
+}
+
+// applyMaxCostOption honours the X-Max-Cost-USD header. When both the header
+// and the body set a ceiling the lower one applies.
+func (s *Service) applyMaxCostOption(req *domain.CompletionRequest, c *gin.Context) error {
+	header := c.GetHeader("X-Max-Cost-USD")
+	if header == "" {
+		return nil
+	}
+
+	limit, err := strconv.ParseFloat(header, 64)
+	if err != nil || limit <= 0 || math.IsInf(limit, 0) {
+		return errors.ValidationError("X-Max-Cost-USD must be a positive number", "X-Max-Cost-USD")
+	}
+
+	if req.MaxCostUSD <= 0 || limit < req.MaxCostUSD {
+		req.MaxCostUSD = limit
+	}
+	return nil
+}
+
+func ceilingExceededError(estimated, limit float64) *errors.QLensError {
+	return errors.ValidationError(
+		fmt.Sprintf("estimated cost $%.6f exceeds max_cost_usd $%.6f", estimated, limit), "max_cost_usd")
+}
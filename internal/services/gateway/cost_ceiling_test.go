@@ -0,0 +1,175 @@
+package gateway
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// ctxRouterClient remembers the context the stream was opened with
+type ctxRouterClient struct {
+	*fakeRouterClient
+	streamCtx context.Context
+}
+
+func (f *ctxRouterClient) RouteCompletionStream(ctx context.Context, req *domain.CompletionRequest) (<-chan *domain.StreamResponse, error) {
+	f.streamCtx = ctx
+	return f.fakeRouterClient.RouteCompletionStream(ctx, req)
+}
+
+// pricedModel costs $0.001 per output token and nothing for input
+var pricedModel = domain.Model{
+	ModelID: "gpt-4o",
+	Pricing: domain.ModelPricing{OutputTokenCost: 0.001, Unit: "token"},
+}
+
+func costTestRequest(stream bool, maxCost float64) *domain.CompletionRequest {
+	return &domain.CompletionRequest{
+		Model:      "gpt-4o",
+		Stream:     stream,
+		MaxCostUSD: maxCost,
+		Messages:   []domain.Message{{Role: domain.MessageRoleUser, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "hi"}}}},
+	}
+}
+
+func textChunk(text string) *domain.StreamResponse {
+	return &domain.StreamResponse{Choices: []domain.Choice{{
+		Message: domain.Message{Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: text}}},
+	}}}
+}
+
+func TestStreamingCompletion_StopsAtCostCeiling(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Every chunk is ~100 tokens, so the $0.25 ceiling is hit on the third
+	chunk := strings.Repeat("a", 400)
+	router := &ctxRouterClient{fakeRouterClient: &fakeRouterClient{
+		models: []domain.Model{pricedModel},
+		stream: []*domain.StreamResponse{textChunk(chunk), textChunk(chunk), textChunk(chunk), textChunk(chunk), {Done: true}},
+	}}
+	metrics := &fakeMetricsClient{}
+	service := &Service{config: &env.Config{}, logger: logger.NewNoop(), routerClient: router, metricsClient: metrics}
+
+	req := costTestRequest(true, 0.25)
+	ceiling, err := service.newCostCeiling(context.Background(), req)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	service.handleStreamingCompletion(context.Background(), req, ceiling, c)
+
+	body := w.Body.String()
+	assert.Equal(t, 3, strings.Count(body, chunk))
+	assert.Contains(t, body, `"finish_reason":"cost_limit"`)
+	assert.True(t, strings.HasSuffix(body, "data: [DONE]\n\n"))
+	assert.Equal(t, []string{string(domain.StreamOutcomeCostLimit)}, metrics.statuses)
+	assert.ErrorIs(t, router.streamCtx.Err(), context.Canceled, "the upstream stream is cancelled")
+}
+
+func TestNewCostCeiling_NonStreaming(t *testing.T) {
+	service, _ := newCapabilityTestService(pricedModel)
+
+	t.Run("no ceiling", func(t *testing.T) {
+		ceiling, err := service.newCostCeiling(context.Background(), costTestRequest(false, 0))
+		assert.NoError(t, err)
+		assert.Nil(t, ceiling)
+	})
+
+	t.Run("max_tokens over the ceiling is rejected", func(t *testing.T) {
+		req := costTestRequest(false, 0.5)
+		maxTokens := 1000
+		req.MaxTokens = &maxTokens
+
+		_, err := service.newCostCeiling(context.Background(), req)
+		require.Error(t, err)
+		assert.True(t, errors.IsType(err, errors.ErrorTypeValidation))
+		assert.Contains(t, err.Error(), "max_cost_usd")
+	})
+
+	t.Run("missing max_tokens is capped to the budget", func(t *testing.T) {
+		req := costTestRequest(false, 0.5)
+
+		_, err := service.newCostCeiling(context.Background(), req)
+		require.NoError(t, err)
+		require.NotNil(t, req.MaxTokens)
+		assert.Equal(t, 500, *req.MaxTokens)
+	})
+
+	t.Run("prompt tokens are estimated as the router estimates them", func(t *testing.T) {
+		req := costTestRequest(true, 0.5)
+		req.Messages = append(req.Messages, domain.Message{
+			Role:    domain.MessageRoleUser,
+			Content: []domain.ContentPart{{Type: domain.ContentTypeImageURL, ImageURL: &domain.ImageURL{URL: "https://example.com/cat.png"}}},
+		})
+
+		ceiling, err := service.newCostCeiling(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, domain.EstimatePromptTokens(req.Messages), ceiling.promptTokens)
+	})
+
+	t.Run("unpriced models cannot be capped", func(t *testing.T) {
+		req := costTestRequest(false, 0.5)
+		req.Model = "unknown-model"
+
+		_, err := service.newCostCeiling(context.Background(), req)
+		assert.True(t, errors.IsType(err, errors.ErrorTypeValidation))
+	})
+}
+
+func TestNewCostCeiling_ConfiguredLimit(t *testing.T) {
+	service, _ := newCapabilityTestService(pricedModel)
+	service.config.MaxCostUSD = 0.5
+
+	// Requests without a ceiling of their own get the configured one
+	req := costTestRequest(false, 0)
+	ceiling, err := service.newCostCeiling(context.Background(), req)
+	require.NoError(t, err)
+	require.NotNil(t, ceiling)
+	assert.Equal(t, 0.5, req.MaxCostUSD)
+	assert.Equal(t, 500, *req.MaxTokens)
+
+	// A lower ceiling is kept, a higher one lowered
+	req = costTestRequest(false, 0.2)
+	_, err = service.newCostCeiling(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 0.2, req.MaxCostUSD)
+
+	req = costTestRequest(false, 5)
+	_, err = service.newCostCeiling(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, req.MaxCostUSD)
+}
+
+func TestApplyMaxCostOption(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := &Service{}
+
+	newContext := func(header string) *gin.Context {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		c.Request.Header.Set("X-Max-Cost-USD", header)
+		return c
+	}
+
+	req := costTestRequest(true, 0.5)
+	require.NoError(t, service.applyMaxCostOption(req, newContext("0.1")))
+	assert.Equal(t, 0.1, req.MaxCostUSD)
+
+	// The lower ceiling wins
+	require.NoError(t, service.applyMaxCostOption(req, newContext("2")))
+	assert.Equal(t, 0.1, req.MaxCostUSD)
+
+	assert.Error(t, service.applyMaxCostOption(req, newContext("-1")))
+	assert.Error(t, service.applyMaxCostOption(req, newContext("cheap")))
+}
@@ -0,0 +1,24 @@
+package gateway
+
+import (
+	"fmt"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// validateProviderEntitlement rejects a request pinning a provider the
+// tenant is not entitled to. Requests leaving the choice to the router are
+// checked there, when the provider is selected.
+func (s *Service) validateProviderEntitlement(tenantID domain.TenantID, provider domain.Provider) error {
+	if provider == "" || provider == domain.ProviderAuto {
+		return nil
+	}
+	if s.currentConfig().ProviderAllowed(string(tenantID), provider) {
+		return nil
+	}
+
+	authErr := errors.AuthorizationError(fmt.Sprintf("tenant is not entitled to provider %s", provider))
+	authErr.Details = map[string]interface{}{"provider": string(provider)}
+	return authErr
+}
@@ -0,0 +1,46 @@
+package gateway
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestValidateProviderEntitlement_RejectsDisallowedPinnedProvider(t *testing.T) {
+	service := &Service{
+		config: &env.Config{TenantProviders: map[string][]domain.Provider{
+			"tenant-a": {domain.ProviderAzureOpenAI},
+		}},
+		logger: logger.NewNoop(),
+	}
+
+	completion := &domain.CompletionRequest{
+		TenantID: "tenant-a",
+		Provider: domain.ProviderAWSBedrock,
+		Model:    "claude-3-sonnet",
+		Messages: []domain.Message{{Role: domain.MessageRoleUser, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "Hi"}}}},
+	}
+	err := service.validateCompletionRequest(completion)
+	require.Error(t, err)
+	assert.True(t, errors.IsType(err, errors.ErrorTypeAuthorization))
+	assert.Equal(t, "aws-bedrock", errors.FromError(err).PublicError().Details["provider"])
+
+	embedding := &domain.EmbeddingRequest{TenantID: "tenant-a", Provider: domain.ProviderAWSBedrock, Model: "titan-embed", Input: []string{"Hi"}}
+	err = service.validateEmbeddingRequest(embedding)
+	assert.True(t, errors.IsType(err, errors.ErrorTypeAuthorization))
+
+	// Entitled providers, routed requests and unrestricted tenants pass
+	completion.Provider = domain.ProviderAzureOpenAI
+	assert.NoError(t, service.validateCompletionRequest(completion))
+	completion.Provider = domain.ProviderAuto
+	assert.NoError(t, service.validateCompletionRequest(completion))
+	completion.Provider = domain.ProviderAWSBedrock
+	completion.TenantID = "tenant-b"
+	assert.NoError(t, service.validateCompletionRequest(completion))
+}
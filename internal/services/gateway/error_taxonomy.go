@@ -0,0 +1,15 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// handleListErrorTypes lists every error type the API returns, with its HTTP
+// status and whether retrying can succeed, for clients to build their error
+// handling from
+func (s *Service) handleListErrorTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": errors.ErrorTypes()})
+}
@@ -0,0 +1,40 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+func TestHandleListErrorTypes(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "qlens.env")
+	require.NoError(t, os.WriteFile(configFile, []byte(""), 0o600))
+	service, _ := newReloadTestService(t, configFile)
+
+	w := httptest.NewRecorder()
+	service.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/errors", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Data []errors.ErrorTypeInfo `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, errors.ErrorTypes(), response.Data)
+
+	byType := make(map[errors.ErrorType]errors.ErrorTypeInfo)
+	for _, info := range response.Data {
+		byType[info.Type] = info
+	}
+	assert.Equal(t, http.StatusBadRequest, byType[errors.ErrorTypeValidation].StatusCode)
+	assert.False(t, byType[errors.ErrorTypeValidation].Retryable)
+	assert.Equal(t, http.StatusServiceUnavailable, byType[errors.ErrorTypeProviderUnavailable].StatusCode)
+	assert.True(t, byType[errors.ErrorTypeProviderUnavailable].Retryable)
+}
@@ -0,0 +1,33 @@
+package gateway
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// applyExplainRoutingOption honours X-Explain-Routing on non-streaming
+// requests when routing explanations are enabled for debugging
+func (s *Service) applyExplainRoutingOption(req *domain.CompletionRequest, c *gin.Context) error {
+	header := c.GetHeader("X-Explain-Routing")
+	if header == "" {
+		return nil
+	}
+
+	explain, err := strconv.ParseBool(header)
+	if err != nil {
+		return errors.ValidationError("X-Explain-Routing must be true or false", "X-Explain-Routing")
+	}
+	if !explain || req.Stream {
+		return nil
+	}
+
+	if !s.currentConfig().DebugExplainRouting {
+		return errors.AuthorizationError("routing explanations are not enabled on this gateway")
+	}
+
+	req.ExplainRouting = true
+	return nil
+}
@@ -0,0 +1,55 @@
+package gateway
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestApplyExplainRoutingOption(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name    string
+		enabled bool
+		stream  bool
+		header  string
+		want    bool
+		errType errors.ErrorType
+	}{
+		{name: "no header", enabled: true},
+		{name: "enabled", enabled: true, header: "true", want: true},
+		{name: "declined", enabled: true, header: "false"},
+		{name: "streaming", enabled: true, stream: true, header: "true"},
+		{name: "disabled on gateway", header: "true", errType: errors.ErrorTypeAuthorization},
+		{name: "invalid header", enabled: true, header: "verbose", errType: errors.ErrorTypeValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &Service{config: &env.Config{DebugExplainRouting: tt.enabled}, logger: logger.NewNoop()}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("POST", "/v1/chat/completions", nil)
+			if tt.header != "" {
+				c.Request.Header.Set("X-Explain-Routing", tt.header)
+			}
+
+			req := &domain.CompletionRequest{Model: "gpt-4", Stream: tt.stream}
+			err := service.applyExplainRoutingOption(req, c)
+			if tt.errType != "" {
+				require.Error(t, err)
+				assert.True(t, errors.IsType(err, tt.errType))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, req.ExplainRouting)
+		})
+	}
+}
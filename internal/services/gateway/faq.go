@@ -0,0 +1,53 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// FAQ entries are curated per tenant: operators register the canonical
+// questions of the tenant in X-Tenant-ID, and completions asking one of them
+// are answered with the stored answer
+
+func (s *Service) handleListFAQEntries(c *gin.Context) {
+	list, err := s.routerClient.ListFAQEntries(c.Request.Context(), domain.TenantID(c.GetString("tenant_id")))
+	if err != nil {
+		s.respondWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (s *Service) handleCreateFAQEntry(c *gin.Context) {
+	var req domain.CreateFAQEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondWithError(c, errors.ValidationError("invalid request format", "body"))
+		return
+	}
+
+	// The question is embedded within the tenant's data-residency region,
+	// which the request cannot loosen
+	tenantID := domain.TenantID(c.GetString("tenant_id"))
+	req.DataResidency = normalizeRegion(req.DataResidency)
+	if region := s.tenantDefaults(tenantID).DataResidency; region != "" {
+		req.DataResidency = region
+	}
+
+	entry, err := s.routerClient.CreateFAQEntry(c.Request.Context(), tenantID, &req)
+	if err != nil {
+		s.respondWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (s *Service) handleDeleteFAQEntry(c *gin.Context) {
+	if err := s.routerClient.DeleteFAQEntry(c.Request.Context(), domain.TenantID(c.GetString("tenant_id")), c.Param("id")); err != nil {
+		s.respondWithError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
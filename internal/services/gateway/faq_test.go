@@ -0,0 +1,48 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+func TestHandleFAQEntries(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "qlens.env")
+	require.NoError(t, os.WriteFile(configFile, []byte(""), 0o600))
+	service, router := newReloadTestService(t, configFile)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-Admin-Key", "admin-secret")
+		req.Header.Set("X-Tenant-ID", "tenant-a")
+		w := httptest.NewRecorder()
+		service.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, "/v1/internal/faq", `{"question": "How do I reset my password?", "answer": "Use the Forgot password link."}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	require.Len(t, router.faq, 1)
+	assert.Equal(t, "How do I reset my password?", router.faq[0].Question)
+
+	w = do(http.MethodGet, "/v1/internal/faq", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list domain.FAQEntryList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	// With auth disabled every request runs as the default tenant
+	assert.Equal(t, domain.TenantID("default"), list.Data[0].TenantID)
+
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/v1/internal/faq/faq_1", "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/v1/internal/faq/faq_1", "").Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/v1/internal/faq", `{`).Code)
+}
@@ -116,6 +116,12 @@ func (s *Service) ListModels(c *gin.Context) {
 // @Security BearerAuth
 // @Security TenantID
 // @Param request body ChatCompletionRequest true "Chat completion request"
+// @Param X-Azure-Api-Version header string false "Pin the Azure OpenAI API version for this request (must be allowlisted)"
+// @Param anthropic-version header string false "Pin the Anthropic API version for this request (must be allowlisted)"
+// @Param X-Include-Raw-Response header bool false "Attach the provider's raw response to metadata.raw_provider_response (debug only, needs X-Admin-Key)"
+// @Param X-Explain-Routing header bool false "Describe the providers considered, the filters that excluded them and why the selected one won in metadata.routing (debug only, non-streaming)"
+// @Param X-Max-Cost-USD header number false "Cost ceiling in USD; streams stop with finish_reason cost_limit once it is reached (the lower of header and max_cost_usd applies)"
+// @Param X-Param-Profile header string false "Named sampling parameter profile, e.g. creative or precise; parameters set in the body take precedence (overrides param_profile)"
 // @Success 200 {object} ChatCompletionResponse "Chat completion response"
 // @Failure 400 {object} ErrorResponse "Bad request"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
@@ -0,0 +1,125 @@
+package gateway
+
+import (
+	"context"
+	goerrors "errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// Clients that give up on a non-streaming or collected request can cancel
+// it with DELETE /v1/requests/:id, naming the request's X-Request-ID, so the
+// provider stops generating a response nobody will read. Requests are
+// tracked in memory by the replica serving them, per tenant, and
+// cancellations are not shared between replicas: with more than one
+// replica, the load balancer must route by request ID, sending the request
+// (by its X-Request-ID header) and its cancellation (by the ID in the path)
+// to the same replica. A cancellation reaching another replica finds
+// nothing in flight and is answered with a 404.
+
+// errCancelledByClient is the cause of a request cancelled through
+// DELETE /v1/requests/:id
+var errCancelledByClient = goerrors.New("request cancelled by the client")
+
+// CancelRequestResponse reports the requests a cancellation stopped
+type CancelRequestResponse struct {
+	ID string `json:"id"`
+	// Cancelled counts the requests in flight under the ID, more than one
+	// when the client reused it
+	Cancelled int `json:"cancelled"`
+}
+
+// trackInFlight registers a request so it can be cancelled by its ID, and
+// returns the context to call upstream with. The returned function forgets
+// the request; call it once the upstream call returns.
+func (s *Service) trackInFlight(ctx context.Context, tenantID, requestID string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	key := tenantID + "/" + requestID
+
+	s.inFlightMu.Lock()
+	if s.inFlight == nil {
+		s.inFlight = make(map[string]map[uint64]context.CancelCauseFunc)
+	}
+	if s.inFlight[key] == nil {
+		s.inFlight[key] = make(map[uint64]context.CancelCauseFunc)
+	}
+	s.inFlightSeq++
+	seq := s.inFlightSeq
+	s.inFlight[key][seq] = cancel
+	s.inFlightMu.Unlock()
+
+	return ctx, func() {
+		s.inFlightMu.Lock()
+		delete(s.inFlight[key], seq)
+		if len(s.inFlight[key]) == 0 {
+			delete(s.inFlight, key)
+		}
+		s.inFlightMu.Unlock()
+		cancel(nil)
+	}
+}
+
+// cancelInFlight cancels a tenant's requests in flight under an ID and
+// returns how many there were
+func (s *Service) cancelInFlight(tenantID, requestID string) int {
+	s.inFlightMu.Lock()
+	defer s.inFlightMu.Unlock()
+
+	requests := s.inFlight[tenantID+"/"+requestID]
+	for _, cancel := range requests {
+		cancel(errCancelledByClient)
+	}
+	return len(requests)
+}
+
+// cancelledByClient turns the error of an upstream call cancelled through
+// DELETE /v1/requests/:id into a cancellation error
+func cancelledByClient(ctx context.Context, operation string, err error) error {
+	if goerrors.Is(context.Cause(ctx), errCancelledByClient) {
+		return errors.CancelledError(operation, err)
+	}
+	return err
+}
+
+// requestStatus is the metrics status of a failed request
+func requestStatus(err error) string {
+	if errors.IsCancellation(err) {
+		return "cancelled"
+	}
+	return "error"
+}
+
+// CancelRequest godoc
+// @Summary Cancel an in-flight request
+// @Description Cancel the non-streaming or collected completion, or embedding request, sent with the given X-Request-ID, aborting the provider call. The cancelled request is answered with a request_cancelled error. Only the gateway replica serving the request can cancel it, so with several replicas the load balancer must route the request and its cancellation to the same replica by request ID.
+// @Tags requests
+// @Produce json
+// @Security BearerAuth
+// @Security TenantID
+// @Param id path string true "Request ID"
+// @Success 200 {object} CancelRequestResponse "Requests cancelled"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "No request in flight with this ID"
+// @Router /v1/requests/{id} [delete]
+func (s *Service) handleCancelRequest(c *gin.Context) {
+	id := c.Param("id")
+	if !isValidRequestID(id) {
+		s.respondWithError(c, errors.ValidationError("invalid request ID", "id"))
+		return
+	}
+
+	cancelled := s.cancelInFlight(c.GetString("tenant_id"), id)
+	if cancelled == 0 {
+		s.respondWithError(c, errors.NotFoundError("in-flight request", id))
+		return
+	}
+
+	s.logger.Info("In-flight request cancelled by the client",
+		logger.F("request_id", id),
+		logger.F("tenant_id", c.GetString("tenant_id")),
+		logger.F("cancelled", cancelled))
+	c.JSON(http.StatusOK, CancelRequestResponse{ID: id, Cancelled: cancelled})
+}
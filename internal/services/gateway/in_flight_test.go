@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// hangingRouterClient blocks every completion until its context ends, the
+// way the HTTP router client fails once its request is cancelled
+type hangingRouterClient struct {
+	fakeRouterClient
+	started chan struct{}
+}
+
+func (h *hangingRouterClient) RouteCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+	h.started <- struct{}{}
+	<-ctx.Done()
+	return nil, errors.InternalError("failed to call router service", ctx.Err())
+}
+
+// RouteCompletionStream opens a stream that sends nothing until its
+// context ends, then fails the way the HTTP router client does
+func (h *hangingRouterClient) RouteCompletionStream(ctx context.Context, req *domain.CompletionRequest) (<-chan *domain.StreamResponse, error) {
+	h.started <- struct{}{}
+	ch := make(chan *domain.StreamResponse, 1)
+	go func() {
+		defer close(ch)
+		<-ctx.Done()
+		ch <- &domain.StreamResponse{Error: errors.CancelledError("completion stream", ctx.Err())}
+	}()
+	return ch, nil
+}
+
+func newInFlightTestRouter(router RouterClient) (*gin.Engine, *fakeMetricsClient) {
+	gin.SetMode(gin.TestMode)
+	metrics := &fakeMetricsClient{}
+	service := &Service{
+		config:        &env.Config{},
+		logger:        logger.NewNoop(),
+		routerClient:  router,
+		metricsClient: metrics,
+	}
+
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set("tenant_id", c.GetHeader("X-Tenant-ID"))
+		c.Set("correlation_id", c.GetHeader(requestIDHeader))
+	})
+	engine.POST("/v1/completions", service.handleCreateCompletion)
+	engine.DELETE("/v1/requests/:id", service.handleCancelRequest)
+	return engine, metrics
+}
+
+func cancelRequest(engine *gin.Engine, tenant, id string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodDelete, "/v1/requests/"+id, nil)
+	req.Header.Set("X-Tenant-ID", tenant)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestCancelRequest_AbortsInFlightCompletion(t *testing.T) {
+	router := &hangingRouterClient{started: make(chan struct{}, 1)}
+	engine, metrics := newInFlightTestRouter(router)
+
+	completed := make(chan *httptest.ResponseRecorder)
+	go func() {
+		req := httptest.NewRequest(http.MethodPost, "/v1/completions",
+			strings.NewReader(`{"model":"gpt-4","messages":[{"role":"user","content":"Write a novel"}]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Tenant-ID", "tenant-a")
+		req.Header.Set(requestIDHeader, "req-1")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		completed <- w
+	}()
+	<-router.started
+
+	// Another tenant cannot cancel it
+	assert.Equal(t, http.StatusNotFound, cancelRequest(engine, "tenant-b", "req-1").Code)
+
+	w := cancelRequest(engine, "tenant-a", "req-1")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response CancelRequestResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, CancelRequestResponse{ID: "req-1", Cancelled: 1}, response)
+
+	// The completion is answered as cancelled rather than failed
+	completion := <-completed
+	assert.Equal(t, errors.StatusClientClosedRequest, completion.Code, completion.Body.String())
+	assert.Contains(t, completion.Body.String(), string(errors.ErrorTypeCancelled))
+	assert.Equal(t, []string{"cancelled"}, metrics.statuses)
+
+	// Finished requests are forgotten
+	assert.Equal(t, http.StatusNotFound, cancelRequest(engine, "tenant-a", "req-1").Code)
+}
+
+func TestCancelRequest_RejectsInvalidID(t *testing.T) {
+	engine, _ := newInFlightTestRouter(&fakeRouterClient{})
+	assert.Equal(t, http.StatusBadRequest, cancelRequest(engine, "tenant-a", "req%0Aforged").Code)
+}
+
+func TestTrackInFlight_ForgetsRequestOnceDone(t *testing.T) {
+	service := &Service{logger: logger.NewNoop()}
+
+	ctx, untrack := service.trackInFlight(context.Background(), "tenant-a", "req-1")
+	_, untrackReused := service.trackInFlight(context.Background(), "tenant-a", "req-1")
+	untrackReused()
+	untrack()
+
+	assert.Empty(t, service.inFlight)
+	assert.Zero(t, service.cancelInFlight("tenant-a", "req-1"))
+
+	// A call that failed on its own is not reported as cancelled
+	err := errors.InternalError("failed to call router service", ctx.Err())
+	assert.Equal(t, err, cancelledByClient(ctx, "completion", err))
+}
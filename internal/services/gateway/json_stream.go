@@ -0,0 +1,254 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// partialJSONValidator incrementally checks that streamed text is a valid
+// prefix of a JSON document. It does not build values; the final result is
+// confirmed with a full parse once the stream completes.
+type partialJSONValidator struct {
+	buf    strings.Builder
+	stack  []byte
+	state  jsonScanState
+	failed bool
+
+	inString   bool
+	escaped    bool
+	unicodeHex int
+	literal    string
+	inNumber   bool
+}
+
+type jsonScanState int
+
+const (
+	scanValue jsonScanState = iota
+	scanValueOrClose
+	scanKey
+	scanKeyOrClose
+	scanColon
+	scanCommaOrClose
+	scanDone
+)
+
+func newPartialJSONValidator() *partialJSONValidator {
+	return &partialJSONValidator{state: scanValue}
+}
+
+// Write feeds the next chunk of output and reports whether the accumulated
+// text is still a valid JSON prefix
+func (v *partialJSONValidator) Write(chunk string) bool {
+	v.buf.WriteString(chunk)
+	for i := 0; i < len(chunk) && !v.failed; i++ {
+		v.step(chunk[i])
+	}
+	return !v.failed
+}
+
+// Valid reports whether everything written so far is a valid JSON prefix
+func (v *partialJSONValidator) Valid() bool {
+	return !v.failed
+}
+
+// Result performs the final validation of the complete output
+func (v *partialJSONValidator) Result(format *domain.ResponseFormat) *domain.JSONValidation {
+	text := v.buf.String()
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return &domain.JSONValidation{Valid: false, Error: err.Error()}
+	}
+
+	if format != nil && format.Type == domain.ResponseFormatJSONSchema {
+		if err := checkRequiredProperties(value, format.JSONSchema); err != nil {
+			return &domain.JSONValidation{Valid: false, Error: err.Error()}
+		}
+	}
+
+	return &domain.JSONValidation{Valid: true}
+}
+
+func (v *partialJSONValidator) step(ch byte) {
+	if v.inString {
+		v.stepString(ch)
+		return
+	}
+
+	if v.literal != "" {
+		if ch != v.literal[0] {
+			v.failed = true
+			return
+		}
+		v.literal = v.literal[1:]
+		if v.literal == "" {
+			v.endValue()
+		}
+		return
+	}
+
+	if v.inNumber {
+		if strings.IndexByte("0123456789+-.eE", ch) >= 0 {
+			return
+		}
+		v.inNumber = false
+		v.endValue()
+	}
+
+	if ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' {
+		return
+	}
+
+	switch v.state {
+	case scanValue, scanValueOrClose:
+		if v.state == scanValueOrClose && ch == ']' {
+			v.closeContainer('[')
+			return
+		}
+		v.beginValue(ch)
+	case scanKey, scanKeyOrClose:
+		switch {
+		case ch == '"':
+			v.inString = true
+		case ch == '}' && v.state == scanKeyOrClose:
+			v.closeContainer('{')
+		default:
+			v.failed = true
+		}
+	case scanColon:
+		if ch != ':' {
+			v.failed = true
+			return
+		}
+		v.state = scanValue
+	case scanCommaOrClose:
+		top := v.stack[len(v.stack)-1]
+		switch {
+		case ch == ',' && top == '{':
+			v.state = scanKey
+		case ch == ',' && top == '[':
+			v.state = scanValue
+		case ch == '}' || ch == ']':
+			v.closeContainer(openerFor(ch))
+		default:
+			v.failed = true
+		}
+	case scanDone:
+		v.failed = true
+	}
+}
+
+func (v *partialJSONValidator) stepString(ch byte) {
+	switch {
+	case v.unicodeHex > 0:
+		if !isHexDigit(ch) {
+			v.failed = true
+			return
+		}
+		v.unicodeHex--
+	case v.escaped:
+		v.escaped = false
+		switch ch {
+		case '"', '\\', '/', 'b', 'f', 'n', 'r', 't':
+		case 'u':
+			v.unicodeHex = 4
+		default:
+			v.failed = true
+		}
+	case ch == '\\':
+		v.escaped = true
+	case ch == '"':
+		v.inString = false
+		if v.state == scanKey || v.state == scanKeyOrClose {
+			v.state = scanColon
+		} else {
+			v.endValue()
+		}
+	case ch < 0x20:
+		v.failed = true
+	}
+}
+
+func (v *partialJSONValidator) beginValue(ch byte) {
+	switch {
+	case ch == '{':
+		v.stack = append(v.stack, '{')
+		v.state = scanKeyOrClose
+	case ch == '[':
+		v.stack = append(v.stack, '[')
+		v.state = scanValueOrClose
+	case ch == '"':
+		v.inString = true
+	case ch == 't':
+		v.literal = "rue"
+	case ch == 'f':
+		v.literal = "alse"
+	case ch == 'n':
+		v.literal = "ull"
+	case ch == '-' || (ch >= '0' && ch <= '9'):
+		v.inNumber = true
+	default:
+		v.failed = true
+	}
+}
+
+func (v *partialJSONValidator) closeContainer(opener byte) {
+	if len(v.stack) == 0 || v.stack[len(v.stack)-1] != opener {
+		v.failed = true
+		return
+	}
+	v.stack = v.stack[:len(v.stack)-1]
+	v.endValue()
+}
+
+func (v *partialJSONValidator) endValue() {
+	if len(v.stack) == 0 {
+		v.state = scanDone
+		return
+	}
+	v.state = scanCommaOrClose
+}
+
+func openerFor(closer byte) byte {
+	if closer == '}' {
+		return '{'
+	}
+	return '['
+}
+
+func isHexDigit(ch byte) bool {
+	return (ch >= '0' && ch <= '9') || (ch >= 'a' && ch <= 'f') || (ch >= 'A' && ch <= 'F')
+}
+
+// checkRequiredProperties verifies top-level required properties declared by
+// a json_schema response format. Accepts both the OpenAI envelope
+// ({"name": ..., "schema": {...}}) and a bare schema.
+func checkRequiredProperties(value interface{}, jsonSchema map[string]interface{}) error {
+	schema := jsonSchema
+	if inner, ok := jsonSchema["schema"].(map[string]interface{}); ok {
+		schema = inner
+	}
+
+	required, _ := schema["required"].([]interface{})
+	if len(required) == 0 {
+		return nil
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("expected a JSON object")
+	}
+
+	for _, name := range required {
+		key, _ := name.(string)
+		if _, exists := object[key]; !exists {
+			return fmt.Errorf("missing required property: %s", key)
+		}
+	}
+
+	return nil
+}
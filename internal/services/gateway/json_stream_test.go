@@ -0,0 +1,118 @@
+package gateway
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestPartialJSONValidator_ValidPrefixes(t *testing.T) {
+	chunks := []string{`{"na`, `me": "Ada", "tags": [1, -2.5e3, tr`, `ue, null], "nested": {"a\u00`, `e9": {}}`, `}`}
+
+	v := newPartialJSONValidator()
+	for _, chunk := range chunks {
+		assert.True(t, v.Write(chunk), "chunk %q should keep the prefix valid", chunk)
+	}
+
+	result := v.Result(&domain.ResponseFormat{Type: domain.ResponseFormatJSONObject})
+	assert.True(t, result.Valid)
+	assert.Empty(t, result.Error)
+}
+
+func TestPartialJSONValidator_InvalidPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+	}{
+		{"missing colon", []string{`{"a" 1`}},
+		{"bad literal", []string{`{"a": tru`, `x`}},
+		{"mismatched close", []string{`[1, 2}`}},
+		{"trailing data", []string{`{}`, ` {}`}},
+		{"unquoted key", []string{`{a: 1}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newPartialJSONValidator()
+			valid := true
+			for _, chunk := range tt.chunks {
+				valid = v.Write(chunk)
+			}
+			assert.False(t, valid)
+			assert.False(t, v.Result(nil).Valid)
+		})
+	}
+}
+
+func TestPartialJSONValidator_IncompleteOutputFailsFinalValidation(t *testing.T) {
+	v := newPartialJSONValidator()
+	assert.True(t, v.Write(`{"answer": "4`))
+
+	result := v.Result(&domain.ResponseFormat{Type: domain.ResponseFormatJSONObject})
+	assert.False(t, result.Valid)
+	assert.NotEmpty(t, result.Error)
+}
+
+func TestPartialJSONValidator_SchemaRequiredProperties(t *testing.T) {
+	format := &domain.ResponseFormat{
+		Type: domain.ResponseFormatJSONSchema,
+		JSONSchema: map[string]interface{}{
+			"name": "answer",
+			"schema": map[string]interface{}{
+				"type":     "object",
+				"required": []interface{}{"answer", "confidence"},
+			},
+		},
+	}
+
+	v := newPartialJSONValidator()
+	v.Write(`{"answer": "4"}`)
+	result := v.Result(format)
+	assert.False(t, result.Valid)
+	assert.Contains(t, result.Error, "confidence")
+
+	v = newPartialJSONValidator()
+	v.Write(`{"answer": "4", "confidence": 0.9}`)
+	assert.True(t, v.Result(format).Valid)
+}
+
+func TestStreamingCompletion_ValidationRidesOnFinalEvent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	chunk := &domain.StreamResponse{Choices: []domain.Choice{{Message: domain.Message{
+		Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: `{"ok": true}`}},
+	}}}}
+	final := &domain.StreamResponse{Final: true, Choices: []domain.Choice{{FinishReason: domain.FinishReasonStop}}}
+	service := &Service{
+		config:        &env.Config{},
+		logger:        logger.NewNoop(),
+		routerClient:  &fakeRouterClient{stream: []*domain.StreamResponse{chunk, final, {Done: true}}},
+		metricsClient: &fakeMetricsClient{},
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/v1/completions", nil)
+	req := &domain.CompletionRequest{
+		Model:          "gpt-4",
+		Stream:         true,
+		StreamOptions:  &domain.StreamOptions{PartialJSON: true},
+		ResponseFormat: &domain.ResponseFormat{Type: domain.ResponseFormatJSONObject},
+	}
+	service.handleStreamingCompletion(context.Background(), req, nil, c)
+
+	// No separate validation event follows the final one
+	events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	require.Len(t, events, 3)
+	assert.Contains(t, events[1], `"final":true`)
+	assert.Contains(t, events[1], `"validation":{"valid":true`)
+	assert.Equal(t, "data: [DONE]", events[2])
+}
@@ -0,0 +1,36 @@
+package gateway
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// Kill switches stop all traffic to a provider or model during an incident;
+// the router shares them between its replicas
+
+func (s *Service) handleListKillSwitches(c *gin.Context) {
+	list, err := s.routerClient.ListKillSwitches(c.Request.Context())
+	if err != nil {
+		s.respondWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+func (s *Service) handleSetKillSwitch(c *gin.Context) {
+	var req domain.SetKillSwitchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondWithError(c, errors.ValidationError("invalid request format", "body"))
+		return
+	}
+
+	list, err := s.routerClient.SetKillSwitch(c.Request.Context(), &req)
+	if err != nil {
+		s.respondWithError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
@@ -0,0 +1,38 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+func TestHandleKillSwitches(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "qlens.env")
+	require.NoError(t, os.WriteFile(configFile, []byte(""), 0o600))
+	service, router := newReloadTestService(t, configFile)
+
+	do := func(method, body, adminKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/v1/internal/kill-switches", strings.NewReader(body))
+		req.Header.Set("X-Admin-Key", adminKey)
+		w := httptest.NewRecorder()
+		service.router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := do(http.MethodPost, `{"provider": "openai", "model": "gpt-4o", "killed": true}`, "admin-secret")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, []domain.KillSwitch{{Provider: domain.ProviderOpenAI, Model: "gpt-4o"}}, router.killed)
+	assert.JSONEq(t, `{"data":[{"provider":"openai","model":"gpt-4o"}],"configured":[]}`, do(http.MethodGet, "", "admin-secret").Body.String())
+
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, `{`, "admin-secret").Code)
+	assert.NotEqual(t, http.StatusOK, do(http.MethodPost, `{"provider": "openai", "killed": true}`, "wrong").Code)
+	assert.Len(t, router.killed, 1)
+}
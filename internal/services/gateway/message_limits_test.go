@@ -0,0 +1,49 @@
+package gateway
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestValidateCompletionRequest_MessageLimits(t *testing.T) {
+	service := &Service{
+		config: &env.Config{MaxMessages: 3, MaxContentBytes: 100},
+		logger: logger.NewNoop(),
+	}
+
+	request := func(count int, text string) *domain.CompletionRequest {
+		req := &domain.CompletionRequest{Model: "gpt-4"}
+		for i := 0; i < count; i++ {
+			req.Messages = append(req.Messages, domain.Message{
+				Role:    domain.MessageRoleUser,
+				Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: text}},
+			})
+		}
+		return req
+	}
+
+	require.NoError(t, service.validateCompletionRequest(request(3, strings.Repeat("a", 33))))
+
+	err := service.validateCompletionRequest(request(4, "hi"))
+	require.Error(t, err)
+	assert.True(t, errors.IsType(err, errors.ErrorTypeValidation))
+	assert.Contains(t, err.Error(), "4 messages; at most 3")
+	assert.Equal(t, 3, errors.FromError(err).PublicError().Details["limit"])
+
+	err = service.validateCompletionRequest(request(1, strings.Repeat("a", 101)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "101 bytes; at most 100")
+	assert.Equal(t, "messages", errors.FromError(err).PublicError().Details["field"])
+
+	// Zero disables the limits
+	service.config = &env.Config{}
+	assert.NoError(t, service.validateCompletionRequest(request(50, strings.Repeat("a", 1000))))
+}
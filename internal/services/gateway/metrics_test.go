@@ -0,0 +1,158 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/services/gateway/clients"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestHandleMetrics_ServesPrometheusRegistry(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	metrics, err := clients.NewPrometheusMetricsClient("http://localhost:9090", logger.NewNoop())
+	require.NoError(t, err)
+
+	router := &fakeRouterClient{health: &domain.HealthResponse{
+		Status: "degraded",
+		Providers: map[string]domain.ProviderHealth{
+			"openai":      {Status: "healthy"},
+			"aws-bedrock": {Status: "unhealthy"},
+		},
+	}}
+	service := &Service{
+		config:        env.DetectEnvironment(),
+		logger:        logger.NewNoop(),
+		routerClient:  router,
+		metricsClient: metrics,
+	}
+	service.setupRouter()
+
+	ctx := context.Background()
+	require.NoError(t, metrics.RecordRequest(ctx, "POST", "/v1/chat/completions", "success", 300*time.Millisecond))
+	require.NoError(t, metrics.RecordRequest(ctx, "POST", "/v1/chat/completions", "error", 50*time.Millisecond))
+	require.NoError(t, metrics.RecordProviderRequest(ctx, "openai", "gpt-4o", "success", 250*time.Millisecond, 120))
+
+	// The first scrape starts a health check the next one reports
+	service.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/metrics", nil))
+	waitForHealthRefresh(t, service)
+
+	w := httptest.NewRecorder()
+	service.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/metrics", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(w.Body)
+	require.NoError(t, err)
+
+	// labels returns the label names of the family's first series and the
+	// value of the given label across all its series
+	labels := func(name, label string) ([]string, map[string]float64) {
+		family, ok := families[name]
+		require.True(t, ok, "metric %s is missing", name)
+		require.NotEmpty(t, family.GetMetric())
+
+		var names []string
+		for _, pair := range family.GetMetric()[0].GetLabel() {
+			names = append(names, pair.GetName())
+		}
+		sort.Strings(names)
+
+		values := make(map[string]float64)
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() != label {
+					continue
+				}
+				switch {
+				case metric.GetCounter() != nil:
+					values[pair.GetValue()] = metric.GetCounter().GetValue()
+				case metric.GetGauge() != nil:
+					values[pair.GetValue()] = metric.GetGauge().GetValue()
+				case metric.GetHistogram() != nil:
+					values[pair.GetValue()] = float64(metric.GetHistogram().GetSampleCount())
+				}
+			}
+		}
+		return names, values
+	}
+
+	names, byStatus := labels("qlens_requests_total", "status")
+	assert.Equal(t, []string{"endpoint", "method", "status"}, names)
+	assert.Equal(t, map[string]float64{"success": 1, "error": 1}, byStatus)
+
+	names, byEndpoint := labels("qlens_request_duration_seconds", "endpoint")
+	assert.Equal(t, []string{"endpoint", "method"}, names)
+	assert.Equal(t, map[string]float64{"/v1/chat/completions": 2}, byEndpoint)
+
+	names, byProvider := labels("qlens_provider_tokens_total", "provider")
+	assert.Equal(t, []string{"model", "provider"}, names)
+	assert.Equal(t, map[string]float64{"openai": 120}, byProvider)
+
+	names, _ = labels("qlens_provider_requests_total", "provider")
+	assert.Equal(t, []string{"model", "provider", "status"}, names)
+
+	names, health := labels("qlens_provider_health_status", "provider")
+	assert.Equal(t, []string{"provider"}, names)
+	assert.Equal(t, map[string]float64{"openai": 1, "aws-bedrock": 0}, health)
+}
+
+// waitForHealthRefresh waits for the background provider health check to
+// finish
+func waitForHealthRefresh(t *testing.T, s *Service) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		s.healthRefreshMu.Lock()
+		defer s.healthRefreshMu.Unlock()
+		return !s.healthRefreshed.IsZero() && !s.healthRefreshing
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestHandleMetrics_ChecksHealthOncePerInterval(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := &fakeRouterClient{}
+	service := &Service{
+		config:        env.DetectEnvironment(),
+		logger:        logger.NewNoop(),
+		routerClient:  router,
+		metricsClient: &fakeMetricsClient{},
+	}
+	service.setupRouter()
+
+	for i := 0; i < 5; i++ {
+		service.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	}
+	waitForHealthRefresh(t, service)
+	service.router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, int32(1), router.healthChecks.Load())
+}
+
+func TestHandleMetrics_RequiresAuthentication(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := &fakeRouterClient{}
+	config := env.DetectEnvironment()
+	config.AuthEnabled = true
+	service := &Service{
+		config:        config,
+		logger:        logger.NewNoop(),
+		routerClient:  router,
+		metricsClient: &fakeMetricsClient{},
+	}
+	service.setupRouter()
+
+	w := httptest.NewRecorder()
+	service.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Zero(t, router.healthChecks.Load())
+}
@@ -0,0 +1,103 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// maxComparedModels bounds how many models one comparison may request
+const maxComparedModels = 20
+
+// handleCompareModels godoc
+// @Summary Compare models
+// @Description Side-by-side price per 1K tokens, context length, capabilities, provider and status for the requested models. Unknown model IDs are listed in not_found.
+// @Tags models
+// @Produce json
+// @Security BearerAuth
+// @Security TenantID
+// @Param models query string true "Comma-separated model IDs (up to 20); the parameter may also be repeated"
+// @Success 200 {object} ModelComparisonResponse "Comparison in the order requested"
+// @Failure 400 {object} ErrorResponse "No models or too many models requested"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Router /v1/models/compare [get]
+func (s *Service) handleCompareModels(c *gin.Context) {
+	modelIDs := parseModelIDs(c.QueryArray("models"))
+	if len(modelIDs) == 0 {
+		s.respondWithError(c, errors.ValidationError("models is required", "models"))
+		return
+	}
+	if len(modelIDs) > maxComparedModels {
+		s.respondWithError(c, errors.ValidationError(fmt.Sprintf("at most %d models can be compared", maxComparedModels), "models"))
+		return
+	}
+
+	models, err := s.routerClient.ListModels(c.Request.Context(), &domain.ListModelsOptions{})
+	if err != nil {
+		s.respondWithError(c, err)
+		return
+	}
+
+	registry := make(map[string]domain.Model, len(models.Data))
+	for _, model := range models.Data {
+		registry[model.ModelID] = model
+	}
+
+	response := ModelComparisonResponse{Object: "list", Data: []ModelComparison{}}
+	for _, id := range modelIDs {
+		model, ok := registry[id]
+		if !ok {
+			response.NotFound = append(response.NotFound, id)
+			continue
+		}
+		response.Data = append(response.Data, compareModel(model))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// parseModelIDs flattens repeated and comma-separated model IDs, dropping
+// blanks and duplicates while keeping the requested order
+func parseModelIDs(values []string) []string {
+	seen := make(map[string]bool)
+	var ids []string
+	for _, value := range values {
+		for _, part := range strings.Split(value, ",") {
+			id := strings.TrimSpace(part)
+			if id == "" || seen[id] {
+				continue
+			}
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// compareModel normalizes a registry entry so prices quoted per token and
+// per 1K tokens line up
+func compareModel(model domain.Model) ModelComparison {
+	input, output, reasoning := model.Pricing.PerThousandTokens()
+
+	capabilities := make([]string, len(model.Capabilities))
+	for i, capability := range model.Capabilities {
+		capabilities[i] = string(capability)
+	}
+
+	return ModelComparison{
+		ID:                  model.ModelID,
+		Name:                model.Name,
+		Provider:            string(model.Provider),
+		Status:              string(model.Status),
+		ContextLength:       model.ContextLength,
+		Deployment:          string(model.Deployment),
+		InputPricePer1K:     input,
+		OutputPricePer1K:    output,
+		ReasoningPricePer1K: reasoning,
+		Capabilities:        capabilities,
+	}
+}
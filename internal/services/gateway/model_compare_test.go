@@ -0,0 +1,72 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+func compareModels(service *Service, query string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/models/compare?"+query, nil)
+	service.handleCompareModels(c)
+	return w
+}
+
+func TestHandleCompareModels_NormalizesPricing(t *testing.T) {
+	service, _ := newCapabilityTestService(
+		domain.Model{
+			ModelID:       "gpt-4o",
+			Provider:      domain.ProviderAzureOpenAI,
+			Capabilities:  []domain.Capability{domain.CapabilityCompletion, domain.CapabilityVision},
+			ContextLength: 128000,
+			Pricing:       domain.ModelPricing{InputTokenCost: 0.000005, OutputTokenCost: 0.000015, Unit: "token"},
+			Status:        domain.ModelStatusAvailable,
+		},
+		domain.Model{
+			ModelID:  "gpt-4",
+			Provider: domain.ProviderOpenAI,
+			Pricing:  domain.ModelPricing{InputTokenCost: 0.03, OutputTokenCost: 0.06, Unit: domain.PricingUnitThousandTokens},
+		},
+	)
+
+	w := compareModels(service, "models=gpt-4,missing&models=gpt-4o,gpt-4")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response ModelComparisonResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	// Results follow the requested order with duplicates dropped
+	require.Len(t, response.Data, 2)
+	assert.Equal(t, "gpt-4", response.Data[0].ID)
+	assert.InDelta(t, 0.03, response.Data[0].InputPricePer1K, 1e-9)
+	assert.InDelta(t, 0.06, response.Data[0].OutputPricePer1K, 1e-9)
+
+	gpt4o := response.Data[1]
+	assert.Equal(t, "gpt-4o", gpt4o.ID)
+	assert.Equal(t, "azure-openai", gpt4o.Provider)
+	assert.Equal(t, 128000, gpt4o.ContextLength)
+	assert.InDelta(t, 0.005, gpt4o.InputPricePer1K, 1e-9)
+	assert.InDelta(t, 0.015, gpt4o.OutputPricePer1K, 1e-9)
+	assert.InDelta(t, 0.015, gpt4o.ReasoningPricePer1K, 1e-9)
+	assert.Equal(t, []string{"completion", "vision"}, gpt4o.Capabilities)
+
+	assert.Equal(t, []string{"missing"}, response.NotFound)
+}
+
+func TestHandleCompareModels_RequiresModels(t *testing.T) {
+	service, router := newCapabilityTestService()
+
+	w := compareModels(service, "models=,")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Zero(t, router.listCalls)
+}
@@ -0,0 +1,16 @@
+package gateway
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// setModelDeprecationHeader passes on the router's warning that the
+// requested model is deprecated, when the response metadata carries one
+func setModelDeprecationHeader(c *gin.Context, metadata map[string]interface{}) {
+	deprecation, ok := domain.ModelDeprecationFrom(metadata)
+	if !ok {
+		return
+	}
+	c.Header("Warning", deprecation.Warning())
+}
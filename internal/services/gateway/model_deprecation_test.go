@@ -0,0 +1,46 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestCreateEmbeddings_PassesOnModelDeprecation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := &fakeRouterClient{embedding: &domain.EmbeddingResponse{
+		Object: "list",
+		Model:  "text-embedding-3-small",
+		Data:   []domain.Embedding{{Object: "embedding", Embedding: []float64{0.1}}},
+		Metadata: map[string]interface{}{domain.MetadataKeyModelDeprecation: map[string]interface{}{
+			"model": "text-embedding-ada-002", "action": "migrated", "successor": "text-embedding-3-small",
+		}},
+	}}
+	service := &Service{
+		config:        &env.Config{},
+		logger:        logger.NewNoop(),
+		routerClient:  router,
+		metricsClient: &fakeMetricsClient{},
+	}
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) { c.Set("tenant_id", "tenant-a") })
+	engine.POST("/v1/embeddings", service.handleCreateEmbeddings)
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/embeddings",
+		strings.NewReader(`{"model":"text-embedding-ada-002","input":["hello"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `299 - "model text-embedding-ada-002 is deprecated; the request was served by text-embedding-3-small"`, w.Header().Get("Warning"))
+}
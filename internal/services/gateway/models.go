@@ -1,5 +1,14 @@
 package gateway
 
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
 
 // Health check models
 type HealthResponse struct {
@@ -27,8 +36,9 @@ type ErrorDetail struct {
 
 // Models endpoint
 type ModelsResponse struct {
-	Object string  `json:"object" example:"list"`
-	Data   []Model `json:"data"`
+	Object          string  `json:"object" example:"list"`
+	Data            []Model `json:"data"`
+	RegistryVersion string  `json:"registry_version,omitempty" example:"2026-10-01"`
 } // @name ModelsResponse
 
 type Model struct {
@@ -40,7 +50,8 @@ type Model struct {
 
 // Chat completion models
 type ChatCompletionRequest struct {
-	Model            string    `json:"model" binding:"required" example:"gpt-4"`
+	Model            string    `json:"model,omitempty" example:"gpt-4"`
+	Provider         string    `json:"provider,omitempty" example:"azure-openai"`
 	Messages         []Message `json:"messages" binding:"required"`
 	MaxTokens        int       `json:"max_tokens,omitempty" example:"100"`
 	Temperature      float64   `json:"temperature,omitempty" example:"0.7"`
@@ -51,21 +62,104 @@ type ChatCompletionRequest struct {
 	FrequencyPenalty float64   `json:"frequency_penalty,omitempty" example:"0.0"`
 	Stream           bool      `json:"stream,omitempty" example:"false"`
 	User             string    `json:"user,omitempty" example:"user123"`
+	ResponseFormat   *ResponseFormat `json:"response_format,omitempty"`
+	StreamOptions    *StreamOptions  `json:"stream_options,omitempty"`
+	Tools            []Tool          `json:"tools,omitempty"`
+	AzureAPIVersion  string          `json:"azure_api_version,omitempty" example:"2024-06-01"`
+	AnthropicVersion string          `json:"anthropic_version,omitempty" example:"bedrock-2023-05-31"`
+	MaxCostUSD       float64         `json:"max_cost_usd,omitempty" example:"0.05"`
+	ParamProfile     string          `json:"param_profile,omitempty" example:"precise"`
+	VerboseUsage     bool            `json:"verbose_usage,omitempty" example:"false"`
+	Store            *bool           `json:"store,omitempty" example:"true"`
+	Metadata         map[string]interface{} `json:"metadata,omitempty"`
 } // @name ChatCompletionRequest
 
+type Tool struct {
+	Type     string             `json:"type" example:"function"`
+	Function FunctionDefinition `json:"function"`
+} // @name Tool
+
+type FunctionDefinition struct {
+	Name        string                 `json:"name" example:"get_weather"`
+	Description string                 `json:"description,omitempty" example:"Get the current weather"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+} // @name FunctionDefinition
+
+type ResponseFormat struct {
+	Type       string                 `json:"type" example:"json_object" enums:"text,json_object,json_schema"`
+	JSONSchema map[string]interface{} `json:"json_schema,omitempty"`
+} // @name ResponseFormat
+
+type StreamOptions struct {
+	PartialJSON        bool    `json:"partial_json,omitempty" example:"true"`
+	MaxTokensPerSecond float64 `json:"max_tokens_per_second,omitempty" example:"20"`
+} // @name StreamOptions
+
 type Message struct {
 	Role    string `json:"role" example:"user" enums:"system,user,assistant"`
 	Content string `json:"content" example:"Hello, how are you?"`
 	Name    string `json:"name,omitempty" example:"assistant"`
+
+	// Parts holds multi-modal content when content is sent as an array
+	Parts []ContentPart `json:"-"`
 } // @name Message
 
+type ContentPart struct {
+	Type     string    `json:"type" example:"text" enums:"text,image_url"`
+	Text     string    `json:"text,omitempty" example:"What is in this image?"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+} // @name ContentPart
+
+type ImageURL struct {
+	URL    string `json:"url" example:"https://example.com/image.png"`
+	Detail string `json:"detail,omitempty" example:"auto"`
+} // @name ImageURL
+
+// UnmarshalJSON accepts content either as a plain string or as an array of
+// content parts (OpenAI multi-modal format)
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+		Name    string          `json:"name"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	m.Role = raw.Role
+	m.Name = raw.Name
+	m.Content = ""
+	m.Parts = nil
+
+	content := bytes.TrimSpace(raw.Content)
+	if len(content) == 0 || bytes.Equal(content, []byte("null")) {
+		return nil
+	}
+
+	if content[0] == '[' {
+		if err := json.Unmarshal(content, &m.Parts); err != nil {
+			return err
+		}
+		for _, part := range m.Parts {
+			if part.Type == "text" {
+				m.Content += part.Text
+			}
+		}
+		return nil
+	}
+
+	return json.Unmarshal(content, &m.Content)
+}
+
 type ChatCompletionResponse struct {
-	ID      string   `json:"id" example:"chatcmpl-123"`
-	Object  string   `json:"object" example:"chat.completion"`
-	Created int64    `json:"created" example:"1677652288"`
-	Model   string   `json:"model" example:"gpt-4"`
-	Choices []Choice `json:"choices"`
-	Usage   Usage    `json:"usage"`
+	ID         string   `json:"id" example:"chatcmpl-123"`
+	Object     string   `json:"object" example:"chat.completion"`
+	Created    int64    `json:"created" example:"1677652288"`
+	Model      string   `json:"model" example:"gpt-4"`
+	Choices    []Choice `json:"choices"`
+	Usage      Usage    `json:"usage"`
+	ReceivedAt string   `json:"received_at" example:"2023-03-01T06:31:28.123456Z"`
 } // @name ChatCompletionResponse
 
 type Choice struct {
@@ -106,19 +200,34 @@ type CompletionChoice struct {
 
 // Embedding models
 type EmbeddingRequest struct {
-	Input          []string `json:"input" binding:"required" example:"The food was delicious and the waiter..."`
-	Model          string   `json:"model" binding:"required" example:"text-embedding-ada-002"`
-	EncodingFormat string   `json:"encoding_format,omitempty" example:"float"`
-	User           string   `json:"user,omitempty" example:"user123"`
+	Input           []string                  `json:"input" binding:"required" example:"The food was delicious and the waiter..."`
+	Model           string                    `json:"model" binding:"required" example:"text-embedding-ada-002"`
+	EncodingFormat  string                    `json:"encoding_format,omitempty" example:"float"`
+	User            string                    `json:"user,omitempty" example:"user123"`
+	Representations []EmbeddingRepresentation `json:"representations,omitempty"`
 } // @name EmbeddingRequest
 
+type EmbeddingRepresentation struct {
+	EncodingFormat string `json:"encoding_format,omitempty" example:"base64"`
+	Dimensions     int    `json:"dimensions,omitempty" example:"256"`
+	Normalize      bool   `json:"normalize,omitempty" example:"true"`
+} // @name EmbeddingRepresentation
+
 type EmbeddingResponse struct {
-	Object string            `json:"object" example:"list"`
-	Data   []EmbeddingObject `json:"data"`
-	Model  string            `json:"model" example:"text-embedding-ada-002"`
-	Usage  EmbeddingUsage    `json:"usage"`
+	Object          string                        `json:"object" example:"list"`
+	Data            []EmbeddingObject             `json:"data"`
+	Model           string                        `json:"model" example:"text-embedding-ada-002"`
+	Usage           EmbeddingUsage                `json:"usage"`
+	Representations []EmbeddingRepresentationData `json:"representations,omitempty"`
 } // @name EmbeddingResponse
 
+type EmbeddingRepresentationData struct {
+	EncodingFormat string            `json:"encoding_format,omitempty" example:"base64"`
+	Dimensions     int               `json:"dimensions,omitempty" example:"256"`
+	Normalize      bool              `json:"normalize,omitempty" example:"true"`
+	Data           []EmbeddingObject `json:"data"`
+} // @name EmbeddingRepresentationData
+
 type EmbeddingObject struct {
 	Object    string    `json:"object" example:"embedding"`
 	Embedding []float64 `json:"embedding"`
@@ -182,6 +291,33 @@ type MetricsResponse struct {
 	Version      string `json:"version" example:"1.0.0"`
 } // @name MetricsResponse
 
+// Batch models
+type BatchRequest struct {
+	Requests []ChatCompletionRequest `json:"requests" binding:"required"`
+} // @name BatchRequest
+
+type BatchJob struct {
+	ID          string               `json:"id" example:"batch_3f2a9c"`
+	Object      string               `json:"object" example:"batch"`
+	Status      domain.RequestStatus `json:"status" example:"processing" enums:"pending,processing,completed,failed,cancelled"`
+	Total       int                  `json:"total" example:"2"`
+	Completed   int                  `json:"completed" example:"1"`
+	Failed      int                  `json:"failed" example:"0"`
+	Cancelled   int                  `json:"cancelled" example:"0"`
+	Usage       domain.Usage         `json:"usage"`
+	CreatedAt   time.Time            `json:"created_at"`
+	CompletedAt *time.Time           `json:"completed_at,omitempty"`
+	CancelledAt *time.Time           `json:"cancelled_at,omitempty"`
+	Items       []BatchItem          `json:"items"`
+} // @name BatchJob
+
+type BatchItem struct {
+	Index    int                        `json:"index" example:"0"`
+	Status   domain.RequestStatus       `json:"status" example:"completed"`
+	Response *domain.CompletionResponse `json:"response,omitempty"`
+	Error    *errors.QLensError         `json:"error,omitempty"`
+} // @name BatchItem
+
 // Streaming response models
 type StreamResponse struct {
 	ID      string        `json:"id" example:"chatcmpl-123"`
@@ -200,4 +336,90 @@ type StreamChoice struct {
 type StreamMessage struct {
 	Role    string `json:"role,omitempty" example:"assistant"`
 	Content string `json:"content,omitempty" example:"Hello"`
-} // @name StreamMessage
\ No newline at end of file
+} // @name StreamMessage
+// Admin models
+type ReloadResponse struct {
+	Gateway     env.ReloadResult  `json:"gateway"`
+	Router      *env.ReloadResult `json:"router,omitempty"`
+	RouterError string            `json:"router_error,omitempty"`
+
+	// TenantConfigError is set when TENANT_CONFIG_FILE could not be
+	// re-read; the tenant defaults loaded before stay in effect
+	TenantConfigError string `json:"tenant_config_error,omitempty"`
+} // @name ReloadResponse
+
+// ConfigResponse is the effective configuration of the gateway and the
+// router, with secrets redacted
+type ConfigResponse struct {
+	Gateway     *env.Config `json:"gateway"`
+	Router      *env.Config `json:"router,omitempty"`
+	RouterError string      `json:"router_error,omitempty"`
+} // @name ConfigResponse
+
+
+// Model comparison models
+type ModelComparisonResponse struct {
+	Object   string            `json:"object" example:"list"`
+	Data     []ModelComparison `json:"data"`
+	NotFound []string          `json:"not_found,omitempty"`
+} // @name ModelComparisonResponse
+
+type ModelComparison struct {
+	ID                  string   `json:"id" example:"gpt-4o"`
+	Name                string   `json:"name" example:"GPT-4o"`
+	Provider            string   `json:"provider" example:"azure-openai"`
+	Status              string   `json:"status" example:"available"`
+	ContextLength       int      `json:"context_length" example:"128000"`
+	Deployment          string   `json:"deployment,omitempty" example:"on_demand" enums:"on_demand,provisioned"`
+	InputPricePer1K     float64  `json:"input_price_per_1k" example:"0.005"`
+	OutputPricePer1K    float64  `json:"output_price_per_1k" example:"0.015"`
+	ReasoningPricePer1K float64  `json:"reasoning_price_per_1k" example:"0.015"`
+	Capabilities        []string `json:"capabilities"`
+} // @name ModelComparison
+
+// Benchmark models
+type BenchmarkRequest struct {
+	Model       string  `json:"model" binding:"required" example:"gpt-4o"`
+	Provider    string  `json:"provider,omitempty" example:"azure-openai"`
+	Requests    int     `json:"requests,omitempty" example:"20"`
+	Concurrency int     `json:"concurrency,omitempty" example:"4"`
+	MaxTokens   int     `json:"max_tokens,omitempty" example:"64"`
+	Prompt      string  `json:"prompt,omitempty"`
+	MaxCostUSD  float64 `json:"max_cost_usd,omitempty" example:"1.00"`
+
+	// ConfirmProviderLoad must be true: every benchmark request is a real,
+	// billed provider call
+	ConfirmProviderLoad bool `json:"confirm_provider_load" example:"true"`
+} // @name BenchmarkRequest
+
+type BenchmarkResult struct {
+	Model                     string           `json:"model" example:"gpt-4o"`
+	Provider                  string           `json:"provider" example:"azure-openai"`
+	Requested                 int              `json:"requested" example:"20"`
+	Sent                      int              `json:"sent" example:"20"`
+	Concurrency               int              `json:"concurrency" example:"4"`
+	Succeeded                 int              `json:"succeeded" example:"19"`
+	Failed                    int              `json:"failed" example:"1"`
+	ErrorRate                 float64          `json:"error_rate" example:"0.05"`
+	Errors                    map[string]int   `json:"errors,omitempty"`
+	Latency                   BenchmarkLatency `json:"latency"`
+	TokensPerSecond           float64          `json:"tokens_per_second" example:"42.5"`
+	ThroughputTokensPerSecond float64          `json:"throughput_tokens_per_second" example:"160.2"`
+	RequestsPerSecond         float64          `json:"requests_per_second" example:"2.6"`
+	CompletionTokens          int              `json:"completion_tokens" example:"1216"`
+	TotalTokens               int              `json:"total_tokens" example:"1596"`
+	CostUSD                   float64          `json:"cost_usd" example:"0.0201"`
+	EstimatedMaxCostUSD       float64          `json:"estimated_max_cost_usd" example:"0.0238"`
+	DurationMs                float64          `json:"duration_ms" example:"7612.4"`
+	StoppedReason             string           `json:"stopped_reason,omitempty" example:"budget_exceeded" enums:"budget_exceeded,max_cost_reached,cancelled"`
+	Warning                   string           `json:"warning"`
+} // @name BenchmarkResult
+
+type BenchmarkLatency struct {
+	MinMs  float64 `json:"min_ms" example:"812.3"`
+	MeanMs float64 `json:"mean_ms" example:"1490.1"`
+	P50Ms  float64 `json:"p50_ms" example:"1402.7"`
+	P90Ms  float64 `json:"p90_ms" example:"2210.9"`
+	P99Ms  float64 `json:"p99_ms" example:"2675.0"`
+	MaxMs  float64 `json:"max_ms" example:"2675.0"`
+} // @name BenchmarkLatency
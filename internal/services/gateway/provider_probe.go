@@ -0,0 +1,42 @@
+package gateway
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// handleProbeProvider health checks one provider on demand and returns the
+// detailed result, including the provider's raw error, for diagnosing an
+// incident without waiting for the periodic checker
+func (s *Service) handleProbeProvider(c *gin.Context) {
+	provider := domain.Provider(strings.ToLower(c.Param("provider")))
+	if !isValidProviderName(string(provider)) {
+		s.respondWithError(c, errors.ValidationError("invalid provider", "provider"))
+		return
+	}
+
+	probe, err := s.routerClient.ProbeProvider(c.Request.Context(), provider)
+	if err != nil {
+		s.respondWithError(c, err)
+		return
+	}
+	s.metricsClient.RecordProviderHealth(c.Request.Context(), string(provider), probe.Healthy)
+	c.JSON(http.StatusOK, probe)
+}
+
+// isValidProviderName reports whether name is safe to put in a router URL
+func isValidProviderName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for _, r := range name {
+		if !((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
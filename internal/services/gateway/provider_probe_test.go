@@ -0,0 +1,50 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+func TestHandleProbeProvider(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "qlens.env")
+	require.NoError(t, os.WriteFile(configFile, []byte(""), 0o600))
+	service, router := newReloadTestService(t, configFile)
+	router.probe = &domain.ProviderProbe{
+		Provider:   domain.ProviderAzureOpenAI,
+		LatencyMs:  120,
+		StatusCode: http.StatusBadGateway,
+		ErrorType:  "provider_error",
+		Error:      "azure openai: 401 invalid subscription key",
+	}
+
+	get := func(path, adminKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("X-Admin-Key", adminKey)
+		w := httptest.NewRecorder()
+		service.router.ServeHTTP(w, req)
+		return w
+	}
+	assert.Equal(t, http.StatusForbidden, get("/v1/health/providers/azure-openai", "wrong").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/v1/health/providers/azure%3Fx", "admin-secret").Code)
+
+	w := get("/v1/health/providers/Azure-OpenAI", "admin-secret")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var probe domain.ProviderProbe
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &probe))
+	assert.False(t, probe.Healthy)
+	assert.Equal(t, http.StatusBadGateway, probe.StatusCode)
+	assert.Equal(t, "azure openai: 401 invalid subscription key", probe.Error)
+
+	router.probe, router.err = nil, errors.NotFoundError("provider", "gemini")
+	assert.Equal(t, http.StatusNotFound, get("/v1/health/providers/gemini", "admin-secret").Code)
+}
@@ -0,0 +1,204 @@
+package gateway
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// rateLimitWindow is the period rate limits are counted over
+const rateLimitWindow = time.Minute
+
+const (
+	// The X-RateLimit-* headers describe the tenant's request bucket, or its
+	// token bucket when only tokens are limited. Each bucket is also
+	// reported on its own with a -Requests or -Tokens suffix.
+	rateLimitLimitHeader     = "X-RateLimit-Limit"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	rateLimitResetHeader     = "X-RateLimit-Reset"
+
+	// rateLimitProviderHeader names the provider a request named when its
+	// tenant's buckets at that provider are reported, as the same headers
+	// with a -Provider-Requests or -Provider-Tokens suffix
+	rateLimitProviderHeader = "X-RateLimit-Provider"
+
+	// retryAfterHeader tells a rate limited client how many seconds to wait
+	retryAfterHeader = "Retry-After"
+)
+
+// rateWindow counts a tenant's requests and tokens in the current window
+type rateWindow struct {
+	start    time.Time
+	requests int
+	tokens   int
+}
+
+// rateLimitBucket is one limit's state as reported to the client
+type rateLimitBucket struct {
+	suffix    string
+	limit     int
+	remaining int
+}
+
+// rateLimitMiddleware counts each request against its tenant's limits and
+// reports what is left on every response. A tenant over either limit is
+// answered with a 429 and Retry-After until the window resets. Tokens are
+// charged once a response reports or streams them, so a request is only
+// refused after the tokens of earlier ones have used up the window.
+func (s *Service) rateLimitMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		config := s.currentConfig()
+		if config.RateLimitRequestsPerMinute <= 0 && config.RateLimitTokensPerMinute <= 0 {
+			c.Next()
+			return
+		}
+
+		buckets, reset, allowed := s.takeRateLimit(c.GetString("tenant_id"),
+			config.RateLimitRequestsPerMinute, config.RateLimitTokensPerMinute, time.Now())
+		resetSeconds := writeRateLimitHeaders(c, "", buckets, reset)
+		if len(buckets) > 0 {
+			c.Header(rateLimitLimitHeader, strconv.Itoa(buckets[0].limit))
+			c.Header(rateLimitRemainingHeader, strconv.Itoa(buckets[0].remaining))
+			c.Header(rateLimitResetHeader, resetSeconds)
+		}
+
+		if !allowed {
+			c.Header(retryAfterHeader, resetSeconds)
+			limit := config.RateLimitRequestsPerMinute
+			if limit <= 0 {
+				limit = config.RateLimitTokensPerMinute
+			}
+			s.respondWithError(c, errors.RateLimitError(limit, time.Now().Add(reset)))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// takeProviderRateLimit counts a request naming a provider against its
+// tenant's limits at that provider and reports what is left. A tenant over
+// either limit is answered with a 429 and Retry-After, and false returned.
+// Requests leaving the choice of provider to the router are only limited
+// by the tenant's own buckets.
+func (s *Service) takeProviderRateLimit(c *gin.Context, tenantID domain.TenantID, provider domain.Provider) bool {
+	config := s.currentConfig()
+	if config.RateLimitProviderRequestsPerMinute <= 0 && config.RateLimitProviderTokensPerMinute <= 0 {
+		return true
+	}
+	if provider == "" || provider == domain.ProviderAuto {
+		return true
+	}
+
+	buckets, reset, allowed := s.takeRateLimit(providerRateWindowKey(string(tenantID), provider),
+		config.RateLimitProviderRequestsPerMinute, config.RateLimitProviderTokensPerMinute, time.Now())
+	c.Header(rateLimitProviderHeader, string(provider))
+	resetSeconds := writeRateLimitHeaders(c, "-Provider", buckets, reset)
+
+	if !allowed {
+		c.Header(retryAfterHeader, resetSeconds)
+		limit := config.RateLimitProviderRequestsPerMinute
+		if limit <= 0 {
+			limit = config.RateLimitProviderTokensPerMinute
+		}
+		s.respondWithError(c, errors.RateLimitError(limit, time.Now().Add(reset)))
+		return false
+	}
+	return true
+}
+
+// writeRateLimitHeaders reports each bucket in headers suffixed with its
+// scope and kind, returning the seconds until the window resets
+func writeRateLimitHeaders(c *gin.Context, scope string, buckets []rateLimitBucket, reset time.Duration) string {
+	resetSeconds := strconv.Itoa(int(math.Ceil(reset.Seconds())))
+	for _, bucket := range buckets {
+		c.Header(rateLimitLimitHeader+scope+bucket.suffix, strconv.Itoa(bucket.limit))
+		c.Header(rateLimitRemainingHeader+scope+bucket.suffix, strconv.Itoa(bucket.remaining))
+		c.Header(rateLimitResetHeader+scope+bucket.suffix, resetSeconds)
+	}
+	return resetSeconds
+}
+
+// takeRateLimit counts a request against the current window for key, a
+// tenant or a tenant at a provider, if both limits allow it, and returns the
+// buckets left and the time until the window resets. A limit of zero is not
+// enforced or reported.
+func (s *Service) takeRateLimit(key string, requestLimit, tokenLimit int, now time.Time) ([]rateLimitBucket, time.Duration, bool) {
+	s.rateLimitMu.Lock()
+	defer s.rateLimitMu.Unlock()
+
+	window := s.currentRateWindow(key, now)
+	allowed := (requestLimit <= 0 || window.requests < requestLimit) &&
+		(tokenLimit <= 0 || window.tokens < tokenLimit)
+	if allowed {
+		window.requests++
+	}
+
+	var buckets []rateLimitBucket
+	if requestLimit > 0 {
+		buckets = append(buckets, rateLimitBucket{suffix: "-Requests", limit: requestLimit, remaining: max(requestLimit-window.requests, 0)})
+	}
+	if tokenLimit > 0 {
+		buckets = append(buckets, rateLimitBucket{suffix: "-Tokens", limit: tokenLimit, remaining: max(tokenLimit-window.tokens, 0)})
+	}
+	return buckets, window.start.Add(rateLimitWindow).Sub(now), allowed
+}
+
+// chargeRateLimitTokens counts tokens a tenant's request used against its
+// token limit, and against its token limit at the provider the request
+// named, if any
+func (s *Service) chargeRateLimitTokens(tenantID string, provider domain.Provider, tokens int) {
+	config := s.currentConfig()
+	if tokens <= 0 {
+		return
+	}
+	chargeProvider := config.RateLimitProviderTokensPerMinute > 0 && provider != "" && provider != domain.ProviderAuto
+	if config.RateLimitTokensPerMinute <= 0 && !chargeProvider {
+		return
+	}
+
+	s.rateLimitMu.Lock()
+	defer s.rateLimitMu.Unlock()
+	now := time.Now()
+	if config.RateLimitTokensPerMinute > 0 {
+		s.currentRateWindow(tenantID, now).tokens += tokens
+	}
+	if chargeProvider {
+		s.currentRateWindow(providerRateWindowKey(tenantID, provider), now).tokens += tokens
+	}
+}
+
+// currentRateWindow returns the window for key, starting a new one when the
+// last has ended. Ended windows are swept at most once per window, so
+// tenants and providers that went quiet are not kept. The caller holds
+// rateLimitMu.
+func (s *Service) currentRateWindow(key string, now time.Time) *rateWindow {
+	if s.rateWindows == nil {
+		s.rateWindows = make(map[string]*rateWindow)
+	}
+	if now.Sub(s.rateWindowSweep) > rateLimitWindow {
+		s.rateWindowSweep = now
+		for k, window := range s.rateWindows {
+			if !now.Before(window.start.Add(rateLimitWindow)) {
+				delete(s.rateWindows, k)
+			}
+		}
+	}
+
+	window, ok := s.rateWindows[key]
+	if !ok || !now.Before(window.start.Add(rateLimitWindow)) {
+		window = &rateWindow{start: now}
+		s.rateWindows[key] = window
+	}
+	return window
+}
+
+// providerRateWindowKey keys a tenant's window at a provider apart from its
+// own window
+func providerRateWindowKey(tenantID string, provider domain.Provider) string {
+	return tenantID + "/" + string(provider)
+}
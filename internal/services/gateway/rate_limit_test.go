@@ -0,0 +1,170 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func newRateLimitTestRouter(service *Service) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		c.Set("tenant_id", c.GetHeader("X-Tenant-ID"))
+	})
+	engine.Use(service.rateLimitMiddleware())
+	engine.GET("/v1/models", func(c *gin.Context) {
+		provider := domain.Provider(c.Query("provider"))
+		if !service.takeProviderRateLimit(c, domain.TenantID(c.GetString("tenant_id")), provider) {
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	return engine
+}
+
+func serveRateLimited(engine *gin.Engine, tenantID string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/v1/models", nil)
+	req.Header.Set("X-Tenant-ID", tenantID)
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimitMiddleware_Requests(t *testing.T) {
+	service := &Service{config: &env.Config{RateLimitRequestsPerMinute: 2}, logger: logger.NewNoop()}
+	engine := newRateLimitTestRouter(service)
+
+	w := serveRateLimited(engine, "tenant-a")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Remaining-Requests"))
+	assert.Equal(t, "60", w.Header().Get("X-RateLimit-Reset"))
+	assert.Empty(t, w.Header().Get("X-RateLimit-Limit-Tokens"))
+
+	w = serveRateLimited(engine, "tenant-a")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+
+	w = serveRateLimited(engine, "tenant-a")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Tenants have their own windows
+	w = serveRateLimited(engine, "tenant-b")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRateLimitMiddleware_Tokens(t *testing.T) {
+	service := &Service{config: &env.Config{RateLimitRequestsPerMinute: 10, RateLimitTokensPerMinute: 100}, logger: logger.NewNoop()}
+	engine := newRateLimitTestRouter(service)
+
+	w := serveRateLimited(engine, "tenant-a")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "100", w.Header().Get("X-RateLimit-Remaining-Tokens"))
+
+	service.chargeRateLimitTokens("tenant-a", "", 60)
+	w = serveRateLimited(engine, "tenant-a")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "40", w.Header().Get("X-RateLimit-Remaining-Tokens"))
+	assert.Equal(t, "8", w.Header().Get("X-RateLimit-Remaining"))
+
+	service.chargeRateLimitTokens("tenant-a", "", 60)
+	w = serveRateLimited(engine, "tenant-a")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining-Tokens"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestTakeRateLimit_WindowResets(t *testing.T) {
+	service := &Service{config: &env.Config{}}
+	start := time.Now()
+
+	_, _, allowed := service.takeRateLimit("tenant-a", 1, 0, start)
+	assert.True(t, allowed)
+	_, reset, allowed := service.takeRateLimit("tenant-a", 1, 0, start.Add(45*time.Second))
+	assert.False(t, allowed)
+	assert.Equal(t, 15*time.Second, reset)
+
+	buckets, reset, allowed := service.takeRateLimit("tenant-a", 1, 0, start.Add(rateLimitWindow))
+	assert.True(t, allowed)
+	assert.Equal(t, rateLimitWindow, reset)
+	assert.Equal(t, []rateLimitBucket{{suffix: "-Requests", limit: 1, remaining: 0}}, buckets)
+}
+
+func TestRateLimitMiddleware_Disabled(t *testing.T) {
+	service := &Service{config: &env.Config{}, logger: logger.NewNoop()}
+	w := serveRateLimited(newRateLimitTestRouter(service), "tenant-a")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+}
+
+func TestRateLimitMiddleware_ProviderBuckets(t *testing.T) {
+	service := &Service{config: &env.Config{
+		RateLimitRequestsPerMinute:         10,
+		RateLimitProviderRequestsPerMinute: 1,
+		RateLimitProviderTokensPerMinute:   100,
+	}, logger: logger.NewNoop()}
+	engine := newRateLimitTestRouter(service)
+
+	serve := func(provider string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/models?provider="+provider, nil)
+		req.Header.Set("X-Tenant-ID", "tenant-a")
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("openai")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "openai", w.Header().Get("X-RateLimit-Provider"))
+	assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit-Provider-Requests"))
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining-Provider-Requests"))
+	assert.Equal(t, "100", w.Header().Get("X-RateLimit-Remaining-Provider-Tokens"))
+	assert.Equal(t, "9", w.Header().Get("X-RateLimit-Remaining"))
+
+	// Tokens are charged to the named provider's bucket too
+	service.chargeRateLimitTokens("tenant-a", domain.ProviderAzureOpenAI, 30)
+	w = serve("azure-openai")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "70", w.Header().Get("X-RateLimit-Remaining-Provider-Tokens"))
+
+	// Each provider has its own bucket within the tenant's limits
+	w = serve("openai")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+	// Requests leaving the provider to the router only use the tenant's
+	w = serve("auto")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-RateLimit-Provider"))
+	assert.Equal(t, "6", w.Header().Get("X-RateLimit-Remaining"))
+}
+
+func TestCurrentRateWindow_EvictsIdleWindows(t *testing.T) {
+	service := &Service{config: &env.Config{}}
+	start := time.Now()
+
+	service.takeRateLimit("tenant-a", 1, 0, start)
+	service.takeRateLimit(providerRateWindowKey("tenant-a", domain.ProviderOpenAI), 1, 0, start)
+	service.takeRateLimit("tenant-b", 1, 0, start.Add(30*time.Second))
+	require.Len(t, service.rateWindows, 3)
+
+	// Windows that ended are dropped once a window has passed, while a
+	// window still running is kept
+	service.takeRateLimit("tenant-c", 1, 0, start.Add(rateLimitWindow+time.Second))
+	assert.Len(t, service.rateWindows, 2)
+	assert.Contains(t, service.rateWindows, "tenant-b")
+	assert.Contains(t, service.rateWindows, "tenant-c")
+}
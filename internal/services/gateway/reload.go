@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// currentConfig returns the active config. Callers should read it once per
+// request so a concurrent reload cannot change settings part way through.
+func (s *Service) currentConfig() *env.Config {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.config
+}
+
+// reloadConfig applies the safe-to-change fields of next
+func (s *Service) reloadConfig(next *env.Config) env.ReloadResult {
+	s.configMu.Lock()
+	defer s.configMu.Unlock()
+
+	updated, result := env.Reload(s.config, next)
+	s.config = updated
+	return result
+}
+
+// adminMiddleware restricts a route to callers presenting the admin API key.
+// Admin routes are disabled when no key is configured.
+func (s *Service) adminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := s.checkAdminKey(c); err != nil {
+			s.respondWithError(c, err)
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// checkAdminKey rejects callers not presenting the admin API key
+func (s *Service) checkAdminKey(c *gin.Context) error {
+	adminKey := s.currentConfig().AdminAPIKey
+	if adminKey == "" {
+		return errors.AuthorizationError("admin endpoints are disabled")
+	}
+
+	provided := c.GetHeader("X-Admin-Key")
+	if subtle.ConstantTimeCompare([]byte(provided), []byte(adminKey)) != 1 {
+		return errors.AuthorizationError("missing or invalid X-Admin-Key header")
+	}
+	return nil
+}
+
+// handleReloadConfig godoc
+// @Summary Reload configuration
+// @Description Re-read configuration and apply fields that are safe to change at runtime: rate limits, batch limits, cache TTL, model aliases, model pricing, the cost ceiling, provider order and provider enable/disable. Changed fields that need a restart are reported as ignored. Tenant defaults are re-read from TENANT_CONFIG_FILE.
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Key header string true "Admin API key"
+// @Success 200 {object} ReloadResponse "Changed fields per service"
+// @Failure 403 {object} ErrorResponse "Admin key missing or invalid"
+// @Failure 500 {object} ErrorResponse "Configuration could not be read"
+// @Router /v1/internal/reload [post]
+func (s *Service) handleReloadConfig(c *gin.Context) {
+	next, err := env.Reread()
+	if err != nil {
+		s.respondWithError(c, errors.InternalError("failed to read configuration", err))
+		return
+	}
+
+	response := ReloadResponse{Gateway: s.reloadConfig(next)}
+
+	// Tenant defaults are re-read from their own file
+	if s.tenantConfigs != nil {
+		if err := s.tenantConfigs.Load(); err != nil {
+			response.TenantConfigError = err.Error()
+		}
+	}
+
+	// Provider settings live in the router, which re-reads its own config
+	routerResult, err := s.routerClient.ReloadConfig(c.Request.Context())
+	if err != nil {
+		response.RouterError = errors.FromError(err).PublicError().Message
+	} else {
+		response.Router = routerResult
+	}
+
+	s.logger.Info("Configuration reloaded",
+		logger.F("applied", response.Gateway.Applied),
+		logger.F("ignored", response.Gateway.Ignored),
+		logger.F("router_error", response.RouterError),
+		logger.F("tenant_config_error", response.TenantConfigError),
+	)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// handleGetConfig godoc
+// @Summary Show effective configuration
+// @Description Return the configuration the gateway and router are running with, including resolved defaults and reloaded values. API keys, secrets and custom header values are never included, for comparing replicas when debugging configuration drift.
+// @Tags admin
+// @Produce json
+// @Param X-Admin-Key header string true "Admin API key"
+// @Success 200 {object} ConfigResponse "Effective configuration per service"
+// @Failure 403 {object} ErrorResponse "Admin key missing or invalid"
+// @Router /v1/internal/config [get]
+func (s *Service) handleGetConfig(c *gin.Context) {
+	response := ConfigResponse{Gateway: s.currentConfig().Redacted()}
+
+	// The router reports its own config, which may have been reloaded separately
+	routerConfig, err := s.routerClient.GetConfig(c.Request.Context())
+	if err != nil {
+		response.RouterError = errors.FromError(err).PublicError().Message
+	} else {
+		response.Router = routerConfig
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,154 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func newReloadTestService(t *testing.T, configFile string) (*Service, *fakeRouterClient) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	// Restore everything the config file sets once the test ends
+	t.Setenv("CONFIG_FILE", configFile)
+	t.Setenv("ADMIN_API_KEY", "admin-secret")
+	t.Setenv("STREAM_TOKENS_PER_SECOND", "10")
+	t.Setenv("PORT", "8080")
+
+	router := &fakeRouterClient{reload: &env.ReloadResult{Applied: []string{"providers.azure-openai.enabled"}, Ignored: []string{}}}
+	service := &Service{
+		config:        env.DetectEnvironment(),
+		logger:        logger.NewNoop(),
+		routerClient:  router,
+		metricsClient: &fakeMetricsClient{},
+	}
+	service.setupRouter()
+	return service, router
+}
+
+func postReload(service *Service, adminKey string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/internal/reload", nil)
+	if adminKey != "" {
+		req.Header.Set("X-Admin-Key", adminKey)
+	}
+	w := httptest.NewRecorder()
+	service.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestHandleReloadConfig_AppliesSafeFields(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "qlens.env")
+	require.NoError(t, os.WriteFile(configFile, []byte(""), 0o600))
+	service, _ := newReloadTestService(t, configFile)
+
+	before := service.currentConfig()
+	require.NoError(t, os.WriteFile(configFile, []byte("# tuned limits\nSTREAM_TOKENS_PER_SECOND=50\nPORT=9090\n"), 0o600))
+
+	w := postReload(service, "admin-secret")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response ReloadResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []string{"stream_tokens_per_second"}, response.Gateway.Applied)
+	assert.Equal(t, []string{"port"}, response.Gateway.Ignored)
+	require.NotNil(t, response.Router)
+	assert.Equal(t, []string{"providers.azure-openai.enabled"}, response.Router.Applied)
+
+	assert.Equal(t, 50.0, service.currentConfig().StreamTokensPerSecond)
+	assert.Equal(t, 8080, service.currentConfig().Port, "restart-only fields keep their value")
+
+	// Requests that already read the config keep the old values
+	assert.Equal(t, 10.0, before.StreamTokensPerSecond)
+}
+
+func TestHandleReloadConfig_RequiresAdminKey(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "qlens.env")
+	require.NoError(t, os.WriteFile(configFile, []byte(""), 0o600))
+	service, _ := newReloadTestService(t, configFile)
+
+	assert.Equal(t, http.StatusForbidden, postReload(service, "").Code)
+	assert.Equal(t, http.StatusForbidden, postReload(service, "wrong").Code)
+
+	// Without a configured key the endpoint is disabled
+	service.config.AdminAPIKey = ""
+	assert.Equal(t, http.StatusForbidden, postReload(service, "admin-secret").Code)
+}
+
+func TestApplyRawResponseOption_RequiresAdminKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := &Service{config: &env.Config{DebugRawResponses: true, AdminAPIKey: "admin-secret"}, logger: logger.NewNoop()}
+
+	apply := func(adminKey string) (*domain.CompletionRequest, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		c.Request.Header.Set("X-Include-Raw-Response", "true")
+		if adminKey != "" {
+			c.Request.Header.Set("X-Admin-Key", adminKey)
+		}
+		req := &domain.CompletionRequest{}
+		return req, service.applyRawResponseOption(req, c)
+	}
+
+	req, err := apply("admin-secret")
+	require.NoError(t, err)
+	assert.True(t, req.IncludeRawResponse)
+
+	for _, adminKey := range []string{"", "wrong"} {
+		req, err = apply(adminKey)
+		require.Error(t, err)
+		assert.Equal(t, http.StatusForbidden, errors.FromError(err).HTTPStatusCode())
+		assert.False(t, req.IncludeRawResponse)
+	}
+
+	// Debugging turned off refuses admins too
+	service.config.DebugRawResponses = false
+	_, err = apply("admin-secret")
+	assert.Error(t, err)
+}
+
+func TestHandleGetConfig_RedactsSecrets(t *testing.T) {
+	t.Setenv("AZURE_OPENAI_API_KEY", "azure-live-key")
+	configFile := filepath.Join(t.TempDir(), "qlens.env")
+	require.NoError(t, os.WriteFile(configFile, []byte(""), 0o600))
+	service, router := newReloadTestService(t, configFile)
+	router.config = &env.Config{Port: 8081}
+
+	get := func(adminKey string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/internal/config", nil)
+		req.Header.Set("X-Admin-Key", adminKey)
+		w := httptest.NewRecorder()
+		service.router.ServeHTTP(w, req)
+		return w
+	}
+	assert.Equal(t, http.StatusForbidden, get("wrong").Code)
+
+	w := get("admin-secret")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.NotContains(t, w.Body.String(), "azure-live-key")
+	assert.NotContains(t, w.Body.String(), "admin-secret")
+
+	var response ConfigResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.NotNil(t, response.Gateway)
+	assert.Equal(t, 8080, response.Gateway.Port)
+	assert.Equal(t, 10.0, response.Gateway.StreamTokensPerSecond)
+	assert.True(t, response.Gateway.Providers["azure-openai"].Enabled)
+	require.NotNil(t, response.Router)
+	assert.Equal(t, 8081, response.Router.Port)
+
+	// The running config keeps its key
+	assert.Equal(t, "azure-live-key", service.currentConfig().Providers["azure-openai"].APIKey)
+}
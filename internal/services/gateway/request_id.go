@@ -0,0 +1,67 @@
+package gateway
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+const (
+	// requestIDHeader carries the request ID in both directions. Clients may
+	// set it to correlate their own logs; it is always returned.
+	requestIDHeader = "X-Request-ID"
+
+	// correlationIDHeader is the older request ID header, still accepted
+	correlationIDHeader = "X-Correlation-ID"
+
+	// providerRequestIDHeader returns the ID the provider gave the call
+	providerRequestIDHeader = "X-Provider-Request-ID"
+
+	// maxRequestIDLength bounds client-supplied request IDs, which end up in
+	// logs and provider headers
+	maxRequestIDLength = 128
+)
+
+// requestIDFromHeaders returns the client's request ID, or a new one when
+// the client sent none or one that is unsafe to log and forward
+func requestIDFromHeaders(c *gin.Context) string {
+	for _, header := range []string{requestIDHeader, correlationIDHeader} {
+		if id := c.GetHeader(header); id != "" {
+			if isValidRequestID(id) {
+				return id
+			}
+			break
+		}
+	}
+	return generateCorrelationID()
+}
+
+// isValidRequestID reports whether id is short and limited to characters
+// safe in headers and log lines
+func isValidRequestID(id string) bool {
+	if len(id) == 0 || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
+			(r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.' || r == ':') {
+			return false
+		}
+	}
+	return true
+}
+
+// setProviderRequestIDHeader returns the provider's ID for the call, when it
+// sent one
+func setProviderRequestIDHeader(c *gin.Context, id string) {
+	if id != "" {
+		c.Header(providerRequestIDHeader, id)
+	}
+}
+
+// setCompletionHeaders sets the headers every completion response carries:
+// the provider's request ID and the router's warnings
+func setCompletionHeaders(c *gin.Context, response *domain.CompletionResponse) {
+	setProviderRequestIDHeader(c, response.ProviderRequestID)
+	setContextUtilizationHeader(c, response)
+	setModelDeprecationHeader(c, response.Metadata)
+}
@@ -0,0 +1,65 @@
+package gateway
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoggingMiddleware_RequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service, _ := newCapabilityTestService()
+	router := gin.New()
+	router.Use(service.loggingMiddleware())
+	router.GET("/v1/models/compare", service.handleCompareModels)
+
+	send := func(header, value string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/v1/models/compare", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		want   string
+	}{
+		{name: "client request ID is kept", header: "X-Request-ID", value: "client-abc_1", want: "client-abc_1"},
+		{name: "correlation ID is accepted", header: "X-Correlation-ID", value: "corr-1", want: "corr-1"},
+		{name: "missing ID is generated", want: ""},
+		{name: "unsafe ID is replaced", header: "X-Request-ID", value: "bad id\r\nX-Injected: 1", want: ""},
+		{name: "oversized ID is replaced", header: "X-Request-ID", value: strings.Repeat("a", maxRequestIDLength+1), want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := send(tt.header, tt.value)
+			requestID := w.Header().Get("X-Request-ID")
+			if tt.want != "" {
+				assert.Equal(t, tt.want, requestID)
+			} else {
+				assert.True(t, strings.HasPrefix(requestID, "req_"), requestID)
+			}
+
+			// Error bodies carry the same ID as the header
+			var body struct {
+				Error struct {
+					RequestID string `json:"request_id"`
+				} `json:"error"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, requestID, body.Error.RequestID)
+		})
+	}
+}
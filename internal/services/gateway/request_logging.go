@@ -0,0 +1,11 @@
+package gateway
+
+// sampleRequestLog reports whether a successful request should be logged,
+// keeping one in every n. Failed requests are always logged by the caller.
+// Logged requests carry the rate so log volume can be scaled back up.
+func (s *Service) sampleRequestLog(n int) bool {
+	if n <= 1 {
+		return true
+	}
+	return (s.requestLogCount.Add(1)-1)%uint64(n) == 0
+}
@@ -0,0 +1,48 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestSampleRequestLog(t *testing.T) {
+	s := &Service{}
+	for i := 0; i < 3; i++ {
+		assert.True(t, s.sampleRequestLog(1))
+		assert.True(t, s.sampleRequestLog(0))
+	}
+
+	logged := 0
+	for i := 0; i < 30; i++ {
+		if s.sampleRequestLog(10) {
+			logged++
+		}
+	}
+	assert.Equal(t, 3, logged)
+}
+
+func TestLoggingMiddleware_SamplesSuccessesOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := &Service{
+		config: &env.Config{Logging: env.LoggingConfig{RequestSampleN: 1000, MaxFieldBytes: 64}},
+		logger: logger.NewNoop(),
+	}
+	router := gin.New()
+	router.Use(s.loggingMiddleware())
+	router.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/fail", func(c *gin.Context) { c.Status(http.StatusBadGateway) })
+
+	for i := 0; i < 5; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/ok", nil))
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	}
+	// Only successes count towards the sample
+	assert.Equal(t, uint64(5), s.requestLogCount.Load())
+}
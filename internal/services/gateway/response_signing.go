@@ -0,0 +1,115 @@
+package gateway
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// responseSignatureHeader carries the signature of a signed response
+const responseSignatureHeader = "X-Response-Signature"
+
+// responseSigningMiddleware signs the responses of tenants configured for
+// response signing (see responseSignature), so they can prove a response was
+// not altered in transit. The body is buffered until the handler is done,
+// since the signature header must precede it. It runs inside compression, so
+// the signature covers the uncompressed body. Event streams are not signed.
+func (s *Service) responseSigningMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(s.currentConfig().ResponseSigningTenants) == 0 {
+			c.Next()
+			return
+		}
+
+		writer := &signingResponseWriter{ResponseWriter: c.Writer, service: s, context: c}
+		c.Writer = writer
+		c.Next()
+		writer.finish()
+	}
+}
+
+// responseSigningSecret returns the secret a tenant's responses are signed
+// with, if they are signed
+func (s *Service) responseSigningSecret(tenantID string) (string, bool) {
+	config := s.currentConfig()
+	for _, tenant := range config.ResponseSigningTenants {
+		if tenant == tenantID {
+			secret := config.RequestSigningSecrets[tenantID]
+			return secret, secret != ""
+		}
+	}
+	return "", false
+}
+
+// signingResponseWriter defers the signing decision until the first body
+// write, when authentication has identified the tenant and the handler has
+// set the content type
+type signingResponseWriter struct {
+	gin.ResponseWriter
+	service *Service
+	context *gin.Context
+	body    bytes.Buffer
+	secret  string
+	decided bool
+	sign    bool
+}
+
+func (w *signingResponseWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+		return
+	}
+	w.secret, w.sign = w.service.responseSigningSecret(w.context.GetString("tenant_id"))
+}
+
+func (w *signingResponseWriter) Write(data []byte) (int, error) {
+	w.decide()
+	if w.sign {
+		return w.body.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *signingResponseWriter) WriteString(data string) (int, error) {
+	return w.Write([]byte(data))
+}
+
+// Flush is deferred for a buffered response, which is written whole
+func (w *signingResponseWriter) Flush() {
+	if !w.sign {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// finish signs and writes a buffered response
+func (w *signingResponseWriter) finish() {
+	if !w.sign {
+		return
+	}
+	signature := responseSignature(w.secret, w.Status(), w.Header().Get(requestIDHeader), w.body.Bytes())
+	w.Header().Set(responseSignatureHeader, "sha256="+hex.EncodeToString(signature))
+	w.ResponseWriter.Write(w.body.Bytes())
+}
+
+// responseSignature is the HMAC-SHA256, keyed by the tenant's secret, of the
+// status code and request ID, one per line, followed by a newline and the
+// uncompressed body. Including the request ID binds the response to the
+// request it answers.
+func responseSignature(secret string, status int, requestID string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	for _, field := range []string{strconv.Itoa(status), requestID} {
+		mac.Write([]byte(field))
+		mac.Write([]byte("\n"))
+	}
+	mac.Write(body)
+	return mac.Sum(nil)
+}
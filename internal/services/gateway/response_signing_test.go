@@ -0,0 +1,96 @@
+package gateway
+
+import (
+	"compress/gzip"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func newResponseSigningTestRouter(config *env.Config) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	service := &Service{config: config, logger: logger.NewNoop()}
+
+	engine := gin.New()
+	engine.Use(service.compressionMiddleware())
+	engine.Use(service.responseSigningMiddleware())
+	engine.Use(func(c *gin.Context) {
+		c.Header(requestIDHeader, "req-1")
+		c.Set("tenant_id", c.GetHeader("X-Tenant-ID"))
+	})
+	engine.GET("/v1/models", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"object": "list"})
+	})
+	engine.GET("/v1/stream", func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		c.String(http.StatusOK, "data: [DONE]\n\n")
+	})
+	return engine
+}
+
+func newResponseSigningConfig() *env.Config {
+	return &env.Config{
+		CompressionEnabled:     true,
+		RequestSigningSecrets:  map[string]string{"tenant-a": "secret-a", "tenant-b": "secret-b"},
+		ResponseSigningTenants: []string{"tenant-a"},
+	}
+}
+
+func serveForTenant(engine *gin.Engine, path, tenantID string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Header.Set("X-Tenant-ID", tenantID)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestResponseSigning_SignsTenantResponses(t *testing.T) {
+	engine := newResponseSigningTestRouter(newResponseSigningConfig())
+
+	w := serveForTenant(engine, "/v1/models", "tenant-a")
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.JSONEq(t, `{"object":"list"}`, w.Body.String())
+
+	expected := responseSignature("secret-a", http.StatusCreated, "req-1", w.Body.Bytes())
+	assert.Equal(t, "sha256="+hex.EncodeToString(expected), w.Header().Get(responseSignatureHeader))
+
+	// Tenants not configured for it, and event streams, are not signed
+	assert.Empty(t, serveForTenant(engine, "/v1/models", "tenant-b").Header().Get(responseSignatureHeader))
+	w = serveForTenant(engine, "/v1/stream", "tenant-a")
+	assert.Equal(t, "data: [DONE]\n\n", w.Body.String())
+	assert.Empty(t, w.Header().Get(responseSignatureHeader))
+}
+
+func TestResponseSigning_CoversUncompressedBody(t *testing.T) {
+	engine := newResponseSigningTestRouter(newResponseSigningConfig())
+
+	w := serveForTenant(engine, "/v1/models", "tenant-a", "Accept-Encoding", "gzip")
+	require.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	reader, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(reader)
+	require.NoError(t, err)
+
+	expected := responseSignature("secret-a", http.StatusCreated, "req-1", body)
+	assert.Equal(t, "sha256="+hex.EncodeToString(expected), w.Header().Get(responseSignatureHeader))
+}
+
+func TestResponseSigning_ChangedBodyFailsVerification(t *testing.T) {
+	signature := responseSignature("secret-a", http.StatusOK, "req-1", []byte(`{"id":"a"}`))
+
+	assert.NotEqual(t, signature, responseSignature("secret-a", http.StatusOK, "req-1", []byte(`{"id":"b"}`)))
+	assert.NotEqual(t, signature, responseSignature("secret-a", http.StatusOK, "req-2", []byte(`{"id":"a"}`)))
+	assert.NotEqual(t, signature, responseSignature("secret-b", http.StatusOK, "req-1", []byte(`{"id":"a"}`)))
+}
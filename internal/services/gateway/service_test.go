@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/quantum-suite/platform/pkg/shared/env"
@@ -17,7 +18,7 @@ func TestNewService(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-gateway",
-		Port:        "8080",
+		Port:        8080,
 		Logging: env.LoggingConfig{
 			Level:      "debug",
 			Format:     "json",
@@ -39,7 +40,7 @@ func TestServiceHealthCheck(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-gateway",
-		Port:        "8080",
+		Port:        8080,
 		Logging: env.LoggingConfig{
 			Level:      "error",
 			Format:     "json",
@@ -52,6 +53,7 @@ func TestServiceHealthCheck(t *testing.T) {
 	service, err := NewService(config, log)
 	require.NoError(t, err)
 	defer service.Close()
+	service.routerClient = &fakeRouterClient{}
 
 	server := httptest.NewServer(service.Handler())
 	defer server.Close()
@@ -68,14 +70,14 @@ func TestServiceHealthCheck(t *testing.T) {
 
 	assert.Contains(t, healthResp, "status")
 	assert.Contains(t, healthResp, "timestamp")
-	assert.Contains(t, healthResp, "service")
+	assert.Contains(t, healthResp, "services")
 }
 
 func TestServiceModelsEndpoint(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-gateway",
-		Port:        "8080",
+		Port:        8080,
 		Logging: env.LoggingConfig{
 			Level:      "error",
 			Format:     "json",
@@ -88,6 +90,7 @@ func TestServiceModelsEndpoint(t *testing.T) {
 	service, err := NewService(config, log)
 	require.NoError(t, err)
 	defer service.Close()
+	service.routerClient = &fakeRouterClient{}
 
 	server := httptest.NewServer(service.Handler())
 	defer server.Close()
@@ -110,19 +113,21 @@ func TestServiceAuthenticationRequired(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-gateway",
-		Port:        "8080",
+		Port:        8080,
 		Logging: env.LoggingConfig{
 			Level:      "error",
 			Format:     "json",
 			Structured: true,
 		},
 		Providers: map[string]env.ProviderConfig{},
+		AuthEnabled: true,
 	}
 
 	log := logger.NewNoop()
 	service, err := NewService(config, log)
 	require.NoError(t, err)
 	defer service.Close()
+	service.routerClient = &fakeRouterClient{}
 
 	server := httptest.NewServer(service.Handler())
 	defer server.Close()
@@ -153,7 +158,7 @@ func TestServiceTenantHeaderRequired(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-gateway",
-		Port:        "8080",
+		Port:        8080,
 		Logging: env.LoggingConfig{
 			Level:      "error",
 			Format:     "json",
@@ -166,6 +171,7 @@ func TestServiceTenantHeaderRequired(t *testing.T) {
 	service, err := NewService(config, log)
 	require.NoError(t, err)
 	defer service.Close()
+	service.routerClient = &fakeRouterClient{}
 
 	server := httptest.NewServer(service.Handler())
 	defer server.Close()
@@ -197,7 +203,7 @@ func TestServiceInvalidJSON(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-gateway",
-		Port:        "8080",
+		Port:        8080,
 		Logging: env.LoggingConfig{
 			Level:      "error",
 			Format:     "json",
@@ -210,6 +216,7 @@ func TestServiceInvalidJSON(t *testing.T) {
 	service, err := NewService(config, log)
 	require.NoError(t, err)
 	defer service.Close()
+	service.routerClient = &fakeRouterClient{}
 
 	server := httptest.NewServer(service.Handler())
 	defer server.Close()
@@ -233,7 +240,7 @@ func TestServiceCORS(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-gateway",
-		Port:        "8080",
+		Port:        8080,
 		Logging: env.LoggingConfig{
 			Level:      "error",
 			Format:     "json",
@@ -246,6 +253,7 @@ func TestServiceCORS(t *testing.T) {
 	service, err := NewService(config, log)
 	require.NoError(t, err)
 	defer service.Close()
+	service.routerClient = &fakeRouterClient{}
 
 	server := httptest.NewServer(service.Handler())
 	defer server.Close()
@@ -271,7 +279,7 @@ func TestServiceMetrics(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-gateway",
-		Port:        "8080",
+		Port:        8080,
 		Logging: env.LoggingConfig{
 			Level:      "error",
 			Format:     "json",
@@ -284,6 +292,7 @@ func TestServiceMetrics(t *testing.T) {
 	service, err := NewService(config, log)
 	require.NoError(t, err)
 	defer service.Close()
+	service.routerClient = &fakeRouterClient{}
 
 	server := httptest.NewServer(service.Handler())
 	defer server.Close()
@@ -294,5 +303,5 @@ func TestServiceMetrics(t *testing.T) {
 	defer resp.Body.Close()
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
-	assert.Equal(t, "text/plain", resp.Header.Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain"))
 }
\ No newline at end of file
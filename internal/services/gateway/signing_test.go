@@ -0,0 +1,142 @@
+package gateway
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/services/gateway/clients"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func newSigningTestRouter() *gin.Engine {
+	return newSigningTestReplica(clients.NewSimpleCacheClient(logger.NewNoop()))
+}
+
+// newSigningTestReplica builds a gateway replica recording nonces in cache
+func newSigningTestReplica(cache CacheClient) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	service := &Service{
+		config: &env.Config{
+			AuthEnabled:           true,
+			RequestSigningSecrets: map[string]string{"tenant-a": "secret-a"},
+			RequestSigningWindow:  time.Minute,
+		},
+		logger:      logger.NewNoop(),
+		cacheClient: cache,
+	}
+
+	engine := gin.New()
+	engine.Use(service.authenticationMiddleware())
+	engine.POST("/v1/completions", func(c *gin.Context) {
+		body, _ := c.GetRawData()
+		c.String(http.StatusOK, c.GetString("user_id")+":"+string(body))
+	})
+	return engine
+}
+
+// signedRequest builds a request signed with secret at timestamp
+func signedRequest(secret, nonce string, timestamp time.Time, body string) *http.Request {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	signature := requestSignature(secret, http.MethodPost, "/v1/completions", "tenant-a", "user-1", ts, nonce, []byte(body))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/completions", strings.NewReader(body))
+	req.Header.Set("X-Tenant-ID", "tenant-a")
+	req.Header.Set("X-User-ID", "user-1")
+	req.Header.Set(timestampHeader, ts)
+	req.Header.Set(nonceHeader, nonce)
+	req.Header.Set(signatureHeader, hex.EncodeToString(signature))
+	return req
+}
+
+func serveSigned(engine *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+	return w
+}
+
+func TestSignedRequest_Accepted(t *testing.T) {
+	engine := newSigningTestRouter()
+
+	w := serveSigned(engine, signedRequest("secret-a", "nonce-1", time.Now(), `{"model":"gpt-4"}`))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `user-1:{"model":"gpt-4"}`, w.Body.String(), "the handler still sees the body")
+}
+
+func TestSignedRequest_RejectsReplay(t *testing.T) {
+	engine := newSigningTestRouter()
+
+	require.Equal(t, http.StatusOK, serveSigned(engine, signedRequest("secret-a", "nonce-1", time.Now(), "{}")).Code)
+	w := serveSigned(engine, signedRequest("secret-a", "nonce-1", time.Now(), "{}"))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Body.String(), "nonce has already been used")
+}
+
+func TestSignedRequest_Rejected(t *testing.T) {
+	engine := newSigningTestRouter()
+
+	tampered := signedRequest("secret-a", "nonce-2", time.Now(), "{}")
+	tampered.Body = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"model":"gpt-5"}`)).Body
+
+	otherUser := signedRequest("secret-a", "nonce-3", time.Now(), "{}")
+	otherUser.Header.Set("X-User-ID", "user-2")
+
+	unknownTenant := signedRequest("secret-a", "nonce-4", time.Now(), "{}")
+	unknownTenant.Header.Set("X-Tenant-ID", "tenant-b")
+
+	tests := []struct {
+		name string
+		req  *http.Request
+	}{
+		{name: "wrong secret", req: signedRequest("secret-b", "nonce-1", time.Now(), "{}")},
+		{name: "stale timestamp", req: signedRequest("secret-a", "nonce-5", time.Now().Add(-2*time.Minute), "{}")},
+		{name: "future timestamp", req: signedRequest("secret-a", "nonce-6", time.Now().Add(2*time.Minute), "{}")},
+		{name: "tampered body", req: tampered},
+		{name: "different user", req: otherUser},
+		{name: "tenant without a secret", req: unknownTenant},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, http.StatusUnauthorized, serveSigned(engine, tt.req).Code)
+		})
+	}
+
+	// Rejected requests do not use up their nonce
+	assert.Equal(t, http.StatusOK, serveSigned(engine, signedRequest("secret-a", "nonce-1", time.Now(), "{}")).Code)
+}
+
+func TestSignedRequest_ConcurrentReplaysAcrossReplicas(t *testing.T) {
+	cache := clients.NewSimpleCacheClient(logger.NewNoop())
+	replicas := []*gin.Engine{newSigningTestReplica(cache), newSigningTestReplica(cache)}
+	timestamp := time.Now()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		accepted int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(engine *gin.Engine) {
+			defer wg.Done()
+			if serveSigned(engine, signedRequest("secret-a", "nonce-1", timestamp, "{}")).Code == http.StatusOK {
+				mu.Lock()
+				accepted++
+				mu.Unlock()
+			}
+		}(replicas[i%len(replicas)])
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, accepted)
+}
@@ -0,0 +1,129 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// collectsStream reports whether the request's tenant is configured to get
+// streaming requests answered with a single collected response
+func (s *Service) collectsStream(req *domain.CompletionRequest) bool {
+	for _, tenant := range s.currentConfig().StreamCollectTenants {
+		if tenant == string(req.TenantID) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleCollectedStreamCompletion streams the completion from the provider
+// but answers with one JSON response, for clients whose middleboxes mangle
+// server-sent events. When the provider reports no usage it is estimated the
+// same way as for a cost ceiling.
+func (s *Service) handleCollectedStreamCompletion(ctx context.Context, req *domain.CompletionRequest, ceiling *costCeiling, c *gin.Context) {
+	start := time.Now()
+
+	// The client may cancel the call by its request ID, and a cost ceiling
+	// stops generation at the provider by cancelling the upstream context
+	trackedCtx, untrack := s.trackInFlight(ctx, string(req.TenantID), req.RequestID)
+	defer untrack()
+	upstreamCtx, cancelUpstream := context.WithCancel(trackedCtx)
+	defer cancelUpstream()
+
+	streamChan, err := s.routerClient.RouteCompletionStream(upstreamCtx, req)
+	var response *domain.CompletionResponse
+	if err == nil {
+		if ceiling != nil {
+			streamChan = limitStream(ctx, streamChan, ceiling, cancelUpstream)
+		}
+		response, err = domain.CollectStream(ctx, streamChan)
+	}
+	duration := time.Since(start)
+
+	if err != nil {
+		err = cancelledByClient(trackedCtx, "completion", err)
+		if errors.IsCancellation(err) {
+			s.recordStreamOutcome(ctx, req, domain.StreamOutcomeCancelled, duration, err)
+			// A client that cancelled by request ID is still waiting for
+			// the answer
+			if ctx.Err() == nil {
+				s.respondWithError(c, err)
+			}
+			return
+		}
+		s.recordStreamOutcome(ctx, req, domain.StreamOutcomeProviderError, duration, err)
+		s.respondWithError(c, err)
+		return
+	}
+
+	outcome := domain.StreamOutcomeCompleted
+	for _, choice := range response.Choices {
+		if choice.FinishReason == domain.FinishReasonCostLimit {
+			outcome = domain.StreamOutcomeCostLimit
+		}
+	}
+	s.recordStreamOutcome(ctx, req, outcome, duration, nil)
+
+	if response.Model == "" {
+		response.Model = req.Model
+	}
+	if response.Usage.TotalTokens == 0 {
+		response.Usage.PromptTokens = domain.EstimatePromptTokens(req.Messages)
+		response.Usage.CompletionTokens = estimateChunkTokens(&domain.StreamResponse{Choices: response.Choices})
+		response.Usage.TotalTokens = response.Usage.PromptTokens + response.Usage.CompletionTokens
+	}
+	s.chargeRateLimitTokens(string(req.TenantID), req.Provider, response.Usage.TotalTokens)
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]interface{})
+	}
+	response.Metadata[domain.MetadataKeyStreamCollected] = true
+	if req.ResponseFormat.IsJSON() && s.currentConfig().StripJSONFences {
+		response.StripJSONFences()
+	}
+	response.RequestID = req.RequestID
+	response.ReceivedAt = time.Now().UTC()
+
+	setCompletionHeaders(c, response)
+	c.JSON(http.StatusOK, response)
+}
+
+// limitStream forwards a stream until the request has spent its cost
+// ceiling, then stops the upstream and ends the stream with a cost_limit
+// finish reason
+func limitStream(ctx context.Context, stream <-chan *domain.StreamResponse, ceiling *costCeiling, stop context.CancelFunc) <-chan *domain.StreamResponse {
+	limited := make(chan *domain.StreamResponse)
+
+	go func() {
+		defer close(limited)
+
+		send := func(chunk *domain.StreamResponse) bool {
+			select {
+			case limited <- chunk:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for chunk := range stream {
+			if !send(chunk) || chunk.Error != nil || chunk.Done {
+				return
+			}
+			if ceiling.Add(estimateChunkTokens(chunk)) {
+				stop()
+				send(&domain.StreamResponse{
+					Choices: []domain.Choice{{FinishReason: domain.FinishReasonCostLimit}},
+					Done:    true,
+				})
+				return
+			}
+		}
+	}()
+
+	return limited
+}
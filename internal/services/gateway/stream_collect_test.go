@@ -0,0 +1,137 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func runCollectedStream(t *testing.T, stream []*domain.StreamResponse, ceiling *costCeiling) (*httptest.ResponseRecorder, []string) {
+	gin.SetMode(gin.TestMode)
+	metrics := &fakeMetricsClient{}
+	service := &Service{
+		config:        &env.Config{StreamCollectTenants: []string{"tenant-a"}},
+		logger:        logger.NewNoop(),
+		routerClient:  &fakeRouterClient{stream: stream},
+		metricsClient: metrics,
+	}
+
+	req := &domain.CompletionRequest{TenantID: "tenant-a", Model: "gpt-4", Stream: true, RequestID: "req-1"}
+	require.True(t, service.collectsStream(req))
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/v1/completions", nil)
+
+	service.handleCollectedStreamCompletion(context.Background(), req, ceiling, c)
+	return w, metrics.statuses
+}
+
+func TestCollectedStreamCompletion(t *testing.T) {
+	w, statuses := runCollectedStream(t, []*domain.StreamResponse{
+		textChunk("Hello, "),
+		textChunk("world"),
+		{Choices: []domain.Choice{{FinishReason: domain.FinishReasonStop}}, Final: true, ProviderRequestID: "chatcmpl-1", Metadata: map[string]interface{}{
+			domain.MetadataKeyTimeToFirstToken:   85,
+			domain.MetadataKeyContextUtilization: 0.92,
+			domain.MetadataKeyModelDeprecation:   map[string]interface{}{"model": "gpt-4", "action": "warned", "successor": "gpt-4o"},
+		}},
+		{Done: true},
+	}, nil)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+	// The router's warnings arrive in the final event and are passed on as
+	// the same headers a completion gets
+	assert.Equal(t, "chatcmpl-1", w.Header().Get(providerRequestIDHeader))
+	assert.Equal(t, "0.92", w.Header().Get(domain.ContextUtilizationHeader))
+	assert.Equal(t, `299 - "model gpt-4 is deprecated; use gpt-4o"`, w.Header().Get("Warning"))
+
+	var response domain.CompletionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Choices, 1)
+	assert.Equal(t, "Hello, world", response.Choices[0].Message.Content[0].Text)
+	assert.Equal(t, domain.FinishReasonStop, response.Choices[0].FinishReason)
+	assert.Equal(t, "req-1", response.RequestID)
+	assert.Equal(t, true, response.Metadata[domain.MetadataKeyStreamCollected])
+	assert.Equal(t, float64(85), response.Metadata[domain.MetadataKeyTimeToFirstToken])
+	assert.Greater(t, response.Usage.CompletionTokens, 0)
+	assert.Equal(t, []string{string(domain.StreamOutcomeCompleted)}, statuses)
+}
+
+func TestCollectedStreamCompletion_ProviderError(t *testing.T) {
+	w, statuses := runCollectedStream(t, []*domain.StreamResponse{
+		textChunk("Hello"),
+		{Error: errors.ProviderError("azure-openai", "stream reset", nil)},
+	}, nil)
+
+	assert.GreaterOrEqual(t, w.Code, http.StatusInternalServerError)
+	assert.NotContains(t, w.Body.String(), "data:")
+	assert.Equal(t, []string{string(domain.StreamOutcomeProviderError)}, statuses)
+}
+
+func TestCollectedStreamCompletion_CostCeiling(t *testing.T) {
+	ceiling := &costCeiling{limit: 0.001, pricing: domain.ModelPricing{OutputTokenCost: 0.001}}
+	w, statuses := runCollectedStream(t, []*domain.StreamResponse{
+		textChunk("four"),
+		textChunk("never"),
+		{Done: true},
+	}, ceiling)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response domain.CompletionResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "four", response.Choices[0].Message.Content[0].Text)
+	assert.Equal(t, domain.FinishReasonCostLimit, response.Choices[0].FinishReason)
+	assert.Equal(t, []string{string(domain.StreamOutcomeCostLimit)}, statuses)
+}
+
+func TestCollectedStreamCompletion_CancelledByRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := &hangingRouterClient{started: make(chan struct{}, 1)}
+	metrics := &fakeMetricsClient{}
+	service := &Service{
+		config:        &env.Config{StreamCollectTenants: []string{"tenant-a"}},
+		logger:        logger.NewNoop(),
+		routerClient:  router,
+		metricsClient: metrics,
+	}
+	req := &domain.CompletionRequest{TenantID: "tenant-a", Model: "gpt-4", Stream: true, RequestID: "req-1"}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/v1/completions", nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		service.handleCollectedStreamCompletion(context.Background(), req, nil, c)
+	}()
+	<-router.started
+
+	assert.Equal(t, 1, service.cancelInFlight("tenant-a", "req-1"))
+	<-done
+
+	// The client is still connected and is told its request was cancelled
+	assert.Equal(t, errors.StatusClientClosedRequest, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), string(errors.ErrorTypeCancelled))
+	assert.Equal(t, []string{string(domain.StreamOutcomeCancelled)}, metrics.statuses)
+	assert.Zero(t, service.cancelInFlight("tenant-a", "req-1"))
+}
+
+func TestCollectsStream_OtherTenants(t *testing.T) {
+	service := &Service{config: &env.Config{StreamCollectTenants: []string{"tenant-a"}}}
+	assert.False(t, service.collectsStream(&domain.CompletionRequest{TenantID: "tenant-b"}))
+}
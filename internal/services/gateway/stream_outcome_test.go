@@ -0,0 +1,129 @@
+package gateway
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// fakeMetricsClient records request statuses for assertions
+type fakeMetricsClient struct {
+	mu       sync.Mutex
+	statuses []string
+}
+
+func (f *fakeMetricsClient) RecordRequest(ctx context.Context, method, endpoint, status string, duration time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statuses = append(f.statuses, status)
+	return nil
+}
+
+func (f *fakeMetricsClient) RecordProviderRequest(ctx context.Context, provider, model, status string, duration time.Duration, tokens int) error {
+	return nil
+}
+
+func (f *fakeMetricsClient) GetRequestCount(ctx context.Context, since time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeMetricsClient) GetErrorCount(ctx context.Context, since time.Time) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeMetricsClient) GetAverageLatency(ctx context.Context, since time.Time) (time.Duration, error) {
+	return 0, nil
+}
+
+func (f *fakeMetricsClient) GetProviderMetrics(ctx context.Context, provider string, since time.Time) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func (f *fakeMetricsClient) Health(ctx context.Context) error {
+	return nil
+}
+
+func (f *fakeMetricsClient) RecordProviderHealth(ctx context.Context, provider string, healthy bool) error {
+	return nil
+}
+
+func (f *fakeMetricsClient) Handler() http.Handler {
+	return http.NotFoundHandler()
+}
+
+func runStream(t *testing.T, ctx context.Context, stream []*domain.StreamResponse) (string, []string) {
+	gin.SetMode(gin.TestMode)
+	metrics := &fakeMetricsClient{}
+	service := &Service{
+		config:        &env.Config{},
+		logger:        logger.NewNoop(),
+		routerClient:  &fakeRouterClient{stream: stream},
+		metricsClient: metrics,
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/v1/completions", nil).WithContext(ctx)
+
+	service.handleStreamingCompletion(ctx, &domain.CompletionRequest{Model: "gpt-4", Stream: true}, nil, c)
+	return w.Body.String(), metrics.statuses
+}
+
+func TestStreamingCompletion_Completed(t *testing.T) {
+	body, statuses := runStream(t, context.Background(), []*domain.StreamResponse{{Done: true}})
+
+	assert.Contains(t, body, "data: [DONE]")
+	assert.Equal(t, []string{string(domain.StreamOutcomeCompleted)}, statuses)
+}
+
+func TestStreamingCompletion_ProviderError(t *testing.T) {
+	body, statuses := runStream(t, context.Background(), []*domain.StreamResponse{
+		{Error: errors.ProviderError("azure-openai", "upstream failed", nil)},
+	})
+
+	assert.Contains(t, body, "upstream failed")
+	assert.Equal(t, []string{string(domain.StreamOutcomeProviderError)}, statuses)
+}
+
+func TestStreamingCompletion_ClientCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	body, statuses := runStream(t, ctx, []*domain.StreamResponse{
+		{Error: errors.CancelledError("completion stream", context.Canceled)},
+	})
+
+	assert.NotContains(t, body, "error")
+	assert.Equal(t, []string{string(domain.StreamOutcomeCancelled)}, statuses)
+}
+
+func TestStreamingCompletion_ErrorBeforeStreamIsJSON(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	service := &Service{
+		config:        &env.Config{},
+		logger:        logger.NewNoop(),
+		routerClient:  &fakeRouterClient{err: errors.NewError(errors.ErrorTypeTooManyRequests, "slow down").Build()},
+		metricsClient: &fakeMetricsClient{},
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/v1/completions", nil)
+
+	service.handleStreamingCompletion(context.Background(), &domain.CompletionRequest{Model: "gpt-4", Stream: true}, nil, c)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	assert.Contains(t, w.Body.String(), "slow down")
+}
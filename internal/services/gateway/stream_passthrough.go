@@ -0,0 +1,60 @@
+package gateway
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// applyStreamPassthroughOption honours X-Stream-Passthrough on streaming
+// requests when pass-through streams are enabled
+func (s *Service) applyStreamPassthroughOption(req *domain.CompletionRequest, c *gin.Context) error {
+	header := c.GetHeader("X-Stream-Passthrough")
+	if header == "" {
+		return nil
+	}
+
+	passthrough, err := strconv.ParseBool(header)
+	if err != nil {
+		return errors.ValidationError("X-Stream-Passthrough must be true or false", "X-Stream-Passthrough")
+	}
+	if !passthrough || !req.Stream {
+		return nil
+	}
+
+	if !s.currentConfig().StreamPassthrough {
+		return errors.AuthorizationError("pass-through streams are not enabled on this gateway")
+	}
+
+	req.StreamPassthrough = true
+	return nil
+}
+
+// streamPassthrough relays the chunks of a pass-through stream as the
+// provider sent them. Once the provider's own chunks are flowing, the events
+// the router adds, such as the final event, are left out so the client sees
+// the provider's stream and nothing else. Providers that do not speak the
+// OpenAI schema have no chunks to relay and are streamed as usual.
+type streamPassthrough struct {
+	relaying bool
+}
+
+// eventData returns the data of the event for a chunk, and false when the
+// chunk is not sent. A nil streamPassthrough sends every chunk as it was
+// translated.
+func (p *streamPassthrough) eventData(response *domain.StreamResponse) ([]byte, bool) {
+	if p != nil {
+		if len(response.Raw) > 0 {
+			p.relaying = true
+			return response.Raw, true
+		}
+		if p.relaying {
+			return nil, false
+		}
+	}
+	data, _ := json.Marshal(response)
+	return data, true
+}
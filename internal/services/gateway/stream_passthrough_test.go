@@ -0,0 +1,105 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestApplyStreamPassthroughOption(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name    string
+		enabled bool
+		stream  bool
+		header  string
+		want    bool
+		errType errors.ErrorType
+	}{
+		{name: "no header", enabled: true, stream: true},
+		{name: "enabled", enabled: true, stream: true, header: "true", want: true},
+		{name: "not streaming", enabled: true, header: "true"},
+		{name: "disabled on gateway", stream: true, header: "true", errType: errors.ErrorTypeAuthorization},
+		{name: "invalid header", enabled: true, stream: true, header: "raw", errType: errors.ErrorTypeValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &Service{config: &env.Config{StreamPassthrough: tt.enabled}, logger: logger.NewNoop()}
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("POST", "/v1/chat/completions", nil)
+			if tt.header != "" {
+				c.Request.Header.Set("X-Stream-Passthrough", tt.header)
+			}
+
+			req := &domain.CompletionRequest{Model: "gpt-4", Stream: tt.stream}
+			err := service.applyStreamPassthroughOption(req, c)
+			if tt.errType != "" {
+				require.Error(t, err)
+				assert.True(t, errors.IsType(err, tt.errType))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, req.StreamPassthrough)
+		})
+	}
+}
+
+func streamPassthroughEvents(t *testing.T, stream []*domain.StreamResponse) []string {
+	gin.SetMode(gin.TestMode)
+	service := &Service{
+		config:        &env.Config{StreamPassthrough: true},
+		logger:        logger.NewNoop(),
+		routerClient:  &fakeRouterClient{stream: stream},
+		metricsClient: &fakeMetricsClient{},
+	}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	req := &domain.CompletionRequest{Model: "gpt-4", Stream: true, StreamPassthrough: true}
+	service.handleStreamingCompletion(context.Background(), req, nil, c)
+	return strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+}
+
+func TestStreamingCompletion_PassthroughRelaysProviderChunks(t *testing.T) {
+	raw := `{"id":"chatcmpl-1","object":"chat.completion.chunk","system_fingerprint":"fp_1","choices":[{"index":0,"delta":{"content":"Hi"},"logprobs":null,"finish_reason":null}]}`
+	chunk := &domain.StreamResponse{
+		ID:      "chatcmpl-1",
+		Choices: []domain.Choice{{Message: domain.Message{Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "Hi"}}}}},
+		Raw:     json.RawMessage(raw),
+	}
+	final := &domain.StreamResponse{Final: true, Choices: []domain.Choice{{FinishReason: domain.FinishReasonStop}}}
+
+	events := streamPassthroughEvents(t, []*domain.StreamResponse{chunk, final, {Done: true}})
+
+	// The router's final event is left out
+	require.Len(t, events, 2)
+	assert.Equal(t, "data: "+raw, events[0])
+	assert.Equal(t, "data: [DONE]", events[1])
+}
+
+func TestStreamingCompletion_PassthroughWithoutProviderChunks(t *testing.T) {
+	chunk := &domain.StreamResponse{
+		Choices: []domain.Choice{{Message: domain.Message{Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "Hi"}}}}},
+	}
+	final := &domain.StreamResponse{Final: true, Choices: []domain.Choice{{FinishReason: domain.FinishReasonStop}}}
+
+	// Providers without raw chunks stream as usual
+	events := streamPassthroughEvents(t, []*domain.StreamResponse{chunk, final, {Done: true}})
+	require.Len(t, events, 3)
+	assert.Contains(t, events[0], `"text":"Hi"`)
+	assert.Contains(t, events[1], `"final":true`)
+	assert.Equal(t, "data: [DONE]", events[2])
+}
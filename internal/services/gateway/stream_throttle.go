@@ -0,0 +1,96 @@
+package gateway
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// streamThrottle is a token bucket that paces streamed chunks to a maximum
+// tokens-per-second rate. Chunks are only ever delayed, never dropped; a
+// chunk larger than the bucket is let through and its excess is paid back
+// by waiting before the next one.
+type streamThrottle struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	now  func() time.Time
+	wait func(ctx context.Context, d time.Duration) error
+}
+
+// newStreamThrottle returns a throttle for the given rate, or nil when the
+// rate is zero and throttling is disabled
+func newStreamThrottle(tokensPerSecond float64) *streamThrottle {
+	if tokensPerSecond <= 0 {
+		return nil
+	}
+
+	// Allow up to one second of tokens to go out without delay
+	burst := math.Max(tokensPerSecond, 1)
+	return &streamThrottle{
+		rate:   tokensPerSecond,
+		burst:  burst,
+		tokens: burst,
+		last:   time.Now(),
+		now:    time.Now,
+		wait:   sleepContext,
+	}
+}
+
+// Wait blocks until n tokens may be delivered or the context is done
+func (t *streamThrottle) Wait(ctx context.Context, n int) error {
+	if n <= 0 {
+		return nil
+	}
+
+	now := t.now()
+	t.tokens = math.Min(t.burst, t.tokens+now.Sub(t.last).Seconds()*t.rate)
+	t.last = now
+
+	t.tokens -= float64(n)
+	if t.tokens >= 0 {
+		return nil
+	}
+
+	delay := time.Duration(-t.tokens / t.rate * float64(time.Second))
+	if err := t.wait(ctx, delay); err != nil {
+		return err
+	}
+
+	// The debt was paid by waiting
+	t.tokens = 0
+	t.last = t.now()
+	return nil
+}
+
+// estimateChunkTokens approximates the tokens in a streamed chunk with the
+// domain's text estimate, counting at least one token for any content
+func estimateChunkTokens(response *domain.StreamResponse) int {
+	chars := 0
+	for _, choice := range response.Choices {
+		for _, part := range choice.Message.Content {
+			chars += len(part.Text)
+		}
+		for _, call := range choice.Message.ToolCalls {
+			chars += len(call.Function.Name) + len(call.Function.Arguments)
+		}
+	}
+
+	return domain.EstimateTextTokens(chars)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
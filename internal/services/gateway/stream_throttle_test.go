@@ -0,0 +1,93 @@
+package gateway
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+)
+
+// fakeThrottleClock advances only when the throttle waits
+type fakeThrottleClock struct {
+	now    time.Time
+	waited []time.Duration
+}
+
+func newTestThrottle(rate float64) (*streamThrottle, *fakeThrottleClock) {
+	clock := &fakeThrottleClock{now: time.Unix(0, 0)}
+	throttle := newStreamThrottle(rate)
+	throttle.last = clock.now
+	throttle.now = func() time.Time { return clock.now }
+	throttle.wait = func(ctx context.Context, d time.Duration) error {
+		clock.waited = append(clock.waited, d)
+		clock.now = clock.now.Add(d)
+		return ctx.Err()
+	}
+	return throttle, clock
+}
+
+func TestStreamThrottle_DisabledWithoutRate(t *testing.T) {
+	assert.Nil(t, newStreamThrottle(0))
+}
+
+func TestStreamThrottle_DelaysBeyondBurst(t *testing.T) {
+	throttle, clock := newTestThrottle(10)
+
+	// The first second's worth of tokens goes out immediately
+	require.NoError(t, throttle.Wait(context.Background(), 10))
+	assert.Empty(t, clock.waited)
+
+	// The next five tokens must wait half a second
+	require.NoError(t, throttle.Wait(context.Background(), 5))
+	assert.Equal(t, []time.Duration{500 * time.Millisecond}, clock.waited)
+
+	// Oversized chunks are delayed, never rejected
+	require.NoError(t, throttle.Wait(context.Background(), 30))
+	assert.Equal(t, 3*time.Second, clock.waited[1])
+}
+
+func TestStreamThrottle_RespectsCancellation(t *testing.T) {
+	throttle, _ := newTestThrottle(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.NoError(t, throttle.Wait(ctx, 1))
+	assert.ErrorIs(t, throttle.Wait(ctx, 1), context.Canceled)
+}
+
+func TestEstimateChunkTokens(t *testing.T) {
+	chunk := &domain.StreamResponse{Choices: []domain.Choice{{
+		Message: domain.Message{Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "Hello there"}}},
+	}}}
+
+	assert.Equal(t, 3, estimateChunkTokens(chunk))
+	assert.Equal(t, 0, estimateChunkTokens(&domain.StreamResponse{}))
+}
+
+func TestStreamTokenRate(t *testing.T) {
+	service := &Service{config: &env.Config{
+		StreamTokensPerSecond:       50,
+		TenantStreamTokensPerSecond: map[string]float64{"demo": 10},
+	}}
+
+	req := &domain.CompletionRequest{TenantID: "acme"}
+	assert.Equal(t, 50.0, service.streamTokenRate(req))
+
+	req.StreamOptions = &domain.StreamOptions{MaxTokensPerSecond: 20}
+	assert.Equal(t, 20.0, service.streamTokenRate(req))
+
+	// A request cannot raise its tenant's cap
+	req.TenantID = "demo"
+	assert.Equal(t, 10.0, service.streamTokenRate(req))
+
+	// Without any configured cap the request rate is used as-is
+	service.config = &env.Config{}
+	assert.Equal(t, 20.0, service.streamTokenRate(req))
+	req.StreamOptions = nil
+	assert.Equal(t, 0.0, service.streamTokenRate(req))
+}
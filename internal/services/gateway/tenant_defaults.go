@@ -0,0 +1,151 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// TenantDefaults are the provider and models a tenant's requests use when
+// they name none. DataResidency is not a default: it restricts every request
+// of the tenant to providers serving from the region, whatever the request
+// asks for.
+type TenantDefaults struct {
+	Provider        domain.Provider `json:"provider,omitempty"`
+	CompletionModel string          `json:"completion_model,omitempty"`
+	EmbeddingModel  string          `json:"embedding_model,omitempty"`
+	DataResidency   string          `json:"data_residency,omitempty"`
+}
+
+// FileTenantConfigStore holds the tenant defaults of a JSON file mapping
+// tenant IDs to their TenantDefaults. Load re-reads the file, so edits are
+// picked up by a configuration reload without a restart.
+type FileTenantConfigStore struct {
+	path     string
+	mu       sync.RWMutex
+	defaults map[domain.TenantID]TenantDefaults
+}
+
+// NewFileTenantConfigStore creates a store holding the defaults in path
+func NewFileTenantConfigStore(path string) (*FileTenantConfigStore, error) {
+	store := &FileTenantConfigStore{path: path}
+	if err := store.Load(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Load re-reads the file. When it cannot be read or parsed the defaults
+// loaded before are kept.
+func (s *FileTenantConfigStore) Load() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read tenant config: %w", err)
+	}
+	var defaults map[domain.TenantID]TenantDefaults
+	if err := json.Unmarshal(data, &defaults); err != nil {
+		return fmt.Errorf("failed to parse tenant config: %w", err)
+	}
+	for tenantID, tenant := range defaults {
+		tenant.Provider = domain.Provider(strings.ToLower(string(tenant.Provider)))
+		tenant.DataResidency = normalizeRegion(tenant.DataResidency)
+		defaults[tenantID] = tenant
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaults = defaults
+	return nil
+}
+
+// Defaults returns the tenant's defaults, which are empty when the file has
+// no entry for it
+func (s *FileTenantConfigStore) Defaults(tenantID domain.TenantID) TenantDefaults {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.defaults[tenantID]
+}
+
+// tenantDefaults returns the tenant's defaults, if tenant config is enabled
+func (s *Service) tenantDefaults(tenantID domain.TenantID) TenantDefaults {
+	if s.tenantConfigs == nil {
+		return TenantDefaults{}
+	}
+	return s.tenantConfigs.Defaults(tenantID)
+}
+
+// applyCompletionDefaults fills in the model and provider a completion
+// request left out: the model from the tenant's default, then the global
+// one, and the provider from the tenant's default. Without either the
+// router picks the provider as usual. A model alias is then replaced by the
+// model it names. The tenant's data residency replaces any the request
+// asked for.
+func (s *Service) applyCompletionDefaults(req *domain.CompletionRequest) {
+	defaults := s.tenantDefaults(req.TenantID)
+	config := s.currentConfig()
+	if req.Model == "" {
+		req.Model = firstNonEmpty(defaults.CompletionModel, config.DefaultCompletionModel)
+		s.logDefaultModel(req.TenantID, req.Model)
+	}
+	req.Model = resolveModelAlias(config, req.Model)
+	if req.Provider == "" {
+		req.Provider = defaults.Provider
+	}
+	if defaults.DataResidency != "" {
+		req.DataResidency = defaults.DataResidency
+	}
+}
+
+// applyEmbeddingDefaults is applyCompletionDefaults for embedding requests
+func (s *Service) applyEmbeddingDefaults(req *domain.EmbeddingRequest) {
+	defaults := s.tenantDefaults(req.TenantID)
+	config := s.currentConfig()
+	if req.Model == "" {
+		req.Model = firstNonEmpty(defaults.EmbeddingModel, config.DefaultEmbeddingModel)
+		s.logDefaultModel(req.TenantID, req.Model)
+	}
+	req.Model = resolveModelAlias(config, req.Model)
+	if req.Provider == "" {
+		req.Provider = defaults.Provider
+	}
+	if defaults.DataResidency != "" {
+		req.DataResidency = defaults.DataResidency
+	}
+}
+
+// resolveModelAlias returns the model an alias names, or the model itself
+func resolveModelAlias(config *env.Config, model string) string {
+	if target, ok := config.ModelAliases[model]; ok {
+		return target
+	}
+	return model
+}
+
+func (s *Service) logDefaultModel(tenantID domain.TenantID, model string) {
+	if model == "" {
+		return
+	}
+	s.logger.Debug("Using default model",
+		logger.F("tenant_id", tenantID),
+		logger.F("model", model))
+}
+
+// normalizeRegion normalizes a data-residency region name
+func normalizeRegion(region string) string {
+	return strings.ToLower(strings.TrimSpace(region))
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
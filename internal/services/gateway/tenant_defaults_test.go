@@ -0,0 +1,155 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+func TestApplyTenantDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"acme": {"provider": "Azure-OpenAI", "completion_model": "gpt-4o", "embedding_model": "text-embedding-3-large"}
+	}`), 0o600))
+	store, err := NewFileTenantConfigStore(path)
+	require.NoError(t, err)
+
+	service, _ := newCapabilityTestService()
+	service.tenantConfigs = store
+	service.config.DefaultCompletionModel = "gpt-4o-mini"
+	service.config.DefaultEmbeddingModel = "text-embedding-3-small"
+
+	// The tenant's defaults fill what the request left out
+	req := &domain.CompletionRequest{TenantID: "acme"}
+	service.applyCompletionDefaults(req)
+	assert.Equal(t, "gpt-4o", req.Model)
+	assert.Equal(t, domain.ProviderAzureOpenAI, req.Provider)
+
+	embeddingReq := &domain.EmbeddingRequest{TenantID: "acme"}
+	service.applyEmbeddingDefaults(embeddingReq)
+	assert.Equal(t, "text-embedding-3-large", embeddingReq.Model)
+	assert.Equal(t, domain.ProviderAzureOpenAI, embeddingReq.Provider)
+
+	// What the client names is kept
+	req = &domain.CompletionRequest{TenantID: "acme", Model: "gpt-35-turbo", Provider: domain.ProviderAWSBedrock}
+	service.applyCompletionDefaults(req)
+	assert.Equal(t, "gpt-35-turbo", req.Model)
+	assert.Equal(t, domain.ProviderAWSBedrock, req.Provider)
+
+	// Other tenants get the global model and leave the provider to the router
+	req = &domain.CompletionRequest{TenantID: "globex"}
+	service.applyCompletionDefaults(req)
+	assert.Equal(t, "gpt-4o-mini", req.Model)
+	assert.Empty(t, req.Provider)
+}
+
+func TestApplyModelAliases(t *testing.T) {
+	service, _ := newCapabilityTestService()
+	service.config.DefaultCompletionModel = "fast"
+	service.config.ModelAliases = map[string]string{"fast": "gpt-4o-mini", "embed": "text-embedding-3-small"}
+
+	req := &domain.CompletionRequest{Model: "fast"}
+	service.applyCompletionDefaults(req)
+	assert.Equal(t, "gpt-4o-mini", req.Model)
+
+	// Defaults may name aliases too
+	req = &domain.CompletionRequest{}
+	service.applyCompletionDefaults(req)
+	assert.Equal(t, "gpt-4o-mini", req.Model)
+
+	embeddingReq := &domain.EmbeddingRequest{Model: "embed"}
+	service.applyEmbeddingDefaults(embeddingReq)
+	assert.Equal(t, "text-embedding-3-small", embeddingReq.Model)
+
+	req = &domain.CompletionRequest{Model: "gpt-4o"}
+	service.applyCompletionDefaults(req)
+	assert.Equal(t, "gpt-4o", req.Model)
+}
+
+func TestFileTenantConfigStore_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"acme": {"completion_model": "gpt-4o"}}`), 0o600))
+	store, err := NewFileTenantConfigStore(path)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte(`{"acme": {"completion_model": "gpt-5"}}`), 0o600))
+	require.NoError(t, store.Load())
+	assert.Equal(t, "gpt-5", store.Defaults("acme").CompletionModel)
+
+	// A broken file keeps the defaults loaded before
+	require.NoError(t, os.WriteFile(path, []byte(`{`), 0o600))
+	assert.Error(t, store.Load())
+	assert.Equal(t, "gpt-5", store.Defaults("acme").CompletionModel)
+
+	_, err = NewFileTenantConfigStore(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestApplyTenantDataResidency(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"acme": {"data_residency": " EU "}}`), 0o600))
+	store, err := NewFileTenantConfigStore(path)
+	require.NoError(t, err)
+
+	service, _ := newCapabilityTestService()
+	service.tenantConfigs = store
+
+	enrich := func(tenantID, region string) *domain.CompletionRequest {
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		if region != "" {
+			c.Request.Header.Set("X-Data-Residency", region)
+		}
+		c.Set("tenant_id", tenantID)
+		req := &domain.CompletionRequest{}
+		service.enrichCompletionRequest(req, c)
+		return req
+	}
+
+	// The tenant's region cannot be loosened by the request
+	assert.Equal(t, "eu", enrich("acme", "").DataResidency)
+	assert.Equal(t, "eu", enrich("acme", "us").DataResidency)
+
+	// Other tenants may ask for one
+	assert.Equal(t, "us", enrich("globex", "US").DataResidency)
+	assert.Empty(t, enrich("globex", "").DataResidency)
+}
+
+func TestCreateFAQEntry_UsesTenantDataResidency(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tenants.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"acme": {"data_residency": "eu"}}`), 0o600))
+	store, err := NewFileTenantConfigStore(path)
+	require.NoError(t, err)
+
+	service, router := newCapabilityTestService()
+	service.tenantConfigs = store
+
+	create := func(tenantID, body string) *domain.CreateFAQEntryRequest {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/v1/admin/faq", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("tenant_id", tenantID)
+		service.handleCreateFAQEntry(c)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		return router.faqRequest
+	}
+
+	// The tenant's region cannot be loosened by the request
+	assert.Equal(t, "eu", create("acme", `{"question":"q","answer":"a"}`).DataResidency)
+	assert.Equal(t, "eu", create("acme", `{"question":"q","answer":"a","data_residency":"us"}`).DataResidency)
+
+	// Other tenants may ask for one
+	assert.Equal(t, "us", create("globex", `{"question":"q","answer":"a","data_residency":" US "}`).DataResidency)
+}
@@ -0,0 +1,130 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// maxConversationIDLength bounds the X-Conversation-ID header, since every
+// conversation is held in memory until it goes idle
+const maxConversationIDLength = 128
+
+// toolRoundConversation remembers the most tool-call rounds one agent
+// conversation has had answered
+type toolRoundConversation struct {
+	rounds   int
+	lastSeen time.Time
+}
+
+// checkToolRounds guards tenants with a tool-round limit against runaway
+// agent loops. Each run of tool messages answers one response's tool calls,
+// so a request whose last message is a tool result starts the round after
+// those its history holds; once that is beyond the limit the request is
+// rejected. A conversation in X-Conversation-ID also remembers the rounds
+// of its answered requests, so trimming the history does not start over.
+// The returned function records the request's round once it has been
+// answered, so rejected and failed requests use up none.
+func (s *Service) checkToolRounds(req *domain.CompletionRequest, c *gin.Context) (func(), error) {
+	noop := func() {}
+
+	cfg := s.currentConfig()
+	limit := cfg.TenantMaxToolRounds[string(req.TenantID)]
+	if limit <= 0 {
+		return noop, nil
+	}
+
+	conversationID := c.GetHeader("X-Conversation-ID")
+	if len(conversationID) > maxConversationIDLength {
+		return noop, errors.ValidationError(
+			fmt.Sprintf("X-Conversation-ID must be at most %d characters", maxConversationIDLength), "X-Conversation-ID")
+	}
+	if len(req.Messages) == 0 || req.Messages[len(req.Messages)-1].Role != domain.MessageRoleTool {
+		return noop, nil
+	}
+
+	rounds := countToolRounds(req.Messages)
+	key := string(req.TenantID) + "/" + conversationID
+	if conversationID != "" {
+		if answered := s.answeredToolRounds(key, time.Now(), cfg.ToolRoundIdleTTL); answered >= rounds {
+			rounds = answered + 1
+		}
+	}
+	if rounds > limit {
+		return noop, toolRoundLimitError(rounds, limit)
+	}
+
+	if conversationID == "" {
+		return noop, nil
+	}
+	return func() { s.recordToolRound(key, rounds, time.Now(), cfg.ToolRoundIdleTTL) }, nil
+}
+
+// countToolRounds counts the runs of consecutive tool messages, each of
+// which answers the tool calls of one response
+func countToolRounds(messages []domain.Message) int {
+	rounds := 0
+	for i, message := range messages {
+		if message.Role == domain.MessageRoleTool && (i == 0 || messages[i-1].Role != domain.MessageRoleTool) {
+			rounds++
+		}
+	}
+	return rounds
+}
+
+// answeredToolRounds returns the rounds recorded for a conversation, or
+// zero once it has been idle longer than ttl
+func (s *Service) answeredToolRounds(key string, now time.Time, ttl time.Duration) int {
+	s.toolRoundMu.Lock()
+	defer s.toolRoundMu.Unlock()
+
+	conversation, ok := s.toolRounds[key]
+	if !ok || (ttl > 0 && now.Sub(conversation.lastSeen) > ttl) {
+		return 0
+	}
+	return conversation.rounds
+}
+
+// recordToolRound records that a conversation's round was answered and
+// returns the most rounds it has had. Conversations idle longer than ttl
+// are forgotten, so a resumed one starts over.
+func (s *Service) recordToolRound(key string, rounds int, now time.Time, ttl time.Duration) int {
+	s.toolRoundMu.Lock()
+	defer s.toolRoundMu.Unlock()
+
+	if s.toolRounds == nil {
+		s.toolRounds = make(map[string]*toolRoundConversation)
+	}
+	// Idle conversations are swept at most once per ttl
+	if ttl > 0 && now.Sub(s.toolRoundSweep) > ttl {
+		s.toolRoundSweep = now
+		for id, conversation := range s.toolRounds {
+			if now.Sub(conversation.lastSeen) > ttl {
+				delete(s.toolRounds, id)
+			}
+		}
+	}
+
+	conversation, ok := s.toolRounds[key]
+	if !ok || (ttl > 0 && now.Sub(conversation.lastSeen) > ttl) {
+		conversation = &toolRoundConversation{}
+		s.toolRounds[key] = conversation
+	}
+	conversation.rounds = max(conversation.rounds, rounds)
+	conversation.lastSeen = now
+	return conversation.rounds
+}
+
+func toolRoundLimitError(rounds, limit int) *errors.QLensError {
+	return errors.NewError(errors.ErrorTypeValidation,
+		fmt.Sprintf("conversation has reached %d tool-call rounds; at most %d are allowed", rounds, limit)).
+		WithCode(errors.CodeToolRoundLimitExceeded).
+		WithDetail("field", "messages").
+		WithDetail("limit", limit).
+		WithStatusCode(http.StatusUnprocessableEntity).
+		Build()
+}
@@ -0,0 +1,134 @@
+package gateway
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// toolRoundRequest builds a request whose history holds the given number of
+// answered tool-call rounds, ending with the last message role given
+func toolRoundRequest(tenant string, rounds int, lastRole domain.MessageRole) *domain.CompletionRequest {
+	text := func(role domain.MessageRole, s string) domain.Message {
+		return domain.Message{Role: role, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: s}}}
+	}
+
+	messages := []domain.Message{text(domain.MessageRoleUser, "look it up")}
+	for i := 0; i < rounds; i++ {
+		messages = append(messages,
+			domain.Message{Role: domain.MessageRoleAssistant, ToolCalls: []domain.ToolCall{{ID: "call_a"}, {ID: "call_b"}}},
+			text(domain.MessageRoleTool, "result a"),
+			text(domain.MessageRoleTool, "result b"))
+	}
+	if lastRole != domain.MessageRoleTool {
+		messages = append(messages, text(lastRole, "thanks"))
+	}
+	return &domain.CompletionRequest{TenantID: domain.TenantID(tenant), Model: "gpt-4", Messages: messages}
+}
+
+// checkToolRoundsWith checks a request and, when answered, records its
+// round as a successful request would
+func checkToolRoundsWith(service *Service, req *domain.CompletionRequest, conversationID string, answered bool) error {
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest("POST", "/v1/chat/completions", nil)
+	if conversationID != "" {
+		c.Request.Header.Set("X-Conversation-ID", conversationID)
+	}
+	record, err := service.checkToolRounds(req, c)
+	if err == nil && answered {
+		record()
+	}
+	return err
+}
+
+func TestCheckToolRounds_RejectsBeyondTenantLimit(t *testing.T) {
+	service := &Service{
+		config: &env.Config{TenantMaxToolRounds: map[string]int{"tenant-a": 2}, ToolRoundIdleTTL: time.Hour},
+		logger: logger.NewNoop(),
+	}
+
+	require.NoError(t, checkToolRoundsWith(service, toolRoundRequest("tenant-a", 1, domain.MessageRoleTool), "", true))
+	require.NoError(t, checkToolRoundsWith(service, toolRoundRequest("tenant-a", 2, domain.MessageRoleTool), "", true))
+
+	// The limit holds without a conversation ID, since the rounds are
+	// counted from the request's own history
+	err := checkToolRoundsWith(service, toolRoundRequest("tenant-a", 3, domain.MessageRoleTool), "", true)
+	require.Error(t, err)
+	qlensErr := errors.FromError(err)
+	assert.Equal(t, "tool_round_limit_exceeded", qlensErr.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, qlensErr.HTTPStatusCode())
+	assert.Equal(t, 2, qlensErr.PublicError().Details["limit"])
+	// The error taxonomy lists the code with the status it is returned with
+	info, ok := errors.DescribeErrorCode(qlensErr.Type, qlensErr.Code)
+	require.True(t, ok)
+	assert.Equal(t, qlensErr.HTTPStatusCode(), info.StatusCode)
+
+	// Requests that start no round are unaffected
+	require.NoError(t, checkToolRoundsWith(service, toolRoundRequest("tenant-a", 3, domain.MessageRoleAssistant), "", true))
+}
+
+func TestCheckToolRounds_ConversationRemembersAnsweredRounds(t *testing.T) {
+	service := &Service{
+		config: &env.Config{TenantMaxToolRounds: map[string]int{"tenant-a": 2}, ToolRoundIdleTTL: time.Hour},
+		logger: logger.NewNoop(),
+	}
+	latest := toolRoundRequest("tenant-a", 1, domain.MessageRoleTool)
+
+	// A failed round is not counted, so retrying it is allowed
+	require.NoError(t, checkToolRoundsWith(service, latest, "conv-1", false))
+	require.NoError(t, checkToolRoundsWith(service, latest, "conv-1", false))
+	require.NoError(t, checkToolRoundsWith(service, latest, "conv-1", true))
+	require.NoError(t, checkToolRoundsWith(service, latest, "conv-1", true))
+
+	// Two rounds were answered, so trimming the history to the latest
+	// results does not start the count over
+	err := checkToolRoundsWith(service, latest, "conv-1", true)
+	require.Error(t, err)
+	assert.Equal(t, "tool_round_limit_exceeded", errors.FromError(err).Code)
+
+	// Other conversations are unaffected
+	require.NoError(t, checkToolRoundsWith(service, latest, "conv-2", true))
+}
+
+func TestCheckToolRounds_OptInPerTenant(t *testing.T) {
+	service := &Service{
+		config: &env.Config{TenantMaxToolRounds: map[string]int{"tenant-a": 1}},
+		logger: logger.NewNoop(),
+	}
+
+	req := toolRoundRequest("tenant-b", 5, domain.MessageRoleTool)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, checkToolRoundsWith(service, req, "conv-1", true))
+	}
+	assert.Empty(t, service.toolRounds)
+
+	err := checkToolRoundsWith(service, toolRoundRequest("tenant-a", 1, domain.MessageRoleTool), strings.Repeat("x", maxConversationIDLength+1), true)
+	require.Error(t, err)
+	assert.True(t, errors.IsType(err, errors.ErrorTypeValidation))
+}
+
+func TestRecordToolRound_IdleConversationsStartOver(t *testing.T) {
+	service := &Service{}
+	now := time.Now()
+
+	assert.Equal(t, 1, service.recordToolRound("tenant-a/conv-1", 1, now, time.Hour))
+	assert.Equal(t, 2, service.recordToolRound("tenant-a/conv-1", 2, now.Add(time.Minute), time.Hour))
+	assert.Equal(t, 2, service.recordToolRound("tenant-a/conv-1", 1, now.Add(2*time.Minute), time.Hour))
+	assert.Equal(t, 1, service.recordToolRound("tenant-a/conv-1", 1, now.Add(3*time.Hour), time.Hour))
+
+	// Idle conversations are dropped rather than kept forever
+	service.recordToolRound("tenant-a/conv-2", 1, now.Add(6*time.Hour), time.Hour)
+	assert.Len(t, service.toolRounds, 1)
+}
@@ -0,0 +1,63 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Alert is a firing alert in the shape Alertmanager's v2 API accepts
+type Alert struct {
+	Labels      map[string]string `json:"labels"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	StartsAt    time.Time         `json:"startsAt"`
+}
+
+// AlertManager delivers alerts raised by the router itself, for conditions
+// Prometheus rules cannot express on their own
+type AlertManager interface {
+	Send(ctx context.Context, alerts ...Alert) error
+}
+
+// HTTPAlertManager posts alerts to an Alertmanager
+type HTTPAlertManager struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPAlertManager creates an AlertManager posting to the Alertmanager
+// at baseURL
+func NewHTTPAlertManager(baseURL string) *HTTPAlertManager {
+	return &HTTPAlertManager{
+		url:    strings.TrimRight(baseURL, "/") + "/api/v2/alerts",
+		client: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+func (m *HTTPAlertManager) Send(ctx context.Context, alerts ...Alert) error {
+	data, err := json.Marshal(alerts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal alerts: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create alert request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send alerts: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("alertmanager returned status %d", resp.StatusCode)
+	}
+	return nil
+}
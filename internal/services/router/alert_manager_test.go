@@ -0,0 +1,35 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPAlertManager_Send(t *testing.T) {
+	var received []Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/alerts", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	manager := NewHTTPAlertManager(server.URL + "/")
+	err := manager.Send(context.Background(), Alert{Labels: map[string]string{"alertname": "Test"}, StartsAt: time.Now()})
+	require.NoError(t, err)
+	require.Len(t, received, 1)
+	assert.Equal(t, "Test", received[0].Labels["alertname"])
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer failing.Close()
+	assert.Error(t, NewHTTPAlertManager(failing.URL).Send(context.Background(), Alert{}))
+}
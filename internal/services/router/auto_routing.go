@@ -0,0 +1,181 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// selectAutoProvider picks the provider for a request that asked for
+// provider "auto": the eligible provider with the lowest weighted score of
+// cost, latency and error rate. Every candidate's score is returned so the
+// choice can be reported back to the caller. Only providers the tenant is
+// entitled to, in the request's data-residency region, are candidates.
+func (s *Service) selectAutoProvider(tenantID domain.TenantID, modelID, region string) (domain.Provider, []domain.ProviderScore, error) {
+	candidates, err := s.entitledProviders(tenantID, modelID, region)
+	if err != nil {
+		return "", nil, err
+	}
+
+	scores := s.scoreProviders(modelID, candidates)
+	s.rankScores(scores)
+	scores[0].Selected = true
+
+	autoRoutingSelections.WithLabelValues(modelID, string(scores[0].Provider)).Inc()
+	s.logger.Debug("Selected provider for auto routing",
+		logger.F("model", modelID),
+		logger.F("provider", scores[0].Provider),
+		logger.F("scores", scores))
+
+	return scores[0].Provider, scores, nil
+}
+
+// rankScores sorts scores best first. Ties go to the configured default
+// order, then to the provider and model names so the choice is stable.
+func (s *Service) rankScores(scores []domain.ProviderScore) {
+	rank := make(map[domain.Provider]int)
+	for i, provider := range s.currentConfig().DefaultProviderOrder {
+		if _, exists := rank[provider]; !exists {
+			rank[provider] = i + 1
+		}
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Score != scores[j].Score {
+			return scores[i].Score < scores[j].Score
+		}
+		ri, rj := rank[scores[i].Provider], rank[scores[j].Provider]
+		if ri != rj {
+			return ri != 0 && (rj == 0 || ri < rj)
+		}
+		if scores[i].Provider != scores[j].Provider {
+			return scores[i].Provider < scores[j].Provider
+		}
+		return scores[i].Model < scores[j].Model
+	})
+}
+
+// scoreProviders computes each candidate's weighted score. Cost is the
+// model's input plus output price per 1K tokens and latency the tracked
+// average, both relative to the highest among the candidates.
+func (s *Service) scoreProviders(modelID string, candidates []domain.Provider) []domain.ProviderScore {
+	weights := s.currentConfig().AutoRoutingWeights
+
+	costs := make([]float64, len(candidates))
+	latencies := make([]float64, len(candidates))
+	costKnown := make([]bool, len(candidates))
+	latencyKnown := make([]bool, len(candidates))
+
+	s.mu.RLock()
+	for i, provider := range candidates {
+		if model := s.providerModel(provider, modelID); model != nil {
+			input, output, _ := model.Pricing.PerThousandTokens()
+			costs[i] = input + output
+			costKnown[i] = costs[i] > 0
+		}
+	}
+	s.mu.RUnlock()
+
+	for i, provider := range candidates {
+		latencies[i], latencyKnown[i] = s.providerStats.Latency(provider)
+	}
+
+	costs = normalize(costs, costKnown)
+	latencies = normalize(latencies, latencyKnown)
+
+	scores := make([]domain.ProviderScore, len(candidates))
+	for i, provider := range candidates {
+		score := domain.ProviderScore{
+			Provider:  provider,
+			Cost:      costs[i],
+			Latency:   latencies[i],
+			ErrorRate: s.providerStats.ErrorRate(provider),
+		}
+		score.Score = weights.Cost*score.Cost + weights.Latency*score.Latency + weights.ErrorRate*score.ErrorRate
+		scores[i] = score
+	}
+	return scores
+}
+
+// providerModel returns the provider's registry entry for a model, or nil.
+// The caller holds s.mu.
+func (s *Service) providerModel(provider domain.Provider, modelID string) *domain.Model {
+	return s.modelRegistry[modelID][provider]
+}
+
+// normalize scales values to 0-1 against the largest known one. Unknown
+// values, such as a provider that has not served a request yet, take the
+// average of the known ones so missing data neither favours nor penalizes a
+// provider.
+func normalize(values []float64, known []bool) []float64 {
+	var largest, total float64
+	count := 0
+	for i, value := range values {
+		if !known[i] {
+			continue
+		}
+		if value > largest {
+			largest = value
+		}
+		total += value
+		count++
+	}
+
+	normalized := make([]float64, len(values))
+	if largest <= 0 {
+		return normalized
+	}
+	average := total / float64(count)
+	for i, value := range values {
+		if !known[i] {
+			value = average
+		}
+		normalized[i] = value / largest
+	}
+	return normalized
+}
+
+// recordProviderStats feeds a provider call into the latency and error rate
+// tracking used by auto routing and passive health. Cancelled calls and
+// client errors say nothing about the provider, and calls on a tenant's own
+// credentials say nothing about the shared account.
+func (s *Service) recordProviderStats(ctx context.Context, provider domain.Provider, latency time.Duration, err error) {
+	if isTenantScoped(ctx) || shared_errors.IsCancellation(err) {
+		return
+	}
+	if err != nil && !isProviderFailure(err) {
+		return
+	}
+	s.providerStats.Record(provider, latency, err != nil)
+	s.updateProviderHealth(provider)
+}
+
+// recordStreamStats feeds the outcome of a completion stream into the error
+// rate tracking and passive health, by the same rules as recordProviderStats.
+// A stream's duration depends on the length of its response, so it is not
+// counted towards the provider's latency.
+func (s *Service) recordStreamStats(ctx context.Context, provider domain.Provider, err error) {
+	if isTenantScoped(ctx) || shared_errors.IsCancellation(err) {
+		return
+	}
+	if err != nil && !isProviderFailure(err) {
+		return
+	}
+	s.providerStats.RecordOutcome(provider, err != nil)
+	s.updateProviderHealth(provider)
+}
+
+// isProviderFailure reports whether an error counts against the provider:
+// server errors, throttling and timeouts
+func isProviderFailure(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || shared_errors.IsType(err, shared_errors.ErrorTypeTimeout) {
+		return true
+	}
+	status := shared_errors.FromError(err).HTTPStatusCode()
+	return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+}
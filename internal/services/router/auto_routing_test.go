@@ -0,0 +1,115 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// newAutoRoutingService serves gpt-4o from OpenAI and, at half the price,
+// from Azure OpenAI
+func newAutoRoutingService(weights env.AutoRoutingWeights) *Service {
+	client := &countingProviderClient{}
+	s := newCacheTestService(client, nil)
+	s.config.AutoRoutingWeights = weights
+	s.providerConfigs = map[domain.Provider]*domain.ProviderConfig{
+		domain.ProviderOpenAI:      {Provider: domain.ProviderOpenAI, Enabled: true, HealthStatus: domain.ProviderHealthHealthy},
+		domain.ProviderAzureOpenAI: {Provider: domain.ProviderAzureOpenAI, Enabled: true, HealthStatus: domain.ProviderHealthHealthy},
+	}
+	s.providerClients[domain.ProviderAzureOpenAI] = client
+	s.modelRegistry = make(map[string]map[domain.Provider]*domain.Model)
+
+	s.registerModel(&domain.Model{
+		ModelID:  "gpt-4o",
+		Provider: domain.ProviderOpenAI,
+		Pricing:  domain.ModelPricing{InputTokenCost: 0.01, OutputTokenCost: 0.03, Unit: domain.PricingUnitThousandTokens},
+	})
+	s.registerModel(&domain.Model{
+		ModelID:  "gpt-4o",
+		Provider: domain.ProviderAzureOpenAI,
+		Pricing:  domain.ModelPricing{InputTokenCost: 0.005, OutputTokenCost: 0.015, Unit: domain.PricingUnitThousandTokens},
+	})
+	return s
+}
+
+func TestSelectAutoProvider_WeighsCostAgainstLatency(t *testing.T) {
+	s := newAutoRoutingService(env.AutoRoutingWeights{Cost: 1})
+	// Azure is cheaper but four times slower
+	s.providerStats.Record(domain.ProviderOpenAI, 100*time.Millisecond, false)
+	s.providerStats.Record(domain.ProviderAzureOpenAI, 400*time.Millisecond, false)
+
+	provider, scores, err := s.selectAutoProvider("tenant-a", "gpt-4o", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderAzureOpenAI, provider)
+	require.Len(t, scores, 2)
+	assert.Equal(t, domain.ProviderAzureOpenAI, scores[0].Provider)
+	assert.True(t, scores[0].Selected)
+	assert.InDelta(t, 0.5, scores[0].Cost, 1e-9)
+	assert.InDelta(t, 1.0, scores[1].Cost, 1e-9)
+
+	s.config.AutoRoutingWeights = env.AutoRoutingWeights{Cost: 0.2, Latency: 0.8}
+	provider, scores, err = s.selectAutoProvider("tenant-a", "gpt-4o", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderOpenAI, provider)
+	assert.InDelta(t, 0.25, scores[0].Latency, 1e-9)
+	assert.InDelta(t, 0.2*1+0.8*0.25, scores[0].Score, 1e-9)
+}
+
+func TestSelectAutoProvider_PenalizesErrors(t *testing.T) {
+	s := newAutoRoutingService(env.AutoRoutingWeights{Cost: 0.4, ErrorRate: 1})
+	for i := 0; i < 10; i++ {
+		s.providerStats.Record(domain.ProviderAzureOpenAI, time.Millisecond, true)
+	}
+
+	provider, scores, err := s.selectAutoProvider("tenant-a", "gpt-4o", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderOpenAI, provider)
+	assert.Greater(t, scores[1].ErrorRate, 0.5)
+}
+
+func TestSelectAutoProvider_NoEligibleProvider(t *testing.T) {
+	s := newAutoRoutingService(env.AutoRoutingWeights{Cost: 1})
+
+	_, _, err := s.selectAutoProvider("tenant-a", "unknown-model", "")
+	require.Error(t, err)
+	assert.True(t, shared_errors.IsType(err, shared_errors.ErrorTypeValidation))
+}
+
+func TestRouteCompletion_AutoRecordsScores(t *testing.T) {
+	s := newAutoRoutingService(env.AutoRoutingWeights{Cost: 1})
+	req := newCacheTestRequest("tenant-a")
+	req.Provider = domain.ProviderAuto
+
+	response, err := s.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+
+	scores, ok := response.Metadata[domain.MetadataKeyRoutingScores].([]domain.ProviderScore)
+	require.True(t, ok)
+	assert.Equal(t, domain.ProviderAzureOpenAI, scores[0].Provider)
+	assert.True(t, scores[0].Selected)
+
+	// The successful call feeds the latency tracking
+	_, known := s.providerStats.Latency(domain.ProviderAzureOpenAI)
+	assert.True(t, known)
+}
+
+func TestRecordProviderStats_IgnoresClientErrors(t *testing.T) {
+	s := newAutoRoutingService(env.AutoRoutingWeights{})
+	ctx := context.Background()
+
+	s.recordProviderStats(ctx, domain.ProviderOpenAI, time.Millisecond, shared_errors.ValidationError("bad request", "messages"))
+	s.recordProviderStats(ctx, domain.ProviderOpenAI, time.Millisecond, context.Canceled)
+	assert.Zero(t, s.providerStats.ErrorRate(domain.ProviderOpenAI))
+
+	s.recordProviderStats(ctx, domain.ProviderOpenAI, time.Millisecond, shared_errors.ProviderUnavailableError("openai"))
+	assert.Greater(t, s.providerStats.ErrorRate(domain.ProviderOpenAI), 0.0)
+	_, known := s.providerStats.Latency(domain.ProviderOpenAI)
+	assert.False(t, known)
+}
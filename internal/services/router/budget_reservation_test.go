@@ -0,0 +1,103 @@
+package router
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/services/cost"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// withTenantBudget gives the service a cost service whose tenants may spend
+// limit a day
+func withTenantBudget(s *Service, limit float64) *Service {
+	s.costService = cost.NewCostService(logger.NewNoop(), &cost.BudgetConfiguration{
+		TenantDailyLimit:   limit,
+		TenantMonthlyLimit: 1000,
+		GlobalDailyLimit:   1000,
+		GlobalMonthlyLimit: 1000,
+	})
+	return s
+}
+
+func TestRouteCompletionStream_ReservesBudget(t *testing.T) {
+	client := &scriptedStreamClient{chunks: []*domain.StreamResponse{textChunk("Hi"), {Done: true}}}
+
+	// A gpt-4o request for 50 tokens is estimated at $0.001
+	s := withTenantBudget(newCacheTestService(client, nil), 0.0005)
+	req := newCacheTestRequest("tenant-a")
+	req.Stream = true
+
+	_, err := routeTestStream(t, s, req)
+	require.Error(t, err)
+	assert.True(t, shared_errors.IsType(err, shared_errors.ErrorTypeQuotaExceeded), "got %v", err)
+	assert.Zero(t, client.streams, "an over-budget stream must not reach the provider")
+
+	// Each stream settles its reservation, so streams one after another
+	// never hold more than one estimate
+	s = withTenantBudget(newCacheTestService(client, nil), 0.0015)
+	for i := 0; i < 2; i++ {
+		w, err := routeTestStream(t, s, req)
+		require.NoError(t, err)
+		assert.Contains(t, w.Body.String(), "[DONE]")
+	}
+	assert.Equal(t, 2, client.streams)
+	_, err = s.costService.CheckBudgetCompliance("tenant-a", 0.001)
+	assert.NoError(t, err)
+}
+
+func TestRouteCompletionStream_FallbackSettlesReservation(t *testing.T) {
+	client := &streamFailingProviderClient{streamErr: shared_errors.ProviderUnavailableError("openai")}
+	s := withTenantBudget(newCacheTestService(client, nil), 0.0115)
+	s.config.StreamFallback = true
+	req := newCacheTestRequest("tenant-a")
+	req.Stream = true
+
+	_, err := routeTestStream(t, s, req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, client.calls)
+
+	// The completion's $0.01 replaced the stream's $0.001 estimate
+	usage, err := s.costService.GetTenantUsage("tenant-a", "daily")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.01, usage.DailyCost, 1e-9)
+	_, err = s.costService.CheckBudgetCompliance("tenant-a", 0.001)
+	assert.NoError(t, err)
+}
+
+func TestRouteEmbedding_ReservesBudget(t *testing.T) {
+	client := &embeddingProviderClient{sizes: map[string]int{"text-embedding-3-small": 1536}}
+	s := withTenantBudget(newCacheTestService(client, nil), 0.01)
+	s.modelRegistry = make(map[string]map[domain.Provider]*domain.Model)
+	s.registerModel(&domain.Model{
+		ModelID:  "text-embedding-3-small",
+		Provider: domain.ProviderOpenAI,
+		Status:   domain.ModelStatusAvailable,
+		Pricing:  domain.ModelPricing{InputTokenCost: 0.02, Unit: domain.PricingUnitThousandTokens},
+	})
+
+	// 4,000 characters are estimated at 1,000 tokens, or $0.02
+	req := &domain.EmbeddingRequest{
+		TenantID: "tenant-a",
+		Model:    "text-embedding-3-small",
+		Input:    []string{strings.Repeat("a", 4000)},
+	}
+	_, err := s.routeEmbedding(context.Background(), req)
+	require.Error(t, err)
+	assert.True(t, shared_errors.IsType(err, shared_errors.ErrorTypeQuotaExceeded), "got %v", err)
+	assert.Empty(t, client.models, "an over-budget request must not reach the provider")
+
+	// Within budget the request is served and its cost tracked
+	req.Input = []string{"hello"}
+	_, err = s.routeEmbedding(context.Background(), req)
+	require.NoError(t, err)
+	usage, err := s.costService.GetTenantUsage("tenant-a", "daily")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), usage.RequestCount)
+}
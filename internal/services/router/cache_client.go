@@ -0,0 +1,129 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/services/cache"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// CacheClient stores serialized completion responses. Every operation is
+// scoped to a tenant so one tenant can never read another tenant's entries.
+type CacheClient interface {
+	Get(ctx context.Context, tenantID domain.TenantID, key string) ([]byte, bool, error)
+	Set(ctx context.Context, tenantID domain.TenantID, key string, value []byte, ttl time.Duration) error
+}
+
+// HTTPCacheClient talks to the cache service's internal API
+type HTTPCacheClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPCacheClient creates a cache client for the cache service at baseURL
+func NewHTTPCacheClient(baseURL string) *HTTPCacheClient {
+	return &HTTPCacheClient{
+		baseURL: baseURL,
+		client: &http.Client{
+			Timeout: 2 * time.Second,
+		},
+	}
+}
+
+// Get fetches a cached value; the cache service scopes the key by the
+// X-Tenant-ID header
+func (c *HTTPCacheClient) Get(ctx context.Context, tenantID domain.TenantID, key string) ([]byte, bool, error) {
+	endpoint := fmt.Sprintf("%s/internal/v1/cache/%s", c.baseURL, url.PathEscape(key))
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, false, errors.InternalError("failed to create cache request", err)
+	}
+	httpReq.Header.Set("X-Tenant-ID", string(tenantID))
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, false, errors.InternalError("failed to call cache service", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, errors.InternalError(fmt.Sprintf("cache service returned status %d", resp.StatusCode), nil)
+	}
+
+	var cacheResp struct {
+		Value json.RawMessage `json:"value"`
+		Found bool            `json:"found"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&cacheResp); err != nil {
+		return nil, false, errors.InternalError("failed to decode cache response", err)
+	}
+	if !cacheResp.Found || len(cacheResp.Value) == 0 {
+		return nil, false, nil
+	}
+
+	return cacheResp.Value, true, nil
+}
+
+// Set stores a JSON value in the cache service
+func (c *HTTPCacheClient) Set(ctx context.Context, tenantID domain.TenantID, key string, value []byte, ttl time.Duration) error {
+	body, err := json.Marshal(struct {
+		Key   string          `json:"key"`
+		Value json.RawMessage `json:"value"`
+		TTL   time.Duration   `json:"ttl,omitempty"`
+	}{Key: key, Value: value, TTL: ttl})
+	if err != nil {
+		return errors.InternalError("failed to marshal cache request", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/internal/v1/cache", bytes.NewReader(body))
+	if err != nil {
+		return errors.InternalError("failed to create cache request", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("X-Tenant-ID", string(tenantID))
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return errors.InternalError("failed to call cache service", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.InternalError(fmt.Sprintf("cache service returned status %d", resp.StatusCode), nil)
+	}
+	return nil
+}
+
+// StoreCacheClient keeps entries in a local cache store, for deployments
+// without a separate cache service
+type StoreCacheClient struct {
+	store cache.CacheStore
+}
+
+// NewStoreCacheClient wraps a cache store such as cache.NewMemoryStore
+func NewStoreCacheClient(store cache.CacheStore) *StoreCacheClient {
+	return &StoreCacheClient{store: store}
+}
+
+// Get fetches a cached value from the tenant's key space
+func (c *StoreCacheClient) Get(ctx context.Context, tenantID domain.TenantID, key string) ([]byte, bool, error) {
+	return c.store.Get(ctx, tenantCacheKey(tenantID, key))
+}
+
+// Set stores a value in the tenant's key space
+func (c *StoreCacheClient) Set(ctx context.Context, tenantID domain.TenantID, key string, value []byte, ttl time.Duration) error {
+	return c.store.Set(ctx, tenantCacheKey(tenantID, key), value, ttl)
+}
+
+// tenantCacheKey matches the key layout used by the cache service
+func tenantCacheKey(tenantID domain.TenantID, key string) string {
+	return fmt.Sprintf("tenant:%s:%s", tenantID, key)
+}
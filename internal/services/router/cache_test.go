@@ -0,0 +1,221 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/services/cache"
+	"github.com/quantum-suite/platform/internal/services/cost"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+type countingProviderClient struct {
+	ProviderClient
+	calls int
+}
+
+func (c *countingProviderClient) CreateCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+	c.calls++
+	return &domain.CompletionResponse{
+		ID:       "resp-1",
+		Model:    req.Model,
+		Provider: domain.ProviderOpenAI,
+		Usage:    domain.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, CostUSD: 0.01},
+	}, nil
+}
+
+func newCacheTestService(client ProviderClient, cacheClient CacheClient) *Service {
+	log := logger.NewNoop()
+	return &Service{
+		config: &env.Config{Cache: env.CacheConfig{TTL: time.Minute}},
+		logger: log,
+		providerConfigs: map[domain.Provider]*domain.ProviderConfig{
+			domain.ProviderOpenAI: {Provider: domain.ProviderOpenAI, Enabled: true},
+		},
+		providerClients: map[domain.Provider]ProviderClient{domain.ProviderOpenAI: client},
+		circuitBreaker:  NewCircuitBreaker(log),
+		concurrency:     NewAdaptiveLimiter(log),
+		providerStats:   NewProviderStats(),
+		costService: cost.NewCostService(log, &cost.BudgetConfiguration{
+			TenantDailyLimit:   1000,
+			TenantMonthlyLimit: 1000,
+			GlobalDailyLimit:   1000,
+			GlobalMonthlyLimit: 1000,
+		}),
+		cache: cacheClient,
+	}
+}
+
+func newCacheTestRequest(tenantID domain.TenantID) *domain.CompletionRequest {
+	temperature := 0.2
+	maxTokens := 50
+	return &domain.CompletionRequest{
+		TenantID:     tenantID,
+		Provider:     domain.ProviderOpenAI,
+		Model:        "gpt-4o",
+		Messages:     []domain.Message{{Role: domain.MessageRoleUser, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "Hello"}}}},
+		Temperature:  &temperature,
+		MaxTokens:    &maxTokens,
+		CacheEnabled: true,
+	}
+}
+
+func TestRouteCompletion_CachesResponses(t *testing.T) {
+	client := &countingProviderClient{}
+	service := newCacheTestService(client, NewStoreCacheClient(cache.NewMemoryStore(logger.NewNoop())))
+
+	first, err := service.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	assert.False(t, first.Usage.CacheHit)
+
+	// An identical request built separately is served from the cache
+	second, err := service.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, client.calls)
+	assert.True(t, second.Usage.CacheHit)
+	assert.Equal(t, "resp-1", second.ID)
+	assert.Equal(t, 15, second.Usage.TotalTokens)
+	assert.Zero(t, second.Usage.CostUSD)
+
+	// Other tenants never see the entry
+	_, err = service.routeCompletion(context.Background(), newCacheTestRequest("tenant-b"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, client.calls)
+}
+
+func TestRouteCompletion_CacheDisabledPerRequest(t *testing.T) {
+	client := &countingProviderClient{}
+	service := newCacheTestService(client, NewStoreCacheClient(cache.NewMemoryStore(logger.NewNoop())))
+
+	for i := 0; i < 2; i++ {
+		req := newCacheTestRequest("tenant-a")
+		req.CacheEnabled = false
+		response, err := service.routeCompletion(context.Background(), req)
+		require.NoError(t, err)
+		assert.False(t, response.Usage.CacheHit)
+	}
+	assert.Equal(t, 2, client.calls)
+}
+
+func TestRouteCompletion_CacheFailureFallsThrough(t *testing.T) {
+	cacheServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer cacheServer.Close()
+
+	client := &countingProviderClient{}
+	service := newCacheTestService(client, NewHTTPCacheClient(cacheServer.URL))
+
+	response, err := service.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	assert.False(t, response.Usage.CacheHit)
+	assert.Equal(t, 1, client.calls)
+}
+
+// ttlRecordingCacheClient records the TTL of every entry it stores
+type ttlRecordingCacheClient struct {
+	CacheClient
+	ttls map[string]time.Duration
+}
+
+func (c *ttlRecordingCacheClient) Set(ctx context.Context, tenantID domain.TenantID, key string, value []byte, ttl time.Duration) error {
+	c.ttls[key] = ttl
+	return c.CacheClient.Set(ctx, tenantID, key, value, ttl)
+}
+
+func TestRouteCompletion_CacheHitSkipsProviderAccounting(t *testing.T) {
+	client := &countingProviderClient{}
+	service := newCacheTestService(client, NewStoreCacheClient(cache.NewMemoryStore(logger.NewNoop())))
+
+	_, err := service.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	lastSuccess := service.circuitBreaker.states[domain.ProviderOpenAI].LastSuccess
+	requests := service.costService.GetGlobalUsage().RequestCount
+
+	_, err = service.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, client.calls)
+	assert.Equal(t, requests, service.costService.GetGlobalUsage().RequestCount)
+	assert.Equal(t, lastSuccess, service.circuitBreaker.states[domain.ProviderOpenAI].LastSuccess)
+	_, calls := service.providerStats.WindowErrorRate(domain.ProviderOpenAI, time.Minute)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRouteCompletion_CacheTTL(t *testing.T) {
+	cacheClient := &ttlRecordingCacheClient{
+		CacheClient: NewStoreCacheClient(cache.NewMemoryStore(logger.NewNoop())),
+		ttls:        make(map[string]time.Duration),
+	}
+	service := newCacheTestService(&countingProviderClient{}, cacheClient)
+
+	// Requests without a TTL get the configured default
+	req := newCacheTestRequest("tenant-a")
+	_, err := service.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, cacheClient.ttls[service.generateCacheKey("tenant-a", req)])
+
+	req = newCacheTestRequest("tenant-b")
+	req.CacheTTL = 5 * time.Minute
+	_, err = service.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, cacheClient.ttls[service.generateCacheKey("tenant-b", req)])
+}
+
+func TestHTTPCacheClient_RoundTrip(t *testing.T) {
+	cacheService, err := cache.NewService(&env.Config{CacheType: "memory"}, logger.NewNoop())
+	require.NoError(t, err)
+	server := httptest.NewServer(cacheService.Handler())
+	defer server.Close()
+
+	client := NewHTTPCacheClient(server.URL)
+	value := []byte(`{"id":"resp-1"}`)
+	require.NoError(t, client.Set(context.Background(), "tenant-a", "key", value, time.Minute))
+
+	got, found, err := client.Get(context.Background(), "tenant-a", "key")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.JSONEq(t, string(value), string(got))
+
+	_, found, err = client.Get(context.Background(), "tenant-b", "key")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestGenerateCacheKey_UsesPointerValues(t *testing.T) {
+	service := newCacheTestService(&countingProviderClient{}, nil)
+
+	key := service.generateCacheKey("tenant-a", newCacheTestRequest("tenant-a"))
+	assert.Len(t, key, 64)
+	assert.Equal(t, key, service.generateCacheKey("tenant-a", newCacheTestRequest("tenant-a")))
+	assert.NotEqual(t, key, service.generateCacheKey("tenant-b", newCacheTestRequest("tenant-a")))
+
+	changed := newCacheTestRequest("tenant-a")
+	temperature := 0.9
+	changed.Temperature = &temperature
+	assert.NotEqual(t, key, service.generateCacheKey("tenant-a", changed))
+}
+
+func TestGenerateCacheKey_IncludesResponseShapingFields(t *testing.T) {
+	service := newCacheTestService(&countingProviderClient{}, nil)
+	key := service.generateCacheKey("tenant-a", newCacheTestRequest("tenant-a"))
+
+	store := false
+	for name, change := range map[string]func(req *domain.CompletionRequest){
+		"verbose usage":     func(req *domain.CompletionRequest) { req.VerboseUsage = true },
+		"store":             func(req *domain.CompletionRequest) { req.Store = &store },
+		"azure api version": func(req *domain.CompletionRequest) { req.AzureAPIVersion = "2024-06-01" },
+		"anthropic version": func(req *domain.CompletionRequest) { req.AnthropicVersion = "2023-06-01" },
+	} {
+		changed := newCacheTestRequest("tenant-a")
+		change(changed)
+		assert.NotEqual(t, key, service.generateCacheKey("tenant-a", changed), name)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/quantum-suite/platform/internal/domain"
 	"github.com/quantum-suite/platform/pkg/shared/logger"
 )
@@ -167,25 +169,628 @@ func (cb *CircuitBreaker) getOrCreateState(provider domain.Provider) *CircuitSta
 	return state
 }
 
-// HealthChecker monitors provider health
+// AdaptiveLimiter is an AIMD concurrency limiter kept per provider. The
+// allowed in-flight count grows by one after a full window of successes and
+// is cut multiplicatively on overload signals (429s, timeouts).
+type AdaptiveLimiter struct {
+	logger       logger.Logger
+	limits       map[domain.Provider]*adaptiveLimit
+	mu           sync.Mutex
+	initialLimit float64
+	minLimit     float64
+	maxLimit     float64
+	backoff      float64 // Multiplier applied to the limit on overload
+}
+
+type adaptiveLimit struct {
+	limit     float64
+	inFlight  int
+	successes int
+}
+
+// LimiterOutcome classifies a finished request for the adaptive limiter
+type LimiterOutcome int
+
+const (
+	LimiterOutcomeSuccess  LimiterOutcome = iota
+	LimiterOutcomeOverload                // 429 / throttling / timeout
+	LimiterOutcomeIgnore                  // Failures unrelated to provider capacity
+)
+
+var providerConcurrencyLimit = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "qlens_provider_concurrency_limit",
+		Help: "Current adaptive concurrency limit per provider",
+	},
+	[]string{"provider"},
+)
+
+var providerInFlight = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "qlens_provider_requests_in_flight",
+		Help: "Requests currently in flight per provider",
+	},
+	[]string{"provider"},
+)
+
+var streamTerminations = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_stream_terminations_total",
+		Help: "Completion streams by provider and how they terminated (completed, cancelled, provider_error)",
+	},
+	[]string{"provider", "outcome"},
+)
+
+var maxTokensAdjustments = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_max_tokens_adjustments_total",
+		Help: "Completion requests whose max_tokens the router set from the model default or clamped to its ceiling, by model and action (default, clamped)",
+	},
+	[]string{"model", "action"},
+)
+
+var faqRequests = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_faq_requests_total",
+		Help: "Completion requests checked against the tenant's FAQ entries, by tenant and result (hit, miss, error)",
+	},
+	[]string{"tenant_id", "result"},
+)
+
+var toolResultsSanitized = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_tool_results_sanitized_total",
+		Help: "Completion requests whose tool results the router altered before dispatch, by tenant and mode (strip, wrap)",
+	},
+	[]string{"tenant_id", "mode"},
+)
+
+var toolInjectionsRemoved = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_tool_injections_removed_total",
+		Help: "Completion requests with a prompt-injection pattern removed from their tool results, by tenant and pattern",
+	},
+	[]string{"tenant_id", "pattern"},
+)
+
+var streamFallbacks = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_stream_fallbacks_total",
+		Help: "Completion streams the provider failed to open that were retried without streaming, by provider and result (success, error)",
+	},
+	[]string{"provider", "result"},
+)
+
+var streamFailovers = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_stream_failovers_total",
+		Help: "Completion streams moved to another provider after failing before any content was sent, by failed and next provider",
+	},
+	[]string{"from", "to"},
+)
+
+var createdTimestampsAdjusted = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_created_timestamps_adjusted_total",
+		Help: "Provider Created timestamps replaced with the router's time, by provider and reason (missing or skewed)",
+	},
+	[]string{"provider", "reason"},
+)
+
+var embeddingFallbacks = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_embedding_fallbacks_total",
+		Help: "Embedding requests served by a fallback model because the requested model was unavailable",
+	},
+	[]string{"model", "fallback_model"},
+)
+
+var autoRoutingSelections = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_auto_selections_total",
+		Help: "Providers chosen for requests with provider auto, by model",
+	},
+	[]string{"model", "provider"},
+)
+
+var embeddingRouteSelections = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_embedding_route_selections_total",
+		Help: "Models and providers that served embedding requests routed by cost, by requested model",
+	},
+	[]string{"requested_model", "model", "provider"},
+)
+
+var deadLetteredRequests = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_dead_letters_total",
+		Help: "Requests that failed after every retry and fallback, by operation and last provider tried",
+	},
+	[]string{"operation", "provider"},
+)
+
+var completionCacheRequests = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_cache_requests_total",
+		Help: "Completion cache lookups by result (hit, miss, error, stale)",
+	},
+	[]string{"result"},
+)
+
+var payloadRequestBytes = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "qlens_router_request_content_bytes",
+		Help:    "Message content size of completion requests sent to providers, by provider and model",
+		Buckets: prometheus.ExponentialBuckets(256, 4, 9),
+	},
+	[]string{"provider", "model"},
+)
+
+var payloadResponseBytes = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "qlens_router_response_content_bytes",
+		Help:    "Message content size of completions returned by providers, by provider and model",
+		Buckets: prometheus.ExponentialBuckets(64, 4, 9),
+	},
+	[]string{"provider", "model"},
+)
+
+var payloadPromptTokens = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "qlens_router_prompt_tokens",
+		Help:    "Prompt tokens of completions, by provider and model",
+		Buckets: prometheus.ExponentialBuckets(16, 4, 9),
+	},
+	[]string{"provider", "model"},
+)
+
+var payloadCompletionTokens = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "qlens_router_completion_tokens",
+		Help:    "Completion tokens of completions, by provider and model",
+		Buckets: prometheus.ExponentialBuckets(16, 4, 9),
+	},
+	[]string{"provider", "model"},
+)
+
+var responseSizeDeviations = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_response_size_deviations_total",
+		Help: "Times a model's recent average response size strayed from its baseline by more than the alert factor, by provider and model",
+	},
+	[]string{"provider", "model"},
+)
+
+var streamUsageDelta = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "qlens_router_stream_usage_delta_tokens",
+		Help:    "Provider-reported minus estimated total tokens of sampled completed streams",
+		Buckets: []float64{-1000, -250, -50, -10, 0, 10, 50, 250, 1000},
+	},
+	[]string{"provider"},
+)
+
+var streamUsageBilled = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_stream_usage_billed_total",
+		Help: "Completed streams by provider and the usage source they were billed by (provider, estimate)",
+	},
+	[]string{"provider", "source"},
+)
+
+var contextUtilizationRatio = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "qlens_router_context_utilization_ratio",
+		Help:    "Estimated prompt tokens as a fraction of the model's context window",
+		Buckets: []float64{0.1, 0.25, 0.5, 0.75, 0.9, 0.95, 1},
+	},
+	[]string{"model"},
+)
+
+var providerKeepAlives = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_provider_keepalives_total",
+		Help: "Warm standby keepalive probes by provider and result (success, error)",
+	},
+	[]string{"provider", "result"},
+)
+
+var timeToFirstToken = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "qlens_router_time_to_first_token_seconds",
+		Help:    "Time from calling the provider to the first streamed content, by provider and model",
+		Buckets: []float64{0.1, 0.25, 0.5, 0.75, 1, 1.5, 2, 3, 5, 10, 20},
+	},
+	[]string{"provider", "model"},
+)
+
+var providerWarmups = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_provider_warmups_total",
+		Help: "Startup warm-up connections by provider and result (success, error, timeout)",
+	},
+	[]string{"provider", "result"},
+)
+
+var streamToolCallsAssembled = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_stream_tool_calls_total",
+		Help: "Streams whose tool calls were reassembled, by provider and whether their arguments were valid JSON (valid, invalid)",
+	},
+	[]string{"provider", "result"},
+)
+
+var killedRequests = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_killed_requests_total",
+		Help: "Requests kept from a provider by a kill switch, by provider, model and whether they were rerouted or rejected",
+	},
+	[]string{"provider", "model", "outcome"},
+)
+
+var modelTransformsApplied = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_model_transforms_total",
+		Help: "Requests the router rewrote for their model's configured transform, by model",
+	},
+	[]string{"model"},
+)
+
+var partialStreamsBilled = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_partial_streams_billed_total",
+		Help: "Streams cut short by the client or the provider that were billed for the output delivered so far, by provider",
+	},
+	[]string{"provider"},
+)
+
+var deprecatedModelRequests = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_deprecated_model_requests_total",
+		Help: "Requests for models the registry marks deprecated, by tenant, model and whether they were warned or migrated",
+	},
+	[]string{"tenant_id", "model", "action"},
+)
+
+var healthCheckDuration = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "qlens_router_health_check_duration_seconds",
+		Help:    "Duration of active provider health checks, by provider and result (success, error, timeout)",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	},
+	[]string{"provider", "result"},
+)
+
+var healthCheckFailures = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_health_check_failures_total",
+		Help: "Failed active provider health checks by provider and reason (error, timeout)",
+	},
+	[]string{"provider", "reason"},
+)
+
+var healthChecksSkipped = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "qlens_router_health_checks_skipped_total",
+		Help: "Active health checks skipped because the provider's previous check was still running",
+	},
+	[]string{"provider"},
+)
+
+func NewAdaptiveLimiter(log logger.Logger) *AdaptiveLimiter {
+	return &AdaptiveLimiter{
+		logger:       log.WithField("component", "adaptive_limiter"),
+		limits:       make(map[domain.Provider]*adaptiveLimit),
+		initialLimit: 20,
+		minLimit:     1,
+		maxLimit:     200,
+		backoff:      0.5,
+	}
+}
+
+// Acquire reserves an in-flight slot, returning false when the provider is at its limit
+func (al *AdaptiveLimiter) Acquire(provider domain.Provider) bool {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	state := al.getOrCreateLimit(provider)
+	if float64(state.inFlight) >= state.limit {
+		return false
+	}
+
+	state.inFlight++
+	providerInFlight.WithLabelValues(string(provider)).Set(float64(state.inFlight))
+	return true
+}
+
+// Release frees a slot and adjusts the limit based on the request outcome
+func (al *AdaptiveLimiter) Release(provider domain.Provider, outcome LimiterOutcome) {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	state := al.getOrCreateLimit(provider)
+	if state.inFlight > 0 {
+		state.inFlight--
+	}
+	providerInFlight.WithLabelValues(string(provider)).Set(float64(state.inFlight))
+
+	switch outcome {
+	case LimiterOutcomeSuccess:
+		state.successes++
+		// Additive increase once a full window at the current limit succeeds
+		if float64(state.successes) >= state.limit && state.limit < al.maxLimit {
+			state.limit++
+			state.successes = 0
+		}
+	case LimiterOutcomeOverload:
+		previous := state.limit
+		state.limit = state.limit * al.backoff
+		if state.limit < al.minLimit {
+			state.limit = al.minLimit
+		}
+		state.successes = 0
+		al.logger.Warn("Provider overloaded, reducing concurrency limit",
+			logger.F("provider", provider),
+			logger.F("previous_limit", int(previous)),
+			logger.F("limit", int(state.limit)))
+	}
+
+	providerConcurrencyLimit.WithLabelValues(string(provider)).Set(float64(int(state.limit)))
+}
+
+// Limit returns the current concurrency limit for a provider
+func (al *AdaptiveLimiter) Limit(provider domain.Provider) int {
+	al.mu.Lock()
+	defer al.mu.Unlock()
+
+	return int(al.getOrCreateLimit(provider).limit)
+}
+
+func (al *AdaptiveLimiter) getOrCreateLimit(provider domain.Provider) *adaptiveLimit {
+	if state, exists := al.limits[provider]; exists {
+		return state
+	}
+
+	state := &adaptiveLimit{limit: al.initialLimit}
+	al.limits[provider] = state
+	providerConcurrencyLimit.WithLabelValues(string(provider)).Set(al.initialLimit)
+	return state
+}
+
+// providerOutcomeRetention bounds how far back per-second call outcomes are
+// kept for windowed error rates
+const providerOutcomeRetention = 15 * time.Minute
+
+// ProviderStats tracks each provider's recent latency and error rate as
+// exponentially weighted moving averages of the requests routed to it, and
+// the outcomes of its calls per second for error rates over a sliding window
+type ProviderStats struct {
+	mu    sync.Mutex
+	stats map[domain.Provider]*providerStat
+	decay float64 // Weight of the newest sample
+	now   func() time.Time
+}
+
+type providerStat struct {
+	latencyMs float64
+	errorRate float64
+	latencies int
+	outcomes  []outcomeBucket // Oldest first
+}
+
+// outcomeBucket counts the calls to a provider that finished in one second
+type outcomeBucket struct {
+	second   int64
+	calls    int
+	failures int
+}
+
+func NewProviderStats() *ProviderStats {
+	return &ProviderStats{
+		stats: make(map[domain.Provider]*providerStat),
+		decay: 0.1,
+		now:   time.Now,
+	}
+}
+
+// Record adds one provider call. Only successful calls update the latency,
+// since failures are often fast rejections.
+func (ps *ProviderStats) Record(provider domain.Provider, latency time.Duration, failed bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	stat := ps.recordOutcome(provider, failed)
+	if failed {
+		return
+	}
+	ms := float64(latency) / float64(time.Millisecond)
+	if stat.latencies == 0 {
+		stat.latencyMs = ms
+	} else {
+		stat.latencyMs += ps.decay * (ms - stat.latencyMs)
+	}
+	stat.latencies++
+}
+
+// RecordOutcome adds one provider call whose duration says nothing about the
+// provider's latency, such as a stream, which lasts as long as its response
+func (ps *ProviderStats) RecordOutcome(provider domain.Provider, failed bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	ps.recordOutcome(provider, failed)
+}
+
+// recordOutcome counts a call towards the provider's error rates
+func (ps *ProviderStats) recordOutcome(provider domain.Provider, failed bool) *providerStat {
+	stat, exists := ps.stats[provider]
+	if !exists {
+		stat = &providerStat{}
+		ps.stats[provider] = stat
+	}
+
+	failure := 0.0
+	if failed {
+		failure = 1
+	}
+	stat.errorRate += ps.decay * (failure - stat.errorRate)
+	stat.addOutcome(ps.now(), failed)
+	return stat
+}
+
+// Latency returns the provider's average latency in milliseconds, and false
+// when no call to it has succeeded yet
+func (ps *ProviderStats) Latency(provider domain.Provider) (float64, bool) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	stat, exists := ps.stats[provider]
+	if !exists || stat.latencies == 0 {
+		return 0, false
+	}
+	return stat.latencyMs, true
+}
+
+// ErrorRate returns the provider's recent fraction of failed calls
+func (ps *ProviderStats) ErrorRate(provider domain.Provider) float64 {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if stat, exists := ps.stats[provider]; exists {
+		return stat.errorRate
+	}
+	return 0
+}
+
+// WindowErrorRate returns the fraction of the provider's calls that failed
+// within the last window, and how many calls that is
+func (ps *ProviderStats) WindowErrorRate(provider domain.Provider, window time.Duration) (float64, int) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	stat, exists := ps.stats[provider]
+	if !exists {
+		return 0, 0
+	}
+
+	since := ps.now().Add(-window).Unix()
+	calls, failures := 0, 0
+	for _, bucket := range stat.outcomes {
+		if bucket.second <= since {
+			continue
+		}
+		calls += bucket.calls
+		failures += bucket.failures
+	}
+	if calls == 0 {
+		return 0, 0
+	}
+	return float64(failures) / float64(calls), calls
+}
+
+// addOutcome counts a call in its second's bucket and drops buckets older
+// than providerOutcomeRetention
+func (stat *providerStat) addOutcome(now time.Time, failed bool) {
+	second := now.Unix()
+	if n := len(stat.outcomes); n == 0 || stat.outcomes[n-1].second < second {
+		stat.outcomes = append(stat.outcomes, outcomeBucket{second: second})
+	}
+	bucket := &stat.outcomes[len(stat.outcomes)-1]
+	bucket.calls++
+	if failed {
+		bucket.failures++
+	}
+
+	expired := now.Add(-providerOutcomeRetention).Unix()
+	drop := 0
+	for drop < len(stat.outcomes) && stat.outcomes[drop].second <= expired {
+		drop++
+	}
+	if drop > 0 {
+		stat.outcomes = append(stat.outcomes[:0], stat.outcomes[drop:]...)
+	}
+}
+
+// ConnectionWarmer is implemented by provider clients that can keep their
+// connection pool warm with a request cheaper than a full health check
+type ConnectionWarmer interface {
+	WarmConnection(ctx context.Context) error
+}
+
+// Health check defaults for a zero timeout or concurrency
+const (
+	defaultHealthCheckTimeout     = 10 * time.Second
+	defaultHealthCheckConcurrency = 4
+)
+
+// HealthChecker monitors provider health by probing every provider each
+// interval. With no interval it sends no probes and every provider counts as
+// healthy, leaving failures to the circuit breaker fed by real traffic. When
+// a keepalive interval is set it also probes healthy providers between
+// health checks so idle connections are not closed and low-traffic tenants
+// skip the TLS handshake.
+//
+// Each round of checks is run by a small pool of workers, at most
+// concurrency probes at a time across rounds, and a provider whose previous
+// check is still running is skipped rather than probed twice.
 type HealthChecker struct {
-	providers map[domain.Provider]ProviderClient
-	logger    logger.Logger
-	stopCh    chan struct{}
-	wg        sync.WaitGroup
+	providers         map[domain.Provider]ProviderClient
+	interval          time.Duration
+	timeout           time.Duration
+	keepAliveInterval time.Duration
+	logger            logger.Logger
+	stopCh            chan struct{}
+	wg                sync.WaitGroup
+
+	// slots holds a token for each probe running
+	slots chan struct{}
+
+	// mu guards healthy, checking and providers, which a config reload may
+	// replace
+	mu       sync.RWMutex
+	healthy  map[domain.Provider]bool
+	checking map[domain.Provider]bool
+
+	// onResult is called after each active check with the checked provider
+	onResult func(domain.Provider)
 }
 
-func NewHealthChecker(providers map[domain.Provider]ProviderClient, log logger.Logger) *HealthChecker {
+func NewHealthChecker(providers map[domain.Provider]ProviderClient, interval, timeout, keepAliveInterval time.Duration, concurrency int, log logger.Logger) *HealthChecker {
+	if timeout <= 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	if concurrency <= 0 {
+		concurrency = defaultHealthCheckConcurrency
+	}
 	return &HealthChecker{
-		providers: providers,
-		logger:    log.WithField("component", "health_checker"),
-		stopCh:    make(chan struct{}),
+		providers:         providers,
+		interval:          interval,
+		timeout:           timeout,
+		keepAliveInterval: keepAliveInterval,
+		logger:            log.WithField("component", "health_checker"),
+		stopCh:            make(chan struct{}),
+		slots:             make(chan struct{}, concurrency),
+		healthy:           make(map[domain.Provider]bool),
+		checking:          make(map[domain.Provider]bool),
 	}
 }
 
+// OnResult registers a function called after each active check of a
+// provider. It must be set before Start.
+func (hc *HealthChecker) OnResult(fn func(domain.Provider)) {
+	hc.onResult = fn
+}
+
 func (hc *HealthChecker) Start() {
-	hc.wg.Add(1)
-	go hc.healthCheckLoop()
+	if hc.interval > 0 {
+		hc.wg.Add(1)
+		go hc.healthCheckLoop()
+	}
+
+	if hc.keepAliveInterval > 0 {
+		hc.wg.Add(1)
+		go hc.keepAliveLoop()
+	}
 }
 
 func (hc *HealthChecker) Stop() {
@@ -196,7 +801,7 @@ func (hc *HealthChecker) Stop() {
 func (hc *HealthChecker) healthCheckLoop() {
 	defer hc.wg.Done()
 	
-	ticker := time.NewTicker(5 * time.Minute) // Check every 5 minutes to reduce API quota usage
+	ticker := time.NewTicker(hc.interval)
 	defer ticker.Stop()
 
 	// Initial health check
@@ -212,23 +817,196 @@ func (hc *HealthChecker) healthCheckLoop() {
 	}
 }
 
+// SetProviders replaces the providers that are checked, for providers
+// enabled by a config reload
+func (hc *HealthChecker) SetProviders(providers map[domain.Provider]ProviderClient) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	hc.providers = providers
+}
+
+func (hc *HealthChecker) currentProviders() map[domain.Provider]ProviderClient {
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	return hc.providers
+}
+
+// providerCheck is a provider queued for a health check
+type providerCheck struct {
+	provider domain.Provider
+	client   ProviderClient
+}
+
+// checkAllProviders queues a health check of every provider whose previous
+// check has finished and starts workers to run them
 func (hc *HealthChecker) checkAllProviders() {
-	for provider, client := range hc.providers {
+	var pending []providerCheck
+	for provider, client := range hc.currentProviders() {
+		if !hc.startCheck(provider) {
+			healthChecksSkipped.WithLabelValues(string(provider)).Inc()
+			hc.logger.Warn("Skipping provider health check, previous check still running",
+				logger.F("provider", provider),
+			)
+			continue
+		}
+		pending = append(pending, providerCheck{provider: provider, client: client})
+	}
+
+	queue := make(chan providerCheck, len(pending))
+	for _, check := range pending {
+		queue <- check
+	}
+	close(queue)
+
+	workers := cap(hc.slots)
+	if len(pending) < workers {
+		workers = len(pending)
+	}
+	for i := 0; i < workers; i++ {
+		hc.wg.Add(1)
+		go func() {
+			defer hc.wg.Done()
+			for check := range queue {
+				hc.runCheck(check)
+			}
+		}()
+	}
+}
+
+// runCheck checks a queued provider once a probe slot is free
+func (hc *HealthChecker) runCheck(check providerCheck) {
+	defer hc.finishCheck(check.provider)
+
+	select {
+	case hc.slots <- struct{}{}:
+	case <-hc.stopCh:
+		return
+	}
+	defer func() { <-hc.slots }()
+
+	hc.checkProviderHealth(check.provider, check.client)
+}
+
+// startCheck marks the provider as being checked, and reports false when
+// its previous check is still running
+func (hc *HealthChecker) startCheck(provider domain.Provider) bool {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	if hc.checking[provider] {
+		return false
+	}
+	hc.checking[provider] = true
+	return true
+}
+
+func (hc *HealthChecker) finishCheck(provider domain.Provider) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	delete(hc.checking, provider)
+}
+
+// IsHealthy reports whether the provider passed its most recent health
+// check. Without active probing every provider is healthy.
+func (hc *HealthChecker) IsHealthy(provider domain.Provider) bool {
+	if hc.interval <= 0 {
+		return true
+	}
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	return hc.healthy[provider]
+}
+
+// Probed reports the result of the provider's most recent active check, and
+// false when it has not been checked
+func (hc *HealthChecker) Probed(provider domain.Provider) (healthy, probed bool) {
+	if hc.interval <= 0 {
+		return false, false
+	}
+	hc.mu.RLock()
+	defer hc.mu.RUnlock()
+	healthy, probed = hc.healthy[provider]
+	return healthy, probed
+}
+
+func (hc *HealthChecker) setHealthy(provider domain.Provider, healthy bool) {
+	hc.mu.Lock()
+	defer hc.mu.Unlock()
+	hc.healthy[provider] = healthy
+}
+
+func (hc *HealthChecker) keepAliveLoop() {
+	defer hc.wg.Done()
+
+	ticker := time.NewTicker(hc.keepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			hc.warmProviders()
+		case <-hc.stopCh:
+			return
+		}
+	}
+}
+
+// warmProviders probes every healthy provider that supports a cheap
+// keepalive. Providers whose only probe is a billed request are skipped.
+func (hc *HealthChecker) warmProviders() {
+	for provider, client := range hc.currentProviders() {
+		warmer, ok := client.(ConnectionWarmer)
+		if !ok || !hc.IsHealthy(provider) {
+			continue
+		}
+
 		hc.wg.Add(1)
-		go func(p domain.Provider, c ProviderClient) {
+		go func(p domain.Provider, w ConnectionWarmer) {
 			defer hc.wg.Done()
-			hc.checkProviderHealth(p, c)
-		}(provider, client)
+			hc.warmProvider(p, w)
+		}(provider, warmer)
 	}
 }
 
+func (hc *HealthChecker) warmProvider(provider domain.Provider, warmer ConnectionWarmer) {
+	timeout := hc.keepAliveInterval
+	if timeout > 10*time.Second {
+		timeout = 10 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := warmer.WarmConnection(ctx); err != nil {
+		providerKeepAlives.WithLabelValues(string(provider), "error").Inc()
+		hc.logger.Debug("Provider keepalive failed",
+			logger.F("provider", provider),
+			logger.F("error", err),
+		)
+		return
+	}
+	providerKeepAlives.WithLabelValues(string(provider), "success").Inc()
+}
+
 func (hc *HealthChecker) checkProviderHealth(provider domain.Provider, client ProviderClient) {
 	start := time.Now()
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), hc.timeout)
 	defer cancel()
 
 	err := client.HealthCheck(ctx)
 	latency := time.Since(start)
+	hc.setHealthy(provider, err == nil)
+	if hc.onResult != nil {
+		hc.onResult(provider)
+	}
+
+	result := "success"
+	if err != nil {
+		result = "error"
+		if ctx.Err() == context.DeadlineExceeded {
+			result = "timeout"
+		}
+		healthCheckFailures.WithLabelValues(string(provider), result).Inc()
+	}
+	healthCheckDuration.WithLabelValues(string(provider), result).Observe(latency.Seconds())
 
 	if err != nil {
 		hc.logger.Warn("Provider health check failed",
@@ -236,7 +1014,6 @@ func (hc *HealthChecker) checkProviderHealth(provider domain.Provider, client Pr
 			logger.F("error", err),
 			logger.F("latency_ms", latency.Milliseconds()),
 		)
-		// In a real implementation, this would update the provider config
 	} else {
 		hc.logger.Debug("Provider health check passed",
 			logger.F("provider", provider),
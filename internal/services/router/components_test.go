@@ -0,0 +1,181 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestAdaptiveLimiter_AcquireRespectsLimit(t *testing.T) {
+	limiter := NewAdaptiveLimiter(logger.NewNoop())
+	limiter.initialLimit = 2
+
+	assert.True(t, limiter.Acquire(domain.ProviderOpenAI))
+	assert.True(t, limiter.Acquire(domain.ProviderOpenAI))
+	assert.False(t, limiter.Acquire(domain.ProviderOpenAI))
+
+	// Limits are tracked per provider
+	assert.True(t, limiter.Acquire(domain.ProviderAnthropic))
+
+	limiter.Release(domain.ProviderOpenAI, LimiterOutcomeIgnore)
+	assert.True(t, limiter.Acquire(domain.ProviderOpenAI))
+}
+
+func TestAdaptiveLimiter_AdditiveIncrease(t *testing.T) {
+	limiter := NewAdaptiveLimiter(logger.NewNoop())
+	limiter.initialLimit = 4
+
+	for i := 0; i < 4; i++ {
+		assert.True(t, limiter.Acquire(domain.ProviderOpenAI))
+		limiter.Release(domain.ProviderOpenAI, LimiterOutcomeSuccess)
+	}
+
+	assert.Equal(t, 5, limiter.Limit(domain.ProviderOpenAI))
+}
+
+func TestAdaptiveLimiter_MultiplicativeDecrease(t *testing.T) {
+	limiter := NewAdaptiveLimiter(logger.NewNoop())
+	limiter.initialLimit = 8
+
+	limiter.Acquire(domain.ProviderAzureOpenAI)
+	limiter.Release(domain.ProviderAzureOpenAI, LimiterOutcomeOverload)
+	assert.Equal(t, 4, limiter.Limit(domain.ProviderAzureOpenAI))
+
+	for i := 0; i < 10; i++ {
+		limiter.Acquire(domain.ProviderAzureOpenAI)
+		limiter.Release(domain.ProviderAzureOpenAI, LimiterOutcomeOverload)
+	}
+	assert.Equal(t, 1, limiter.Limit(domain.ProviderAzureOpenAI), "limit never drops below the minimum")
+}
+
+func TestClassifyLimiterOutcome(t *testing.T) {
+	throttled := shared_errors.NewError(shared_errors.ErrorTypeTooManyRequests, "slow down").Build()
+
+	assert.Equal(t, LimiterOutcomeSuccess, classifyLimiterOutcome(nil))
+	assert.Equal(t, LimiterOutcomeOverload, classifyLimiterOutcome(throttled))
+	assert.Equal(t, LimiterOutcomeOverload, classifyLimiterOutcome(shared_errors.TimeoutError("completion", 0)))
+	assert.Equal(t, LimiterOutcomeOverload, classifyLimiterOutcome(context.DeadlineExceeded))
+	assert.Equal(t, LimiterOutcomeIgnore, classifyLimiterOutcome(shared_errors.ValidationError("bad", "model")))
+}
+
+type warmableProviderClient struct {
+	ProviderClient
+	healthErr error
+	warms     atomic.Int32
+}
+
+func (c *warmableProviderClient) HealthCheck(ctx context.Context) error {
+	return c.healthErr
+}
+
+func (c *warmableProviderClient) WarmConnection(ctx context.Context) error {
+	c.warms.Add(1)
+	return nil
+}
+
+func TestHealthChecker_WarmsOnlyHealthyProviders(t *testing.T) {
+	healthy := &warmableProviderClient{}
+	unhealthy := &warmableProviderClient{healthErr: errors.New("connection refused")}
+	checker := NewHealthChecker(map[domain.Provider]ProviderClient{
+		domain.ProviderOpenAI:    healthy,
+		domain.ProviderAnthropic: unhealthy,
+	}, 5*time.Minute, 0, time.Minute, 0, logger.NewNoop())
+
+	// Nothing is warmed before the first health check
+	checker.warmProviders()
+	checker.wg.Wait()
+	assert.Zero(t, healthy.warms.Load())
+
+	checker.checkAllProviders()
+	checker.wg.Wait()
+	assert.True(t, checker.IsHealthy(domain.ProviderOpenAI))
+	assert.False(t, checker.IsHealthy(domain.ProviderAnthropic))
+
+	checker.warmProviders()
+	checker.wg.Wait()
+	assert.Equal(t, int32(1), healthy.warms.Load())
+	assert.Zero(t, unhealthy.warms.Load())
+}
+
+func TestHealthChecker_PassiveTreatsProvidersHealthy(t *testing.T) {
+	checker := NewHealthChecker(map[domain.Provider]ProviderClient{
+		domain.ProviderOpenAI: &warmableProviderClient{healthErr: errors.New("connection refused")},
+	}, 0, 0, 0, 0, logger.NewNoop())
+
+	checker.Start()
+	checker.Stop()
+	assert.True(t, checker.IsHealthy(domain.ProviderOpenAI))
+}
+
+// blockingProviderClient holds its health checks until released or timed out
+type blockingProviderClient struct {
+	ProviderClient
+	running *atomic.Int32
+	peak    *atomic.Int32
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (c *blockingProviderClient) HealthCheck(ctx context.Context) error {
+	c.calls.Add(1)
+	running := c.running.Add(1)
+	defer c.running.Add(-1)
+	for {
+		peak := c.peak.Load()
+		if running <= peak || c.peak.CompareAndSwap(peak, running) {
+			break
+		}
+	}
+
+	select {
+	case <-c.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func TestHealthChecker_BoundsConcurrentChecks(t *testing.T) {
+	var running, peak atomic.Int32
+	release := make(chan struct{})
+	providers := map[domain.Provider]ProviderClient{}
+	for _, provider := range []domain.Provider{domain.ProviderOpenAI, domain.ProviderAnthropic, domain.ProviderAzureOpenAI, domain.ProviderAWSBedrock} {
+		providers[provider] = &blockingProviderClient{running: &running, peak: &peak, release: release}
+	}
+	checker := NewHealthChecker(providers, time.Minute, time.Minute, 0, 2, logger.NewNoop())
+
+	checker.checkAllProviders()
+	assert.Eventually(t, func() bool { return running.Load() == 2 }, time.Second, time.Millisecond)
+
+	// The next round skips the providers whose checks are still running
+	checker.checkAllProviders()
+	close(release)
+	checker.wg.Wait()
+	assert.Equal(t, int32(2), peak.Load())
+	for provider, client := range providers {
+		assert.True(t, checker.IsHealthy(provider))
+		assert.Equal(t, int32(1), client.(*blockingProviderClient).calls.Load())
+	}
+	assert.Empty(t, checker.checking)
+}
+
+func TestHealthChecker_TimesOutSlowChecks(t *testing.T) {
+	var running, peak atomic.Int32
+	checker := NewHealthChecker(map[domain.Provider]ProviderClient{
+		domain.ProviderOpenAI: &blockingProviderClient{running: &running, peak: &peak, release: make(chan struct{})},
+	}, time.Minute, 10*time.Millisecond, 0, 0, logger.NewNoop())
+
+	checker.checkAllProviders()
+	checker.wg.Wait()
+	healthy, probed := checker.Probed(domain.ProviderOpenAI)
+	assert.True(t, probed)
+	assert.False(t, healthy)
+}
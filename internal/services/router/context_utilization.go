@@ -0,0 +1,47 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// contextUtilization measures the fraction of the model's context window the
+// request's prompt fills and returns it when it passes the configured
+// warning threshold. Models without a known context length are skipped.
+// With record set the measurement goes into the histogram; a stream failing
+// over measures again for the next provider but records only its first, so
+// every request is observed once.
+func (s *Service) contextUtilization(req *domain.CompletionRequest, provider domain.Provider, record bool) (float64, bool) {
+	s.mu.RLock()
+	contextLength := 0
+	if model := s.providerModel(provider, req.Model); model != nil {
+		contextLength = model.ContextLength
+	}
+	s.mu.RUnlock()
+	if contextLength <= 0 {
+		return 0, false
+	}
+
+	utilization := float64(domain.EstimatePromptTokens(req.Messages)) / float64(contextLength)
+	if record {
+		contextUtilizationRatio.WithLabelValues(req.Model).Observe(utilization)
+	}
+
+	threshold := s.currentConfig().ContextUtilizationWarning
+	if threshold <= 0 || utilization < threshold {
+		return 0, false
+	}
+	s.logger.Warn("Prompt is close to the model's context window",
+		logger.F("model", req.Model),
+		logger.F("provider", provider),
+		logger.F("request_id", req.RequestID),
+		logger.F("utilization", utilization),
+	)
+	return utilization, true
+}
+
+// setContextUtilizationHeader sets the warning header on a streamed response
+func setContextUtilizationHeader(c *gin.Context, utilization float64) {
+	c.Header(domain.ContextUtilizationHeader, domain.ContextUtilizationWarning(utilization))
+}
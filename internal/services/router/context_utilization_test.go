@@ -0,0 +1,82 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+func TestRouteCompletion_ContextUtilizationWarning(t *testing.T) {
+	service := newCacheTestService(&countingProviderClient{}, nil)
+	// "Hello" estimates to 6 prompt tokens
+	service.modelRegistry = map[string]map[domain.Provider]*domain.Model{
+		"gpt-4o": {domain.ProviderOpenAI: {ModelID: "gpt-4o", Provider: domain.ProviderOpenAI, ContextLength: 10}},
+	}
+
+	response, err := service.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	assert.NotContains(t, response.Metadata, domain.MetadataKeyContextUtilization)
+
+	service.config.ContextUtilizationWarning = 0.5
+	response, err = service.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	assert.InDelta(t, 0.6, response.Metadata[domain.MetadataKeyContextUtilization], 0.001)
+
+	service.config.ContextUtilizationWarning = 0.9
+	response, err = service.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	assert.NotContains(t, response.Metadata, domain.MetadataKeyContextUtilization)
+}
+
+func TestContextUtilization_UnknownContextLength(t *testing.T) {
+	service := newCacheTestService(&countingProviderClient{}, nil)
+	service.config.ContextUtilizationWarning = 0.1
+
+	_, warn := service.contextUtilization(newCacheTestRequest("tenant-a"), domain.ProviderOpenAI, true)
+	assert.False(t, warn)
+}
+
+// contextUtilizationSamples counts the histogram's observations for a model
+func contextUtilizationSamples(t *testing.T, model string) uint64 {
+	t.Helper()
+	registry := prometheus.NewRegistry()
+	require.NoError(t, registry.Register(contextUtilizationRatio))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "model" && label.GetValue() == model {
+					return metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestRouteCompletionStream_RecordsContextUtilizationOnceAcrossFailover(t *testing.T) {
+	failure := shared_errors.ProviderUnavailableError("openai")
+	primary := &scriptedStreamClient{chunks: []*domain.StreamResponse{{Error: failure}}}
+	secondary := &scriptedStreamClient{chunks: []*domain.StreamResponse{textChunk("Hello"), {Done: true}}}
+	s := newStreamFailoverService(primary, secondary)
+	s.config.ContextUtilizationWarning = 0.5
+	req := newStreamFailoverRequest()
+	req.Model = "gpt-4o-failover-utilization"
+	s.registerModel(&domain.Model{ModelID: req.Model, Provider: domain.ProviderOpenAI, ContextLength: 10})
+	s.registerModel(&domain.Model{ModelID: req.Model, Provider: domain.ProviderAzureOpenAI, ContextLength: 10})
+
+	w, err := routeTestStream(t, s, req)
+	require.NoError(t, err)
+	require.Equal(t, 1, secondary.streams)
+	assert.Equal(t, uint64(1), contextUtilizationSamples(t, req.Model))
+	// The warning still describes the provider that served the stream
+	assert.Equal(t, "0.60", w.Header().Get(domain.ContextUtilizationHeader))
+}
@@ -0,0 +1,44 @@
+package router
+
+import (
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// normalizeCreated replaces the provider's Created timestamp when it is
+// missing or too far from the router's clock, and stamps ReceivedAt with the
+// router's time
+func (s *Service) normalizeCreated(provider domain.Provider, response *domain.CompletionResponse) {
+	now := time.Now()
+	response.Created = s.checkCreated(provider, response.Created, now)
+	response.ReceivedAt = now.UTC()
+}
+
+// normalizeChunkCreated gives every chunk of a stream the checked Created of
+// its first chunk, so chunks order consistently even when the provider
+// stamps each one with its own clock. first holds the stream's timestamp
+// once the first chunk has set it.
+func (s *Service) normalizeChunkCreated(provider domain.Provider, chunk *domain.StreamResponse, first *int64) {
+	if *first == 0 {
+		*first = s.checkCreated(provider, chunk.Created, time.Now())
+	}
+	chunk.Created = *first
+}
+
+func (s *Service) checkCreated(provider domain.Provider, created int64, now time.Time) int64 {
+	normalized, reason := domain.NormalizeCreated(created, now, s.currentConfig().CreatedMaxSkew)
+	if reason == "" {
+		return created
+	}
+
+	createdTimestampsAdjusted.WithLabelValues(string(provider), reason).Inc()
+	if reason == domain.CreatedSkewed {
+		s.logger.Debug("Replacing skewed provider timestamp",
+			logger.F("provider", provider),
+			logger.F("created", created),
+			logger.F("skew_seconds", created-now.Unix()))
+	}
+	return normalized
+}
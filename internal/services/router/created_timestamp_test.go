@@ -0,0 +1,68 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// createdProviderClient answers with a fixed Created timestamp
+type createdProviderClient struct {
+	countingProviderClient
+	created int64
+}
+
+func (c *createdProviderClient) CreateCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+	response, err := c.countingProviderClient.CreateCompletion(ctx, req)
+	response.Created = c.created
+	return response, err
+}
+
+func TestRouteCompletion_NormalizesCreated(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		created  int64
+		replaced bool
+	}{
+		{"accurate", now.Unix() - 2, false},
+		{"missing", 0, true},
+		{"skewed", now.Add(time.Hour).Unix(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newCacheTestService(&createdProviderClient{created: tt.created}, nil)
+			s.config.CreatedMaxSkew = 5 * time.Minute
+
+			response, err := s.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+			require.NoError(t, err)
+			if tt.replaced {
+				assert.WithinDuration(t, time.Now(), time.Unix(response.Created, 0), 5*time.Second)
+			} else {
+				assert.Equal(t, tt.created, response.Created)
+			}
+			assert.WithinDuration(t, time.Now(), response.ReceivedAt, 5*time.Second)
+			assert.Equal(t, time.UTC, response.ReceivedAt.Location())
+		})
+	}
+}
+
+func TestNormalizeChunkCreated_PinsFirstChunk(t *testing.T) {
+	s := newCacheTestService(&countingProviderClient{}, nil)
+	s.config.CreatedMaxSkew = 5 * time.Minute
+
+	var created int64
+	first := &domain.StreamResponse{Created: time.Now().Unix() - 1}
+	later := &domain.StreamResponse{Created: time.Now().Unix() + 1}
+	missing := &domain.StreamResponse{}
+	for _, chunk := range []*domain.StreamResponse{first, later, missing} {
+		s.normalizeChunkCreated(domain.ProviderAWSBedrock, chunk, &created)
+	}
+	assert.Equal(t, first.Created, later.Created)
+	assert.Equal(t, first.Created, missing.Created)
+}
@@ -0,0 +1,367 @@
+package router
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// TenantCredentials are a tenant's own keys for one provider, so requests
+// are billed to and rate limited on the tenant's account. For AWS Bedrock the
+// API key is the access key ID.
+type TenantCredentials struct {
+	APIKey    string `json:"api_key"`
+	SecretKey string `json:"secret_key,omitempty"`
+	BaseURL   string `json:"base_url,omitempty"`
+}
+
+// String keeps credentials out of logs and error messages
+func (c TenantCredentials) String() string {
+	return "[redacted]"
+}
+
+// GoString keeps credentials out of %#v output
+func (c TenantCredentials) GoString() string {
+	return "[redacted]"
+}
+
+// validate checks the credentials carry everything the provider needs, so a
+// partial set never silently mixes with the platform's shared keys
+func (c *TenantCredentials) validate(provider domain.Provider) error {
+	missing := ""
+	switch {
+	case c.APIKey == "":
+		missing = "api_key"
+	case provider == domain.ProviderAWSBedrock && c.SecretKey == "":
+		missing = "secret_key"
+	case provider == domain.ProviderAzureOpenAI && c.BaseURL == "":
+		missing = "base_url"
+	}
+	if missing != "" {
+		return shared_errors.ConfigurationError(fmt.Sprintf("tenant credentials for %s are missing %s", provider, missing))
+	}
+	return nil
+}
+
+func (c *TenantCredentials) fingerprint() [sha256.Size]byte {
+	return sha256.Sum256([]byte(c.APIKey + "\x00" + c.SecretKey + "\x00" + c.BaseURL))
+}
+
+// CredentialStore resolves a tenant's provider credentials. It returns nil
+// credentials and no error when the tenant has none for the provider.
+type CredentialStore interface {
+	GetCredentials(ctx context.Context, tenantID domain.TenantID, provider domain.Provider) (*TenantCredentials, error)
+}
+
+// DirCredentialStore reads credentials from <dir>/<tenant>/<provider>.json,
+// the layout of a mounted secret volume
+type DirCredentialStore struct {
+	dir string
+}
+
+// NewDirCredentialStore creates a credential store rooted at dir
+func NewDirCredentialStore(dir string) *DirCredentialStore {
+	return &DirCredentialStore{dir: dir}
+}
+
+func (s *DirCredentialStore) GetCredentials(ctx context.Context, tenantID domain.TenantID, provider domain.Provider) (*TenantCredentials, error) {
+	if !isPathSegment(string(tenantID)) || !isPathSegment(string(provider)) {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, string(tenantID), string(provider)+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, shared_errors.InternalError("failed to read tenant credentials", err)
+	}
+
+	var credentials TenantCredentials
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		// The decode error is dropped as it can quote the file
+		return nil, shared_errors.ConfigurationError(fmt.Sprintf("tenant credentials for %s are malformed", provider))
+	}
+	return &credentials, nil
+}
+
+// isPathSegment reports whether value names a single directory entry, so a
+// tenant ID cannot reach outside the credentials directory
+func isPathSegment(value string) bool {
+	return value != "" && value != "." && value != ".." && !strings.ContainsAny(value, `/\`)
+}
+
+type tenantClientKey struct {
+	tenantID domain.TenantID
+	provider domain.Provider
+}
+
+type tenantClientEntry struct {
+	// client is nil when the tenant uses the shared credentials
+	client      ProviderClient
+	fingerprint [sha256.Size]byte
+	expires     time.Time
+
+	// refs counts the calls using client. A retired client is closed once
+	// none are left, so rotation never cuts off a request in flight.
+	refs    int
+	retired bool
+}
+
+// tenantClientLoad is a lookup of a tenant's credentials in progress, which
+// concurrent misses for the same tenant wait on rather than repeat
+type tenantClientLoad struct {
+	done  chan struct{}
+	entry *tenantClientEntry
+	err   error
+}
+
+// TenantClients caches provider clients built from tenant credentials. Only
+// a hash of the credentials is kept beside each client; the keys themselves
+// live in the client and are read again from the store once the TTL passes.
+type TenantClients struct {
+	store   CredentialStore
+	ttl     time.Duration
+	build   func(provider domain.Provider, credentials *TenantCredentials) (ProviderClient, error)
+	logger  logger.Logger
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[tenantClientKey]*tenantClientEntry
+	loading map[tenantClientKey]*tenantClientLoad
+}
+
+// NewTenantClients creates a tenant client cache. build constructs a client
+// for a provider from a tenant's credentials.
+func NewTenantClients(store CredentialStore, ttl time.Duration, build func(domain.Provider, *TenantCredentials) (ProviderClient, error), log logger.Logger) *TenantClients {
+	return &TenantClients{
+		store:   store,
+		ttl:     ttl,
+		build:   build,
+		logger:  log,
+		now:     time.Now,
+		entries: make(map[tenantClientKey]*tenantClientEntry),
+		loading: make(map[tenantClientKey]*tenantClientLoad),
+	}
+}
+
+// Get returns the tenant's own client for the provider, or nil when the
+// tenant has no credentials and should use the shared client. The returned
+// function must be called once the caller is done with the client.
+func (t *TenantClients) Get(ctx context.Context, tenantID domain.TenantID, provider domain.Provider) (ProviderClient, func(), error) {
+	key := tenantClientKey{tenantID: tenantID, provider: provider}
+
+	for {
+		t.mu.Lock()
+		cached, ok := t.entries[key]
+		if ok && t.now().Before(cached.expires) {
+			release := t.acquire(cached)
+			t.mu.Unlock()
+			return cached.client, release, nil
+		}
+
+		load, loading := t.loading[key]
+		if !loading {
+			load = &tenantClientLoad{done: make(chan struct{})}
+			t.loading[key] = load
+			t.mu.Unlock()
+
+			// The lookup is shared, so one caller giving up does not fail
+			// the others
+			entry, err := t.load(context.WithoutCancel(ctx), key, cached)
+			return t.finishLoad(key, load, entry, err)
+		}
+		t.mu.Unlock()
+
+		select {
+		case <-load.done:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+		if load.err != nil {
+			return nil, nil, load.err
+		}
+
+		t.mu.Lock()
+		if t.entries[key] == load.entry {
+			release := t.acquire(load.entry)
+			t.mu.Unlock()
+			return load.entry.client, release, nil
+		}
+		// Replaced since the load finished; look again
+		t.mu.Unlock()
+	}
+}
+
+// load reads the tenant's credentials and returns the entry to cache,
+// reusing cached when the keys have not changed
+func (t *TenantClients) load(ctx context.Context, key tenantClientKey, cached *tenantClientEntry) (*tenantClientEntry, error) {
+	credentials, err := t.store.GetCredentials(ctx, key.tenantID, key.provider)
+	if err != nil {
+		return nil, err
+	}
+	if credentials == nil {
+		return &tenantClientEntry{}, nil
+	}
+	if err := credentials.validate(key.provider); err != nil {
+		return nil, err
+	}
+
+	fingerprint := credentials.fingerprint()
+	if cached != nil && cached.client != nil && cached.fingerprint == fingerprint {
+		// Unchanged keys keep the existing client and its connections
+		return cached, nil
+	}
+
+	client, err := t.build(key.provider, credentials)
+	if err != nil {
+		return nil, err
+	}
+	t.logger.Info("Tenant provider client created",
+		logger.F("tenant_id", key.tenantID),
+		logger.F("provider", key.provider))
+	return &tenantClientEntry{client: client, fingerprint: fingerprint}, nil
+}
+
+// finishLoad caches a loaded entry, retiring the one it replaces and any
+// that expired, and wakes the callers waiting on the load
+func (t *TenantClients) finishLoad(key tenantClientKey, load *tenantClientLoad, entry *tenantClientEntry, err error) (ProviderClient, func(), error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.loading, key)
+	load.entry, load.err = entry, err
+	close(load.done)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := t.now()
+	for k, e := range t.entries {
+		// Entries being reloaded may still be reused by their load
+		if _, loading := t.loading[k]; k != key && !loading && !now.Before(e.expires) {
+			delete(t.entries, k)
+			t.retire(e)
+		}
+	}
+	if replaced, ok := t.entries[key]; ok && replaced != entry {
+		t.retire(replaced)
+	}
+	entry.expires = now.Add(t.ttl)
+	t.entries[key] = entry
+
+	return entry.client, t.acquire(entry), nil
+}
+
+// acquire counts a call using the entry's client and returns the function
+// that ends it. t.mu must be held.
+func (t *TenantClients) acquire(entry *tenantClientEntry) func() {
+	if entry.client == nil {
+		return func() {}
+	}
+	entry.refs++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			entry.refs--
+			if entry.retired && entry.refs == 0 {
+				closeClient(entry.client)
+			}
+		})
+	}
+}
+
+// retire marks an entry no longer cached, closing its client now if no call
+// is using it or else when the last one finishes. t.mu must be held.
+func (t *TenantClients) retire(entry *tenantClientEntry) {
+	if entry.retired {
+		return
+	}
+	entry.retired = true
+	if entry.refs == 0 {
+		closeClient(entry.client)
+	}
+}
+
+// closeClient releases the connections of a client no longer cached, for
+// clients that hold any
+func closeClient(client ProviderClient) {
+	if closer, ok := client.(io.Closer); ok {
+		closer.Close()
+	}
+}
+
+type tenantScopeKey struct{}
+
+// withTenantScope marks calls made with a tenant's own credentials
+func withTenantScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tenantScopeKey{}, true)
+}
+
+// isTenantScoped reports whether a call uses a tenant's own credentials. Its
+// failures and rate limits reflect the tenant's account, not the shared one,
+// so they are kept out of the circuit breaker and concurrency limiter.
+func isTenantScoped(ctx context.Context) bool {
+	scoped, _ := ctx.Value(tenantScopeKey{}).(bool)
+	return scoped
+}
+
+// resolveClient returns the client to call a provider with for a tenant:
+// the tenant's own when it has credentials, otherwise the shared one. The
+// returned context marks tenant-scoped calls, and the returned function must
+// be called once the client is no longer used.
+func (s *Service) resolveClient(ctx context.Context, tenantID domain.TenantID, provider domain.Provider) (context.Context, ProviderClient, func(), error) {
+	if s.tenantClients != nil {
+		client, release, err := s.tenantClients.Get(ctx, tenantID, provider)
+		if err != nil {
+			s.logger.Error("Failed to resolve tenant credentials",
+				logger.F("tenant_id", tenantID),
+				logger.F("provider", provider),
+				logger.F("error", err))
+			return ctx, nil, nil, err
+		}
+		if client != nil {
+			return withTenantScope(ctx), client, release, nil
+		}
+	}
+
+	client, _ := s.providerClient(provider)
+	return ctx, client, func() {}, nil
+}
+
+// createTenantProviderClient builds a client from the shared provider
+// settings with the tenant's credentials swapped in
+func (s *Service) createTenantProviderClient(provider domain.Provider, credentials *TenantCredentials) (ProviderClient, error) {
+	config := s.currentConfig().Providers[string(provider)]
+	config.APIKey = credentials.APIKey
+	config.SecretKey = credentials.SecretKey
+	if credentials.BaseURL != "" {
+		config.BaseURL = credentials.BaseURL
+	}
+	return s.createProviderClient(provider, config)
+}
+
+// limiterOutcome classifies a provider call for the concurrency limiter. A
+// tenant's own rate limits say nothing about shared capacity.
+func limiterOutcome(ctx context.Context, err error) LimiterOutcome {
+	outcome := classifyLimiterOutcome(err)
+	if outcome == LimiterOutcomeOverload && isTenantScoped(ctx) {
+		return LimiterOutcomeIgnore
+	}
+	return outcome
+}
@@ -0,0 +1,321 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+type fakeCredentialStore struct {
+	credentials map[domain.TenantID]*TenantCredentials
+	lookups     int
+}
+
+func (s *fakeCredentialStore) GetCredentials(ctx context.Context, tenantID domain.TenantID, provider domain.Provider) (*TenantCredentials, error) {
+	s.lookups++
+	return s.credentials[tenantID], nil
+}
+
+func TestDirCredentialStore_GetCredentials(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "tenant-a"), 0o700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tenant-a", "azure-openai.json"),
+		[]byte(`{"api_key": "tenant-key", "base_url": "https://tenant.openai.azure.com"}`), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "azure-openai.json"), []byte(`{"api_key": "outside"}`), 0o600))
+
+	store := NewDirCredentialStore(dir)
+
+	credentials, err := store.GetCredentials(context.Background(), "tenant-a", domain.ProviderAzureOpenAI)
+	require.NoError(t, err)
+	assert.Equal(t, "tenant-key", credentials.APIKey)
+	assert.Equal(t, "https://tenant.openai.azure.com", credentials.BaseURL)
+
+	// Tenants without a file use the shared credentials
+	credentials, err = store.GetCredentials(context.Background(), "tenant-b", domain.ProviderAzureOpenAI)
+	require.NoError(t, err)
+	assert.Nil(t, credentials)
+
+	// Tenant IDs cannot reach outside their own directory
+	credentials, err = store.GetCredentials(context.Background(), "..", domain.ProviderAzureOpenAI)
+	require.NoError(t, err)
+	assert.Nil(t, credentials)
+	credentials, err = store.GetCredentials(context.Background(), "tenant-a/..", domain.ProviderAzureOpenAI)
+	require.NoError(t, err)
+	assert.Nil(t, credentials)
+}
+
+func TestTenantCredentials_Redacted(t *testing.T) {
+	credentials := TenantCredentials{APIKey: "sk-secret", SecretKey: "aws-secret"}
+
+	for _, formatted := range []string{
+		fmt.Sprint(credentials),
+		fmt.Sprintf("%v %+v %#v", credentials, credentials, credentials),
+		fmt.Sprint(&credentials),
+	} {
+		assert.NotContains(t, formatted, "secret")
+	}
+}
+
+func TestTenantClients_CachesUntilTTL(t *testing.T) {
+	store := &fakeCredentialStore{credentials: map[domain.TenantID]*TenantCredentials{
+		"tenant-a": {APIKey: "key-1", SecretKey: "secret-1"},
+	}}
+	builds := 0
+	clients := NewTenantClients(store, time.Minute, func(provider domain.Provider, credentials *TenantCredentials) (ProviderClient, error) {
+		builds++
+		return &countingProviderClient{}, nil
+	}, logger.NewNoop())
+	now := time.Now()
+	clients.now = func() time.Time { return now }
+
+	first, _, err := clients.Get(context.Background(), "tenant-a", domain.ProviderAWSBedrock)
+	require.NoError(t, err)
+	require.NotNil(t, first)
+
+	second, _, err := clients.Get(context.Background(), "tenant-a", domain.ProviderAWSBedrock)
+	require.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Equal(t, 1, store.lookups)
+
+	// After the TTL the store is read again; unchanged keys keep the client
+	now = now.Add(2 * time.Minute)
+	third, _, err := clients.Get(context.Background(), "tenant-a", domain.ProviderAWSBedrock)
+	require.NoError(t, err)
+	assert.Same(t, first, third)
+	assert.Equal(t, 2, store.lookups)
+	assert.Equal(t, 1, builds)
+
+	// Rotated keys build a new client
+	store.credentials["tenant-a"] = &TenantCredentials{APIKey: "key-2", SecretKey: "secret-2"}
+	now = now.Add(2 * time.Minute)
+	rotated, _, err := clients.Get(context.Background(), "tenant-a", domain.ProviderAWSBedrock)
+	require.NoError(t, err)
+	assert.NotSame(t, first, rotated)
+	assert.Equal(t, 2, builds)
+
+	// Tenants without credentials fall back to the shared client
+	shared, _, err := clients.Get(context.Background(), "tenant-b", domain.ProviderAWSBedrock)
+	require.NoError(t, err)
+	assert.Nil(t, shared)
+}
+
+func TestTenantClients_RejectsIncompleteCredentials(t *testing.T) {
+	store := &fakeCredentialStore{credentials: map[domain.TenantID]*TenantCredentials{
+		"tenant-a": {APIKey: "access-key-only"},
+	}}
+	clients := NewTenantClients(store, time.Minute, func(provider domain.Provider, credentials *TenantCredentials) (ProviderClient, error) {
+		t.Fatal("client built from incomplete credentials")
+		return nil, nil
+	}, logger.NewNoop())
+
+	client, _, err := clients.Get(context.Background(), "tenant-a", domain.ProviderAWSBedrock)
+	assert.Nil(t, client)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "access-key-only")
+}
+
+func TestRouteCompletion_UsesTenantCredentials(t *testing.T) {
+	shared := &countingProviderClient{}
+	tenant := &countingProviderClient{}
+	s := newCacheTestService(shared, nil)
+	s.tenantClients = NewTenantClients(&fakeCredentialStore{credentials: map[domain.TenantID]*TenantCredentials{
+		"tenant-a": {APIKey: "tenant-key"},
+	}}, time.Minute, func(provider domain.Provider, credentials *TenantCredentials) (ProviderClient, error) {
+		return tenant, nil
+	}, logger.NewNoop())
+
+	_, err := s.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, tenant.calls)
+	assert.Equal(t, 0, shared.calls)
+
+	_, err = s.routeCompletion(context.Background(), newCacheTestRequest("tenant-b"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, shared.calls)
+}
+
+type closableProviderClient struct {
+	countingProviderClient
+	closed bool
+}
+
+func (c *closableProviderClient) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestTenantClients_ClosesReplacedAndExpiredClients(t *testing.T) {
+	store := &fakeCredentialStore{credentials: map[domain.TenantID]*TenantCredentials{
+		"tenant-a": {APIKey: "key-1"},
+		"tenant-b": {APIKey: "key-b"},
+	}}
+	clients := NewTenantClients(store, time.Minute, func(provider domain.Provider, credentials *TenantCredentials) (ProviderClient, error) {
+		return &closableProviderClient{}, nil
+	}, logger.NewNoop())
+	now := time.Now()
+	clients.now = func() time.Time { return now }
+
+	first, releaseFirst, err := clients.Get(context.Background(), "tenant-a", domain.ProviderOpenAI)
+	require.NoError(t, err)
+	releaseFirst()
+	other, releaseOther, err := clients.Get(context.Background(), "tenant-b", domain.ProviderOpenAI)
+	require.NoError(t, err)
+	releaseOther()
+
+	// Rotated keys replace the client, closing the old one
+	store.credentials["tenant-a"] = &TenantCredentials{APIKey: "key-2"}
+	now = now.Add(2 * time.Minute)
+	rotated, _, err := clients.Get(context.Background(), "tenant-a", domain.ProviderOpenAI)
+	require.NoError(t, err)
+	assert.True(t, first.(*closableProviderClient).closed)
+	assert.False(t, rotated.(*closableProviderClient).closed)
+
+	// The other tenant's entry expired and was swept
+	assert.True(t, other.(*closableProviderClient).closed)
+}
+
+func TestTenantClients_ClosesRetiredClientsOnceUnused(t *testing.T) {
+	store := &fakeCredentialStore{credentials: map[domain.TenantID]*TenantCredentials{
+		"tenant-a": {APIKey: "key-1"},
+	}}
+	clients := NewTenantClients(store, time.Minute, func(provider domain.Provider, credentials *TenantCredentials) (ProviderClient, error) {
+		return &closableProviderClient{}, nil
+	}, logger.NewNoop())
+	now := time.Now()
+	clients.now = func() time.Time { return now }
+
+	// Two calls are still using the client when the keys rotate
+	first, releaseFirst, err := clients.Get(context.Background(), "tenant-a", domain.ProviderOpenAI)
+	require.NoError(t, err)
+	_, releaseSecond, err := clients.Get(context.Background(), "tenant-a", domain.ProviderOpenAI)
+	require.NoError(t, err)
+
+	store.credentials["tenant-a"] = &TenantCredentials{APIKey: "key-2"}
+	now = now.Add(2 * time.Minute)
+	rotated, releaseRotated, err := clients.Get(context.Background(), "tenant-a", domain.ProviderOpenAI)
+	require.NoError(t, err)
+	assert.NotSame(t, first, rotated)
+	assert.False(t, first.(*closableProviderClient).closed)
+
+	// Releasing twice does not count twice
+	releaseFirst()
+	releaseFirst()
+	assert.False(t, first.(*closableProviderClient).closed)
+
+	releaseSecond()
+	assert.True(t, first.(*closableProviderClient).closed)
+
+	// The current client stays open when released
+	releaseRotated()
+	assert.False(t, rotated.(*closableProviderClient).closed)
+}
+
+// blockingCredentialStore holds lookups until release is closed
+type blockingCredentialStore struct {
+	credentials *TenantCredentials
+	release     chan struct{}
+	lookups     atomic.Int32
+}
+
+func (s *blockingCredentialStore) GetCredentials(ctx context.Context, tenantID domain.TenantID, provider domain.Provider) (*TenantCredentials, error) {
+	s.lookups.Add(1)
+	<-s.release
+	return s.credentials, nil
+}
+
+func TestTenantClients_ConcurrentMissesLoadOnce(t *testing.T) {
+	store := &blockingCredentialStore{credentials: &TenantCredentials{APIKey: "key-1"}, release: make(chan struct{})}
+	var builds atomic.Int32
+	clients := NewTenantClients(store, time.Minute, func(provider domain.Provider, credentials *TenantCredentials) (ProviderClient, error) {
+		builds.Add(1)
+		return &closableProviderClient{}, nil
+	}, logger.NewNoop())
+
+	const callers = 8
+	results := make(chan ProviderClient, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client, release, err := clients.Get(context.Background(), "tenant-a", domain.ProviderOpenAI)
+			if assert.NoError(t, err) {
+				release()
+			}
+			results <- client
+		}()
+	}
+
+	require.Eventually(t, func() bool { return store.lookups.Load() == 1 }, time.Second, time.Millisecond)
+	close(store.release)
+	wg.Wait()
+	close(results)
+
+	assert.Equal(t, int32(1), store.lookups.Load())
+	assert.Equal(t, int32(1), builds.Load())
+	first := <-results
+	require.NotNil(t, first)
+	for client := range results {
+		assert.Same(t, first, client)
+	}
+}
+
+func TestTenantClients_WaiterGivesUpWithoutCancellingLoad(t *testing.T) {
+	store := &blockingCredentialStore{credentials: &TenantCredentials{APIKey: "key-1"}, release: make(chan struct{})}
+	clients := NewTenantClients(store, time.Minute, func(provider domain.Provider, credentials *TenantCredentials) (ProviderClient, error) {
+		return &closableProviderClient{}, nil
+	}, logger.NewNoop())
+
+	loaded := make(chan ProviderClient, 1)
+	go func() {
+		client, _, _ := clients.Get(context.Background(), "tenant-a", domain.ProviderOpenAI)
+		loaded <- client
+	}()
+	require.Eventually(t, func() bool { return store.lookups.Load() == 1 }, time.Second, time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := clients.Get(ctx, "tenant-a", domain.ProviderOpenAI)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	close(store.release)
+	assert.NotNil(t, <-loaded)
+}
+
+func TestRouteCompletion_TenantCredentialsBypassSharedCircuitBreaker(t *testing.T) {
+	shared := &countingProviderClient{}
+	tenant := &countingProviderClient{}
+	s := newCacheTestService(shared, nil)
+	s.tenantClients = NewTenantClients(&fakeCredentialStore{credentials: map[domain.TenantID]*TenantCredentials{
+		"tenant-a": {APIKey: "tenant-key"},
+	}}, time.Minute, func(provider domain.Provider, credentials *TenantCredentials) (ProviderClient, error) {
+		return tenant, nil
+	}, logger.NewNoop())
+	state := s.circuitBreaker.getOrCreateState(domain.ProviderOpenAI)
+	state.State = CircuitStateOpen
+	state.LastFailure = time.Now()
+
+	// The tenant's own account is called despite the open shared circuit
+	req := newCacheTestRequest("tenant-a")
+	req.CacheEnabled = false
+	_, err := s.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, tenant.calls)
+	assert.Equal(t, CircuitStateOpen, state.State)
+
+	// Shared traffic is still held back
+	_, err = s.routeCompletion(context.Background(), newCacheTestRequest("tenant-b"))
+	require.Error(t, err)
+	assert.Equal(t, 0, shared.calls)
+}
@@ -0,0 +1,59 @@
+package router
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// providerInRegion reports whether the provider may serve a request
+// restricted to the data-residency region. Requests without a region may use
+// any provider; providers without a region serve none that have one.
+func (s *Service) providerInRegion(provider domain.Provider, region string) bool {
+	if region == "" {
+		return true
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	config, ok := s.providerConfigs[provider]
+	return ok && config.DataRegion != "" && strings.EqualFold(config.DataRegion, region)
+}
+
+// providersInRegion returns the providers that may serve a request
+// restricted to the region
+func (s *Service) providersInRegion(providers []domain.Provider, region string) []domain.Provider {
+	if region == "" {
+		return providers
+	}
+	inRegion := make([]domain.Provider, 0, len(providers))
+	for _, provider := range providers {
+		if s.providerInRegion(provider, region) {
+			inRegion = append(inRegion, provider)
+		}
+	}
+	return inRegion
+}
+
+// checkDataResidency rejects a pinned provider outside the request's region,
+// so pinning cannot send the request out of the region
+func (s *Service) checkDataResidency(provider domain.Provider, modelID, region string) error {
+	if s.providerInRegion(provider, region) {
+		return nil
+	}
+	return dataResidencyError(fmt.Sprintf("provider %s does not serve from data residency region %s", provider, region), modelID).
+		WithDetail("provider", string(provider)).
+		Build()
+}
+
+// dataResidencyError is returned rather than routing a request out of its
+// data-residency region
+func dataResidencyError(message, modelID string) *shared_errors.ErrorBuilder {
+	return shared_errors.NewError(shared_errors.ErrorTypeValidation, message).
+		WithCode(shared_errors.CodeDataResidencyUnavailable).
+		WithDetail("field", "data_residency").
+		WithDetail("model", modelID).
+		WithStatusCode(http.StatusUnprocessableEntity)
+}
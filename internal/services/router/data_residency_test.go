@@ -0,0 +1,83 @@
+package router
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// newResidencyTestService serves gpt-4o from Azure OpenAI in the EU and
+// from OpenAI in the US
+func newResidencyTestService() *Service {
+	s := newAutoRoutingService(env.AutoRoutingWeights{Cost: 1})
+	s.providerConfigs[domain.ProviderAzureOpenAI].DataRegion = "eu"
+	s.providerConfigs[domain.ProviderOpenAI].DataRegion = "us"
+	return s
+}
+
+func TestSelectProvider_StaysInDataResidencyRegion(t *testing.T) {
+	s := newResidencyTestService()
+
+	// Restricting the region leaves one provider, so the model is not
+	// ambiguous
+	provider, err := s.selectProvider("tenant-a", "gpt-4o", "", "eu")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderAzureOpenAI, provider)
+	provider, err = s.selectProvider("tenant-a", "gpt-4o", "", "US")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderOpenAI, provider)
+
+	s.config.AutoRoutingWeights = env.AutoRoutingWeights{Latency: 1}
+	provider, scores, err := s.selectAutoProvider("tenant-a", "gpt-4o", "eu")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderAzureOpenAI, provider)
+	assert.Len(t, scores, 1)
+
+	// Pinning a provider cannot leave the region
+	_, err = s.selectProvider("tenant-a", "gpt-4o", domain.ProviderOpenAI, "eu")
+	require.Error(t, err)
+	qlensErr := shared_errors.FromError(err)
+	assert.Equal(t, "data_residency_unavailable", qlensErr.Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, qlensErr.HTTPStatusCode())
+	assert.Equal(t, "openai", qlensErr.PublicError().Details["provider"])
+	// The error taxonomy lists the code with the status it is returned with
+	info, ok := shared_errors.DescribeErrorCode(qlensErr.Type, qlensErr.Code)
+	require.True(t, ok)
+	assert.Equal(t, qlensErr.HTTPStatusCode(), info.StatusCode)
+}
+
+func TestSelectProvider_NoCompliantProvider(t *testing.T) {
+	s := newResidencyTestService()
+
+	// The EU provider being down does not send EU requests to the US
+	s.providerConfigs[domain.ProviderAzureOpenAI].Enabled = false
+	_, err := s.selectProvider("tenant-a", "gpt-4o", "", "eu")
+	require.Error(t, err)
+	assert.Equal(t, "data_residency_unavailable", shared_errors.FromError(err).Code)
+
+	_, _, err = s.selectAutoProvider("tenant-a", "gpt-4o", "eu")
+	assert.Equal(t, "data_residency_unavailable", shared_errors.FromError(err).Code)
+
+	// Providers without a region serve no restricted requests
+	s.providerConfigs[domain.ProviderOpenAI].DataRegion = ""
+	_, err = s.selectProvider("tenant-a", "gpt-4o", "", "us")
+	assert.Equal(t, "data_residency_unavailable", shared_errors.FromError(err).Code)
+	provider, err := s.selectProvider("tenant-a", "gpt-4o", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderOpenAI, provider)
+}
+
+func TestGenerateCacheKey_SeparatesDataResidency(t *testing.T) {
+	s := newResidencyTestService()
+	req := newCacheTestRequest("tenant-a")
+	key := s.generateCacheKey("tenant-a", req)
+
+	req.DataResidency = "eu"
+	assert.NotEqual(t, key, s.generateCacheKey("tenant-a", req))
+}
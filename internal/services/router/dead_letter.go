@@ -0,0 +1,245 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// Operations recorded in dead letters
+const (
+	deadLetterOperationCompletion       = "completion"
+	deadLetterOperationCompletionStream = "completion_stream"
+	deadLetterOperationEmbedding        = "embedding"
+)
+
+// DeadLetter records a request that failed after every retry and fallback,
+// for analysing provider outages after the fact. Prompt text, images, tool
+// arguments and embedding inputs are redacted from the request.
+type DeadLetter struct {
+	Timestamp time.Time           `json:"timestamp"`
+	Operation string              `json:"operation"`
+	RequestID string              `json:"request_id,omitempty"`
+	TenantID  domain.TenantID     `json:"tenant_id,omitempty"`
+	UserID    domain.UserID       `json:"user_id,omitempty"`
+	Model     string              `json:"model"`
+	Request   interface{}         `json:"request"`
+	Attempts  []DeadLetterAttempt `json:"attempts"`
+	ErrorType string              `json:"error_type"`
+	Error     string              `json:"error"`
+}
+
+// DeadLetterAttempt is one failed provider call, or a provider skipped
+// because its circuit was open
+type DeadLetterAttempt struct {
+	Timestamp  time.Time       `json:"timestamp"`
+	Provider   domain.Provider `json:"provider"`
+	Model      string          `json:"model"`
+	Attempt    int             `json:"attempt"`
+	ErrorType  string          `json:"error_type"`
+	Error      string          `json:"error"`
+	StatusCode int             `json:"status_code"`
+}
+
+// DeadLetterStore durably keeps dead letters
+type DeadLetterStore interface {
+	Put(ctx context.Context, letter *DeadLetter) error
+}
+
+// FileDeadLetterStore appends dead letters to a file as JSON lines
+type FileDeadLetterStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileDeadLetterStore creates a store appending to path
+func NewFileDeadLetterStore(path string) *FileDeadLetterStore {
+	return &FileDeadLetterStore{path: path}
+}
+
+func (s *FileDeadLetterStore) Put(ctx context.Context, letter *DeadLetter) error {
+	data, err := json.Marshal(letter)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dead letter: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open dead letter file: %w", err)
+	}
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write dead letter: %w", err)
+	}
+	return file.Close()
+}
+
+type attemptLogKey struct{}
+
+// attemptLog collects the failed provider calls of one request
+type attemptLog struct {
+	mu       sync.Mutex
+	attempts []DeadLetterAttempt
+}
+
+// withAttemptLog returns a context that records the request's failed
+// provider calls for its dead letter
+func withAttemptLog(ctx context.Context) context.Context {
+	return context.WithValue(ctx, attemptLogKey{}, &attemptLog{})
+}
+
+// recordAttempt adds a failed provider call to the request's attempt log, if
+// it has one
+func recordAttempt(ctx context.Context, provider domain.Provider, model string, attempt int, err error) {
+	log, ok := ctx.Value(attemptLogKey{}).(*attemptLog)
+	if !ok || err == nil {
+		return
+	}
+
+	qlensErr := shared_errors.FromError(err)
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	log.attempts = append(log.attempts, DeadLetterAttempt{
+		Timestamp:  time.Now(),
+		Provider:   provider,
+		Model:      model,
+		Attempt:    attempt,
+		ErrorType:  string(qlensErr.Type),
+		Error:      err.Error(),
+		StatusCode: qlensErr.HTTPStatusCode(),
+	})
+}
+
+func recordedAttempts(ctx context.Context) []DeadLetterAttempt {
+	log, ok := ctx.Value(attemptLogKey{}).(*attemptLog)
+	if !ok {
+		return nil
+	}
+
+	log.mu.Lock()
+	defer log.mu.Unlock()
+	return append([]DeadLetterAttempt(nil), log.attempts...)
+}
+
+// shouldDeadLetter reports whether a failed request is worth a dead letter:
+// provider failures and unavailability, not invalid requests, exhausted
+// budgets or callers that went away
+func shouldDeadLetter(err error) bool {
+	if err == nil || shared_errors.IsCancellation(err) {
+		return false
+	}
+	return isUnavailable(err) || isProviderFailure(err)
+}
+
+// deadLetterCompletion records a failed completion request
+func (s *Service) deadLetterCompletion(ctx context.Context, operation string, req *domain.CompletionRequest, err error) {
+	if !shouldDeadLetter(err) {
+		return
+	}
+	s.putDeadLetter(ctx, &DeadLetter{
+		Operation: operation,
+		RequestID: req.RequestID,
+		TenantID:  req.TenantID,
+		UserID:    req.UserID,
+		Model:     req.Model,
+		Request:   redactCompletionRequest(req),
+	}, err)
+}
+
+// deadLetterEmbedding records a failed embedding request
+func (s *Service) deadLetterEmbedding(ctx context.Context, req *domain.EmbeddingRequest, err error) {
+	if !shouldDeadLetter(err) {
+		return
+	}
+	s.putDeadLetter(ctx, &DeadLetter{
+		Operation: deadLetterOperationEmbedding,
+		RequestID: req.RequestID,
+		TenantID:  req.TenantID,
+		UserID:    req.UserID,
+		Model:     req.Model,
+		Request:   redactEmbeddingRequest(req),
+	}, err)
+}
+
+func (s *Service) putDeadLetter(ctx context.Context, letter *DeadLetter, err error) {
+	letter.Timestamp = time.Now()
+	letter.Attempts = recordedAttempts(ctx)
+	letter.ErrorType = string(shared_errors.FromError(err).Type)
+	letter.Error = err.Error()
+
+	lastProvider := "none"
+	if len(letter.Attempts) > 0 {
+		lastProvider = string(letter.Attempts[len(letter.Attempts)-1].Provider)
+	}
+	deadLetteredRequests.WithLabelValues(letter.Operation, lastProvider).Inc()
+
+	if s.deadLetters == nil {
+		return
+	}
+
+	// The caller may already be gone; the record must still be written
+	if putErr := s.deadLetters.Put(context.WithoutCancel(ctx), letter); putErr != nil {
+		s.logger.Error("Failed to write dead letter",
+			logger.F("request_id", letter.RequestID),
+			logger.F("operation", letter.Operation),
+			logger.F("error", putErr))
+	}
+}
+
+// redactCompletionRequest copies a request with prompt text, images, tool
+// arguments, the end-user ID and caller metadata replaced, keeping the shape
+// and parameters of the request
+func redactCompletionRequest(req *domain.CompletionRequest) domain.CompletionRequest {
+	redacted := *req
+	redacted.User = redactText(req.User)
+	redacted.Metadata = nil
+	redacted.Messages = make([]domain.Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		msg.Content = make([]domain.ContentPart, len(req.Messages[i].Content))
+		for j, part := range req.Messages[i].Content {
+			part.Text = redactText(part.Text)
+			if part.ImageURL != nil {
+				part.ImageURL = &domain.ImageURL{URL: redactText(part.ImageURL.URL), Detail: part.ImageURL.Detail}
+			}
+			msg.Content[j] = part
+		}
+		msg.ToolCalls = make([]domain.ToolCall, len(req.Messages[i].ToolCalls))
+		for j, toolCall := range req.Messages[i].ToolCalls {
+			toolCall.Function.Arguments = redactText(toolCall.Function.Arguments)
+			msg.ToolCalls[j] = toolCall
+		}
+		redacted.Messages[i] = msg
+	}
+	return redacted
+}
+
+// redactEmbeddingRequest copies a request with its inputs and end-user ID
+// replaced
+func redactEmbeddingRequest(req *domain.EmbeddingRequest) domain.EmbeddingRequest {
+	redacted := *req
+	redacted.User = redactText(req.User)
+	redacted.Input = make([]string, len(req.Input))
+	for i, input := range req.Input {
+		redacted.Input[i] = redactText(input)
+	}
+	return redacted
+}
+
+// redactText replaces text with a note of its length, so a dead letter still
+// shows how large the request was
+func redactText(text string) string {
+	if text == "" {
+		return ""
+	}
+	return fmt.Sprintf("[redacted %d bytes]", len(text))
+}
@@ -0,0 +1,102 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// failingProviderClient fails every completion with err
+type failingProviderClient struct {
+	ProviderClient
+	err error
+}
+
+func (c *failingProviderClient) CreateCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+	return nil, c.err
+}
+
+func readDeadLetters(t *testing.T, path string) []DeadLetter {
+	t.Helper()
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	require.NoError(t, err)
+	defer file.Close()
+
+	var letters []DeadLetter
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var letter DeadLetter
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &letter))
+		letters = append(letters, letter)
+	}
+	return letters
+}
+
+func TestDeadLetter_RecordsFailedCompletion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	providerErr := shared_errors.NewError(shared_errors.ErrorTypeProviderError, "upstream returned 502").Build()
+	s := newCacheTestService(&failingProviderClient{err: providerErr}, nil)
+	s.deadLetters = NewFileDeadLetterStore(path)
+
+	req := newCacheTestRequest("tenant-a")
+	req.RequestID = "req-1"
+	req.User = "end-user@example.com"
+
+	ctx := withAttemptLog(context.Background())
+	_, err := s.routeCompletion(ctx, req)
+	require.Error(t, err)
+	s.deadLetterCompletion(ctx, deadLetterOperationCompletion, req, err)
+
+	letters := readDeadLetters(t, path)
+	require.Len(t, letters, 1)
+	letter := letters[0]
+	assert.Equal(t, deadLetterOperationCompletion, letter.Operation)
+	assert.Equal(t, "req-1", letter.RequestID)
+	assert.Equal(t, domain.TenantID("tenant-a"), letter.TenantID)
+	assert.Equal(t, string(shared_errors.ErrorTypeProviderError), letter.ErrorType)
+
+	require.Len(t, letter.Attempts, 1)
+	assert.Equal(t, domain.ProviderOpenAI, letter.Attempts[0].Provider)
+	assert.Equal(t, "gpt-4o", letter.Attempts[0].Model)
+	assert.Equal(t, 1, letter.Attempts[0].Attempt)
+	assert.Equal(t, 502, letter.Attempts[0].StatusCode)
+
+	// The prompt and end-user ID stay out of the record
+	data, err := json.Marshal(letter.Request)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "Hello")
+	assert.NotContains(t, string(data), "end-user@example.com")
+	assert.Contains(t, string(data), "[redacted 5 bytes]")
+
+	// The caller's request is left untouched
+	assert.Equal(t, "Hello", req.Messages[0].Content[0].Text)
+}
+
+func TestDeadLetter_SkipsClientErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dead-letters.jsonl")
+	s := newCacheTestService(&countingProviderClient{}, nil)
+	s.deadLetters = NewFileDeadLetterStore(path)
+	req := newCacheTestRequest("tenant-a")
+
+	s.deadLetterCompletion(context.Background(), deadLetterOperationCompletion, req, shared_errors.ValidationError("invalid request", "messages"))
+	s.deadLetterCompletion(context.Background(), deadLetterOperationCompletion, req, context.Canceled)
+	assert.Empty(t, readDeadLetters(t, path))
+
+	s.deadLetterEmbedding(context.Background(), &domain.EmbeddingRequest{Model: "text-embedding-3-small", Input: []string{"secret"}}, shared_errors.ProviderUnavailableError("openai"))
+	letters := readDeadLetters(t, path)
+	require.Len(t, letters, 1)
+	assert.Equal(t, deadLetterOperationEmbedding, letters[0].Operation)
+	assert.Empty(t, letters[0].Attempts)
+}
@@ -0,0 +1,158 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// embeddingBatch is one provider call's share of an embedding request: the
+// inputs from start up to start+len(req.Input)
+type embeddingBatch struct {
+	start    int
+	req      *domain.EmbeddingRequest
+	response *domain.EmbeddingResponse
+}
+
+// createEmbeddingBatches embeds the request's inputs with one provider,
+// splitting them into batches of EmbeddingBatchSize sent at most
+// EmbeddingBatchConcurrency at a time. The first failed batch fails the
+// request and cancels the rest.
+func (s *Service) createEmbeddingBatches(ctx context.Context, client ProviderClient, provider domain.Provider, req *domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	config := s.currentConfig()
+	size := config.EmbeddingBatchSize
+	if size <= 0 || len(req.Input) <= size {
+		return s.createEmbeddingBatch(ctx, client, provider, req)
+	}
+
+	var batches []*embeddingBatch
+	for start := 0; start < len(req.Input); start += size {
+		end := start + size
+		if end > len(req.Input) {
+			end = len(req.Input)
+		}
+		batchReq := *req
+		batchReq.Input = req.Input[start:end]
+		batches = append(batches, &embeddingBatch{start: start, req: &batchReq})
+	}
+
+	concurrency := config.EmbeddingBatchConcurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	slots := make(chan struct{}, concurrency)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for _, batch := range batches {
+		slots <- struct{}{}
+		if ctx.Err() != nil {
+			<-slots
+			break
+		}
+
+		wg.Add(1)
+		go func(batch *embeddingBatch) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			response, err := s.createEmbeddingBatch(ctx, client, provider, batch.req)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			batch.response = response
+		}(batch)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// A caller cancelling part way leaves batches without a response
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return mergeEmbeddingBatches(provider, len(req.Input), batches)
+}
+
+// createEmbeddingBatch sends one provider call, retrying transient failures
+func (s *Service) createEmbeddingBatch(ctx context.Context, client ProviderClient, provider domain.Provider, req *domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	result, err := s.executeWithRetry(ctx, func() (interface{}, error) {
+		return client.CreateEmbeddings(ctx, req)
+	}, provider, req.Model)
+	if err != nil {
+		return nil, err
+	}
+	return result.(*domain.EmbeddingResponse), nil
+}
+
+// mergeEmbeddingBatches reassembles batch responses in input order. Each
+// provider numbers its embeddings from zero within the batch, so indexes are
+// offset by the batch's start. A batch returning the wrong number of
+// embeddings, or an index that is out of range or repeated, fails the whole
+// request rather than returning vectors that may belong to other inputs.
+func mergeEmbeddingBatches(provider domain.Provider, total int, batches []*embeddingBatch) (*domain.EmbeddingResponse, error) {
+	data := make([]domain.Embedding, 0, total)
+	seen := make([]bool, total)
+	var usage domain.EmbeddingUsage
+
+	for i, batch := range batches {
+		inputs := len(batch.req.Input)
+		if len(batch.response.Data) != inputs {
+			return nil, embeddingMergeError(provider, fmt.Sprintf("embedding batch %d returned %d embeddings for %d inputs", i, len(batch.response.Data), inputs))
+		}
+
+		for _, embedding := range batch.response.Data {
+			if embedding.Index < 0 || embedding.Index >= inputs {
+				return nil, embeddingMergeError(provider, fmt.Sprintf("embedding batch %d returned index %d for %d inputs", i, embedding.Index, inputs))
+			}
+			embedding.Index += batch.start
+			if seen[embedding.Index] {
+				return nil, embeddingMergeError(provider, fmt.Sprintf("embedding batch %d returned index %d twice", i, embedding.Index-batch.start))
+			}
+			seen[embedding.Index] = true
+			data = append(data, embedding)
+		}
+
+		usage.PromptTokens += batch.response.Usage.PromptTokens
+		usage.TotalTokens += batch.response.Usage.TotalTokens
+		usage.CostUSD += batch.response.Usage.CostUSD
+	}
+
+	sort.Slice(data, func(i, j int) bool { return data[i].Index < data[j].Index })
+	for i, embedding := range data {
+		if embedding.Index != i {
+			return nil, embeddingMergeError(provider, fmt.Sprintf("embedding batches are missing input %d", i))
+		}
+	}
+
+	merged := *batches[0].response
+	merged.Data = data
+	merged.Usage = usage
+	// No single provider call produced the merged response
+	merged.ProviderRequestID = ""
+	merged.Metadata = make(map[string]interface{}, len(batches[0].response.Metadata)+1)
+	for key, value := range batches[0].response.Metadata {
+		merged.Metadata[key] = value
+	}
+	merged.Metadata[domain.MetadataKeyEmbeddingBatches] = len(batches)
+	return &merged, nil
+}
+
+func embeddingMergeError(provider domain.Provider, message string) error {
+	return shared_errors.ProviderError(string(provider), message, nil)
+}
@@ -0,0 +1,151 @@
+package router
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// batchingEmbeddingClient embeds input "n" as the vector [n]. It returns each
+// batch's embeddings in reverse order and answers later batches first, as a
+// provider is free to.
+type batchingEmbeddingClient struct {
+	ProviderClient
+	mu      sync.Mutex
+	batches [][]string
+	short   bool
+}
+
+func (c *batchingEmbeddingClient) CreateEmbeddings(ctx context.Context, req *domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	c.mu.Lock()
+	c.batches = append(c.batches, req.Input)
+	c.mu.Unlock()
+
+	first, _ := strconv.Atoi(req.Input[0])
+	time.Sleep(time.Duration(10-first) * time.Millisecond)
+
+	data := make([]domain.Embedding, 0, len(req.Input))
+	for i := len(req.Input) - 1; i >= 0; i-- {
+		value, _ := strconv.Atoi(req.Input[i])
+		data = append(data, domain.Embedding{Object: "embedding", Index: i, Embedding: []float64{float64(value)}})
+	}
+	if c.short && first > 0 {
+		data = data[1:]
+	}
+
+	return &domain.EmbeddingResponse{
+		Object: "list",
+		Model:  req.Model,
+		Data:   data,
+		Usage:  domain.EmbeddingUsage{PromptTokens: len(req.Input), TotalTokens: len(req.Input)},
+	}, nil
+}
+
+func newEmbeddingBatchRequest(inputs int) *domain.EmbeddingRequest {
+	req := &domain.EmbeddingRequest{Provider: domain.ProviderOpenAI, Model: "text-embedding-3-small"}
+	for i := 0; i < inputs; i++ {
+		req.Input = append(req.Input, strconv.Itoa(i))
+	}
+	return req
+}
+
+func TestRouteEmbedding_ReassemblesBatchesInInputOrder(t *testing.T) {
+	client := &batchingEmbeddingClient{}
+	s := newEmbeddingFallbackService(client, nil)
+	s.config.EmbeddingBatchSize = 3
+	s.config.EmbeddingBatchConcurrency = 3
+
+	response, err := s.routeEmbedding(context.Background(), newEmbeddingBatchRequest(8))
+	require.NoError(t, err)
+
+	assert.Len(t, client.batches, 3)
+	require.Len(t, response.Data, 8)
+	for i, embedding := range response.Data {
+		assert.Equal(t, i, embedding.Index)
+		assert.Equal(t, []float64{float64(i)}, embedding.Embedding, "input %d got another input's vector", i)
+	}
+	assert.Equal(t, 8, response.Usage.TotalTokens)
+	assert.Equal(t, 3, response.Metadata[domain.MetadataKeyEmbeddingBatches])
+}
+
+func TestRouteEmbedding_SmallRequestIsNotSplit(t *testing.T) {
+	client := &batchingEmbeddingClient{}
+	s := newEmbeddingFallbackService(client, nil)
+	s.config.EmbeddingBatchSize = 3
+
+	response, err := s.routeEmbedding(context.Background(), newEmbeddingBatchRequest(3))
+	require.NoError(t, err)
+	assert.Len(t, client.batches, 1)
+	assert.NotContains(t, response.Metadata, domain.MetadataKeyEmbeddingBatches)
+}
+
+func TestRouteEmbedding_FailsOnMismatchedBatchCount(t *testing.T) {
+	client := &batchingEmbeddingClient{short: true}
+	s := newEmbeddingFallbackService(client, nil)
+	s.config.EmbeddingBatchSize = 3
+	s.config.EmbeddingBatchConcurrency = 1
+
+	_, err := s.routeEmbedding(context.Background(), newEmbeddingBatchRequest(6))
+	require.Error(t, err)
+	assert.True(t, shared_errors.IsType(err, shared_errors.ErrorTypeProviderError))
+	assert.Contains(t, err.Error(), "returned 2 embeddings for 3 inputs")
+}
+
+// cancellingEmbeddingClient cancels the request once its first batch is
+// answered
+type cancellingEmbeddingClient struct {
+	batchingEmbeddingClient
+	cancel context.CancelFunc
+}
+
+func (c *cancellingEmbeddingClient) CreateEmbeddings(ctx context.Context, req *domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	defer c.cancel()
+	return c.batchingEmbeddingClient.CreateEmbeddings(ctx, req)
+}
+
+func TestRouteEmbedding_CancelledMidBatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	client := &cancellingEmbeddingClient{cancel: cancel}
+	s := newEmbeddingFallbackService(client, nil)
+	s.config.EmbeddingBatchSize = 2
+	s.config.EmbeddingBatchConcurrency = 1
+
+	response, err := s.routeEmbedding(ctx, newEmbeddingBatchRequest(6))
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, response)
+	assert.Len(t, client.batches, 1, "no batch is sent after the cancellation")
+}
+
+func TestMergeEmbeddingBatches_RejectsBadIndexes(t *testing.T) {
+	batch := func(start int, indexes ...int) *embeddingBatch {
+		b := &embeddingBatch{start: start, req: &domain.EmbeddingRequest{}, response: &domain.EmbeddingResponse{}}
+		for _, index := range indexes {
+			b.req.Input = append(b.req.Input, "x")
+			b.response.Data = append(b.response.Data, domain.Embedding{Index: index})
+		}
+		return b
+	}
+
+	_, err := mergeEmbeddingBatches(domain.ProviderOpenAI, 4, []*embeddingBatch{batch(0, 0, 1), batch(2, 1, 1)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "index 1 twice")
+
+	_, err = mergeEmbeddingBatches(domain.ProviderOpenAI, 4, []*embeddingBatch{batch(0, 0, 1), batch(2, 0, 2)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "index 2 for 2 inputs")
+
+	merged, err := mergeEmbeddingBatches(domain.ProviderOpenAI, 4, []*embeddingBatch{batch(0, 1, 0), batch(2, 1, 0)})
+	require.NoError(t, err)
+	for i, embedding := range merged.Data {
+		assert.Equal(t, i, embedding.Index)
+	}
+}
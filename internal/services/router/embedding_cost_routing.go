@@ -0,0 +1,187 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// rankEmbeddingRoutes scores the models and providers an embedding request
+// may be served by when embedding cost routing is enabled, best first, by
+// the AutoRoutingWeights of their price, latency and error rate. A request
+// naming a model is only routed among the providers serving that model, so
+// its vectors stay in the model's embedding space; only a request for model
+// "auto" may be served by any model whose vectors have the requested
+// dimensions. No routes are returned when cost routing is off or the
+// request pins both the model and the provider.
+func (s *Service) rankEmbeddingRoutes(req *domain.EmbeddingRequest) ([]domain.ProviderScore, error) {
+	config := s.currentConfig()
+	auto := req.Model == domain.EmbeddingModelAuto
+	if !config.EmbeddingCostRouting {
+		if auto {
+			return nil, shared_errors.ValidationError("model auto requires embedding cost routing to be enabled", "model")
+		}
+		return nil, nil
+	}
+	pinned := req.Provider != "" && req.Provider != domain.ProviderAuto
+	if pinned && !auto {
+		return nil, nil
+	}
+
+	models := []string{req.Model}
+	if auto {
+		if req.Dimensions == nil || *req.Dimensions <= 0 {
+			return nil, shared_errors.ValidationError("dimensions is required with model auto", "dimensions")
+		}
+		models = modelsWithDimensions(config.EmbeddingDimensions, *req.Dimensions)
+	}
+
+	var routes []domain.ProviderScore
+	for _, model := range models {
+		providers, err := s.entitledProviders(req.TenantID, model, req.DataResidency)
+		if err != nil {
+			if !auto {
+				return nil, err
+			}
+			continue
+		}
+		for _, provider := range providers {
+			if !pinned || provider == req.Provider {
+				routes = append(routes, domain.ProviderScore{Provider: provider, Model: model})
+			}
+		}
+	}
+	if len(routes) == 0 {
+		return nil, shared_errors.ValidationError(
+			fmt.Sprintf("no embedding model with %d dimensions is available", *req.Dimensions), "dimensions")
+	}
+
+	s.scoreEmbeddingRoutes(routes)
+	s.rankScores(routes)
+	return routes, nil
+}
+
+// modelsWithDimensions lists the models whose native vectors have the given
+// size, sorted
+func modelsWithDimensions(dimensions map[string]int, size int) []string {
+	var models []string
+	for model, modelSize := range dimensions {
+		if modelSize == size {
+			models = append(models, model)
+		}
+	}
+	sort.Strings(models)
+	return models
+}
+
+// scoreEmbeddingRoutes computes each route's weighted score. Cost is the
+// model's input price per 1K tokens at the provider, as embeddings bill no
+// output, and latency the provider's tracked average, both relative to the
+// highest among the routes.
+func (s *Service) scoreEmbeddingRoutes(routes []domain.ProviderScore) {
+	weights := s.currentConfig().AutoRoutingWeights
+
+	costs := make([]float64, len(routes))
+	latencies := make([]float64, len(routes))
+	costKnown := make([]bool, len(routes))
+	latencyKnown := make([]bool, len(routes))
+
+	s.mu.RLock()
+	for i, route := range routes {
+		if model := s.providerModel(route.Provider, route.Model); model != nil {
+			costs[i], _, _ = model.Pricing.PerThousandTokens()
+			costKnown[i] = costs[i] > 0
+		}
+	}
+	s.mu.RUnlock()
+
+	for i, route := range routes {
+		latencies[i], latencyKnown[i] = s.providerStats.Latency(route.Provider)
+	}
+
+	costs = normalize(costs, costKnown)
+	latencies = normalize(latencies, latencyKnown)
+
+	for i := range routes {
+		routes[i].Cost = costs[i]
+		routes[i].Latency = latencies[i]
+		routes[i].ErrorRate = s.providerStats.ErrorRate(routes[i].Provider)
+		routes[i].Score = weights.Cost*routes[i].Cost + weights.Latency*routes[i].Latency + weights.ErrorRate*routes[i].ErrorRate
+	}
+}
+
+// routeEmbeddingRoutes tries the ranked routes in order until one serves the
+// request with vectors of the expected size. A route that is unavailable or
+// answers with vectors of another size is skipped; any other error ends the
+// request. The response names the route's model and provider and lists
+// every route's score, with the one that served it selected.
+func (s *Service) routeEmbeddingRoutes(ctx context.Context, req *domain.EmbeddingRequest, routes []domain.ProviderScore) (*domain.EmbeddingResponse, error) {
+	auto := req.Model == domain.EmbeddingModelAuto
+
+	var lastErr error
+	for i, route := range routes {
+		if ctx.Err() != nil {
+			break
+		}
+
+		routed := *req
+		routed.Model, routed.Provider = route.Model, route.Provider
+		expected := s.currentConfig().EmbeddingDimensions[route.Model]
+		if req.Dimensions != nil {
+			expected = *req.Dimensions
+		}
+		if auto {
+			// The model's native vectors have the requested size, and not
+			// every model accepts the dimensions parameter
+			routed.Dimensions = nil
+		}
+
+		response, err := s.routeEmbeddingModel(ctx, &routed)
+		if err != nil {
+			if !isUnavailable(err) {
+				return nil, err
+			}
+			s.logger.Warn("Embedding route unavailable",
+				logger.F("model", route.Model),
+				logger.F("provider", route.Provider),
+				logger.F("request_id", req.RequestID),
+				logger.F("error", err))
+			lastErr = err
+			continue
+		}
+
+		// A model whose size is unknown is only checked against the size
+		// the caller asked for
+		if expected > 0 && !hasDimensions(response, expected) {
+			s.logger.Warn("Embedding route returned incompatible dimensions",
+				logger.F("model", route.Model),
+				logger.F("provider", route.Provider),
+				logger.F("expected_dimensions", expected),
+				logger.F("request_id", req.RequestID))
+			lastErr = shared_errors.NewError(shared_errors.ErrorTypeModelUnavailable,
+				fmt.Sprintf("no embedding route returned %d-dimensional vectors", expected)).
+				WithDetail("model", req.Model).
+				WithRetryable(false).
+				Build()
+			continue
+		}
+
+		routes[i].Selected = true
+		response.Model, response.Provider = route.Model, route.Provider
+		if response.Metadata == nil {
+			response.Metadata = make(map[string]interface{})
+		}
+		response.Metadata[domain.MetadataKeyRoutingScores] = routes
+		embeddingRouteSelections.WithLabelValues(req.Model, route.Model, string(route.Provider)).Inc()
+		return response, nil
+	}
+
+	if lastErr == nil {
+		lastErr = ctx.Err()
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,164 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// newEmbeddingCostRoutingService serves text-embedding-3-small from OpenAI
+// and, at half the price, from Azure OpenAI, and the pricier
+// text-embedding-ada-002 of the same size and text-embedding-3-large from
+// OpenAI only
+func newEmbeddingCostRoutingService(openai, azure *embeddingProviderClient) *Service {
+	s := newCacheTestService(openai, nil)
+	s.config.EmbeddingCostRouting = true
+	s.config.AutoRoutingWeights = env.AutoRoutingWeights{Cost: 1}
+	s.config.EmbeddingDimensions = map[string]int{
+		"text-embedding-3-small": 1536,
+		"text-embedding-ada-002": 1536,
+		"text-embedding-3-large": 3072,
+	}
+	s.providerConfigs[domain.ProviderAzureOpenAI] = &domain.ProviderConfig{Provider: domain.ProviderAzureOpenAI, Enabled: true}
+	s.providerClients[domain.ProviderAzureOpenAI] = azure
+	s.modelRegistry = make(map[string]map[domain.Provider]*domain.Model)
+
+	for _, model := range []*domain.Model{
+		{ModelID: "text-embedding-3-small", Provider: domain.ProviderOpenAI, Pricing: domain.ModelPricing{InputTokenCost: 0.00002, Unit: domain.PricingUnitThousandTokens}},
+		{ModelID: "text-embedding-3-small", Provider: domain.ProviderAzureOpenAI, Pricing: domain.ModelPricing{InputTokenCost: 0.00001, Unit: domain.PricingUnitThousandTokens}},
+		{ModelID: "text-embedding-ada-002", Provider: domain.ProviderOpenAI, Pricing: domain.ModelPricing{InputTokenCost: 0.0001, Unit: domain.PricingUnitThousandTokens}},
+		{ModelID: "text-embedding-3-large", Provider: domain.ProviderOpenAI, Pricing: domain.ModelPricing{InputTokenCost: 0.000005, Unit: domain.PricingUnitThousandTokens}},
+	} {
+		s.registerModel(model)
+	}
+	return s
+}
+
+func newEmbeddingClients() (*embeddingProviderClient, *embeddingProviderClient) {
+	sizes := map[string]int{"text-embedding-3-small": 1536, "text-embedding-ada-002": 1536, "text-embedding-3-large": 3072}
+	return &embeddingProviderClient{sizes: sizes}, &embeddingProviderClient{sizes: sizes}
+}
+
+func TestRouteEmbedding_CostRoutingPicksCheapestProvider(t *testing.T) {
+	openai, azure := newEmbeddingClients()
+	s := newEmbeddingCostRoutingService(openai, azure)
+
+	response, err := s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+		TenantID: "tenant-a",
+		Model:    "text-embedding-3-small",
+		Input:    []string{"hello"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderAzureOpenAI, response.Provider)
+	assert.Equal(t, "text-embedding-3-small", response.Model)
+	assert.Equal(t, []string{"text-embedding-3-small"}, azure.models)
+	assert.Empty(t, openai.models)
+
+	// The named model is never swapped for a cheaper one of the same size
+	scores, ok := response.Metadata[domain.MetadataKeyRoutingScores].([]domain.ProviderScore)
+	require.True(t, ok)
+	require.Len(t, scores, 2)
+	for _, score := range scores {
+		assert.Equal(t, "text-embedding-3-small", score.Model)
+	}
+	assert.True(t, scores[0].Selected)
+	assert.Equal(t, domain.ProviderAzureOpenAI, scores[0].Provider)
+}
+
+func TestRouteEmbedding_CostRoutingFailsOverToNextProvider(t *testing.T) {
+	openai, azure := newEmbeddingClients()
+	azure.unavailable = map[string]bool{"text-embedding-3-small": true}
+	s := newEmbeddingCostRoutingService(openai, azure)
+
+	response, err := s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+		TenantID: "tenant-a",
+		Model:    "text-embedding-3-small",
+		Input:    []string{"hello"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderOpenAI, response.Provider)
+
+	scores := response.Metadata[domain.MetadataKeyRoutingScores].([]domain.ProviderScore)
+	assert.False(t, scores[0].Selected)
+	assert.True(t, scores[1].Selected)
+}
+
+func TestRouteEmbedding_AutoModelMatchesDimensions(t *testing.T) {
+	openai, azure := newEmbeddingClients()
+	s := newEmbeddingCostRoutingService(openai, azure)
+	dimensions := 1536
+
+	response, err := s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+		TenantID:   "tenant-a",
+		Model:      domain.EmbeddingModelAuto,
+		Dimensions: &dimensions,
+		Input:      []string{"hello"},
+	})
+	require.NoError(t, err)
+
+	// text-embedding-3-large is cheaper but its vectors are larger
+	assert.Equal(t, "text-embedding-3-small", response.Model)
+	assert.Equal(t, domain.ProviderAzureOpenAI, response.Provider)
+	scores := response.Metadata[domain.MetadataKeyRoutingScores].([]domain.ProviderScore)
+	require.Len(t, scores, 3)
+	assert.Equal(t, "text-embedding-ada-002", scores[2].Model)
+
+	// Without dimensions there is nothing to match
+	_, err = s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+		TenantID: "tenant-a",
+		Model:    domain.EmbeddingModelAuto,
+		Input:    []string{"hello"},
+	})
+	require.Error(t, err)
+	assert.Equal(t, "dimensions", shared_errors.FromError(err).Details["field"])
+}
+
+func TestRouteEmbedding_AutoModelSkipsWrongSizedVectors(t *testing.T) {
+	openai, azure := newEmbeddingClients()
+	// Azure's deployment serves vectors of another size under the model ID
+	azure.sizes = map[string]int{"text-embedding-3-small": 768}
+	s := newEmbeddingCostRoutingService(openai, azure)
+	dimensions := 1536
+
+	response, err := s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+		TenantID:   "tenant-a",
+		Model:      domain.EmbeddingModelAuto,
+		Dimensions: &dimensions,
+		Input:      []string{"hello"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderOpenAI, response.Provider)
+	assert.Len(t, response.Data[0].Embedding, 1536)
+}
+
+func TestRouteEmbedding_CostRoutingDisabled(t *testing.T) {
+	openai, azure := newEmbeddingClients()
+	s := newEmbeddingCostRoutingService(openai, azure)
+	s.config.EmbeddingCostRouting = false
+	s.config.DefaultProviderOrder = []domain.Provider{domain.ProviderOpenAI}
+
+	response, err := s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+		TenantID: "tenant-a",
+		Model:    "text-embedding-3-small",
+		Input:    []string{"hello"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"text-embedding-3-small"}, openai.models)
+	assert.Nil(t, response.Metadata[domain.MetadataKeyRoutingScores])
+
+	dimensions := 1536
+	_, err = s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+		TenantID:   "tenant-a",
+		Model:      domain.EmbeddingModelAuto,
+		Dimensions: &dimensions,
+		Input:      []string{"hello"},
+	})
+	require.Error(t, err)
+	assert.True(t, shared_errors.IsType(err, shared_errors.ErrorTypeValidation))
+}
@@ -0,0 +1,113 @@
+package router
+
+import (
+	"context"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// isUnavailable reports whether an error means the model or its provider
+// cannot serve requests right now, as opposed to a bad request
+func isUnavailable(err error) bool {
+	return shared_errors.IsType(err, shared_errors.ErrorTypeProviderUnavailable) ||
+		shared_errors.IsType(err, shared_errors.ErrorTypeModelUnavailable) ||
+		shared_errors.IsType(err, shared_errors.ErrorTypeUnavailable)
+}
+
+// routeEmbeddingFallback retries an embedding request on the configured
+// fallback models in order. The first one that answers with vectors of the
+// requested size wins and the response records the substitution; if none
+// does, the primary model's error is returned.
+func (s *Service) routeEmbeddingFallback(ctx context.Context, req *domain.EmbeddingRequest, primaryErr error) (*domain.EmbeddingResponse, error) {
+	expected := s.currentConfig().EmbeddingDimensions[req.Model]
+	if req.Dimensions != nil {
+		expected = *req.Dimensions
+	}
+
+	for _, model := range s.embeddingFallbacks(req.Model) {
+		if ctx.Err() != nil {
+			break
+		}
+
+		fallbackReq := *req
+		fallbackReq.Model = model
+		response, err := s.routeEmbeddingModel(ctx, &fallbackReq)
+		if err != nil {
+			s.logger.Warn("Embedding fallback failed",
+				logger.F("model", req.Model),
+				logger.F("fallback_model", model),
+				logger.F("request_id", req.RequestID),
+				logger.F("error", err))
+			continue
+		}
+
+		// Providers may ignore the dimensions parameter; never hand back
+		// vectors of a different size than the caller asked for
+		if !hasDimensions(response, expected) {
+			s.logger.Warn("Embedding fallback returned incompatible dimensions",
+				logger.F("model", req.Model),
+				logger.F("fallback_model", model),
+				logger.F("expected_dimensions", expected),
+				logger.F("request_id", req.RequestID))
+			continue
+		}
+
+		if response.Metadata == nil {
+			response.Metadata = make(map[string]interface{})
+		}
+		response.Metadata[domain.MetadataKeyFallbackFrom] = req.Model
+		response.Metadata[domain.MetadataKeyFallbackModel] = model
+		embeddingFallbacks.WithLabelValues(req.Model, model).Inc()
+
+		s.logger.Info("Embedding request served by fallback model",
+			logger.F("model", req.Model),
+			logger.F("fallback_model", model),
+			logger.F("tenant_id", req.TenantID),
+			logger.F("request_id", req.RequestID))
+		return response, nil
+	}
+
+	return nil, primaryErr
+}
+
+// embeddingFallbacks returns the configured fallbacks for a model whose
+// vectors have the same size as the model's. Fallbacks with unknown or
+// different dimensions are skipped, as is every fallback of a model whose
+// own dimensions are unknown.
+func (s *Service) embeddingFallbacks(model string) []string {
+	config := s.currentConfig()
+	dimensions, known := config.EmbeddingDimensions[model]
+
+	var fallbacks []string
+	for _, fallback := range config.EmbeddingFallbacks[model] {
+		if fallback == model {
+			continue
+		}
+		if !known || config.EmbeddingDimensions[fallback] != dimensions {
+			s.logger.Warn("Skipping embedding fallback with incompatible dimensions",
+				logger.F("model", model),
+				logger.F("fallback_model", fallback),
+				logger.F("dimensions", dimensions),
+				logger.F("fallback_dimensions", config.EmbeddingDimensions[fallback]))
+			continue
+		}
+		fallbacks = append(fallbacks, fallback)
+	}
+	return fallbacks
+}
+
+// hasDimensions reports whether every vector in the response has the
+// expected size
+func hasDimensions(response *domain.EmbeddingResponse, expected int) bool {
+	if expected <= 0 || len(response.Data) == 0 {
+		return false
+	}
+	for _, embedding := range response.Data {
+		if len(embedding.Embedding) != expected {
+			return false
+		}
+	}
+	return true
+}
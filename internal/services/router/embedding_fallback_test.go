@@ -0,0 +1,93 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// embeddingProviderClient fails for unavailable models and otherwise returns
+// one vector of the model's size
+type embeddingProviderClient struct {
+	ProviderClient
+	unavailable map[string]bool
+	sizes       map[string]int
+	models      []string
+}
+
+func (c *embeddingProviderClient) CreateEmbeddings(ctx context.Context, req *domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	c.models = append(c.models, req.Model)
+	if c.unavailable[req.Model] {
+		return nil, shared_errors.NewError(shared_errors.ErrorTypeModelUnavailable, "model is unavailable").Build()
+	}
+	return &domain.EmbeddingResponse{
+		Object: "list",
+		Model:  req.Model,
+		Data:   []domain.Embedding{{Object: "embedding", Embedding: make([]float64, c.sizes[req.Model])}},
+	}, nil
+}
+
+func newEmbeddingFallbackService(client ProviderClient, fallbacks map[string][]string) *Service {
+	s := newCacheTestService(client, nil)
+	s.config.EmbeddingFallbacks = fallbacks
+	s.config.EmbeddingDimensions = map[string]int{"primary": 1536, "same-size": 1536, "other-size": 3072}
+	return s
+}
+
+func TestRouteEmbedding_UsesCompatibleFallback(t *testing.T) {
+	client := &embeddingProviderClient{
+		unavailable: map[string]bool{"primary": true},
+		sizes:       map[string]int{"same-size": 1536, "other-size": 3072},
+	}
+	s := newEmbeddingFallbackService(client, map[string][]string{"primary": {"other-size", "same-size"}})
+
+	response, err := s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+		Provider: domain.ProviderOpenAI,
+		Model:    "primary",
+		Input:    []string{"hello"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "same-size", response.Model)
+	assert.Equal(t, "primary", response.Metadata[domain.MetadataKeyFallbackFrom])
+	assert.Equal(t, "same-size", response.Metadata[domain.MetadataKeyFallbackModel])
+
+	// The fallback with a different vector size is never called
+	assert.Equal(t, []string{"primary", "same-size"}, client.models)
+}
+
+func TestRouteEmbedding_RejectsFallbackWithWrongVectorSize(t *testing.T) {
+	// Configured as 1536 but actually returns 768-dimensional vectors
+	client := &embeddingProviderClient{
+		unavailable: map[string]bool{"primary": true},
+		sizes:       map[string]int{"same-size": 768},
+	}
+	s := newEmbeddingFallbackService(client, map[string][]string{"primary": {"same-size"}})
+
+	_, err := s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+		Provider: domain.ProviderOpenAI,
+		Model:    "primary",
+		Input:    []string{"hello"},
+	})
+	require.Error(t, err)
+	assert.True(t, shared_errors.IsType(err, shared_errors.ErrorTypeModelUnavailable))
+}
+
+func TestRouteEmbedding_PrimaryModelHasNoFallbackMetadata(t *testing.T) {
+	client := &embeddingProviderClient{sizes: map[string]int{"primary": 1536}}
+	s := newEmbeddingFallbackService(client, map[string][]string{"primary": {"same-size"}})
+
+	response, err := s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+		Provider: domain.ProviderOpenAI,
+		Model:    "primary",
+		Input:    []string{"hello"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "primary", response.Model)
+	assert.Nil(t, response.Metadata)
+	assert.Equal(t, []string{"primary"}, client.models)
+}
@@ -0,0 +1,41 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// validateEmbeddingRepresentations rejects representations that cannot be
+// derived before the provider is paid for the base vectors
+func validateEmbeddingRepresentations(req *domain.EmbeddingRequest) error {
+	if len(req.Representations) > domain.MaxEmbeddingRepresentations {
+		return shared_errors.ValidationError(
+			fmt.Sprintf("at most %d representations may be requested", domain.MaxEmbeddingRepresentations), "representations")
+	}
+	for _, representation := range req.Representations {
+		if err := representation.Validate(); err != nil {
+			return shared_errors.ValidationError(err.Error(), "representations")
+		}
+		if req.Dimensions != nil && representation.Dimensions > *req.Dimensions {
+			return shared_errors.ValidationError(
+				fmt.Sprintf("representation dimensions %d exceed the requested dimensions %d", representation.Dimensions, *req.Dimensions), "representations")
+		}
+	}
+	return nil
+}
+
+// deriveEmbeddingRepresentations fills the response's Representations from
+// its vectors, without further provider calls
+func deriveEmbeddingRepresentations(req *domain.EmbeddingRequest, response *domain.EmbeddingResponse) error {
+	if len(req.Representations) == 0 {
+		return nil
+	}
+	representations, err := domain.DeriveEmbeddingRepresentations(response.Data, req.Representations)
+	if err != nil {
+		return shared_errors.ValidationError(err.Error(), "representations")
+	}
+	response.Representations = representations
+	return nil
+}
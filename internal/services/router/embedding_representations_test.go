@@ -0,0 +1,69 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+func TestRouteEmbedding_DerivesRepresentations(t *testing.T) {
+	client := &embeddingProviderClient{sizes: map[string]int{"text-embedding-3-large": 3072}}
+	s := newCacheTestService(client, nil)
+
+	response, err := s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+		Provider: domain.ProviderOpenAI,
+		Model:    "text-embedding-3-large",
+		Input:    []string{"hello"},
+		Representations: []domain.EmbeddingRepresentation{
+			{EncodingFormat: domain.EmbeddingEncodingBase64},
+			{Dimensions: 256, Normalize: true},
+		},
+	})
+	require.NoError(t, err)
+
+	// One provider call serves every representation
+	assert.Len(t, client.models, 1)
+	require.Len(t, response.Representations, 2)
+	assert.NotEmpty(t, response.Representations[0].Data[0].Base64)
+	assert.Len(t, response.Representations[1].Data[0].Embedding, 256)
+	assert.Len(t, response.Data[0].Embedding, 3072)
+}
+
+func TestRouteEmbedding_RejectsUnderivableRepresentations(t *testing.T) {
+	client := &embeddingProviderClient{sizes: map[string]int{"text-embedding-3-small": 1536}}
+	s := newCacheTestService(client, nil)
+	dimensions := 512
+
+	for _, representations := range [][]domain.EmbeddingRepresentation{
+		{{EncodingFormat: "binary"}},
+		{{Dimensions: 1024}},
+		make([]domain.EmbeddingRepresentation, domain.MaxEmbeddingRepresentations+1),
+	} {
+		_, err := s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+			Provider:        domain.ProviderOpenAI,
+			Model:           "text-embedding-3-small",
+			Input:           []string{"hello"},
+			Dimensions:      &dimensions,
+			Representations: representations,
+		})
+		require.Error(t, err)
+		assert.Equal(t, shared_errors.ErrorTypeValidation, shared_errors.FromError(err).Type)
+	}
+	// Nothing was sent to the provider
+	assert.Empty(t, client.models)
+
+	// Without requested dimensions the vector size is only known afterwards
+	_, err := s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+		Provider:        domain.ProviderOpenAI,
+		Model:           "text-embedding-3-small",
+		Input:           []string{"hello"},
+		Representations: []domain.EmbeddingRepresentation{{Dimensions: 2048}},
+	})
+	require.Error(t, err)
+	assert.Equal(t, shared_errors.ErrorTypeValidation, shared_errors.FromError(err).Type)
+}
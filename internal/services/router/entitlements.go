@@ -0,0 +1,47 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// checkProviderEntitlement rejects a pinned provider the tenant is not
+// entitled to, so pinning cannot route around the tenant's access policy
+func (s *Service) checkProviderEntitlement(tenantID domain.TenantID, provider domain.Provider) error {
+	if s.currentConfig().ProviderAllowed(string(tenantID), provider) {
+		return nil
+	}
+	authErr := shared_errors.AuthorizationError(fmt.Sprintf("tenant is not entitled to provider %s", provider))
+	authErr.Details = map[string]interface{}{"provider": string(provider)}
+	return authErr
+}
+
+// entitledProviders returns the eligible providers serving the model that
+// the tenant is entitled to, that serve from the request's data-residency
+// region, if it has one, and whose traffic is not switched off
+func (s *Service) entitledProviders(tenantID domain.TenantID, modelID, region string) ([]domain.Provider, error) {
+	candidates := s.eligibleProviders(modelID)
+	if len(candidates) == 0 {
+		return nil, shared_errors.ValidationError("no providers support the specified model", "model")
+	}
+
+	config := s.currentConfig()
+	entitled := make([]domain.Provider, 0, len(candidates))
+	for _, provider := range candidates {
+		if config.ProviderAllowed(string(tenantID), provider) {
+			entitled = append(entitled, provider)
+		}
+	}
+	if len(entitled) == 0 {
+		return nil, shared_errors.AuthorizationError(fmt.Sprintf("tenant is not entitled to any provider serving model %s", modelID))
+	}
+
+	// Never fall back to a provider outside the region
+	inRegion := s.providersInRegion(entitled, region)
+	if len(inRegion) == 0 {
+		return nil, dataResidencyError(fmt.Sprintf("no available provider serving model %s is in data residency region %s", modelID, region), modelID).Build()
+	}
+	return s.withoutKilled(inRegion, modelID)
+}
@@ -0,0 +1,47 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+func TestSelectProvider_EnforcesTenantEntitlements(t *testing.T) {
+	s := newAutoRoutingService(env.AutoRoutingWeights{Cost: 1})
+	s.config.TenantProviders = map[string][]domain.Provider{
+		"tenant-a": {domain.ProviderOpenAI},
+		"tenant-b": {domain.ProviderAWSBedrock},
+	}
+
+	// Pinning a provider outside the tenant's entitlements is refused
+	_, err := s.selectProvider("tenant-a", "gpt-4o", domain.ProviderAzureOpenAI, "")
+	require.Error(t, err)
+	assert.True(t, shared_errors.IsType(err, shared_errors.ErrorTypeAuthorization))
+
+	provider, err := s.selectProvider("tenant-a", "gpt-4o", domain.ProviderOpenAI, "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderOpenAI, provider)
+
+	// Routed requests only consider entitled providers, even when another
+	// one would score better
+	provider, err = s.selectProvider("tenant-a", "gpt-4o", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderOpenAI, provider)
+	provider, scores, err := s.selectAutoProvider("tenant-a", "gpt-4o", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderOpenAI, provider)
+	assert.Len(t, scores, 1)
+
+	_, err = s.selectProvider("tenant-b", "gpt-4o", "", "")
+	assert.True(t, shared_errors.IsType(err, shared_errors.ErrorTypeAuthorization))
+
+	// Tenants without entitlements may use every provider
+	provider, err = s.selectProvider("tenant-c", "gpt-4o", domain.ProviderAzureOpenAI, "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderAzureOpenAI, provider)
+}
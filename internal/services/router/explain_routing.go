@@ -0,0 +1,141 @@
+package router
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// explainRouting describes how the provider selected for the request was
+// chosen. Every configured provider the tenant is entitled to is listed
+// with the first filter that excluded it, in the order selectProvider
+// applies them, and the providers that passed them all are scored as for
+// provider "auto". Providers outside the tenant's entitlement are left out
+// so the explanation does not reveal them. The scores of an auto-routed
+// request are reused so the explanation matches the choice.
+func (s *Service) explainRouting(req *domain.CompletionRequest, selected domain.Provider, scores []domain.ProviderScore) *domain.RoutingExplanation {
+	config := s.currentConfig()
+	pinned := req.Provider != "" && req.Provider != domain.ProviderAuto
+	window := config.PassiveHealth.Window
+	now := time.Now()
+
+	explanation := &domain.RoutingExplanation{
+		Model:      req.Model,
+		Selected:   selected,
+		Candidates: []domain.RoutingCandidate{},
+	}
+
+	s.mu.RLock()
+	for provider, providerConfig := range s.providerConfigs {
+		if !config.ProviderAllowed(string(req.TenantID), provider) {
+			continue
+		}
+		candidate := domain.RoutingCandidate{
+			Provider: provider,
+			Health:   providerConfig.HealthStatus,
+			Selected: provider == selected,
+		}
+		if model := s.providerModel(provider, req.Model); model != nil {
+			input, output, _ := model.Pricing.PerThousandTokens()
+			candidate.CostPer1K = input + output
+		}
+
+		switch {
+		case pinned:
+			// A pinned provider passed its own checks or the request would
+			// have failed
+			if provider != req.Provider {
+				candidate.Excluded = domain.RoutingExcludedNotRequested
+			}
+		case !providerConfig.Enabled:
+			candidate.Excluded = domain.RoutingExcludedDisabled
+		case !providerRoutable(providerConfig, window, now):
+			candidate.Excluded = domain.RoutingExcludedUnhealthy
+		case !s.providerSupportsModel(provider, req.Model):
+			candidate.Excluded = domain.RoutingExcludedModel
+		}
+		explanation.Candidates = append(explanation.Candidates, candidate)
+	}
+	s.mu.RUnlock()
+
+	// providerInRegion and killSwitch take their own locks
+	var eligible []domain.Provider
+	for i := range explanation.Candidates {
+		candidate := &explanation.Candidates[i]
+		candidate.LatencyMs, _ = s.providerStats.Latency(candidate.Provider)
+		candidate.ErrorRate = s.providerStats.ErrorRate(candidate.Provider)
+		if candidate.Excluded != "" || pinned {
+			continue
+		}
+		if !s.providerInRegion(candidate.Provider, req.DataResidency) {
+			candidate.Excluded = domain.RoutingExcludedDataResidency
+		} else if _, killed := s.killSwitch(candidate.Provider, req.Model); killed {
+			candidate.Excluded = domain.RoutingExcludedKillSwitch
+		} else {
+			eligible = append(eligible, candidate.Provider)
+		}
+	}
+	if pinned {
+		eligible = []domain.Provider{req.Provider}
+	}
+
+	if scores == nil && len(eligible) > 0 {
+		scores = s.scoreProviders(req.Model, eligible)
+		s.rankScores(scores)
+		for i := range scores {
+			scores[i].Selected = scores[i].Provider == selected
+		}
+	}
+	for i := range scores {
+		for j := range explanation.Candidates {
+			if explanation.Candidates[j].Provider == scores[i].Provider {
+				score := scores[i]
+				explanation.Candidates[j].Score = &score
+			}
+		}
+	}
+
+	// Eligible providers first, best score first, then the excluded ones
+	rank := make(map[domain.Provider]int, len(scores))
+	for i, score := range scores {
+		rank[score.Provider] = i + 1
+	}
+	sort.Slice(explanation.Candidates, func(i, j int) bool {
+		ri, rj := rank[explanation.Candidates[i].Provider], rank[explanation.Candidates[j].Provider]
+		if ri != rj {
+			return ri != 0 && (rj == 0 || ri < rj)
+		}
+		return explanation.Candidates[i].Provider < explanation.Candidates[j].Provider
+	})
+
+	explanation.Strategy, explanation.Rationale = s.routingRationale(req, selected, eligible, scores)
+	return explanation
+}
+
+// routingRationale names the strategy that picked the selected provider
+// among the eligible ones and explains the choice
+func (s *Service) routingRationale(req *domain.CompletionRequest, selected domain.Provider, eligible []domain.Provider, scores []domain.ProviderScore) (string, string) {
+	switch {
+	case req.Provider != "" && req.Provider != domain.ProviderAuto:
+		return domain.RoutingStrategyPinned, fmt.Sprintf("the request pinned provider %s", selected)
+	case req.Provider == domain.ProviderAuto:
+		weights := s.currentConfig().AutoRoutingWeights
+		score := 0.0
+		if len(scores) > 0 {
+			score = scores[0].Score
+		}
+		return domain.RoutingStrategyAuto, fmt.Sprintf(
+			"%s has the lowest weighted score (%.3f) of %d eligible providers, weighing cost %g, latency %g and error rate %g",
+			selected, score, len(eligible), weights.Cost, weights.Latency, weights.ErrorRate)
+	case len(eligible) == 1:
+		return domain.RoutingStrategyOnlyCandidate, fmt.Sprintf("%s is the only eligible provider serving model %s", selected, req.Model)
+	}
+	if preferred, ok := s.preferredByDefaultOrder(eligible); ok && preferred == selected {
+		return domain.RoutingStrategyDefaultOrder, fmt.Sprintf(
+			"%s comes first in the default provider order among %d eligible providers", selected, len(eligible))
+	}
+	return domain.RoutingStrategyLoadBalancer, fmt.Sprintf(
+		"the load balancer picked %s among %d eligible providers", selected, len(eligible))
+}
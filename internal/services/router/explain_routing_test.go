@@ -0,0 +1,140 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+)
+
+// newExplainRoutingService serves gpt-4o from OpenAI and Azure OpenAI, has
+// Anthropic disabled and Bedrock unhealthy, and Local serving other models
+func newExplainRoutingService() *Service {
+	s := newAutoRoutingService(env.AutoRoutingWeights{Cost: 1})
+	s.providerConfigs[domain.ProviderAnthropic] = &domain.ProviderConfig{Provider: domain.ProviderAnthropic, HealthStatus: domain.ProviderHealthHealthy}
+	s.providerConfigs[domain.ProviderAWSBedrock] = &domain.ProviderConfig{
+		Provider: domain.ProviderAWSBedrock, Enabled: true, HealthStatus: domain.ProviderHealthUnhealthy, LastHealthCheck: time.Now(),
+	}
+	s.providerConfigs[domain.ProviderLocal] = &domain.ProviderConfig{Provider: domain.ProviderLocal, Enabled: true, HealthStatus: domain.ProviderHealthHealthy}
+	s.registerModel(&domain.Model{ModelID: "gpt-4o", Provider: domain.ProviderAWSBedrock})
+	s.registerModel(&domain.Model{ModelID: "llama3", Provider: domain.ProviderLocal})
+	return s
+}
+
+func routingExplanation(t *testing.T, response *domain.CompletionResponse) *domain.RoutingExplanation {
+	explanation, ok := response.Metadata[domain.MetadataKeyRouting].(*domain.RoutingExplanation)
+	require.True(t, ok, "metadata.routing is missing")
+	return explanation
+}
+
+func excludedBy(explanation *domain.RoutingExplanation) map[domain.Provider]string {
+	excluded := make(map[domain.Provider]string)
+	for _, candidate := range explanation.Candidates {
+		excluded[candidate.Provider] = candidate.Excluded
+	}
+	return excluded
+}
+
+func TestRouteCompletion_ExplainsAutoRouting(t *testing.T) {
+	s := newExplainRoutingService()
+	s.providerStats.Record(domain.ProviderOpenAI, 200*time.Millisecond, false)
+	req := newCacheTestRequest("tenant-a")
+	req.Provider = domain.ProviderAuto
+	req.ExplainRouting = true
+
+	response, err := s.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	explanation := routingExplanation(t, response)
+
+	assert.Equal(t, "gpt-4o", explanation.Model)
+	assert.Equal(t, domain.RoutingStrategyAuto, explanation.Strategy)
+	assert.Equal(t, domain.ProviderAzureOpenAI, explanation.Selected)
+	assert.Contains(t, explanation.Rationale, "lowest weighted score")
+	assert.Equal(t, map[domain.Provider]string{
+		domain.ProviderAzureOpenAI: "",
+		domain.ProviderOpenAI:      "",
+		domain.ProviderAnthropic:   domain.RoutingExcludedDisabled,
+		domain.ProviderAWSBedrock:  domain.RoutingExcludedUnhealthy,
+		domain.ProviderLocal:       domain.RoutingExcludedModel,
+	}, excludedBy(explanation))
+
+	// The eligible providers come first, best first, with the scores the
+	// choice was made on
+	selected := explanation.Candidates[0]
+	assert.Equal(t, domain.ProviderAzureOpenAI, selected.Provider)
+	assert.True(t, selected.Selected)
+	require.NotNil(t, selected.Score)
+	assert.InDelta(t, 0.5, selected.Score.Score, 1e-9)
+	assert.InDelta(t, 0.02, selected.CostPer1K, 1e-9)
+
+	openai := explanation.Candidates[1]
+	assert.Equal(t, domain.ProviderOpenAI, openai.Provider)
+	assert.False(t, openai.Selected)
+	assert.InDelta(t, 200, openai.LatencyMs, 1e-9)
+	require.NotNil(t, openai.Score)
+	assert.InDelta(t, 1.0, openai.Score.Score, 1e-9)
+	assert.Nil(t, explanation.Candidates[2].Score)
+}
+
+func TestRouteCompletion_ExplainsFilters(t *testing.T) {
+	s := newExplainRoutingService()
+	s.config.DefaultProviderOrder = []domain.Provider{domain.ProviderAzureOpenAI}
+	req := newCacheTestRequest("tenant-a")
+	req.Provider = ""
+	req.ExplainRouting = true
+
+	response, err := s.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	explanation := routingExplanation(t, response)
+	assert.Equal(t, domain.RoutingStrategyDefaultOrder, explanation.Strategy)
+	assert.Equal(t, domain.ProviderAzureOpenAI, explanation.Selected)
+
+	// Switching Azure off leaves OpenAI the only candidate
+	s.config.KillSwitches = []domain.KillSwitch{{Provider: domain.ProviderAzureOpenAI}}
+	response, err = s.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	explanation = routingExplanation(t, response)
+	assert.Equal(t, domain.RoutingStrategyOnlyCandidate, explanation.Strategy)
+	assert.Equal(t, domain.ProviderOpenAI, explanation.Selected)
+	assert.Equal(t, domain.RoutingExcludedKillSwitch, excludedBy(explanation)[domain.ProviderAzureOpenAI])
+
+	// So does restricting the tenant to OpenAI
+	s.config.KillSwitches = nil
+	s.config.TenantProviders = map[string][]domain.Provider{"tenant-a": {domain.ProviderOpenAI}}
+	response, err = s.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	explanation = routingExplanation(t, response)
+	assert.Equal(t, domain.ProviderOpenAI, explanation.Selected)
+	assert.Equal(t, domain.RoutingStrategyOnlyCandidate, explanation.Strategy)
+	// Providers the tenant is not entitled to are not revealed
+	for _, candidate := range explanation.Candidates {
+		assert.NotEqual(t, domain.ProviderAzureOpenAI, candidate.Provider)
+	}
+}
+
+func TestRouteCompletion_ExplainsPinnedProvider(t *testing.T) {
+	s := newExplainRoutingService()
+	req := newCacheTestRequest("tenant-a")
+	req.ExplainRouting = true
+
+	response, err := s.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	explanation := routingExplanation(t, response)
+	assert.Equal(t, domain.RoutingStrategyPinned, explanation.Strategy)
+	assert.Equal(t, domain.ProviderOpenAI, explanation.Selected)
+	assert.Equal(t, domain.ProviderOpenAI, explanation.Candidates[0].Provider)
+	assert.Equal(t, domain.RoutingExcludedNotRequested, excludedBy(explanation)[domain.ProviderAzureOpenAI])
+}
+
+func TestRouteCompletion_NoExplanationUnlessAsked(t *testing.T) {
+	s := newExplainRoutingService()
+
+	response, err := s.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	assert.NotContains(t, response.Metadata, domain.MetadataKeyRouting)
+}
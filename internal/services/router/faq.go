@@ -0,0 +1,367 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// FAQStore keeps the canonical FAQ entries operators registered per tenant
+type FAQStore interface {
+	List(ctx context.Context, tenantID domain.TenantID) ([]domain.FAQEntry, error)
+	// Put adds an entry, replacing the tenant's entry with the same ID
+	Put(ctx context.Context, entry *domain.FAQEntry) error
+	Delete(ctx context.Context, tenantID domain.TenantID, id string) (bool, error)
+}
+
+// FileFAQStore keeps FAQ entries in memory and, when it has a path, rewrites
+// them to that file on every change so they survive restarts
+type FileFAQStore struct {
+	path    string
+	mu      sync.RWMutex
+	entries map[domain.TenantID][]domain.FAQEntry
+}
+
+// NewFileFAQStore creates a store holding the entries already saved at path.
+// An empty path keeps entries in memory only.
+func NewFileFAQStore(path string) (*FileFAQStore, error) {
+	store := &FileFAQStore{path: path, entries: make(map[domain.TenantID][]domain.FAQEntry)}
+	if path == "" {
+		return store, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read faq store: %w", err)
+	}
+	var entries []domain.FAQEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse faq store: %w", err)
+	}
+	for _, entry := range entries {
+		store.entries[entry.TenantID] = append(store.entries[entry.TenantID], entry)
+	}
+	return store, nil
+}
+
+func (s *FileFAQStore) List(ctx context.Context, tenantID domain.TenantID) ([]domain.FAQEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]domain.FAQEntry(nil), s.entries[tenantID]...), nil
+}
+
+func (s *FileFAQStore) Put(ctx context.Context, entry *domain.FAQEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.entries[entry.TenantID]
+	updated := make([]domain.FAQEntry, 0, len(previous)+1)
+	for _, existing := range previous {
+		if existing.ID != entry.ID {
+			updated = append(updated, existing)
+		}
+	}
+	s.entries[entry.TenantID] = append(updated, *entry)
+	if err := s.save(); err != nil {
+		s.entries[entry.TenantID] = previous
+		return err
+	}
+	return nil
+}
+
+func (s *FileFAQStore) Delete(ctx context.Context, tenantID domain.TenantID, id string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.entries[tenantID]
+	remaining := make([]domain.FAQEntry, 0, len(previous))
+	for _, entry := range previous {
+		if entry.ID != id {
+			remaining = append(remaining, entry)
+		}
+	}
+	if len(remaining) == len(previous) {
+		return false, nil
+	}
+
+	s.entries[tenantID] = remaining
+	if err := s.save(); err != nil {
+		s.entries[tenantID] = previous
+		return false, err
+	}
+	return true, nil
+}
+
+// save writes every tenant's entries to the file, replacing it atomically.
+// The caller holds the write lock.
+func (s *FileFAQStore) save() error {
+	if s.path == "" {
+		return nil
+	}
+
+	tenants := make([]string, 0, len(s.entries))
+	for tenantID := range s.entries {
+		tenants = append(tenants, string(tenantID))
+	}
+	sort.Strings(tenants)
+	all := []domain.FAQEntry{}
+	for _, tenantID := range tenants {
+		all = append(all, s.entries[domain.TenantID(tenantID)]...)
+	}
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal faq entries: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write faq store: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to replace faq store: %w", err)
+	}
+	return nil
+}
+
+// answerFromFAQ returns the tenant's canonical answer when the prompt asks
+// one of its FAQ questions, or nil to route the request to a provider. Any
+// failure along the way is logged and treated as no match.
+func (s *Service) answerFromFAQ(ctx context.Context, req *domain.CompletionRequest) *domain.CompletionResponse {
+	config := s.currentConfig().FAQ
+	if s.faqs == nil || config.MatchThreshold == 0 {
+		return nil
+	}
+	question, ok := faqQuestion(req)
+	if !ok {
+		return nil
+	}
+
+	entries, err := s.faqs.List(ctx, req.TenantID)
+	if err != nil {
+		s.logger.Warn("FAQ lookup failed", logger.F("tenant_id", req.TenantID), logger.F("error", err))
+		faqRequests.WithLabelValues(string(req.TenantID), "error").Inc()
+		return nil
+	}
+	// Tenants without entries never pay for embedding their prompts
+	if len(entries) == 0 {
+		return nil
+	}
+	entries = s.reembedFAQEntries(ctx, req.DataResidency, config.EmbeddingModel, entries)
+	if len(entries) == 0 {
+		faqRequests.WithLabelValues(string(req.TenantID), "error").Inc()
+		return nil
+	}
+
+	embedding, err := s.embedFAQText(ctx, req.TenantID, req.DataResidency, config.EmbeddingModel, question)
+	if err != nil {
+		s.logger.Warn("Failed to embed prompt for FAQ matching", logger.F("tenant_id", req.TenantID), logger.F("error", err))
+		faqRequests.WithLabelValues(string(req.TenantID), "error").Inc()
+		return nil
+	}
+
+	var best *domain.FAQEntry
+	bestSimilarity := 0.0
+	for i := range entries {
+		if similarity := domain.CosineSimilarity(embedding, entries[i].Embedding); similarity > bestSimilarity {
+			best, bestSimilarity = &entries[i], similarity
+		}
+	}
+	if best == nil || bestSimilarity < config.MatchThreshold {
+		faqRequests.WithLabelValues(string(req.TenantID), "miss").Inc()
+		return nil
+	}
+
+	faqRequests.WithLabelValues(string(req.TenantID), "hit").Inc()
+	return &domain.CompletionResponse{
+		ID:      "faq-" + uuid.New().String(),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   req.Model,
+		Choices: []domain.Choice{{
+			Message: domain.Message{
+				Role:    domain.MessageRoleAssistant,
+				Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: best.Answer}},
+			},
+			FinishReason: domain.FinishReasonStop,
+		}},
+		// No provider generated the answer, so like a cache hit it is free
+		Usage: domain.Usage{CacheHit: true},
+		Metadata: map[string]interface{}{
+			domain.MetadataKeyFAQMatch: domain.FAQMatch{EntryID: best.ID, Question: best.Question, Similarity: bestSimilarity},
+		},
+		RequestID:  req.RequestID,
+		ReceivedAt: time.Now().UTC(),
+	}
+}
+
+// reembedFAQEntries returns the entries embedded by model, embedding those
+// registered under a previous FAQ embedding model again and storing them, so
+// changing the model does not leave them unmatched. Entries that cannot be
+// embedded now are logged and left out until the next request tries again.
+func (s *Service) reembedFAQEntries(ctx context.Context, region, model string, entries []domain.FAQEntry) []domain.FAQEntry {
+	current := make([]domain.FAQEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.EmbeddingModel != model {
+			embedding, err := s.embedFAQText(ctx, entry.TenantID, region, model, strings.TrimSpace(entry.Question))
+			if err == nil {
+				entry.EmbeddingModel, entry.Embedding = model, embedding
+				err = s.faqs.Put(ctx, &entry)
+			}
+			if err != nil {
+				s.logger.Warn("Failed to re-embed FAQ entry",
+					logger.F("tenant_id", entry.TenantID),
+					logger.F("entry_id", entry.ID),
+					logger.F("embedding_model", model),
+					logger.F("error", err))
+				continue
+			}
+		}
+		current = append(current, entry)
+	}
+	return current
+}
+
+// streamFAQAnswer answers a stream with a canonical answer the way a stream
+// falling back to a completion is answered: a single event carrying the
+// answer, then the final event, with the match in its metadata, and [DONE]
+func streamFAQAnswer(c *gin.Context, req *domain.CompletionRequest, answer *domain.CompletionResponse, finish *streamFinish) {
+	event := &domain.StreamResponse{
+		ID:      answer.ID,
+		Object:  "chat.completion.chunk",
+		Created: answer.Created,
+		Model:   answer.Model,
+		Choices: answer.Choices,
+	}
+	finish.observe(event)
+	finish.warn(domain.MetadataKeyFAQMatch, answer.Metadata[domain.MetadataKeyFAQMatch])
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	writeStreamEvents(c, []*domain.StreamResponse{event})
+	usage := answer.Usage
+	writeStreamEnd(c, finish.event(req, answer.Provider, answer.Created, &usage))
+}
+
+// faqQuestion returns the text a request asks, when it is a plain question
+// a canonical answer can stand in for: the last message is the user's, and
+// the request expects neither tool calls nor structured output
+func faqQuestion(req *domain.CompletionRequest) (string, bool) {
+	if len(req.Messages) == 0 || len(req.Tools) > 0 || req.ResponseFormat.IsJSON() {
+		return "", false
+	}
+	last := req.Messages[len(req.Messages)-1]
+	if last.Role != domain.MessageRoleUser {
+		return "", false
+	}
+
+	var text strings.Builder
+	for _, part := range last.Content {
+		if part.Type == domain.ContentTypeText {
+			text.WriteString(part.Text)
+		}
+	}
+	question := strings.TrimSpace(text.String())
+	return question, question != ""
+}
+
+// embedFAQText embeds a question or prompt with the FAQ embedding model
+func (s *Service) embedFAQText(ctx context.Context, tenantID domain.TenantID, region, model, text string) ([]float64, error) {
+	response, err := s.routeEmbedding(ctx, &domain.EmbeddingRequest{TenantID: tenantID, Model: model, Input: []string{text}, DataResidency: region})
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Data) != 1 || len(response.Data[0].Embedding) == 0 {
+		return nil, shared_errors.ProviderError(string(response.Provider), "embedding response has no vector", nil)
+	}
+	return response.Data[0].Embedding, nil
+}
+
+// createFAQEntry embeds a canonical question and registers it with its
+// answer for the tenant
+func (s *Service) createFAQEntry(ctx context.Context, tenantID domain.TenantID, req *domain.CreateFAQEntryRequest) (*domain.FAQEntry, error) {
+	if strings.TrimSpace(req.Question) == "" {
+		return nil, shared_errors.ValidationError("question is required", "question")
+	}
+	if strings.TrimSpace(req.Answer) == "" {
+		return nil, shared_errors.ValidationError("answer is required", "answer")
+	}
+
+	model := s.currentConfig().FAQ.EmbeddingModel
+	embedding, err := s.embedFAQText(ctx, tenantID, req.DataResidency, model, strings.TrimSpace(req.Question))
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &domain.FAQEntry{
+		ID:             "faq_" + uuid.New().String(),
+		TenantID:       tenantID,
+		Question:       req.Question,
+		Answer:         req.Answer,
+		EmbeddingModel: model,
+		Embedding:      embedding,
+		CreatedAt:      time.Now().UTC(),
+	}
+	if err := s.faqs.Put(ctx, entry); err != nil {
+		return nil, shared_errors.InternalError("failed to store faq entry", err)
+	}
+	return entry, nil
+}
+
+// handleListFAQEntries lists a tenant's FAQ entries without their embeddings
+func (s *Service) handleListFAQEntries(c *gin.Context) {
+	entries, err := s.faqs.List(c.Request.Context(), domain.TenantID(c.Param("tenant_id")))
+	if err != nil {
+		s.respondWithError(c, shared_errors.InternalError("failed to list faq entries", err))
+		return
+	}
+	for i := range entries {
+		entries[i].Embedding = nil
+	}
+	c.JSON(http.StatusOK, domain.FAQEntryList{Data: entries})
+}
+
+func (s *Service) handleCreateFAQEntry(c *gin.Context) {
+	var req domain.CreateFAQEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondWithError(c, shared_errors.ValidationError("invalid request", "body"))
+		return
+	}
+
+	entry, err := s.createFAQEntry(c.Request.Context(), domain.TenantID(c.Param("tenant_id")), &req)
+	if err != nil {
+		s.respondWithError(c, err)
+		return
+	}
+	entry.Embedding = nil
+	c.JSON(http.StatusCreated, entry)
+}
+
+func (s *Service) handleDeleteFAQEntry(c *gin.Context) {
+	id := c.Param("id")
+	found, err := s.faqs.Delete(c.Request.Context(), domain.TenantID(c.Param("tenant_id")), id)
+	if err != nil {
+		s.respondWithError(c, shared_errors.InternalError("failed to delete faq entry", err))
+		return
+	}
+	if !found {
+		s.respondWithError(c, shared_errors.NotFoundError("faq entry", id))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
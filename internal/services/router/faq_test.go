@@ -0,0 +1,238 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// faqProviderClient embeds text by the topics it mentions and counts the
+// completions and embeddings it is asked for
+type faqProviderClient struct {
+	countingProviderClient
+	embeddings int
+}
+
+func (c *faqProviderClient) CreateEmbeddings(ctx context.Context, req *domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	c.embeddings++
+	response := &domain.EmbeddingResponse{Object: "list", Model: req.Model, Provider: domain.ProviderOpenAI}
+	for i, input := range req.Input {
+		input = strings.ToLower(input)
+		vector := make([]float64, 3)
+		for j, topic := range []string{"password", "refund", "shipping"} {
+			if strings.Contains(input, topic) {
+				vector[j] = 1
+			}
+		}
+		response.Data = append(response.Data, domain.Embedding{Object: "embedding", Embedding: vector, Index: i})
+	}
+	return response, nil
+}
+
+func newFAQTestService(t *testing.T, client ProviderClient, path string) *Service {
+	s := newCacheTestService(client, nil)
+	s.config.FAQ = env.FAQConfig{EmbeddingModel: "text-embedding-3-small", MatchThreshold: 0.9}
+	s.modelRegistry = make(map[string]map[domain.Provider]*domain.Model)
+	s.registerModel(&domain.Model{ModelID: "gpt-4o", Provider: domain.ProviderOpenAI})
+	s.registerModel(&domain.Model{ModelID: "text-embedding-3-small", Provider: domain.ProviderOpenAI})
+	faqs, err := NewFileFAQStore(path)
+	require.NoError(t, err)
+	s.faqs = faqs
+	return s
+}
+
+func newFAQTestRequest(tenantID domain.TenantID, question string) *domain.CompletionRequest {
+	req := newCacheTestRequest(tenantID)
+	req.CacheEnabled = false
+	req.Messages[0].Content[0].Text = question
+	return req
+}
+
+func TestRouteCompletion_AnswersFromFAQ(t *testing.T) {
+	client := &faqProviderClient{}
+	s := newFAQTestService(t, client, "")
+	entry, err := s.createFAQEntry(context.Background(), "tenant-a", &domain.CreateFAQEntryRequest{
+		Question: "How do I reset my password?",
+		Answer:   "Use the Forgot password link on the sign-in page.",
+	})
+	require.NoError(t, err)
+
+	response, err := s.routeCompletion(context.Background(), newFAQTestRequest("tenant-a", "I forgot my PASSWORD, what now?"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, client.calls)
+	assert.Equal(t, "Use the Forgot password link on the sign-in page.", response.Choices[0].Message.Content[0].Text)
+	assert.True(t, response.Usage.CacheHit)
+	match := response.Metadata[domain.MetadataKeyFAQMatch].(domain.FAQMatch)
+	assert.Equal(t, entry.ID, match.EntryID)
+	assert.InDelta(t, 1.0, match.Similarity, 1e-9)
+
+	// Other questions, and other tenants, go to the provider. Tenants
+	// without entries have no prompt embedded.
+	_, err = s.routeCompletion(context.Background(), newFAQTestRequest("tenant-a", "When will my refund arrive?"))
+	require.NoError(t, err)
+	embeddings := client.embeddings
+	_, err = s.routeCompletion(context.Background(), newFAQTestRequest("tenant-b", "How do I reset my password?"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, client.calls)
+	assert.Equal(t, embeddings, client.embeddings)
+
+	// Structured output cannot be answered with free text
+	req := newFAQTestRequest("tenant-a", "How do I reset my password?")
+	req.ResponseFormat = &domain.ResponseFormat{Type: domain.ResponseFormatJSONObject}
+	_, err = s.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 3, client.calls)
+
+	// A threshold of 0 turns the layer off
+	s.config.FAQ.MatchThreshold = 0
+	_, err = s.routeCompletion(context.Background(), newFAQTestRequest("tenant-a", "How do I reset my password?"))
+	require.NoError(t, err)
+	assert.Equal(t, 4, client.calls)
+}
+
+func TestRouteCompletionStream_AnswersFromFAQ(t *testing.T) {
+	client := &faqProviderClient{}
+	s := newFAQTestService(t, client, "")
+	entry, err := s.createFAQEntry(context.Background(), "tenant-a", &domain.CreateFAQEntryRequest{
+		Question: "How do I reset my password?",
+		Answer:   "Use the Forgot password link on the sign-in page.",
+	})
+	require.NoError(t, err)
+
+	req := newFAQTestRequest("tenant-a", "I forgot my password")
+	req.Stream = true
+	w, err := routeTestStream(t, s, req)
+	require.NoError(t, err)
+	assert.Equal(t, 0, client.calls)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	require.Len(t, events, 3)
+	assert.Contains(t, events[0], "Use the Forgot password link on the sign-in page.")
+	var final domain.StreamResponse
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(events[1], "data: ")), &final))
+	assert.True(t, final.Final)
+	assert.Equal(t, domain.FinishReasonStop, final.Choices[0].FinishReason)
+	match, ok := final.Metadata[domain.MetadataKeyFAQMatch].(map[string]interface{})
+	require.True(t, ok, "final event metadata: %v", final.Metadata)
+	assert.Equal(t, entry.ID, match["entry_id"])
+	assert.Equal(t, "data: [DONE]", events[2])
+}
+
+func TestAnswerFromFAQ_ReembedsEntriesAfterModelChange(t *testing.T) {
+	client := &faqProviderClient{}
+	s := newFAQTestService(t, client, "")
+	entry, err := s.createFAQEntry(context.Background(), "tenant-a", &domain.CreateFAQEntryRequest{
+		Question: "How do I reset my password?",
+		Answer:   "Use the Forgot password link on the sign-in page.",
+	})
+	require.NoError(t, err)
+
+	s.registerModel(&domain.Model{ModelID: "text-embedding-3-large", Provider: domain.ProviderOpenAI})
+	s.config.FAQ.EmbeddingModel = "text-embedding-3-large"
+
+	// The entry is embedded again with the new model and still matches
+	answer := s.answerFromFAQ(context.Background(), newFAQTestRequest("tenant-a", "I forgot my password"))
+	require.NotNil(t, answer)
+	entries, err := s.faqs.List(context.Background(), "tenant-a")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, entry.ID, entries[0].ID)
+	assert.Equal(t, "text-embedding-3-large", entries[0].EmbeddingModel)
+
+	// Only once: later prompts embed just themselves
+	embeddings := client.embeddings
+	require.NotNil(t, s.answerFromFAQ(context.Background(), newFAQTestRequest("tenant-a", "password help")))
+	assert.Equal(t, embeddings+1, client.embeddings)
+}
+
+func TestCreateFAQEntry_StaysInDataResidencyRegion(t *testing.T) {
+	client := &faqProviderClient{}
+	s := newFAQTestService(t, client, "")
+	s.providerConfigs[domain.ProviderOpenAI].DataRegion = "us"
+
+	_, err := s.createFAQEntry(context.Background(), "tenant-a", &domain.CreateFAQEntryRequest{
+		Question:      "How do I reset my password?",
+		Answer:        "Use the Forgot password link on the sign-in page.",
+		DataResidency: "eu",
+	})
+	require.Error(t, err)
+	assert.Equal(t, "data_residency_unavailable", shared_errors.FromError(err).Code)
+
+	_, err = s.createFAQEntry(context.Background(), "tenant-a", &domain.CreateFAQEntryRequest{
+		Question:      "How do I reset my password?",
+		Answer:        "Use the Forgot password link on the sign-in page.",
+		DataResidency: "us",
+	})
+	require.NoError(t, err)
+}
+
+func TestFileFAQStore_PersistsEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "faq.json")
+	store, err := NewFileFAQStore(path)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, &domain.FAQEntry{ID: "faq_1", TenantID: "tenant-a", Question: "q1", Embedding: []float64{1, 0}}))
+	require.NoError(t, store.Put(ctx, &domain.FAQEntry{ID: "faq_2", TenantID: "tenant-a", Question: "q2"}))
+	found, err := store.Delete(ctx, "tenant-a", "faq_2")
+	require.NoError(t, err)
+	assert.True(t, found)
+	found, err = store.Delete(ctx, "tenant-b", "faq_1")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	reopened, err := NewFileFAQStore(path)
+	require.NoError(t, err)
+	entries, err := reopened.List(ctx, "tenant-a")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "faq_1", entries[0].ID)
+	assert.Equal(t, []float64{1, 0}, entries[0].Embedding)
+}
+
+func TestHandleFAQEntries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := newFAQTestService(t, &faqProviderClient{}, "")
+	router := gin.New()
+	router.GET("/internal/v1/faq/:tenant_id", s.handleListFAQEntries)
+	router.POST("/internal/v1/faq/:tenant_id", s.handleCreateFAQEntry)
+	router.DELETE("/internal/v1/faq/:tenant_id/:id", s.handleDeleteFAQEntry)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/internal/v1/faq/tenant-a", `{"question": "How do I get a refund?"}`).Code)
+
+	w := do(http.MethodPost, "/internal/v1/faq/tenant-a", `{"question": "How do I get a refund?", "answer": "Within 30 days."}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var entry domain.FAQEntry
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &entry))
+	assert.Empty(t, entry.Embedding)
+	assert.Equal(t, "text-embedding-3-small", entry.EmbeddingModel)
+
+	w = do(http.MethodGet, "/internal/v1/faq/tenant-a", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var list domain.FAQEntryList
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.Empty(t, list.Data[0].Embedding)
+
+	assert.Equal(t, http.StatusNotFound, do(http.MethodDelete, "/internal/v1/faq/tenant-b/"+entry.ID, "").Code)
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/internal/v1/faq/tenant-a/"+entry.ID, "").Code)
+}
@@ -0,0 +1,13 @@
+package router
+
+import "github.com/quantum-suite/platform/internal/domain"
+
+// stripJSONFences unwraps the JSON in a response to a request expecting
+// JSON output, when the post-processing is enabled. Models often fence JSON
+// in markdown or add prose around it despite response_format.
+func (s *Service) stripJSONFences(req *domain.CompletionRequest, response *domain.CompletionResponse) {
+	if !req.ResponseFormat.IsJSON() || !s.currentConfig().StripJSONFences {
+		return
+	}
+	response.StripJSONFences()
+}
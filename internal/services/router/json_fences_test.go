@@ -0,0 +1,47 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// fencedProviderClient answers with JSON wrapped in a markdown fence
+type fencedProviderClient struct {
+	ProviderClient
+}
+
+func (c *fencedProviderClient) CreateCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+	return &domain.CompletionResponse{
+		ID:       "resp-1",
+		Model:    req.Model,
+		Provider: domain.ProviderOpenAI,
+		Choices: []domain.Choice{{Message: domain.Message{
+			Role:    domain.MessageRoleAssistant,
+			Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "```json\n{\"answer\": 42}\n```"}},
+		}}},
+	}, nil
+}
+
+func TestRouteCompletion_StripsJSONFences(t *testing.T) {
+	s := newCacheTestService(&fencedProviderClient{}, nil)
+	s.config.StripJSONFences = true
+	req := newCacheTestRequest("tenant-a")
+	req.CacheEnabled = false
+
+	// Only requests expecting JSON are cleaned up
+	response, err := s.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	assert.Contains(t, response.Choices[0].Message.Content[0].Text, "```")
+	assert.NotContains(t, response.Metadata, domain.MetadataKeyJSONCleanup)
+
+	req.ResponseFormat = &domain.ResponseFormat{Type: domain.ResponseFormatJSONObject}
+	response, err = s.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, `{"answer": 42}`, response.Choices[0].Message.Content[0].Text)
+	assert.Equal(t, true, response.Metadata[domain.MetadataKeyJSONCleanup])
+}
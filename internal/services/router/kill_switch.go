@@ -0,0 +1,321 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+const (
+	// killSwitchKey locates the toggled kill switches in the system store
+	killSwitchKey = "router:kill_switches"
+
+	// killSwitchUpdateAttempts bounds how often a toggle is retried while
+	// other replicas keep changing the switches
+	killSwitchUpdateAttempts = 5
+)
+
+// KillSwitchStore keeps the kill switches toggled through the admin API
+type KillSwitchStore interface {
+	// Load returns the stored switches, and false when none are stored
+	Load(ctx context.Context) ([]domain.KillSwitch, bool, error)
+	// Update replaces the stored switches with change's result atomically,
+	// calling change again if another replica changed them meanwhile
+	Update(ctx context.Context, change func(current []domain.KillSwitch, found bool) []domain.KillSwitch) ([]domain.KillSwitch, error)
+}
+
+// CacheKillSwitchStore keeps kill switches in the cache's system key space.
+// Through the cache service every router replica sees the same switches; a
+// local cache store only covers its own replica.
+type CacheKillSwitchStore struct {
+	store SystemStore
+}
+
+// NewCacheKillSwitchStore creates a kill switch store in the cache
+func NewCacheKillSwitchStore(store SystemStore) *CacheKillSwitchStore {
+	return &CacheKillSwitchStore{store: store}
+}
+
+func (s *CacheKillSwitchStore) Load(ctx context.Context) ([]domain.KillSwitch, bool, error) {
+	switches, _, found, err := s.load(ctx)
+	return switches, found, err
+}
+
+// load returns the stored switches along with their encoding, which an
+// update swaps out
+func (s *CacheKillSwitchStore) load(ctx context.Context) ([]domain.KillSwitch, []byte, bool, error) {
+	data, found, err := s.store.Get(ctx, killSwitchKey)
+	if err != nil || !found {
+		return nil, nil, false, err
+	}
+	var switches []domain.KillSwitch
+	if err := json.Unmarshal(data, &switches); err != nil {
+		return nil, nil, false, fmt.Errorf("failed to parse kill switches: %w", err)
+	}
+	return switches, data, true, nil
+}
+
+func (s *CacheKillSwitchStore) Update(ctx context.Context, change func([]domain.KillSwitch, bool) []domain.KillSwitch) ([]domain.KillSwitch, error) {
+	for attempt := 0; attempt < killSwitchUpdateAttempts; attempt++ {
+		current, old, found, err := s.load(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		switches := change(current, found)
+		data, err := json.Marshal(switches)
+		if err != nil {
+			return nil, err
+		}
+		swapped, err := s.store.CompareAndSwap(ctx, killSwitchKey, old, data)
+		if err != nil {
+			return nil, err
+		}
+		if swapped {
+			return switches, nil
+		}
+	}
+	return nil, fmt.Errorf("kill switches kept changing during %d attempts to update them", killSwitchUpdateAttempts)
+}
+
+// KillSwitches holds the toggled kill switches, rereading them from the
+// shared store so switches toggled on another replica take effect here
+// within one refresh interval
+type KillSwitches struct {
+	store    KillSwitchStore
+	interval time.Duration
+	logger   logger.Logger
+
+	mu     sync.RWMutex
+	active []domain.KillSwitch
+	stopCh chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewKillSwitches creates kill switches kept in store, refreshed every
+// interval; zero disables refreshing
+func NewKillSwitches(store KillSwitchStore, interval time.Duration, log logger.Logger) *KillSwitches {
+	return &KillSwitches{
+		store:    store,
+		interval: interval,
+		logger:   log,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start loads the switches and keeps refreshing them
+func (k *KillSwitches) Start() {
+	k.refresh(context.Background())
+	if k.interval > 0 {
+		k.wg.Add(1)
+		go k.refreshLoop()
+	}
+}
+
+func (k *KillSwitches) Stop() {
+	close(k.stopCh)
+	k.wg.Wait()
+}
+
+func (k *KillSwitches) refreshLoop() {
+	defer k.wg.Done()
+
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			k.refresh(context.Background())
+		case <-k.stopCh:
+			return
+		}
+	}
+}
+
+// refresh rereads the switches. When the store cannot be read, or has lost
+// the switches, the last switches seen stay in effect; losing them must not
+// send traffic back to a provider that was switched off.
+func (k *KillSwitches) refresh(ctx context.Context) {
+	switches, found, err := k.store.Load(ctx)
+	if err != nil {
+		k.logger.Warn("Failed to refresh kill switches", logger.F("error", err))
+		return
+	}
+	if !found {
+		if active := k.Active(); len(active) > 0 {
+			k.logger.Warn("Kill switches are missing from the store; keeping the last ones seen",
+				logger.F("count", len(active)))
+		}
+		return
+	}
+	k.mu.Lock()
+	k.active = switches
+	k.mu.Unlock()
+}
+
+// Active returns the toggled switches
+func (k *KillSwitches) Active() []domain.KillSwitch {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return append([]domain.KillSwitch(nil), k.active...)
+}
+
+// Set turns a switch on or off in the store, applying it here at once.
+// Toggles made at the same moment on different replicas are all kept.
+func (k *KillSwitches) Set(ctx context.Context, sw domain.KillSwitch, killed bool) ([]domain.KillSwitch, error) {
+	switches, err := k.store.Update(ctx, func(current []domain.KillSwitch, found bool) []domain.KillSwitch {
+		if !found {
+			// The store lost the switches; start from the ones in effect
+			current = k.Active()
+		}
+		switches := make([]domain.KillSwitch, 0, len(current)+1)
+		for _, existing := range current {
+			if existing != sw {
+				switches = append(switches, existing)
+			}
+		}
+		if killed {
+			switches = append(switches, sw)
+		}
+		return switches
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	k.mu.Lock()
+	k.active = switches
+	k.mu.Unlock()
+	return switches, nil
+}
+
+// killSwitch returns the configured or toggled switch stopping the model at
+// the provider, if any
+func (s *Service) killSwitch(provider domain.Provider, modelID string) (domain.KillSwitch, bool) {
+	switches := s.currentConfig().KillSwitches
+	if s.killSwitches != nil {
+		switches = append(append([]domain.KillSwitch(nil), switches...), s.killSwitches.Active()...)
+	}
+	for _, sw := range switches {
+		if sw.Covers(provider, modelID) {
+			return sw, true
+		}
+	}
+	return domain.KillSwitch{}, false
+}
+
+// withoutKilled drops the providers whose traffic for the model is switched
+// off. When none remain the request is rejected rather than routed to them.
+func (s *Service) withoutKilled(providers []domain.Provider, modelID string) ([]domain.Provider, error) {
+	live := make([]domain.Provider, 0, len(providers))
+	var killed []domain.Provider
+	var first domain.KillSwitch
+	for _, provider := range providers {
+		sw, ok := s.killSwitch(provider, modelID)
+		if !ok {
+			live = append(live, provider)
+			continue
+		}
+		if len(killed) == 0 {
+			first = sw
+		}
+		killed = append(killed, provider)
+	}
+
+	outcome := "rerouted"
+	if len(live) == 0 {
+		outcome = "rejected"
+	}
+	for _, provider := range killed {
+		killedRequests.WithLabelValues(string(provider), modelID, outcome).Inc()
+	}
+	if len(live) == 0 && len(killed) > 0 {
+		return nil, killSwitchError(first, modelID)
+	}
+	return live, nil
+}
+
+// checkKillSwitch rejects a pinned provider whose traffic for the model is
+// switched off
+func (s *Service) checkKillSwitch(provider domain.Provider, modelID string) error {
+	sw, ok := s.killSwitch(provider, modelID)
+	if !ok {
+		return nil
+	}
+	killedRequests.WithLabelValues(string(provider), modelID, "rejected").Inc()
+	return killSwitchError(sw, modelID)
+}
+
+// killSwitchError is returned for a request only switched-off providers
+// could serve
+func killSwitchError(sw domain.KillSwitch, modelID string) error {
+	builder := shared_errors.NewError(shared_errors.ErrorTypeProviderUnavailable,
+		fmt.Sprintf("traffic to %s is stopped by a kill switch", sw)).
+		WithCode(shared_errors.CodeKillSwitch).
+		WithDetail("model", modelID).
+		WithSeverity(shared_errors.SeverityHigh).
+		WithRetryable(true)
+	if sw.Provider != "" {
+		builder = builder.WithDetail("provider", string(sw.Provider))
+	}
+	return builder.Build()
+}
+
+// killSwitchList lists the switches in effect
+func (s *Service) killSwitchList() *domain.KillSwitchList {
+	list := &domain.KillSwitchList{
+		Data:       []domain.KillSwitch{},
+		Configured: append([]domain.KillSwitch{}, s.currentConfig().KillSwitches...),
+	}
+	if s.killSwitches != nil {
+		list.Data = append(list.Data, s.killSwitches.Active()...)
+	}
+	return list
+}
+
+func (s *Service) handleListKillSwitches(c *gin.Context) {
+	c.JSON(http.StatusOK, s.killSwitchList())
+}
+
+func (s *Service) handleSetKillSwitch(c *gin.Context) {
+	var req domain.SetKillSwitchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		s.respondWithError(c, shared_errors.ValidationError("invalid request", "body"))
+		return
+	}
+	if req.Provider == "" && req.Model == "" {
+		s.respondWithError(c, shared_errors.ValidationError("a kill switch needs a provider, a model or both", "provider"))
+		return
+	}
+	if req.Provider != "" {
+		s.mu.RLock()
+		_, known := s.providerConfigs[req.Provider]
+		s.mu.RUnlock()
+		if !known {
+			s.respondWithError(c, shared_errors.ValidationError(fmt.Sprintf("unknown provider %s", req.Provider), "provider"))
+			return
+		}
+	}
+	if s.killSwitches == nil {
+		s.respondWithError(c, shared_errors.InternalError("kill switches are not available", nil))
+		return
+	}
+
+	if _, err := s.killSwitches.Set(c.Request.Context(), req.KillSwitch, req.Killed); err != nil {
+		s.respondWithError(c, shared_errors.InternalError("failed to save kill switch", err))
+		return
+	}
+	s.logger.Warn("Kill switch toggled",
+		logger.F("kill_switch", req.KillSwitch.String()),
+		logger.F("killed", req.Killed))
+	c.JSON(http.StatusOK, s.killSwitchList())
+}
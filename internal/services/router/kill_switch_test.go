@@ -0,0 +1,154 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/services/cache"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func newKillSwitchTestService(store KillSwitchStore) *Service {
+	s := newAutoRoutingService(env.AutoRoutingWeights{Cost: 1})
+	s.config.DefaultProviderOrder = []domain.Provider{domain.ProviderOpenAI, domain.ProviderAzureOpenAI}
+	s.killSwitches = NewKillSwitches(store, 0, logger.NewNoop())
+	return s
+}
+
+func setKillSwitch(t *testing.T, s *Service, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, "/internal/v1/kill-switches", strings.NewReader(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+	s.handleSetKillSwitch(c)
+	return w
+}
+
+func TestSelectProvider_RoutesAroundKilledProvider(t *testing.T) {
+	s := newKillSwitchTestService(NewCacheKillSwitchStore(NewLocalSystemStore(cache.NewMemoryStore(logger.NewNoop()))))
+
+	provider, err := s.selectProvider("tenant-a", "gpt-4o", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderOpenAI, provider)
+
+	w := setKillSwitch(t, s, `{"provider":"openai","killed":true}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t, `{"data":[{"provider":"openai"}],"configured":[]}`, w.Body.String())
+
+	provider, err = s.selectProvider("tenant-a", "gpt-4o", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderAzureOpenAI, provider)
+
+	// A request pinned to the provider is rejected
+	_, err = s.selectProvider("tenant-a", "gpt-4o", domain.ProviderOpenAI, "")
+	require.Error(t, err)
+	qlensErr := shared_errors.FromError(err)
+	assert.Equal(t, "kill_switch", qlensErr.Code)
+	assert.Equal(t, http.StatusServiceUnavailable, qlensErr.HTTPStatusCode())
+	// The error taxonomy lists the code with the status it is returned with
+	info, ok := shared_errors.DescribeErrorCode(qlensErr.Type, qlensErr.Code)
+	require.True(t, ok)
+	assert.Equal(t, qlensErr.HTTPStatusCode(), info.StatusCode)
+	assert.Equal(t, qlensErr.Retryable, info.Retryable)
+
+	// Turning it off restores the provider
+	require.Equal(t, http.StatusOK, setKillSwitch(t, s, `{"provider":"openai","killed":false}`).Code)
+	provider, err = s.selectProvider("tenant-a", "gpt-4o", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderOpenAI, provider)
+}
+
+func TestSelectProvider_KilledModelEverywhere(t *testing.T) {
+	s := newKillSwitchTestService(NewCacheKillSwitchStore(NewLocalSystemStore(cache.NewMemoryStore(logger.NewNoop()))))
+	s.config.KillSwitches = []domain.KillSwitch{{Model: "gpt-4o"}}
+
+	_, err := s.selectProvider("tenant-a", "gpt-4o", "", "")
+	require.Error(t, err)
+	assert.Equal(t, "kill_switch", shared_errors.FromError(err).Code)
+	assert.Contains(t, err.Error(), "*/gpt-4o")
+
+	_, _, err = s.selectAutoProvider("tenant-a", "gpt-4o", "")
+	require.Error(t, err)
+	assert.Equal(t, "kill_switch", shared_errors.FromError(err).Code)
+}
+
+func TestKillSwitches_SharedAcrossReplicas(t *testing.T) {
+	shared := NewLocalSystemStore(cache.NewMemoryStore(logger.NewNoop()))
+	a := newKillSwitchTestService(NewCacheKillSwitchStore(shared))
+	b := newKillSwitchTestService(NewCacheKillSwitchStore(shared))
+
+	require.Equal(t, http.StatusOK, setKillSwitch(t, a, `{"provider":"openai","model":"gpt-4o","killed":true}`).Code)
+
+	// The other replica picks it up on its next refresh
+	b.killSwitches.refresh(context.Background())
+	provider, err := b.selectProvider("tenant-a", "gpt-4o", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderAzureOpenAI, provider)
+}
+
+func TestKillSwitches_ConcurrentTogglesAcrossReplicas(t *testing.T) {
+	shared := NewLocalSystemStore(cache.NewMemoryStore(logger.NewNoop()))
+	replicas := []*KillSwitches{
+		NewKillSwitches(NewCacheKillSwitchStore(shared), 0, logger.NewNoop()),
+		NewKillSwitches(NewCacheKillSwitchStore(shared), 0, logger.NewNoop()),
+	}
+
+	var wg sync.WaitGroup
+	// Fewer toggles than update attempts, so none can run out of them
+	for i := 0; i < 2; i++ {
+		for _, replica := range replicas {
+			wg.Add(1)
+			go func(replica *KillSwitches, model string) {
+				defer wg.Done()
+				_, err := replica.Set(context.Background(), domain.KillSwitch{Model: model}, true)
+				assert.NoError(t, err)
+			}(replica, fmt.Sprintf("model-%p-%d", replica, i))
+		}
+	}
+	wg.Wait()
+
+	// No toggle overwrote another
+	replicas[0].refresh(context.Background())
+	assert.Len(t, replicas[0].Active(), 4)
+}
+
+func TestKillSwitches_KeepSwitchesLostByStore(t *testing.T) {
+	memory := cache.NewMemoryStore(logger.NewNoop())
+	s := newKillSwitchTestService(NewCacheKillSwitchStore(NewLocalSystemStore(memory)))
+	require.Equal(t, http.StatusOK, setKillSwitch(t, s, `{"provider":"openai","killed":true}`).Code)
+
+	// The store losing the switches does not send traffic back
+	require.NoError(t, memory.Delete(context.Background(), systemCacheKey(killSwitchKey)))
+	s.killSwitches.refresh(context.Background())
+	provider, err := s.selectProvider("tenant-a", "gpt-4o", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderAzureOpenAI, provider)
+
+	// The next toggle stores them again
+	require.Equal(t, http.StatusOK, setKillSwitch(t, s, `{"model":"gpt-4o-mini","killed":true}`).Code)
+	stored, found, err := s.killSwitches.store.Load(context.Background())
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.ElementsMatch(t, []domain.KillSwitch{{Provider: domain.ProviderOpenAI}, {Model: "gpt-4o-mini"}}, stored)
+}
+
+func TestSetKillSwitch_Invalid(t *testing.T) {
+	s := newKillSwitchTestService(NewCacheKillSwitchStore(NewLocalSystemStore(cache.NewMemoryStore(logger.NewNoop()))))
+
+	assert.Equal(t, http.StatusBadRequest, setKillSwitch(t, s, `{"killed":true}`).Code)
+	assert.Equal(t, http.StatusBadRequest, setKillSwitch(t, s, `{"provider":"nope","killed":true}`).Code)
+	assert.Empty(t, s.killSwitches.Active())
+}
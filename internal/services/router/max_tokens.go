@@ -0,0 +1,43 @@
+package router
+
+import "github.com/quantum-suite/platform/internal/domain"
+
+// applyMaxTokensLimits fills max_tokens from the model's default when the
+// request omits it and clamps it to the model's ceiling, so a request cannot
+// generate up to the whole context window. It runs after the parameter
+// profile and before the cache key is computed, and returns the adjustment
+// made, if any.
+func (s *Service) applyMaxTokensLimits(req *domain.CompletionRequest) *domain.MaxTokensAdjustment {
+	limits := s.currentConfig().ModelDefaultsFor(req.Model)
+
+	var adjustment *domain.MaxTokensAdjustment
+	switch {
+	case req.MaxTokens == nil && limits.MaxTokens > 0:
+		maxTokens := limits.MaxTokens
+		if limits.MaxTokensCeiling > 0 && maxTokens > limits.MaxTokensCeiling {
+			maxTokens = limits.MaxTokensCeiling
+		}
+		req.MaxTokens = &maxTokens
+		adjustment = &domain.MaxTokensAdjustment{Action: domain.MaxTokensDefaulted, MaxTokens: maxTokens}
+	case req.MaxTokens != nil && limits.MaxTokensCeiling > 0 && *req.MaxTokens > limits.MaxTokensCeiling:
+		maxTokens := limits.MaxTokensCeiling
+		adjustment = &domain.MaxTokensAdjustment{Action: domain.MaxTokensClamped, MaxTokens: maxTokens, Requested: *req.MaxTokens}
+		req.MaxTokens = &maxTokens
+	default:
+		return nil
+	}
+
+	maxTokensAdjustments.WithLabelValues(req.Model, adjustment.Action).Inc()
+	return adjustment
+}
+
+// setMaxTokensAdjustment records the adjustment in the response metadata
+func setMaxTokensAdjustment(response *domain.CompletionResponse, adjustment *domain.MaxTokensAdjustment) {
+	if adjustment == nil {
+		return
+	}
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]interface{})
+	}
+	response.Metadata[domain.MetadataKeyMaxTokensAdjustment] = adjustment
+}
@@ -0,0 +1,53 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+)
+
+func TestApplyMaxTokensLimits(t *testing.T) {
+	s := newCacheTestService(nil, nil)
+	s.config.ModelDefaults = map[string]env.ModelDefaults{
+		env.AllModels: {MaxTokens: 1024, MaxTokensCeiling: 4096},
+		"gpt-4o":      {MaxTokensCeiling: 2048},
+	}
+	maxTokens := func(n int) *int { return &n }
+
+	// The default comes from the catch-all entry, bounded by the model's ceiling
+	req := &domain.CompletionRequest{Model: "gpt-4o"}
+	adjustment := s.applyMaxTokensLimits(req)
+	require.NotNil(t, adjustment)
+	assert.Equal(t, domain.MaxTokensAdjustment{Action: domain.MaxTokensDefaulted, MaxTokens: 1024}, *adjustment)
+	assert.Equal(t, 1024, *req.MaxTokens)
+
+	req = &domain.CompletionRequest{Model: "gpt-4o", MaxTokens: maxTokens(100000)}
+	adjustment = s.applyMaxTokensLimits(req)
+	require.NotNil(t, adjustment)
+	assert.Equal(t, domain.MaxTokensAdjustment{Action: domain.MaxTokensClamped, MaxTokens: 2048, Requested: 100000}, *adjustment)
+	assert.Equal(t, 2048, *req.MaxTokens)
+
+	req = &domain.CompletionRequest{Model: "claude-3-sonnet", MaxTokens: maxTokens(3000)}
+	assert.Nil(t, s.applyMaxTokensLimits(req))
+	assert.Equal(t, 3000, *req.MaxTokens)
+
+	s.config.ModelDefaults = nil
+	req = &domain.CompletionRequest{Model: "gpt-4o"}
+	assert.Nil(t, s.applyMaxTokensLimits(req))
+	assert.Nil(t, req.MaxTokens)
+}
+
+func TestRouteCompletion_RecordsMaxTokensAdjustment(t *testing.T) {
+	s := newCacheTestService(&countingProviderClient{}, nil)
+	s.config.ModelDefaults = map[string]env.ModelDefaults{"gpt-4o": {MaxTokensCeiling: 20}}
+	req := newCacheTestRequest("tenant-a")
+
+	response, err := s.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, &domain.MaxTokensAdjustment{Action: domain.MaxTokensClamped, MaxTokens: 20, Requested: 50}, response.Metadata[domain.MetadataKeyMaxTokensAdjustment])
+}
@@ -0,0 +1,132 @@
+package router
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// modelDeprecationHeader carries the deprecation warning on responses to
+// requests for a deprecated model
+const modelDeprecationHeader = "Warning"
+
+// applyModelDeprecation handles a request for a model the registry marks
+// deprecated. With the migrate action and a configured successor the
+// registry serves, the request is sent to the successor instead; otherwise
+// it goes ahead with a warning. It runs before the other request
+// adjustments so they apply to the model actually served, and counts every
+// such request per tenant to show who still has to move before the model
+// is withdrawn.
+func (s *Service) applyModelDeprecation(req *domain.CompletionRequest) *domain.ModelDeprecation {
+	return s.deprecateModel(req.TenantID, req.Provider, &req.Model, req.RequestID)
+}
+
+// applyEmbeddingModelDeprecation handles an embedding request for a
+// deprecated model the same way as a completion, before any cost routing or
+// fallback picks among models
+func (s *Service) applyEmbeddingModelDeprecation(req *domain.EmbeddingRequest) *domain.ModelDeprecation {
+	if req.Model == domain.EmbeddingModelAuto {
+		return nil
+	}
+	return s.deprecateModel(req.TenantID, req.Provider, &req.Model, req.RequestID)
+}
+
+// deprecateModel warns about or migrates the requested model, replacing it
+// with its successor when migrating
+func (s *Service) deprecateModel(tenantID domain.TenantID, provider domain.Provider, model *string, requestID string) *domain.ModelDeprecation {
+	if !s.modelDeprecated(provider, *model) {
+		return nil
+	}
+
+	config := s.currentConfig()
+	deprecation := &domain.ModelDeprecation{
+		Model:     *model,
+		Action:    domain.ModelDeprecationWarned,
+		Successor: config.ModelSuccessors[*model],
+	}
+	if config.ModelDeprecationAction == env.ModelDeprecationMigrate && deprecation.Successor != "" {
+		if s.modelServed(provider, deprecation.Successor) && !s.modelDeprecated(provider, deprecation.Successor) {
+			*model = deprecation.Successor
+			deprecation.Action = domain.ModelDeprecationMigrated
+		} else {
+			s.logger.Warn("Successor of deprecated model is not available, serving the deprecated model",
+				logger.F("model", deprecation.Model),
+				logger.F("successor", deprecation.Successor))
+		}
+	}
+
+	deprecatedModelRequests.WithLabelValues(string(tenantID), deprecation.Model, deprecation.Action).Inc()
+	s.logger.Info("Request for deprecated model",
+		logger.F("tenant_id", tenantID),
+		logger.F("model", deprecation.Model),
+		logger.F("action", deprecation.Action),
+		logger.F("request_id", requestID))
+	return deprecation
+}
+
+// modelDeprecated reports whether the registry marks the model deprecated
+// at the requested provider, or, when the request names none, at every
+// provider serving it
+func (s *Service) modelDeprecated(provider domain.Provider, modelID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if provider != "" && provider != domain.ProviderAuto {
+		model := s.providerModel(provider, modelID)
+		return model != nil && model.Status == domain.ModelStatusDeprecated
+	}
+	entries := s.modelRegistry[modelID]
+	if len(entries) == 0 {
+		return false
+	}
+	for _, model := range entries {
+		if model.Status != domain.ModelStatusDeprecated {
+			return false
+		}
+	}
+	return true
+}
+
+// modelServed reports whether the registry lists the model at the
+// requested provider, or at any provider when the request names none
+func (s *Service) modelServed(provider domain.Provider, modelID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if provider != "" && provider != domain.ProviderAuto {
+		return s.providerModel(provider, modelID) != nil
+	}
+	return len(s.modelRegistry[modelID]) > 0
+}
+
+// setModelDeprecation records the deprecation in the response metadata
+func setModelDeprecation(response *domain.CompletionResponse, deprecation *domain.ModelDeprecation) {
+	if deprecation == nil {
+		return
+	}
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]interface{})
+	}
+	response.Metadata[domain.MetadataKeyModelDeprecation] = deprecation
+}
+
+// setEmbeddingModelDeprecation records the deprecation in the embedding
+// response metadata
+func setEmbeddingModelDeprecation(response *domain.EmbeddingResponse, deprecation *domain.ModelDeprecation) {
+	if deprecation == nil {
+		return
+	}
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]interface{})
+	}
+	response.Metadata[domain.MetadataKeyModelDeprecation] = deprecation
+}
+
+// setModelDeprecationHeader sets the deprecation warning on a response
+func setModelDeprecationHeader(c *gin.Context, deprecation *domain.ModelDeprecation) {
+	if deprecation == nil {
+		return
+	}
+	c.Header(modelDeprecationHeader, deprecation.Warning())
+}
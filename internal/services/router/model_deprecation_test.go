@@ -0,0 +1,148 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+)
+
+func newDeprecationTestService(client ProviderClient) *Service {
+	s := newCacheTestService(client, nil)
+	s.modelRegistry = make(map[string]map[domain.Provider]*domain.Model)
+	s.registerModel(&domain.Model{ModelID: "gpt-35-turbo", Provider: domain.ProviderOpenAI, Status: domain.ModelStatusDeprecated})
+	s.registerModel(&domain.Model{ModelID: "gpt-4o-mini", Provider: domain.ProviderOpenAI, Status: domain.ModelStatusAvailable})
+	s.config.ModelSuccessors = map[string]string{"gpt-35-turbo": "gpt-4o-mini"}
+	return s
+}
+
+func newDeprecatedModelRequest() *domain.CompletionRequest {
+	req := newCacheTestRequest("tenant-a")
+	req.Model = "gpt-35-turbo"
+	return req
+}
+
+func TestRouteCompletion_WarnsAboutDeprecatedModel(t *testing.T) {
+	s := newDeprecationTestService(&countingProviderClient{})
+	s.config.ModelDeprecationAction = env.ModelDeprecationWarn
+
+	response, err := s.routeCompletion(context.Background(), newDeprecatedModelRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-35-turbo", response.Model)
+	deprecation, ok := domain.ModelDeprecationFrom(response.Metadata)
+	require.True(t, ok)
+	assert.Equal(t, domain.ModelDeprecation{Model: "gpt-35-turbo", Action: domain.ModelDeprecationWarned, Successor: "gpt-4o-mini"}, *deprecation)
+	assert.Equal(t, `299 - "model gpt-35-turbo is deprecated; use gpt-4o-mini"`, deprecation.Warning())
+
+	// Models still available carry no warning
+	response, err = s.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	assert.NotContains(t, response.Metadata, domain.MetadataKeyModelDeprecation)
+}
+
+func TestRouteCompletion_MigratesDeprecatedModel(t *testing.T) {
+	client := &capturingProviderClient{}
+	s := newDeprecationTestService(client)
+	s.config.ModelDeprecationAction = env.ModelDeprecationMigrate
+
+	response, err := s.routeCompletion(context.Background(), newDeprecatedModelRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4o-mini", client.last.Model)
+	deprecation, ok := domain.ModelDeprecationFrom(response.Metadata)
+	require.True(t, ok)
+	assert.Equal(t, domain.ModelDeprecationMigrated, deprecation.Action)
+	assert.Equal(t, `299 - "model gpt-35-turbo is deprecated; the request was served by gpt-4o-mini"`, deprecation.Warning())
+}
+
+func TestRouteCompletionStream_FinalEventCarriesDeprecation(t *testing.T) {
+	s := newDeprecationTestService(&scriptedStreamClient{chunks: []*domain.StreamResponse{textChunk("Hello"), {Done: true}}})
+	s.config.ModelDeprecationAction = env.ModelDeprecationWarn
+	req := newDeprecatedModelRequest()
+	req.Stream = true
+
+	w, err := routeTestStream(t, s, req)
+	require.NoError(t, err)
+	assert.Equal(t, `299 - "model gpt-35-turbo is deprecated; use gpt-4o-mini"`, w.Header().Get(modelDeprecationHeader))
+
+	// Clients that only read the events, such as the gateway collecting the
+	// stream, find the warning in the final event
+	events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	require.GreaterOrEqual(t, len(events), 2)
+	var final domain.StreamResponse
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(events[len(events)-2], "data: ")), &final))
+	require.True(t, final.Final)
+	deprecation, ok := domain.ModelDeprecationFrom(final.Metadata)
+	require.True(t, ok, "final event metadata: %v", final.Metadata)
+	assert.Equal(t, domain.ModelDeprecationWarned, deprecation.Action)
+	assert.Contains(t, final.Metadata, domain.MetadataKeyTimeToFirstToken)
+}
+
+func TestRouteEmbedding_MigratesDeprecatedModel(t *testing.T) {
+	client := &embeddingProviderClient{sizes: map[string]int{"text-embedding-3-small": 1536}}
+	s := newDeprecationTestService(client)
+	s.registerModel(&domain.Model{ModelID: "text-embedding-ada-002", Provider: domain.ProviderOpenAI, Status: domain.ModelStatusDeprecated})
+	s.registerModel(&domain.Model{ModelID: "text-embedding-3-small", Provider: domain.ProviderOpenAI, Status: domain.ModelStatusAvailable})
+	s.config.ModelSuccessors = map[string]string{"text-embedding-ada-002": "text-embedding-3-small"}
+	s.config.ModelDeprecationAction = env.ModelDeprecationMigrate
+
+	response, err := s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+		TenantID: "tenant-a",
+		Model:    "text-embedding-ada-002",
+		Input:    []string{"hello"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"text-embedding-3-small"}, client.models)
+	deprecation, ok := domain.ModelDeprecationFrom(response.Metadata)
+	require.True(t, ok)
+	assert.Equal(t, domain.ModelDeprecation{Model: "text-embedding-ada-002", Action: domain.ModelDeprecationMigrated, Successor: "text-embedding-3-small"}, *deprecation)
+
+	// With the warn action the deprecated model still serves the request
+	s.config.ModelDeprecationAction = env.ModelDeprecationWarn
+	response, err = s.routeEmbedding(context.Background(), &domain.EmbeddingRequest{
+		TenantID: "tenant-a",
+		Model:    "text-embedding-ada-002",
+		Input:    []string{"hello"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "text-embedding-ada-002", client.models[len(client.models)-1])
+	deprecation, ok = domain.ModelDeprecationFrom(response.Metadata)
+	require.True(t, ok)
+	assert.Equal(t, domain.ModelDeprecationWarned, deprecation.Action)
+}
+
+func TestApplyModelDeprecation_SuccessorMustBeServed(t *testing.T) {
+	s := newDeprecationTestService(&countingProviderClient{})
+	s.config.ModelDeprecationAction = env.ModelDeprecationMigrate
+	s.config.ModelSuccessors = map[string]string{"gpt-35-turbo": "gpt-5"}
+
+	req := newDeprecatedModelRequest()
+	deprecation := s.applyModelDeprecation(req)
+	require.NotNil(t, deprecation)
+	assert.Equal(t, domain.ModelDeprecationWarned, deprecation.Action)
+	assert.Equal(t, "gpt-35-turbo", req.Model)
+
+	// Without a successor the request is only warned about
+	s.config.ModelSuccessors = nil
+	deprecation = s.applyModelDeprecation(newDeprecatedModelRequest())
+	require.NotNil(t, deprecation)
+	assert.Equal(t, `299 - "model gpt-35-turbo is deprecated"`, deprecation.Warning())
+}
+
+func TestModelDeprecated_OnlyWhenEveryProviderDeprecatesIt(t *testing.T) {
+	s := newAutoRoutingService(env.AutoRoutingWeights{Cost: 1})
+	s.modelRegistry["gpt-4o"][domain.ProviderOpenAI].Status = domain.ModelStatusDeprecated
+
+	assert.True(t, s.modelDeprecated(domain.ProviderOpenAI, "gpt-4o"))
+	assert.False(t, s.modelDeprecated(domain.ProviderAzureOpenAI, "gpt-4o"))
+	assert.False(t, s.modelDeprecated("", "gpt-4o"), "Azure OpenAI still serves it")
+
+	s.modelRegistry["gpt-4o"][domain.ProviderAzureOpenAI].Status = domain.ModelStatusDeprecated
+	assert.True(t, s.modelDeprecated(domain.ProviderAuto, "gpt-4o"))
+	assert.False(t, s.modelDeprecated("", "unknown-model"))
+}
@@ -0,0 +1,67 @@
+package router
+
+import (
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// registryKey identifies a model in the registry
+type registryKey struct {
+	provider domain.Provider
+	modelID  string
+}
+
+// priceModel returns the model with its configured pricing, if the config
+// overrides it, remembering the provider's own pricing so a reload that
+// drops the override can restore it. The model itself is never modified.
+// Callers hold s.mu.
+func (s *Service) priceModel(model *domain.Model, overrides map[string]domain.ModelPricing) *domain.Model {
+	if s.listedPricing == nil {
+		s.listedPricing = make(map[registryKey]domain.ModelPricing)
+	}
+	s.listedPricing[registryKey{model.Provider, model.ModelID}] = model.Pricing
+
+	pricing, ok := overrides[model.ModelID]
+	if !ok {
+		return model
+	}
+	repriced := *model
+	repriced.Pricing = pricing
+	return &repriced
+}
+
+// applyModelPricing reprices the registry from the configured overrides,
+// restoring the provider's pricing to models no longer overridden. Entries
+// are replaced rather than modified, so callers holding a model keep a
+// consistent copy. Callers hold s.mu.
+func (s *Service) applyModelPricing(overrides map[string]domain.ModelPricing) {
+	for modelID, providers := range s.modelRegistry {
+		for provider, model := range providers {
+			pricing, ok := overrides[modelID]
+			if !ok {
+				pricing = s.listedPricing[registryKey{provider, modelID}]
+			}
+			if model.Pricing == pricing {
+				continue
+			}
+			repriced := *model
+			repriced.Pricing = pricing
+			providers[provider] = &repriced
+		}
+	}
+}
+
+// repriceCompletion prices a completion's usage at the configured rate for
+// its model, when the config overrides the provider's
+func (s *Service) repriceCompletion(modelID string, usage *domain.Usage) {
+	if pricing, ok := s.currentConfig().ModelPricing[modelID]; ok {
+		usage.CostUSD = pricing.CompletionCost(*usage)
+	}
+}
+
+// repriceEmbedding is repriceCompletion for embeddings, which bill input
+// tokens alone
+func (s *Service) repriceEmbedding(modelID string, usage *domain.EmbeddingUsage) {
+	if pricing, ok := s.currentConfig().ModelPricing[modelID]; ok {
+		usage.CostUSD = pricing.CompletionCost(domain.Usage{PromptTokens: usage.PromptTokens})
+	}
+}
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+)
+
+func TestModelPricing_RepricesRegistry(t *testing.T) {
+	listed := domain.ModelPricing{InputTokenCost: 0.0025, OutputTokenCost: 0.01, Unit: domain.PricingUnitThousandTokens}
+	negotiated := domain.ModelPricing{InputTokenCost: 0.000001, OutputTokenCost: 0.000004, Unit: "token"}
+
+	s := newCacheTestService(&countingProviderClient{}, nil)
+	s.modelRegistry = make(map[string]map[domain.Provider]*domain.Model)
+	s.config.ModelPricing = map[string]domain.ModelPricing{"gpt-4o": negotiated}
+
+	model := &domain.Model{ModelID: "gpt-4o", Provider: domain.ProviderOpenAI, Pricing: listed}
+	s.registerModel(model)
+	s.registerModel(&domain.Model{ModelID: "gpt-4o-mini", Provider: domain.ProviderOpenAI, Pricing: listed})
+
+	assert.Equal(t, negotiated, s.providerModel(domain.ProviderOpenAI, "gpt-4o").Pricing)
+	assert.Equal(t, listed, s.providerModel(domain.ProviderOpenAI, "gpt-4o-mini").Pricing)
+	assert.Equal(t, listed, model.Pricing, "the listed model is not modified")
+	assert.Equal(t, negotiated, s.listModels(&domain.ListModelsOptions{})[0].Pricing)
+
+	// A reload moves the override, restoring the provider's own pricing
+	result := s.reloadConfig(context.Background(), &env.Config{
+		Cache:        s.config.Cache,
+		ModelPricing: map[string]domain.ModelPricing{"gpt-4o-mini": negotiated},
+	})
+	assert.Contains(t, result.Applied, "model_pricing")
+	assert.Equal(t, listed, s.providerModel(domain.ProviderOpenAI, "gpt-4o").Pricing)
+	assert.Equal(t, negotiated, s.providerModel(domain.ProviderOpenAI, "gpt-4o-mini").Pricing)
+}
+
+func TestRouteCompletion_ChargesConfiguredPricing(t *testing.T) {
+	client := &countingProviderClient{}
+	s := newCacheTestService(client, nil)
+	pricing := domain.ModelPricing{InputTokenCost: 0.001, OutputTokenCost: 0.002, Unit: "token"}
+	s.config.ModelPricing = map[string]domain.ModelPricing{"gpt-4o": pricing}
+
+	response, err := s.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+
+	// 10 prompt and 5 completion tokens instead of the provider's $0.01
+	assert.InDelta(t, 0.02, response.Usage.CostUSD, 1e-9)
+	usage, err := s.costService.GetTenantUsage("tenant-a", "daily")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.02, usage.DailyCost, 1e-9)
+}
@@ -0,0 +1,232 @@
+package router
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// modelManifest is a versioned list of the models the router routes to. Every
+// replica loading the same manifest has the same registry, however the
+// providers' live listings differ between the times they started.
+type modelManifest struct {
+	Version string         `json:"version"`
+	Models  []domain.Model `json:"models"`
+}
+
+// loadModelManifest reads and checks a model registry manifest
+func loadModelManifest(path string) (*modelManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read model registry manifest: %w", err)
+	}
+	var manifest modelManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse model registry manifest: %w", err)
+	}
+	if manifest.Version == "" {
+		return nil, fmt.Errorf("model registry manifest %s has no version", path)
+	}
+	for i, model := range manifest.Models {
+		if model.ModelID == "" || model.Provider == "" {
+			return nil, fmt.Errorf("model registry manifest %s: model %d needs an id and a provider", path, i)
+		}
+	}
+	return &manifest, nil
+}
+
+// lists reports whether the manifest pins a provider's model
+func (m *modelManifest) lists(model domain.Model) bool {
+	for _, pinned := range m.Models {
+		if pinned.ModelID == model.ModelID && pinned.Provider == model.Provider {
+			return true
+		}
+	}
+	return false
+}
+
+// loadModelRegistry builds the model registry. A configured manifest is
+// loaded as it is, and the providers' live listings are only added when
+// there is no manifest or live augmentation is on; models the manifest pins
+// keep their pinned entries.
+func (s *Service) loadModelRegistry() error {
+	var manifest *modelManifest
+	if path := s.config.ModelRegistryManifest; path != "" {
+		var err error
+		if manifest, err = loadModelManifest(path); err != nil {
+			return err
+		}
+		for i := range manifest.Models {
+			model := &manifest.Models[i]
+			if _, ok := s.providerClients[model.Provider]; !ok {
+				s.logger.Warn("Model registry manifest lists a model of a provider that is not enabled",
+					logger.F("model", model.ModelID),
+					logger.F("provider", model.Provider))
+			}
+			s.registerModel(model)
+		}
+		s.logger.Info("Loaded model registry manifest",
+			logger.F("version", manifest.Version),
+			logger.F("count", len(manifest.Models)))
+
+		s.modelManifest = manifest
+		if !s.config.ModelRegistryLive {
+			s.registryVersion = manifest.Version
+			return nil
+		}
+	}
+
+	// Load available models from all providers
+	var live []domain.Model
+	for provider, client := range s.providerClients {
+		models, err := client.ListModels(context.Background())
+		if err != nil {
+			s.logger.Error("Failed to load models from provider",
+				logger.F("provider", provider),
+				logger.F("error", err))
+			continue
+		}
+
+		added := 0
+		for _, model := range models {
+			if manifest != nil && manifest.lists(model) {
+				continue
+			}
+			s.registerModel(&model)
+			live = append(live, model)
+			added++
+		}
+
+		s.logger.Info("Loaded models from provider",
+			logger.F("provider", provider),
+			logger.F("count", added))
+	}
+
+	s.liveModels = live
+	s.registryVersion = registryVersion(manifest, live)
+	return nil
+}
+
+// takesLiveModels reports whether the providers' live listings are added to
+// the registry, which a manifest pinning it without live augmentation stops
+func (s *Service) takesLiveModels() bool {
+	s.mu.RLock()
+	pinned := s.modelManifest != nil
+	s.mu.RUnlock()
+	return !pinned || s.currentConfig().ModelRegistryLive
+}
+
+// unpinnedModels drops the models the manifest pins from a live listing, so
+// they keep their pinned entries
+func (s *Service) unpinnedModels(models []domain.Model) []domain.Model {
+	s.mu.RLock()
+	manifest := s.modelManifest
+	s.mu.RUnlock()
+	if manifest == nil {
+		return models
+	}
+
+	var kept []domain.Model
+	for _, model := range models {
+		if !manifest.lists(model) {
+			kept = append(kept, model)
+		}
+	}
+	return kept
+}
+
+// addLiveModels registers models from a live listing and updates the
+// registry's version to match. The caller holds s.mu.
+func (s *Service) addLiveModels(models []domain.Model) {
+	if len(models) == 0 {
+		return
+	}
+	for i := range models {
+		s.registerModel(&models[i])
+	}
+	s.liveModels = append(s.liveModels, models...)
+	s.registryVersion = registryVersion(s.modelManifest, s.liveModels)
+}
+
+// currentRegistryVersion returns the version of the registry's contents
+func (s *Service) currentRegistryVersion() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.registryVersion
+}
+
+// registryVersion names the registry's contents. A pinned registry has its
+// manifest's version. Models from live listings add a digest of what was
+// listed, so replicas whose providers listed different models report
+// different versions.
+func registryVersion(manifest *modelManifest, live []domain.Model) string {
+	if manifest != nil && len(live) == 0 {
+		return manifest.Version
+	}
+
+	sorted := append([]domain.Model(nil), live...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Provider != sorted[j].Provider {
+			return sorted[i].Provider < sorted[j].Provider
+		}
+		return sorted[i].ModelID < sorted[j].ModelID
+	})
+	data, _ := json.Marshal(sorted)
+	sum := sha256.Sum256(data)
+	digest := "live." + hex.EncodeToString(sum[:6])
+
+	if manifest == nil {
+		return digest
+	}
+	return manifest.Version + "+" + digest
+}
+
+// checkModelAmbiguity rejects a bare model ID that several enabled providers
+// the tenant may use expose, unless the default provider order names one of
+// them. Which provider serves such a model would otherwise depend on load
+// balancing, and the same model ID may not be the same model everywhere.
+// Whether a model is ambiguous does not depend on provider health, so a
+// request is never rejected or accepted because of an outage.
+func (s *Service) checkModelAmbiguity(tenantID domain.TenantID, modelID, region string) error {
+	config := s.currentConfig()
+
+	s.mu.RLock()
+	var providers []domain.Provider
+	for provider := range s.modelRegistry[modelID] {
+		if providerConfig, ok := s.providerConfigs[provider]; ok && providerConfig.Enabled && config.ProviderAllowed(string(tenantID), provider) {
+			providers = append(providers, provider)
+		}
+	}
+	s.mu.RUnlock()
+	providers = s.providersInRegion(providers, region)
+
+	if len(providers) < 2 {
+		return nil
+	}
+	if _, ok := s.preferredByDefaultOrder(providers); ok {
+		return nil
+	}
+
+	names := make([]string, len(providers))
+	for i, provider := range providers {
+		names[i] = string(provider)
+	}
+	sort.Strings(names)
+	return shared_errors.NewError(shared_errors.ErrorTypeValidation,
+		fmt.Sprintf("model %s is served by several providers (%s); set provider to choose one", modelID, strings.Join(names, ", "))).
+		WithCode(shared_errors.CodeAmbiguousModel).
+		WithDetail("field", "provider").
+		WithDetail("model", modelID).
+		WithStatusCode(http.StatusBadRequest).
+		Build()
+}
@@ -0,0 +1,204 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/providers/registry"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// listingProviderClient lists a fixed set of models
+type listingProviderClient struct {
+	ProviderClient
+	models []domain.Model
+	calls  int
+}
+
+func (c *listingProviderClient) ListModels(ctx context.Context) ([]domain.Model, error) {
+	c.calls++
+	return c.models, nil
+}
+
+func newRegistryTestService(t *testing.T, manifest string, live bool, client *listingProviderClient) *Service {
+	config := &env.Config{ModelRegistryLive: live}
+	if manifest != "" {
+		config.ModelRegistryManifest = filepath.Join(t.TempDir(), "models.json")
+		require.NoError(t, os.WriteFile(config.ModelRegistryManifest, []byte(manifest), 0o600))
+	}
+	return &Service{
+		config:          config,
+		logger:          logger.NewNoop(),
+		providerClients: map[domain.Provider]ProviderClient{domain.ProviderAzureOpenAI: client},
+		modelRegistry:   make(map[string]map[domain.Provider]*domain.Model),
+	}
+}
+
+const testModelManifest = `{"version":"2026-10-01","models":[{"id":"gpt-4o","provider":"azure-openai","context_length":128000}]}`
+
+func TestLoadModelRegistry_PinnedManifest(t *testing.T) {
+	client := &listingProviderClient{models: []domain.Model{{ModelID: "gpt-4o-mini", Provider: domain.ProviderAzureOpenAI}}}
+	s := newRegistryTestService(t, testModelManifest, false, client)
+
+	require.NoError(t, s.loadModelRegistry())
+	assert.Equal(t, "2026-10-01", s.registryVersion)
+	assert.Zero(t, client.calls, "a pinned registry does not ask the providers")
+	assert.Len(t, s.modelRegistry, 1)
+	assert.Equal(t, 128000, s.modelRegistry["gpt-4o"][domain.ProviderAzureOpenAI].ContextLength)
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/internal/v1/models", nil)
+	s.handleListModels(c)
+	assert.Contains(t, w.Body.String(), `"registry_version":"2026-10-01"`)
+	assert.Equal(t, "2026-10-01", s.generateHealthResponse().RegistryVersion)
+}
+
+func TestLoadModelRegistry_LiveAugmentation(t *testing.T) {
+	client := &listingProviderClient{models: []domain.Model{
+		{ModelID: "gpt-4o", Provider: domain.ProviderAzureOpenAI, ContextLength: 8000},
+		{ModelID: "gpt-4o-mini", Provider: domain.ProviderAzureOpenAI},
+	}}
+	s := newRegistryTestService(t, testModelManifest, true, client)
+
+	require.NoError(t, s.loadModelRegistry())
+	assert.Len(t, s.modelRegistry, 2)
+	assert.Equal(t, 128000, s.modelRegistry["gpt-4o"][domain.ProviderAzureOpenAI].ContextLength, "pinned models keep their manifest entry")
+	assert.Regexp(t, `^2026-10-01\+live\.[0-9a-f]{12}$`, s.registryVersion)
+
+	// Live listings that add nothing leave the manifest's version
+	client.models = client.models[:1]
+	s = newRegistryTestService(t, testModelManifest, true, client)
+	require.NoError(t, s.loadModelRegistry())
+	assert.Equal(t, "2026-10-01", s.registryVersion)
+}
+
+func TestReloadConfig_KeepsPinnedRegistry(t *testing.T) {
+	const provider = domain.Provider("registry-reload-test")
+	enabled := &listingProviderClient{models: []domain.Model{
+		{ModelID: "gpt-4o", Provider: provider, ContextLength: 8000},
+		{ModelID: "reload-model", Provider: provider},
+	}}
+	registry.RegisterProviderFactory(provider, registry.Factory{
+		Service: func(domain.Provider, env.ProviderConfig, logger.Logger) (registry.Client, error) {
+			return enabled, nil
+		},
+	})
+	manifest := `{"version":"2026-10-01","models":[{"id":"gpt-4o","provider":"registry-reload-test","context_length":128000}]}`
+
+	reload := func(live bool) *Service {
+		s := newRegistryTestService(t, manifest, live, &listingProviderClient{})
+		s.config.Providers = map[string]env.ProviderConfig{string(provider): {Enabled: false}}
+		s.providerConfigs = map[domain.Provider]*domain.ProviderConfig{provider: {Provider: provider}}
+		require.NoError(t, s.loadModelRegistry())
+
+		next := *s.config
+		next.Providers = map[string]env.ProviderConfig{string(provider): {Enabled: true}}
+		result := s.reloadConfig(context.Background(), &next)
+		require.Empty(t, result.Errors)
+		require.True(t, s.providerEnabled(provider))
+		return s
+	}
+
+	// A pinned registry takes nothing from the newly enabled provider
+	enabled.calls = 0
+	s := reload(false)
+	assert.Zero(t, enabled.calls)
+	assert.False(t, s.providerSupportsModel(provider, "reload-model"))
+	assert.Equal(t, 128000, s.modelRegistry["gpt-4o"][provider].ContextLength)
+	assert.Equal(t, "2026-10-01", s.currentRegistryVersion())
+
+	// With live augmentation the unpinned models are added and the version
+	// follows, but the pinned entry is kept
+	s = reload(true)
+	assert.True(t, s.providerSupportsModel(provider, "reload-model"))
+	assert.Equal(t, 128000, s.modelRegistry["gpt-4o"][provider].ContextLength)
+	assert.Equal(t, registryVersion(s.modelManifest, []domain.Model{{ModelID: "reload-model", Provider: provider}}), s.currentRegistryVersion())
+}
+
+func TestRegistryVersion_IndependentOfListingOrder(t *testing.T) {
+	a := domain.Model{ModelID: "gpt-4o", Provider: domain.ProviderAzureOpenAI}
+	b := domain.Model{ModelID: "claude-3-haiku", Provider: domain.ProviderAWSBedrock}
+
+	assert.Equal(t, registryVersion(nil, []domain.Model{a, b}), registryVersion(nil, []domain.Model{b, a}))
+	assert.NotEqual(t, registryVersion(nil, []domain.Model{a, b}), registryVersion(nil, []domain.Model{a}))
+}
+
+func TestLoadModelManifest_Invalid(t *testing.T) {
+	for name, manifest := range map[string]string{
+		"no version":  `{"models":[{"id":"gpt-4o","provider":"azure-openai"}]}`,
+		"no provider": `{"version":"1","models":[{"id":"gpt-4o"}]}`,
+		"not json":    `models:`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := newRegistryTestService(t, manifest, false, &listingProviderClient{})
+			assert.Error(t, s.loadModelRegistry())
+		})
+	}
+
+	_, err := loadModelManifest(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
+
+func TestRegistry_SameModelIDFromSeveralProviders(t *testing.T) {
+	s := newAutoRoutingService(env.AutoRoutingWeights{Cost: 1})
+
+	models := s.listModels(&domain.ListModelsOptions{})
+	require.Len(t, models, 2, "neither provider's gpt-4o replaces the other's")
+	assert.Equal(t, domain.ProviderAzureOpenAI, models[0].Provider)
+	assert.Equal(t, domain.ProviderOpenAI, models[1].Provider)
+	assert.InDelta(t, 0.005, s.providerModel(domain.ProviderAzureOpenAI, "gpt-4o").Pricing.InputTokenCost, 1e-9)
+}
+
+func TestSelectProvider_AmbiguousModelID(t *testing.T) {
+	s := newAutoRoutingService(env.AutoRoutingWeights{Cost: 1})
+
+	_, err := s.selectProvider("tenant-a", "gpt-4o", "", "")
+	require.Error(t, err)
+	qlensErr := shared_errors.FromError(err)
+	assert.Equal(t, "ambiguous_model", qlensErr.Code)
+	assert.Equal(t, http.StatusBadRequest, qlensErr.HTTPStatusCode())
+	assert.Contains(t, err.Error(), "azure-openai, openai")
+	// The error taxonomy lists the code with the status it is returned with
+	info, ok := shared_errors.DescribeErrorCode(qlensErr.Type, qlensErr.Code)
+	require.True(t, ok)
+	assert.Equal(t, qlensErr.HTTPStatusCode(), info.StatusCode)
+
+	// An explicit provider resolves it
+	provider, err := s.selectProvider("tenant-a", "gpt-4o", domain.ProviderAzureOpenAI, "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderAzureOpenAI, provider)
+
+	// So does a default order naming one of the providers
+	s.config.DefaultProviderOrder = []domain.Provider{domain.ProviderAWSBedrock, domain.ProviderOpenAI}
+	provider, err = s.selectProvider("tenant-a", "gpt-4o", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderOpenAI, provider)
+}
+
+func TestSelectProvider_AmbiguityIgnoresProviderHealth(t *testing.T) {
+	s := newAutoRoutingService(env.AutoRoutingWeights{Cost: 1})
+	s.providerConfigs[domain.ProviderOpenAI].HealthStatus = domain.ProviderHealthUnhealthy
+
+	_, err := s.selectProvider("tenant-a", "gpt-4o", "", "")
+	require.Error(t, err)
+	assert.Equal(t, "ambiguous_model", shared_errors.FromError(err).Code)
+
+	// A disabled provider does not serve the model at all
+	s.providerConfigs[domain.ProviderOpenAI].Enabled = false
+	provider, err := s.selectProvider("tenant-a", "gpt-4o", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, domain.ProviderAzureOpenAI, provider)
+}
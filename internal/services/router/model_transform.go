@@ -0,0 +1,104 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// modelTransformHeader carries a streamed request's transform warnings,
+// which a stream has no metadata for
+const modelTransformHeader = "X-Model-Transform-Warnings"
+
+// applyModelTransform rewrites a request for a model whose configured
+// transform drops parameters it rejects or sends system messages in
+// another role, so clients need no model-specific logic. It runs with the
+// other request adjustments, before the cache key is computed, and returns
+// a warning for each change it made. The token limit needs no rule:
+// provider clients send it in the field the model expects.
+func (s *Service) applyModelTransform(req *domain.CompletionRequest) []string {
+	transform, ok := s.currentConfig().ModelTransformFor(req.Model)
+	if !ok {
+		return nil
+	}
+
+	var warnings []string
+	for _, param := range transform.Drop {
+		if dropRequestParam(req, param) {
+			warnings = append(warnings, fmt.Sprintf("%s is not supported by model %s and was dropped", param, req.Model))
+		}
+	}
+
+	if transform.SystemRole != "" {
+		converted := false
+		for i := range req.Messages {
+			if req.Messages[i].Role == domain.MessageRoleSystem {
+				req.Messages[i].Role = transform.SystemRole
+				converted = true
+			}
+		}
+		if converted {
+			warnings = append(warnings, fmt.Sprintf("system messages were sent to model %s as %s messages", req.Model, transform.SystemRole))
+		}
+	}
+
+	if len(warnings) > 0 {
+		modelTransformsApplied.WithLabelValues(req.Model).Inc()
+		s.logger.Debug("Transformed request for model",
+			logger.F("model", req.Model),
+			logger.F("request_id", req.RequestID),
+			logger.F("warnings", warnings))
+	}
+	return warnings
+}
+
+// dropRequestParam clears a request parameter, reporting whether it was set
+func dropRequestParam(req *domain.CompletionRequest, param string) bool {
+	switch param {
+	case "temperature":
+		set := req.Temperature != nil
+		req.Temperature = nil
+		return set
+	case "top_p":
+		set := req.TopP != nil
+		req.TopP = nil
+		return set
+	case "presence_penalty":
+		set := req.PresencePenalty != nil
+		req.PresencePenalty = nil
+		return set
+	case "frequency_penalty":
+		set := req.FrequencyPenalty != nil
+		req.FrequencyPenalty = nil
+		return set
+	case "stop":
+		set := len(req.Stop) > 0
+		req.Stop = nil
+		return set
+	}
+	return false
+}
+
+// setTransformWarnings records the transform warnings in the response
+// metadata
+func setTransformWarnings(response *domain.CompletionResponse, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]interface{})
+	}
+	response.Metadata[domain.MetadataKeyTransformWarnings] = warnings
+}
+
+// setTransformWarningsHeader sets the transform warnings on a streamed
+// response
+func setTransformWarningsHeader(c *gin.Context, warnings []string) {
+	if len(warnings) == 0 {
+		return
+	}
+	c.Header(modelTransformHeader, strings.Join(warnings, "; "))
+}
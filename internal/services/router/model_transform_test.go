@@ -0,0 +1,68 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+)
+
+func newReasoningTestService(client ProviderClient) *Service {
+	s := newCacheTestService(client, nil)
+	s.config.ModelTransforms = map[string]env.ModelTransform{
+		"o1": {Drop: []string{"temperature", "top_p", "stop"}, SystemRole: domain.MessageRoleDeveloper},
+	}
+	return s
+}
+
+func newReasoningTestRequest(model string) *domain.CompletionRequest {
+	req := newCacheTestRequest("tenant-a")
+	req.CacheEnabled = false
+	req.Model = model
+	req.Messages = append([]domain.Message{
+		{Role: domain.MessageRoleSystem, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "Be brief."}}},
+	}, req.Messages...)
+	return req
+}
+
+func TestRouteCompletion_TransformsRequestsForModel(t *testing.T) {
+	client := &capturingProviderClient{}
+	s := newReasoningTestService(client)
+
+	response, err := s.routeCompletion(context.Background(), newReasoningTestRequest("o1-mini"))
+	require.NoError(t, err)
+	assert.Nil(t, client.last.Temperature)
+	assert.Equal(t, domain.MessageRoleDeveloper, client.last.Messages[0].Role)
+	assert.Equal(t, domain.MessageRoleUser, client.last.Messages[1].Role)
+	assert.NotNil(t, client.last.MaxTokens, "the token limit is the provider client's to map")
+	assert.Equal(t, []string{
+		"temperature is not supported by model o1-mini and was dropped",
+		"system messages were sent to model o1-mini as developer messages",
+	}, response.Metadata[domain.MetadataKeyTransformWarnings])
+}
+
+func TestRouteCompletion_OtherModelsUntransformed(t *testing.T) {
+	client := &capturingProviderClient{}
+	s := newReasoningTestService(client)
+
+	response, err := s.routeCompletion(context.Background(), newReasoningTestRequest("gpt-4o"))
+	require.NoError(t, err)
+	assert.NotNil(t, client.last.Temperature)
+	assert.Equal(t, domain.MessageRoleSystem, client.last.Messages[0].Role)
+	assert.NotContains(t, response.Metadata, domain.MetadataKeyTransformWarnings)
+}
+
+func TestApplyModelTransform_OnlyWarnsAboutParametersSent(t *testing.T) {
+	s := newReasoningTestService(&countingProviderClient{})
+	req := newReasoningTestRequest("O1-preview")
+	req.Temperature = nil
+	req.Messages = req.Messages[1:]
+	req.Stop = []string{"\n"}
+
+	assert.Equal(t, []string{"stop is not supported by model O1-preview and was dropped"}, s.applyModelTransform(req))
+	assert.Nil(t, req.Stop)
+}
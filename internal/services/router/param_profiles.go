@@ -0,0 +1,42 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// applyParamProfile fills the sampling parameters the request leaves unset
+// from its named profile. It runs before the cache key is computed so cached
+// responses are keyed by the parameters actually sent.
+func (s *Service) applyParamProfile(req *domain.CompletionRequest) error {
+	if req.ParamProfile == "" {
+		return nil
+	}
+
+	profile, ok := s.currentConfig().ParamProfiles[req.ParamProfile]
+	if !ok {
+		return shared_errors.ValidationError(fmt.Sprintf("unknown parameter profile %q", req.ParamProfile), "param_profile")
+	}
+
+	req.Temperature = defaultFloat(req.Temperature, profile.Temperature)
+	req.TopP = defaultFloat(req.TopP, profile.TopP)
+	req.PresencePenalty = defaultFloat(req.PresencePenalty, profile.PresencePenalty)
+	req.FrequencyPenalty = defaultFloat(req.FrequencyPenalty, profile.FrequencyPenalty)
+	if req.MaxTokens == nil && profile.MaxTokens != nil {
+		maxTokens := *profile.MaxTokens
+		req.MaxTokens = &maxTokens
+	}
+	return nil
+}
+
+// defaultFloat returns the request's value if set, otherwise a copy of the
+// profile's, so requests never share the config's pointers
+func defaultFloat(value, profile *float64) *float64 {
+	if value != nil || profile == nil {
+		return value
+	}
+	copied := *profile
+	return &copied
+}
@@ -0,0 +1,46 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+func TestApplyParamProfile_RequestFieldsOverrideProfile(t *testing.T) {
+	s := newCacheTestService(&countingProviderClient{}, nil)
+	temperature, profileTopP, requestTopP := 1.0, 0.95, 0.5
+	s.config.ParamProfiles = map[string]env.ParamProfile{
+		"creative": {Temperature: &temperature, TopP: &profileTopP},
+	}
+
+	req := newCacheTestRequest("tenant-a")
+	req.ParamProfile = "creative"
+	req.Temperature = nil
+	req.TopP = &requestTopP
+	require.NoError(t, s.applyParamProfile(req))
+
+	assert.Equal(t, 1.0, *req.Temperature)
+	assert.Equal(t, 0.5, *req.TopP)
+	assert.Nil(t, req.PresencePenalty)
+
+	// The request holds its own copy of the profile's values
+	*req.Temperature = 0.2
+	assert.Equal(t, 1.0, *s.config.ParamProfiles["creative"].Temperature)
+}
+
+func TestRouteCompletion_UnknownParamProfile(t *testing.T) {
+	client := &countingProviderClient{}
+	s := newCacheTestService(client, nil)
+	req := newCacheTestRequest("tenant-a")
+	req.ParamProfile = "imaginative"
+
+	_, err := s.routeCompletion(context.Background(), req)
+	require.Error(t, err)
+	assert.True(t, shared_errors.IsType(err, shared_errors.ErrorTypeValidation))
+	assert.Zero(t, client.calls)
+}
@@ -0,0 +1,185 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+const (
+	// responseSizeBaselineAlpha weights each response in a model's long-run
+	// average response size
+	responseSizeBaselineAlpha = 0.01
+
+	// responseSizeRecentAlpha weights each response in the recent average,
+	// which is compared against the baseline
+	responseSizeRecentAlpha = 0.2
+
+	// responseSizeMinSamples is how many responses a model needs before its
+	// baseline is trusted
+	responseSizeMinSamples = 100
+
+	// responseSizeAlertCooldown is the least time between alerts for a model
+	responseSizeAlertCooldown = 15 * time.Minute
+
+	// responseSizeAlertName is the alertname of response size alerts
+	responseSizeAlertName = "QLensResponseSizeDeviation"
+)
+
+// ResponseSizeMonitor tracks the average response size of each provider and
+// model, and reports when the recent average strays far from the long-run
+// one. A sudden jump can mean prompt injection making a model dump content;
+// a collapse often means a misconfigured deployment or truncated output.
+type ResponseSizeMonitor struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	models map[string]*responseSizeStats
+}
+
+type responseSizeStats struct {
+	samples   int
+	baseline  float64
+	recent    float64
+	lastAlert time.Time
+}
+
+// ResponseSizeDeviation describes a response size average that moved sharply
+type ResponseSizeDeviation struct {
+	Provider domain.Provider
+	Model    string
+	Baseline float64
+	Recent   float64
+}
+
+// NewResponseSizeMonitor creates an empty monitor
+func NewResponseSizeMonitor() *ResponseSizeMonitor {
+	return &ResponseSizeMonitor{now: time.Now, models: make(map[string]*responseSizeStats)}
+}
+
+// Observe records a response size and returns a deviation when the recent
+// average is at least factor times above or below the baseline. Deviations
+// for a model are reported at most once per cooldown. A factor of 1 or less
+// disables reporting.
+func (m *ResponseSizeMonitor) Observe(provider domain.Provider, model string, bytes int, factor float64) *ResponseSizeDeviation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := string(provider) + "/" + model
+	stats, exists := m.models[key]
+	if !exists {
+		stats = &responseSizeStats{baseline: float64(bytes), recent: float64(bytes)}
+		m.models[key] = stats
+	}
+	stats.samples++
+	stats.recent += responseSizeRecentAlpha * (float64(bytes) - stats.recent)
+
+	var deviation *ResponseSizeDeviation
+	if factor > 1 && stats.samples >= responseSizeMinSamples && stats.baseline > 0 {
+		ratio := stats.recent / stats.baseline
+		now := m.now()
+		if (ratio >= factor || ratio <= 1/factor) && now.Sub(stats.lastAlert) >= responseSizeAlertCooldown {
+			stats.lastAlert = now
+			deviation = &ResponseSizeDeviation{Provider: provider, Model: model, Baseline: stats.baseline, Recent: stats.recent}
+		}
+	}
+
+	// The baseline follows only after the comparison, so a sustained change
+	// is reported before it becomes the new normal
+	stats.baseline += responseSizeBaselineAlpha * (float64(bytes) - stats.baseline)
+	return deviation
+}
+
+// recordPayloadSizes records the content size and tokens of a completion
+// against its provider and model, and raises an alert when the model's
+// response sizes change sharply
+func (s *Service) recordPayloadSizes(ctx context.Context, req *domain.CompletionRequest, provider domain.Provider, responseBytes int, usage domain.Usage) {
+	labels := []string{string(provider), req.Model}
+	payloadRequestBytes.WithLabelValues(labels...).Observe(float64(requestContentBytes(req)))
+	payloadResponseBytes.WithLabelValues(labels...).Observe(float64(responseBytes))
+	payloadPromptTokens.WithLabelValues(labels...).Observe(float64(usage.PromptTokens))
+	payloadCompletionTokens.WithLabelValues(labels...).Observe(float64(usage.CompletionTokens))
+
+	if s.responseSizes == nil {
+		return
+	}
+	deviation := s.responseSizes.Observe(provider, req.Model, responseBytes, s.currentConfig().ResponseSizeAlertFactor)
+	if deviation == nil {
+		return
+	}
+
+	responseSizeDeviations.WithLabelValues(labels...).Inc()
+	s.logger.Warn("Response size deviates from the model's baseline",
+		logger.F("provider", provider),
+		logger.F("model", req.Model),
+		logger.F("baseline_bytes", int(deviation.Baseline)),
+		logger.F("recent_bytes", int(deviation.Recent)))
+
+	if s.alertManager != nil {
+		go s.sendResponseSizeAlert(context.WithoutCancel(ctx), deviation)
+	}
+}
+
+// sendResponseSizeAlert raises a response size deviation with Alertmanager
+func (s *Service) sendResponseSizeAlert(ctx context.Context, deviation *ResponseSizeDeviation) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	alert := Alert{
+		Labels: map[string]string{
+			"alertname": responseSizeAlertName,
+			"severity":  "warning",
+			"service":   "qlens-router",
+			"provider":  string(deviation.Provider),
+			"model":     deviation.Model,
+		},
+		Annotations: map[string]string{
+			"summary": fmt.Sprintf("Average response size of %s on %s changed sharply", deviation.Model, deviation.Provider),
+			"description": fmt.Sprintf("Recent responses average %.0f bytes against a baseline of %.0f bytes. Check for prompt injection or a misconfigured model.",
+				deviation.Recent, deviation.Baseline),
+		},
+		StartsAt: time.Now(),
+	}
+	if err := s.alertManager.Send(ctx, alert); err != nil {
+		s.logger.Warn("Failed to send response size alert",
+			logger.F("provider", deviation.Provider),
+			logger.F("model", deviation.Model),
+			logger.F("error", err))
+	}
+}
+
+// requestContentBytes is the size of a request's message content: text,
+// image URLs and tool call arguments
+func requestContentBytes(req *domain.CompletionRequest) int {
+	size := 0
+	for _, msg := range req.Messages {
+		size += messageContentBytes(msg)
+	}
+	return size
+}
+
+// responseContentBytes is the size of a response's message content
+func responseContentBytes(response *domain.CompletionResponse) int {
+	size := 0
+	for _, choice := range response.Choices {
+		size += messageContentBytes(choice.Message)
+	}
+	return size
+}
+
+func messageContentBytes(msg domain.Message) int {
+	size := 0
+	for _, part := range msg.Content {
+		size += len(part.Text)
+		if part.ImageURL != nil {
+			size += len(part.ImageURL.URL)
+		}
+	}
+	for _, call := range msg.ToolCalls {
+		size += len(call.Function.Name) + len(call.Function.Arguments)
+	}
+	return size
+}
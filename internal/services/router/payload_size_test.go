@@ -0,0 +1,83 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// recordingAlertManager hands sent alerts to a channel
+type recordingAlertManager struct {
+	alerts chan Alert
+}
+
+func (m *recordingAlertManager) Send(ctx context.Context, alerts ...Alert) error {
+	for _, alert := range alerts {
+		m.alerts <- alert
+	}
+	return nil
+}
+
+func TestResponseSizeMonitor_ReportsSharpChanges(t *testing.T) {
+	monitor := NewResponseSizeMonitor()
+	now := time.Unix(1_700_000_000, 0)
+	monitor.now = func() time.Time { return now }
+
+	for i := 0; i < responseSizeMinSamples; i++ {
+		require.Nil(t, monitor.Observe(domain.ProviderOpenAI, "gpt-4o", 1000, 4))
+	}
+
+	// A few outsized responses are smoothed over
+	assert.Nil(t, monitor.Observe(domain.ProviderOpenAI, "gpt-4o", 5000, 4))
+
+	var deviation *ResponseSizeDeviation
+	for i := 0; i < 20 && deviation == nil; i++ {
+		deviation = monitor.Observe(domain.ProviderOpenAI, "gpt-4o", 50000, 4)
+	}
+	require.NotNil(t, deviation)
+	assert.Equal(t, "gpt-4o", deviation.Model)
+	assert.Greater(t, deviation.Recent, 4*deviation.Baseline)
+
+	// Reported once per cooldown
+	assert.Nil(t, monitor.Observe(domain.ProviderOpenAI, "gpt-4o", 50000, 4))
+	now = now.Add(responseSizeAlertCooldown)
+	assert.NotNil(t, monitor.Observe(domain.ProviderOpenAI, "gpt-4o", 50000, 4))
+
+	// Other models keep their own baseline, and a factor of 0 disables alerts
+	for i := 0; i < 2*responseSizeMinSamples; i++ {
+		assert.Nil(t, monitor.Observe(domain.ProviderOpenAI, "gpt-4o-mini", 10+i*1000, 0))
+	}
+}
+
+func TestRecordPayloadSizes_SendsAlert(t *testing.T) {
+	s := newCacheTestService(&countingProviderClient{}, nil)
+	s.config.ResponseSizeAlertFactor = 4
+	s.responseSizes = NewResponseSizeMonitor()
+	alerts := &recordingAlertManager{alerts: make(chan Alert, 1)}
+	s.alertManager = alerts
+
+	req := newCacheTestRequest("tenant-a")
+	assert.Equal(t, 5, requestContentBytes(req))
+
+	usage := domain.Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}
+	for i := 0; i < responseSizeMinSamples; i++ {
+		s.recordPayloadSizes(context.Background(), req, domain.ProviderOpenAI, 2000, usage)
+	}
+	for i := 0; i < 20; i++ {
+		s.recordPayloadSizes(context.Background(), req, domain.ProviderOpenAI, 10, usage)
+	}
+
+	select {
+	case alert := <-alerts.alerts:
+		assert.Equal(t, responseSizeAlertName, alert.Labels["alertname"])
+		assert.Equal(t, "openai", alert.Labels["provider"])
+		assert.Equal(t, "gpt-4o", alert.Labels["model"])
+	case <-time.After(time.Second):
+		t.Fatal("no alert sent")
+	}
+}
@@ -0,0 +1,31 @@
+package router
+
+import (
+	"fmt"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// nativePrefillProviders continue a trailing assistant message as a partial
+// turn. Other providers only accept a prefill when emulation is enabled.
+var nativePrefillProviders = map[domain.Provider]bool{
+	domain.ProviderAWSBedrock: true,
+	domain.ProviderAnthropic:  true,
+}
+
+// validatePrefill rejects a prefilled request for a provider that can
+// neither continue the prefill nor have it emulated
+func (s *Service) validatePrefill(req *domain.CompletionRequest, provider domain.Provider) error {
+	if _, ok := req.Prefill(); !ok {
+		return nil
+	}
+	if nativePrefillProviders[provider] || s.currentConfig().PrefillEmulation {
+		return nil
+	}
+
+	validationErr := shared_errors.ValidationError(
+		fmt.Sprintf("provider %s does not support assistant prefill", provider), "messages")
+	validationErr.Details["provider"] = string(provider)
+	return validationErr
+}
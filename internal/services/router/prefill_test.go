@@ -0,0 +1,31 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+func TestValidatePrefill(t *testing.T) {
+	prefilled := newCacheTestRequest("tenant-a")
+	prefilled.Messages = append(prefilled.Messages, domain.Message{
+		Role:    domain.MessageRoleAssistant,
+		Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "Hi"}},
+	})
+
+	service := &Service{config: &env.Config{}}
+	assert.NoError(t, service.validatePrefill(prefilled, domain.ProviderAWSBedrock))
+	assert.NoError(t, service.validatePrefill(newCacheTestRequest("tenant-a"), domain.ProviderAzureOpenAI))
+
+	err := service.validatePrefill(prefilled, domain.ProviderAzureOpenAI)
+	require.Error(t, err)
+	assert.True(t, shared_errors.IsType(err, shared_errors.ErrorTypeValidation))
+
+	service.config.PrefillEmulation = true
+	assert.NoError(t, service.validatePrefill(prefilled, domain.ProviderAzureOpenAI))
+}
@@ -0,0 +1,102 @@
+package router
+
+import (
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// updateProviderHealth recomputes the provider's health status from the
+// outcomes of real requests over the passive health window and its most
+// recent active check. It runs after every recorded call and every active
+// check, so routing reacts to failing traffic without waiting for the next
+// probe. Updates are serialized by healthMu, and the router's lock is only
+// taken for writing when the status changes, or is refreshed while the
+// provider is unhealthy so its retry is timed from the latest failure; the
+// health endpoint reads latency and error rate from providerStats.
+func (s *Service) updateProviderHealth(provider domain.Provider) {
+	config := s.currentConfig().PassiveHealth
+
+	errorRate, calls := s.providerStats.ErrorRate(provider), 0
+	if config.Window > 0 {
+		errorRate, calls = s.providerStats.WindowErrorRate(provider, config.Window)
+	}
+	latency, _ := s.providerStats.Latency(provider)
+
+	status := passiveHealthStatus(config, errorRate, calls)
+	if s.healthChecker != nil {
+		if healthy, probed := s.healthChecker.Probed(provider); probed && !healthy {
+			status = combineFailedProbe(status, config, calls)
+		}
+	}
+
+	s.healthMu.Lock()
+	defer s.healthMu.Unlock()
+
+	s.mu.RLock()
+	providerConfig, exists := s.providerConfigs[provider]
+	var previous domain.ProviderHealthStatus
+	if exists {
+		previous = providerConfig.HealthStatus
+	}
+	s.mu.RUnlock()
+	if !exists || (previous == status && status != domain.ProviderHealthUnhealthy) {
+		return
+	}
+
+	s.mu.Lock()
+	if providerConfig, exists := s.providerConfigs[provider]; exists {
+		providerConfig.UpdateHealth(status, latency, errorRate)
+	}
+	s.mu.Unlock()
+
+	if previous != status {
+		s.logger.Warn("Provider health changed",
+			logger.F("provider", provider),
+			logger.F("from", previous),
+			logger.F("to", status),
+			logger.F("error_rate", errorRate),
+			logger.F("calls", calls),
+		)
+	}
+}
+
+// passiveHealthStatus grades a provider by its error rate over the window.
+// Too few calls to judge leave it healthy.
+func passiveHealthStatus(config env.PassiveHealthConfig, errorRate float64, calls int) domain.ProviderHealthStatus {
+	switch {
+	case config.Window <= 0 || calls < config.MinRequests:
+		return domain.ProviderHealthHealthy
+	case errorRate >= config.UnhealthyErrorRate:
+		return domain.ProviderHealthUnhealthy
+	case errorRate >= config.DegradedErrorRate:
+		return domain.ProviderHealthDegraded
+	}
+	return domain.ProviderHealthHealthy
+}
+
+// combineFailedProbe folds a failed active check into the passive status. A
+// failed probe makes the provider unhealthy, unless enough real requests in
+// the window succeeded to show it is serving, when it is only degraded.
+func combineFailedProbe(passive domain.ProviderHealthStatus, config env.PassiveHealthConfig, calls int) domain.ProviderHealthStatus {
+	if passive == domain.ProviderHealthHealthy && config.Window > 0 && calls >= config.MinRequests {
+		return domain.ProviderHealthDegraded
+	}
+	return domain.ProviderHealthUnhealthy
+}
+
+// providerRoutable reports whether requests may be sent to the provider.
+// Degraded providers still serve. An unhealthy provider is tried again once
+// its status is a passive health window old, since without traffic its
+// failures would otherwise never age out.
+func providerRoutable(config *domain.ProviderConfig, window time.Duration, now time.Time) bool {
+	if !config.Enabled {
+		return false
+	}
+	if config.HealthStatus != domain.ProviderHealthUnhealthy {
+		return true
+	}
+	return window > 0 && now.Sub(config.LastHealthCheck) >= window
+}
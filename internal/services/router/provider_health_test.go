@@ -0,0 +1,136 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func newPassiveHealthService() *Service {
+	s := newCacheTestService(&countingProviderClient{}, nil)
+	s.config.PassiveHealth = env.PassiveHealthConfig{
+		Window:             time.Minute,
+		MinRequests:        4,
+		DegradedErrorRate:  0.2,
+		UnhealthyErrorRate: 0.5,
+	}
+	s.providerConfigs[domain.ProviderOpenAI].HealthStatus = domain.ProviderHealthHealthy
+	s.modelRegistry = make(map[string]map[domain.Provider]*domain.Model)
+	s.registerModel(&domain.Model{ModelID: "gpt-4o", Provider: domain.ProviderOpenAI})
+	return s
+}
+
+func TestProviderStats_WindowErrorRate(t *testing.T) {
+	stats := NewProviderStats()
+	now := time.Unix(1_700_000_000, 0)
+	stats.now = func() time.Time { return now }
+
+	stats.Record(domain.ProviderOpenAI, time.Millisecond, true)
+	stats.Record(domain.ProviderOpenAI, time.Millisecond, true)
+	now = now.Add(30 * time.Second)
+	stats.Record(domain.ProviderOpenAI, time.Millisecond, false)
+	stats.Record(domain.ProviderOpenAI, time.Millisecond, false)
+
+	rate, calls := stats.WindowErrorRate(domain.ProviderOpenAI, time.Minute)
+	assert.Equal(t, 4, calls)
+	assert.InDelta(t, 0.5, rate, 1e-9)
+
+	// The failures slide out of the window
+	now = now.Add(45 * time.Second)
+	rate, calls = stats.WindowErrorRate(domain.ProviderOpenAI, time.Minute)
+	assert.Equal(t, 2, calls)
+	assert.Zero(t, rate)
+
+	_, calls = stats.WindowErrorRate(domain.ProviderAnthropic, time.Minute)
+	assert.Zero(t, calls)
+}
+
+func TestUpdateProviderHealth_ReactsToFailingTraffic(t *testing.T) {
+	s := newPassiveHealthService()
+	ctx := context.Background()
+	failure := shared_errors.ProviderUnavailableError("openai")
+
+	// Too few calls to judge
+	for i := 0; i < 3; i++ {
+		s.recordProviderStats(ctx, domain.ProviderOpenAI, time.Millisecond, failure)
+	}
+	assert.Equal(t, domain.ProviderHealthHealthy, s.providerConfigs[domain.ProviderOpenAI].HealthStatus)
+	assert.Equal(t, []domain.Provider{domain.ProviderOpenAI}, s.eligibleProviders("gpt-4o"))
+
+	s.recordProviderStats(ctx, domain.ProviderOpenAI, 20*time.Millisecond, nil)
+	config := s.providerConfigs[domain.ProviderOpenAI]
+	assert.Equal(t, domain.ProviderHealthUnhealthy, config.HealthStatus)
+	assert.InDelta(t, 0.75, config.ErrorRate, 1e-9)
+	assert.InDelta(t, 20, config.Latency, 1e-9)
+	assert.Empty(t, s.eligibleProviders("gpt-4o"))
+
+	for i := 0; i < 8; i++ {
+		s.recordProviderStats(ctx, domain.ProviderOpenAI, 20*time.Millisecond, nil)
+	}
+	assert.Equal(t, domain.ProviderHealthDegraded, s.providerConfigs[domain.ProviderOpenAI].HealthStatus)
+}
+
+func TestUpdateProviderHealth_CombinesFailedProbe(t *testing.T) {
+	s := newPassiveHealthService()
+	s.healthChecker = NewHealthChecker(map[domain.Provider]ProviderClient{
+		domain.ProviderOpenAI: &warmableProviderClient{healthErr: errors.New("connection refused")},
+	}, time.Minute, 0, 0, 0, logger.NewNoop())
+	s.healthChecker.OnResult(s.updateProviderHealth)
+
+	s.healthChecker.checkAllProviders()
+	s.healthChecker.wg.Wait()
+	assert.Equal(t, domain.ProviderHealthUnhealthy, s.providerConfigs[domain.ProviderOpenAI].HealthStatus)
+
+	// Real traffic succeeding outweighs the probe, but not entirely
+	for i := 0; i < 4; i++ {
+		s.recordProviderStats(context.Background(), domain.ProviderOpenAI, time.Millisecond, nil)
+	}
+	assert.Equal(t, domain.ProviderHealthDegraded, s.providerConfigs[domain.ProviderOpenAI].HealthStatus)
+}
+
+func TestUpdateProviderHealth_CountsStreamOutcomes(t *testing.T) {
+	s := newPassiveHealthService()
+	// The circuit breaker opens after two failures
+	s.config.PassiveHealth.MinRequests = 2
+	failure := shared_errors.ProviderUnavailableError("openai")
+	client := &scriptedStreamClient{chunks: []*domain.StreamResponse{textChunk("Hel"), {Error: failure}}}
+	s.providerClients[domain.ProviderOpenAI] = client
+	req := newCacheTestRequest("tenant-a")
+	req.Stream = true
+
+	for i := 0; i < 2; i++ {
+		_, err := routeTestStream(t, s, req)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, domain.ProviderHealthUnhealthy, s.providerConfigs[domain.ProviderOpenAI].HealthStatus)
+	rate, calls := s.providerStats.WindowErrorRate(domain.ProviderOpenAI, time.Minute)
+	assert.Equal(t, 2, calls)
+	assert.InDelta(t, 1, rate, 1e-9)
+	// A stream's duration is not a latency sample
+	_, measured := s.providerStats.Latency(domain.ProviderOpenAI)
+	assert.False(t, measured)
+}
+
+func TestProviderRoutable(t *testing.T) {
+	now := time.Now()
+	config := &domain.ProviderConfig{Enabled: true, HealthStatus: domain.ProviderHealthDegraded, LastHealthCheck: now}
+	assert.True(t, providerRoutable(config, time.Minute, now))
+
+	config.HealthStatus = domain.ProviderHealthUnhealthy
+	assert.False(t, providerRoutable(config, time.Minute, now))
+	// Retried once its failures are a window old
+	assert.True(t, providerRoutable(config, time.Minute, now.Add(time.Minute)))
+	assert.False(t, providerRoutable(config, 0, now.Add(time.Minute)))
+
+	config.Enabled = false
+	config.HealthStatus = domain.ProviderHealthHealthy
+	assert.False(t, providerRoutable(config, time.Minute, now))
+}
@@ -0,0 +1,65 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// providerProbeTimeout bounds each call an on-demand probe makes
+const providerProbeTimeout = 30 * time.Second
+
+// probeProvider runs the provider's health check and lists its models
+// synchronously. The result is reported as is; routing state is left to the
+// periodic checker and real traffic.
+func (s *Service) probeProvider(ctx context.Context, provider domain.Provider, client ProviderClient) *domain.ProviderProbe {
+	probe := &domain.ProviderProbe{Provider: provider, CheckedAt: time.Now().UTC()}
+	s.mu.RLock()
+	if config, ok := s.providerConfigs[provider]; ok {
+		probe.HealthStatus = config.HealthStatus
+	}
+	s.mu.RUnlock()
+
+	checkCtx, cancel := context.WithTimeout(ctx, providerProbeTimeout)
+	start := time.Now()
+	err := client.HealthCheck(checkCtx)
+	cancel()
+	probe.LatencyMs = time.Since(start).Milliseconds()
+
+	probe.Healthy = err == nil
+	probe.StatusCode = http.StatusOK
+	if err != nil {
+		qlensErr := shared_errors.FromError(err)
+		probe.StatusCode = qlensErr.HTTPStatusCode()
+		probe.ErrorType = string(qlensErr.Type)
+		probe.Error = err.Error()
+	}
+
+	listCtx, cancel := context.WithTimeout(ctx, providerProbeTimeout)
+	models, err := client.ListModels(listCtx)
+	cancel()
+	if err != nil {
+		probe.ModelsError = err.Error()
+	}
+	for _, model := range models {
+		probe.Models = append(probe.Models, model.ModelID)
+	}
+	sort.Strings(probe.Models)
+	return probe
+}
+
+// handleProbeProvider actively probes one provider for diagnostics
+func (s *Service) handleProbeProvider(c *gin.Context) {
+	provider := domain.Provider(c.Param("provider"))
+	client, exists := s.providerClient(provider)
+	if !exists {
+		s.respondWithError(c, shared_errors.NotFoundError("provider", string(provider)))
+		return
+	}
+	c.JSON(http.StatusOK, s.probeProvider(c.Request.Context(), provider, client))
+}
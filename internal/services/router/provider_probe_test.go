@@ -0,0 +1,67 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// probedProviderClient fails its health check and lists one model
+type probedProviderClient struct {
+	countingProviderClient
+	healthErr error
+}
+
+func (c *probedProviderClient) HealthCheck(ctx context.Context) error {
+	return c.healthErr
+}
+
+func (c *probedProviderClient) ListModels(ctx context.Context) ([]domain.Model, error) {
+	return []domain.Model{{ModelID: "gpt-4o", Provider: domain.ProviderOpenAI}}, nil
+}
+
+func TestHandleProbeProvider(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	client := &probedProviderClient{healthErr: shared_errors.ProviderError("openai", "invalid api key", errors.New("401 Unauthorized"))}
+	s := newCacheTestService(client, nil)
+	s.providerConfigs[domain.ProviderOpenAI].HealthStatus = domain.ProviderHealthDegraded
+	router := gin.New()
+	router.GET("/internal/v1/providers/:provider/probe", s.handleProbeProvider)
+
+	get := func(provider string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/internal/v1/providers/"+provider+"/probe", nil))
+		return w
+	}
+
+	w := get("openai")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var probe domain.ProviderProbe
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &probe))
+	assert.False(t, probe.Healthy)
+	assert.Equal(t, domain.ProviderHealthDegraded, probe.HealthStatus)
+	assert.Equal(t, string(shared_errors.ErrorTypeProviderError), probe.ErrorType)
+	assert.Equal(t, shared_errors.FromError(client.healthErr).HTTPStatusCode(), probe.StatusCode)
+	assert.Contains(t, probe.Error, "401 Unauthorized")
+	assert.Equal(t, []string{"gpt-4o"}, probe.Models)
+
+	client.healthErr = nil
+	w = get("openai")
+	probe = domain.ProviderProbe{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &probe))
+	assert.True(t, probe.Healthy)
+	assert.Equal(t, http.StatusOK, probe.StatusCode)
+	assert.Empty(t, probe.Error)
+
+	assert.Equal(t, http.StatusNotFound, get("gemini").Code)
+}
@@ -0,0 +1,45 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/providers"
+	"github.com/quantum-suite/platform/internal/providers/registry"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestCreateProviderClient_UsesRegisteredFactory(t *testing.T) {
+	plugin := domain.Provider("router-plugin-test")
+	client := &countingProviderClient{}
+	var built env.ProviderConfig
+	registry.RegisterProviderFactory(plugin, registry.Factory{
+		Service: func(provider domain.Provider, config env.ProviderConfig, log logger.Logger) (registry.Client, error) {
+			built = config
+			return client, nil
+		},
+	})
+	s := newCacheTestService(nil, nil)
+
+	created, err := s.createProviderClient(plugin, env.ProviderConfig{Enabled: true, APIKey: "key"})
+	require.NoError(t, err)
+	assert.Same(t, client, created)
+	assert.Equal(t, "key", built.APIKey)
+}
+
+func TestCreateProviderClient_BuiltInAdaptersRegister(t *testing.T) {
+	s := newCacheTestService(nil, nil)
+
+	created, err := s.createProviderClient(domain.ProviderAzureOpenAI, env.ProviderConfig{Enabled: true, APIKey: "key", BaseURL: "https://example.openai.azure.com"})
+	require.NoError(t, err)
+	assert.IsType(t, &providers.AzureOpenAIClient{}, created)
+
+	// Providers without an adapter keep the mock client
+	created, err = s.createProviderClient(domain.Provider("unregistered"), env.ProviderConfig{Enabled: true})
+	require.NoError(t, err)
+	assert.IsType(t, &mockProviderClient{}, created)
+}
@@ -0,0 +1,174 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// currentConfig returns the active config; a reload swaps it for a copy
+func (s *Service) currentConfig() *env.Config {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.config
+}
+
+// providerClient returns the client for a provider, whether or not the
+// provider is currently enabled, so requests already routed to a provider
+// finish even if it is disabled meanwhile
+func (s *Service) providerClient(provider domain.Provider) (ProviderClient, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	client, ok := s.providerClients[provider]
+	return client, ok
+}
+
+// providerEnabled reports whether new requests may be routed to a provider
+func (s *Service) providerEnabled(provider domain.Provider) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	config, ok := s.providerConfigs[provider]
+	return ok && config.Enabled
+}
+
+// handleGetConfig returns the effective configuration with secrets redacted
+func (s *Service) handleGetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, s.currentConfig().Redacted())
+}
+
+func (s *Service) handleReloadConfig(c *gin.Context) {
+	next, err := env.Reread()
+	if err != nil {
+		s.respondWithError(c, shared_errors.InternalError("failed to read configuration", err))
+		return
+	}
+
+	result := s.reloadConfig(c.Request.Context(), next)
+	s.logger.Info("Configuration reloaded",
+		logger.F("applied", result.Applied),
+		logger.F("ignored", result.Ignored),
+		logger.F("errors", result.Errors),
+	)
+
+	c.JSON(http.StatusOK, result)
+}
+
+// reloadConfig applies the safe-to-change fields of next. Enabling a provider
+// that was disabled at startup creates its client; disabling one only stops
+// new requests from being routed to it.
+func (s *Service) reloadConfig(ctx context.Context, next *env.Config) env.ReloadResult {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	updated, result := env.Reload(s.currentConfig(), next)
+
+	s.mu.RLock()
+	enabled := make(map[domain.Provider]bool, len(s.providerConfigs))
+	for provider, config := range s.providerConfigs {
+		enabled[provider] = config.Enabled
+	}
+	clients := s.providerClients
+	s.mu.RUnlock()
+
+	// Clients of newly enabled providers are built and their models listed
+	// before taking s.mu, so requests are not held up by the provider calls
+	changed := make(map[domain.Provider]bool)
+	added := make(map[domain.Provider]ProviderClient)
+	var models []domain.Model
+	for name, providerConfig := range updated.Providers {
+		provider := domain.Provider(name)
+		wasEnabled, ok := enabled[provider]
+		if !ok || wasEnabled == providerConfig.Enabled {
+			continue
+		}
+
+		if providerConfig.Enabled && clients[provider] == nil {
+			client, err := s.createProviderClient(provider, providerConfig)
+			if err != nil {
+				providerConfig.Enabled = false
+				updated.Providers[name] = providerConfig
+				result.Applied = removeField(result.Applied, "providers."+name+".enabled")
+				result.Errors = append(result.Errors, fmt.Sprintf("providers.%s.enabled: %v", name, err))
+				continue
+			}
+			added[provider] = client
+			models = append(models, s.listProviderModels(ctx, provider, client)...)
+		}
+		changed[provider] = providerConfig.Enabled
+	}
+
+	s.mu.Lock()
+	if len(added) > 0 {
+		// Copy so the health checker and in-flight readers of the old map
+		// are unaffected
+		swapped := make(map[domain.Provider]ProviderClient, len(s.providerClients)+len(added))
+		for p, c := range s.providerClients {
+			swapped[p] = c
+		}
+		for p, c := range added {
+			swapped[p] = c
+		}
+		s.providerClients = swapped
+	}
+	s.addLiveModels(models)
+	s.applyModelPricing(updated.ModelPricing)
+	for provider, enabled := range changed {
+		s.providerConfigs[provider].Enabled = enabled
+	}
+	clients = s.providerClients
+	s.mu.Unlock()
+
+	for provider, enabled := range changed {
+		s.logger.Info("Provider availability changed",
+			logger.F("provider", provider),
+			logger.F("enabled", enabled),
+		)
+	}
+	if s.healthChecker != nil {
+		s.healthChecker.SetProviders(clients)
+	}
+
+	s.configMu.Lock()
+	s.config = updated
+	s.configMu.Unlock()
+
+	return result
+}
+
+// listProviderModels lists a newly enabled provider's models for the
+// registry, leaving out any a manifest pins. A provider that fails to list
+// them is enabled without models.
+func (s *Service) listProviderModels(ctx context.Context, provider domain.Provider, client ProviderClient) []domain.Model {
+	if !s.takesLiveModels() {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	models, err := client.ListModels(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to load models from provider",
+			logger.F("provider", provider),
+			logger.F("error", err))
+		return nil
+	}
+	return s.unpinnedModels(models)
+}
+
+func removeField(fields []string, field string) []string {
+	kept := fields[:0]
+	for _, f := range fields {
+		if f != field {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,116 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/providers/registry"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestReloadConfig_DisablesProvider(t *testing.T) {
+	current := &env.Config{
+		DefaultProviderOrder: []domain.Provider{domain.ProviderAzureOpenAI},
+		Providers: map[string]env.ProviderConfig{
+			"azure-openai": {Enabled: true},
+		},
+	}
+	s := &Service{
+		config: current,
+		logger: logger.NewNoop(),
+		providerConfigs: map[domain.Provider]*domain.ProviderConfig{
+			domain.ProviderAzureOpenAI: {Provider: domain.ProviderAzureOpenAI, Enabled: true},
+		},
+		providerClients: map[domain.Provider]ProviderClient{
+			domain.ProviderAzureOpenAI: &countingProviderClient{},
+		},
+		modelRegistry: map[string]map[domain.Provider]*domain.Model{},
+	}
+
+	next := &env.Config{
+		DefaultProviderOrder: []domain.Provider{domain.ProviderAWSBedrock, domain.ProviderAzureOpenAI},
+		Providers: map[string]env.ProviderConfig{
+			"azure-openai": {Enabled: false},
+		},
+	}
+	result := s.reloadConfig(context.Background(), next)
+
+	assert.Equal(t, []string{"default_provider_order", "providers.azure-openai.enabled"}, result.Applied)
+	assert.Empty(t, result.Errors)
+	assert.Equal(t, next.DefaultProviderOrder, s.currentConfig().DefaultProviderOrder)
+
+	// New requests are refused, but in-flight ones can still reach the client
+	_, err := s.selectProvider("tenant-a", "gpt-4o", domain.ProviderAzureOpenAI, "")
+	require.Error(t, err)
+	_, ok := s.providerClient(domain.ProviderAzureOpenAI)
+	assert.True(t, ok)
+
+	// The previous config is left untouched for requests holding it
+	assert.Equal(t, []domain.Provider{domain.ProviderAzureOpenAI}, current.DefaultProviderOrder)
+	assert.True(t, current.Providers["azure-openai"].Enabled)
+}
+
+// blockingModelsClient lists its models only once released
+type blockingModelsClient struct {
+	countingProviderClient
+	listing chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingModelsClient) ListModels(ctx context.Context) ([]domain.Model, error) {
+	close(c.listing)
+	<-c.release
+	return []domain.Model{{ModelID: "reload-model", Provider: "reload-test"}}, nil
+}
+
+func TestReloadConfig_ListsModelsWithoutBlockingRequests(t *testing.T) {
+	const provider = domain.Provider("reload-test")
+	client := &blockingModelsClient{listing: make(chan struct{}), release: make(chan struct{})}
+	registry.RegisterProviderFactory(provider, registry.Factory{
+		Service: func(domain.Provider, env.ProviderConfig, logger.Logger) (registry.Client, error) {
+			return client, nil
+		},
+	})
+
+	s := &Service{
+		config: &env.Config{Providers: map[string]env.ProviderConfig{
+			string(provider): {Enabled: false},
+		}},
+		logger: logger.NewNoop(),
+		providerConfigs: map[domain.Provider]*domain.ProviderConfig{
+			provider: {Provider: provider, Enabled: false},
+		},
+		providerClients: map[domain.Provider]ProviderClient{},
+		modelRegistry:   map[string]map[domain.Provider]*domain.Model{},
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.reloadConfig(context.Background(), &env.Config{Providers: map[string]env.ProviderConfig{
+			string(provider): {Enabled: true},
+		}})
+	}()
+
+	// Requests keep being served while the provider lists its models
+	<-client.listing
+	served := make(chan bool)
+	go func() { served <- s.providerEnabled(provider) }()
+	select {
+	case enabled := <-served:
+		assert.False(t, enabled)
+	case <-time.After(time.Second):
+		t.Fatal("request blocked by the reload")
+	}
+
+	close(client.release)
+	<-done
+	assert.True(t, s.providerEnabled(provider))
+	assert.True(t, s.providerSupportsModel(provider, "reload-model"))
+}
@@ -0,0 +1,51 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// statusProviderClient fails with the given provider statuses in turn, then
+// succeeds
+type statusProviderClient struct {
+	countingProviderClient
+	statuses []int
+}
+
+func (c *statusProviderClient) CreateCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+	if len(c.statuses) > 0 {
+		status := c.statuses[0]
+		c.statuses = c.statuses[1:]
+		c.calls++
+		return nil, shared_errors.ProviderError("openai", http.StatusText(status), nil).WithProviderStatus(status)
+	}
+	return c.countingProviderClient.CreateCompletion(ctx, req)
+}
+
+func TestRouteCompletion_RetriesConfiguredStatusCodes(t *testing.T) {
+	client := &statusProviderClient{statuses: []int{http.StatusServiceUnavailable}}
+	s := newCacheTestService(client, nil)
+	s.config.RetryStatusCodes = shared_errors.DefaultRetryStatusCodes
+
+	_, err := s.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, client.calls)
+}
+
+func TestRouteCompletion_DoesNotRetryUnlistedStatusCodes(t *testing.T) {
+	// Provider errors are flagged retryable, but the status overrides that
+	client := &statusProviderClient{statuses: []int{http.StatusNotImplemented}}
+	s := newCacheTestService(client, nil)
+	s.config.RetryStatusCodes = shared_errors.DefaultRetryStatusCodes
+
+	_, err := s.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.Error(t, err)
+	assert.Equal(t, 1, client.calls)
+}
@@ -8,13 +8,17 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
-	"os"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/quantum-suite/platform/internal/domain"
-	"github.com/quantum-suite/platform/internal/providers"
+	// Registers the built-in provider adapters
+	_ "github.com/quantum-suite/platform/internal/providers"
+	"github.com/quantum-suite/platform/internal/providers/registry"
+	"github.com/quantum-suite/platform/internal/services/cache"
 	"github.com/quantum-suite/platform/internal/services/cost"
 	"github.com/quantum-suite/platform/pkg/shared/env"
 	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
@@ -28,33 +32,50 @@ type Service struct {
 	router            *gin.Engine
 	providerClients   map[domain.Provider]ProviderClient
 	providerConfigs   map[domain.Provider]*domain.ProviderConfig
-	modelRegistry     map[string]*domain.Model
+	modelRegistry     map[string]map[domain.Provider]*domain.Model // Models by ID and the provider serving them
+	registryVersion   string                                       // Version of the model registry's contents
+	modelManifest     *modelManifest                               // Manifest pinning the registry; nil without one
+	liveModels        []domain.Model                               // Models added from the providers' live listings
+	listedPricing     map[registryKey]domain.ModelPricing          // Providers' own pricing of models the config reprices
 	healthChecker     *HealthChecker
 	loadBalancer      *LoadBalancer
 	circuitBreaker    *CircuitBreaker
+	concurrency       *AdaptiveLimiter
+	providerStats     *ProviderStats
 	costService       *cost.CostService
+	cache             CacheClient
+	tenantClients     *TenantClients
+	deadLetters       DeadLetterStore
+	responseSizes     *ResponseSizeMonitor
+	alertManager      AlertManager
+	faqs              FAQStore
+	killSwitches      *KillSwitches
+	warmupDone        chan struct{}      // Closed once the startup warm-up ends; nil without one
+	stopWarmup        context.CancelFunc // Cuts the startup warm-up short
 	mu                sync.RWMutex
+	configMu          sync.RWMutex
+	reloadMu          sync.Mutex // Serializes config reloads
+	healthMu          sync.Mutex // Serializes provider health updates
 }
 
-// ProviderClient interface for LLM providers
-type ProviderClient interface {
-	CreateCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error)
-	CreateCompletionStream(ctx context.Context, req *domain.CompletionRequest) (<-chan *domain.StreamResponse, error)
-	CreateEmbeddings(ctx context.Context, req *domain.EmbeddingRequest) (*domain.EmbeddingResponse, error)
-	ListModels(ctx context.Context) ([]domain.Model, error)
-	HealthCheck(ctx context.Context) error
-}
+// ProviderClient interface for LLM providers, built through the adapter
+// registry
+type ProviderClient = registry.Client
 
 // Request/Response types (same as gateway service)
 // Use domain types instead of duplicating them here
 
 func NewService(config *env.Config, log logger.Logger) (*Service, error) {
+	if err := config.Validate(); err != nil {
+		return nil, shared_errors.InternalError("invalid router configuration", err)
+	}
+
 	service := &Service{
 		config:          config,
 		logger:          log.WithField("service", "router"),
 		providerClients: make(map[domain.Provider]ProviderClient),
 		providerConfigs: make(map[domain.Provider]*domain.ProviderConfig),
-		modelRegistry:   make(map[string]*domain.Model),
+		modelRegistry:   make(map[string]map[domain.Provider]*domain.Model),
 	}
 
 	// Initialize components
@@ -80,8 +101,15 @@ func (s *Service) initializeComponents() error {
 	// Initialize circuit breaker
 	s.circuitBreaker = NewCircuitBreaker(s.logger)
 
+	// Initialize adaptive per-provider concurrency limiter
+	s.concurrency = NewAdaptiveLimiter(s.logger)
+
+	// Initialize per-provider latency and error tracking for auto routing
+	s.providerStats = NewProviderStats()
+
 	// Initialize health checker
-	s.healthChecker = NewHealthChecker(s.providerClients, s.logger)
+	s.healthChecker = NewHealthChecker(s.providerClients, s.config.HealthCheckInterval, s.config.HealthCheckTimeout, s.config.ProviderKeepAliveInterval, s.config.HealthCheckConcurrency, s.logger)
+	s.healthChecker.OnResult(s.updateProviderHealth)
 	s.healthChecker.Start()
 
 	// Initialize cost service with default budget configuration
@@ -98,11 +126,55 @@ func (s *Service) initializeComponents() error {
 	}
 	s.costService = cost.NewCostService(s.logger, budgetConfig)
 
+	// Initialize the completion cache: the shared cache service when one is
+	// configured, otherwise a store local to this instance
+	cacheURL := s.config.GetString("CACHE_SERVICE_URL", "")
+	if cacheURL != "" {
+		s.cache = NewHTTPCacheClient(cacheURL)
+	} else if s.config.CacheType != "none" {
+		s.cache = NewStoreCacheClient(cache.NewMemoryStore(s.logger))
+	}
+
+	// Kill switches toggled by operators are shared through the cache
+	// service's system key space, even with completion caching off
+	var systemStore SystemStore = NewLocalSystemStore(cache.NewMemoryStore(s.logger))
+	if cacheURL != "" {
+		systemStore = NewHTTPSystemStore(cacheURL)
+	}
+	s.killSwitches = NewKillSwitches(NewCacheKillSwitchStore(systemStore), s.config.KillSwitchRefresh, s.logger)
+	s.killSwitches.Start()
+
+	// Tenants with their own provider credentials get clients built from them
+	if dir := s.config.TenantCredentialsDir; dir != "" {
+		s.tenantClients = NewTenantClients(NewDirCredentialStore(dir), s.config.TenantCredentialsTTL, s.createTenantProviderClient, s.logger)
+	}
+
+	// Requests failing after every retry and fallback are kept for analysis
+	if path := s.config.DeadLetterPath; path != "" {
+		s.deadLetters = NewFileDeadLetterStore(path)
+	}
+
+	// Sharp changes in response sizes are raised with Alertmanager
+	s.responseSizes = NewResponseSizeMonitor()
+	if url := s.config.AlertManagerURL; url != "" {
+		s.alertManager = NewHTTPAlertManager(url)
+	}
+
+	// Tenants' curated FAQ answers are matched before calling a provider
+	faqs, err := NewFileFAQStore(s.config.FAQ.StorePath)
+	if err != nil {
+		return err
+	}
+	s.faqs = faqs
+
 	// Load model registry
 	if err := s.loadModelRegistry(); err != nil {
 		return err
 	}
 
+	// Open connections to the providers before reporting ready
+	s.startProviderWarmup()
+
 	return nil
 }
 
@@ -122,6 +194,7 @@ func (s *Service) initializeProviders() error {
 			"timeout": providerConfig.Timeout,
 			"max_retries": providerConfig.MaxRetries,
 		}
+		config.DataRegion = providerConfig.DataRegion
 		s.providerConfigs[provider] = config
 
 		if !providerConfig.Enabled {
@@ -150,81 +223,18 @@ func (s *Service) initializeProviders() error {
 }
 
 func (s *Service) createProviderClient(provider domain.Provider, config env.ProviderConfig) (ProviderClient, error) {
-	switch provider {
-	case domain.ProviderAzureOpenAI:
-		azureConfig := providers.AzureOpenAIConfig{
-			Endpoint:    config.BaseURL,
-			APIKey:      config.APIKey,
-			APIVersion:  "2024-02-15-preview",  // Stable API version
-			Deployments: map[string]string{
-				"gpt-35-turbo":    "gpt-35-turbo-0125",
-				"gpt-4":           "gpt-4-turbo-2024-04-09", 
-				"gpt-4o":          "gpt-4o-2024-05-13",
-				"gpt-4o-mini":     "gpt-4o-mini-2024-07-18",
-				"gpt-5":           "gpt-5-2025-08-07",
-				"gpt-5-mini":      "gpt-5-mini-2025-08-07",
-			},
-		}
-		return providers.NewAzureOpenAIClient(azureConfig, s.logger.WithField("provider", string(provider)))
-		
-	case domain.ProviderAWSBedrock:
-		models := []providers.BedrockModelConfig{
-			{
-				ID:      "claude-3.7-sonnet",
-				ModelID: "anthropic.claude-3-7-sonnet-20250219-v1:0",
-				Name:    "Claude 3.7 Sonnet",
-			},
-			{
-				ID:      "claude-3-sonnet",
-				ModelID: "anthropic.claude-3-sonnet-20240229-v1:0",
-				Name:    "Claude 3 Sonnet",
-			},
-			{
-				ID:      "claude-3-haiku",
-				ModelID: "anthropic.claude-3-haiku-20240307-v1:0",
-				Name:    "Claude 3 Haiku",
-			},
-		}
-		
-		bedrockConfig := providers.AWSBedrockConfig{
-			Region:          os.Getenv("AWS_REGION"),  // Read from environment
-			AccessKeyID:     config.APIKey,  // Using APIKey field
-			SecretAccessKey: "",  // Will be loaded from env
-			SessionToken:    "",
-			Models:          models,
-		}
-		return providers.NewAWSBedrockClient(bedrockConfig, s.logger.WithField("provider", string(provider)))
-		
-	default:
-		// For other providers, return mock implementations for now
-		return &mockProviderClient{
-			provider: provider,
-			logger:   s.logger.WithField("provider", string(provider)),
-		}, nil
-	}
-}
-
-func (s *Service) loadModelRegistry() error {
-	// Load available models from all providers
-	for provider, client := range s.providerClients {
-		models, err := client.ListModels(context.Background())
-		if err != nil {
-			s.logger.Error("Failed to load models from provider",
-				logger.F("provider", provider),
-				logger.F("error", err))
-			continue
-		}
-
-		for _, model := range models {
-			s.modelRegistry[model.ModelID] = &model
-		}
-
-		s.logger.Info("Loaded models from provider",
-			logger.F("provider", provider),
-			logger.F("count", len(models)))
+	log := s.logger.WithField("provider", string(provider))
+	
+	// Adapters register how their clients are built
+	if factory, ok := registry.Lookup(provider); ok && factory.Service != nil {
+		return factory.Service(provider, config, log)
 	}
-
-	return nil
+	
+	// For other providers, return mock implementations for now
+	return &mockProviderClient{
+		provider: provider,
+		logger:   log,
+	}, nil
 }
 
 func (s *Service) setupRouter() {
@@ -239,6 +249,9 @@ func (s *Service) setupRouter() {
 	s.router.GET("/health", s.handleHealth)
 	s.router.GET("/health/ready", s.handleReadiness)
 
+	// Prometheus metrics
+	s.router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	// Internal API endpoints (called by gateway)
 	api := s.router.Group("/internal/v1")
 	{
@@ -251,6 +264,16 @@ func (s *Service) setupRouter() {
 		api.GET("/usage/global", s.handleGetGlobalUsage)
 		api.GET("/usage/tenant/:tenant_id", s.handleGetTenantUsage)
 		api.GET("/costs/summary", s.handleGetCostSummary)
+
+		// Admin endpoints (called by the gateway's admin API)
+		api.GET("/config", s.handleGetConfig)
+		api.GET("/providers/:provider/probe", s.handleProbeProvider)
+		api.POST("/reload", s.handleReloadConfig)
+		api.GET("/faq/:tenant_id", s.handleListFAQEntries)
+		api.POST("/faq/:tenant_id", s.handleCreateFAQEntry)
+		api.DELETE("/faq/:tenant_id/:id", s.handleDeleteFAQEntry)
+		api.GET("/kill-switches", s.handleListKillSwitches)
+		api.POST("/kill-switches", s.handleSetKillSwitch)
 	}
 }
 
@@ -263,6 +286,13 @@ func (s *Service) Close() error {
 	if s.healthChecker != nil {
 		s.healthChecker.Stop()
 	}
+	if s.killSwitches != nil {
+		s.killSwitches.Stop()
+	}
+	if s.warmupDone != nil {
+		s.stopWarmup()
+		<-s.warmupDone
+	}
 
 	// Close provider clients if they have cleanup
 	// This would be implemented by actual provider clients
@@ -273,7 +303,7 @@ func (s *Service) Close() error {
 // Route handlers
 
 func (s *Service) handleRouteCompletion(c *gin.Context) {
-	ctx := c.Request.Context()
+	ctx := withAttemptLog(c.Request.Context())
 
 	var req domain.CompletionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -284,6 +314,7 @@ func (s *Service) handleRouteCompletion(c *gin.Context) {
 	// Select provider and route request
 	response, err := s.routeCompletion(ctx, &req)
 	if err != nil {
+		s.deadLetterCompletion(ctx, deadLetterOperationCompletion, &req, err)
 		s.respondWithError(c, err)
 		return
 	}
@@ -292,7 +323,7 @@ func (s *Service) handleRouteCompletion(c *gin.Context) {
 }
 
 func (s *Service) handleRouteCompletionStream(c *gin.Context) {
-	ctx := c.Request.Context()
+	ctx := withAttemptLog(c.Request.Context())
 
 	var req domain.CompletionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -300,19 +331,16 @@ func (s *Service) handleRouteCompletionStream(c *gin.Context) {
 		return
 	}
 
-	// Set streaming headers
-	c.Header("Content-Type", "text/event-stream")
-	c.Header("Cache-Control", "no-cache")
-
 	// Route streaming request
 	if err := s.routeCompletionStream(ctx, &req, c); err != nil {
+		s.deadLetterCompletion(ctx, deadLetterOperationCompletionStream, &req, err)
 		s.respondWithError(c, err)
 		return
 	}
 }
 
 func (s *Service) handleRouteEmbedding(c *gin.Context) {
-	ctx := c.Request.Context()
+	ctx := withAttemptLog(c.Request.Context())
 
 	var req domain.EmbeddingRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -323,6 +351,7 @@ func (s *Service) handleRouteEmbedding(c *gin.Context) {
 	// Route embedding request
 	response, err := s.routeEmbedding(ctx, &req)
 	if err != nil {
+		s.deadLetterEmbedding(ctx, &req, err)
 		s.respondWithError(c, err)
 		return
 	}
@@ -343,8 +372,9 @@ func (s *Service) handleListModels(c *gin.Context) {
 
 	models := s.listModels(opts)
 	c.JSON(http.StatusOK, &domain.ModelsResponse{
-		Object: "list",
-		Data:   models,
+		Object:          "list",
+		Data:            models,
+		RegistryVersion: s.currentRegistryVersion(),
 	})
 }
 
@@ -362,10 +392,12 @@ func (s *Service) handleHealth(c *gin.Context) {
 func (s *Service) handleReadiness(c *gin.Context) {
 	// Check if we have at least one healthy provider
 	hasHealthyProvider := false
+	window := s.currentConfig().PassiveHealth.Window
+	now := time.Now()
 	
 	s.mu.RLock()
 	for _, config := range s.providerConfigs {
-		if config.Enabled && config.HealthStatus == domain.ProviderHealthHealthy {
+		if providerRoutable(config, window, now) {
 			hasHealthyProvider = true
 			break
 		}
@@ -380,6 +412,14 @@ func (s *Service) handleReadiness(c *gin.Context) {
 		return
 	}
 
+	if s.warmingUp() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"status": "not_ready",
+			"reason": "provider_warmup",
+		})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"status": "ready"})
 }
 
@@ -388,67 +428,202 @@ func (s *Service) handleReadiness(c *gin.Context) {
 func (s *Service) routeCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
 	start := time.Now() // Track request timing
 	
+	if err := s.applyParamProfile(req); err != nil {
+		return nil, err
+	}
+	deprecation := s.applyModelDeprecation(req)
+	maxTokensAdjustment := s.applyMaxTokensLimits(req)
+	transformWarnings := s.applyModelTransform(req)
+	toolSanitization := s.sanitizeToolResults(req)
+
 	// Generate cache key if caching is enabled
 	var cacheKey string
-	if req.CacheEnabled {
+	if req.CacheEnabled && s.cache != nil && !req.IncludeRawResponse && !req.ExplainRouting {
 		cacheKey = s.generateCacheKey(req.TenantID, req)
-		// TODO: Check cache first
+		if cached := s.getCachedCompletion(ctx, req.TenantID, cacheKey); cached != nil {
+			cached.ReceivedAt = time.Now().UTC()
+			setModelDeprecation(cached, deprecation)
+			setMaxTokensAdjustment(cached, maxTokensAdjustment)
+			setTransformWarnings(cached, transformWarnings)
+			setToolSanitization(cached, toolSanitization)
+			setUsageBreakdown(req, cached)
+			return cached, nil
+		}
 	}
 
-	// Select provider
-	provider, err := s.selectProvider(req.Model, req.Provider)
+	// A prompt asking one of the tenant's curated FAQ questions is answered
+	// with the canonical answer instead of by a provider
+	if answer := s.answerFromFAQ(ctx, req); answer != nil {
+		return answer, nil
+	}
+
+	// Select provider, scoring the candidates when the request asks for auto
+	var provider domain.Provider
+	var scores []domain.ProviderScore
+	var err error
+	if req.Provider == domain.ProviderAuto {
+		provider, scores, err = s.selectAutoProvider(req.TenantID, req.Model, req.DataResidency)
+	} else {
+		provider, err = s.selectProvider(req.TenantID, req.Model, req.Provider, req.DataResidency)
+	}
 	if err != nil {
+		return s.staleOrError(ctx, req, err)
+	}
+	if err := s.validatePrefill(req, provider); err != nil {
 		return nil, err
 	}
+	utilization, overContext := s.contextUtilization(req, provider, true)
+	var explanation *domain.RoutingExplanation
+	if req.ExplainRouting {
+		explanation = s.explainRouting(req, provider, scores)
+	}
 
-	// Check circuit breaker
-	if !s.circuitBreaker.CanExecute(provider) {
-		return nil, shared_errors.ProviderUnavailableError(string(provider))
+	ctx, client, release, err := s.resolveClient(ctx, req.TenantID, provider)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Check circuit breaker; a tenant's own account is not gated by it
+	if !isTenantScoped(ctx) && !s.circuitBreaker.CanExecute(provider) {
+		err := shared_errors.ProviderUnavailableError(string(provider))
+		recordAttempt(ctx, provider, req.Model, 0, err)
+		return s.staleOrError(ctx, req, err)
 	}
 
 	// Check budget compliance before making expensive API call
-	estimatedCost := s.estimateRequestCost(req.Model, req.MaxTokens)
-	if err := s.costService.CheckBudgetCompliance(req.TenantID, estimatedCost); err != nil {
-		s.logger.Warn("Budget compliance check failed",
-			logger.F("tenant_id", req.TenantID),
-			logger.F("estimated_cost", estimatedCost),
-			logger.F("error", err),
-		)
+	reservation, err := s.reserveBudget(ctx, req.TenantID, s.estimateRequestCost(req.Model, req.MaxTokens))
+	if err != nil {
 		return nil, err
 	}
 
 	// Route to provider with retry logic
-	client := s.providerClients[provider]
 	result, err := s.executeWithRetry(ctx, func() (interface{}, error) {
 		return client.CreateCompletion(ctx, req)
-	}, provider)
+	}, provider, req.Model)
 	
 	if err != nil {
-		return nil, err
+		s.costService.ReleaseReservation(reservation)
+		return s.staleOrError(ctx, req, err)
 	}
 	
 	response := result.(*domain.CompletionResponse)
 
-	s.circuitBreaker.RecordSuccess(provider)
+	if !isTenantScoped(ctx) {
+		s.circuitBreaker.RecordSuccess(provider)
+	}
+
+	// Check the provider's timestamp before caching so cached copies keep
+	// a sane one
+	s.normalizeCreated(provider, response)
 
-	// Track cost and usage
-	if err := s.trackRequestCost(ctx, req, response, provider, time.Since(start)); err != nil {
+	// Unwrap JSON before caching so cached copies are clean too
+	s.stripJSONFences(req, response)
+
+	// Track cost, usage and payload sizes
+	s.recordPayloadSizes(ctx, req, provider, responseContentBytes(response), response.Usage)
+	if err := s.trackRequestCost(ctx, req, response, provider, time.Since(start), reservation); err != nil {
 		s.logger.Warn("Failed to track request cost", logger.F("error", err))
 	}
 
 	// Cache response if enabled
-	if req.CacheEnabled && cacheKey != "" {
-		// TODO: Cache the response
+	if cacheKey != "" {
+		s.cacheCompletion(ctx, req, cacheKey, response)
+	}
+
+	// Scores describe this request's routing, so cached copies leave them out
+	if scores != nil {
+		if response.Metadata == nil {
+			response.Metadata = make(map[string]interface{})
+		}
+		response.Metadata[domain.MetadataKeyRoutingScores] = scores
 	}
+	if explanation != nil {
+		if response.Metadata == nil {
+			response.Metadata = make(map[string]interface{})
+		}
+		response.Metadata[domain.MetadataKeyRouting] = explanation
+	}
+	if overContext {
+		if response.Metadata == nil {
+			response.Metadata = make(map[string]interface{})
+		}
+		response.Metadata[domain.MetadataKeyContextUtilization] = utilization
+	}
+	setModelDeprecation(response, deprecation)
+	setMaxTokensAdjustment(response, maxTokensAdjustment)
+	setTransformWarnings(response, transformWarnings)
+	setToolSanitization(response, toolSanitization)
+	setUsageBreakdown(req, response)
 
 	return response, nil
 }
 
-// trackRequestCost records cost and usage metrics for a completed request
-func (s *Service) trackRequestCost(ctx context.Context, req *domain.CompletionRequest, response *domain.CompletionResponse, provider domain.Provider, duration time.Duration) error {
+// getCachedCompletion returns the cached response for the key, or nil on a
+// miss. Cache failures are logged and treated as misses so they never fail
+// the request.
+func (s *Service) getCachedCompletion(ctx context.Context, tenantID domain.TenantID, cacheKey string) *domain.CompletionResponse {
+	data, found, err := s.cache.Get(ctx, tenantID, cacheKey)
+	if err != nil {
+		s.logger.Warn("Completion cache lookup failed",
+			logger.F("tenant_id", tenantID),
+			logger.F("error", err))
+		completionCacheRequests.WithLabelValues("error").Inc()
+		return nil
+	}
+	if !found {
+		completionCacheRequests.WithLabelValues("miss").Inc()
+		return nil
+	}
+
+	var response domain.CompletionResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		s.logger.Warn("Discarding unreadable cached completion",
+			logger.F("tenant_id", tenantID),
+			logger.F("error", err))
+		completionCacheRequests.WithLabelValues("error").Inc()
+		return nil
+	}
+
+	completionCacheRequests.WithLabelValues("hit").Inc()
+
+	// No provider was called, so the hit itself costs nothing
+	response.Usage.CacheHit = true
+	response.Usage.CostUSD = 0
+	return &response
+}
+
+// cacheCompletion stores a successful response for the request's TTL, or the
+// configured default when the request does not set one
+func (s *Service) cacheCompletion(ctx context.Context, req *domain.CompletionRequest, cacheKey string, response *domain.CompletionResponse) {
+	data, err := json.Marshal(response)
+	if err != nil {
+		s.logger.Warn("Failed to marshal completion for cache", logger.F("error", err))
+		return
+	}
+
+	ttl := req.CacheTTL
+	if ttl <= 0 {
+		ttl = s.currentConfig().Cache.TTL
+	}
+
+	if err := s.cache.Set(ctx, req.TenantID, cacheKey, data, ttl); err != nil {
+		s.logger.Warn("Failed to cache completion",
+			logger.F("tenant_id", req.TenantID),
+			logger.F("error", err))
+	}
+	s.cacheStaleCopy(ctx, req.TenantID, cacheKey, data, ttl)
+}
+
+// trackRequestCost records cost and usage metrics for a completed request,
+// settling its budget reservation when it has one. A configured price for
+// the model replaces the provider's cost.
+func (s *Service) trackRequestCost(ctx context.Context, req *domain.CompletionRequest, response *domain.CompletionResponse, provider domain.Provider, duration time.Duration, reservation *cost.BudgetReservation) error {
 	// Extract service name from context or headers
 	serviceName := s.extractServiceName(ctx)
 	
+	s.repriceCompletion(req.Model, &response.Usage)
+
 	// Create cost tracking request
 	costReq := &cost.CostTrackingRequest{
 		TenantID:      req.TenantID,
@@ -461,6 +636,30 @@ func (s *Service) trackRequestCost(ctx context.Context, req *domain.CompletionRe
 		Success:       true,
 		RequestID:     response.ID,
 		Timestamp:     time.Now(),
+		Reservation:   reservation,
+	}
+
+	return s.costService.TrackRequest(ctx, costReq)
+}
+
+// trackEmbeddingCost records cost and usage for a completed embedding
+// request, settling its budget reservation when it has one. A configured
+// price for the model replaces the provider's cost.
+func (s *Service) trackEmbeddingCost(ctx context.Context, req *domain.EmbeddingRequest, response *domain.EmbeddingResponse, provider domain.Provider, duration time.Duration, reservation *cost.BudgetReservation) error {
+	s.repriceEmbedding(response.Model, &response.Usage)
+
+	costReq := &cost.CostTrackingRequest{
+		TenantID:    req.TenantID,
+		ServiceName: s.extractServiceName(ctx),
+		ModelID:     response.Model,
+		Provider:    provider,
+		Cost:        response.Usage.CostUSD,
+		TokensUsed:  int64(response.Usage.TotalTokens),
+		LatencyMs:   float64(duration.Milliseconds()),
+		Success:     true,
+		RequestID:   req.RequestID,
+		Timestamp:   time.Now(),
+		Reservation: reservation,
 	}
 
 	return s.costService.TrackRequest(ctx, costReq)
@@ -478,6 +677,25 @@ func (s *Service) extractServiceName(ctx context.Context) string {
 	return "unknown_service"
 }
 
+// reserveBudget checks budget compliance for a request, reserving the
+// estimated cost until the actual cost is tracked. Requests on the tenant's
+// own credentials are billed to the tenant's account and reserve nothing.
+func (s *Service) reserveBudget(ctx context.Context, tenantID domain.TenantID, estimatedCost float64) (*cost.BudgetReservation, error) {
+	if isTenantScoped(ctx) {
+		return nil, nil
+	}
+	reservation, err := s.costService.CheckBudgetCompliance(tenantID, estimatedCost)
+	if err != nil {
+		s.logger.Warn("Budget compliance check failed",
+			logger.F("tenant_id", tenantID),
+			logger.F("estimated_cost", estimatedCost),
+			logger.F("error", err),
+		)
+		return nil, err
+	}
+	return reservation, nil
+}
+
 // estimateRequestCost provides rough cost estimation for budget compliance
 func (s *Service) estimateRequestCost(modelID string, maxTokens *int) float64 {
 	// Default values if not specified
@@ -511,109 +729,440 @@ func (s *Service) estimateRequestCost(modelID string, maxTokens *int) float64 {
 	return estimatedCost
 }
 
+// estimateEmbeddingCost estimates an embedding request's cost from the size
+// of its inputs, at about four characters a token, and the model's input
+// price at the provider. Embeddings bill no output.
+func (s *Service) estimateEmbeddingCost(provider domain.Provider, req *domain.EmbeddingRequest) float64 {
+	chars := 0
+	for _, input := range req.Input {
+		chars += len(input)
+	}
+	tokens := domain.EstimateTextTokens(chars)
+
+	s.mu.RLock()
+	model := s.providerModel(provider, req.Model)
+	s.mu.RUnlock()
+	if model == nil {
+		return 0
+	}
+	inputCost, _, _ := model.Pricing.PerThousandTokens()
+	return float64(tokens) * inputCost / 1000.0
+}
+
 func (s *Service) routeCompletionStream(ctx context.Context, req *domain.CompletionRequest, c *gin.Context) error {
+	start := time.Now()
+	if err := s.applyParamProfile(req); err != nil {
+		return err
+	}
+	deprecation := s.applyModelDeprecation(req)
+	setModelDeprecationHeader(c, deprecation)
+	s.applyMaxTokensLimits(req)
+	setTransformWarningsHeader(c, s.applyModelTransform(req))
+	s.sanitizeToolResults(req)
+
+	// A prompt asking one of the tenant's curated FAQ questions is answered
+	// with the canonical answer instead of by a provider
+	if answer := s.answerFromFAQ(ctx, req); answer != nil {
+		streamFAQAnswer(c, req, answer, newStreamFinish(deprecation, 0, false))
+		return nil
+	}
+
 	// Select provider
-	provider, err := s.selectProvider(req.Model, req.Provider)
+	provider, err := s.selectProvider(req.TenantID, req.Model, req.Provider, req.DataResidency)
 	if err != nil {
 		return err
 	}
 
-	// Check circuit breaker
-	if !s.circuitBreaker.CanExecute(provider) {
-		return shared_errors.ProviderUnavailableError(string(provider))
+	// A stream that fails before sending any content moves on to the next
+	// provider serving the model, which is chosen up front so the stream
+	// knows whether it can hand over or must report the error itself
+	tried := []domain.Provider{}
+	for {
+		tried = append(tried, provider)
+		next, canFailover := s.nextStreamProvider(req, tried)
+		if err := s.validatePrefill(req, provider); err != nil {
+			return err
+		}
+		// Every provider's context window is checked, but the request is
+		// recorded in the histogram once
+		utilization, overContext := s.contextUtilization(req, provider, len(tried) == 1)
+		finish := newStreamFinish(deprecation, utilization, overContext)
+		failedOver, err := s.streamFromProvider(ctx, req, provider, finish, c, start, canFailover)
+		if !failedOver {
+			if err != nil && len(tried) > 1 {
+				// The first provider's stream set the event-stream headers,
+				// so the error goes out as an event
+				s.deadLetterCompletion(ctx, deadLetterOperationCompletionStream, req, err)
+				writeStreamError(c, shared_errors.FromError(err))
+				return nil
+			}
+			return err
+		}
+
+		streamFailovers.WithLabelValues(string(provider), string(next)).Inc()
+		s.logger.Warn("Completion stream failed before sending content, failing over",
+			logger.F("provider", provider),
+			logger.F("next_provider", next),
+			logger.F("model", req.Model),
+			logger.F("request_id", req.RequestID),
+			logger.F("error", err))
+		provider = next
 	}
+}
+
+// streamFromProvider streams the completion from one provider. With
+// failover set, a provider failure within the failover grace window before
+// any content has been sent is returned with failedOver true and nothing
+// written, so the caller can retry the stream elsewhere. Chunks carrying no
+// content are held back until then. The final event reports the warnings
+// finish was started with.
+func (s *Service) streamFromProvider(ctx context.Context, req *domain.CompletionRequest, provider domain.Provider, finish *streamFinish, c *gin.Context, start time.Time, failover bool) (failedOver bool, err error) {
+	utilization, overContext := finish.contextUtilization()
+
+	ctx, client, release, err := s.resolveClient(ctx, req.TenantID, provider)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+
+	// Check circuit breaker; a tenant's own account is not gated by it
+	if !isTenantScoped(ctx) && !s.circuitBreaker.CanExecute(provider) {
+		err := shared_errors.ProviderUnavailableError(string(provider))
+		recordAttempt(ctx, provider, req.Model, 0, err)
+		return false, err
+	}
+
+	// Reserve the stream's estimated cost until what it delivered is billed
+	reservation, err := s.reserveBudget(ctx, req.TenantID, s.estimateRequestCost(req.Model, req.MaxTokens))
+	if err != nil {
+		return false, err
+	}
+
+	// Reserve a concurrency slot for the lifetime of the stream
+	if !s.concurrency.Acquire(provider) {
+		s.costService.ReleaseReservation(reservation)
+		return false, concurrencyLimitError(provider)
+	}
+	outcome := LimiterOutcomeIgnore
+	defer func() { s.concurrency.Release(provider, outcome) }()
 
 	// Route to provider
-	client := s.providerClients[provider]
+	requested := time.Now()
 	streamChan, err := client.CreateCompletionStream(ctx, req)
 	if err != nil {
-		s.circuitBreaker.RecordFailure(provider)
-		return err
+		if s.isStreamCancelled(ctx, err) {
+			s.costService.ReleaseReservation(reservation)
+			s.recordStreamTermination(ctx, req, provider, domain.StreamOutcomeCancelled, err)
+			return false, nil
+		}
+		if s.fallsBackFromStream(ctx, err) {
+			if overContext {
+				setContextUtilizationHeader(c, utilization)
+			}
+			outcome, err = s.completeStreamWithoutStreaming(ctx, req, provider, client, finish, reservation, c, err, start)
+			return false, err
+		}
+		s.costService.ReleaseReservation(reservation)
+		outcome = limiterOutcome(ctx, err)
+		s.recordStreamTermination(ctx, req, provider, domain.StreamOutcomeProviderError, err)
+		return false, err
+	}
+	opened := time.Now()
+
+	// Streaming headers are only set once the provider has accepted the
+	// request, so earlier errors go out as plain JSON with their own status
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	if overContext {
+		setContextUtilizationHeader(c, utilization)
 	}
 
-	// Stream responses
+	// Stream responses. However the stream ends, including the client going
+	// away mid-stream, what it delivered is billed: the provider charges for
+	// every token it generated.
+	usage := &streamUsage{}
+	ended := false
+	defer func() {
+		if failedOver || (!ended && !usage.delivered()) {
+			s.costService.ReleaseReservation(reservation)
+			return
+		}
+		if !ended {
+			partialStreamsBilled.WithLabelValues(string(provider)).Inc()
+		}
+		s.settleStreamUsage(ctx, req, provider, usage, reservation, time.Since(start))
+	}()
+	var toolCalls *streamToolCalls
+	if !req.StreamPassthrough {
+		toolCalls = &streamToolCalls{}
+	}
+	var held []*domain.StreamResponse
+	var created int64
+	sent := false
 	for {
 		select {
 		case response, ok := <-streamChan:
 			if !ok {
-				s.circuitBreaker.RecordSuccess(provider)
-				return nil
+				ended = true
+				writeStreamEvents(c, held)
+				if !s.writeStreamToolCalls(ctx, c, req, provider, toolCalls, finish, created) {
+					return false, nil
+				}
+				writeStreamEnd(c, finish.event(req, provider, created, usage.reported))
+				outcome = LimiterOutcomeSuccess
+				s.recordStreamTermination(ctx, req, provider, domain.StreamOutcomeCompleted, nil)
+				return false, nil
 			}
 
 			if response.Error != nil {
-				s.circuitBreaker.RecordFailure(provider)
-				errorData := map[string]interface{}{
-					"error": response.Error.PublicError(),
+				// Provider clients surface read errors when the caller goes away
+				if s.isStreamCancelled(ctx, response.Error) {
+					s.recordStreamTermination(ctx, req, provider, domain.StreamOutcomeCancelled, response.Error)
+					return false, nil
 				}
-				data, _ := json.Marshal(errorData)
-				c.Writer.Write([]byte(fmt.Sprintf("data: %s\n\n", data)))
-				c.Writer.Flush()
-				return nil
+
+				outcome = limiterOutcome(ctx, response.Error)
+				s.recordStreamTermination(ctx, req, provider, domain.StreamOutcomeProviderError, response.Error)
+				if failover && !sent && s.failsOverStream(ctx, response.Error, opened) {
+					return true, response.Error
+				}
+				writeStreamEvents(c, held)
+				s.deadLetterCompletion(ctx, deadLetterOperationCompletionStream, req, response.Error)
+				writeStreamError(c, response.Error)
+				return false, nil
+			}
+
+			usage.observe(response)
+			s.normalizeChunkCreated(provider, response, &created)
+			finish.observe(response)
+			finish.firstContent(response, provider, req.Model, requested)
+			if toolCalls != nil {
+				toolCalls.take(response)
 			}
 
 			if response.Done {
-				c.Writer.Write([]byte("data: [DONE]\n\n"))
-				c.Writer.Flush()
-				s.circuitBreaker.RecordSuccess(provider)
-				return nil
+				ended = true
+				writeStreamEvents(c, held)
+				if !s.writeStreamToolCalls(ctx, c, req, provider, toolCalls, finish, created) {
+					return false, nil
+				}
+				// Usage reported with the end of the stream goes out in the
+				// final event, with the finish reasons
+				writeStreamEnd(c, finish.event(req, provider, created, usage.reported))
+				outcome = LimiterOutcomeSuccess
+				s.recordStreamTermination(ctx, req, provider, domain.StreamOutcomeCompleted, nil)
+				return false, nil
+			}
+
+			if failover && !sent && !hasStreamContent(response) {
+				held = append(held, response)
+				continue
 			}
+			writeStreamEvents(c, held)
+			held = nil
+			sent = true
 
 			data, _ := json.Marshal(response)
 			c.Writer.Write([]byte(fmt.Sprintf("data: %s\n\n", data)))
 			c.Writer.Flush()
 
 		case <-ctx.Done():
-			return ctx.Err()
+			if s.isStreamCancelled(ctx, ctx.Err()) {
+				s.recordStreamTermination(ctx, req, provider, domain.StreamOutcomeCancelled, ctx.Err())
+				return false, nil
+			}
+			s.recordStreamTermination(ctx, req, provider, domain.StreamOutcomeProviderError, ctx.Err())
+			return false, shared_errors.TimeoutError("completion stream", 0)
+		}
+	}
+}
+
+// isStreamCancelled reports whether a stream ended because the client went
+// away rather than because the provider failed
+func (s *Service) isStreamCancelled(ctx context.Context, err error) bool {
+	return errors.Is(ctx.Err(), context.Canceled) || shared_errors.IsCancellation(err)
+}
+
+// recordStreamTermination updates the circuit breaker and metrics for a
+// finished stream. Client cancellations never count as provider failures.
+func (s *Service) recordStreamTermination(ctx context.Context, req *domain.CompletionRequest, provider domain.Provider, result domain.StreamOutcome, err error) {
+	streamTerminations.WithLabelValues(string(provider), string(result)).Inc()
+
+	switch result {
+	case domain.StreamOutcomeCompleted:
+		if !isTenantScoped(ctx) {
+			s.circuitBreaker.RecordSuccess(provider)
+		}
+		s.recordStreamStats(ctx, provider, nil)
+	case domain.StreamOutcomeProviderError:
+		recordAttempt(ctx, provider, req.Model, 1, err)
+		if !isTenantScoped(ctx) {
+			s.circuitBreaker.RecordFailure(provider)
 		}
+		s.recordStreamStats(ctx, provider, err)
+		s.logger.Error("Completion stream failed",
+			logger.F("provider", provider),
+			logger.F("model", req.Model),
+			logger.F("request_id", req.RequestID),
+			logger.F("error", err))
+	case domain.StreamOutcomeCancelled:
+		s.logger.Info("Completion stream cancelled by client",
+			logger.F("provider", provider),
+			logger.F("model", req.Model),
+			logger.F("request_id", req.RequestID))
 	}
 }
 
 func (s *Service) routeEmbedding(ctx context.Context, req *domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
+	if err := validateEmbeddingRepresentations(req); err != nil {
+		return nil, err
+	}
+	deprecation := s.applyEmbeddingModelDeprecation(req)
+
+	// With cost routing the request goes to the best scoring route instead
+	routes, err := s.rankEmbeddingRoutes(req)
+	if err != nil {
+		return nil, err
+	}
+	var response *domain.EmbeddingResponse
+	if len(routes) > 0 {
+		response, err = s.routeEmbeddingRoutes(ctx, req, routes)
+	} else {
+		response, err = s.routeEmbeddingModel(ctx, req)
+	}
+	if err != nil && isUnavailable(err) && req.Model != domain.EmbeddingModelAuto {
+		response, err = s.routeEmbeddingFallback(ctx, req, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := deriveEmbeddingRepresentations(req, response); err != nil {
+		return nil, err
+	}
+	setEmbeddingModelDeprecation(response, deprecation)
+	return response, nil
+}
+
+// routeEmbeddingModel sends an embedding request to a provider serving the
+// requested model
+func (s *Service) routeEmbeddingModel(ctx context.Context, req *domain.EmbeddingRequest) (*domain.EmbeddingResponse, error) {
 	// Select provider
-	provider, err := s.selectProvider(req.Model, req.Provider)
+	provider, err := s.selectProvider(req.TenantID, req.Model, req.Provider, req.DataResidency)
 	if err != nil {
 		return nil, err
 	}
 
-	// Check circuit breaker
-	if !s.circuitBreaker.CanExecute(provider) {
-		return nil, shared_errors.ProviderUnavailableError(string(provider))
+	ctx, client, release, err := s.resolveClient(ctx, req.TenantID, provider)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
-	// Route to provider with retry logic
-	client := s.providerClients[provider]
-	result, err := s.executeWithRetry(ctx, func() (interface{}, error) {
-		return client.CreateEmbeddings(ctx, req)
-	}, provider)
-	
+	// Check circuit breaker; a tenant's own account is not gated by it
+	if !isTenantScoped(ctx) && !s.circuitBreaker.CanExecute(provider) {
+		err := shared_errors.ProviderUnavailableError(string(provider))
+		recordAttempt(ctx, provider, req.Model, 0, err)
+		return nil, err
+	}
+
+	// Check budget compliance before making expensive API call
+	reservation, err := s.reserveBudget(ctx, req.TenantID, s.estimateEmbeddingCost(provider, req))
 	if err != nil {
 		return nil, err
 	}
-	
-	response := result.(*domain.EmbeddingResponse)
 
-	s.circuitBreaker.RecordSuccess(provider)
+	// Route to provider with retry logic, in batches if the request is large
+	start := time.Now()
+	response, err := s.createEmbeddingBatches(ctx, client, provider, req)
+	if err != nil {
+		s.costService.ReleaseReservation(reservation)
+		return nil, err
+	}
+
+	if !isTenantScoped(ctx) {
+		s.circuitBreaker.RecordSuccess(provider)
+	}
+
+	if err := s.trackEmbeddingCost(ctx, req, response, provider, time.Since(start), reservation); err != nil {
+		s.logger.Warn("Failed to track embedding cost", logger.F("error", err))
+	}
 	return response, nil
 }
 
-func (s *Service) selectProvider(modelID string, preferredProvider domain.Provider) (domain.Provider, error) {
+// selectProvider picks the provider serving the model for the tenant, the
+// preferred one if set. A data-residency region restricts the choice to
+// providers serving from it, and is never given up for another provider.
+func (s *Service) selectProvider(tenantID domain.TenantID, modelID string, preferredProvider domain.Provider, region string) (domain.Provider, error) {
+	if preferredProvider == domain.ProviderAuto {
+		provider, _, err := s.selectAutoProvider(tenantID, modelID, region)
+		return provider, err
+	}
+
 	// If provider is specified, validate and use it
 	if preferredProvider != "" {
-		if _, exists := s.providerClients[preferredProvider]; !exists {
+		if _, exists := s.providerClient(preferredProvider); !exists || !s.providerEnabled(preferredProvider) {
 			return "", shared_errors.ValidationError("invalid provider", "provider")
 		}
+		if err := s.checkProviderEntitlement(tenantID, preferredProvider); err != nil {
+			return "", err
+		}
+		if err := s.checkDataResidency(preferredProvider, modelID, region); err != nil {
+			return "", err
+		}
+		if err := s.checkKillSwitch(preferredProvider, modelID); err != nil {
+			return "", err
+		}
 		return preferredProvider, nil
 	}
 
-	// Find providers that support the model
+	// A model ID several providers expose needs a provider or a default
+	// order to pick one
+	if err := s.checkModelAmbiguity(tenantID, modelID, region); err != nil {
+		return "", err
+	}
+
+	// Find the providers serving the model that the tenant may use
+	supportedProviders, err := s.entitledProviders(tenantID, modelID, region)
+	if err != nil {
+		return "", err
+	}
+
+	// Prefer the configured default order when several providers qualify
+	if len(supportedProviders) > 1 {
+		if provider, ok := s.preferredByDefaultOrder(supportedProviders); ok {
+			return provider, nil
+		}
+	}
+
+	// Use load balancer to select provider
+	return s.loadBalancer.SelectProvider(supportedProviders), nil
+}
+
+// preferredByDefaultOrder returns the first provider in the configured
+// DefaultProviderOrder that is present among the candidates
+func (s *Service) preferredByDefaultOrder(candidates []domain.Provider) (domain.Provider, bool) {
+	for _, preferred := range s.currentConfig().DefaultProviderOrder {
+		for _, candidate := range candidates {
+			if candidate == preferred {
+				return candidate, true
+			}
+		}
+	}
+	return "", false
+}
+
+// eligibleProviders returns the enabled, routable providers serving the model
+func (s *Service) eligibleProviders(modelID string) []domain.Provider {
 	supportedProviders := []domain.Provider{}
-	
+	window := s.currentConfig().PassiveHealth.Window
+	now := time.Now()
+
 	s.mu.RLock()
 	for provider, config := range s.providerConfigs {
-		if !config.Enabled || config.HealthStatus != domain.ProviderHealthHealthy {
+		if !providerRoutable(config, window, now) {
 			continue
 		}
-		
+
 		// Check if provider supports the model
 		if s.providerSupportsModel(provider, modelID) {
 			supportedProviders = append(supportedProviders, provider)
@@ -621,60 +1170,72 @@ func (s *Service) selectProvider(modelID string, preferredProvider domain.Provid
 	}
 	s.mu.RUnlock()
 
-	if len(supportedProviders) == 0 {
-		return "", shared_errors.ValidationError("no providers support the specified model", "model")
-	}
+	return supportedProviders
+}
 
-	// Use load balancer to select provider
-	return s.loadBalancer.SelectProvider(supportedProviders), nil
+// registerModel adds a provider's model to the registry, at the configured
+// pricing when there is one. Models are keyed by provider as well as ID, so
+// providers exposing the same model ID are all kept and routable.
+func (s *Service) registerModel(model *domain.Model) {
+	if s.modelRegistry[model.ModelID] == nil {
+		s.modelRegistry[model.ModelID] = make(map[domain.Provider]*domain.Model)
+	}
+	s.modelRegistry[model.ModelID][model.Provider] = s.priceModel(model, s.currentConfig().ModelPricing)
 }
 
 func (s *Service) providerSupportsModel(provider domain.Provider, modelID string) bool {
-	// Check if the provider supports this model
-	// This would typically check against the model registry
-	model, exists := s.modelRegistry[modelID]
-	if !exists {
-		return false
-	}
-	
-	return model.Provider == provider
+	_, exists := s.modelRegistry[modelID][provider]
+	return exists
 }
 
 func (s *Service) listModels(opts *domain.ListModelsOptions) []domain.Model {
 	models := []domain.Model{}
 	
-	for _, model := range s.modelRegistry {
-		// Filter by provider
-		if opts.Provider != "" && model.Provider != opts.Provider {
-			continue
-		}
-		
-		// Filter by capability
-		if opts.Capability != "" {
-			hasCapability := false
-			for _, cap := range model.Capabilities {
-				if cap == opts.Capability {
-					hasCapability = true
-					break
-				}
-			}
-			if !hasCapability {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, providers := range s.modelRegistry {
+		for _, model := range providers {
+			// Filter by provider
+			if opts.Provider != "" && model.Provider != opts.Provider {
 				continue
 			}
+			
+			// Filter by capability
+			if opts.Capability != "" {
+				hasCapability := false
+				for _, cap := range model.Capabilities {
+					if cap == opts.Capability {
+						hasCapability = true
+						break
+					}
+				}
+				if !hasCapability {
+					continue
+				}
+			}
+			
+			models = append(models, *model)
 		}
-		
-		models = append(models, *model)
 	}
 	
+	// Models served by several providers are listed once per provider
+	sort.Slice(models, func(i, j int) bool {
+		if models[i].ModelID != models[j].ModelID {
+			return models[i].ModelID < models[j].ModelID
+		}
+		return models[i].Provider < models[j].Provider
+	})
 	return models
 }
 
 func (s *Service) generateHealthResponse() *domain.HealthResponse {
 	response := &domain.HealthResponse{
-		Status:    "healthy",
-		Timestamp: time.Now(),
-		Services:  make(map[string]domain.ServiceHealth),
-		Providers: make(map[string]domain.ProviderHealth),
+		Status:          "healthy",
+		Timestamp:       time.Now(),
+		Services:        make(map[string]domain.ServiceHealth),
+		Providers:       make(map[string]domain.ProviderHealth),
+		RegistryVersion: s.currentRegistryVersion(),
 	}
 
 	// Check provider health
@@ -685,7 +1246,10 @@ func (s *Service) generateHealthResponse() *domain.HealthResponse {
 		health := domain.ProviderHealth{
 			Status:    string(config.HealthStatus),
 			Latency:   int64(config.Latency),
-			ErrorRate: config.ErrorRate,
+			ErrorRate: s.providerStats.ErrorRate(provider),
+		}
+		if latency, ok := s.providerStats.Latency(provider); ok {
+			health.Latency = int64(latency)
 		}
 		
 		response.Providers[string(provider)] = health
@@ -709,14 +1273,53 @@ func (s *Service) generateHealthResponse() *domain.HealthResponse {
 func (s *Service) generateCacheKey(tenantID domain.TenantID, req *domain.CompletionRequest) string {
 	// Create a hash of the request for caching
 	// FIXED: Include tenant ID to prevent cross-tenant data leakage
-	data := fmt.Sprintf("%s:%s:%v:%v:%v:%s", 
-		tenantID, req.Model, req.Messages, req.Temperature, req.MaxTokens, req.User)
-	
-	hash := sha256.Sum256([]byte(data))
+	// Hash the JSON form so pointer fields contribute their values, not their
+	// addresses, and every field that changes the output is part of the key
+	data, _ := json.Marshal(struct {
+		TenantID         domain.TenantID        `json:"tenant_id"`
+		Provider         domain.Provider        `json:"provider,omitempty"`
+		Model            string                 `json:"model"`
+		Messages         []domain.Message       `json:"messages"`
+		Tools            []domain.Tool          `json:"tools,omitempty"`
+		MaxTokens        *int                   `json:"max_tokens,omitempty"`
+		Temperature      *float64               `json:"temperature,omitempty"`
+		TopP             *float64               `json:"top_p,omitempty"`
+		Stop             []string               `json:"stop,omitempty"`
+		PresencePenalty  *float64               `json:"presence_penalty,omitempty"`
+		FrequencyPenalty *float64               `json:"frequency_penalty,omitempty"`
+		ResponseFormat   *domain.ResponseFormat `json:"response_format,omitempty"`
+		User             string                 `json:"user,omitempty"`
+		DataResidency    string                 `json:"data_residency,omitempty"`
+		VerboseUsage     bool                   `json:"verbose_usage,omitempty"`
+		Store            *bool                  `json:"store,omitempty"`
+		AzureAPIVersion  string                 `json:"azure_api_version,omitempty"`
+		AnthropicVersion string                 `json:"anthropic_version,omitempty"`
+	}{
+		TenantID:         tenantID,
+		Provider:         req.Provider,
+		Model:            req.Model,
+		Messages:         req.Messages,
+		Tools:            req.Tools,
+		MaxTokens:        req.MaxTokens,
+		Temperature:      req.Temperature,
+		TopP:             req.TopP,
+		Stop:             req.Stop,
+		PresencePenalty:  req.PresencePenalty,
+		FrequencyPenalty: req.FrequencyPenalty,
+		ResponseFormat:   req.ResponseFormat,
+		User:             req.User,
+		DataResidency:    req.DataResidency,
+		VerboseUsage:     req.VerboseUsage,
+		Store:            req.Store,
+		AzureAPIVersion:  req.AzureAPIVersion,
+		AnthropicVersion: req.AnthropicVersion,
+	})
+
+	hash := sha256.Sum256(data)
 	return hex.EncodeToString(hash[:])
 }
 
-func (s *Service) executeWithRetry(ctx context.Context, fn func() (interface{}, error), provider domain.Provider) (interface{}, error) {
+func (s *Service) executeWithRetry(ctx context.Context, fn func() (interface{}, error), provider domain.Provider, model string) (interface{}, error) {
 	var result interface{}
 	var lastErr error
 
@@ -733,13 +1336,27 @@ func (s *Service) executeWithRetry(ctx context.Context, fn func() (interface{},
 			}
 		}
 
+		if !s.concurrency.Acquire(provider) {
+			lastErr = concurrencyLimitError(provider)
+			recordAttempt(ctx, provider, model, attempt+1, lastErr)
+			s.logger.Warn("Provider concurrency limit reached",
+				logger.F("provider", provider),
+				logger.F("limit", s.concurrency.Limit(provider)),
+				logger.F("attempt", attempt+1))
+			continue
+		}
+
+		started := time.Now()
 		result, lastErr = fn()
+		s.concurrency.Release(provider, limiterOutcome(ctx, lastErr))
+		s.recordProviderStats(ctx, provider, time.Since(started), lastErr)
 		if lastErr == nil {
 			return result, nil
 		}
+		recordAttempt(ctx, provider, model, attempt+1, lastErr)
 
 		// Check if error is retryable
-		if qlensErr, ok := lastErr.(*shared_errors.QLensError); ok && !qlensErr.Retryable {
+		if !s.retryPolicy().RetryableError(lastErr) {
 			break
 		}
 
@@ -752,6 +1369,34 @@ func (s *Service) executeWithRetry(ctx context.Context, fn func() (interface{},
 	return result, lastErr
 }
 
+// retryPolicy decides which failed provider calls executeWithRetry retries
+func (s *Service) retryPolicy() shared_errors.RetryPolicy {
+	config := s.currentConfig()
+	return shared_errors.RetryPolicy{StatusCodes: config.RetryStatusCodes, ErrorTypes: config.RetryErrorTypes}
+}
+
+// concurrencyLimitError is returned when a provider has no free concurrency slots
+func concurrencyLimitError(provider domain.Provider) *shared_errors.QLensError {
+	return shared_errors.NewError(shared_errors.ErrorTypeTooManyRequests, fmt.Sprintf("provider %s concurrency limit reached", provider)).
+		WithDetail("provider", string(provider)).
+		WithSeverity(shared_errors.SeverityMedium).
+		WithRetryable(true).
+		Build()
+}
+
+// classifyLimiterOutcome maps a provider call result onto an AIMD signal
+func classifyLimiterOutcome(err error) LimiterOutcome {
+	if err == nil {
+		return LimiterOutcomeSuccess
+	}
+	if shared_errors.IsType(err, shared_errors.ErrorTypeTooManyRequests) ||
+		shared_errors.IsType(err, shared_errors.ErrorTypeTimeout) ||
+		errors.Is(err, context.DeadlineExceeded) {
+		return LimiterOutcomeOverload
+	}
+	return LimiterOutcomeIgnore
+}
+
 func (s *Service) respondWithError(c *gin.Context, err error) {
 	var qlensErr *shared_errors.QLensError
 	if !errors.As(err, &qlensErr) {
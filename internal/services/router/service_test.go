@@ -2,7 +2,6 @@ package router
 
 import (
 	"bytes"
-	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -19,7 +18,7 @@ func TestNewRouterService(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-router",
-		Port:        "8081",
+		Port:        8081,
 		Logging: env.LoggingConfig{
 			Level:      "debug",
 			Format:     "json",
@@ -28,9 +27,7 @@ func TestNewRouterService(t *testing.T) {
 		Providers: map[string]env.ProviderConfig{
 			"openai": {
 				Enabled: true,
-				Config: map[string]interface{}{
-					"api_key": "test-key",
-				},
+				APIKey: "test-key",
 			},
 		},
 	}
@@ -48,7 +45,7 @@ func TestRouterServiceHealthCheck(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-router",
-		Port:        "8081",
+		Port:        8081,
 		Logging: env.LoggingConfig{
 			Level:      "error",
 			Format:     "json",
@@ -57,9 +54,7 @@ func TestRouterServiceHealthCheck(t *testing.T) {
 		Providers: map[string]env.ProviderConfig{
 			"openai": {
 				Enabled: true,
-				Config: map[string]interface{}{
-					"api_key": "test-key",
-				},
+				APIKey: "test-key",
 			},
 		},
 	}
@@ -78,7 +73,7 @@ func TestRouterServiceHealthCheck(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-	var healthResp HealthResponse
+	var healthResp domain.HealthResponse
 	err = json.NewDecoder(resp.Body).Decode(&healthResp)
 	require.NoError(t, err)
 
@@ -90,7 +85,7 @@ func TestRouterServiceReadiness(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-router",
-		Port:        "8081",
+		Port:        8081,
 		Logging: env.LoggingConfig{
 			Level:      "error",
 			Format:     "json",
@@ -99,9 +94,7 @@ func TestRouterServiceReadiness(t *testing.T) {
 		Providers: map[string]env.ProviderConfig{
 			"openai": {
 				Enabled: true,
-				Config: map[string]interface{}{
-					"api_key": "test-key",
-				},
+				APIKey: "test-key",
 			},
 		},
 	}
@@ -131,7 +124,7 @@ func TestRouterServiceListModels(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-router",
-		Port:        "8081",
+		Port:        8081,
 		Logging: env.LoggingConfig{
 			Level:      "error",
 			Format:     "json",
@@ -140,9 +133,7 @@ func TestRouterServiceListModels(t *testing.T) {
 		Providers: map[string]env.ProviderConfig{
 			"openai": {
 				Enabled: true,
-				Config: map[string]interface{}{
-					"api_key": "test-key",
-				},
+				APIKey: "test-key",
 			},
 		},
 	}
@@ -161,7 +152,7 @@ func TestRouterServiceListModels(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 
-	var modelsResp ModelsResponse
+	var modelsResp domain.ModelsResponse
 	err = json.NewDecoder(resp.Body).Decode(&modelsResp)
 	require.NoError(t, err)
 
@@ -173,7 +164,7 @@ func TestRouterServiceCompletionRequest(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-router",
-		Port:        "8081",
+		Port:        8081,
 		Logging: env.LoggingConfig{
 			Level:      "error",
 			Format:     "json",
@@ -182,9 +173,7 @@ func TestRouterServiceCompletionRequest(t *testing.T) {
 		Providers: map[string]env.ProviderConfig{
 			"openai": {
 				Enabled: true,
-				Config: map[string]interface{}{
-					"api_key": "test-key",
-				},
+				APIKey: "test-key",
 			},
 		},
 	}
@@ -197,7 +186,7 @@ func TestRouterServiceCompletionRequest(t *testing.T) {
 	server := httptest.NewServer(service.Handler())
 	defer server.Close()
 
-	reqBody := CompletionRequest{
+	reqBody := domain.CompletionRequest{
 		TenantID: domain.TenantID("test-tenant"),
 		UserID:   domain.UserID("test-user"),
 		Model:    "gpt-35-turbo",
@@ -237,7 +226,7 @@ func TestRouterServiceProviderSelection(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-router",
-		Port:        "8081",
+		Port:        8081,
 		Logging: env.LoggingConfig{
 			Level:      "error",
 			Format:     "json",
@@ -246,9 +235,7 @@ func TestRouterServiceProviderSelection(t *testing.T) {
 		Providers: map[string]env.ProviderConfig{
 			"openai": {
 				Enabled: true,
-				Config: map[string]interface{}{
-					"api_key": "test-key",
-				},
+				APIKey: "test-key",
 			},
 		},
 	}
@@ -259,7 +246,7 @@ func TestRouterServiceProviderSelection(t *testing.T) {
 	defer service.Close()
 
 	// Test selectProvider method
-	provider, err := service.selectProvider("gpt-4", domain.ProviderOpenAI)
+	provider, err := service.selectProvider("tenant-a", "gpt-4", domain.ProviderOpenAI, "")
 	if err == nil {
 		assert.Equal(t, domain.ProviderOpenAI, provider)
 	} else {
@@ -272,7 +259,7 @@ func TestRouterServiceGenerateCacheKey(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-router",
-		Port:        "8081",
+		Port:        8081,
 		Logging: env.LoggingConfig{
 			Level:      "error",
 			Format:     "json",
@@ -281,9 +268,7 @@ func TestRouterServiceGenerateCacheKey(t *testing.T) {
 		Providers: map[string]env.ProviderConfig{
 			"openai": {
 				Enabled: true,
-				Config: map[string]interface{}{
-					"api_key": "test-key",
-				},
+				APIKey: "test-key",
 			},
 		},
 	}
@@ -294,7 +279,7 @@ func TestRouterServiceGenerateCacheKey(t *testing.T) {
 	defer service.Close()
 
 	tenantID := domain.TenantID("test-tenant")
-	req := &CompletionRequest{
+	req := &domain.CompletionRequest{
 		Model: "gpt-4",
 		Messages: []domain.Message{
 			{
@@ -334,7 +319,7 @@ func TestRouterServiceNoProvidersEnabled(t *testing.T) {
 	config := &env.Config{
 		Environment: env.EnvironmentDevelopment,
 		ServiceName: "test-router",
-		Port:        "8081",
+		Port:        8081,
 		Logging: env.LoggingConfig{
 			Level:      "error",
 			Format:     "json",
@@ -343,9 +328,7 @@ func TestRouterServiceNoProvidersEnabled(t *testing.T) {
 		Providers: map[string]env.ProviderConfig{
 			"openai": {
 				Enabled: false,
-				Config: map[string]interface{}{
-					"api_key": "test-key",
-				},
+				APIKey: "test-key",
 			},
 		},
 	}
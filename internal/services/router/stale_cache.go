@@ -0,0 +1,70 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// staleCacheKey is the key of the copy of a cached completion that outlives
+// it by the stale grace period. The fresh entry keeps its own TTL, so cache
+// hits are never stale.
+func staleCacheKey(cacheKey string) string {
+	return "stale:" + cacheKey
+}
+
+// cacheStaleCopy keeps a cached completion for its TTL plus the stale grace
+// period, when stale answers are enabled
+func (s *Service) cacheStaleCopy(ctx context.Context, tenantID domain.TenantID, cacheKey string, data []byte, ttl time.Duration) {
+	grace := s.currentConfig().Cache.StaleGrace
+	if grace <= 0 {
+		return
+	}
+	if err := s.cache.Set(ctx, tenantID, staleCacheKey(cacheKey), data, ttl+grace); err != nil {
+		s.logger.Warn("Failed to cache stale copy of completion",
+			logger.F("tenant_id", tenantID),
+			logger.F("error", err))
+	}
+}
+
+// staleOrError answers a request that opted in to stale answers with its
+// cached completion, even past the cache TTL, when providers failed to serve
+// it. The response is tagged stale in its metadata. Errors other than
+// provider failures, and requests with nothing cached, get the error.
+func (s *Service) staleOrError(ctx context.Context, req *domain.CompletionRequest, err error) (*domain.CompletionResponse, error) {
+	if !req.StaleOnError || req.IncludeRawResponse || s.cache == nil || s.currentConfig().Cache.StaleGrace <= 0 {
+		return nil, err
+	}
+	if ctx.Err() != nil || !isProviderFailure(err) {
+		return nil, err
+	}
+
+	data, found, cacheErr := s.cache.Get(ctx, req.TenantID, staleCacheKey(s.generateCacheKey(req.TenantID, req)))
+	if cacheErr != nil || !found {
+		return nil, err
+	}
+	var response domain.CompletionResponse
+	if json.Unmarshal(data, &response) != nil {
+		return nil, err
+	}
+
+	s.logger.Warn("Providers failed, serving stale cached completion",
+		logger.F("tenant_id", req.TenantID),
+		logger.F("model", req.Model),
+		logger.F("request_id", req.RequestID),
+		logger.F("error", err))
+	completionCacheRequests.WithLabelValues("stale").Inc()
+
+	// No provider answered, so the response costs nothing
+	response.Usage.CacheHit = true
+	response.Usage.CostUSD = 0
+	response.ReceivedAt = time.Now().UTC()
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]interface{})
+	}
+	response.Metadata[domain.MetadataKeyStale] = true
+	return &response, nil
+}
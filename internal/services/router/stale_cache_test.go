@@ -0,0 +1,115 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// ttlCacheClient keeps entries in a map and records their TTLs
+type ttlCacheClient struct {
+	values map[string][]byte
+	ttls   map[string]time.Duration
+}
+
+func newTTLCacheClient() *ttlCacheClient {
+	return &ttlCacheClient{values: make(map[string][]byte), ttls: make(map[string]time.Duration)}
+}
+
+func (c *ttlCacheClient) Get(ctx context.Context, tenantID domain.TenantID, key string) ([]byte, bool, error) {
+	value, found := c.values[tenantCacheKey(tenantID, key)]
+	return value, found, nil
+}
+
+func (c *ttlCacheClient) Set(ctx context.Context, tenantID domain.TenantID, key string, value []byte, ttl time.Duration) error {
+	c.values[tenantCacheKey(tenantID, key)] = value
+	c.ttls[tenantCacheKey(tenantID, key)] = ttl
+	return nil
+}
+
+// outageProviderClient answers until the outage starts
+type outageProviderClient struct {
+	countingProviderClient
+	err error
+}
+
+func (c *outageProviderClient) CreateCompletion(ctx context.Context, req *domain.CompletionRequest) (*domain.CompletionResponse, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.countingProviderClient.CreateCompletion(ctx, req)
+}
+
+func TestRouteCompletion_ServesStaleCacheDuringOutage(t *testing.T) {
+	client := &outageProviderClient{}
+	cacheClient := newTTLCacheClient()
+	service := newCacheTestService(client, cacheClient)
+	service.config.Cache.StaleGrace = time.Hour
+
+	_, err := service.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+
+	// The stale copy outlives the fresh entry by the grace period
+	key := service.generateCacheKey("tenant-a", newCacheTestRequest("tenant-a"))
+	assert.Equal(t, time.Minute, cacheClient.ttls[tenantCacheKey("tenant-a", key)])
+	assert.Equal(t, time.Minute+time.Hour, cacheClient.ttls[tenantCacheKey("tenant-a", staleCacheKey(key))])
+
+	// The fresh entry expires, then the provider goes down
+	delete(cacheClient.values, tenantCacheKey("tenant-a", key))
+	client.err = shared_errors.ProviderError("openai", "overloaded", nil).WithProviderStatus(http.StatusServiceUnavailable)
+
+	req := newCacheTestRequest("tenant-a")
+	req.StaleOnError = true
+	response, err := service.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, "resp-1", response.ID)
+	assert.Equal(t, true, response.Metadata[domain.MetadataKeyStale])
+	assert.True(t, response.Usage.CacheHit)
+	assert.Zero(t, response.Usage.CostUSD)
+
+	// Requests that did not opt in get the error
+	_, err = service.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.Error(t, err)
+}
+
+func TestRouteCompletion_StaleCacheOnlyForProviderFailures(t *testing.T) {
+	client := &outageProviderClient{}
+	cacheClient := newTTLCacheClient()
+	service := newCacheTestService(client, cacheClient)
+	service.config.Cache.StaleGrace = time.Hour
+
+	_, err := service.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	key := service.generateCacheKey("tenant-a", newCacheTestRequest("tenant-a"))
+	delete(cacheClient.values, tenantCacheKey("tenant-a", key))
+
+	// A request the provider rejects would be rejected by any provider
+	client.err = shared_errors.ValidationError("bad request", "messages")
+	req := newCacheTestRequest("tenant-a")
+	req.StaleOnError = true
+	_, err = service.routeCompletion(context.Background(), req)
+	require.Error(t, err)
+
+	// Nothing cached for another tenant
+	client.err = shared_errors.ProviderError("openai", "overloaded", nil).WithProviderStatus(http.StatusServiceUnavailable)
+	req = newCacheTestRequest("tenant-b")
+	req.StaleOnError = true
+	_, err = service.routeCompletion(context.Background(), req)
+	require.Error(t, err)
+}
+
+func TestRouteCompletion_NoStaleCopyWithoutGrace(t *testing.T) {
+	cacheClient := newTTLCacheClient()
+	service := newCacheTestService(&outageProviderClient{}, cacheClient)
+
+	_, err := service.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	assert.Len(t, cacheClient.values, 1)
+}
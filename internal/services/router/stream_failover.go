@@ -0,0 +1,99 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// nextStreamProvider returns the provider a stream fails over to if the
+// providers in tried fail before sending content. Requests pinned to a
+// provider never fail over, and neither does anything while the failover
+// grace window is disabled.
+func (s *Service) nextStreamProvider(req *domain.CompletionRequest, tried []domain.Provider) (domain.Provider, bool) {
+	if s.currentConfig().StreamFailoverGrace <= 0 {
+		return "", false
+	}
+	if req.Provider != "" && req.Provider != domain.ProviderAuto {
+		return "", false
+	}
+
+	candidates, err := s.entitledProviders(req.TenantID, req.Model, req.DataResidency)
+	if err != nil {
+		return "", false
+	}
+	remaining := make([]domain.Provider, 0, len(candidates))
+	for _, candidate := range candidates {
+		if containsProvider(tried, candidate) || s.validatePrefill(req, candidate) != nil {
+			continue
+		}
+		remaining = append(remaining, candidate)
+	}
+	if len(remaining) == 0 {
+		return "", false
+	}
+
+	if provider, ok := s.preferredByDefaultOrder(remaining); ok {
+		return provider, true
+	}
+	return s.loadBalancer.SelectProvider(remaining), true
+}
+
+// failsOverStream reports whether a stream failure is one another provider
+// may do better with, and came soon enough after the stream opened that
+// the client is still waiting on its first token
+func (s *Service) failsOverStream(ctx context.Context, err error, opened time.Time) bool {
+	return ctx.Err() == nil && isProviderFailure(err) && time.Since(opened) <= s.currentConfig().StreamFailoverGrace
+}
+
+// hasStreamContent reports whether a chunk carries text or tool calls, after
+// which the stream can no longer move to another provider unnoticed
+func hasStreamContent(chunk *domain.StreamResponse) bool {
+	for _, choice := range chunk.Choices {
+		for _, part := range choice.Message.Content {
+			if part.Text != "" {
+				return true
+			}
+		}
+		if len(choice.Message.ToolCalls) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// writeStreamEvents sends chunks held back while the stream could still
+// fail over
+func writeStreamEvents(c *gin.Context, chunks []*domain.StreamResponse) {
+	if len(chunks) == 0 {
+		return
+	}
+	for _, chunk := range chunks {
+		data, _ := json.Marshal(chunk)
+		c.Writer.Write([]byte(fmt.Sprintf("data: %s\n\n", data)))
+	}
+	c.Writer.Flush()
+}
+
+// writeStreamError ends a stream whose event-stream headers are already set
+// with an error event, as the error can no longer go out as plain JSON with
+// its own status
+func writeStreamError(c *gin.Context, err *shared_errors.QLensError) {
+	data, _ := json.Marshal(map[string]interface{}{"error": err.PublicError()})
+	c.Writer.Write([]byte(fmt.Sprintf("data: %s\n\n", data)))
+	c.Writer.Flush()
+}
+
+func containsProvider(providers []domain.Provider, provider domain.Provider) bool {
+	for _, p := range providers {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
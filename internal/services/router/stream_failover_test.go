@@ -0,0 +1,137 @@
+package router
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// scriptedStreamClient streams a fixed sequence of chunks
+type scriptedStreamClient struct {
+	countingProviderClient
+	chunks  []*domain.StreamResponse
+	streams int
+}
+
+func (c *scriptedStreamClient) CreateCompletionStream(ctx context.Context, req *domain.CompletionRequest) (<-chan *domain.StreamResponse, error) {
+	c.streams++
+	ch := make(chan *domain.StreamResponse, len(c.chunks))
+	for _, chunk := range c.chunks {
+		ch <- chunk
+	}
+	close(ch)
+	return ch, nil
+}
+
+func textChunk(text string) *domain.StreamResponse {
+	return &domain.StreamResponse{Choices: []domain.Choice{{Message: domain.Message{
+		Role:    domain.MessageRoleAssistant,
+		Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: text}},
+	}}}}
+}
+
+func newStreamFailoverService(primary, secondary *scriptedStreamClient) *Service {
+	s := newCacheTestService(primary, nil)
+	s.config.StreamFailoverGrace = time.Minute
+	s.config.DefaultProviderOrder = []domain.Provider{domain.ProviderOpenAI, domain.ProviderAzureOpenAI}
+	s.providerConfigs[domain.ProviderAzureOpenAI] = &domain.ProviderConfig{Provider: domain.ProviderAzureOpenAI, Enabled: true}
+	s.providerClients[domain.ProviderAzureOpenAI] = secondary
+	s.modelRegistry = make(map[string]map[domain.Provider]*domain.Model)
+	s.registerModel(&domain.Model{ModelID: "gpt-4o", Provider: domain.ProviderOpenAI})
+	s.registerModel(&domain.Model{ModelID: "gpt-4o", Provider: domain.ProviderAzureOpenAI})
+	return s
+}
+
+func newStreamFailoverRequest() *domain.CompletionRequest {
+	req := newCacheTestRequest("tenant-a")
+	req.Provider = ""
+	req.Stream = true
+	return req
+}
+
+func TestRouteCompletionStream_FailsOverBeforeContent(t *testing.T) {
+	failure := shared_errors.ProviderUnavailableError("openai")
+	primary := &scriptedStreamClient{chunks: []*domain.StreamResponse{textChunk(""), {Error: failure}}}
+	secondary := &scriptedStreamClient{chunks: []*domain.StreamResponse{textChunk(""), textChunk("Hello"), {Done: true}}}
+	s := newStreamFailoverService(primary, secondary)
+
+	w, err := routeTestStream(t, s, newStreamFailoverRequest())
+	require.NoError(t, err)
+	assert.Equal(t, 1, primary.streams)
+	assert.Equal(t, 1, secondary.streams)
+
+	// The client sees one uninterrupted stream from the second provider
+	events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	require.Len(t, events, 4)
+	assert.Contains(t, events[1], "Hello")
+	assert.Contains(t, events[2], `"final":true`)
+	assert.Equal(t, "data: [DONE]", events[3])
+	assert.NotContains(t, w.Body.String(), `"error"`)
+}
+
+func TestRouteCompletionStream_FailoverErrorIsAnEvent(t *testing.T) {
+	failure := shared_errors.ProviderUnavailableError("openai")
+	primary := &scriptedStreamClient{chunks: []*domain.StreamResponse{{Error: failure}}}
+	s := newStreamFailoverService(primary, &scriptedStreamClient{})
+	s.providerClients[domain.ProviderAzureOpenAI] = &streamFailingProviderClient{
+		streamErr: shared_errors.ProviderUnavailableError("azure-openai"),
+	}
+
+	// The first stream set the event-stream headers, so the second
+	// provider's failure to open goes out as an event rather than JSON
+	w, err := routeTestStream(t, s, newStreamFailoverRequest())
+	require.NoError(t, err)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	body := strings.TrimSpace(w.Body.String())
+	assert.True(t, strings.HasPrefix(body, "data: "), body)
+	assert.Contains(t, body, `"error"`)
+	assert.Contains(t, body, "azure-openai")
+}
+
+func TestRouteCompletionStream_NoFailoverAfterContent(t *testing.T) {
+	failure := shared_errors.ProviderUnavailableError("openai")
+	primary := &scriptedStreamClient{chunks: []*domain.StreamResponse{textChunk("Hel"), {Error: failure}}}
+	secondary := &scriptedStreamClient{}
+	s := newStreamFailoverService(primary, secondary)
+
+	w, err := routeTestStream(t, s, newStreamFailoverRequest())
+	require.NoError(t, err)
+	assert.Zero(t, secondary.streams)
+	assert.Contains(t, w.Body.String(), "Hel")
+	assert.Contains(t, w.Body.String(), `"error"`)
+}
+
+func TestRouteCompletionStream_FailoverRequiresUnpinnedProviderAndGrace(t *testing.T) {
+	failure := shared_errors.ProviderUnavailableError("openai")
+	primary := &scriptedStreamClient{chunks: []*domain.StreamResponse{{Error: failure}}}
+	secondary := &scriptedStreamClient{}
+	s := newStreamFailoverService(primary, secondary)
+
+	pinned := newStreamFailoverRequest()
+	pinned.Provider = domain.ProviderOpenAI
+	w, err := routeTestStream(t, s, pinned)
+	require.NoError(t, err)
+	assert.Zero(t, secondary.streams)
+	assert.Contains(t, w.Body.String(), `"error"`)
+
+	s.config.StreamFailoverGrace = 0
+	_, err = routeTestStream(t, s, newStreamFailoverRequest())
+	require.NoError(t, err)
+	assert.Zero(t, secondary.streams)
+
+	// Rejected requests would be rejected again
+	s.config.StreamFailoverGrace = time.Minute
+	s.circuitBreaker = NewCircuitBreaker(logger.NewNoop())
+	primary.chunks = []*domain.StreamResponse{{Error: shared_errors.ValidationError("bad request", "messages")}}
+	_, err = routeTestStream(t, s, newStreamFailoverRequest())
+	require.NoError(t, err)
+	assert.Zero(t, secondary.streams)
+}
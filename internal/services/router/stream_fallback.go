@@ -0,0 +1,79 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/services/cost"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// fallsBackFromStream reports whether a stream the provider failed to open
+// should be retried without streaming. Only provider failures qualify; a
+// request the provider rejected would be rejected again.
+func (s *Service) fallsBackFromStream(ctx context.Context, err error) bool {
+	return s.currentConfig().StreamFallback && ctx.Err() == nil && isProviderFailure(err)
+}
+
+// completeStreamWithoutStreaming answers a stream the provider failed to
+// open with a non-streaming completion, written as a single event carrying
+// the whole response, then the final event with its usage and [DONE]. Clients keep the
+// server-sent events contract. An error is returned before anything is
+// written, so it still goes out as plain JSON with its own status. The
+// stream's budget reservation is settled by the completion or released.
+func (s *Service) completeStreamWithoutStreaming(ctx context.Context, req *domain.CompletionRequest, provider domain.Provider, client ProviderClient, finish *streamFinish, reservation *cost.BudgetReservation, c *gin.Context, streamErr error, start time.Time) (LimiterOutcome, error) {
+	s.logger.Warn("Provider failed to open stream, retrying without streaming",
+		logger.F("provider", provider),
+		logger.F("model", req.Model),
+		logger.F("request_id", req.RequestID),
+		logger.F("error", streamErr))
+	recordAttempt(ctx, provider, req.Model, 1, streamErr)
+	s.recordStreamStats(ctx, provider, streamErr)
+
+	// The completion's outcome is recorded with the stream's termination
+	completionReq := *req
+	completionReq.Stream = false
+
+	response, err := client.CreateCompletion(ctx, &completionReq)
+	if err != nil {
+		s.costService.ReleaseReservation(reservation)
+		streamFallbacks.WithLabelValues(string(provider), "error").Inc()
+		if s.isStreamCancelled(ctx, err) {
+			s.recordStreamTermination(ctx, req, provider, domain.StreamOutcomeCancelled, err)
+			return LimiterOutcomeIgnore, nil
+		}
+		s.recordStreamTermination(ctx, req, provider, domain.StreamOutcomeProviderError, err)
+		return limiterOutcome(ctx, err), err
+	}
+	streamFallbacks.WithLabelValues(string(provider), "success").Inc()
+	s.normalizeCreated(provider, response)
+
+	usage := response.Usage
+	event := &domain.StreamResponse{
+		ID:                response.ID,
+		Object:            "chat.completion.chunk",
+		Created:           response.Created,
+		Model:             response.Model,
+		Provider:          response.Provider,
+		Choices:           response.Choices,
+		ProviderRequestID: response.ProviderRequestID,
+	}
+	finish.observe(event)
+	data, _ := json.Marshal(event)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Writer.Write([]byte(fmt.Sprintf("data: %s\n\n", data)))
+	writeStreamEnd(c, finish.event(req, provider, response.Created, &usage))
+
+	s.recordStreamTermination(ctx, req, provider, domain.StreamOutcomeCompleted, nil)
+	s.recordPayloadSizes(ctx, req, provider, responseContentBytes(response), response.Usage)
+	if err := s.trackRequestCost(context.WithoutCancel(ctx), req, response, provider, time.Since(start), reservation); err != nil {
+		s.logger.Warn("Failed to track request cost", logger.F("error", err))
+	}
+	return LimiterOutcomeSuccess, nil
+}
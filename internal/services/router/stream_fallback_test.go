@@ -0,0 +1,73 @@
+package router
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// streamFailingProviderClient cannot open streams but completes requests
+type streamFailingProviderClient struct {
+	countingProviderClient
+	streamErr error
+}
+
+func (c *streamFailingProviderClient) CreateCompletionStream(ctx context.Context, req *domain.CompletionRequest) (<-chan *domain.StreamResponse, error) {
+	return nil, c.streamErr
+}
+
+func routeTestStream(t *testing.T, s *Service, req *domain.CompletionRequest) (*httptest.ResponseRecorder, error) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/internal/v1/completions/stream", nil)
+	err := s.routeCompletionStream(context.Background(), req, c)
+	return w, err
+}
+
+func TestRouteCompletionStream_FallsBackToCompletion(t *testing.T) {
+	client := &streamFailingProviderClient{streamErr: shared_errors.ProviderUnavailableError("openai")}
+	s := newCacheTestService(client, nil)
+	req := newCacheTestRequest("tenant-a")
+	req.Stream = true
+
+	// Without the flag the failure is returned as before
+	_, err := routeTestStream(t, s, req)
+	require.Error(t, err)
+	assert.Zero(t, client.calls)
+
+	s.config.StreamFallback = true
+	w, err := routeTestStream(t, s, req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, client.calls)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+	events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	require.Len(t, events, 3)
+	assert.Contains(t, events[0], `"object":"chat.completion.chunk"`)
+	assert.Contains(t, events[1], `"final":true`)
+	assert.Contains(t, events[1], `"total_tokens":15`)
+	assert.Equal(t, "data: [DONE]", events[2])
+	assert.Equal(t, int64(1), s.costService.GetGlobalUsage().RequestCount)
+}
+
+func TestRouteCompletionStream_NoFallbackForRejectedRequests(t *testing.T) {
+	client := &streamFailingProviderClient{streamErr: shared_errors.ValidationError("bad request", "messages")}
+	s := newCacheTestService(client, nil)
+	s.config.StreamFallback = true
+	req := newCacheTestRequest("tenant-a")
+	req.Stream = true
+
+	_, err := routeTestStream(t, s, req)
+	require.Error(t, err)
+	assert.Zero(t, client.calls)
+}
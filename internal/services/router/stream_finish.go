@@ -0,0 +1,154 @@
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// streamFinish gathers what the final event of a stream reports. Providers
+// send finish reasons in different places: OpenAI and Azure in the last
+// content chunk or an empty one after it, Bedrock in a message_delta event
+// of its own. Clients stopping at [DONE] get them all from the final event.
+type streamFinish struct {
+	id                string
+	model             string
+	providerRequestID string
+	reasons           map[int]domain.FinishReason
+	firstToken        time.Duration // Zero until content arrives
+	// warnings holds metadata the router attached to the request itself,
+	// such as a deprecated model, for clients that never see the headers
+	warnings map[string]interface{}
+}
+
+// newStreamFinish starts the final event of a stream, reporting the
+// request's deprecation and context window warnings in its metadata
+func newStreamFinish(deprecation *domain.ModelDeprecation, utilization float64, overContext bool) *streamFinish {
+	f := &streamFinish{}
+	if deprecation != nil {
+		f.warn(domain.MetadataKeyModelDeprecation, deprecation)
+	}
+	if overContext {
+		f.warn(domain.MetadataKeyContextUtilization, utilization)
+	}
+	return f
+}
+
+// contextUtilization returns the context window warning the final event
+// reports, if any
+func (f *streamFinish) contextUtilization() (float64, bool) {
+	utilization, ok := f.warnings[domain.MetadataKeyContextUtilization].(float64)
+	return utilization, ok
+}
+
+// warn adds metadata to the final event
+func (f *streamFinish) warn(key string, value interface{}) {
+	if f.warnings == nil {
+		f.warnings = make(map[string]interface{})
+	}
+	f.warnings[key] = value
+}
+
+// observe records a chunk received from the provider
+func (f *streamFinish) observe(chunk *domain.StreamResponse) {
+	if f.id == "" {
+		f.id = chunk.ID
+	}
+	if f.model == "" {
+		f.model = chunk.Model
+	}
+	if chunk.ProviderRequestID != "" {
+		f.providerRequestID = chunk.ProviderRequestID
+	}
+	if f.reasons == nil {
+		f.reasons = make(map[int]domain.FinishReason)
+	}
+	for _, choice := range chunk.Choices {
+		if _, seen := f.reasons[choice.Index]; !seen || choice.FinishReason != "" {
+			f.reasons[choice.Index] = choice.FinishReason
+		}
+	}
+}
+
+// event builds the final event of a stream that completed. A choice the
+// provider gave no finish reason for ran to its natural end, so it reports
+// stop.
+func (f *streamFinish) event(req *domain.CompletionRequest, provider domain.Provider, created int64, usage *domain.Usage) *domain.StreamResponse {
+	indexes := make([]int, 0, len(f.reasons))
+	for index := range f.reasons {
+		indexes = append(indexes, index)
+	}
+	if len(indexes) == 0 {
+		indexes = append(indexes, 0)
+	}
+	sort.Ints(indexes)
+
+	choices := make([]domain.Choice, len(indexes))
+	for i, index := range indexes {
+		reason := f.reasons[index]
+		if reason == "" {
+			reason = domain.FinishReasonStop
+		}
+		choices[i] = domain.Choice{
+			Index:        index,
+			ID:           domain.ChoiceID(f.id, index),
+			Message:      domain.Message{Role: domain.MessageRoleAssistant},
+			FinishReason: reason,
+		}
+	}
+
+	model := f.model
+	if model == "" {
+		model = req.Model
+	}
+	return &domain.StreamResponse{
+		ID:                f.id,
+		Object:            "chat.completion.chunk",
+		Created:           created,
+		Model:             model,
+		Provider:          provider,
+		Choices:           choices,
+		Usage:             usage,
+		ProviderRequestID: f.providerRequestID,
+		Final:             true,
+		Metadata:          f.metadata(),
+	}
+}
+
+// firstContent records the time to first token once a chunk carries content
+func (f *streamFinish) firstContent(chunk *domain.StreamResponse, provider domain.Provider, model string, requested time.Time) {
+	if f.firstToken > 0 || !hasStreamContent(chunk) {
+		return
+	}
+	f.firstToken = time.Since(requested)
+	timeToFirstToken.WithLabelValues(string(provider), model).Observe(f.firstToken.Seconds())
+}
+
+// metadata is the final event's metadata, nil for a stream without content
+// or warnings
+func (f *streamFinish) metadata() map[string]interface{} {
+	if f.firstToken == 0 && len(f.warnings) == 0 {
+		return nil
+	}
+	metadata := make(map[string]interface{}, len(f.warnings)+1)
+	for key, value := range f.warnings {
+		metadata[key] = value
+	}
+	if f.firstToken > 0 {
+		metadata[domain.MetadataKeyTimeToFirstToken] = f.firstToken.Milliseconds()
+	}
+	return metadata
+}
+
+// writeStreamEnd sends the final event of a completed stream followed by
+// [DONE]
+func writeStreamEnd(c *gin.Context, final *domain.StreamResponse) {
+	data, _ := json.Marshal(final)
+	c.Writer.Write([]byte(fmt.Sprintf("data: %s\n\n", data)))
+	c.Writer.Write([]byte("data: [DONE]\n\n"))
+	c.Writer.Flush()
+}
@@ -0,0 +1,122 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+func routeFinishTestStream(t *testing.T, chunks ...*domain.StreamResponse) []string {
+	t.Helper()
+	s := newCacheTestService(&scriptedStreamClient{chunks: chunks}, nil)
+	req := newCacheTestRequest("tenant-a")
+	req.Stream = true
+
+	w, err := routeTestStream(t, s, req)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+}
+
+func TestRouteCompletionStream_FinalEventCarriesFinishReasonAndUsage(t *testing.T) {
+	last := textChunk(" world")
+	last.Choices[0].FinishReason = domain.FinishReasonLength
+	usage := &domain.StreamResponse{Usage: &domain.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}}
+
+	events := routeFinishTestStream(t, textChunk("Hello"), last, usage, &domain.StreamResponse{Done: true})
+
+	require.GreaterOrEqual(t, len(events), 2)
+	final := events[len(events)-2]
+	assert.Contains(t, final, `"final":true`)
+	assert.Contains(t, final, `"finish_reason":"length"`)
+	assert.Contains(t, final, `"total_tokens":5`)
+	assert.Equal(t, "data: [DONE]", events[len(events)-1])
+
+	// Only the final event is marked
+	for _, event := range events[:len(events)-2] {
+		assert.NotContains(t, event, `"final":true`)
+	}
+}
+
+func TestRouteCompletionStream_FinalEventReportsEveryChoice(t *testing.T) {
+	first, second := textChunk("a"), textChunk("b")
+	second.Choices[0].Index = 1
+
+	events := routeFinishTestStream(t, first, second, &domain.StreamResponse{Done: true})
+
+	require.Len(t, events, 4)
+	final := events[2]
+	assert.Contains(t, final, `"final":true`)
+	assert.Contains(t, final, `"index":1`)
+	assert.Equal(t, 2, strings.Count(final, `"finish_reason":"stop"`))
+	assert.Equal(t, "data: [DONE]", events[3])
+}
+
+func TestRouteCompletionStream_FinalEventWhenProviderClosesWithoutDone(t *testing.T) {
+	events := routeFinishTestStream(t, textChunk("Hello"))
+
+	require.Len(t, events, 3)
+	assert.Contains(t, events[1], `"final":true`)
+	assert.Contains(t, events[1], `"finish_reason":"stop"`)
+	assert.Equal(t, "data: [DONE]", events[2])
+}
+
+// slowStartStreamClient sends its chunks after a delay, as a provider does
+// while it processes the prompt
+type slowStartStreamClient struct {
+	countingProviderClient
+	delay  time.Duration
+	chunks []*domain.StreamResponse
+}
+
+func (c *slowStartStreamClient) CreateCompletionStream(ctx context.Context, req *domain.CompletionRequest) (<-chan *domain.StreamResponse, error) {
+	ch := make(chan *domain.StreamResponse)
+	go func() {
+		defer close(ch)
+		time.Sleep(c.delay)
+		for _, chunk := range c.chunks {
+			ch <- chunk
+		}
+	}()
+	return ch, nil
+}
+
+func TestRouteCompletionStream_FinalEventReportsTimeToFirstToken(t *testing.T) {
+	// Role-only chunks before the content do not count as the first token
+	role := &domain.StreamResponse{Choices: []domain.Choice{{Message: domain.Message{Role: domain.MessageRoleAssistant}}}}
+	client := &slowStartStreamClient{delay: 30 * time.Millisecond, chunks: []*domain.StreamResponse{role, textChunk("Hello"), {Done: true}}}
+	s := newCacheTestService(client, nil)
+	req := newCacheTestRequest("tenant-a")
+	req.Stream = true
+
+	w, err := routeTestStream(t, s, req)
+	require.NoError(t, err)
+	events := strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+	require.GreaterOrEqual(t, len(events), 2)
+
+	var final domain.StreamResponse
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(events[len(events)-2], "data: ")), &final))
+	require.True(t, final.Final)
+	ttft, ok := final.Metadata[domain.MetadataKeyTimeToFirstToken].(float64)
+	require.True(t, ok, "final event metadata: %v", final.Metadata)
+	assert.GreaterOrEqual(t, ttft, float64(30))
+
+	// Only the final event carries it
+	for _, event := range events[:len(events)-2] {
+		assert.NotContains(t, event, domain.MetadataKeyTimeToFirstToken)
+	}
+}
+
+func TestRouteCompletionStream_NoTimeToFirstTokenWithoutContent(t *testing.T) {
+	events := routeFinishTestStream(t, &domain.StreamResponse{Done: true})
+
+	require.GreaterOrEqual(t, len(events), 2)
+	assert.Contains(t, events[len(events)-2], `"final":true`)
+	assert.NotContains(t, events[len(events)-2], domain.MetadataKeyTimeToFirstToken)
+}
@@ -0,0 +1,128 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// streamToolCalls reassembles the tool calls a provider streams in
+// fragments. The fragments are withheld from the client, and once the
+// stream ends each choice's calls go out whole in one event, after their
+// arguments are checked to be JSON. A call whose arguments were cut off or
+// are malformed fails the stream instead of reaching the client broken.
+type streamToolCalls struct {
+	calls map[int][]domain.ToolCall
+}
+
+// take removes the tool-call fragments from a chunk and adds them to the
+// calls being assembled. A fragment without an ID extends the previous call
+// of its choice.
+func (t *streamToolCalls) take(chunk *domain.StreamResponse) {
+	for i := range chunk.Choices {
+		choice := &chunk.Choices[i]
+		for _, call := range choice.Message.ToolCalls {
+			if t.calls == nil {
+				t.calls = make(map[int][]domain.ToolCall)
+			}
+			calls := t.calls[choice.Index]
+			if call.ID == "" && len(calls) > 0 {
+				last := &calls[len(calls)-1]
+				last.Function.Name += call.Function.Name
+				last.Function.Arguments += call.Function.Arguments
+				continue
+			}
+			t.calls[choice.Index] = append(calls, call)
+		}
+		choice.Message.ToolCalls = nil
+	}
+}
+
+// validate checks that every assembled call's arguments are JSON. Calls to
+// functions without parameters may stream no arguments at all; they are
+// given an empty object.
+func (t *streamToolCalls) validate(provider domain.Provider) *shared_errors.QLensError {
+	for _, calls := range t.calls {
+		for i := range calls {
+			call := &calls[i]
+			if call.Function.Arguments == "" {
+				call.Function.Arguments = "{}"
+			}
+			if !json.Valid([]byte(call.Function.Arguments)) {
+				streamToolCallsAssembled.WithLabelValues(string(provider), "invalid").Inc()
+				return invalidToolCallArgumentsError(provider, call)
+			}
+		}
+	}
+	if len(t.calls) > 0 {
+		streamToolCallsAssembled.WithLabelValues(string(provider), "valid").Inc()
+	}
+	return nil
+}
+
+// event builds the event carrying each choice's assembled tool calls, or
+// nil when the stream had none
+func (t *streamToolCalls) event(finish *streamFinish, provider domain.Provider, created int64) *domain.StreamResponse {
+	if len(t.calls) == 0 {
+		return nil
+	}
+	indexes := make([]int, 0, len(t.calls))
+	for index := range t.calls {
+		indexes = append(indexes, index)
+	}
+	sort.Ints(indexes)
+
+	choices := make([]domain.Choice, len(indexes))
+	for i, index := range indexes {
+		choices[i] = domain.Choice{
+			Index:   index,
+			ID:      domain.ChoiceID(finish.id, index),
+			Message: domain.Message{Role: domain.MessageRoleAssistant, ToolCalls: t.calls[index]},
+		}
+	}
+	return &domain.StreamResponse{
+		ID:                 finish.id,
+		Object:             "chat.completion.chunk",
+		Created:            created,
+		Model:              finish.model,
+		Provider:           provider,
+		Choices:            choices,
+		ProviderRequestID:  finish.providerRequestID,
+		ToolCallsAssembled: true,
+	}
+}
+
+// invalidToolCallArgumentsError reports a streamed tool call whose
+// arguments are not JSON, usually because the stream was cut off
+func invalidToolCallArgumentsError(provider domain.Provider, call *domain.ToolCall) *shared_errors.QLensError {
+	return shared_errors.NewError(shared_errors.ErrorTypeProviderError,
+		fmt.Sprintf("tool call %s (%s) streamed by %s has arguments that are not valid JSON", call.Function.Name, call.ID, provider)).
+		WithCode(shared_errors.CodeInvalidToolCallArguments).
+		WithDetail("provider", string(provider)).
+		WithStatusCode(http.StatusBadGateway).
+		Build()
+}
+
+// writeStreamToolCalls sends a completed stream's assembled tool calls. When
+// their arguments are not valid JSON it sends an error event instead, ends
+// the stream and reports false.
+func (s *Service) writeStreamToolCalls(ctx context.Context, c *gin.Context, req *domain.CompletionRequest, provider domain.Provider, toolCalls *streamToolCalls, finish *streamFinish, created int64) bool {
+	if toolCalls == nil {
+		return true
+	}
+	if err := toolCalls.validate(provider); err != nil {
+		s.recordStreamTermination(ctx, req, provider, domain.StreamOutcomeProviderError, err)
+		writeStreamError(c, err)
+		return false
+	}
+	if event := toolCalls.event(finish, provider, created); event != nil {
+		writeStreamEvents(c, []*domain.StreamResponse{event})
+	}
+	return true
+}
@@ -0,0 +1,91 @@
+package router
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+func toolCallChunk(id, name, arguments string) *domain.StreamResponse {
+	return &domain.StreamResponse{Choices: []domain.Choice{{Message: domain.Message{
+		Role:      domain.MessageRoleAssistant,
+		ToolCalls: []domain.ToolCall{{ID: id, Type: "function", Function: domain.FunctionCall{Name: name, Arguments: arguments}}},
+	}}}}
+}
+
+func routeToolCallStream(t *testing.T, chunks ...*domain.StreamResponse) []string {
+	t.Helper()
+	s := newCacheTestService(&scriptedStreamClient{chunks: chunks}, nil)
+	req := newCacheTestRequest("tenant-a")
+	req.Stream = true
+
+	w, err := routeTestStream(t, s, req)
+	require.NoError(t, err)
+	return strings.Split(strings.TrimSpace(w.Body.String()), "\n\n")
+}
+
+func TestRouteCompletionStream_AssemblesToolCalls(t *testing.T) {
+	events := routeToolCallStream(t,
+		toolCallChunk("call_1", "lookup", ""),
+		toolCallChunk("", "", `{"city":`),
+		toolCallChunk("", "", `"Paris"}`),
+		toolCallChunk("call_2", "time", ""),
+		&domain.StreamResponse{Choices: []domain.Choice{{FinishReason: domain.FinishReasonToolCalls}}},
+		&domain.StreamResponse{Done: true},
+	)
+
+	// Fragments go out without their tool calls, then the calls go out
+	// whole before the final event
+	require.Len(t, events, 8)
+	for _, event := range events[:5] {
+		assert.NotContains(t, event, "tool_calls\":[")
+	}
+	var assembled domain.StreamResponse
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(events[5], "data: ")), &assembled))
+	assert.True(t, assembled.ToolCallsAssembled)
+	calls := assembled.Choices[0].Message.ToolCalls
+	require.Len(t, calls, 2)
+	assert.Equal(t, "lookup", calls[0].Function.Name)
+	assert.Equal(t, `{"city":"Paris"}`, calls[0].Function.Arguments)
+	assert.Equal(t, "{}", calls[1].Function.Arguments, "calls without arguments get an empty object")
+	assert.Contains(t, events[6], `"finish_reason":"tool_calls"`)
+	assert.Equal(t, "data: [DONE]", events[7])
+}
+
+func TestRouteCompletionStream_RejectsTruncatedToolCallArguments(t *testing.T) {
+	events := routeToolCallStream(t,
+		toolCallChunk("call_1", "lookup", `{"city":`),
+		toolCallChunk("", "", `"Par`),
+		&domain.StreamResponse{Done: true},
+	)
+
+	last := events[len(events)-1]
+	assert.Contains(t, last, `"code":"invalid_tool_call_arguments"`)
+	assert.Contains(t, last, "call_1")
+	assert.NotContains(t, strings.Join(events, "\n"), "[DONE]")
+	assert.NotContains(t, strings.Join(events, "\n"), `"Par`)
+
+	qlensErr := invalidToolCallArgumentsError(domain.ProviderOpenAI, &domain.ToolCall{ID: "call_1"})
+	// The error taxonomy lists the code with the status it is returned with
+	info, ok := shared_errors.DescribeErrorCode(qlensErr.Type, qlensErr.Code)
+	require.True(t, ok)
+	assert.Equal(t, qlensErr.HTTPStatusCode(), info.StatusCode)
+}
+
+func TestStreamToolCalls_PassthroughLeavesFragments(t *testing.T) {
+	s := newCacheTestService(&scriptedStreamClient{chunks: []*domain.StreamResponse{toolCallChunk("call_1", "lookup", `{"city":`), {Done: true}}}, nil)
+	req := newCacheTestRequest("tenant-a")
+	req.Stream = true
+	req.StreamPassthrough = true
+
+	w, err := routeTestStream(t, s, req)
+	require.NoError(t, err)
+	assert.Contains(t, w.Body.String(), `"arguments":"{\"city\":"`)
+	assert.NotContains(t, w.Body.String(), "invalid_tool_call_arguments")
+}
@@ -0,0 +1,98 @@
+package router
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/services/cost"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// streamUsage accumulates what a completion stream delivered: the size of
+// its output and the usage the provider reported, if any
+type streamUsage struct {
+	completionChars int
+	reported        *domain.Usage
+}
+
+// observe records a chunk forwarded to the client
+func (u *streamUsage) observe(chunk *domain.StreamResponse) {
+	for _, choice := range chunk.Choices {
+		for _, part := range choice.Message.Content {
+			u.completionChars += len(part.Text)
+		}
+		for _, call := range choice.Message.ToolCalls {
+			u.completionChars += len(call.Function.Name) + len(call.Function.Arguments)
+		}
+	}
+	if chunk.Usage != nil {
+		u.reported = chunk.Usage
+	}
+}
+
+// delivered reports whether the stream produced any output or usage to bill
+func (u *streamUsage) delivered() bool {
+	return u.completionChars > 0 || u.reported != nil
+}
+
+// estimate approximates the stream's usage with the domain's token
+// estimates, the same the gateway uses for cost ceilings
+func (u *streamUsage) estimate(req *domain.CompletionRequest) domain.Usage {
+	promptTokens := domain.EstimatePromptTokens(req.Messages)
+	completionTokens := domain.EstimateTextTokens(u.completionChars)
+	return domain.Usage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      promptTokens + completionTokens,
+	}
+}
+
+// settleStreamUsage bills a stream, including one cut short by the client
+// or the provider, for what it delivered, settling its budget reservation.
+// Sampled streams have the provider's reported usage compared against the
+// estimate so drift in streamed billing shows up in metrics and logs. The
+// configured billing source decides which usage is billed; streams whose
+// provider reported nothing are billed by the estimate either way.
+func (s *Service) settleStreamUsage(ctx context.Context, req *domain.CompletionRequest, provider domain.Provider, usage *streamUsage, reservation *cost.BudgetReservation, duration time.Duration) {
+	config := s.currentConfig()
+	// The client may already be gone
+	ctx = context.WithoutCancel(ctx)
+
+	estimated := usage.estimate(req)
+	s.mu.RLock()
+	if model := s.providerModel(provider, req.Model); model != nil {
+		estimated.CostUSD = model.Pricing.CompletionCost(estimated)
+	}
+	s.mu.RUnlock()
+
+	if usage.reported != nil && rand.Float64() < config.StreamUsageSampleRate {
+		delta := usage.reported.TotalTokens - estimated.TotalTokens
+		streamUsageDelta.WithLabelValues(string(provider)).Observe(float64(delta))
+		s.logger.Info("Reconciled stream usage",
+			logger.F("provider", provider),
+			logger.F("model", req.Model),
+			logger.F("request_id", req.RequestID),
+			logger.F("reported_tokens", usage.reported.TotalTokens),
+			logger.F("estimated_tokens", estimated.TotalTokens),
+			logger.F("delta_tokens", delta),
+		)
+	}
+
+	billed := estimated
+	source := env.StreamUsageSourceEstimate
+	if usage.reported != nil && config.StreamUsageBillingSource != env.StreamUsageSourceEstimate {
+		billed = *usage.reported
+		source = env.StreamUsageSourceProvider
+	}
+	streamUsageBilled.WithLabelValues(string(provider), source).Inc()
+
+	s.recordPayloadSizes(ctx, req, provider, usage.completionChars, billed)
+
+	response := &domain.CompletionResponse{ID: req.RequestID, Model: req.Model, Provider: provider, Usage: billed}
+	if err := s.trackRequestCost(ctx, req, response, provider, duration, reservation); err != nil {
+		s.logger.Warn("Failed to track stream cost", logger.F("error", err))
+	}
+}
@@ -0,0 +1,140 @@
+package router
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+func newStreamUsageTestService(source string) *Service {
+	service := newCacheTestService(nil, nil)
+	service.config = &env.Config{StreamUsageSampleRate: 1, StreamUsageBillingSource: source}
+	service.modelRegistry = map[string]map[domain.Provider]*domain.Model{
+		"gpt-4o": {domain.ProviderOpenAI: {ModelID: "gpt-4o", Provider: domain.ProviderOpenAI, Pricing: domain.ModelPricing{InputTokenCost: 0.001, OutputTokenCost: 0.002}}},
+	}
+	return service
+}
+
+func streamedText(text string) *domain.StreamResponse {
+	return &domain.StreamResponse{Choices: []domain.Choice{{
+		Message: domain.Message{Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: text}}},
+	}}}
+}
+
+func TestStreamUsage_Estimate(t *testing.T) {
+	usage := &streamUsage{}
+	usage.observe(streamedText("Hello, "))
+	usage.observe(streamedText("world"))
+
+	// "Hello" is 2 tokens plus the message overhead; 12 characters are 3
+	estimated := usage.estimate(newCacheTestRequest("tenant-a"))
+	assert.Equal(t, domain.Usage{PromptTokens: 6, CompletionTokens: 3, TotalTokens: 9}, estimated)
+	assert.Nil(t, usage.reported)
+
+	usage.observe(&domain.StreamResponse{Done: true, Usage: &domain.Usage{TotalTokens: 12}})
+	assert.Equal(t, 12, usage.reported.TotalTokens)
+}
+
+func TestSettleStreamUsage_BillingSource(t *testing.T) {
+	reported := &domain.Usage{PromptTokens: 8, CompletionTokens: 4, TotalTokens: 12, CostUSD: 0.5}
+	estimatedCost := 6*0.001 + 3*0.002
+
+	tests := []struct {
+		name     string
+		source   string
+		reported *domain.Usage
+		cost     float64
+	}{
+		{name: "provider", source: env.StreamUsageSourceProvider, reported: reported, cost: 0.5},
+		{name: "estimate", source: env.StreamUsageSourceEstimate, reported: reported, cost: estimatedCost},
+		{name: "provider reported nothing", source: env.StreamUsageSourceProvider, cost: estimatedCost},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := newStreamUsageTestService(tt.source)
+			usage := &streamUsage{reported: tt.reported}
+			usage.observe(streamedText("Hello, world"))
+
+			service.settleStreamUsage(context.Background(), newCacheTestRequest("tenant-a"), domain.ProviderOpenAI, usage, nil, time.Second)
+
+			stats := service.costService.GetGlobalUsage()
+			assert.Equal(t, int64(1), stats.RequestCount)
+			assert.InDelta(t, tt.cost, stats.TotalCostToday, 1e-9)
+		})
+	}
+}
+
+// stallingStreamClient streams its chunks, then hangs until the request is
+// cancelled, like a provider still generating when the client goes away
+type stallingStreamClient struct {
+	countingProviderClient
+	chunks  []*domain.StreamResponse
+	stalled chan struct{}
+}
+
+func (c *stallingStreamClient) CreateCompletionStream(ctx context.Context, req *domain.CompletionRequest) (<-chan *domain.StreamResponse, error) {
+	ch := make(chan *domain.StreamResponse)
+	go func() {
+		for _, chunk := range c.chunks {
+			ch <- chunk
+		}
+		close(c.stalled)
+		<-ctx.Done()
+	}()
+	return ch, nil
+}
+
+func TestRouteCompletionStream_BillsStreamCancelledMidway(t *testing.T) {
+	client := &stallingStreamClient{chunks: []*domain.StreamResponse{streamedText("Hello, world")}, stalled: make(chan struct{})}
+	service := newStreamUsageTestService(env.StreamUsageSourceProvider)
+	service.providerClients[domain.ProviderOpenAI] = client
+	req := newCacheTestRequest("tenant-a")
+	req.Stream = true
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-client.stalled
+		cancel()
+	}()
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/internal/v1/completions/stream", nil)
+	require.NoError(t, service.routeCompletionStream(ctx, req, c))
+	assert.Contains(t, w.Body.String(), "Hello, world")
+
+	// The provider reported no usage, so the delivered output is estimated
+	stats := service.costService.GetGlobalUsage()
+	assert.Equal(t, int64(1), stats.RequestCount)
+	assert.InDelta(t, 6*0.001+3*0.002, stats.TotalCostToday, 1e-9)
+}
+
+func TestRouteCompletionStream_BillsOnlyDeliveredOutput(t *testing.T) {
+	failure := shared_errors.ProviderUnavailableError("openai")
+
+	// Output streamed before the provider failed was generated and billed
+	service := newStreamUsageTestService(env.StreamUsageSourceProvider)
+	service.providerClients[domain.ProviderOpenAI] = &scriptedStreamClient{chunks: []*domain.StreamResponse{streamedText("Hello, world"), {Error: failure}}}
+	req := newCacheTestRequest("tenant-a")
+	req.Stream = true
+	_, err := routeTestStream(t, service, req)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), service.costService.GetGlobalUsage().RequestCount)
+
+	// A stream that failed before producing anything is not
+	service = newStreamUsageTestService(env.StreamUsageSourceProvider)
+	service.providerClients[domain.ProviderOpenAI] = &scriptedStreamClient{chunks: []*domain.StreamResponse{{Error: failure}}}
+	_, err = routeTestStream(t, service, req)
+	require.NoError(t, err)
+	assert.Zero(t, service.costService.GetGlobalUsage().RequestCount)
+}
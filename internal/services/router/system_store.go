@@ -0,0 +1,127 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/services/cache"
+	"github.com/quantum-suite/platform/pkg/shared/errors"
+)
+
+// SystemStore keeps the router's own state, such as toggled kill switches,
+// apart from tenants' cache entries and without expiry. Updates are made
+// with a compare-and-swap so replicas changing the same entry never
+// overwrite each other.
+type SystemStore interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// CompareAndSwap sets key to value only if it still holds old, or is
+	// absent when old is nil, reporting whether it did
+	CompareAndSwap(ctx context.Context, key string, old, value []byte) (bool, error)
+}
+
+// HTTPSystemStore keeps system entries in the cache service
+type HTTPSystemStore struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPSystemStore creates a system store in the cache service at baseURL
+func NewHTTPSystemStore(baseURL string) *HTTPSystemStore {
+	return &HTTPSystemStore{
+		baseURL: baseURL,
+		client: &http.Client{
+			Timeout: 2 * time.Second,
+		},
+	}
+}
+
+func (s *HTTPSystemStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	endpoint := fmt.Sprintf("%s/internal/v1/system-cache/%s", s.baseURL, url.PathEscape(key))
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, false, errors.InternalError("failed to create cache request", err)
+	}
+
+	var cacheResp struct {
+		Value json.RawMessage `json:"value"`
+		Found bool            `json:"found"`
+	}
+	if err := s.do(httpReq, &cacheResp); err != nil {
+		return nil, false, err
+	}
+	if !cacheResp.Found || len(cacheResp.Value) == 0 {
+		return nil, false, nil
+	}
+	return cacheResp.Value, true, nil
+}
+
+func (s *HTTPSystemStore) CompareAndSwap(ctx context.Context, key string, old, value []byte) (bool, error) {
+	body, err := json.Marshal(struct {
+		Key   string          `json:"key"`
+		Old   json.RawMessage `json:"old,omitempty"`
+		Value json.RawMessage `json:"value"`
+	}{Key: key, Old: old, Value: value})
+	if err != nil {
+		return false, errors.InternalError("failed to marshal cache request", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, s.baseURL+"/internal/v1/system-cache/swap", bytes.NewReader(body))
+	if err != nil {
+		return false, errors.InternalError("failed to create cache request", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	var cacheResp struct {
+		Swapped bool `json:"swapped"`
+	}
+	if err := s.do(httpReq, &cacheResp); err != nil {
+		return false, err
+	}
+	return cacheResp.Swapped, nil
+}
+
+func (s *HTTPSystemStore) do(httpReq *http.Request, out interface{}) error {
+	resp, err := s.client.Do(httpReq)
+	if err != nil {
+		return errors.InternalError("failed to call cache service", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.InternalError(fmt.Sprintf("cache service returned status %d", resp.StatusCode), nil)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.InternalError("failed to decode cache response", err)
+	}
+	return nil
+}
+
+// LocalSystemStore keeps system entries in a local cache store, for
+// deployments without a separate cache service
+type LocalSystemStore struct {
+	store cache.CacheStore
+}
+
+// NewLocalSystemStore wraps a cache store such as cache.NewMemoryStore
+func NewLocalSystemStore(store cache.CacheStore) *LocalSystemStore {
+	return &LocalSystemStore{store: store}
+}
+
+func (s *LocalSystemStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return s.store.Get(ctx, systemCacheKey(key))
+}
+
+func (s *LocalSystemStore) CompareAndSwap(ctx context.Context, key string, old, value []byte) (bool, error) {
+	return s.store.CompareAndSwap(ctx, systemCacheKey(key), old, value, 0)
+}
+
+// systemCacheKey matches the system key layout used by the cache service
+func systemCacheKey(key string) string {
+	return "system:" + key
+}
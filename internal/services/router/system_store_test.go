@@ -0,0 +1,56 @@
+package router
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/services/cache"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestHTTPSystemStore_CompareAndSwap(t *testing.T) {
+	cacheService, err := cache.NewService(&env.Config{CacheType: "memory"}, logger.NewNoop())
+	require.NoError(t, err)
+	server := httptest.NewServer(cacheService.Handler())
+	defer server.Close()
+
+	store := NewHTTPSystemStore(server.URL)
+	ctx := context.Background()
+
+	_, found, err := store.Get(ctx, "state")
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	// Only the first writer of an absent entry succeeds
+	swapped, err := store.CompareAndSwap(ctx, "state", nil, []byte(`{"v":1}`))
+	require.NoError(t, err)
+	assert.True(t, swapped)
+	swapped, err = store.CompareAndSwap(ctx, "state", nil, []byte(`{"v":2}`))
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	// The value read back is the one to swap out
+	current, found, err := store.Get(ctx, "state")
+	require.NoError(t, err)
+	require.True(t, found)
+	swapped, err = store.CompareAndSwap(ctx, "state", current, []byte(`{"v":3}`))
+	require.NoError(t, err)
+	assert.True(t, swapped)
+	swapped, err = store.CompareAndSwap(ctx, "state", current, []byte(`{"v":4}`))
+	require.NoError(t, err)
+	assert.False(t, swapped)
+
+	current, _, err = store.Get(ctx, "state")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"v":3}`, string(current))
+
+	// System entries are apart from every tenant's
+	_, found, err = NewHTTPCacheClient(server.URL).Get(ctx, "system", "state")
+	require.NoError(t, err)
+	assert.False(t, found)
+}
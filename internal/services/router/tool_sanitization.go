@@ -0,0 +1,46 @@
+package router
+
+import (
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// sanitizeToolResults sanitizes the tool messages of a request according to
+// the tenant's mode, before the cache key is computed and the request is
+// dispatched, and returns what changed, if anything
+func (s *Service) sanitizeToolResults(req *domain.CompletionRequest) *domain.ToolSanitization {
+	mode := s.currentConfig().ToolSanitizationFor(string(req.TenantID))
+	if mode != env.ToolSanitizationStrip && mode != env.ToolSanitizationWrap {
+		return nil
+	}
+
+	sanitization := req.SanitizeToolResults(mode == env.ToolSanitizationWrap)
+	if sanitization == nil {
+		return nil
+	}
+
+	toolResultsSanitized.WithLabelValues(string(req.TenantID), mode).Inc()
+	for _, pattern := range sanitization.Patterns {
+		toolInjectionsRemoved.WithLabelValues(string(req.TenantID), pattern).Inc()
+	}
+	if len(sanitization.Patterns) > 0 {
+		s.logger.Warn("Removed prompt-injection patterns from tool results",
+			logger.F("tenant_id", req.TenantID),
+			logger.F("model", req.Model),
+			logger.F("patterns", sanitization.Patterns),
+			logger.F("messages", sanitization.Messages))
+	}
+	return sanitization
+}
+
+// setToolSanitization records the sanitization in the response metadata
+func setToolSanitization(response *domain.CompletionResponse, sanitization *domain.ToolSanitization) {
+	if sanitization == nil {
+		return
+	}
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]interface{})
+	}
+	response.Metadata[domain.MetadataKeyToolSanitization] = sanitization
+}
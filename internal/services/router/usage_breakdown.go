@@ -0,0 +1,18 @@
+package router
+
+import (
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// setUsageBreakdown attributes the response's prompt tokens to the request's
+// messages when the request asked for verbose usage. The breakdown describes
+// this request, so it is added after the response was cached.
+func setUsageBreakdown(req *domain.CompletionRequest, response *domain.CompletionResponse) {
+	if !req.VerboseUsage {
+		return
+	}
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]interface{})
+	}
+	response.Metadata[domain.MetadataKeyUsageBreakdown] = domain.NewUsageBreakdown(req, response.Usage.PromptTokens)
+}
@@ -0,0 +1,44 @@
+package router
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/internal/services/cache"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+func TestRouteCompletion_VerboseUsageBreakdown(t *testing.T) {
+	client := &countingProviderClient{}
+	service := newCacheTestService(client, NewStoreCacheClient(cache.NewMemoryStore(logger.NewNoop())))
+
+	// Without the option responses carry no breakdown, and neither does
+	// the copy they leave in the cache
+	plain, err := service.routeCompletion(context.Background(), newCacheTestRequest("tenant-a"))
+	require.NoError(t, err)
+	assert.NotContains(t, plain.Metadata, domain.MetadataKeyUsageBreakdown)
+
+	// Verbose requests are cached apart from plain ones
+	req := newCacheTestRequest("tenant-a")
+	req.VerboseUsage = true
+	_, err = service.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, 2, client.calls)
+
+	verbose, err := service.routeCompletion(context.Background(), req)
+	require.NoError(t, err)
+	assert.True(t, verbose.Usage.CacheHit)
+	assert.Equal(t, 2, client.calls)
+
+	breakdown, ok := verbose.Metadata[domain.MetadataKeyUsageBreakdown].(*domain.UsageBreakdown)
+	require.True(t, ok)
+	assert.Equal(t, domain.UsageBreakdownProvider, breakdown.Source)
+	assert.Equal(t, 10, breakdown.PromptTokens)
+	require.Len(t, breakdown.Messages, 1)
+	assert.Equal(t, domain.MessageRoleUser, breakdown.Messages[0].Role)
+	assert.Equal(t, 10, breakdown.ByRole[string(domain.MessageRoleUser)])
+}
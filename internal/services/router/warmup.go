@@ -0,0 +1,128 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// startProviderWarmup warms every enabled provider in the background, so the
+// first requests after a deploy skip the TLS handshakes and credential
+// lookups. Readiness fails until the warm-up ends, which it does once every
+// provider is warm or the warm-up timeout passes, whichever comes first.
+// Without a timeout there is no warm-up.
+func (s *Service) startProviderWarmup() {
+	timeout := s.config.ProviderWarmupTimeout
+	if timeout <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	s.warmupDone = make(chan struct{})
+	s.stopWarmup = cancel
+
+	go func() {
+		defer close(s.warmupDone)
+		defer cancel()
+
+		started := time.Now()
+		cold := s.warmProvidersAtStartup(ctx, s.config.ProviderWarmupConnections)
+		if len(cold) > 0 {
+			s.logger.Warn("Provider warm-up ended with providers still cold",
+				logger.F("providers", cold),
+				logger.F("duration", time.Since(started)))
+			return
+		}
+		s.logger.Info("Provider warm-up complete", logger.F("duration", time.Since(started)))
+	}()
+}
+
+// warmProvidersAtStartup opens the given number of connections to every
+// provider that supports a cheap warm-up request, all at once so a pool of
+// that size is left open. It returns the providers none of whose connections
+// were warmed by the time ctx ends. Providers whose only probe is a billed
+// request are not warmed.
+func (s *Service) warmProvidersAtStartup(ctx context.Context, connections int) []domain.Provider {
+	if connections < 1 {
+		connections = 1
+	}
+
+	s.mu.RLock()
+	warmers := make(map[domain.Provider]ConnectionWarmer, len(s.providerClients))
+	for provider, client := range s.providerClients {
+		if warmer, ok := client.(ConnectionWarmer); ok {
+			warmers[provider] = warmer
+		}
+	}
+	s.mu.RUnlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		warm = make(map[domain.Provider]bool, len(warmers))
+	)
+	for provider, warmer := range warmers {
+		for i := 0; i < connections; i++ {
+			wg.Add(1)
+			go func(p domain.Provider, w ConnectionWarmer) {
+				defer wg.Done()
+
+				err := w.WarmConnection(ctx)
+				switch {
+				case err == nil:
+					providerWarmups.WithLabelValues(string(p), "success").Inc()
+					mu.Lock()
+					warm[p] = true
+					mu.Unlock()
+				case errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled):
+					providerWarmups.WithLabelValues(string(p), "timeout").Inc()
+				default:
+					providerWarmups.WithLabelValues(string(p), "error").Inc()
+					s.logger.Warn("Provider warm-up failed",
+						logger.F("provider", p),
+						logger.F("error", err))
+				}
+			}(provider, warmer)
+		}
+	}
+
+	// A provider ignoring the deadline does not hold up readiness
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-ctx.Done():
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var cold []domain.Provider
+	for provider := range warmers {
+		if !warm[provider] {
+			cold = append(cold, provider)
+		}
+	}
+	sort.Slice(cold, func(i, j int) bool { return cold[i] < cold[j] })
+	return cold
+}
+
+// warmingUp reports whether the startup warm-up is still running
+func (s *Service) warmingUp() bool {
+	if s.warmupDone == nil {
+		return false
+	}
+	select {
+	case <-s.warmupDone:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,125 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/shared/env"
+	"github.com/quantum-suite/platform/pkg/shared/logger"
+)
+
+// gatedWarmerClient warms a connection once its gate opens, or never when it
+// ignores the deadline
+type gatedWarmerClient struct {
+	ProviderClient
+	gate           chan struct{}
+	ignoreDeadline bool
+	warms          atomic.Int32
+}
+
+func (c *gatedWarmerClient) WarmConnection(ctx context.Context) error {
+	if c.ignoreDeadline {
+		<-c.gate
+	} else {
+		select {
+		case <-c.gate:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	c.warms.Add(1)
+	return nil
+}
+
+func newWarmupTestService(timeout time.Duration, connections int, clients map[domain.Provider]ProviderClient) *Service {
+	configs := make(map[domain.Provider]*domain.ProviderConfig, len(clients))
+	for provider := range clients {
+		config := domain.NewProviderConfig(provider, "system")
+		config.Enabled = true
+		config.HealthStatus = domain.ProviderHealthHealthy
+		configs[provider] = config
+	}
+	return &Service{
+		config:          &env.Config{ProviderWarmupTimeout: timeout, ProviderWarmupConnections: connections},
+		logger:          logger.NewNoop(),
+		providerClients: clients,
+		providerConfigs: configs,
+	}
+}
+
+func readiness(s *Service) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/health/ready", nil)
+	s.handleReadiness(c)
+	return w
+}
+
+func TestProviderWarmup_ReadyOnceWarm(t *testing.T) {
+	client := &gatedWarmerClient{gate: make(chan struct{})}
+	s := newWarmupTestService(time.Minute, 3, map[domain.Provider]ProviderClient{
+		domain.ProviderAzureOpenAI: client,
+		domain.ProviderOpenAI:      &mockProviderClient{provider: domain.ProviderOpenAI},
+	})
+
+	s.startProviderWarmup()
+	w := readiness(s)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Contains(t, w.Body.String(), `"reason":"provider_warmup"`)
+
+	close(client.gate)
+	<-s.warmupDone
+	assert.Equal(t, http.StatusOK, readiness(s).Code)
+	assert.Equal(t, int32(3), client.warms.Load(), "one warm-up per connection")
+}
+
+func TestProviderWarmup_BoundedByTimeout(t *testing.T) {
+	stuck := &gatedWarmerClient{gate: make(chan struct{}), ignoreDeadline: true}
+	defer close(stuck.gate)
+	s := newWarmupTestService(50*time.Millisecond, 1, map[domain.Provider]ProviderClient{
+		domain.ProviderAWSBedrock: stuck,
+	})
+
+	s.startProviderWarmup()
+	select {
+	case <-s.warmupDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("warm-up outlasted its timeout")
+	}
+	assert.Equal(t, http.StatusOK, readiness(s).Code, "cold providers do not hold up readiness past the timeout")
+}
+
+func TestWarmProvidersAtStartup_ReportsColdProviders(t *testing.T) {
+	warm := &gatedWarmerClient{gate: make(chan struct{})}
+	close(warm.gate)
+	cold := &gatedWarmerClient{gate: make(chan struct{})}
+	s := newWarmupTestService(time.Minute, 2, map[domain.Provider]ProviderClient{
+		domain.ProviderAzureOpenAI: warm,
+		domain.ProviderAWSBedrock:  cold,
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, []domain.Provider{domain.ProviderAWSBedrock}, s.warmProvidersAtStartup(ctx, 2))
+	assert.Equal(t, int32(2), warm.warms.Load())
+}
+
+func TestProviderWarmup_DisabledWithoutTimeout(t *testing.T) {
+	client := &gatedWarmerClient{gate: make(chan struct{})}
+	s := newWarmupTestService(0, 1, map[domain.Provider]ProviderClient{domain.ProviderAzureOpenAI: client})
+
+	s.startProviderWarmup()
+	require.Nil(t, s.warmupDone)
+	assert.Equal(t, http.StatusOK, readiness(s).Code)
+	assert.Zero(t, client.warms.Load())
+}
@@ -37,6 +37,13 @@ type CompletionRequest struct {
 
 	// Rate limiting
 	Priority domain.Priority `json:"priority,omitempty"`
+
+	// Debugging: retain the provider's untranslated response body
+	IncludeRawResponse bool `json:"include_raw_response,omitempty"`
+
+	// Store asks providers that support it to keep the completion for
+	// their dashboards and evals, tagged with the string metadata
+	Store *bool `json:"store,omitempty"`
 }
 
 // CompletionResponse represents a completion response
@@ -54,6 +61,17 @@ type CompletionResponse struct {
 	ResponseTime time.Duration `json:"response_time"`
 	CacheHit     bool          `json:"cache_hit"`
 	RequestID    string        `json:"request_id,omitempty"`
+
+	// ProviderRequestID is the ID the provider assigned to the call
+	ProviderRequestID string `json:"provider_request_id,omitempty"`
+}
+
+// AttachRawResponse stores the provider's untranslated response body in the
+// response metadata, as domain.CompletionResponse.AttachRawResponse does
+func (r *CompletionResponse) AttachRawResponse(raw []byte) {
+	attached := domain.CompletionResponse{Metadata: r.Metadata}
+	attached.AttachRawResponse(raw)
+	r.Metadata = attached.Metadata
 }
 
 // StreamResponse represents a streaming completion response chunk
@@ -67,13 +85,19 @@ type StreamResponse struct {
 	Done     bool                   `json:"done"`
 	Error    *StreamError           `json:"error,omitempty"`
 
+	// Usage is the token count for the whole stream, sent on the final
+	// chunk by providers that report it
+	Usage *domain.Usage `json:"usage,omitempty"`
+
 	// Performance metrics
-	RequestID string `json:"request_id,omitempty"`
+	RequestID         string `json:"request_id,omitempty"`
+	ProviderRequestID string `json:"provider_request_id,omitempty"`
 }
 
 // StreamChoice represents a choice in a streaming response
 type StreamChoice struct {
 	Index        int              `json:"index"`
+	ID           string           `json:"id,omitempty"`
 	Delta        StreamDelta      `json:"delta"`
 	FinishReason *domain.FinishReason `json:"finish_reason,omitempty"`
 }
@@ -82,6 +106,7 @@ type StreamChoice struct {
 type StreamDelta struct {
 	Role      *domain.MessageRole `json:"role,omitempty"`
 	Content   *string             `json:"content,omitempty"`
+	Refusal   *string             `json:"refusal,omitempty"`
 	ToolCalls []domain.ToolCall   `json:"tool_calls,omitempty"`
 }
 
@@ -102,6 +127,10 @@ type EmbeddingRequest struct {
 	Dimensions     *int               `json:"dimensions,omitempty"`
 	User           string             `json:"user,omitempty"`
 
+	// Representations asks for the vectors in further forms, derived from
+	// the single set the provider returns
+	Representations []domain.EmbeddingRepresentation `json:"representations,omitempty"`
+
 	// Quantum Suite specific fields
 	TenantID  domain.TenantID        `json:"tenant_id"`
 	UserID    domain.UserID          `json:"user_id"`
@@ -124,6 +153,13 @@ type EmbeddingResponse struct {
 	// Performance metrics
 	ResponseTime time.Duration `json:"response_time"`
 	RequestID    string        `json:"request_id,omitempty"`
+
+	// ProviderRequestID is the ID the provider assigned to the call
+	ProviderRequestID string `json:"provider_request_id,omitempty"`
+
+	// Representations holds the vectors in each form the request's
+	// Representations asked for, in the same order
+	Representations []domain.EmbeddingRepresentationData `json:"representations,omitempty"`
 }
 
 // Model represents a model available through a provider
@@ -182,6 +218,57 @@ type ProviderConfig struct {
 	Enabled   bool                   `json:"enabled"`
 	Priority  int                    `json:"priority"`
 	Config    map[string]interface{} `json:"config,omitempty"`
+
+	// UserAgent overrides the client's default user agent, QLens/<version>.
+	// A {version} placeholder is replaced with the build's version.
+	UserAgent string `json:"user_agent,omitempty"`
+
+	// CustomHeaders are sent with every provider request. Headers the client
+	// manages itself, such as Authorization, always take precedence.
+	CustomHeaders map[string]string `json:"custom_headers,omitempty"`
+
+	// AuthStyle overrides how OpenAI-compatible clients send the API key
+	AuthStyle AuthStyle `json:"auth_style,omitempty"`
+
+	// APIFormat selects the API OpenAI-compatible clients send completions
+	// to; empty means chat completions. Models can override it.
+	APIFormat APIFormat `json:"api_format,omitempty"`
+
+	// Models overrides the capabilities, context length, pricing and API
+	// format the client uses per model ID. Keys also match model IDs that contain
+	// them, so "llama-3.1-70b" covers "llama-3.1-70b-versatile". The SDK
+	// routes an OpenAI-compatible provider only the models listed here or
+	// in its built-in pricing table.
+	Models map[string]ModelSpec `json:"models,omitempty"`
+}
+
+// AuthStyle selects the header an OpenAI-compatible API expects the key in
+type AuthStyle string
+
+const (
+	AuthStyleBearer AuthStyle = "bearer"  // Authorization: Bearer <key>
+	AuthStyleAPIKey AuthStyle = "api-key" // api-key: <key>
+	AuthStyleNone   AuthStyle = "none"    // No credentials, e.g. local servers
+)
+
+// APIFormat selects the completion API of an OpenAI-compatible provider
+type APIFormat string
+
+const (
+	APIFormatChatCompletions APIFormat = "chat_completions" // POST /chat/completions
+	APIFormatResponses       APIFormat = "responses"        // POST /responses
+)
+
+// ModelSpec describes a model in a provider's pricing table. Zero fields
+// fall back to the next matching entry or the provider default.
+type ModelSpec struct {
+	Capabilities  []domain.Capability  `json:"capabilities,omitempty"`
+	ContextLength int                  `json:"context_length,omitempty"`
+	Pricing       *domain.ModelPricing `json:"pricing,omitempty"`
+
+	// APIFormat routes the model's completions to a different API, e.g. for
+	// models only available through Responses
+	APIFormat APIFormat `json:"api_format,omitempty"`
 }
 
 // ClientConfig represents configuration for the QLens client
@@ -199,6 +286,29 @@ type ClientConfig struct {
 	CacheDefaultTTL   time.Duration `json:"cache_default_ttl"`
 	CacheMaxSize      int           `json:"cache_max_size"`
 
+	// CacheCompression compresses Redis cache entries of at least
+	// CacheCompressionMinBytes. Only "gzip" is supported; empty or "none"
+	// stores plain JSON, and "zstd" is rejected as no codec is available.
+	CacheCompression         string `json:"cache_compression,omitempty"`
+	CacheCompressionMinBytes int    `json:"cache_compression_min_bytes,omitempty"`
+
+	// CacheTenantStats breaks cache hits and misses down by tenant
+	CacheTenantStats bool `json:"cache_tenant_stats,omitempty"`
+
+	// CacheEvictionPolicy picks the entry the in-memory cache evicts when
+	// full: "lru" (the default), "lfu" or "ttl"
+	CacheEvictionPolicy string `json:"cache_eviction_policy,omitempty"`
+
+	// EmbeddingStoreTTL is how long individual input embeddings are kept so
+	// unchanged documents are not re-embedded. Zero disables the store.
+	EmbeddingStoreTTL time.Duration `json:"embedding_store_ttl"`
+
+	// EmbeddingStorePath is the file the in-memory client keeps input
+	// embeddings in. The Redis client stores them in Redis instead. Without
+	// either there is no per-input store and embedding responses are cached
+	// whole like any other response.
+	EmbeddingStorePath string `json:"embedding_store_path,omitempty"`
+
 	// Rate limiting
 	GlobalRateLimit   domain.RateLimitConfig `json:"global_rate_limit"`
 
@@ -215,6 +325,10 @@ type ClientConfig struct {
 	MaxRetries        int           `json:"max_retries"`
 	RetryBackoff      time.Duration `json:"retry_backoff"`
 	RetryableErrors   []string      `json:"retryable_errors"`
+
+	// RetryableStatusCodes are provider HTTP statuses retried whatever the
+	// error type; see errors.RetryPolicy for the precedence
+	RetryableStatusCodes []int `json:"retryable_status_codes,omitempty"`
 }
 
 // QLensError represents an error from QLens
@@ -225,6 +339,15 @@ type QLensError struct {
 	Details   map[string]interface{} `json:"details,omitempty"`
 	Provider  domain.Provider        `json:"provider,omitempty"`
 	RequestID string                 `json:"request_id,omitempty"`
+
+	// StatusCode is the HTTP status the provider answered with, or 0 when
+	// the call failed before a response
+	StatusCode int `json:"-"`
+
+	// TenantID and UserID attribute the error for logging; they are never
+	// serialized
+	TenantID domain.TenantID `json:"-"`
+	UserID   domain.UserID   `json:"-"`
 }
 
 func (e *QLensError) Error() string {
@@ -245,6 +368,7 @@ const (
 	ErrorTypeTimeout            = "timeout"
 	ErrorTypeProviderUnavailable = "provider_unavailable"
 	ErrorTypeCacheError         = "cache_error"
+	ErrorTypeNotFound           = "not_found"
 )
 
 // Configuration types
@@ -262,6 +386,20 @@ type CacheConfig struct {
 	MaxSize        int                    `json:"max_size"`
 	CleanupInterval time.Duration         `json:"cleanup_interval"`
 	Config         map[string]interface{} `json:"config,omitempty"`
+
+	// Compression is the algorithm applied to stored entries, "gzip" or
+	// "none" (zstd is not supported); entries smaller than
+	// CompressionMinBytes are stored as is
+	Compression         string `json:"compression,omitempty"`
+	CompressionMinBytes int    `json:"compression_min_bytes,omitempty"`
+
+	// TenantStats breaks hits and misses down by tenant in the cache's
+	// stats. Every tenant seen adds a series, so it is off by default.
+	TenantStats bool `json:"tenant_stats,omitempty"`
+
+	// EvictionPolicy picks the entry the in-memory cache evicts when full:
+	// "lru" (the default), "lfu" or "ttl"
+	EvictionPolicy string `json:"eviction_policy,omitempty"`
 }
 
 // ProviderClient represents an interface for individual LLM providers
@@ -295,4 +433,24 @@ type CacheStats struct {
 	Misses    int64   `json:"misses"`
 	HitRate   float64 `json:"hit_rate"`
 	Evictions int64   `json:"evictions"`
+
+	// Compression statistics cover compressed entries only.
+	// CompressionRatio is uncompressed over compressed bytes.
+	CompressedEntries int64   `json:"compressed_entries,omitempty"`
+	UncompressedBytes int64   `json:"uncompressed_bytes,omitempty"`
+	CompressedBytes   int64   `json:"compressed_bytes,omitempty"`
+	CompressionRatio  float64 `json:"compression_ratio,omitempty"`
+
+	// ByType breaks hits and misses down by entry type ("completion" or
+	// "embedding"), and ByTenant by the requesting tenant when the cache
+	// is configured with TenantStats
+	ByType   map[string]CacheHitStats `json:"by_type,omitempty"`
+	ByTenant map[string]CacheHitStats `json:"by_tenant,omitempty"`
+}
+
+// CacheHitStats counts the lookups of one entry type or tenant
+type CacheHitStats struct {
+	Hits    int64   `json:"hits"`
+	Misses  int64   `json:"misses"`
+	HitRate float64 `json:"hit_rate"`
 }
\ No newline at end of file
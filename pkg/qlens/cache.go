@@ -33,17 +33,29 @@ type InMemoryCache struct {
 	mu          sync.RWMutex
 	entries     map[string]*CacheEntry
 	maxSize     int
-	stats       types.CacheStats
+	counters    cacheCounters
 	stopCleanup chan struct{}
 	cleanupOnce sync.Once
+
+	// policy picks the entry to evict when the cache is full
+	policyName string
+	policy     evictionPolicy
 }
 
 // RedisCache implements the Cache interface using Redis
 type RedisCache struct {
 	client   RedisClient
 	keyPrefix string
-	stats     types.CacheStats
+	counters  cacheCounters
 	mu        sync.RWMutex
+
+	// stats holds the compression statistics; lookups are counted in
+	// counters
+	stats types.CacheStats
+
+	// compression is configured through Configure; entries are stored as
+	// plain JSON until then
+	compression cacheCompression
 }
 
 // RedisClient interface for Redis operations
@@ -62,6 +74,8 @@ func NewInMemoryCache(maxSize int) *InMemoryCache {
 		maxSize:     maxSize,
 		stopCleanup: make(chan struct{}),
 	}
+	cache.policyName = CacheEvictionLRU
+	cache.policy, _ = newEvictionPolicy(CacheEvictionLRU)
 	
 	// Start cleanup goroutine
 	cache.startCleanup()
@@ -79,52 +93,51 @@ func NewRedisCache(client RedisClient, keyPrefix string) *RedisCache {
 
 // InMemoryCache implementation
 
+// Get looks up a completion and counts the lookup
 func (c *InMemoryCache) Get(ctx context.Context, key string) (*types.CompletionResponse, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	if entry, ok := c.lookup(key); ok {
+		if response, ok := entry.Data.(*types.CompletionResponse); ok {
+			c.counters.record(ctx, CacheTypeCompletion, true)
+			// Mark as cache hit
+			responseCopy := *response
+			responseCopy.CacheHit = true
+			return &responseCopy, true
+		}
+	}
+	
+	c.counters.record(ctx, CacheTypeCompletion, false)
+	return nil, false
+}
+
+// lookup returns a live entry and marks it used, dropping it if it has
+// expired. Marking the entry updates the eviction policy's bookkeeping, so
+// lookups take the write lock.
+func (c *InMemoryCache) lookup(key string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	
 	entry, exists := c.entries[key]
 	if !exists {
-		c.stats.Misses++
 		return nil, false
 	}
 	
 	// Check expiration
 	if time.Now().After(entry.ExpiresAt) {
-		c.mu.RUnlock()
-		c.mu.Lock()
-		delete(c.entries, key)
-		c.mu.Unlock()
-		c.mu.RLock()
-		c.stats.Misses++
+		c.remove(key)
 		return nil, false
 	}
 	
 	// Update access stats
 	entry.AccessCount++
 	entry.LastAccessed = time.Now()
-	c.stats.Hits++
-	
-	if response, ok := entry.Data.(*types.CompletionResponse); ok {
-		// Mark as cache hit
-		responseCopy := *response
-		responseCopy.CacheHit = true
-		return &responseCopy, true
-	}
-	
-	c.stats.Misses++
-	return nil, false
+	c.policy.touch(entry)
+	return entry, true
 }
 
 func (c *InMemoryCache) Set(ctx context.Context, key string, response *types.CompletionResponse, ttl time.Duration) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
-	// Check size limit and evict if necessary
-	if len(c.entries) >= c.maxSize {
-		c.evictLRU()
-	}
-	
 	now := time.Now()
 	entry := &CacheEntry{
 		Key:          key,
@@ -141,7 +154,7 @@ func (c *InMemoryCache) Set(ctx context.Context, key string, response *types.Com
 		},
 	}
 	
-	c.entries[key] = entry
+	c.store(entry)
 	return nil
 }
 
@@ -149,41 +162,20 @@ func (c *InMemoryCache) Delete(ctx context.Context, key string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
-	delete(c.entries, key)
+	c.remove(key)
 	return nil
 }
 
+// GetEmbedding looks up embeddings and counts the lookup
 func (c *InMemoryCache) GetEmbedding(ctx context.Context, key string) (*types.EmbeddingResponse, bool) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	
-	entry, exists := c.entries[key]
-	if !exists {
-		c.stats.Misses++
-		return nil, false
-	}
-	
-	// Check expiration
-	if time.Now().After(entry.ExpiresAt) {
-		c.mu.RUnlock()
-		c.mu.Lock()
-		delete(c.entries, key)
-		c.mu.Unlock()
-		c.mu.RLock()
-		c.stats.Misses++
-		return nil, false
-	}
-	
-	// Update access stats
-	entry.AccessCount++
-	entry.LastAccessed = time.Now()
-	c.stats.Hits++
-	
-	if response, ok := entry.Data.(*types.EmbeddingResponse); ok {
-		return response, true
+	if entry, ok := c.lookup(key); ok {
+		if response, ok := entry.Data.(*types.EmbeddingResponse); ok {
+			c.counters.record(ctx, CacheTypeEmbedding, true)
+			return response, true
+		}
 	}
 	
-	c.stats.Misses++
+	c.counters.record(ctx, CacheTypeEmbedding, false)
 	return nil, false
 }
 
@@ -191,11 +183,6 @@ func (c *InMemoryCache) SetEmbedding(ctx context.Context, key string, response *
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
-	// Check size limit and evict if necessary
-	if len(c.entries) >= c.maxSize {
-		c.evictLRU()
-	}
-	
 	now := time.Now()
 	entry := &CacheEntry{
 		Key:          key,
@@ -212,7 +199,7 @@ func (c *InMemoryCache) SetEmbedding(ctx context.Context, key string, response *
 		},
 	}
 	
-	c.entries[key] = entry
+	c.store(entry)
 	return nil
 }
 
@@ -221,6 +208,7 @@ func (c *InMemoryCache) Clear(ctx context.Context) error {
 	defer c.mu.Unlock()
 	
 	c.entries = make(map[string]*CacheEntry)
+	c.policy, _ = newEvictionPolicy(c.policyName)
 	return nil
 }
 
@@ -234,11 +222,22 @@ func (c *InMemoryCache) Close() error {
 
 // Configure implements the Cache interface for InMemoryCache
 func (c *InMemoryCache) Configure(config types.CacheConfig) error {
+	name := config.EvictionPolicy
+	if name == "" {
+		name = CacheEvictionLRU
+	}
+	
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
-	// Update configuration as needed
-	// For now, this is a placeholder
+	if name != c.policyName {
+		policy, err := rebuildEvictionPolicy(name, c.entries)
+		if err != nil {
+			return err
+		}
+		c.policyName, c.policy = name, policy
+	}
+	c.counters.trackTenants.Store(config.TenantStats)
 	return nil
 }
 
@@ -251,32 +250,35 @@ func (c *InMemoryCache) HealthCheck(ctx context.Context) error {
 // Stats implements the Cache interface for InMemoryCache
 func (c *InMemoryCache) Stats() types.CacheStats {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
-	
-	stats := c.stats
-	stats.Size = len(c.entries)
-	if stats.Hits+stats.Misses > 0 {
-		stats.HitRate = float64(stats.Hits) / float64(stats.Hits+stats.Misses)
-	}
+	size := len(c.entries)
+	c.mu.RUnlock()
 	
+	var stats types.CacheStats
+	c.counters.fill(&stats)
+	stats.Size = size
 	return stats
 }
 
-func (c *InMemoryCache) evictLRU() {
-	var oldestKey string
-	var oldestTime time.Time = time.Now()
-	
-	for key, entry := range c.entries {
-		if entry.LastAccessed.Before(oldestTime) {
-			oldestTime = entry.LastAccessed
-			oldestKey = key
+// store adds an entry, replacing any under the same key, and evicts the
+// entry the policy picks if the cache is full
+func (c *InMemoryCache) store(entry *CacheEntry) {
+	if _, exists := c.entries[entry.Key]; exists {
+		c.remove(entry.Key)
+	} else if len(c.entries) >= c.maxSize {
+		if key, ok := c.policy.victim(); ok {
+			c.remove(key)
+			c.counters.evictions.Add(1)
 		}
 	}
 	
-	if oldestKey != "" {
-		delete(c.entries, oldestKey)
-		c.stats.Evictions++
-	}
+	c.entries[entry.Key] = entry
+	c.policy.add(entry)
+}
+
+// remove drops an entry and its eviction bookkeeping
+func (c *InMemoryCache) remove(key string) {
+	delete(c.entries, key)
+	c.policy.remove(key)
 }
 
 func (c *InMemoryCache) startCleanup() {
@@ -302,8 +304,8 @@ func (c *InMemoryCache) cleanup() {
 	now := time.Now()
 	for key, entry := range c.entries {
 		if now.After(entry.ExpiresAt) {
-			delete(c.entries, key)
-			c.stats.Evictions++
+			c.remove(key)
+			c.counters.evictions.Add(1)
 		}
 	}
 }
@@ -311,23 +313,26 @@ func (c *InMemoryCache) cleanup() {
 // RedisCache implementation
 
 func (c *RedisCache) Get(ctx context.Context, key string) (*types.CompletionResponse, bool) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	
 	fullKey := c.keyPrefix + ":" + key
 	data, err := c.client.Get(ctx, fullKey)
 	if err != nil || data == "" {
-		c.stats.Misses++
+		c.counters.record(ctx, CacheTypeCompletion, false)
+		return nil, false
+	}
+	
+	decoded, err := decodeCacheEntry([]byte(data))
+	if err != nil {
+		c.counters.record(ctx, CacheTypeCompletion, false)
 		return nil, false
 	}
 	
 	var response types.CompletionResponse
-	if err := json.Unmarshal([]byte(data), &response); err != nil {
-		c.stats.Misses++
+	if err := json.Unmarshal(decoded, &response); err != nil {
+		c.counters.record(ctx, CacheTypeCompletion, false)
 		return nil, false
 	}
 	
-	c.stats.Hits++
+	c.counters.record(ctx, CacheTypeCompletion, true)
 	response.CacheHit = true
 	return &response, true
 }
@@ -340,6 +345,11 @@ func (c *RedisCache) Set(ctx context.Context, key string, response *types.Comple
 		return fmt.Errorf("failed to marshal response: %w", err)
 	}
 	
+	data, err = c.compress(data)
+	if err != nil {
+		return err
+	}
+	
 	return c.client.Set(ctx, fullKey, data, ttl)
 }
 
@@ -349,23 +359,26 @@ func (c *RedisCache) Delete(ctx context.Context, key string) error {
 }
 
 func (c *RedisCache) GetEmbedding(ctx context.Context, key string) (*types.EmbeddingResponse, bool) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	
 	fullKey := c.keyPrefix + ":emb:" + key
 	data, err := c.client.Get(ctx, fullKey)
 	if err != nil || data == "" {
-		c.stats.Misses++
+		c.counters.record(ctx, CacheTypeEmbedding, false)
+		return nil, false
+	}
+	
+	decoded, err := decodeCacheEntry([]byte(data))
+	if err != nil {
+		c.counters.record(ctx, CacheTypeEmbedding, false)
 		return nil, false
 	}
 	
 	var response types.EmbeddingResponse
-	if err := json.Unmarshal([]byte(data), &response); err != nil {
-		c.stats.Misses++
+	if err := json.Unmarshal(decoded, &response); err != nil {
+		c.counters.record(ctx, CacheTypeEmbedding, false)
 		return nil, false
 	}
 	
-	c.stats.Hits++
+	c.counters.record(ctx, CacheTypeEmbedding, true)
 	return &response, true
 }
 
@@ -377,20 +390,44 @@ func (c *RedisCache) SetEmbedding(ctx context.Context, key string, response *typ
 		return fmt.Errorf("failed to marshal embedding response: %w", err)
 	}
 	
+	data, err = c.compress(data)
+	if err != nil {
+		return err
+	}
+	
 	return c.client.Set(ctx, fullKey, data, ttl)
 }
 
+// compress applies the configured compression to an encoded entry and
+// records the sizes behind the compression ratio
+func (c *RedisCache) compress(data []byte) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	
+	encoded, compressed, err := c.compression.encode(data)
+	if err != nil {
+		return nil, err
+	}
+	if compressed {
+		c.stats.CompressedEntries++
+		c.stats.UncompressedBytes += int64(len(data))
+		c.stats.CompressedBytes += int64(len(encoded))
+	}
+	return encoded, nil
+}
+
 func (c *RedisCache) Clear(ctx context.Context) error {
 	return c.client.FlushAll(ctx)
 }
 
 func (c *RedisCache) Stats() types.CacheStats {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
-	
 	stats := c.stats
-	if stats.Hits+stats.Misses > 0 {
-		stats.HitRate = float64(stats.Hits) / float64(stats.Hits+stats.Misses)
+	c.mu.RUnlock()
+	
+	c.counters.fill(&stats)
+	if stats.CompressedBytes > 0 {
+		stats.CompressionRatio = float64(stats.UncompressedBytes) / float64(stats.CompressedBytes)
 	}
 	
 	return stats
@@ -400,13 +437,20 @@ func (c *RedisCache) Close() error {
 	return c.client.Close()
 }
 
-// Configure implements the Cache interface for RedisCache
+// Configure implements the Cache interface for RedisCache. Changing the
+// compression only affects new entries; existing ones still decode.
 func (c *RedisCache) Configure(config types.CacheConfig) error {
+	compression, err := newCacheCompression(config.Compression, config.CompressionMinBytes)
+	if err != nil {
+		return err
+	}
+	
+	c.counters.trackTenants.Store(config.TenantStats)
+	
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	
-	// Update configuration as needed
-	// For now, this is a placeholder
+	c.compression = compression
 	return nil
 }
 
@@ -525,6 +569,7 @@ func CacheMiddleware(cache Cache, config *types.ClientConfig) func(next Completi
 			
 			// Generate cache key
 			key := GenerateCompletionCacheKey(req)
+			ctx = WithCacheTenant(ctx, req.TenantID)
 			
 			// Try to get from cache
 			if cached, found := cache.Get(ctx, key); found {
@@ -567,6 +612,7 @@ func EmbeddingCacheMiddleware(cache Cache, config *types.ClientConfig) func(next
 			
 			// Generate cache key
 			key := GenerateEmbeddingCacheKey(req)
+			ctx = WithCacheTenant(ctx, req.TenantID)
 			
 			// Try to get from cache
 			if cached, found := cache.GetEmbedding(ctx, key); found {
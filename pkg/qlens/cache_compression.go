@@ -0,0 +1,107 @@
+package qlens
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// Cache compression algorithms accepted by RedisCache.Configure. Only gzip
+// is implemented. zstd is rejected rather than offered: neither the standard
+// library nor the module's dependencies provide a codec for it, and adding
+// one is left for when it can be vendored; cacheHeaderZstd keeps room for it.
+const (
+	CacheCompressionNone = "none"
+	CacheCompressionGzip = "gzip"
+)
+
+// DefaultCacheCompressionMinBytes is the smallest encoded entry worth
+// compressing; below it the gzip framing costs more than it saves
+const DefaultCacheCompressionMinBytes = 1024
+
+// Compressed entries start with a header byte naming the algorithm. Entries
+// written uncompressed, including those from before compression existed,
+// are plain JSON and always start with '{', so both decode side by side.
+const (
+	cacheHeaderGzip byte = 0x01
+	// cacheHeaderZstd is reserved for zstd, which this build cannot decode
+	cacheHeaderZstd byte = 0x02
+)
+
+// cacheCompression holds the compression settings of a RedisCache
+type cacheCompression struct {
+	algorithm string
+	minBytes  int
+}
+
+// newCacheCompression validates the configured algorithm and threshold
+func newCacheCompression(algorithm string, minBytes int) (cacheCompression, error) {
+	switch algorithm {
+	case "", CacheCompressionNone:
+		return cacheCompression{}, nil
+	case CacheCompressionGzip:
+	case "zstd":
+		return cacheCompression{}, fmt.Errorf("zstd cache compression is not supported, use %q", CacheCompressionGzip)
+	default:
+		return cacheCompression{}, fmt.Errorf("unsupported cache compression %q", algorithm)
+	}
+
+	if minBytes < 0 {
+		return cacheCompression{}, fmt.Errorf("cache compression threshold must not be negative")
+	}
+	if minBytes == 0 {
+		minBytes = DefaultCacheCompressionMinBytes
+	}
+	return cacheCompression{algorithm: algorithm, minBytes: minBytes}, nil
+}
+
+// encode compresses data when it is large enough and compression actually
+// shrinks it. The boolean reports whether the result is compressed.
+func (cc cacheCompression) encode(data []byte) ([]byte, bool, error) {
+	if cc.algorithm != CacheCompressionGzip || len(data) < cc.minBytes {
+		return data, false, nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(cacheHeaderGzip)
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(data); err != nil {
+		return nil, false, fmt.Errorf("failed to compress cache entry: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, false, fmt.Errorf("failed to compress cache entry: %w", err)
+	}
+
+	if buf.Len() >= len(data) {
+		return data, false, nil
+	}
+	return buf.Bytes(), true, nil
+}
+
+// decodeCacheEntry returns the JSON of a stored entry, decompressing it when
+// it carries a compression header
+func decodeCacheEntry(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	switch data[0] {
+	case cacheHeaderGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(data[1:]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress cache entry: %w", err)
+		}
+		defer reader.Close()
+
+		decoded, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress cache entry: %w", err)
+		}
+		return decoded, nil
+	case cacheHeaderZstd:
+		return nil, fmt.Errorf("zstd cache entries are not supported")
+	default:
+		return data, nil
+	}
+}
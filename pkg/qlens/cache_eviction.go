@@ -0,0 +1,229 @@
+package qlens
+
+import (
+	"container/heap"
+	"container/list"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Eviction policies of the in-memory cache, choosing the entry to drop when
+// it is full
+const (
+	// CacheEvictionLRU evicts the least recently used entry
+	CacheEvictionLRU = "lru"
+	// CacheEvictionLFU evicts the least frequently used entry, the least
+	// recently used of them on a tie
+	CacheEvictionLFU = "lfu"
+	// CacheEvictionTTL evicts the entry closest to expiring
+	CacheEvictionTTL = "ttl"
+)
+
+// evictionPolicy tracks the entries of an in-memory cache to pick the one
+// to evict. The LRU and LFU policies do so in constant time and the TTL
+// policy in logarithmic time, so a full cache does not scan its entries on
+// every write. Callers hold the cache's write lock.
+type evictionPolicy interface {
+	add(entry *CacheEntry)
+	touch(entry *CacheEntry)
+	remove(key string)
+	victim() (string, bool)
+}
+
+func newEvictionPolicy(name string) (evictionPolicy, error) {
+	switch name {
+	case "", CacheEvictionLRU:
+		return &lruPolicy{order: list.New(), elements: make(map[string]*list.Element)}, nil
+	case CacheEvictionLFU:
+		return &lfuPolicy{buckets: make(map[int64]*list.List), nodes: make(map[string]*lfuNode)}, nil
+	case CacheEvictionTTL:
+		return &ttlPolicy{items: make(map[string]*ttlItem)}, nil
+	}
+	return nil, fmt.Errorf("unsupported cache eviction policy %q", name)
+}
+
+// rebuildEvictionPolicy creates a policy tracking the given entries, added
+// least recently used first so their recency carries over
+func rebuildEvictionPolicy(name string, entries map[string]*CacheEntry) (evictionPolicy, error) {
+	policy, err := newEvictionPolicy(name)
+	if err != nil {
+		return nil, err
+	}
+	ordered := make([]*CacheEntry, 0, len(entries))
+	for _, entry := range entries {
+		ordered = append(ordered, entry)
+	}
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].LastAccessed.Before(ordered[j].LastAccessed) })
+	for _, entry := range ordered {
+		policy.add(entry)
+	}
+	return policy, nil
+}
+
+// lruPolicy keeps keys in order of use, most recent at the front
+type lruPolicy struct {
+	order    *list.List
+	elements map[string]*list.Element
+}
+
+func (p *lruPolicy) add(entry *CacheEntry) {
+	p.elements[entry.Key] = p.order.PushFront(entry.Key)
+}
+
+func (p *lruPolicy) touch(entry *CacheEntry) {
+	if element, ok := p.elements[entry.Key]; ok {
+		p.order.MoveToFront(element)
+	}
+}
+
+func (p *lruPolicy) remove(key string) {
+	if element, ok := p.elements[key]; ok {
+		p.order.Remove(element)
+		delete(p.elements, key)
+	}
+}
+
+func (p *lruPolicy) victim() (string, bool) {
+	back := p.order.Back()
+	if back == nil {
+		return "", false
+	}
+	return back.Value.(string), true
+}
+
+// lfuPolicy keeps a list of keys per use count, each most recently used
+// first, and the lowest count in use
+type lfuPolicy struct {
+	buckets  map[int64]*list.List
+	nodes    map[string]*lfuNode
+	minCount int64
+}
+
+type lfuNode struct {
+	count   int64
+	element *list.Element
+}
+
+func (p *lfuPolicy) add(entry *CacheEntry) {
+	count := entry.AccessCount + 1
+	p.nodes[entry.Key] = &lfuNode{count: count, element: p.bucket(count).PushFront(entry.Key)}
+	if len(p.nodes) == 1 || count < p.minCount {
+		p.minCount = count
+	}
+}
+
+func (p *lfuPolicy) touch(entry *CacheEntry) {
+	node, ok := p.nodes[entry.Key]
+	if !ok {
+		return
+	}
+	p.unlink(node)
+	if node.count == p.minCount && p.buckets[node.count] == nil {
+		p.minCount++
+	}
+	node.count++
+	node.element = p.bucket(node.count).PushFront(entry.Key)
+}
+
+func (p *lfuPolicy) remove(key string) {
+	if node, ok := p.nodes[key]; ok {
+		p.unlink(node)
+		delete(p.nodes, key)
+	}
+}
+
+func (p *lfuPolicy) victim() (string, bool) {
+	if len(p.nodes) == 0 {
+		return "", false
+	}
+	bucket := p.buckets[p.minCount]
+	if bucket == nil {
+		// The least used keys were removed; find the next lowest count
+		first := true
+		for count := range p.buckets {
+			if first || count < p.minCount {
+				p.minCount, first = count, false
+			}
+		}
+		bucket = p.buckets[p.minCount]
+	}
+	return bucket.Back().Value.(string), true
+}
+
+func (p *lfuPolicy) bucket(count int64) *list.List {
+	bucket, ok := p.buckets[count]
+	if !ok {
+		bucket = list.New()
+		p.buckets[count] = bucket
+	}
+	return bucket
+}
+
+// unlink takes a node out of its bucket, dropping the bucket once empty
+func (p *lfuPolicy) unlink(node *lfuNode) {
+	bucket := p.buckets[node.count]
+	bucket.Remove(node.element)
+	if bucket.Len() == 0 {
+		delete(p.buckets, node.count)
+	}
+}
+
+// ttlPolicy keeps keys in a min-heap by expiry
+type ttlPolicy struct {
+	heap  ttlHeap
+	items map[string]*ttlItem
+}
+
+type ttlItem struct {
+	key       string
+	expiresAt time.Time
+	index     int
+}
+
+func (p *ttlPolicy) add(entry *CacheEntry) {
+	item := &ttlItem{key: entry.Key, expiresAt: entry.ExpiresAt}
+	p.items[entry.Key] = item
+	heap.Push(&p.heap, item)
+}
+
+func (p *ttlPolicy) touch(entry *CacheEntry) {}
+
+func (p *ttlPolicy) remove(key string) {
+	if item, ok := p.items[key]; ok {
+		heap.Remove(&p.heap, item.index)
+		delete(p.items, key)
+	}
+}
+
+func (p *ttlPolicy) victim() (string, bool) {
+	if len(p.heap) == 0 {
+		return "", false
+	}
+	return p.heap[0].key, true
+}
+
+type ttlHeap []*ttlItem
+
+func (h ttlHeap) Len() int           { return len(h) }
+func (h ttlHeap) Less(i, j int) bool { return h[i].expiresAt.Before(h[j].expiresAt) }
+
+func (h ttlHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *ttlHeap) Push(x interface{}) {
+	item := x.(*ttlItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *ttlHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,172 @@
+package qlens
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+func newEvictionTestCache(t *testing.T, policy string, maxSize int) *InMemoryCache {
+	cache := NewInMemoryCache(maxSize)
+	t.Cleanup(func() { cache.Close() })
+	require.NoError(t, cache.Configure(types.CacheConfig{EvictionPolicy: policy}))
+	return cache
+}
+
+func cachedKeys(cache *InMemoryCache, keys ...string) []string {
+	var present []string
+	for _, key := range keys {
+		if _, ok := cache.entries[key]; ok {
+			present = append(present, key)
+		}
+	}
+	return present
+}
+
+func TestInMemoryCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	cache := newEvictionTestCache(t, CacheEvictionLRU, 3)
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, cache.Set(ctx, key, newCompletionResponse(key), time.Minute))
+	}
+	_, ok := cache.Get(ctx, "a")
+	require.True(t, ok)
+
+	require.NoError(t, cache.Set(ctx, "d", newCompletionResponse("d"), time.Minute))
+	assert.Equal(t, []string{"a", "c", "d"}, cachedKeys(cache, "a", "b", "c", "d"))
+	assert.Equal(t, int64(1), cache.Stats().Evictions)
+}
+
+func TestInMemoryCache_EvictsLeastFrequentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	cache := newEvictionTestCache(t, CacheEvictionLFU, 3)
+
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, cache.Set(ctx, key, newCompletionResponse(key), time.Minute))
+	}
+	cache.Get(ctx, "a")
+	cache.Get(ctx, "a")
+	cache.Get(ctx, "b")
+	cache.Get(ctx, "c")
+
+	// b and c were used as often; b was used longer ago
+	require.NoError(t, cache.Set(ctx, "d", newCompletionResponse("d"), time.Minute))
+	assert.Equal(t, []string{"a", "c", "d"}, cachedKeys(cache, "a", "b", "c", "d"))
+
+	// The new entry has been used least
+	require.NoError(t, cache.Set(ctx, "e", newCompletionResponse("e"), time.Minute))
+	assert.Equal(t, []string{"a", "c", "e"}, cachedKeys(cache, "a", "c", "d", "e"))
+}
+
+func TestInMemoryCache_EvictsClosestToExpiring(t *testing.T) {
+	ctx := context.Background()
+	cache := newEvictionTestCache(t, CacheEvictionTTL, 3)
+
+	require.NoError(t, cache.Set(ctx, "a", newCompletionResponse("a"), time.Hour))
+	require.NoError(t, cache.Set(ctx, "b", newCompletionResponse("b"), time.Minute))
+	require.NoError(t, cache.Set(ctx, "c", newCompletionResponse("c"), 2*time.Hour))
+	cache.Get(ctx, "a")
+
+	require.NoError(t, cache.Set(ctx, "d", newCompletionResponse("d"), 3*time.Hour))
+	assert.Equal(t, []string{"a", "c", "d"}, cachedKeys(cache, "a", "b", "c", "d"))
+}
+
+func TestInMemoryCache_OverwriteDoesNotEvict(t *testing.T) {
+	ctx := context.Background()
+
+	for _, policy := range []string{CacheEvictionLRU, CacheEvictionLFU, CacheEvictionTTL} {
+		t.Run(policy, func(t *testing.T) {
+			cache := newEvictionTestCache(t, policy, 2)
+			require.NoError(t, cache.Set(ctx, "a", newCompletionResponse("a"), time.Minute))
+			require.NoError(t, cache.Set(ctx, "b", newCompletionResponse("b"), time.Minute))
+			require.NoError(t, cache.Set(ctx, "a", newCompletionResponse("a2"), time.Minute))
+
+			assert.Equal(t, []string{"a", "b"}, cachedKeys(cache, "a", "b"))
+			assert.Equal(t, int64(0), cache.Stats().Evictions)
+
+			// Deleted entries are never picked for eviction
+			require.NoError(t, cache.Delete(ctx, "b"))
+			require.NoError(t, cache.Set(ctx, "c", newCompletionResponse("c"), time.Minute))
+			assert.Equal(t, []string{"a", "c"}, cachedKeys(cache, "a", "b", "c"))
+			assert.Equal(t, int64(0), cache.Stats().Evictions)
+		})
+	}
+}
+
+func TestInMemoryCache_ConfigureKeepsEntriesWhenSwitchingPolicy(t *testing.T) {
+	ctx := context.Background()
+	cache := newEvictionTestCache(t, CacheEvictionLRU, 2)
+
+	require.NoError(t, cache.Set(ctx, "a", newCompletionResponse("a"), time.Minute))
+	require.NoError(t, cache.Set(ctx, "b", newCompletionResponse("b"), time.Minute))
+	cache.Get(ctx, "a")
+
+	require.NoError(t, cache.Configure(types.CacheConfig{EvictionPolicy: CacheEvictionLFU}))
+	require.NoError(t, cache.Set(ctx, "c", newCompletionResponse("c"), time.Minute))
+	assert.Equal(t, []string{"a", "c"}, cachedKeys(cache, "a", "b", "c"))
+}
+
+func TestInMemoryCache_ConfigureRejectsUnknownEvictionPolicy(t *testing.T) {
+	cache := newEvictionTestCache(t, CacheEvictionLRU, 2)
+
+	err := cache.Configure(types.CacheConfig{EvictionPolicy: "random"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "random")
+	assert.Equal(t, CacheEvictionLRU, cache.policyName)
+}
+
+// linearScanPolicy evicts the least recently used entry by scanning every
+// entry, as the cache did before it kept its entries in order
+type linearScanPolicy struct {
+	entries map[string]*CacheEntry
+}
+
+func (p *linearScanPolicy) add(entry *CacheEntry)   { p.entries[entry.Key] = entry }
+func (p *linearScanPolicy) touch(entry *CacheEntry) {}
+func (p *linearScanPolicy) remove(key string)       { delete(p.entries, key) }
+
+func (p *linearScanPolicy) victim() (string, bool) {
+	var oldestKey string
+	oldest := time.Now()
+	for key, entry := range p.entries {
+		if entry.LastAccessed.Before(oldest) {
+			oldest, oldestKey = entry.LastAccessed, key
+		}
+	}
+	return oldestKey, oldestKey != ""
+}
+
+// BenchmarkInMemoryCache_SetFull measures writes to a full cache of 100k
+// entries, each of which evicts one
+func BenchmarkInMemoryCache_SetFull(b *testing.B) {
+	const size = 100000
+	ctx := context.Background()
+	response := newCompletionResponse("cached")
+
+	for _, policy := range []string{"linear_scan", CacheEvictionLRU, CacheEvictionLFU, CacheEvictionTTL} {
+		b.Run(policy, func(b *testing.B) {
+			cache := NewInMemoryCache(size)
+			defer cache.Close()
+			if policy == "linear_scan" {
+				cache.policy = &linearScanPolicy{entries: make(map[string]*CacheEntry)}
+			} else if err := cache.Configure(types.CacheConfig{EvictionPolicy: policy}); err != nil {
+				b.Fatal(err)
+			}
+			for i := 0; i < size; i++ {
+				cache.Set(ctx, fmt.Sprintf("key-%d", i), response, time.Hour)
+			}
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				cache.Set(ctx, fmt.Sprintf("new-%d", i), response, time.Hour)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package qlens
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+// Cache entry types, as reported in CacheStats.ByType
+const (
+	CacheTypeCompletion = "completion"
+	CacheTypeEmbedding  = "embedding"
+)
+
+type cacheTenantKey struct{}
+
+// WithCacheTenant attributes the cache lookups made with ctx to a tenant.
+// The cache middlewares set it from the request.
+func WithCacheTenant(ctx context.Context, tenantID domain.TenantID) context.Context {
+	if tenantID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, cacheTenantKey{}, tenantID)
+}
+
+// cacheTenant returns the tenant a lookup was made for, if known
+func cacheTenant(ctx context.Context) domain.TenantID {
+	tenantID, _ := ctx.Value(cacheTenantKey{}).(domain.TenantID)
+	return tenantID
+}
+
+// hitCounters counts the hits and misses of one entry type or tenant
+type hitCounters struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func (h *hitCounters) record(hit bool) {
+	if hit {
+		h.hits.Add(1)
+	} else {
+		h.misses.Add(1)
+	}
+}
+
+func (h *hitCounters) stats() types.CacheHitStats {
+	stats := types.CacheHitStats{Hits: h.hits.Load(), Misses: h.misses.Load()}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// cacheCounters counts cache lookups and evictions. The counters are atomic
+// so lookups can record them whichever lock they hold, and Stats can read
+// them without blocking lookups.
+type cacheCounters struct {
+	total        hitCounters
+	evictions    atomic.Int64
+	byType       sync.Map // string -> *hitCounters
+	byTenant     sync.Map // domain.TenantID -> *hitCounters
+	trackTenants atomic.Bool
+}
+
+// record counts a lookup of an entry type
+func (c *cacheCounters) record(ctx context.Context, entryType string, hit bool) {
+	c.total.record(hit)
+	counters, _ := c.byType.LoadOrStore(entryType, &hitCounters{})
+	counters.(*hitCounters).record(hit)
+
+	if !c.trackTenants.Load() {
+		return
+	}
+	if tenantID := cacheTenant(ctx); tenantID != "" {
+		counters, _ := c.byTenant.LoadOrStore(tenantID, &hitCounters{})
+		counters.(*hitCounters).record(hit)
+	}
+}
+
+// fill copies the counters into stats
+func (c *cacheCounters) fill(stats *types.CacheStats) {
+	total := c.total.stats()
+	stats.Hits, stats.Misses, stats.HitRate = total.Hits, total.Misses, total.HitRate
+	stats.Evictions = c.evictions.Load()
+
+	c.byType.Range(func(key, value interface{}) bool {
+		if stats.ByType == nil {
+			stats.ByType = make(map[string]types.CacheHitStats)
+		}
+		stats.ByType[key.(string)] = value.(*hitCounters).stats()
+		return true
+	})
+	c.byTenant.Range(func(key, value interface{}) bool {
+		if stats.ByTenant == nil {
+			stats.ByTenant = make(map[string]types.CacheHitStats)
+		}
+		stats.ByTenant[string(key.(domain.TenantID))] = value.(*hitCounters).stats()
+		return true
+	})
+}
+
+// CachePrometheusMetrics renders cache stats in Prometheus format, with the
+// hits and misses of every entry type and tracked tenant
+func CachePrometheusMetrics(stats types.CacheStats) string {
+	var b strings.Builder
+
+	b.WriteString("# HELP qlens_cache_entries Entries in the cache\n")
+	b.WriteString("# TYPE qlens_cache_entries gauge\n")
+	fmt.Fprintf(&b, "qlens_cache_entries %d\n", stats.Size)
+
+	b.WriteString("# HELP qlens_cache_evictions_total Entries evicted from the cache\n")
+	b.WriteString("# TYPE qlens_cache_evictions_total counter\n")
+	fmt.Fprintf(&b, "qlens_cache_evictions_total %d\n", stats.Evictions)
+
+	b.WriteString("# HELP qlens_cache_lookups_total Cache lookups by entry type and result\n")
+	b.WriteString("# TYPE qlens_cache_lookups_total counter\n")
+	for _, entryType := range sortedCacheStatKeys(stats.ByType) {
+		counts := stats.ByType[entryType]
+		fmt.Fprintf(&b, "qlens_cache_lookups_total{type=%q,result=\"hit\"} %d\n", entryType, counts.Hits)
+		fmt.Fprintf(&b, "qlens_cache_lookups_total{type=%q,result=\"miss\"} %d\n", entryType, counts.Misses)
+	}
+
+	if len(stats.ByTenant) > 0 {
+		b.WriteString("# HELP qlens_cache_tenant_lookups_total Cache lookups by tenant and result\n")
+		b.WriteString("# TYPE qlens_cache_tenant_lookups_total counter\n")
+		for _, tenantID := range sortedCacheStatKeys(stats.ByTenant) {
+			counts := stats.ByTenant[tenantID]
+			fmt.Fprintf(&b, "qlens_cache_tenant_lookups_total{tenant_id=%q,result=\"hit\"} %d\n", tenantID, counts.Hits)
+			fmt.Fprintf(&b, "qlens_cache_tenant_lookups_total{tenant_id=%q,result=\"miss\"} %d\n", tenantID, counts.Misses)
+		}
+	}
+
+	if stats.CompressedEntries > 0 {
+		b.WriteString("# HELP qlens_cache_compressed_entries_total Entries stored compressed\n")
+		b.WriteString("# TYPE qlens_cache_compressed_entries_total counter\n")
+		fmt.Fprintf(&b, "qlens_cache_compressed_entries_total %d\n", stats.CompressedEntries)
+
+		b.WriteString("# HELP qlens_cache_compression_bytes_total Size of compressed entries before and after compression\n")
+		b.WriteString("# TYPE qlens_cache_compression_bytes_total counter\n")
+		fmt.Fprintf(&b, "qlens_cache_compression_bytes_total{stage=\"uncompressed\"} %d\n", stats.UncompressedBytes)
+		fmt.Fprintf(&b, "qlens_cache_compression_bytes_total{stage=\"compressed\"} %d\n", stats.CompressedBytes)
+
+		b.WriteString("# HELP qlens_cache_compression_ratio Uncompressed over compressed bytes of compressed entries\n")
+		b.WriteString("# TYPE qlens_cache_compression_ratio gauge\n")
+		fmt.Fprintf(&b, "qlens_cache_compression_ratio %f\n", stats.CompressionRatio)
+	}
+	return b.String()
+}
+
+func sortedCacheStatKeys(m map[string]types.CacheHitStats) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,109 @@
+package qlens
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+func TestInMemoryCache_ConcurrentLookups(t *testing.T) {
+	cache := NewInMemoryCache(100)
+	defer cache.Close()
+	require.NoError(t, cache.Configure(types.CacheConfig{TenantStats: true}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ctx := WithCacheTenant(context.Background(), "tenant-a")
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("key-%d", j%10)
+				if _, ok := cache.Get(ctx, key); !ok {
+					_ = cache.Set(ctx, key, newCompletionResponse("hi"), time.Minute)
+				}
+				cache.GetEmbedding(ctx, key)
+				cache.Stats()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(8*100*2), stats.Hits+stats.Misses)
+	assert.Equal(t, int64(8*100), stats.ByType[CacheTypeCompletion].Hits+stats.ByType[CacheTypeCompletion].Misses)
+	assert.Equal(t, int64(8*100), stats.ByType[CacheTypeEmbedding].Misses, "completion entries are not embeddings")
+	assert.Equal(t, stats.Hits, stats.ByTenant["tenant-a"].Hits)
+}
+
+func TestInMemoryCache_StatsByTypeAndTenant(t *testing.T) {
+	ctx := context.Background()
+	cache := NewInMemoryCache(100)
+	defer cache.Close()
+
+	tenantA := WithCacheTenant(ctx, "tenant-a")
+	require.NoError(t, cache.Set(tenantA, "completion", newCompletionResponse("hi"), time.Minute))
+	require.NoError(t, cache.SetEmbedding(tenantA, "embedding", &types.EmbeddingResponse{}, time.Minute))
+
+	// Tenants are only tracked once configured
+	cache.Get(tenantA, "completion")
+	assert.Empty(t, cache.Stats().ByTenant)
+
+	require.NoError(t, cache.Configure(types.CacheConfig{TenantStats: true}))
+	cache.Get(tenantA, "completion")
+	cache.Get(WithCacheTenant(ctx, "tenant-b"), "missing")
+	cache.GetEmbedding(tenantA, "embedding")
+	cache.GetEmbedding(ctx, "missing")
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(3), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, 2, stats.Size)
+	assert.Equal(t, types.CacheHitStats{Hits: 2, Misses: 1, HitRate: 2.0 / 3}, stats.ByType[CacheTypeCompletion])
+	assert.Equal(t, types.CacheHitStats{Hits: 1, Misses: 1, HitRate: 0.5}, stats.ByType[CacheTypeEmbedding])
+	assert.Equal(t, map[string]types.CacheHitStats{
+		"tenant-a": {Hits: 2, HitRate: 1},
+		"tenant-b": {Misses: 1},
+	}, stats.ByTenant)
+
+	metrics := CachePrometheusMetrics(stats)
+	assert.Contains(t, metrics, `qlens_cache_lookups_total{type="completion",result="hit"} 2`)
+	assert.Contains(t, metrics, `qlens_cache_lookups_total{type="embedding",result="miss"} 1`)
+	assert.Contains(t, metrics, `qlens_cache_tenant_lookups_total{tenant_id="tenant-b",result="miss"} 1`)
+	assert.Contains(t, metrics, "qlens_cache_entries 2")
+	assert.NotContains(t, metrics, "qlens_cache_compression_ratio")
+}
+
+func TestRedisCache_StatsByType(t *testing.T) {
+	ctx := context.Background()
+	cache := NewRedisCache(newFakeRedisClient(), "qlens")
+	require.NoError(t, cache.Set(ctx, "completion", newCompletionResponse("hi"), time.Minute))
+
+	cache.Get(ctx, "completion")
+	cache.Get(ctx, "missing")
+	cache.GetEmbedding(ctx, "missing")
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(2), stats.Misses)
+	assert.Equal(t, types.CacheHitStats{Hits: 1, Misses: 1, HitRate: 0.5}, stats.ByType[CacheTypeCompletion])
+	assert.Equal(t, int64(1), stats.ByType[CacheTypeEmbedding].Misses)
+}
+
+func TestMetricsCollector_IncludesWatchedCache(t *testing.T) {
+	cache := NewInMemoryCache(10)
+	defer cache.Close()
+	cache.Get(context.Background(), "missing")
+
+	metrics := NewMetricsCollector()
+	assert.NotContains(t, metrics.GetPrometheusMetrics(), "qlens_cache_lookups_total")
+	metrics.WatchCache(cache)
+	assert.Contains(t, metrics.GetPrometheusMetrics(), `qlens_cache_lookups_total{type="completion",result="miss"} 1`)
+}
@@ -0,0 +1,138 @@
+package qlens
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+// fakeRedisClient stores values the way go-redis round-trips them
+type fakeRedisClient struct {
+	values map[string]string
+}
+
+func newFakeRedisClient() *fakeRedisClient {
+	return &fakeRedisClient{values: make(map[string]string)}
+}
+
+func (f *fakeRedisClient) Get(ctx context.Context, key string) (string, error) {
+	return f.values[key], nil
+}
+
+func (f *fakeRedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	switch v := value.(type) {
+	case []byte:
+		f.values[key] = string(v)
+	case string:
+		f.values[key] = v
+	}
+	return nil
+}
+
+func (f *fakeRedisClient) Del(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(f.values, key)
+	}
+	return nil
+}
+
+func (f *fakeRedisClient) FlushAll(ctx context.Context) error {
+	f.values = make(map[string]string)
+	return nil
+}
+
+func (f *fakeRedisClient) Close() error { return nil }
+
+func newCompletionResponse(content string) *types.CompletionResponse {
+	return &types.CompletionResponse{
+		ID:    "cmpl-1",
+		Model: "gpt-4",
+		Choices: []domain.Choice{{
+			Message: domain.Message{
+				Role:    domain.MessageRoleAssistant,
+				Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: content}},
+			},
+		}},
+	}
+}
+
+func TestRedisCache_CompressesLargeEntries(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeRedisClient()
+	cache := NewRedisCache(client, "qlens")
+	require.NoError(t, cache.Configure(types.CacheConfig{Compression: CacheCompressionGzip, CompressionMinBytes: 2048}))
+
+	large := newCompletionResponse(strings.Repeat("the quick brown fox ", 200))
+	require.NoError(t, cache.Set(ctx, "large", large, time.Minute))
+	require.NoError(t, cache.Set(ctx, "small", newCompletionResponse("hi"), time.Minute))
+
+	assert.Equal(t, cacheHeaderGzip, client.values["qlens:large"][0])
+	assert.Equal(t, byte('{'), client.values["qlens:small"][0])
+
+	cached, ok := cache.Get(ctx, "large")
+	require.True(t, ok)
+	assert.Equal(t, large.Choices[0].Message.Content[0].Text, cached.Choices[0].Message.Content[0].Text)
+	assert.True(t, cached.CacheHit)
+
+	cached, ok = cache.Get(ctx, "small")
+	require.True(t, ok)
+	assert.Equal(t, "hi", cached.Choices[0].Message.Content[0].Text)
+
+	stats := cache.Stats()
+	assert.Equal(t, int64(1), stats.CompressedEntries)
+	assert.Greater(t, stats.UncompressedBytes, stats.CompressedBytes)
+	assert.Greater(t, stats.CompressionRatio, 1.0)
+
+	metrics := CachePrometheusMetrics(stats)
+	assert.Contains(t, metrics, "qlens_cache_compressed_entries_total 1")
+	assert.Contains(t, metrics, fmt.Sprintf("qlens_cache_compression_ratio %f", stats.CompressionRatio))
+	assert.Contains(t, metrics, fmt.Sprintf(`qlens_cache_compression_bytes_total{stage="compressed"} %d`, stats.CompressedBytes))
+}
+
+func TestRedisCache_ReadsUncompressedEntries(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeRedisClient()
+
+	// Entries written before compression was enabled are plain JSON
+	legacy, err := json.Marshal(newCompletionResponse(strings.Repeat("legacy ", 500)))
+	require.NoError(t, err)
+	client.values["qlens:legacy"] = string(legacy)
+
+	cache := NewRedisCache(client, "qlens")
+	require.NoError(t, cache.Configure(types.CacheConfig{Compression: CacheCompressionGzip}))
+
+	_, ok := cache.Get(ctx, "legacy")
+	assert.True(t, ok)
+
+	// Disabling compression later still reads compressed entries
+	embedding := &types.EmbeddingResponse{
+		Object: "list",
+		Data:   []domain.Embedding{{Object: "embedding", Embedding: make([]float64, 1536)}},
+	}
+	require.NoError(t, cache.SetEmbedding(ctx, "emb", embedding, time.Minute))
+	assert.Equal(t, cacheHeaderGzip, client.values["qlens:emb:emb"][0])
+
+	require.NoError(t, cache.Configure(types.CacheConfig{}))
+	cachedEmbedding, ok := cache.GetEmbedding(ctx, "emb")
+	require.True(t, ok)
+	assert.Len(t, cachedEmbedding.Data[0].Embedding, 1536)
+}
+
+func TestRedisCache_ConfigureRejectsUnknownCompression(t *testing.T) {
+	cache := NewRedisCache(newFakeRedisClient(), "qlens")
+
+	err := cache.Configure(types.CacheConfig{Compression: "zstd"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported")
+	assert.Error(t, cache.Configure(types.CacheConfig{Compression: "brotli"}))
+	assert.Error(t, cache.Configure(types.CacheConfig{Compression: CacheCompressionGzip, CompressionMinBytes: -1}))
+}
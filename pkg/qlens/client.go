@@ -5,6 +5,7 @@ import (
 
 	"github.com/quantum-suite/platform/internal/domain"
 	"github.com/quantum-suite/platform/pkg/qlens-types"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
 )
 
 // Interfaces are now in interfaces.go to avoid circular imports
@@ -39,6 +40,48 @@ func WithCaching(enabled bool, ttl time.Duration) ClientOption {
 	}
 }
 
+// WithCacheCompression compresses Redis cache entries of at least minBytes
+// with the given algorithm, CacheCompressionGzip or CacheCompressionNone;
+// zero uses DefaultCacheCompressionMinBytes
+func WithCacheCompression(algorithm string, minBytes int) ClientOption {
+	return func(c *types.ClientConfig) {
+		c.CacheCompression = algorithm
+		c.CacheCompressionMinBytes = minBytes
+	}
+}
+
+// WithCacheTenantStats breaks cache hits and misses down by tenant in the
+// cache's stats and metrics
+func WithCacheTenantStats(enabled bool) ClientOption {
+	return func(c *types.ClientConfig) {
+		c.CacheTenantStats = enabled
+	}
+}
+
+// WithCacheEvictionPolicy chooses which entry the in-memory cache evicts
+// when full: CacheEvictionLRU, CacheEvictionLFU or CacheEvictionTTL
+func WithCacheEvictionPolicy(policy string) ClientOption {
+	return func(c *types.ClientConfig) {
+		c.CacheEvictionPolicy = policy
+	}
+}
+
+// WithEmbeddingStore sets how long per-input embeddings are stored; zero
+// disables the store
+func WithEmbeddingStore(ttl time.Duration) ClientOption {
+	return func(c *types.ClientConfig) {
+		c.EmbeddingStoreTTL = ttl
+	}
+}
+
+// WithEmbeddingStorePath keeps per-input embeddings in the file at path so
+// they survive restarts
+func WithEmbeddingStorePath(path string) ClientOption {
+	return func(c *types.ClientConfig) {
+		c.EmbeddingStorePath = path
+	}
+}
+
 // WithTimeout sets the default timeout
 func WithTimeout(timeout time.Duration) ClientOption {
 	return func(c *types.ClientConfig) {
@@ -54,6 +97,14 @@ func WithRetries(maxRetries int, backoff time.Duration) ClientOption {
 	}
 }
 
+// WithRetryableStatusCodes sets the provider HTTP statuses that are retried
+// whatever the error type. No codes leaves retries to the error type alone.
+func WithRetryableStatusCodes(codes ...int) ClientOption {
+	return func(c *types.ClientConfig) {
+		c.RetryableStatusCodes = codes
+	}
+}
+
 // WithObservability enables metrics and tracing
 func WithObservability(metrics, tracing bool) ClientOption {
 	return func(c *types.ClientConfig) {
@@ -67,19 +118,21 @@ func WithObservability(metrics, tracing bool) ClientOption {
 // DefaultClientConfig returns a default configuration
 func DefaultClientConfig() *types.ClientConfig {
 	return &types.ClientConfig{
-		Providers:         make(map[domain.Provider]types.ProviderConfig),
-		AutoFailover:      true,
-		LoadBalancing:     false,
-		CacheEnabled:      true,
-		CacheDefaultTTL:   15 * time.Minute,
-		CacheMaxSize:      10000,
-		MetricsEnabled:    true,
-		TracingEnabled:    false,
-		LogLevel:          "info",
-		DefaultTimeout:    30 * time.Second,
-		StreamTimeout:     5 * time.Minute,
-		MaxRetries:        3,
-		RetryBackoff:      time.Second,
-		RetryableErrors:   []string{"timeout", "provider_unavailable", "rate_limit_exceeded"},
+		Providers:            make(map[domain.Provider]types.ProviderConfig),
+		AutoFailover:         true,
+		LoadBalancing:        false,
+		CacheEnabled:         true,
+		CacheDefaultTTL:      15 * time.Minute,
+		CacheMaxSize:         10000,
+		EmbeddingStoreTTL:    30 * 24 * time.Hour,
+		MetricsEnabled:       true,
+		TracingEnabled:       false,
+		LogLevel:             "info",
+		DefaultTimeout:       30 * time.Second,
+		StreamTimeout:        5 * time.Minute,
+		MaxRetries:           3,
+		RetryBackoff:         time.Second,
+		RetryableErrors:      []string{"timeout", "provider_unavailable", "rate_limit_exceeded"},
+		RetryableStatusCodes: append([]int(nil), shared_errors.DefaultRetryStatusCodes...),
 	}
 }
\ No newline at end of file
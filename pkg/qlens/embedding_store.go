@@ -0,0 +1,120 @@
+package qlens
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+// EmbeddingStore keeps the embeddings of individual inputs. Unlike the
+// response cache it is meant to outlive the process and is not bounded by
+// the cache size, since re-embedding a corpus is what it exists to avoid.
+type EmbeddingStore interface {
+	Get(ctx context.Context, key string) (domain.Embedding, bool)
+	Set(ctx context.Context, key string, embedding domain.Embedding, ttl time.Duration) error
+	Close() error
+}
+
+// EmbeddingStoreMiddleware stores the embedding of every input separately, so
+// a request that repeats documents embedded before only sends the new ones to
+// the provider. Usage and cost cover the uncached inputs alone, and each
+// result reports whether it came from the store.
+//
+// Entries are scoped to the tenant and keyed by provider, model, dimensions
+// and a hash of the normalized text.
+func EmbeddingStoreMiddleware(store EmbeddingStore, router Router, ttl time.Duration) func(next EmbeddingFunc) EmbeddingFunc {
+	return func(next EmbeddingFunc) EmbeddingFunc {
+		return func(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+			if len(req.Input) == 0 {
+				return next(ctx, req)
+			}
+
+			// The provider is part of the key, so pick it before the lookup
+			// and pin it for the request that embeds the misses
+			provider, err := router.SelectEmbeddingProvider(ctx, req)
+			if err != nil {
+				return nil, err
+			}
+
+			data := make([]domain.Embedding, len(req.Input))
+			keys := make([]string, len(req.Input))
+			var missing []int
+			for i, text := range req.Input {
+				keys[i] = EmbeddingStoreKey(req.TenantID, provider, req.Model, req.Dimensions, text)
+				if stored, found := store.Get(ctx, keys[i]); found {
+					data[i] = stored
+					data[i].Index = i
+					data[i].Cached = true
+					data[i].SetEncoding(req.EncodingFormat)
+					continue
+				}
+				missing = append(missing, i)
+			}
+
+			response := &types.EmbeddingResponse{
+				Object:    "list",
+				Model:     req.Model,
+				Provider:  provider,
+				RequestID: req.RequestID,
+			}
+
+			if len(missing) > 0 {
+				missReq := *req
+				missReq.Provider = provider
+				missReq.Input = make([]string, len(missing))
+				for j, i := range missing {
+					missReq.Input[j] = req.Input[i]
+				}
+
+				computed, err := next(ctx, &missReq)
+				if err != nil {
+					return nil, err
+				}
+				if len(computed.Data) != len(missing) {
+					return nil, fmt.Errorf("provider returned %d embeddings for %d inputs", len(computed.Data), len(missing))
+				}
+
+				for _, embedding := range computed.Data {
+					if embedding.Index < 0 || embedding.Index >= len(missing) {
+						return nil, fmt.Errorf("provider returned embedding with out of range index %d", embedding.Index)
+					}
+					i := missing[embedding.Index]
+					embedding.Index = i
+					data[i] = embedding
+
+					stored := embedding
+					stored.Index = 0
+					stored.Base64 = ""
+					_ = store.Set(ctx, keys[i], stored, ttl)
+				}
+
+				response.Model = computed.Model
+				response.Usage = computed.Usage
+				response.ResponseTime = computed.ResponseTime
+			}
+
+			response.Data = data
+			response.Usage.CachedInputs = len(req.Input) - len(missing)
+			return response, nil
+		}
+	}
+}
+
+// EmbeddingStoreKey builds the store key for a single input. Whitespace is
+// normalized so reformatted but otherwise unchanged documents still hit.
+func EmbeddingStoreKey(tenantID domain.TenantID, provider domain.Provider, model string, dimensions *int, text string) string {
+	normalized := strings.Join(strings.Fields(text), " ")
+	hash := sha256.Sum256([]byte(normalized))
+
+	dims := 0
+	if dimensions != nil {
+		dims = *dimensions
+	}
+	return fmt.Sprintf("embedding-input:%s:%s:%s:%d:%s", tenantID, provider, model, dims, hex.EncodeToString(hash[:]))
+}
@@ -0,0 +1,159 @@
+package qlens
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// fileEmbeddingRecord is one line of a FileEmbeddingStore's file
+type fileEmbeddingRecord struct {
+	Key       string           `json:"key"`
+	Embedding domain.Embedding `json:"embedding"`
+	ExpiresAt time.Time        `json:"expires_at,omitempty"`
+}
+
+// FileEmbeddingStore keeps input embeddings in a file so they survive
+// restarts. Every stored embedding is appended to the file as a JSON line;
+// opening the store drops expired and superseded lines.
+type FileEmbeddingStore struct {
+	mu      sync.RWMutex
+	file    *os.File
+	writer  *bufio.Writer
+	entries map[string]fileEmbeddingRecord
+}
+
+// NewFileEmbeddingStore opens the store at path, creating the file if it
+// does not exist
+func NewFileEmbeddingStore(path string) (*FileEmbeddingStore, error) {
+	entries, err := readEmbeddingRecords(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rewrite the file with the live entries before appending to it
+	tmp := path + ".tmp"
+	if err := writeEmbeddingRecords(tmp, entries); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, fmt.Errorf("failed to replace embedding store: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open embedding store: %w", err)
+	}
+	return &FileEmbeddingStore{file: file, writer: bufio.NewWriter(file), entries: entries}, nil
+}
+
+// readEmbeddingRecords loads the unexpired entries of a store file, the
+// last line for a key winning
+func readEmbeddingRecords(path string) (map[string]fileEmbeddingRecord, error) {
+	entries := make(map[string]fileEmbeddingRecord)
+
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedding store: %w", err)
+	}
+	defer file.Close()
+
+	now := time.Now()
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var record fileEmbeddingRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			// A line cut short by a crash loses only that entry
+			continue
+		}
+		if !record.ExpiresAt.IsZero() && now.After(record.ExpiresAt) {
+			delete(entries, record.Key)
+			continue
+		}
+		entries[record.Key] = record
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read embedding store: %w", err)
+	}
+	return entries, nil
+}
+
+func writeEmbeddingRecords(path string, entries map[string]fileEmbeddingRecord) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write embedding store: %w", err)
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, record := range entries {
+		if err := encoder.Encode(record); err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write embedding store: %w", err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write embedding store: %w", err)
+	}
+	return file.Close()
+}
+
+func (s *FileEmbeddingStore) Get(ctx context.Context, key string) (domain.Embedding, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	record, ok := s.entries[key]
+	if !ok || (!record.ExpiresAt.IsZero() && time.Now().After(record.ExpiresAt)) {
+		return domain.Embedding{}, false
+	}
+	return record.Embedding, true
+}
+
+func (s *FileEmbeddingStore) Set(ctx context.Context, key string, embedding domain.Embedding, ttl time.Duration) error {
+	record := fileEmbeddingRecord{Key: key, Embedding: embedding}
+	if ttl > 0 {
+		record.ExpiresAt = time.Now().Add(ttl)
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal embedding: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return errors.New("embedding store is closed")
+	}
+	if _, err := s.writer.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write embedding store: %w", err)
+	}
+	if err := s.writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write embedding store: %w", err)
+	}
+	s.entries[key] = record
+	return nil
+}
+
+func (s *FileEmbeddingStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
@@ -0,0 +1,47 @@
+package qlens
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// RedisEmbeddingStore keeps input embeddings in Redis under their own key
+// prefix, apart from the response cache
+type RedisEmbeddingStore struct {
+	client RedisClient
+	prefix string
+}
+
+// NewRedisEmbeddingStore creates a store on the given client. The client is
+// shared with the cache, so closing the store leaves it open.
+func NewRedisEmbeddingStore(client RedisClient, prefix string) *RedisEmbeddingStore {
+	return &RedisEmbeddingStore{client: client, prefix: prefix}
+}
+
+func (s *RedisEmbeddingStore) Get(ctx context.Context, key string) (domain.Embedding, bool) {
+	data, err := s.client.Get(ctx, s.prefix+":"+key)
+	if err != nil {
+		return domain.Embedding{}, false
+	}
+	var embedding domain.Embedding
+	if err := json.Unmarshal([]byte(data), &embedding); err != nil {
+		return domain.Embedding{}, false
+	}
+	return embedding, true
+}
+
+func (s *RedisEmbeddingStore) Set(ctx context.Context, key string, embedding domain.Embedding, ttl time.Duration) error {
+	data, err := json.Marshal(embedding)
+	if err != nil {
+		return fmt.Errorf("failed to marshal embedding: %w", err)
+	}
+	return s.client.Set(ctx, s.prefix+":"+key, data, ttl)
+}
+
+func (s *RedisEmbeddingStore) Close() error {
+	return nil
+}
@@ -0,0 +1,162 @@
+package qlens
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+type fixedEmbeddingRouter struct {
+	Router
+	provider domain.Provider
+}
+
+func (r *fixedEmbeddingRouter) SelectEmbeddingProvider(ctx context.Context, req *types.EmbeddingRequest) (domain.Provider, error) {
+	return r.provider, nil
+}
+
+// recordingEmbeddingFunc embeds each input as its length and bills one token
+// and $0.01 per input
+func recordingEmbeddingFunc(calls *[][]string) EmbeddingFunc {
+	return func(ctx context.Context, req *types.EmbeddingRequest) (*types.EmbeddingResponse, error) {
+		*calls = append(*calls, req.Input)
+
+		response := &types.EmbeddingResponse{Object: "list", Model: "text-embedding-3-small", Provider: req.Provider}
+		for i, text := range req.Input {
+			response.Data = append(response.Data, domain.Embedding{Object: "embedding", Embedding: []float64{float64(len(text))}, Index: i})
+		}
+		response.Usage = domain.EmbeddingUsage{
+			PromptTokens: len(req.Input),
+			TotalTokens:  len(req.Input),
+			CostUSD:      0.01 * float64(len(req.Input)),
+		}
+		return response, nil
+	}
+}
+
+func newTestEmbeddingStore(t *testing.T) *FileEmbeddingStore {
+	store, err := NewFileEmbeddingStore(filepath.Join(t.TempDir(), "embeddings.jsonl"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestEmbeddingStoreMiddleware_OnlyEmbedsUncachedInputs(t *testing.T) {
+	var calls [][]string
+	router := &fixedEmbeddingRouter{provider: domain.ProviderOpenAI}
+	embed := EmbeddingStoreMiddleware(newTestEmbeddingStore(t), router, time.Hour)(recordingEmbeddingFunc(&calls))
+
+	first, err := embed(context.Background(), &types.EmbeddingRequest{TenantID: "tenant-a", Input: []string{"alpha", "beta"}})
+	require.NoError(t, err)
+	assert.False(t, first.Data[0].Cached)
+	assert.Equal(t, 0.02, first.Usage.CostUSD)
+
+	// Whitespace changes still hit the store; only "gamma" is embedded
+	second, err := embed(context.Background(), &types.EmbeddingRequest{TenantID: "tenant-a", Input: []string{"gamma", " alpha ", "beta"}})
+	require.NoError(t, err)
+	require.Len(t, calls, 2)
+	assert.Equal(t, []string{"gamma"}, calls[1])
+
+	require.Len(t, second.Data, 3)
+	for i, embedding := range second.Data {
+		assert.Equal(t, i, embedding.Index)
+	}
+	assert.False(t, second.Data[0].Cached)
+	assert.True(t, second.Data[1].Cached)
+	assert.True(t, second.Data[2].Cached)
+	assert.Equal(t, []float64{5}, second.Data[1].Embedding)
+	assert.Equal(t, 1, second.Usage.TotalTokens)
+	assert.Equal(t, 0.01, second.Usage.CostUSD)
+	assert.Equal(t, 2, second.Usage.CachedInputs)
+}
+
+func TestEmbeddingStoreMiddleware_FullyCachedRequestIsFree(t *testing.T) {
+	var calls [][]string
+	router := &fixedEmbeddingRouter{provider: domain.ProviderOpenAI}
+	embed := EmbeddingStoreMiddleware(newTestEmbeddingStore(t), router, time.Hour)(recordingEmbeddingFunc(&calls))
+
+	req := &types.EmbeddingRequest{TenantID: "tenant-a", Input: []string{"alpha"}}
+	_, err := embed(context.Background(), req)
+	require.NoError(t, err)
+
+	response, err := embed(context.Background(), req)
+	require.NoError(t, err)
+	assert.Len(t, calls, 1)
+	assert.True(t, response.Data[0].Cached)
+	assert.Zero(t, response.Usage.CostUSD)
+
+	// Other tenants and models do not share entries
+	_, err = embed(context.Background(), &types.EmbeddingRequest{TenantID: "tenant-b", Input: []string{"alpha"}})
+	require.NoError(t, err)
+	_, err = embed(context.Background(), &types.EmbeddingRequest{TenantID: "tenant-a", Model: "text-embedding-3-large", Input: []string{"alpha"}})
+	require.NoError(t, err)
+	assert.Len(t, calls, 3)
+}
+
+func TestFileEmbeddingStore_SurvivesReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "embeddings.jsonl")
+	ctx := context.Background()
+
+	store, err := NewFileEmbeddingStore(path)
+	require.NoError(t, err)
+	require.NoError(t, store.Set(ctx, "kept", domain.Embedding{Object: "embedding", Embedding: []float64{0.1, 0.2}}, time.Hour))
+	require.NoError(t, store.Set(ctx, "replaced", domain.Embedding{Embedding: []float64{1}}, time.Hour))
+	require.NoError(t, store.Set(ctx, "replaced", domain.Embedding{Embedding: []float64{2}}, time.Hour))
+	require.NoError(t, store.Set(ctx, "expired", domain.Embedding{Embedding: []float64{3}}, time.Nanosecond))
+	require.NoError(t, store.Close())
+
+	time.Sleep(time.Millisecond)
+
+	reopened, err := NewFileEmbeddingStore(path)
+	require.NoError(t, err)
+	defer reopened.Close()
+
+	kept, found := reopened.Get(ctx, "kept")
+	require.True(t, found)
+	assert.Equal(t, []float64{0.1, 0.2}, kept.Embedding)
+
+	replaced, found := reopened.Get(ctx, "replaced")
+	require.True(t, found)
+	assert.Equal(t, []float64{2}, replaced.Embedding)
+
+	_, found = reopened.Get(ctx, "expired")
+	assert.False(t, found)
+	assert.Len(t, reopened.entries, 2)
+}
+
+func TestFileEmbeddingStore_ExpiresEntries(t *testing.T) {
+	store := newTestEmbeddingStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, store.Set(ctx, "short", domain.Embedding{Embedding: []float64{1}}, time.Nanosecond))
+	time.Sleep(time.Millisecond)
+	_, found := store.Get(ctx, "short")
+	assert.False(t, found)
+}
+
+func TestNew_EmbeddingStoreIsNotBoundedByCacheSize(t *testing.T) {
+	var calls [][]string
+	router := &fixedEmbeddingRouter{provider: domain.ProviderOpenAI}
+	smallCache := func(c *types.ClientConfig) { c.CacheMaxSize = 1 }
+	local := WithProvider(domain.ProviderLocal, types.ProviderConfig{Provider: domain.ProviderLocal, BaseURL: "http://localhost:11434/v1", Enabled: true})
+	client, err := New(local, smallCache, WithEmbeddingStorePath(filepath.Join(t.TempDir(), "embeddings.jsonl")))
+	require.NoError(t, err)
+	defer client.Close()
+	require.NotNil(t, client.embeddings)
+
+	embed := EmbeddingStoreMiddleware(client.embeddings, router, time.Hour)(recordingEmbeddingFunc(&calls))
+	_, err = embed(context.Background(), &types.EmbeddingRequest{TenantID: "tenant-a", Input: []string{"alpha", "beta", "gamma"}})
+	require.NoError(t, err)
+
+	response, err := embed(context.Background(), &types.EmbeddingRequest{TenantID: "tenant-a", Input: []string{"alpha", "beta", "gamma"}})
+	require.NoError(t, err)
+	assert.Len(t, calls, 1)
+	assert.Equal(t, 3, response.Usage.CachedInputs)
+}
@@ -0,0 +1,13 @@
+package qlens
+
+import (
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// DecodeEmbeddingBase64 decodes an embedding returned for encoding_format
+// base64 (little-endian float32s) into floats. Embeddings read into
+// domain.Embedding are decoded already; this is for callers handling the
+// raw JSON.
+func DecodeEmbeddingBase64(encoded string) ([]float64, error) {
+	return domain.DecodeEmbeddingBase64(encoded)
+}
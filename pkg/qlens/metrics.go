@@ -17,6 +17,9 @@ type MetricsCollector struct {
 	cacheHits      map[string]int64
 	cacheMisses    map[string]int64
 	startTime      time.Time
+	
+	// cache, when watched, has its stats added to the Prometheus metrics
+	cache Cache
 }
 
 // Metrics represents the current metrics snapshot
@@ -45,6 +48,15 @@ func NewMetricsCollector() *MetricsCollector {
 	}
 }
 
+// WatchCache adds the cache's hits, misses and evictions to the Prometheus
+// metrics
+func (m *MetricsCollector) WatchCache(cache Cache) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	
+	m.cache = cache
+}
+
 // IncrementRequestCount increments the request count for an operation
 func (m *MetricsCollector) IncrementRequestCount(operation string) {
 	m.mu.Lock()
@@ -261,6 +273,13 @@ func (m *MetricsCollector) GetPrometheusMetrics() string {
 	result += "# TYPE qlens_uptime_seconds gauge\n"
 	result += fmt.Sprintf("qlens_uptime_seconds %f\n", metrics.Uptime.Seconds())
 	
+	m.mu.RLock()
+	cache := m.cache
+	m.mu.RUnlock()
+	if cache != nil {
+		result += CachePrometheusMetrics(cache.Stats())
+	}
+	
 	return result
 }
 
@@ -8,18 +8,21 @@ import (
 
 	"github.com/quantum-suite/platform/internal/domain"
 	qlensProvider "github.com/quantum-suite/platform/internal/providers/qlens"
+	"github.com/quantum-suite/platform/internal/providers/registry"
 	"github.com/quantum-suite/platform/pkg/qlens-types"
+	shared_errors "github.com/quantum-suite/platform/pkg/shared/errors"
 )
 
 // QLens is the main client that implements the Client interface
 type QLens struct {
-	mu        sync.RWMutex
-	config    *types.ClientConfig
-	router    Router
-	cache     Cache
-	providers map[domain.Provider]types.ProviderClient
-	metrics   *MetricsCollector
-	startTime time.Time
+	mu         sync.RWMutex
+	config     *types.ClientConfig
+	router     Router
+	cache      Cache
+	embeddings EmbeddingStore
+	providers  map[domain.Provider]types.ProviderClient
+	metrics    *MetricsCollector
+	startTime  time.Time
 }
 
 // New creates a new QLens client with the given configuration
@@ -43,15 +46,31 @@ func New(opts ...ClientOption) (*QLens, error) {
 	// Initialize cache
 	if config.CacheEnabled {
 		client.cache = NewInMemoryCache(config.CacheMaxSize)
+		if err := client.cache.Configure(types.CacheConfig{Type: "memory", TenantStats: config.CacheTenantStats, EvictionPolicy: config.CacheEvictionPolicy}); err != nil {
+			return nil, err
+		}
+	}
+	
+	// Initialize embedding store
+	if config.EmbeddingStoreTTL > 0 && config.EmbeddingStorePath != "" {
+		store, err := NewFileEmbeddingStore(config.EmbeddingStorePath)
+		if err != nil {
+			return nil, err
+		}
+		client.embeddings = store
 	}
 	
 	// Initialize metrics collector
 	if config.MetricsEnabled {
 		client.metrics = NewMetricsCollector()
+		if client.cache != nil {
+			client.metrics.WatchCache(client.cache)
+		}
 	}
 	
 	// Initialize providers
 	if err := client.initializeProviders(); err != nil {
+		client.Close()
 		return nil, fmt.Errorf("failed to initialize providers: %w", err)
 	}
 	
@@ -156,6 +175,10 @@ func (q *QLens) CreateEmbeddings(ctx context.Context, req *types.EmbeddingReques
 		req.RequestID = generateRequestID()
 	}
 	
+	if err := validateEmbeddingRepresentations(req.Representations); err != nil {
+		return nil, err
+	}
+	
 	// Record metrics
 	if q.metrics != nil {
 		q.metrics.IncrementRequestCount("embedding")
@@ -165,8 +188,11 @@ func (q *QLens) CreateEmbeddings(ctx context.Context, req *types.EmbeddingReques
 	}
 	
 	// Apply caching middleware if enabled
+	// The per-input embedding store supersedes the whole-request cache
 	embeddingFunc := q.createEmbeddingFunc()
-	if q.cache != nil {
+	if q.embeddings != nil {
+		embeddingFunc = EmbeddingStoreMiddleware(q.embeddings, q.router, q.config.EmbeddingStoreTTL)(embeddingFunc)
+	} else if q.cache != nil {
 		embeddingFunc = EmbeddingCacheMiddleware(q.cache, q.config)(embeddingFunc)
 	}
 	
@@ -179,6 +205,18 @@ func (q *QLens) CreateEmbeddings(ctx context.Context, req *types.EmbeddingReques
 		return nil, err
 	}
 	
+	// Representations are derived after the caches, which hold the base
+	// vectors alone, on a copy so a cached response is never altered
+	if len(req.Representations) > 0 {
+		representations, err := domain.DeriveEmbeddingRepresentations(response.Data, req.Representations)
+		if err != nil {
+			return nil, &types.QLensError{Type: types.ErrorTypeInvalidRequest, Message: err.Error()}
+		}
+		derived := *response
+		derived.Representations = representations
+		response = &derived
+	}
+	
 	// Record success metrics
 	if q.metrics != nil {
 		q.metrics.RecordTokenUsage("embedding", response.Usage.TotalTokens)
@@ -307,6 +345,13 @@ func (q *QLens) Close() error {
 		}
 	}
 	
+	// Close embedding store
+	if q.embeddings != nil {
+		if err := q.embeddings.Close(); err != nil {
+			errors = append(errors, err)
+		}
+	}
+	
 	// Close router
 	if router, ok := q.router.(*DefaultRouter); ok {
 		router.Stop()
@@ -334,17 +379,24 @@ func (q *QLens) initializeProviders() error {
 		
 		var providerClient types.ProviderClient
 		
-		switch provider {
-		case domain.ProviderOpenAI:
-			providerClient = qlensProvider.NewOpenAIClient(config)
-		case domain.ProviderAnthropic:
-			// TODO: Implement Anthropic client
-			continue
-		case domain.ProviderLocal:
-			// TODO: Implement local client
-			continue
-		default:
-			return fmt.Errorf("unsupported provider: %s", provider)
+		// Adapters register how their clients are built, including OpenAI,
+		// Anthropic and the known OpenAI-compatible providers
+		if factory, ok := registry.Lookup(provider); ok && factory.SDK != nil {
+			client, err := factory.SDK(provider, config)
+			if err != nil {
+				return fmt.Errorf("failed to create provider %s: %w", provider, err)
+			}
+			providerClient = client
+		} else {
+			// Any other provider with a base URL is treated as an
+			// OpenAI-compatible API
+			if config.BaseURL == "" {
+				return fmt.Errorf("unsupported provider: %s", provider)
+			}
+			if config.Provider == "" {
+				config.Provider = provider
+			}
+			providerClient = qlensProvider.NewOpenAICompatibleClient(qlensProvider.GenericOpenAICompatibleProfile(provider), config)
 		}
 		
 		q.mu.Lock()
@@ -474,11 +526,11 @@ func (q *QLens) executeEmbeddingWithRetry(ctx context.Context, fn func() (*types
 
 func (q *QLens) isRetryableError(err error) bool {
 	if qlensErr, ok := err.(*types.QLensError); ok {
-		for _, retryableType := range q.config.RetryableErrors {
-			if qlensErr.Type == retryableType {
-				return true
-			}
+		policy := shared_errors.RetryPolicy{
+			StatusCodes: q.config.RetryableStatusCodes,
+			ErrorTypes:  q.config.RetryableErrors,
 		}
+		return policy.Retryable(qlensErr.StatusCode, qlensErr.Type, false)
 	}
 	
 	return false
@@ -515,6 +567,23 @@ func (q *QLens) filterModels(models []types.Model, opts *types.ListModelsOptions
 
 // Utility functions
 
+// validateEmbeddingRepresentations rejects representations that cannot be
+// derived before the provider is paid for the base vectors
+func validateEmbeddingRepresentations(representations []domain.EmbeddingRepresentation) error {
+	if len(representations) > domain.MaxEmbeddingRepresentations {
+		return &types.QLensError{
+			Type:    types.ErrorTypeInvalidRequest,
+			Message: fmt.Sprintf("at most %d representations may be requested", domain.MaxEmbeddingRepresentations),
+		}
+	}
+	for _, representation := range representations {
+		if err := representation.Validate(); err != nil {
+			return &types.QLensError{Type: types.ErrorTypeInvalidRequest, Message: err.Error()}
+		}
+	}
+	return nil
+}
+
 func generateRequestID() string {
 	// Generate a simple request ID - in production, use a more robust UUID library
 	return fmt.Sprintf("req_%d", time.Now().UnixNano())
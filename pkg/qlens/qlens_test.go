@@ -0,0 +1,84 @@
+package qlens
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// stubTransport answers every HTTP request the test makes through the
+// default transport with handle
+func stubTransport(t *testing.T, handle roundTripFunc) {
+	original := http.DefaultTransport
+	http.DefaultTransport = handle
+	t.Cleanup(func() { http.DefaultTransport = original })
+}
+
+func TestNewWithAnthropic_CreateCompletion(t *testing.T) {
+	var sent struct {
+		Model     string `json:"model"`
+		System    string `json:"system"`
+		MaxTokens int    `json:"max_tokens"`
+		Messages  []struct {
+			Role string `json:"role"`
+		} `json:"messages"`
+	}
+	stubTransport(t, func(req *http.Request) (*http.Response, error) {
+		assert.Equal(t, "https://api.anthropic.com/v1/messages", req.URL.String())
+		assert.Equal(t, "sk-ant-test", req.Header.Get("x-api-key"))
+		require.NoError(t, json.NewDecoder(req.Body).Decode(&sent))
+
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body: io.NopCloser(strings.NewReader(`{
+				"id": "msg_1",
+				"type": "message",
+				"role": "assistant",
+				"model": "claude-3-5-sonnet-20241022",
+				"content": [{"type": "text", "text": "Bonjour"}],
+				"stop_reason": "max_tokens",
+				"usage": {"input_tokens": 20, "output_tokens": 10}
+			}`)),
+			Request: req,
+		}, nil
+	})
+
+	client, err := NewWithAnthropic("sk-ant-test", WithCaching(false, 0))
+	require.NoError(t, err)
+	defer client.Close()
+
+	response, err := client.CreateCompletion(context.Background(), &types.CompletionRequest{
+		Model: "claude-3-5-sonnet-20241022",
+		Messages: []domain.Message{
+			{Role: domain.MessageRoleSystem, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "Answer in French."}}},
+			{Role: domain.MessageRoleUser, Content: []domain.ContentPart{{Type: domain.ContentTypeText, Text: "Hello"}}},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Answer in French.", sent.System)
+	require.Len(t, sent.Messages, 1)
+	assert.Equal(t, "user", sent.Messages[0].Role)
+
+	assert.Equal(t, domain.ProviderAnthropic, response.Provider)
+	require.Len(t, response.Choices, 1)
+	assert.Equal(t, "Bonjour", response.Choices[0].Message.Content[0].Text)
+	assert.Equal(t, domain.FinishReasonLength, response.Choices[0].FinishReason)
+	assert.InDelta(t, 20*3/1e6+10*15/1e6, response.Usage.CostUSD, 1e-12)
+}
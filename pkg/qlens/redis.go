@@ -95,6 +95,17 @@ func NewWithRedisCache(redisURL, openAIKey string, opts ...ClientOption) (*QLens
 		opt(config)
 	}
 	
+	if err := cache.Configure(types.CacheConfig{
+		Type:                "redis",
+		TTL:                 config.CacheDefaultTTL,
+		Compression:         config.CacheCompression,
+		CompressionMinBytes: config.CacheCompressionMinBytes,
+		TenantStats:         config.CacheTenantStats,
+	}); err != nil {
+		redisClient.Close()
+		return nil, err
+	}
+	
 	// Add OpenAI provider
 	if openAIKey != "" {
 		config.Providers[domain.ProviderOpenAI] = types.ProviderConfig{
@@ -113,6 +124,9 @@ func NewWithRedisCache(redisURL, openAIKey string, opts ...ClientOption) (*QLens
 		cache:     cache, // Use Redis cache instead of in-memory
 		startTime: time.Now(),
 	}
+	if config.EmbeddingStoreTTL > 0 {
+		client.embeddings = NewRedisEmbeddingStore(redisClient, "qlens-embeddings")
+	}
 	
 	// Initialize router
 	client.router = NewDefaultRouter(config)
@@ -120,6 +134,7 @@ func NewWithRedisCache(redisURL, openAIKey string, opts ...ClientOption) (*QLens
 	// Initialize metrics collector
 	if config.MetricsEnabled {
 		client.metrics = NewMetricsCollector()
+		client.metrics.WatchCache(cache)
 	}
 	
 	// Initialize providers
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/quantum-suite/platform/internal/domain"
+	qlensProvider "github.com/quantum-suite/platform/internal/providers/qlens"
 	"github.com/quantum-suite/platform/pkg/qlens-types"
 )
 
@@ -254,6 +255,15 @@ func (r *DefaultRouter) supportsEmbeddings(provider domain.Provider) bool {
 	case domain.ProviderLocal:
 		return false
 	default:
+		// Other providers only serve embeddings for models configured with
+		// the embedding capability
+		for _, spec := range r.providers[provider].Models {
+			for _, capability := range spec.Capabilities {
+				if capability == domain.CapabilityEmbedding {
+					return true
+				}
+			}
+		}
 		return false
 	}
 }
@@ -280,7 +290,9 @@ func (r *DefaultRouter) providerSupportsModel(provider domain.Provider, model st
 	case domain.ProviderLocal:
 		return true // Local providers can potentially support any model
 	default:
-		return false
+		// OpenAI-compatible providers host arbitrary model catalogues, so
+		// only the models they are configured with are routed to them
+		return qlensProvider.ServesModel(provider, r.providers[provider], model)
 	}
 }
 
@@ -465,6 +477,8 @@ func isOpenAIModel(model string) bool {
 func isAnthropicModel(model string) bool {
 	anthropicModels := []string{
 		"claude-3-opus", "claude-3-sonnet", "claude-3-haiku",
+		"claude-3-5-sonnet", "claude-3-5-haiku", "claude-3-7-sonnet",
+		"claude-sonnet-4", "claude-opus-4", "claude-haiku-4",
 		"claude-2.1", "claude-2.0", "claude-instant",
 	}
 	
@@ -0,0 +1,30 @@
+package qlens
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+func TestDefaultRouter_ProviderSupportsModel(t *testing.T) {
+	router := &DefaultRouter{providers: map[domain.Provider]types.ProviderConfig{
+		"groq": {BaseURL: "https://api.groq.com/openai/v1"},
+		"local-vllm": {
+			BaseURL: "http://localhost:8000/v1",
+			Models:  map[string]types.ModelSpec{"llama-3.1-70b": {}},
+		},
+	}}
+
+	// A provider without configured models is routed no models
+	assert.False(t, router.providerSupportsModel("groq", "llama-3.1-70b-versatile"))
+	assert.False(t, router.providerSupportsModel("groq", "claude-3-5-sonnet-20241022"))
+
+	// Configured models match the way pricing does, by the longest key the
+	// model ID contains
+	assert.True(t, router.providerSupportsModel("local-vllm", "llama-3.1-70b"))
+	assert.True(t, router.providerSupportsModel("local-vllm", "llama-3.1-70b-instruct"))
+	assert.False(t, router.providerSupportsModel("local-vllm", "anthropic.claude-3-sonnet-20240229-v1:0"))
+}
@@ -0,0 +1,450 @@
+package qlens
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Template engines shipped with the SDK. Templates saved without an engine
+// use TemplateEngineSimple.
+const (
+	TemplateEngineSimple   = "simple"
+	TemplateEngineMustache = "mustache"
+	TemplateEngineGo       = "go"
+)
+
+// DefaultTemplateOutputLimit is the most bytes a template may render to
+const DefaultTemplateOutputLimit = 1 << 20
+
+// TemplateEngine renders prompt template content. Engines are given only the
+// template's variables; they must not reach anything else.
+type TemplateEngine interface {
+	// Name is what templates select the engine by
+	Name() string
+	// Validate checks content when a template is saved
+	Validate(content string) error
+	// Render writes content with the variables substituted to w
+	Render(w io.Writer, content string, values map[string]interface{}) error
+}
+
+var errTemplateOutputTooLarge = errors.New("rendered template exceeds the output limit")
+
+// limitedWriter fails a render once it has written more than its limit
+type limitedWriter struct {
+	w         io.Writer
+	remaining int
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > l.remaining {
+		return 0, errTemplateOutputTooLarge
+	}
+	l.remaining -= len(p)
+	return l.w.Write(p)
+}
+
+// simpleEngine substitutes {{name}} placeholders and leaves placeholders
+// without a value as they are
+type simpleEngine struct{}
+
+func (simpleEngine) Name() string { return TemplateEngineSimple }
+
+func (simpleEngine) Validate(content string) error { return nil }
+
+func (simpleEngine) Render(w io.Writer, content string, values map[string]interface{}) error {
+	rendered := templatePlaceholder.ReplaceAllStringFunc(content, func(match string) string {
+		name := templatePlaceholder.FindStringSubmatch(match)[1]
+		if value, ok := values[name]; ok {
+			return fmt.Sprint(value)
+		}
+		return match
+	})
+	_, err := io.WriteString(w, rendered)
+	return err
+}
+
+// goTemplateFuncs are the only functions Go templates may call, besides the
+// text/template builtins other than call
+var goTemplateFuncs = template.FuncMap{
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+	"join": func(sep string, items []interface{}) string {
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, sep)
+	},
+	"default": func(fallback, value interface{}) interface{} {
+		if value == nil || value == "" {
+			return fallback
+		}
+		return value
+	},
+	// call would run any function a variable holds
+	"call": func(args ...interface{}) (interface{}, error) {
+		return nil, errors.New("call is not allowed in templates")
+	},
+}
+
+// goEngine renders Go text/template templates, with conditionals and loops.
+// Templates may call only the functions in goTemplateFuncs, and see the
+// variables as plain JSON data so no method of a value can run.
+type goEngine struct{}
+
+func (goEngine) Name() string { return TemplateEngineGo }
+
+func (goEngine) parse(content string) (*template.Template, error) {
+	tmpl, err := template.New("prompt").Funcs(goTemplateFuncs).Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		if err := checkGoTemplateNode(t.Tree.Root); err != nil {
+			return nil, err
+		}
+	}
+	return tmpl, nil
+}
+
+func (e goEngine) Validate(content string) error {
+	_, err := e.parse(content)
+	return err
+}
+
+func (e goEngine) Render(w io.Writer, content string, values map[string]interface{}) error {
+	tmpl, err := e.parse(content)
+	if err != nil {
+		return err
+	}
+	data, err := plainTemplateValues(values)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(w, data)
+}
+
+// checkGoTemplateNode rejects calls to call and loops over number literals,
+// which could spin without writing any output
+func checkGoTemplateNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkGoTemplateNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkGoTemplatePipe(n.Pipe)
+	case *parse.IfNode:
+		return checkGoTemplateBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return checkGoTemplateBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		if n.Pipe != nil && len(n.Pipe.Cmds) == 1 && len(n.Pipe.Cmds[0].Args) == 1 {
+			if _, ok := n.Pipe.Cmds[0].Args[0].(*parse.NumberNode); ok {
+				return fmt.Errorf("range over a number is not allowed in templates")
+			}
+		}
+		return checkGoTemplateBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		return checkGoTemplatePipe(n.Pipe)
+	}
+	return nil
+}
+
+func checkGoTemplateBranch(n *parse.BranchNode) error {
+	if err := checkGoTemplatePipe(n.Pipe); err != nil {
+		return err
+	}
+	if err := checkGoTemplateNode(n.List); err != nil {
+		return err
+	}
+	if n.ElseList != nil {
+		return checkGoTemplateNode(n.ElseList)
+	}
+	return nil
+}
+
+func checkGoTemplatePipe(pipe *parse.PipeNode) error {
+	if pipe == nil {
+		return nil
+	}
+	for _, cmd := range pipe.Cmds {
+		for _, arg := range cmd.Args {
+			switch a := arg.(type) {
+			case *parse.IdentifierNode:
+				if a.Ident == "call" {
+					return fmt.Errorf("call is not allowed in templates")
+				}
+			case *parse.PipeNode:
+				if err := checkGoTemplatePipe(a); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// plainTemplateValues copies the variables through JSON, leaving only maps,
+// slices and scalars. Whole numbers come back as int64 so they render as
+// written: a float64 of 1000000 would print as 1e+06.
+func plainTemplateValues(values map[string]interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("template variables must be JSON values: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var plain map[string]interface{}
+	if err := decoder.Decode(&plain); err != nil {
+		return nil, err
+	}
+	return plainNumbers(plain).(map[string]interface{}), nil
+}
+
+// plainNumbers replaces the json.Numbers in a decoded value with int64s,
+// or float64s for numbers with a fraction or beyond the int64 range
+func plainNumbers(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = plainNumbers(item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = plainNumbers(item)
+		}
+	}
+	return value
+}
+
+// mustacheMaxDepth bounds how deeply mustache sections may nest
+const mustacheMaxDepth = 16
+
+// mustacheEngine renders logic-less mustache templates: {{name}} and
+// {{a.b}} variables, {{#name}} sections that repeat over lists and are
+// skipped for empty values, {{^name}} inverted sections and {{! comments}}.
+// Values are written as they are, without HTML escaping; {{{name}}} and
+// {{& name}} are accepted as synonyms.
+type mustacheEngine struct{}
+
+func (mustacheEngine) Name() string { return TemplateEngineMustache }
+
+func (mustacheEngine) Validate(content string) error {
+	_, err := parseMustache(content)
+	return err
+}
+
+func (mustacheEngine) Render(w io.Writer, content string, values map[string]interface{}) error {
+	nodes, err := parseMustache(content)
+	if err != nil {
+		return err
+	}
+	data, err := plainTemplateValues(values)
+	if err != nil {
+		return err
+	}
+	return renderMustache(w, nodes, []interface{}{data})
+}
+
+type mustacheNodeKind int
+
+const (
+	mustacheText mustacheNodeKind = iota
+	mustacheVariable
+	mustacheSection
+	mustacheInverted
+)
+
+type mustacheNode struct {
+	kind     mustacheNodeKind
+	text     string // literal text, or the name of a variable or section
+	children []mustacheNode
+}
+
+// parseMustache parses content into a tree of nodes
+func parseMustache(content string) ([]mustacheNode, error) {
+	type frame struct {
+		name  string
+		kind  mustacheNodeKind
+		nodes []mustacheNode
+	}
+	stack := []frame{{}}
+
+	for len(content) > 0 {
+		start := strings.Index(content, "{{")
+		if start < 0 {
+			stack[len(stack)-1].nodes = append(stack[len(stack)-1].nodes, mustacheNode{kind: mustacheText, text: content})
+			break
+		}
+		if start > 0 {
+			stack[len(stack)-1].nodes = append(stack[len(stack)-1].nodes, mustacheNode{kind: mustacheText, text: content[:start]})
+		}
+		content = content[start+2:]
+
+		closer := "}}"
+		if strings.HasPrefix(content, "{") {
+			closer = "}}}"
+		}
+		end := strings.Index(content, closer)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed mustache tag")
+		}
+		tag := content[:end]
+		content = content[end+len(closer):]
+
+		var sigil byte
+		if closer == "}}}" {
+			sigil, tag = '&', tag[1:]
+		} else if len(tag) > 0 && strings.ContainsRune("#^/!&", rune(tag[0])) {
+			sigil, tag = tag[0], tag[1:]
+		}
+		name := strings.TrimSpace(tag)
+		if sigil == '!' {
+			continue
+		}
+		if name == "" {
+			return nil, fmt.Errorf("empty mustache tag")
+		}
+
+		switch sigil {
+		case '#', '^':
+			if len(stack) > mustacheMaxDepth {
+				return nil, fmt.Errorf("mustache sections nest deeper than %d", mustacheMaxDepth)
+			}
+			kind := mustacheSection
+			if sigil == '^' {
+				kind = mustacheInverted
+			}
+			stack = append(stack, frame{name: name, kind: kind})
+		case '/':
+			top := stack[len(stack)-1]
+			if len(stack) == 1 || top.name != name {
+				return nil, fmt.Errorf("unexpected mustache closing tag %s", name)
+			}
+			stack = stack[:len(stack)-1]
+			stack[len(stack)-1].nodes = append(stack[len(stack)-1].nodes, mustacheNode{kind: top.kind, text: name, children: top.nodes})
+		default:
+			stack[len(stack)-1].nodes = append(stack[len(stack)-1].nodes, mustacheNode{kind: mustacheVariable, text: name})
+		}
+	}
+
+	if len(stack) > 1 {
+		return nil, fmt.Errorf("unclosed mustache section %s", stack[len(stack)-1].name)
+	}
+	return stack[0].nodes, nil
+}
+
+// renderMustache writes nodes, looking names up from the innermost context
+// outwards
+func renderMustache(w io.Writer, nodes []mustacheNode, contexts []interface{}) error {
+	for _, node := range nodes {
+		switch node.kind {
+		case mustacheText:
+			if _, err := io.WriteString(w, node.text); err != nil {
+				return err
+			}
+		case mustacheVariable:
+			if value := lookupMustache(node.text, contexts); value != nil {
+				if _, err := io.WriteString(w, fmt.Sprint(value)); err != nil {
+					return err
+				}
+			}
+		case mustacheSection:
+			value := lookupMustache(node.text, contexts)
+			if items, ok := value.([]interface{}); ok {
+				for _, item := range items {
+					if err := renderMustache(w, node.children, append(contexts, item)); err != nil {
+						return err
+					}
+				}
+				continue
+			}
+			if mustacheTruthy(value) {
+				if err := renderMustache(w, node.children, append(contexts, value)); err != nil {
+					return err
+				}
+			}
+		case mustacheInverted:
+			if !mustacheTruthy(lookupMustache(node.text, contexts)) {
+				if err := renderMustache(w, node.children, contexts); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// lookupMustache resolves a dotted name, or . for the current context
+func lookupMustache(name string, contexts []interface{}) interface{} {
+	if name == "." {
+		return contexts[len(contexts)-1]
+	}
+	parts := strings.Split(name, ".")
+	for i := len(contexts) - 1; i >= 0; i-- {
+		m, ok := contexts[i].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		value, ok := m[parts[0]]
+		if !ok {
+			continue
+		}
+		for _, part := range parts[1:] {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			value = m[part]
+		}
+		return value
+	}
+	return nil
+}
+
+func mustacheTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != ""
+	case []interface{}:
+		return len(v) > 0
+	}
+	return true
+}
+
+// templateEngineNames lists the registered engines for error messages
+func templateEngineNames(engines map[string]TemplateEngine) []string {
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,154 @@
+package qlens
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+func renderNew(t *testing.T, store *TemplateStore, engine, content string, values map[string]interface{}) (string, error) {
+	t.Helper()
+	created, err := store.Create("tenant-a", "alice", TemplateInput{Name: "t", Engine: engine, Content: content})
+	require.NoError(t, err)
+	rendered, _, err := store.Render("tenant-a", created.ID, 0, values)
+	return rendered, err
+}
+
+func TestTemplateStore_GoEngine(t *testing.T) {
+	store := NewTemplateStore()
+
+	rendered, err := renderNew(t, store, TemplateEngineGo,
+		`{{if .expert}}Be terse.{{else}}Explain simply.{{end}} Topics: {{join ", " .topics}}.{{range .steps}} [{{upper .}}]{{end}} {{default "anon" .user}}`,
+		map[string]interface{}{"expert": true, "topics": []string{"go", "sql"}, "steps": []string{"plan", "do"}, "user": ""})
+	require.NoError(t, err)
+	assert.Equal(t, "Be terse. Topics: go, sql. [PLAN] [DO] anon", rendered)
+}
+
+func TestTemplateStore_GoEngineIsSandboxed(t *testing.T) {
+	store := NewTemplateStore()
+
+	for name, content := range map[string]string{
+		"parse error":      "{{if .x}}unclosed",
+		"unknown function": `{{exec "rm"}}`,
+		"call":             "{{call .fn}}",
+		"nested call":      "{{printf \"%v\" (call .fn)}}",
+		"range a number":   "{{range 1000000000}}{{end}}",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := store.Create("tenant-a", "alice", TemplateInput{Name: "t", Engine: TemplateEngineGo, Content: content})
+			assertTemplateError(t, err, types.ErrorTypeInvalidRequest)
+		})
+	}
+
+	// Values are plain data, so no method of a value can run
+	rendered, err := renderNew(t, store, TemplateEngineGo, "{{.v.Secret}}", map[string]interface{}{"v": methodValue{}})
+	require.NoError(t, err)
+	assert.NotContains(t, rendered, "ran")
+}
+
+type methodValue struct{}
+
+func (methodValue) Secret() string { return "ran" }
+
+func TestTemplateStore_MustacheEngine(t *testing.T) {
+	store := NewTemplateStore()
+
+	rendered, err := renderNew(t, store, TemplateEngineMustache,
+		"Hi {{user.name}}!{{! a comment }}{{#items}} - {{title}} ({{{owner}}}){{/items}}{{^items}}nothing{{/items}}{{#admin}} [admin]{{/admin}}{{missing}}",
+		map[string]interface{}{
+			"user":  map[string]interface{}{"name": "Ada"},
+			"owner": "ops",
+			"items": []interface{}{map[string]interface{}{"title": "a"}, map[string]interface{}{"title": "b", "owner": "dev"}},
+			"admin": false,
+		})
+	require.NoError(t, err)
+	assert.Equal(t, "Hi Ada! - a (ops) - b (dev)", rendered)
+
+	rendered, err = renderNew(t, store, TemplateEngineMustache, "{{#items}}x{{/items}}{{^items}}nothing{{/items}}", map[string]interface{}{"items": []interface{}{}})
+	require.NoError(t, err)
+	assert.Equal(t, "nothing", rendered)
+
+	for _, content := range []string{"{{#a}}open", "{{/a}}", "{{#a}}{{/b}}", "{{name", "{{}}", strings.Repeat("{{#a}}", 20) + strings.Repeat("{{/a}}", 20)} {
+		_, err := store.Create("tenant-a", "alice", TemplateInput{Name: "t", Engine: TemplateEngineMustache, Content: content})
+		assertTemplateError(t, err, types.ErrorTypeInvalidRequest)
+	}
+}
+
+func TestTemplateStore_OutputLimit(t *testing.T) {
+	store := NewTemplateStore()
+	store.SetOutputLimit(10)
+
+	_, err := renderNew(t, store, TemplateEngineGo, "{{range .items}}0123456789{{end}}", map[string]interface{}{"items": []int{1, 2}})
+	assertTemplateError(t, err, types.ErrorTypeInvalidRequest)
+
+	rendered, err := renderNew(t, store, TemplateEngineMustache, "{{x}}", map[string]interface{}{"x": "short"})
+	require.NoError(t, err)
+	assert.Equal(t, "short", rendered)
+}
+
+// shoutEngine is an engine a caller plugs in
+type shoutEngine struct{}
+
+func (shoutEngine) Name() string                  { return "shout" }
+func (shoutEngine) Validate(content string) error { return nil }
+func (shoutEngine) Render(w io.Writer, content string, values map[string]interface{}) error {
+	_, err := io.WriteString(w, strings.ToUpper(content))
+	return err
+}
+
+func TestTemplateStore_EngineSelection(t *testing.T) {
+	store := NewTemplateStore()
+
+	_, err := store.Create("tenant-a", "alice", TemplateInput{Name: "t", Engine: "shout", Content: "hey"})
+	assertTemplateError(t, err, types.ErrorTypeInvalidRequest)
+
+	store.RegisterEngine(shoutEngine{})
+	rendered, err := renderNew(t, store, "shout", "hey", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "HEY", rendered)
+
+	// Templates saved without an engine keep the simple engine, and each
+	// version renders with the engine it was saved with
+	created, err := store.Create("tenant-a", "alice", TemplateInput{Name: "t", Content: "Hi {{name}} {{other}}"})
+	require.NoError(t, err)
+	assert.Equal(t, TemplateEngineSimple, created.Engine)
+	_, err = store.Update("tenant-a", created.ID, "alice", TemplateInput{Name: "t", Engine: TemplateEngineGo, Content: "Hi {{.name}}"})
+	require.NoError(t, err)
+
+	rendered, _, err = store.Render("tenant-a", created.ID, 1, map[string]interface{}{"name": "Ada"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hi Ada {{other}}", rendered)
+	rendered, _, err = store.Render("tenant-a", created.ID, 2, map[string]interface{}{"name": "Ada"})
+	require.NoError(t, err)
+	assert.Equal(t, "Hi Ada", rendered)
+
+	rolledBack, err := store.Rollback("tenant-a", created.ID, 1, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, TemplateEngineSimple, rolledBack.Engine)
+}
+
+func TestTemplateStore_RendersNumbersAsWritten(t *testing.T) {
+	store := NewTemplateStore()
+	values := map[string]interface{}{"budget": 1000000, "ratio": 0.25, "big": 12345678901234, "items": []interface{}{2500000.0}}
+
+	for _, engine := range []string{TemplateEngineGo, TemplateEngineMustache} {
+		content := "{{.budget}} {{.ratio}} {{.big}} {{range .items}}{{.}}{{end}}"
+		if engine == TemplateEngineMustache {
+			content = "{{budget}} {{ratio}} {{big}} {{#items}}{{.}}{{/items}}"
+		}
+
+		rendered, err := renderNew(t, store, engine, content, values)
+		require.NoError(t, err, engine)
+		assert.Equal(t, "1000000 0.25 12345678901234 2500000", rendered, engine)
+	}
+
+	// Whole numbers still compare as numbers
+	rendered, err := renderNew(t, store, TemplateEngineGo, "{{if gt .budget 999}}large{{end}}", values)
+	require.NoError(t, err)
+	assert.Equal(t, "large", rendered)
+}
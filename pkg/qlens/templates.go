@@ -0,0 +1,407 @@
+package qlens
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+// templatePlaceholder matches {{name}} and {{ name }} placeholders
+var templatePlaceholder = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// TemplateVersion is an immutable snapshot of a prompt template. Every update
+// or rollback appends a new version; existing versions are never modified.
+type TemplateVersion struct {
+	Version        int64                     `json:"version"`
+	Content        string                    `json:"content"`
+	Engine         string                    `json:"engine"`
+	Variables      []domain.TemplateVariable `json:"variables"`
+	Description    string                    `json:"description,omitempty"`
+	CreatedBy      domain.UserID             `json:"created_by,omitempty"`
+	CreatedAt      time.Time                 `json:"created_at"`
+	RolledBackFrom int64                     `json:"rolled_back_from,omitempty"`
+}
+
+// TemplateInput carries the editable fields of a prompt template
+type TemplateInput struct {
+	Name        string                    `json:"name"`
+	Description string                    `json:"description,omitempty"`
+	Category    string                    `json:"category,omitempty"`
+	Tags        []string                  `json:"tags,omitempty"`
+	Content     string                    `json:"content"`
+	Engine      string                    `json:"engine,omitempty"` // Defaults to TemplateEngineSimple
+	Variables   []domain.TemplateVariable `json:"variables,omitempty"`
+	IsPublic    bool                      `json:"is_public"`
+}
+
+// Template is the API view of a prompt template at its current version
+type Template struct {
+	ID          string                    `json:"id"`
+	TenantID    domain.TenantID           `json:"tenant_id"`
+	Name        string                    `json:"name"`
+	Description string                    `json:"description,omitempty"`
+	Category    string                    `json:"category,omitempty"`
+	Tags        []string                  `json:"tags"`
+	Content     string                    `json:"content"`
+	Engine      string                    `json:"engine"`
+	Variables   []domain.TemplateVariable `json:"variables"`
+	CreatedBy   domain.UserID             `json:"created_by,omitempty"`
+	IsPublic    bool                      `json:"is_public"`
+	UsageCount  int                       `json:"usage_count"`
+	Version     int64                     `json:"version"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	UpdatedAt   time.Time                 `json:"updated_at"`
+}
+
+// TemplateStore keeps prompt templates and their version history in memory,
+// scoped per tenant. Each template is rendered by the engine it was saved
+// with.
+type TemplateStore struct {
+	mu          sync.RWMutex
+	templates   map[domain.TenantID]map[string]*storedTemplate
+	engines     map[string]TemplateEngine
+	outputLimit int
+}
+
+type storedTemplate struct {
+	template *domain.PromptTemplate
+	versions []TemplateVersion
+}
+
+// NewTemplateStore creates an empty template store with the simple,
+// mustache and Go template engines
+func NewTemplateStore() *TemplateStore {
+	s := &TemplateStore{
+		templates:   make(map[domain.TenantID]map[string]*storedTemplate),
+		engines:     make(map[string]TemplateEngine),
+		outputLimit: DefaultTemplateOutputLimit,
+	}
+	for _, engine := range []TemplateEngine{simpleEngine{}, mustacheEngine{}, goEngine{}} {
+		s.engines[engine.Name()] = engine
+	}
+	return s
+}
+
+// RegisterEngine adds a template engine, or replaces the one of the same
+// name. Templates already saved are not revalidated.
+func (s *TemplateStore) RegisterEngine(engine TemplateEngine) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.engines[engine.Name()] = engine
+}
+
+// SetOutputLimit sets the most bytes a template may render to
+func (s *TemplateStore) SetOutputLimit(limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.outputLimit = limit
+}
+
+// Create stores a new template as version 1
+func (s *TemplateStore) Create(tenantID domain.TenantID, userID domain.UserID, input TemplateInput) (*Template, error) {
+	if err := s.validateTemplateInput(&input); err != nil {
+		return nil, err
+	}
+
+	template := domain.NewPromptTemplate(tenantID, userID, input.Name, input.Content)
+	template.Engine = input.Engine
+	template.Description = input.Description
+	template.Category = input.Category
+	template.IsPublic = input.IsPublic
+	if input.Tags != nil {
+		template.Tags = append([]string(nil), input.Tags...)
+	}
+	if input.Variables != nil {
+		template.Variables = copyVariables(input.Variables)
+	}
+
+	stored := &storedTemplate{template: template}
+	stored.versions = append(stored.versions, snapshotTemplate(template, userID, 0))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.templates[tenantID] == nil {
+		s.templates[tenantID] = make(map[string]*storedTemplate)
+	}
+	s.templates[tenantID][template.ID()] = stored
+
+	return toTemplate(template), nil
+}
+
+// Get returns the current version of a template
+func (s *TemplateStore) Get(tenantID domain.TenantID, id string) (*Template, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored, err := s.lookup(tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	return toTemplate(stored.template), nil
+}
+
+// List returns the current version of every template owned by the tenant
+func (s *TemplateStore) List(tenantID domain.TenantID) []*Template {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	templates := make([]*Template, 0, len(s.templates[tenantID]))
+	for _, stored := range s.templates[tenantID] {
+		templates = append(templates, toTemplate(stored.template))
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].CreatedAt.Before(templates[j].CreatedAt)
+	})
+	return templates
+}
+
+// Update applies the input as a new immutable version of the template
+func (s *TemplateStore) Update(tenantID domain.TenantID, id string, userID domain.UserID, input TemplateInput) (*Template, error) {
+	if err := s.validateTemplateInput(&input); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.lookup(tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	template := stored.template
+	template.Name = input.Name
+	template.Description = input.Description
+	template.Category = input.Category
+	template.Content = input.Content
+	template.Engine = input.Engine
+	template.IsPublic = input.IsPublic
+	template.Tags = append(make([]string, 0, len(input.Tags)), input.Tags...)
+	template.Variables = copyVariables(input.Variables)
+
+	s.appendVersion(stored, userID, 0)
+	return toTemplate(template), nil
+}
+
+// Versions returns the full version history of a template, oldest first
+func (s *TemplateStore) Versions(tenantID domain.TenantID, id string) ([]TemplateVersion, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored, err := s.lookup(tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+	return append([]TemplateVersion(nil), stored.versions...), nil
+}
+
+// Rollback restores the content of an earlier version. History is preserved:
+// the restored content is appended as a new version rather than rewinding.
+func (s *TemplateStore) Rollback(tenantID domain.TenantID, id string, version int64, userID domain.UserID) (*Template, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.lookup(tenantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := stored.version(version)
+	if err != nil {
+		return nil, err
+	}
+
+	template := stored.template
+	template.Content = target.Content
+	template.Engine = target.Engine
+	template.Description = target.Description
+	template.Variables = copyVariables(target.Variables)
+
+	s.appendVersion(stored, userID, target.Version)
+	return toTemplate(template), nil
+}
+
+// Render substitutes variables into the template with the engine the version
+// was saved with. A version of zero renders the current version.
+func (s *TemplateStore) Render(tenantID domain.TenantID, id string, version int64, variables map[string]interface{}) (string, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, err := s.lookup(tenantID, id)
+	if err != nil {
+		return "", 0, err
+	}
+
+	if version == 0 {
+		version = stored.template.Version()
+	}
+	target, err := stored.version(version)
+	if err != nil {
+		return "", 0, err
+	}
+
+	values := make(map[string]interface{}, len(target.Variables)+len(variables))
+	for _, variable := range target.Variables {
+		if variable.DefaultValue != nil {
+			values[variable.Name] = variable.DefaultValue
+		}
+	}
+	for name, value := range variables {
+		values[name] = value
+	}
+
+	var missing []string
+	for _, variable := range target.Variables {
+		if _, ok := values[variable.Name]; variable.Required && !ok {
+			missing = append(missing, variable.Name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", 0, &types.QLensError{
+			Type:    types.ErrorTypeInvalidRequest,
+			Message: "missing required template variables",
+			Details: map[string]interface{}{"missing": missing},
+		}
+	}
+
+	engine, err := s.engine(target.Engine)
+	if err != nil {
+		return "", 0, err
+	}
+	var rendered strings.Builder
+	if err := engine.Render(&limitedWriter{w: &rendered, remaining: s.outputLimit}, target.Content, values); err != nil {
+		return "", 0, &types.QLensError{
+			Type:    types.ErrorTypeInvalidRequest,
+			Message: fmt.Sprintf("failed to render template: %v", err),
+			Details: map[string]interface{}{"engine": engine.Name(), "version": target.Version},
+		}
+	}
+
+	stored.template.IncrementUsage()
+	return rendered.String(), target.Version, nil
+}
+
+// engine returns the named engine, or the simple engine for templates saved
+// without one
+func (s *TemplateStore) engine(name string) (TemplateEngine, error) {
+	if name == "" {
+		name = TemplateEngineSimple
+	}
+	engine, ok := s.engines[name]
+	if !ok {
+		return nil, &types.QLensError{
+			Type:    types.ErrorTypeInvalidRequest,
+			Message: fmt.Sprintf("unknown template engine %s", name),
+			Details: map[string]interface{}{"field": "engine", "engines": templateEngineNames(s.engines)},
+		}
+	}
+	return engine, nil
+}
+
+func (s *TemplateStore) lookup(tenantID domain.TenantID, id string) (*storedTemplate, error) {
+	stored, ok := s.templates[tenantID][id]
+	if !ok {
+		return nil, &types.QLensError{
+			Type:    types.ErrorTypeNotFound,
+			Message: fmt.Sprintf("template %s not found", id),
+		}
+	}
+	return stored, nil
+}
+
+func (s *TemplateStore) appendVersion(stored *storedTemplate, userID domain.UserID, rolledBackFrom int64) {
+	template := stored.template
+	event := &domain.PromptTemplateVersionCreated{
+		BaseDomainEvent: domain.NewBaseDomainEvent("PromptTemplateVersionCreated", template.ID(), "PromptTemplate", template.Version()+1),
+		TemplateID:      template.ID(),
+		TenantID:        template.TenantID,
+		TemplateVersion: template.Version() + 1,
+		RolledBackFrom:  rolledBackFrom,
+		CreatedBy:       userID,
+	}
+	template.ApplyEvent(event)
+	stored.versions = append(stored.versions, snapshotTemplate(template, userID, rolledBackFrom))
+}
+
+func (t *storedTemplate) version(version int64) (TemplateVersion, error) {
+	if version < 1 || version > int64(len(t.versions)) {
+		return TemplateVersion{}, &types.QLensError{
+			Type:    types.ErrorTypeNotFound,
+			Message: fmt.Sprintf("template %s has no version %d", t.template.ID(), version),
+		}
+	}
+	return t.versions[version-1], nil
+}
+
+// validateTemplateInput checks the input and that its content parses with
+// its engine, defaulting the engine
+func (s *TemplateStore) validateTemplateInput(input *TemplateInput) error {
+	if input.Name == "" {
+		return &types.QLensError{Type: types.ErrorTypeInvalidRequest, Message: "template name is required"}
+	}
+	if input.Content == "" {
+		return &types.QLensError{Type: types.ErrorTypeInvalidRequest, Message: "template content is required"}
+	}
+	if input.Engine == "" {
+		input.Engine = TemplateEngineSimple
+	}
+
+	s.mu.RLock()
+	engine, err := s.engine(input.Engine)
+	s.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if err := engine.Validate(input.Content); err != nil {
+		return &types.QLensError{
+			Type:    types.ErrorTypeInvalidRequest,
+			Message: fmt.Sprintf("invalid %s template: %v", engine.Name(), err),
+			Details: map[string]interface{}{"field": "content", "engine": engine.Name()},
+		}
+	}
+	return nil
+}
+
+func snapshotTemplate(template *domain.PromptTemplate, userID domain.UserID, rolledBackFrom int64) TemplateVersion {
+	return TemplateVersion{
+		Version:        template.Version(),
+		Content:        template.Content,
+		Engine:         template.Engine,
+		Variables:      copyVariables(template.Variables),
+		Description:    template.Description,
+		CreatedBy:      userID,
+		CreatedAt:      template.UpdatedAt(),
+		RolledBackFrom: rolledBackFrom,
+	}
+}
+
+func toTemplate(template *domain.PromptTemplate) *Template {
+	return &Template{
+		ID:          template.ID(),
+		TenantID:    template.TenantID,
+		Name:        template.Name,
+		Description: template.Description,
+		Category:    template.Category,
+		Tags:        append(make([]string, 0, len(template.Tags)), template.Tags...),
+		Content:     template.Content,
+		Engine:      template.Engine,
+		Variables:   copyVariables(template.Variables),
+		CreatedBy:   template.CreatedBy,
+		IsPublic:    template.IsPublic,
+		UsageCount:  template.UsageCount,
+		Version:     template.Version(),
+		CreatedAt:   template.CreatedAt(),
+		UpdatedAt:   template.UpdatedAt(),
+	}
+}
+
+func copyVariables(variables []domain.TemplateVariable) []domain.TemplateVariable {
+	return append(make([]domain.TemplateVariable, 0, len(variables)), variables...)
+}
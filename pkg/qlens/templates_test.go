@@ -0,0 +1,104 @@
+package qlens
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+	"github.com/quantum-suite/platform/pkg/qlens-types"
+)
+
+func TestTemplateStore_UpdateAppendsVersions(t *testing.T) {
+	store := NewTemplateStore()
+
+	created, err := store.Create("tenant-a", "alice", TemplateInput{Name: "greet", Content: "Hello {{name}}"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), created.Version)
+
+	updated, err := store.Update("tenant-a", created.ID, "bob", TemplateInput{Name: "greet", Content: "Hi {{ name }}!"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), updated.Version)
+
+	versions, err := store.Versions("tenant-a", created.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "Hello {{name}}", versions[0].Content)
+	assert.Equal(t, domain.UserID("alice"), versions[0].CreatedBy)
+	assert.Equal(t, "Hi {{ name }}!", versions[1].Content)
+	assert.Equal(t, domain.UserID("bob"), versions[1].CreatedBy)
+}
+
+func TestTemplateStore_RollbackCreatesNewVersion(t *testing.T) {
+	store := NewTemplateStore()
+
+	created, err := store.Create("tenant-a", "alice", TemplateInput{Name: "greet", Content: "v1"})
+	require.NoError(t, err)
+	_, err = store.Update("tenant-a", created.ID, "alice", TemplateInput{Name: "greet", Content: "v2"})
+	require.NoError(t, err)
+
+	rolledBack, err := store.Rollback("tenant-a", created.ID, 1, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), rolledBack.Version)
+	assert.Equal(t, "v1", rolledBack.Content)
+
+	versions, err := store.Versions("tenant-a", created.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 3)
+	assert.Equal(t, "v2", versions[1].Content, "earlier versions are never rewritten")
+	assert.Equal(t, int64(1), versions[2].RolledBackFrom)
+
+	_, err = store.Rollback("tenant-a", created.ID, 9, "alice")
+	assertTemplateError(t, err, types.ErrorTypeNotFound)
+}
+
+func TestTemplateStore_RenderVersion(t *testing.T) {
+	store := NewTemplateStore()
+
+	created, err := store.Create("tenant-a", "alice", TemplateInput{
+		Name:    "greet",
+		Content: "Hello {{name}} from {{team}}",
+		Variables: []domain.TemplateVariable{
+			{Name: "name", Required: true},
+			{Name: "team", DefaultValue: "support"},
+		},
+	})
+	require.NoError(t, err)
+	_, err = store.Update("tenant-a", created.ID, "alice", TemplateInput{Name: "greet", Content: "Bye {{name}}"})
+	require.NoError(t, err)
+
+	rendered, version, err := store.Render("tenant-a", created.ID, 0, map[string]interface{}{"name": "Ada"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+	assert.Equal(t, "Bye Ada", rendered)
+
+	rendered, version, err = store.Render("tenant-a", created.ID, 1, map[string]interface{}{"name": "Ada"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), version)
+	assert.Equal(t, "Hello Ada from support", rendered)
+
+	_, _, err = store.Render("tenant-a", created.ID, 1, nil)
+	assertTemplateError(t, err, types.ErrorTypeInvalidRequest)
+}
+
+func TestTemplateStore_TenantIsolation(t *testing.T) {
+	store := NewTemplateStore()
+
+	created, err := store.Create("tenant-a", "alice", TemplateInput{Name: "greet", Content: "Hello"})
+	require.NoError(t, err)
+
+	_, err = store.Get("tenant-b", created.ID)
+	assertTemplateError(t, err, types.ErrorTypeNotFound)
+	_, err = store.Versions("tenant-b", created.ID)
+	assertTemplateError(t, err, types.ErrorTypeNotFound)
+	assert.Empty(t, store.List("tenant-b"))
+	assert.Len(t, store.List("tenant-a"), 1)
+}
+
+func assertTemplateError(t *testing.T, err error, errorType string) {
+	t.Helper()
+	qlensErr, ok := err.(*types.QLensError)
+	require.True(t, ok, "expected a QLensError, got %v", err)
+	assert.Equal(t, errorType, qlensErr.Type)
+}
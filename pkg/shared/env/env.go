@@ -0,0 +1,1272 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// Environment represents the deployment environment a service runs in
+type Environment string
+
+const (
+	Development Environment = "development"
+	Staging     Environment = "staging"
+	Production  Environment = "production"
+
+	// EnvironmentDevelopment is kept for callers using the long-form name
+	EnvironmentDevelopment = Development
+)
+
+// IsDevelopment reports whether the environment is development
+func (e Environment) IsDevelopment() bool {
+	return e == Development
+}
+
+// IsProduction reports whether the environment is production
+func (e Environment) IsProduction() bool {
+	return e == Production
+}
+
+// Config holds service configuration resolved from the environment
+type Config struct {
+	Environment Environment `json:"environment"`
+	ServiceName string      `json:"service_name"`
+	Version     string      `json:"version"`
+	Port        int         `json:"port"`
+
+	// Security
+	AuthEnabled  bool `json:"auth_enabled"`
+	IstioEnabled bool `json:"istio_enabled"`
+
+	// AdminAPIKey guards the internal admin endpoints; they are disabled
+	// when it is empty
+	AdminAPIKey string `json:"-"`
+
+	// RequestSigningSecrets are the per-tenant HMAC secrets for requests
+	// authenticated by X-Signature instead of an API key or JWT. Signed
+	// requests must carry a timestamp within RequestSigningWindow and a nonce
+	// not seen within twice that window.
+	RequestSigningSecrets map[string]string `json:"-"`
+	RequestSigningWindow  time.Duration     `json:"request_signing_window"`
+
+	// ResponseSigningTenants are tenants whose responses carry an
+	// X-Response-Signature, the HMAC of the response keyed by the tenant's
+	// RequestSigningSecrets entry
+	ResponseSigningTenants []string `json:"response_signing_tenants,omitempty"`
+
+	// Compression
+	CompressionEnabled       bool  `json:"compression_enabled"`
+	MaxDecompressedBodyBytes int64 `json:"max_decompressed_body_bytes"`
+
+	// PrefillEmulation lets requests ending in an assistant message reach
+	// providers that cannot continue one natively; the prefill is turned
+	// into a system instruction to begin the response with it
+	PrefillEmulation bool `json:"prefill_emulation"`
+
+	// StripJSONFences cleans up responses to JSON requests: markdown code
+	// fences and prose the model put around the JSON are removed
+	StripJSONFences bool `json:"strip_json_fences"`
+
+	// ToolResultSanitization is applied to the content of tool messages
+	// before it reaches a provider, since tool output can carry injected
+	// instructions: ToolSanitizationOff, ToolSanitizationStrip to remove
+	// known injection patterns, or ToolSanitizationWrap to also wrap it in
+	// a block marked as data. TenantToolResultSanitization overrides it per
+	// tenant.
+	ToolResultSanitization       string            `json:"tool_result_sanitization"`
+	TenantToolResultSanitization map[string]string `json:"tenant_tool_result_sanitization,omitempty"`
+
+	// ContextUtilizationWarning is the fraction of a model's context window a
+	// prompt may fill before the response carries a warning header. Zero
+	// disables the warning; utilization is recorded either way.
+	ContextUtilizationWarning float64 `json:"context_utilization_warning"`
+
+	// ModelDeprecationAction decides what happens to requests for a model
+	// the registry marks deprecated: ModelDeprecationWarn answers them with
+	// a warning, ModelDeprecationMigrate sends them to the model's entry in
+	// ModelSuccessors instead, and warns when it has none
+	ModelDeprecationAction string            `json:"model_deprecation_action"`
+	ModelSuccessors        map[string]string `json:"model_successors,omitempty"`
+
+	// ModelAliases maps names clients may request, such as "fast", to the
+	// model the gateway sends instead. Aliases are not chained.
+	ModelAliases map[string]string `json:"model_aliases,omitempty"`
+
+	// ModelPricing overrides the registry's pricing for the models it names,
+	// for negotiated rates or models providers do not price. Costs are per
+	// token.
+	ModelPricing    map[string]domain.ModelPricing `json:"model_pricing,omitempty"`
+	modelPricingErr error
+
+	// MaxCostUSD is the cost ceiling applied to requests that set a higher
+	// one or none, enforced as max_cost_usd is. Zero disables it.
+	MaxCostUSD float64 `json:"max_cost_usd"`
+
+	// Semantic request limits, checked after parsing so clients get a specific
+	// error rather than a provider or tokenizer failure. Zero disables a limit.
+	MaxMessages     int   `json:"max_messages"`
+	MaxContentBytes int64 `json:"max_content_bytes"`
+
+	// TenantMaxToolRounds caps the tool-call rounds of one agent conversation
+	// for the tenants listed, counted from the tool results a request carries.
+	// Conversations identified by the client's X-Conversation-ID also
+	// remember their answered rounds until idle longer than ToolRoundIdleTTL.
+	TenantMaxToolRounds map[string]int `json:"tenant_max_tool_rounds,omitempty"`
+	ToolRoundIdleTTL    time.Duration  `json:"tool_round_idle_ttl"`
+
+	// Per-tenant rate limits over one-minute windows, reported to clients in
+	// X-RateLimit-* headers. Zero disables a limit.
+	RateLimitRequestsPerMinute int `json:"rate_limit_requests_per_minute"`
+	RateLimitTokensPerMinute   int `json:"rate_limit_tokens_per_minute"`
+
+	// Per-tenant rate limits at each provider a request names, reported in
+	// X-RateLimit-*-Provider-* headers. Zero disables a limit.
+	RateLimitProviderRequestsPerMinute int `json:"rate_limit_provider_requests_per_minute"`
+	RateLimitProviderTokensPerMinute   int `json:"rate_limit_provider_tokens_per_minute"`
+
+	// DebugRawResponses allows callers to request the provider's raw response
+	// body via X-Include-Raw-Response (defaults to on in development only)
+	DebugRawResponses bool `json:"debug_raw_responses"`
+
+	// DebugExplainRouting allows callers to ask how their request was routed
+	// via X-Explain-Routing (defaults to on in development only)
+	DebugExplainRouting bool `json:"debug_explain_routing"`
+
+	// Streaming token-rate throttle. Zero disables the default cap; tenant
+	// caps override the default and always bound what a request may ask for.
+	StreamTokensPerSecond       float64            `json:"stream_tokens_per_second,omitempty"`
+	TenantStreamTokensPerSecond map[string]float64 `json:"tenant_stream_tokens_per_second,omitempty"`
+
+	// StreamCollectTenants are tenants whose streaming requests are streamed
+	// from the provider but answered with a single JSON response, for
+	// clients behind middleboxes that mangle server-sent events
+	StreamCollectTenants []string `json:"stream_collect_tenants,omitempty"`
+
+	// StreamFallback retries a stream the provider failed to open as a
+	// non-streaming request, delivering the response as a single event
+	StreamFallback bool `json:"stream_fallback"`
+
+	// StreamPassthrough allows callers to receive the provider's own
+	// event-stream chunks via X-Stream-Passthrough, for OpenAI-schema
+	// providers
+	StreamPassthrough bool `json:"stream_passthrough"`
+
+	// StreamFailoverGrace is how long after a stream opens a provider
+	// failure that has not yet sent content moves the stream to another
+	// provider serving the model. 0 disables failover.
+	StreamFailoverGrace time.Duration `json:"stream_failover_grace"`
+
+	// ResponseSizeAlertFactor raises an alert when a model's recent average
+	// response size is this many times above or below its long-run average.
+	// 0 disables the alert.
+	ResponseSizeAlertFactor float64 `json:"response_size_alert_factor"`
+
+	// AlertManagerURL is the Alertmanager the router sends its own alerts to
+	AlertManagerURL string `json:"alertmanager_url,omitempty"`
+
+	// CreatedMaxSkew is how far a provider's Created timestamp may be from
+	// the router's clock before it is replaced with the time the response
+	// arrived. 0 only fills in missing timestamps.
+	CreatedMaxSkew time.Duration `json:"created_max_skew"`
+
+	// FAQ answers completions from tenants' curated canonical questions
+	FAQ FAQConfig `json:"faq"`
+
+	// Batch completions
+	Batch BatchConfig `json:"batch"`
+
+	// Cache
+	CacheType string      `json:"cache_type"`
+	Cache     CacheConfig `json:"cache"`
+
+	// Logging
+	Logging LoggingConfig `json:"logging"`
+
+	// Providers keyed by provider name (e.g. "azure-openai", "aws-bedrock")
+	Providers map[string]ProviderConfig `json:"providers"`
+
+	// DefaultCompletionModel and DefaultEmbeddingModel are used by requests
+	// that name no model and whose tenant has no default of its own
+	DefaultCompletionModel string `json:"default_completion_model,omitempty"`
+	DefaultEmbeddingModel  string `json:"default_embedding_model,omitempty"`
+
+	// TenantConfigFile is a JSON file of per-tenant default providers and
+	// models, re-read on every configuration reload
+	TenantConfigFile string `json:"tenant_config_file,omitempty"`
+
+	// TenantProviders restricts tenants to the listed providers, whether a
+	// request pins one or leaves the choice to the router. Tenants not
+	// listed may use every provider.
+	TenantProviders map[string][]domain.Provider `json:"tenant_providers,omitempty"`
+
+	// DefaultProviderOrder is the preferred provider order used when a request
+	// does not pin a provider and several healthy providers serve the model
+	DefaultProviderOrder []domain.Provider `json:"default_provider_order,omitempty"`
+
+	// EmbeddingFallbacks lists, per embedding model, the models tried in order
+	// when it is unavailable. A fallback is only used when EmbeddingDimensions
+	// gives both models the same vector size.
+	EmbeddingFallbacks  map[string][]string `json:"embedding_fallbacks,omitempty"`
+	EmbeddingDimensions map[string]int      `json:"embedding_dimensions,omitempty"`
+
+	// EmbeddingBatchSize caps the inputs sent to a provider in one call;
+	// larger requests are split and sent EmbeddingBatchConcurrency batches
+	// at a time. Zero sends every request whole.
+	EmbeddingBatchSize        int `json:"embedding_batch_size"`
+	EmbeddingBatchConcurrency int `json:"embedding_batch_concurrency"`
+
+	// AutoRoutingWeights weigh each provider's normalized cost, latency and
+	// error rate when a request asks for provider "auto"
+	AutoRoutingWeights AutoRoutingWeights `json:"auto_routing_weights"`
+
+	// EmbeddingCostRouting sends embedding requests that pin no provider to
+	// the provider serving the model with the lowest AutoRoutingWeights
+	// score, and lets requests for model "auto" be served by the best
+	// scoring model of the requested dimensions
+	EmbeddingCostRouting bool `json:"embedding_cost_routing"`
+
+	// ModelDefaults are per-model output limits, keyed by model ID; the "*"
+	// entry covers models without a value of their own
+	ModelDefaults map[string]ModelDefaults `json:"model_defaults,omitempty"`
+
+	// ParamProfiles are named sampling presets a request selects with
+	// X-Param-Profile or param_profile; parameters set on the request win
+	ParamProfiles    map[string]ParamProfile `json:"param_profiles,omitempty"`
+	paramProfilesErr error
+
+	// ModelTransforms adapt requests to models that reject parameters other
+	// models accept, keyed by model ID prefix (see ModelTransformFor)
+	ModelTransforms    map[string]ModelTransform `json:"model_transforms,omitempty"`
+	modelTransformsErr error
+
+	// RetryStatusCodes and RetryErrorTypes decide which failed provider
+	// calls are retried, overriding the retryable flag on the error: see
+	// errors.RetryPolicy for the precedence
+	RetryStatusCodes    []int    `json:"retry_status_codes,omitempty"`
+	RetryErrorTypes     []string `json:"retry_error_types,omitempty"`
+	retryStatusCodesErr error
+
+	// ProviderKeepAliveInterval is how often healthy providers are probed to
+	// keep their connection pools warm. Zero disables the keepalive.
+	ProviderKeepAliveInterval time.Duration `json:"provider_keepalive_interval"`
+
+	// ProviderWarmupTimeout bounds the startup warm-up, which opens
+	// ProviderWarmupConnections connections to every enabled provider and
+	// fetches their credentials before the router reports ready. Zero
+	// disables the warm-up.
+	ProviderWarmupTimeout     time.Duration `json:"provider_warmup_timeout"`
+	ProviderWarmupConnections int           `json:"provider_warmup_connections"`
+
+	// HealthCheckInterval is how often providers are actively probed, each
+	// with its configured health probe. Zero disables active probing and
+	// provider health comes from real traffic through the circuit breaker.
+	HealthCheckInterval time.Duration `json:"health_check_interval"`
+
+	// HealthCheckTimeout bounds each active probe, and HealthCheckConcurrency
+	// how many providers are probed at once. Zero uses the defaults of 10s
+	// and 4.
+	HealthCheckTimeout     time.Duration `json:"health_check_timeout"`
+	HealthCheckConcurrency int           `json:"health_check_concurrency"`
+
+	// KillSwitches stop all traffic to providers or models, on top of the
+	// switches toggled through the admin API. The router rereads the
+	// toggled switches from the shared store every KillSwitchRefresh.
+	KillSwitches      []domain.KillSwitch `json:"kill_switches,omitempty"`
+	killSwitchesErr   error
+	KillSwitchRefresh time.Duration `json:"kill_switch_refresh"`
+
+	// PassiveHealth derives provider health from the outcomes of real
+	// requests, combined with the active checks
+	PassiveHealth PassiveHealthConfig `json:"passive_health"`
+
+	// TenantCredentialsDir holds tenants' own provider credentials, one
+	// <tenant>/<provider>.json file each (e.g. a mounted secret). Empty
+	// disables bring-your-own-key and every request uses the shared keys.
+	TenantCredentialsDir string `json:"tenant_credentials_dir,omitempty"`
+
+	// TenantCredentialsTTL is how long resolved tenant credentials and the
+	// clients built from them are kept before being read again
+	TenantCredentialsTTL time.Duration `json:"tenant_credentials_ttl"`
+
+	// DeadLetterPath is the file that requests failing after every retry and
+	// fallback are appended to, one redacted JSON record per line. Empty
+	// disables the dead-letter log.
+	DeadLetterPath string `json:"dead_letter_path,omitempty"`
+
+	// ModelRegistryManifest is a JSON file pinning the router's model
+	// registry to a versioned list of models, so replicas that started at
+	// different times route on the same view. Empty builds the registry from
+	// the providers' live model listings. ModelRegistryLive adds the models
+	// the providers list that the manifest does not.
+	ModelRegistryManifest string `json:"model_registry_manifest,omitempty"`
+	ModelRegistryLive     bool   `json:"model_registry_live"`
+
+	// StreamUsageSampleRate is the fraction of completed streams whose
+	// provider-reported usage is compared against the token estimate.
+	// StreamUsageBillingSource decides which of the two streams are billed
+	// by: StreamUsageSourceProvider or StreamUsageSourceEstimate.
+	StreamUsageSampleRate    float64 `json:"stream_usage_sample_rate"`
+	StreamUsageBillingSource string  `json:"stream_usage_billing_source"`
+}
+
+// Sources of the usage a completed stream is billed by
+const (
+	StreamUsageSourceProvider = "provider"
+	StreamUsageSourceEstimate = "estimate"
+)
+
+// FAQConfig configures the canonical-intent FAQ layer. A prompt whose
+// embedding is at least MatchThreshold similar to one of the tenant's
+// canonical questions is answered with that question's stored answer. A
+// MatchThreshold of 0 disables the layer.
+type FAQConfig struct {
+	EmbeddingModel string  `json:"embedding_model"`
+	MatchThreshold float64 `json:"match_threshold"`
+
+	// StorePath is the file entries are kept in across restarts. Empty
+	// keeps them in memory only.
+	StorePath string `json:"store_path,omitempty"`
+}
+
+// Tool result sanitization modes
+const (
+	ToolSanitizationOff   = "off"
+	ToolSanitizationStrip = "strip"
+	ToolSanitizationWrap  = "wrap"
+)
+
+// Model deprecation actions
+const (
+	ModelDeprecationWarn    = "warn"
+	ModelDeprecationMigrate = "migrate"
+)
+
+// ProviderConfig holds connection settings for a single provider
+type ProviderConfig struct {
+	Enabled    bool                   `json:"enabled"`
+	APIKey     string                 `json:"-"`
+	SecretKey  string                 `json:"-"`
+	BaseURL    string                 `json:"base_url,omitempty"`
+	Timeout    time.Duration          `json:"timeout"`
+	MaxRetries int                    `json:"max_retries"`
+	Config     map[string]interface{} `json:"config,omitempty"`
+
+	// UserAgent overrides the default QLens/<version> user agent
+	UserAgent string `json:"user_agent,omitempty"`
+
+	// CustomHeaders are sent with every request to the provider
+	CustomHeaders map[string]string `json:"custom_headers,omitempty"`
+
+	// DataRegion is the data-residency region the provider's endpoint
+	// serves from, matched against requests' data residency
+	DataRegion string `json:"data_region,omitempty"`
+}
+
+// LoggingConfig holds logger settings
+type LoggingConfig struct {
+	Level      string `json:"level"`
+	Format     string `json:"format"`
+	Structured bool   `json:"structured"`
+
+	// RequestSampleN logs one in every N successful requests; failed
+	// requests are always logged. 0 or 1 logs every request.
+	RequestSampleN int `json:"request_sample_n"`
+
+	// MaxFieldBytes truncates string values in request logs to this many
+	// bytes. 0 disables truncation.
+	MaxFieldBytes int `json:"max_field_bytes"`
+}
+
+// CacheConfig holds cache settings
+type CacheConfig struct {
+	Type    string        `json:"type"`
+	TTL     time.Duration `json:"ttl"`
+	MaxSize int           `json:"max_size"`
+
+	// StaleGrace keeps cached completions this long past their TTL, to
+	// answer requests that opt in when every provider fails. Zero disables
+	// stale answers.
+	StaleGrace time.Duration `json:"stale_grace"`
+}
+
+// BatchConfig holds limits for asynchronous batch completion jobs
+type BatchConfig struct {
+	MaxItems       int           `json:"max_items"`
+	MaxConcurrency int           `json:"max_concurrency"`
+	MaxRetries     int           `json:"max_retries"`
+	Retention      time.Duration `json:"retention"`
+}
+
+// ModelDefaults limit a model's output. MaxTokens is used when a request
+// omits max_tokens and MaxTokensCeiling clamps larger values, so requests
+// cannot run to the end of the context window. Zero leaves either unset.
+type ModelDefaults struct {
+	MaxTokens        int `json:"max_tokens,omitempty"`
+	MaxTokensCeiling int `json:"max_tokens_ceiling,omitempty"`
+}
+
+// AllModels keys the ModelDefaults entry that applies to every model
+const AllModels = "*"
+
+// ModelDefaultsFor returns the model's output limits, each falling back to
+// the AllModels entry when the model does not set it
+func (c *Config) ModelDefaultsFor(model string) ModelDefaults {
+	defaults := c.ModelDefaults[model]
+	fallback := c.ModelDefaults[AllModels]
+	if defaults.MaxTokens == 0 {
+		defaults.MaxTokens = fallback.MaxTokens
+	}
+	if defaults.MaxTokensCeiling == 0 {
+		defaults.MaxTokensCeiling = fallback.MaxTokensCeiling
+	}
+	return defaults
+}
+
+// PassiveHealthConfig sets when a provider's error rate over the last Window
+// marks it degraded or unhealthy. Fewer than MinRequests calls in the window
+// say nothing about the provider. A zero Window disables passive health.
+type PassiveHealthConfig struct {
+	Window             time.Duration `json:"window"`
+	MinRequests        int           `json:"min_requests"`
+	DegradedErrorRate  float64       `json:"degraded_error_rate"`
+	UnhealthyErrorRate float64       `json:"unhealthy_error_rate"`
+}
+
+// AutoRoutingWeights tune provider "auto" between cheap and fast providers.
+// Only the ratio between the weights matters; a zero weight ignores that
+// signal.
+type AutoRoutingWeights struct {
+	Cost      float64 `json:"cost"`
+	Latency   float64 `json:"latency"`
+	ErrorRate float64 `json:"error_rate"`
+}
+
+// ParamProfile is a named set of sampling parameters. Unset fields leave the
+// request's own value, or the provider default, in place.
+type ParamProfile struct {
+	Temperature      *float64 `json:"temperature,omitempty"`
+	TopP             *float64 `json:"top_p,omitempty"`
+	PresencePenalty  *float64 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
+	MaxTokens        *int     `json:"max_tokens,omitempty"`
+}
+
+// ModelTransform rewrites the requests sent to a family of models, such as
+// OpenAI's reasoning models, which reject sampling parameters and system
+// messages
+type ModelTransform struct {
+	// Drop lists the request parameters the models reject; they are removed
+	// with a warning in the response metadata
+	Drop []string `json:"drop,omitempty"`
+
+	// SystemRole is the role system messages are sent as, when the models
+	// do not take system messages
+	SystemRole domain.MessageRole `json:"system_role,omitempty"`
+}
+
+// ModelTransformParams are the request parameters a ModelTransform can drop
+var ModelTransformParams = []string{"temperature", "top_p", "presence_penalty", "frequency_penalty", "stop"}
+
+// Validate checks the transform's parameters and role
+func (t ModelTransform) Validate() error {
+	if len(t.Drop) == 0 && t.SystemRole == "" {
+		return fmt.Errorf("changes nothing")
+	}
+	for _, param := range t.Drop {
+		known := false
+		for _, candidate := range ModelTransformParams {
+			known = known || candidate == param
+		}
+		if !known {
+			return fmt.Errorf("cannot drop unknown parameter %q", param)
+		}
+	}
+	switch t.SystemRole {
+	case "", domain.MessageRoleDeveloper, domain.MessageRoleUser:
+	default:
+		return fmt.Errorf("system messages cannot be sent as %q", t.SystemRole)
+	}
+	return nil
+}
+
+// ModelTransformFor returns the transform of the longest model ID prefix
+// matching the model, if any
+func (c *Config) ModelTransformFor(model string) (ModelTransform, bool) {
+	model = strings.ToLower(model)
+	var matched string
+	var transform ModelTransform
+	found := false
+	for prefix, candidate := range c.ModelTransforms {
+		if strings.HasPrefix(model, prefix) && (!found || len(prefix) > len(matched)) {
+			matched, transform, found = prefix, candidate, true
+		}
+	}
+	return transform, found
+}
+
+// Validate checks the profile's parameters against the ranges providers accept
+func (p ParamProfile) Validate() error {
+	switch {
+	case p.Temperature == nil && p.TopP == nil && p.PresencePenalty == nil && p.FrequencyPenalty == nil && p.MaxTokens == nil:
+		return fmt.Errorf("sets no parameters")
+	case p.Temperature != nil && (*p.Temperature < 0 || *p.Temperature > 2):
+		return fmt.Errorf("temperature must be between 0 and 2")
+	case p.TopP != nil && (*p.TopP < 0 || *p.TopP > 1):
+		return fmt.Errorf("top_p must be between 0 and 1")
+	case p.PresencePenalty != nil && (*p.PresencePenalty < -2 || *p.PresencePenalty > 2):
+		return fmt.Errorf("presence_penalty must be between -2 and 2")
+	case p.FrequencyPenalty != nil && (*p.FrequencyPenalty < -2 || *p.FrequencyPenalty > 2):
+		return fmt.Errorf("frequency_penalty must be between -2 and 2")
+	case p.MaxTokens != nil && *p.MaxTokens <= 0:
+		return fmt.Errorf("max_tokens must be positive")
+	}
+	return nil
+}
+
+// Validate checks the window and that the error rates are fractions, with
+// the degraded rate no higher than the unhealthy one
+func (p PassiveHealthConfig) Validate() error {
+	switch {
+	case p.Window == 0:
+		return nil
+	case p.Window < time.Second || p.Window > 15*time.Minute:
+		return fmt.Errorf("passive health window must be between 1s and 15m")
+	case p.MinRequests < 1:
+		return fmt.Errorf("passive health min requests must be positive")
+	case p.DegradedErrorRate < 0 || p.DegradedErrorRate > 1 || p.UnhealthyErrorRate < 0 || p.UnhealthyErrorRate > 1:
+		return fmt.Errorf("passive health error rates must be between 0 and 1")
+	case p.DegradedErrorRate > p.UnhealthyErrorRate:
+		return fmt.Errorf("passive health degraded error rate must not exceed the unhealthy error rate")
+	}
+	return nil
+}
+
+// defaultParamProfiles are available unless PARAM_PROFILES redefines them
+const defaultParamProfiles = "creative=temperature:1.0|top_p:0.95,precise=temperature:0|top_p:1"
+
+// reasoningModelTransform adapts requests to OpenAI's reasoning models
+const reasoningModelTransform = "drop:temperature|drop:top_p|drop:presence_penalty|drop:frequency_penalty|system_role:developer"
+
+// defaultModelTransforms apply unless MODEL_TRANSFORMS redefines them
+const defaultModelTransforms = "o1=" + reasoningModelTransform + ",o3=" + reasoningModelTransform + ",o4=" + reasoningModelTransform
+
+// defaultRetryStatusCodes are the provider statuses retried unless
+// RETRY_STATUS_CODES says otherwise, matching errors.DefaultRetryStatusCodes
+const defaultRetryStatusCodes = "408,429,500,502,503,504"
+
+// defaultAutoRoutingWeights favour cost and latency equally and penalize
+// failing providers
+const defaultAutoRoutingWeights = "cost=0.4,latency=0.4,error_rate=0.2"
+
+// defaultEmbeddingDimensions are the native vector sizes of the embedding
+// models the platform serves out of the box
+const defaultEmbeddingDimensions = "text-embedding-ada-002=1536,text-embedding-3-small=1536,text-embedding-3-large=3072"
+
+// DetectEnvironment builds a Config from environment variables
+func DetectEnvironment() *Config {
+	// Values from CONFIG_FILE override the process environment. A bad file
+	// is skipped here; Reread reports the error when reloading.
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		_ = LoadConfigFile(path)
+	}
+
+	cfg := &Config{
+		Environment:              Environment(strings.ToLower(getEnvOrDefault("ENVIRONMENT", string(Development)))),
+		ServiceName:              getEnvOrDefault("SERVICE_NAME", "qlens"),
+		Version:                  getEnvOrDefault("VERSION", "dev"),
+		Port:                     getEnvInt("PORT", 8080),
+		AuthEnabled:              getEnvBool("AUTH_ENABLED", false),
+		IstioEnabled:             getEnvBool("ISTIO_ENABLED", false),
+		CompressionEnabled:       getEnvBool("COMPRESSION_ENABLED", true),
+		MaxDecompressedBodyBytes: int64(getEnvInt("MAX_DECOMPRESSED_BODY_BYTES", 10*1024*1024)),
+		CacheType:                getEnvOrDefault("CACHE_TYPE", "memory"),
+		Logging: LoggingConfig{
+			Level:  getEnvOrDefault("LOG_LEVEL", "info"),
+			Format: getEnvOrDefault("LOG_FORMAT", "json"),
+
+			RequestSampleN: getEnvInt("LOG_REQUEST_SAMPLE_N", 1),
+			MaxFieldBytes:  getEnvInt("LOG_MAX_FIELD_BYTES", 0),
+		},
+		Providers:            loadProviders(),
+		DefaultProviderOrder: parseProviderList(os.Getenv("DEFAULT_PROVIDER_ORDER")),
+	}
+
+	cfg.Logging.Structured = cfg.Logging.Format == "json"
+	cfg.AdminAPIKey = os.Getenv("ADMIN_API_KEY")
+	cfg.RequestSigningSecrets = parsePairs(os.Getenv("REQUEST_SIGNING_SECRETS"))
+	cfg.RequestSigningWindow = getEnvDuration("REQUEST_SIGNING_WINDOW", 5*time.Minute)
+	cfg.ResponseSigningTenants = parseList(os.Getenv("RESPONSE_SIGNING_TENANTS"))
+	cfg.MaxMessages = getEnvInt("MAX_MESSAGES", 1000)
+	cfg.MaxContentBytes = int64(getEnvInt("MAX_CONTENT_BYTES", 2*1024*1024))
+	cfg.TenantMaxToolRounds = parseCounts(os.Getenv("TENANT_MAX_TOOL_ROUNDS"))
+	cfg.ToolRoundIdleTTL = getEnvDuration("TOOL_ROUND_IDLE_TTL", time.Hour)
+	cfg.DebugRawResponses = getEnvBool("DEBUG_RAW_RESPONSES", cfg.Environment.IsDevelopment())
+	cfg.DebugExplainRouting = getEnvBool("DEBUG_EXPLAIN_ROUTING", cfg.Environment.IsDevelopment())
+	cfg.TenantProviders = parseTenantProviders(os.Getenv("TENANT_PROVIDERS"))
+	cfg.DefaultCompletionModel = os.Getenv("DEFAULT_COMPLETION_MODEL")
+	cfg.DefaultEmbeddingModel = os.Getenv("DEFAULT_EMBEDDING_MODEL")
+	cfg.TenantConfigFile = os.Getenv("TENANT_CONFIG_FILE")
+	cfg.ModelDefaults = parseModelDefaults(os.Getenv("MODEL_DEFAULT_MAX_TOKENS"), os.Getenv("MODEL_MAX_TOKENS_CEILING"))
+	cfg.EmbeddingFallbacks = parseFallbacks(os.Getenv("EMBEDDING_FALLBACKS"))
+	cfg.EmbeddingBatchSize = getEnvInt("EMBEDDING_BATCH_SIZE", 2048)
+	cfg.EmbeddingBatchConcurrency = getEnvInt("EMBEDDING_BATCH_CONCURRENCY", 4)
+	cfg.EmbeddingDimensions = parseCounts(getEnvOrDefault("EMBEDDING_DIMENSIONS", defaultEmbeddingDimensions))
+	cfg.AutoRoutingWeights = parseAutoRoutingWeights(getEnvOrDefault("AUTO_ROUTING_WEIGHTS", defaultAutoRoutingWeights))
+	cfg.EmbeddingCostRouting = getEnvBool("EMBEDDING_COST_ROUTING", false)
+	cfg.ParamProfiles, cfg.paramProfilesErr = parseParamProfiles(defaultParamProfiles + "," + os.Getenv("PARAM_PROFILES"))
+	cfg.ModelTransforms, cfg.modelTransformsErr = parseModelTransforms(defaultModelTransforms + "," + os.Getenv("MODEL_TRANSFORMS"))
+	cfg.RetryStatusCodes, cfg.retryStatusCodesErr = parseStatusCodes(getEnvOrDefault("RETRY_STATUS_CODES", defaultRetryStatusCodes))
+	cfg.RetryErrorTypes = parseList(os.Getenv("RETRY_ERROR_TYPES"))
+	cfg.ProviderKeepAliveInterval = getEnvDuration("PROVIDER_KEEPALIVE_INTERVAL", 30*time.Second)
+	cfg.ProviderWarmupTimeout = getEnvDuration("PROVIDER_WARMUP_TIMEOUT", 0)
+	cfg.ProviderWarmupConnections = getEnvInt("PROVIDER_WARMUP_CONNECTIONS", 1)
+	cfg.HealthCheckInterval = getEnvDuration("HEALTH_CHECK_INTERVAL", 5*time.Minute)
+	cfg.HealthCheckTimeout = getEnvDuration("HEALTH_CHECK_TIMEOUT", 10*time.Second)
+	cfg.HealthCheckConcurrency = getEnvInt("HEALTH_CHECK_CONCURRENCY", 4)
+	cfg.KillSwitches, cfg.killSwitchesErr = parseKillSwitches(os.Getenv("KILL_SWITCHES"))
+	cfg.KillSwitchRefresh = getEnvDuration("KILL_SWITCH_REFRESH", 5*time.Second)
+	cfg.PassiveHealth = PassiveHealthConfig{
+		Window:             getEnvDuration("PASSIVE_HEALTH_WINDOW", time.Minute),
+		MinRequests:        getEnvInt("PASSIVE_HEALTH_MIN_REQUESTS", 10),
+		DegradedErrorRate:  getEnvFloat("PASSIVE_HEALTH_DEGRADED_ERROR_RATE", 0.2),
+		UnhealthyErrorRate: getEnvFloat("PASSIVE_HEALTH_UNHEALTHY_ERROR_RATE", 0.5),
+	}
+	cfg.TenantCredentialsDir = os.Getenv("TENANT_CREDENTIALS_DIR")
+	cfg.TenantCredentialsTTL = getEnvDuration("TENANT_CREDENTIALS_TTL", 5*time.Minute)
+	cfg.DeadLetterPath = os.Getenv("DEAD_LETTER_PATH")
+	cfg.ModelRegistryManifest = os.Getenv("MODEL_REGISTRY_MANIFEST")
+	cfg.ModelRegistryLive = getEnvBool("MODEL_REGISTRY_LIVE", false)
+	cfg.StreamUsageSampleRate = getEnvFloat("STREAM_USAGE_SAMPLE_RATE", 0.1)
+	cfg.StreamUsageBillingSource = getEnvOrDefault("STREAM_USAGE_BILLING_SOURCE", StreamUsageSourceProvider)
+	cfg.StreamTokensPerSecond = getEnvFloat("STREAM_TOKENS_PER_SECOND", 0)
+	cfg.TenantStreamTokensPerSecond = parseRates(os.Getenv("STREAM_TENANT_TOKENS_PER_SECOND"))
+	cfg.StreamCollectTenants = parseList(os.Getenv("STREAM_COLLECT_TENANTS"))
+	cfg.StreamFallback = getEnvBool("STREAM_FALLBACK", false)
+	cfg.StreamPassthrough = getEnvBool("STREAM_PASSTHROUGH", false)
+	cfg.StreamFailoverGrace = getEnvDuration("STREAM_FAILOVER_GRACE", 0)
+	cfg.ResponseSizeAlertFactor = getEnvFloat("RESPONSE_SIZE_ALERT_FACTOR", 4)
+	cfg.AlertManagerURL = os.Getenv("ALERTMANAGER_URL")
+	cfg.CreatedMaxSkew = getEnvDuration("CREATED_MAX_SKEW", 5*time.Minute)
+	cfg.PrefillEmulation = getEnvBool("PREFILL_EMULATION", false)
+	cfg.StripJSONFences = getEnvBool("STRIP_JSON_FENCES", false)
+	cfg.ToolResultSanitization = getEnvOrDefault("TOOL_RESULT_SANITIZATION", ToolSanitizationOff)
+	cfg.TenantToolResultSanitization = parsePairs(os.Getenv("TENANT_TOOL_RESULT_SANITIZATION"))
+	cfg.ContextUtilizationWarning = getEnvFloat("CONTEXT_UTILIZATION_WARNING", 0)
+	cfg.ModelDeprecationAction = getEnvOrDefault("MODEL_DEPRECATION_ACTION", ModelDeprecationWarn)
+	cfg.ModelSuccessors = parsePairs(os.Getenv("MODEL_SUCCESSORS"))
+	cfg.ModelAliases = parsePairs(os.Getenv("MODEL_ALIASES"))
+	cfg.ModelPricing, cfg.modelPricingErr = parseModelPricing(os.Getenv("MODEL_PRICING"))
+	cfg.MaxCostUSD = getEnvFloat("MAX_COST_USD", 0)
+	cfg.RateLimitRequestsPerMinute = getEnvInt("RATE_LIMIT_REQUESTS_PER_MINUTE", 0)
+	cfg.RateLimitTokensPerMinute = getEnvInt("RATE_LIMIT_TOKENS_PER_MINUTE", 0)
+	cfg.RateLimitProviderRequestsPerMinute = getEnvInt("RATE_LIMIT_PROVIDER_REQUESTS_PER_MINUTE", 0)
+	cfg.RateLimitProviderTokensPerMinute = getEnvInt("RATE_LIMIT_PROVIDER_TOKENS_PER_MINUTE", 0)
+	cfg.FAQ = FAQConfig{
+		EmbeddingModel: getEnvOrDefault("FAQ_EMBEDDING_MODEL", "text-embedding-3-small"),
+		MatchThreshold: getEnvFloat("FAQ_MATCH_THRESHOLD", 0.92),
+		StorePath:      os.Getenv("FAQ_STORE_PATH"),
+	}
+	cfg.Batch = BatchConfig{
+		MaxItems:       getEnvInt("BATCH_MAX_ITEMS", 5000),
+		MaxConcurrency: getEnvInt("BATCH_MAX_CONCURRENCY", 4),
+		MaxRetries:     getEnvInt("BATCH_MAX_RETRIES", 3),
+		Retention:      getEnvDuration("BATCH_RETENTION", 24*time.Hour),
+	}
+	cfg.Cache = CacheConfig{
+		Type:    cfg.CacheType,
+		TTL:     getEnvDuration("CACHE_TTL", time.Hour),
+		MaxSize: getEnvInt("CACHE_MAX_SIZE", 10000),
+
+		StaleGrace: getEnvDuration("CACHE_STALE_GRACE", 0),
+	}
+
+	return cfg
+}
+
+// Validate reports configuration that was set but cannot be used, such as a
+// malformed or out-of-range parameter profile
+func (c *Config) Validate() error {
+	if c.paramProfilesErr != nil {
+		return c.paramProfilesErr
+	}
+	if c.modelTransformsErr != nil {
+		return c.modelTransformsErr
+	}
+	for prefix, transform := range c.ModelTransforms {
+		if err := transform.Validate(); err != nil {
+			return fmt.Errorf("model transform %q: %w", prefix, err)
+		}
+	}
+	for name, profile := range c.ParamProfiles {
+		if err := profile.Validate(); err != nil {
+			return fmt.Errorf("param profile %q: %w", name, err)
+		}
+	}
+	if c.retryStatusCodesErr != nil {
+		return c.retryStatusCodesErr
+	}
+	for _, code := range c.RetryStatusCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("retry status code %d is not an HTTP status", code)
+		}
+	}
+	for _, tenant := range c.ResponseSigningTenants {
+		if c.RequestSigningSecrets[tenant] == "" {
+			return fmt.Errorf("response signing tenant %q has no request signing secret", tenant)
+		}
+	}
+	if c.StreamUsageSampleRate < 0 || c.StreamUsageSampleRate > 1 {
+		return fmt.Errorf("stream usage sample rate must be between 0 and 1")
+	}
+	if c.ContextUtilizationWarning < 0 || c.ContextUtilizationWarning > 1 {
+		return fmt.Errorf("context utilization warning must be between 0 and 1")
+	}
+	switch c.ModelDeprecationAction {
+	case "", ModelDeprecationWarn, ModelDeprecationMigrate:
+	default:
+		return fmt.Errorf("model deprecation action must be %q or %q", ModelDeprecationWarn, ModelDeprecationMigrate)
+	}
+	for model, successor := range c.ModelSuccessors {
+		if successor == model {
+			return fmt.Errorf("model %q cannot be its own successor", model)
+		}
+	}
+	for alias, model := range c.ModelAliases {
+		if model == "" || model == alias {
+			return fmt.Errorf("model alias %q must name another model", alias)
+		}
+	}
+	if c.modelPricingErr != nil {
+		return c.modelPricingErr
+	}
+	if c.MaxCostUSD < 0 {
+		return fmt.Errorf("max cost must not be negative")
+	}
+	if err := c.PassiveHealth.Validate(); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(c.Providers))
+	for name := range c.Providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := c.Providers[name].Validate(domain.Provider(name)); err != nil {
+			return fmt.Errorf("provider %s: %w", name, err)
+		}
+	}
+	if c.ResponseSizeAlertFactor != 0 && c.ResponseSizeAlertFactor <= 1 {
+		return fmt.Errorf("response size alert factor must be greater than 1, or 0 to disable")
+	}
+	if c.FAQ.MatchThreshold < 0 || c.FAQ.MatchThreshold > 1 {
+		return fmt.Errorf("faq match threshold must be between 0 and 1")
+	}
+	if c.StreamFailoverGrace < 0 {
+		return fmt.Errorf("stream failover grace must not be negative")
+	}
+	if c.CreatedMaxSkew < 0 {
+		return fmt.Errorf("created max skew must not be negative")
+	}
+	if c.ToolRoundIdleTTL < 0 {
+		return fmt.Errorf("tool round idle ttl must not be negative")
+	}
+	if c.HealthCheckTimeout < 0 {
+		return fmt.Errorf("health check timeout must not be negative")
+	}
+	if c.HealthCheckConcurrency < 0 {
+		return fmt.Errorf("health check concurrency must not be negative")
+	}
+	if c.killSwitchesErr != nil {
+		return c.killSwitchesErr
+	}
+	if c.KillSwitchRefresh < 0 {
+		return fmt.Errorf("kill switch refresh must not be negative")
+	}
+	if c.ProviderWarmupTimeout < 0 {
+		return fmt.Errorf("provider warmup timeout must not be negative")
+	}
+	if c.ProviderWarmupTimeout > 0 && c.ProviderWarmupConnections < 1 {
+		return fmt.Errorf("provider warmup connections must be at least 1")
+	}
+	if c.Cache.StaleGrace < 0 {
+		return fmt.Errorf("cache stale grace must not be negative")
+	}
+	if c.Logging.RequestSampleN < 0 {
+		return fmt.Errorf("log request sample rate must not be negative")
+	}
+	if c.Logging.MaxFieldBytes < 0 {
+		return fmt.Errorf("log max field bytes must not be negative")
+	}
+	if err := validateToolSanitization(c.ToolResultSanitization); err != nil {
+		return err
+	}
+	for tenant, mode := range c.TenantToolResultSanitization {
+		if err := validateToolSanitization(mode); err != nil {
+			return fmt.Errorf("tenant %s: %w", tenant, err)
+		}
+	}
+	switch c.StreamUsageBillingSource {
+	case "", StreamUsageSourceProvider, StreamUsageSourceEstimate:
+	default:
+		return fmt.Errorf("stream usage billing source must be %q or %q", StreamUsageSourceProvider, StreamUsageSourceEstimate)
+	}
+	return nil
+}
+
+// ProviderAllowed reports whether the tenant is entitled to the provider
+func (c *Config) ProviderAllowed(tenantID string, provider domain.Provider) bool {
+	providers, restricted := c.TenantProviders[tenantID]
+	if !restricted {
+		return true
+	}
+	for _, allowed := range providers {
+		if allowed == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// ToolSanitizationFor returns the tool result sanitization mode for the
+// tenant
+func (c *Config) ToolSanitizationFor(tenantID string) string {
+	if mode, ok := c.TenantToolResultSanitization[tenantID]; ok {
+		return mode
+	}
+	if c.ToolResultSanitization == "" {
+		return ToolSanitizationOff
+	}
+	return c.ToolResultSanitization
+}
+
+func validateToolSanitization(mode string) error {
+	switch mode {
+	case "", ToolSanitizationOff, ToolSanitizationStrip, ToolSanitizationWrap:
+		return nil
+	}
+	return fmt.Errorf("tool result sanitization must be %q, %q or %q", ToolSanitizationOff, ToolSanitizationStrip, ToolSanitizationWrap)
+}
+
+// GetString returns the value of an environment variable or a default
+func (c *Config) GetString(key, defaultValue string) string {
+	return getEnvOrDefault(key, defaultValue)
+}
+
+func loadProviders() map[string]ProviderConfig {
+	providers := make(map[string]ProviderConfig)
+
+	providers[string(domain.ProviderAzureOpenAI)] = ProviderConfig{
+		Enabled:       getEnvBool("AZURE_OPENAI_ENABLED", os.Getenv("AZURE_OPENAI_API_KEY") != ""),
+		APIKey:        os.Getenv("AZURE_OPENAI_API_KEY"),
+		BaseURL:       os.Getenv("AZURE_OPENAI_ENDPOINT"),
+		Timeout:       getEnvDuration("AZURE_OPENAI_TIMEOUT", 30*time.Second),
+		MaxRetries:    getEnvInt("AZURE_OPENAI_MAX_RETRIES", 3),
+		UserAgent:     os.Getenv("AZURE_OPENAI_USER_AGENT"),
+		CustomHeaders: parsePairs(os.Getenv("AZURE_OPENAI_CUSTOM_HEADERS")),
+		DataRegion:    parseRegion(os.Getenv("AZURE_OPENAI_DATA_REGION")),
+		Config: map[string]interface{}{
+			"api_version":          getEnvOrDefault("AZURE_OPENAI_API_VERSION", "2024-02-15-preview"),
+			"allowed_api_versions": parseList(os.Getenv("AZURE_OPENAI_ALLOWED_API_VERSIONS")),
+			"health_probe":         getEnvOrDefault("AZURE_OPENAI_HEALTH_PROBE", "list"),
+			"deployment_failover":  parseFallbacks(os.Getenv("AZURE_OPENAI_DEPLOYMENT_FAILOVER")),
+		},
+	}
+
+	providers[string(domain.ProviderAWSBedrock)] = ProviderConfig{
+		Enabled:       getEnvBool("AWS_BEDROCK_ENABLED", os.Getenv("AWS_ACCESS_KEY_ID") != ""),
+		APIKey:        os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:     os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		Timeout:       getEnvDuration("AWS_BEDROCK_TIMEOUT", 60*time.Second),
+		MaxRetries:    getEnvInt("AWS_BEDROCK_MAX_RETRIES", 3),
+		UserAgent:     os.Getenv("AWS_BEDROCK_USER_AGENT"),
+		CustomHeaders: parsePairs(os.Getenv("AWS_BEDROCK_CUSTOM_HEADERS")),
+		DataRegion:    parseRegion(os.Getenv("AWS_BEDROCK_DATA_REGION")),
+		Config: map[string]interface{}{
+			"region":                     getEnvOrDefault("AWS_REGION", "us-east-1"),
+			"allowed_anthropic_versions": parseList(os.Getenv("AWS_BEDROCK_ALLOWED_ANTHROPIC_VERSIONS")),
+			"provisioned_throughput":     parsePairs(os.Getenv("AWS_BEDROCK_PROVISIONED_THROUGHPUT")),
+			"health_probe":               getEnvOrDefault("AWS_BEDROCK_HEALTH_PROBE", "list"),
+		},
+	}
+
+	if apiKey := os.Getenv("OPENAI_API_KEY"); apiKey != "" {
+		providers[string(domain.ProviderOpenAI)] = ProviderConfig{
+			Enabled:       getEnvBool("OPENAI_ENABLED", true),
+			APIKey:        apiKey,
+			BaseURL:       os.Getenv("OPENAI_BASE_URL"),
+			Timeout:       getEnvDuration("OPENAI_TIMEOUT", 30*time.Second),
+			MaxRetries:    getEnvInt("OPENAI_MAX_RETRIES", 3),
+			UserAgent:     os.Getenv("OPENAI_USER_AGENT"),
+			CustomHeaders: parsePairs(os.Getenv("OPENAI_CUSTOM_HEADERS")),
+			DataRegion:    parseRegion(os.Getenv("OPENAI_DATA_REGION")),
+		}
+	}
+
+	if apiKey := os.Getenv("ANTHROPIC_API_KEY"); apiKey != "" {
+		providers[string(domain.ProviderAnthropic)] = ProviderConfig{
+			Enabled:       getEnvBool("ANTHROPIC_ENABLED", true),
+			APIKey:        apiKey,
+			BaseURL:       os.Getenv("ANTHROPIC_BASE_URL"),
+			Timeout:       getEnvDuration("ANTHROPIC_TIMEOUT", 60*time.Second),
+			MaxRetries:    getEnvInt("ANTHROPIC_MAX_RETRIES", 3),
+			UserAgent:     os.Getenv("ANTHROPIC_USER_AGENT"),
+			CustomHeaders: parsePairs(os.Getenv("ANTHROPIC_CUSTOM_HEADERS")),
+			DataRegion:    parseRegion(os.Getenv("ANTHROPIC_DATA_REGION")),
+		}
+	}
+
+	return providers
+}
+
+// parseProviderList parses a comma-separated provider list, dropping blanks
+// and duplicates while keeping the original order
+func parseProviderList(value string) []domain.Provider {
+	if value == "" {
+		return nil
+	}
+
+	seen := make(map[domain.Provider]bool)
+	var providers []domain.Provider
+	for _, part := range strings.Split(value, ",") {
+		provider := domain.Provider(strings.ToLower(strings.TrimSpace(part)))
+		if provider == "" || seen[provider] {
+			continue
+		}
+		seen[provider] = true
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
+
+// parseRegion normalizes a data-residency region name
+func parseRegion(value string) string {
+	return strings.ToLower(strings.TrimSpace(value))
+}
+
+// parseList parses a comma-separated list, dropping blank entries
+func parseList(value string) []string {
+	var items []string
+	for _, part := range strings.Split(value, ",") {
+		if item := strings.TrimSpace(part); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parsePairs parses comma-separated key=value pairs such as
+// "X-Org-ID=acme,X-Proxy-Route=eu"
+func parsePairs(value string) map[string]string {
+	pairs := make(map[string]string)
+	for _, item := range parseList(value) {
+		key, val, ok := strings.Cut(item, "=")
+		if key = strings.TrimSpace(key); !ok || key == "" {
+			continue
+		}
+		pairs[key] = strings.TrimSpace(val)
+	}
+	return pairs
+}
+
+// parseRates parses comma-separated key=rate pairs, dropping entries that
+// are malformed or not positive
+func parseRates(value string) map[string]float64 {
+	rates := make(map[string]float64)
+	for _, item := range parseList(value) {
+		key, rate, ok := strings.Cut(item, "=")
+		if !ok {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(rate), 64)
+		if err != nil || parsed <= 0 {
+			continue
+		}
+		rates[strings.TrimSpace(key)] = parsed
+	}
+	return rates
+}
+
+// parseFallbacks parses comma-separated model=fallback|fallback entries such
+// as "text-embedding-3-small=text-embedding-ada-002"
+func parseFallbacks(value string) map[string][]string {
+	fallbacks := make(map[string][]string)
+	for key, val := range parsePairs(value) {
+		if alternatives := parseAlternatives(val); len(alternatives) > 0 {
+			fallbacks[key] = alternatives
+		}
+	}
+	return fallbacks
+}
+
+// parseAlternatives splits a |-separated list such as "gpt-4o|gpt-4o-eu",
+// dropping empty entries
+func parseAlternatives(value string) []string {
+	var alternatives []string
+	for _, alternative := range strings.Split(value, "|") {
+		if alternative = strings.TrimSpace(alternative); alternative != "" {
+			alternatives = append(alternatives, alternative)
+		}
+	}
+	return alternatives
+}
+
+// parseTenantProviders parses comma-separated tenant=provider|provider
+// entries such as "acme=azure-openai|aws-bedrock"
+func parseTenantProviders(value string) map[string][]domain.Provider {
+	entitlements := make(map[string][]domain.Provider)
+	for tenant, providers := range parseFallbacks(value) {
+		entitlements[tenant] = parseProviderList(strings.Join(providers, ","))
+	}
+	return entitlements
+}
+
+// parseModelDefaults combines per-model default max_tokens and ceilings,
+// each given as model=count pairs
+func parseModelDefaults(maxTokens, ceilings string) map[string]ModelDefaults {
+	defaults := make(map[string]ModelDefaults)
+	for model, count := range parseCounts(maxTokens) {
+		entry := defaults[model]
+		entry.MaxTokens = count
+		defaults[model] = entry
+	}
+	for model, count := range parseCounts(ceilings) {
+		entry := defaults[model]
+		entry.MaxTokensCeiling = count
+		defaults[model] = entry
+	}
+	return defaults
+}
+
+// parseCounts parses comma-separated key=count pairs, dropping entries that
+// are malformed or not positive
+func parseCounts(value string) map[string]int {
+	counts := make(map[string]int)
+	for key, val := range parsePairs(value) {
+		parsed, err := strconv.Atoi(val)
+		if err != nil || parsed <= 0 {
+			continue
+		}
+		counts[key] = parsed
+	}
+	return counts
+}
+
+// parseStatusCodes parses a comma-separated list of HTTP status codes
+func parseStatusCodes(value string) ([]int, error) {
+	var codes []int
+	for _, item := range parseList(value) {
+		code, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("retry status code %q is not a number", item)
+		}
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// parseAutoRoutingWeights parses "cost=0.4,latency=0.4,error_rate=0.2".
+// Weights that are missing, malformed or negative count as zero.
+func parseAutoRoutingWeights(value string) AutoRoutingWeights {
+	var weights AutoRoutingWeights
+	for key, val := range parsePairs(value) {
+		parsed, err := strconv.ParseFloat(val, 64)
+		if err != nil || parsed < 0 {
+			continue
+		}
+		switch key {
+		case "cost":
+			weights.Cost = parsed
+		case "latency":
+			weights.Latency = parsed
+		case "error_rate":
+			weights.ErrorRate = parsed
+		}
+	}
+	return weights
+}
+
+// parseParamProfiles parses comma-separated name=param:value|param:value
+// entries such as "creative=temperature:1.0|top_p:0.95". A later entry
+// replaces an earlier one of the same name. Unlike the other parsers it
+// rejects malformed entries, since silently dropping a parameter would
+// change the output of every request using the profile.
+func parseParamProfiles(value string) (map[string]ParamProfile, error) {
+	profiles := make(map[string]ParamProfile)
+	for _, item := range parseList(value) {
+		name, params, ok := strings.Cut(item, "=")
+		if name = strings.TrimSpace(name); !ok || name == "" {
+			return nil, fmt.Errorf("param profile %q: expected name=param:value", item)
+		}
+
+		var profile ParamProfile
+		for _, param := range strings.Split(params, "|") {
+			key, val, ok := strings.Cut(param, ":")
+			if !ok {
+				return nil, fmt.Errorf("param profile %q: expected param:value, got %q", name, param)
+			}
+			key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+
+			if key == "max_tokens" {
+				parsed, err := strconv.Atoi(val)
+				if err != nil {
+					return nil, fmt.Errorf("param profile %q: invalid max_tokens %q", name, val)
+				}
+				profile.MaxTokens = &parsed
+				continue
+			}
+
+			parsed, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				return nil, fmt.Errorf("param profile %q: invalid %s %q", name, key, val)
+			}
+			switch key {
+			case "temperature":
+				profile.Temperature = &parsed
+			case "top_p":
+				profile.TopP = &parsed
+			case "presence_penalty":
+				profile.PresencePenalty = &parsed
+			case "frequency_penalty":
+				profile.FrequencyPenalty = &parsed
+			default:
+				return nil, fmt.Errorf("param profile %q: unknown parameter %q", name, key)
+			}
+		}
+
+		if err := profile.Validate(); err != nil {
+			return nil, fmt.Errorf("param profile %q: %w", name, err)
+		}
+		profiles[name] = profile
+	}
+	return profiles, nil
+}
+
+// parseModelTransforms parses comma-separated prefix=rule|rule transforms,
+// where a rule is drop:<parameter> or system_role:<role>, such as
+// "o1=drop:temperature|drop:top_p|system_role:developer". A later entry for
+// the same prefix replaces an earlier one.
+func parseModelTransforms(value string) (map[string]ModelTransform, error) {
+	transforms := make(map[string]ModelTransform)
+	for _, item := range parseList(value) {
+		prefix, rules, ok := strings.Cut(item, "=")
+		if prefix = strings.ToLower(strings.TrimSpace(prefix)); !ok || prefix == "" {
+			return nil, fmt.Errorf("model transform %q: expected prefix=rule|rule", item)
+		}
+
+		var transform ModelTransform
+		for _, rule := range strings.Split(rules, "|") {
+			key, val, ok := strings.Cut(rule, ":")
+			if !ok {
+				return nil, fmt.Errorf("model transform %q: expected rule:value, got %q", prefix, rule)
+			}
+			key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+			switch key {
+			case "drop":
+				transform.Drop = append(transform.Drop, val)
+			case "system_role":
+				transform.SystemRole = domain.MessageRole(val)
+			default:
+				return nil, fmt.Errorf("model transform %q: unknown rule %q", prefix, key)
+			}
+		}
+
+		if err := transform.Validate(); err != nil {
+			return nil, fmt.Errorf("model transform %q: %w", prefix, err)
+		}
+		transforms[prefix] = transform
+	}
+	return transforms, nil
+}
+
+// parseModelPricing parses comma-separated model=input:output[:reasoning]
+// prices per 1K tokens, such as "gpt-4o=0.0025:0.01", into per-token
+// pricing
+func parseModelPricing(value string) (map[string]domain.ModelPricing, error) {
+	pricing := make(map[string]domain.ModelPricing)
+	for _, item := range parseList(value) {
+		model, prices, ok := strings.Cut(item, "=")
+		if model = strings.TrimSpace(model); !ok || model == "" {
+			return nil, fmt.Errorf("model pricing %q: expected model=input:output", item)
+		}
+
+		parts := strings.Split(prices, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("model pricing %q: expected input:output or input:output:reasoning", model)
+		}
+		costs := make([]float64, len(parts))
+		for i, part := range parts {
+			cost, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil || cost < 0 {
+				return nil, fmt.Errorf("model pricing %q: %q is not a price", model, part)
+			}
+			costs[i] = cost / 1000
+		}
+
+		modelPricing := domain.ModelPricing{InputTokenCost: costs[0], OutputTokenCost: costs[1], Unit: "token"}
+		if len(costs) == 3 {
+			modelPricing.ReasoningTokenCost = costs[2]
+		}
+		pricing[model] = modelPricing
+	}
+	return pricing, nil
+}
+
+// parseKillSwitches parses comma-separated kill switches: a provider,
+// provider/model, or */model for a model at every provider
+func parseKillSwitches(value string) ([]domain.KillSwitch, error) {
+	var switches []domain.KillSwitch
+	for _, item := range parseList(value) {
+		provider, model, _ := strings.Cut(item, "/")
+		provider, model = strings.TrimSpace(provider), strings.TrimSpace(model)
+		if provider == "*" {
+			provider = ""
+		}
+		if provider == "" && model == "" {
+			return nil, fmt.Errorf("kill switch %q: expected provider, provider/model or */model", item)
+		}
+		switches = append(switches, domain.KillSwitch{Provider: domain.Provider(provider), Model: model})
+	}
+	return switches, nil
+}
+
+func getEnvOrDefault(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvBool(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,282 @@
+package env
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+// AzureOpenAISettings are the typed Azure OpenAI entries of a provider's
+// Config map
+type AzureOpenAISettings struct {
+	APIVersion         string   `json:"api_version"`
+	AllowedAPIVersions []string `json:"allowed_api_versions,omitempty"`
+	HealthProbe        string   `json:"health_probe,omitempty"`
+
+	// DeploymentFailover lists, per model, the deployments a request may
+	// move between when one runs out of capacity
+	DeploymentFailover map[string][]string `json:"deployment_failover,omitempty"`
+}
+
+// AWSBedrockSettings are the typed AWS Bedrock entries of a provider's
+// Config map
+type AWSBedrockSettings struct {
+	Region                   string            `json:"region"`
+	AllowedAnthropicVersions []string          `json:"allowed_anthropic_versions,omitempty"`
+	ProvisionedThroughput    map[string]string `json:"provisioned_throughput,omitempty"`
+	HealthProbe              string            `json:"health_probe,omitempty"`
+}
+
+// providerSettingKeys are the Config keys each provider understands. Any
+// other key is almost certainly a typo that would otherwise be ignored.
+var providerSettingKeys = map[domain.Provider][]string{
+	domain.ProviderAzureOpenAI: {"api_version", "allowed_api_versions", "health_probe", "deployment_failover"},
+	domain.ProviderAWSBedrock:  {"region", "allowed_anthropic_versions", "provisioned_throughput", "health_probe"},
+}
+
+// providerHealthProbes are the health probes each provider supports
+var providerHealthProbes = map[domain.Provider][]string{
+	domain.ProviderAzureOpenAI: {"list", "completion"},
+	domain.ProviderAWSBedrock:  {"list", "count_tokens", "completion"},
+}
+
+// AzureOpenAISettings decodes the provider's Config map, naming the key in
+// any error
+func (p ProviderConfig) AzureOpenAISettings() (AzureOpenAISettings, error) {
+	var settings AzureOpenAISettings
+	var err error
+	if settings.APIVersion, err = settingString(p.Config, "api_version"); err != nil {
+		return settings, err
+	}
+	if settings.AllowedAPIVersions, err = settingList(p.Config, "allowed_api_versions"); err != nil {
+		return settings, err
+	}
+	if settings.HealthProbe, err = settingString(p.Config, "health_probe"); err != nil {
+		return settings, err
+	}
+	if settings.DeploymentFailover, err = settingLists(p.Config, "deployment_failover"); err != nil {
+		return settings, err
+	}
+	return settings, nil
+}
+
+// AWSBedrockSettings decodes the provider's Config map, naming the key in
+// any error
+func (p ProviderConfig) AWSBedrockSettings() (AWSBedrockSettings, error) {
+	var settings AWSBedrockSettings
+	var err error
+	if settings.Region, err = settingString(p.Config, "region"); err != nil {
+		return settings, err
+	}
+	if settings.AllowedAnthropicVersions, err = settingList(p.Config, "allowed_anthropic_versions"); err != nil {
+		return settings, err
+	}
+	if settings.ProvisionedThroughput, err = settingPairs(p.Config, "provisioned_throughput"); err != nil {
+		return settings, err
+	}
+	if settings.HealthProbe, err = settingString(p.Config, "health_probe"); err != nil {
+		return settings, err
+	}
+	return settings, nil
+}
+
+// Validate checks an enabled provider has what its client needs, so a
+// misconfigured provider fails at startup rather than on its first request.
+// Disabled providers are not checked.
+func (p ProviderConfig) Validate(provider domain.Provider) error {
+	if !p.Enabled {
+		return nil
+	}
+	switch {
+	case p.Timeout < 0:
+		return fmt.Errorf("timeout must not be negative")
+	case p.MaxRetries < 0:
+		return fmt.Errorf("max_retries must not be negative")
+	}
+	if p.BaseURL != "" {
+		if parsed, err := url.Parse(p.BaseURL); err != nil || parsed.Host == "" || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return fmt.Errorf("base_url must be an http or https URL")
+		}
+	}
+	if err := p.validateSettingKeys(provider); err != nil {
+		return err
+	}
+
+	switch provider {
+	case domain.ProviderAzureOpenAI:
+		settings, err := p.AzureOpenAISettings()
+		if err != nil {
+			return err
+		}
+		switch {
+		case p.APIKey == "":
+			return fmt.Errorf("api_key is required (AZURE_OPENAI_API_KEY)")
+		case p.BaseURL == "":
+			return fmt.Errorf("base_url is required (AZURE_OPENAI_ENDPOINT)")
+		}
+		return validateHealthProbe(provider, settings.HealthProbe)
+
+	case domain.ProviderAWSBedrock:
+		settings, err := p.AWSBedrockSettings()
+		if err != nil {
+			return err
+		}
+		// Without static keys the client uses the default credential chain
+		switch {
+		case (p.APIKey == "") != (p.SecretKey == ""):
+			return fmt.Errorf("api_key and secret_key must be set together (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY)")
+		case settings.Region == "":
+			return fmt.Errorf("config.region is required (AWS_REGION)")
+		}
+		return validateHealthProbe(provider, settings.HealthProbe)
+
+	case domain.ProviderOpenAI, domain.ProviderAnthropic:
+		if p.APIKey == "" {
+			return fmt.Errorf("api_key is required")
+		}
+	}
+	return nil
+}
+
+// validateSettingKeys rejects Config keys the provider does not understand
+func (p ProviderConfig) validateSettingKeys(provider domain.Provider) error {
+	known := providerSettingKeys[provider]
+	var unknown []string
+	for key := range p.Config {
+		if !containsString(known, key) {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	if len(known) == 0 {
+		return fmt.Errorf("unknown config keys %s; this provider takes none", strings.Join(unknown, ", "))
+	}
+	return fmt.Errorf("unknown config keys %s; expected %s", strings.Join(unknown, ", "), strings.Join(known, ", "))
+}
+
+func validateHealthProbe(provider domain.Provider, probe string) error {
+	supported := providerHealthProbes[provider]
+	if probe == "" || containsString(supported, probe) {
+		return nil
+	}
+	return fmt.Errorf("config.health_probe %q is not supported; expected %s", probe, strings.Join(supported, ", "))
+}
+
+// settingString returns a string Config entry, or "" when it is missing
+func settingString(config map[string]interface{}, key string) (string, error) {
+	value, ok := config[key]
+	if !ok || value == nil {
+		return "", nil
+	}
+	s, ok := value.(string)
+	if !ok {
+		return "", fmt.Errorf("config.%s must be a string, got %T", key, value)
+	}
+	return s, nil
+}
+
+// settingList returns a list Config entry. Lists decoded from JSON and
+// comma-separated strings are accepted too.
+func settingList(config map[string]interface{}, key string) ([]string, error) {
+	switch value := config[key].(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return value, nil
+	case string:
+		return parseList(value), nil
+	case []interface{}:
+		list := make([]string, 0, len(value))
+		for _, item := range value {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("config.%s must be a list of strings, got an item of type %T", key, item)
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("config.%s must be a list of strings, got %T", key, value)
+	}
+}
+
+// settingPairs returns a map Config entry. Maps decoded from JSON and
+// key=value strings are accepted too.
+func settingPairs(config map[string]interface{}, key string) (map[string]string, error) {
+	switch value := config[key].(type) {
+	case nil:
+		return nil, nil
+	case map[string]string:
+		return value, nil
+	case string:
+		return parsePairs(value), nil
+	case map[string]interface{}:
+		pairs := make(map[string]string, len(value))
+		for name, item := range value {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("config.%s.%s must be a string, got %T", key, name, item)
+			}
+			pairs[name] = s
+		}
+		return pairs, nil
+	default:
+		return nil, fmt.Errorf("config.%s must be a map of strings, got %T", key, value)
+	}
+}
+
+// settingLists returns a map of lists Config entry. Maps decoded from JSON,
+// whose lists may also be |-separated strings, and key=a|b,... strings are
+// accepted too.
+func settingLists(config map[string]interface{}, key string) (map[string][]string, error) {
+	switch value := config[key].(type) {
+	case nil:
+		return nil, nil
+	case map[string][]string:
+		return value, nil
+	case string:
+		return parseFallbacks(value), nil
+	case map[string]string:
+		lists := make(map[string][]string, len(value))
+		for name, item := range value {
+			lists[name] = parseAlternatives(item)
+		}
+		return lists, nil
+	case map[string]interface{}:
+		lists := make(map[string][]string, len(value))
+		for name, item := range value {
+			switch item := item.(type) {
+			case string:
+				lists[name] = parseAlternatives(item)
+			case []interface{}:
+				for _, entry := range item {
+					s, ok := entry.(string)
+					if !ok {
+						return nil, fmt.Errorf("config.%s.%s must be a list of strings, got an item of type %T", key, name, entry)
+					}
+					lists[name] = append(lists[name], s)
+				}
+			default:
+				return nil, fmt.Errorf("config.%s.%s must be a list of strings, got %T", key, name, item)
+			}
+		}
+		return lists, nil
+	default:
+		return nil, fmt.Errorf("config.%s must be a map of lists of strings, got %T", key, value)
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package env
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+func TestProviderConfig_AWSBedrockSettings(t *testing.T) {
+	// Values decoded from JSON map onto the same typed settings
+	config := ProviderConfig{Config: map[string]interface{}{
+		"region":                     "eu-west-1",
+		"allowed_anthropic_versions": []interface{}{"bedrock-2023-05-31"},
+		"provisioned_throughput":     map[string]interface{}{"claude-3-haiku": "arn:aws:bedrock:pt/1"},
+		"health_probe":               "count_tokens",
+	}}
+
+	settings, err := config.AWSBedrockSettings()
+	require.NoError(t, err)
+	assert.Equal(t, AWSBedrockSettings{
+		Region:                   "eu-west-1",
+		AllowedAnthropicVersions: []string{"bedrock-2023-05-31"},
+		ProvisionedThroughput:    map[string]string{"claude-3-haiku": "arn:aws:bedrock:pt/1"},
+		HealthProbe:              "count_tokens",
+	}, settings)
+
+	config.Config["region"] = 42
+	_, err = config.AWSBedrockSettings()
+	assert.EqualError(t, err, "config.region must be a string, got int")
+}
+
+func TestProviderConfig_AzureDeploymentFailover(t *testing.T) {
+	// JSON lists, |-separated strings and the environment's parsed form all
+	// decode to the same lists
+	config := ProviderConfig{Config: map[string]interface{}{
+		"deployment_failover": map[string]interface{}{
+			"gpt-4o":      []interface{}{"gpt-4o", "gpt-4o-eu"},
+			"gpt-4o-mini": "gpt-4o-mini|gpt-4o-mini-eu",
+		},
+	}}
+	settings, err := config.AzureOpenAISettings()
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"gpt-4o":      {"gpt-4o", "gpt-4o-eu"},
+		"gpt-4o-mini": {"gpt-4o-mini", "gpt-4o-mini-eu"},
+	}, settings.DeploymentFailover)
+
+	config.Config["deployment_failover"] = parseFallbacks("gpt-4o=gpt-4o|gpt-4o-eu")
+	settings, err = config.AzureOpenAISettings()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"gpt-4o", "gpt-4o-eu"}, settings.DeploymentFailover["gpt-4o"])
+
+	config.Config["deployment_failover"] = map[string]interface{}{"gpt-4o": 3}
+	_, err = config.AzureOpenAISettings()
+	assert.EqualError(t, err, "config.deployment_failover.gpt-4o must be a list of strings, got int")
+}
+
+func TestProviderConfig_Validate(t *testing.T) {
+	azure := func() ProviderConfig {
+		return ProviderConfig{
+			Enabled: true,
+			APIKey:  "key",
+			BaseURL: "https://example.openai.azure.com",
+			Config:  map[string]interface{}{"api_version": "2024-02-15-preview", "health_probe": "list"},
+		}
+	}
+	require.NoError(t, azure().Validate(domain.ProviderAzureOpenAI))
+
+	tests := []struct {
+		name   string
+		modify func(*ProviderConfig)
+		err    string
+	}{
+		{"missing key", func(p *ProviderConfig) { p.APIKey = "" }, "api_key is required (AZURE_OPENAI_API_KEY)"},
+		{"missing endpoint", func(p *ProviderConfig) { p.BaseURL = "" }, "base_url is required (AZURE_OPENAI_ENDPOINT)"},
+		{"bad endpoint", func(p *ProviderConfig) { p.BaseURL = "example.openai.azure.com" }, "base_url must be an http or https URL"},
+		{"misspelled key", func(p *ProviderConfig) { p.Config["apiversion"] = "x" }, "unknown config keys apiversion; expected api_version, allowed_api_versions, health_probe, deployment_failover"},
+		{"unsupported probe", func(p *ProviderConfig) { p.Config["health_probe"] = "count_tokens" }, `config.health_probe "count_tokens" is not supported; expected list, completion`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := azure()
+			tt.modify(&config)
+			assert.EqualError(t, config.Validate(domain.ProviderAzureOpenAI), tt.err)
+
+			// Disabled providers are not checked
+			config.Enabled = false
+			assert.NoError(t, config.Validate(domain.ProviderAzureOpenAI))
+		})
+	}
+
+	bedrock := ProviderConfig{Enabled: true, APIKey: "AKIA", Config: map[string]interface{}{"region": "us-east-1"}}
+	assert.EqualError(t, bedrock.Validate(domain.ProviderAWSBedrock),
+		"api_key and secret_key must be set together (AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY)")
+	bedrock.APIKey = ""
+	assert.NoError(t, bedrock.Validate(domain.ProviderAWSBedrock))
+}
+
+func TestReread_RejectsMisconfiguredProvider(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("AZURE_OPENAI_API_KEY", "key")
+	t.Setenv("AZURE_OPENAI_ENDPOINT", "")
+
+	_, err := Reread()
+	assert.EqualError(t, err, "provider azure-openai: base_url is required (AZURE_OPENAI_ENDPOINT)")
+
+	t.Setenv("AZURE_OPENAI_ENDPOINT", "https://example.openai.azure.com")
+	_, err = Reread()
+	assert.NoError(t, err)
+}
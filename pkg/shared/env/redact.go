@@ -0,0 +1,82 @@
+package env
+
+import (
+	"net/url"
+	"strings"
+)
+
+// redactedValue replaces secrets in configuration shown to operators
+const redactedValue = "[redacted]"
+
+// secretKeyMarkers are substrings of provider config keys whose values are
+// treated as secrets
+var secretKeyMarkers = []string{"key", "secret", "token", "password", "credential"}
+
+// Redacted returns a copy of the config that is safe to show operators. Keys
+// and secrets are cleared, custom header values are replaced since they
+// often carry credentials, and credentials embedded in provider base URLs
+// are removed. The original is not modified.
+func (c *Config) Redacted() *Config {
+	redacted := *c
+	redacted.AdminAPIKey = ""
+	redacted.RequestSigningSecrets = nil
+	redacted.paramProfilesErr = nil
+	redacted.AlertManagerURL = redactURL(c.AlertManagerURL)
+
+	redacted.Providers = make(map[string]ProviderConfig, len(c.Providers))
+	for name, provider := range c.Providers {
+		redacted.Providers[name] = provider.redacted()
+	}
+	return &redacted
+}
+
+func (p ProviderConfig) redacted() ProviderConfig {
+	p.APIKey = ""
+	p.SecretKey = ""
+	p.BaseURL = redactURL(p.BaseURL)
+
+	if p.CustomHeaders != nil {
+		headers := make(map[string]string, len(p.CustomHeaders))
+		for name := range p.CustomHeaders {
+			headers[name] = redactedValue
+		}
+		p.CustomHeaders = headers
+	}
+
+	if p.Config != nil {
+		config := make(map[string]interface{}, len(p.Config))
+		for key, value := range p.Config {
+			if isSecretKey(key) {
+				value = redactedValue
+			}
+			config[key] = value
+		}
+		p.Config = config
+	}
+	return p
+}
+
+// redactURL drops the user info and query of a URL, where credentials are
+// sometimes passed. Values that do not parse are redacted whole.
+func redactURL(value string) string {
+	if value == "" {
+		return ""
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return redactedValue
+	}
+	parsed.User = nil
+	parsed.RawQuery = ""
+	return parsed.String()
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, marker := range secretKeyMarkers {
+		if strings.Contains(key, marker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,356 @@
+package env
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ReloadResult reports the outcome of applying a reloaded config. Fields are
+// named by their JSON keys; provider fields are prefixed with
+// "providers.<name>.".
+type ReloadResult struct {
+	// Applied lists the fields that changed and now take effect
+	Applied []string `json:"applied"`
+
+	// Ignored lists the fields that changed but only take effect after a
+	// restart
+	Ignored []string `json:"ignored"`
+
+	// Errors lists changes a service could not apply, such as a provider
+	// that failed to start
+	Errors []string `json:"errors,omitempty"`
+}
+
+// Changed reports whether the reload found any difference
+func (r ReloadResult) Changed() bool {
+	return len(r.Applied) > 0 || len(r.Ignored) > 0
+}
+
+// Reload returns a copy of current with the fields that are safe to change at
+// runtime taken from next. Requests that already hold current keep using it,
+// so a reload never disturbs requests in flight.
+func Reload(current, next *Config) (*Config, ReloadResult) {
+	updated := *current
+	result := ReloadResult{Applied: []string{}, Ignored: []string{}}
+
+	apply := func(field string, from, to interface{}, set func()) {
+		if !reflect.DeepEqual(from, to) {
+			set()
+			result.Applied = append(result.Applied, field)
+		}
+	}
+	ignore := func(field string, from, to interface{}) {
+		if !reflect.DeepEqual(from, to) {
+			result.Ignored = append(result.Ignored, field)
+		}
+	}
+
+	// Limits and per-request behaviour are read on every request
+	apply("stream_tokens_per_second", current.StreamTokensPerSecond, next.StreamTokensPerSecond, func() {
+		updated.StreamTokensPerSecond = next.StreamTokensPerSecond
+	})
+	apply("tenant_stream_tokens_per_second", current.TenantStreamTokensPerSecond, next.TenantStreamTokensPerSecond, func() {
+		updated.TenantStreamTokensPerSecond = next.TenantStreamTokensPerSecond
+	})
+	apply("stream_collect_tenants", current.StreamCollectTenants, next.StreamCollectTenants, func() {
+		updated.StreamCollectTenants = next.StreamCollectTenants
+	})
+	apply("stream_fallback", current.StreamFallback, next.StreamFallback, func() {
+		updated.StreamFallback = next.StreamFallback
+	})
+	apply("stream_passthrough", current.StreamPassthrough, next.StreamPassthrough, func() {
+		updated.StreamPassthrough = next.StreamPassthrough
+	})
+	apply("response_size_alert_factor", current.ResponseSizeAlertFactor, next.ResponseSizeAlertFactor, func() {
+		updated.ResponseSizeAlertFactor = next.ResponseSizeAlertFactor
+	})
+	apply("stream_failover_grace", current.StreamFailoverGrace, next.StreamFailoverGrace, func() {
+		updated.StreamFailoverGrace = next.StreamFailoverGrace
+	})
+	apply("created_max_skew", current.CreatedMaxSkew, next.CreatedMaxSkew, func() {
+		updated.CreatedMaxSkew = next.CreatedMaxSkew
+	})
+	apply("prefill_emulation", current.PrefillEmulation, next.PrefillEmulation, func() {
+		updated.PrefillEmulation = next.PrefillEmulation
+	})
+	apply("model_defaults", current.ModelDefaults, next.ModelDefaults, func() {
+		updated.ModelDefaults = next.ModelDefaults
+	})
+	apply("tenant_providers", current.TenantProviders, next.TenantProviders, func() {
+		updated.TenantProviders = next.TenantProviders
+	})
+	apply("default_completion_model", current.DefaultCompletionModel, next.DefaultCompletionModel, func() {
+		updated.DefaultCompletionModel = next.DefaultCompletionModel
+	})
+	apply("default_embedding_model", current.DefaultEmbeddingModel, next.DefaultEmbeddingModel, func() {
+		updated.DefaultEmbeddingModel = next.DefaultEmbeddingModel
+	})
+	apply("strip_json_fences", current.StripJSONFences, next.StripJSONFences, func() {
+		updated.StripJSONFences = next.StripJSONFences
+	})
+	apply("retry_status_codes", current.RetryStatusCodes, next.RetryStatusCodes, func() {
+		updated.RetryStatusCodes = next.RetryStatusCodes
+	})
+	apply("retry_error_types", current.RetryErrorTypes, next.RetryErrorTypes, func() {
+		updated.RetryErrorTypes = next.RetryErrorTypes
+	})
+	apply("tool_result_sanitization", current.ToolResultSanitization, next.ToolResultSanitization, func() {
+		updated.ToolResultSanitization = next.ToolResultSanitization
+	})
+	apply("tenant_tool_result_sanitization", current.TenantToolResultSanitization, next.TenantToolResultSanitization, func() {
+		updated.TenantToolResultSanitization = next.TenantToolResultSanitization
+	})
+	apply("context_utilization_warning", current.ContextUtilizationWarning, next.ContextUtilizationWarning, func() {
+		updated.ContextUtilizationWarning = next.ContextUtilizationWarning
+	})
+	apply("rate_limit_requests_per_minute", current.RateLimitRequestsPerMinute, next.RateLimitRequestsPerMinute, func() {
+		updated.RateLimitRequestsPerMinute = next.RateLimitRequestsPerMinute
+	})
+	apply("rate_limit_tokens_per_minute", current.RateLimitTokensPerMinute, next.RateLimitTokensPerMinute, func() {
+		updated.RateLimitTokensPerMinute = next.RateLimitTokensPerMinute
+	})
+	apply("rate_limit_provider_requests_per_minute", current.RateLimitProviderRequestsPerMinute, next.RateLimitProviderRequestsPerMinute, func() {
+		updated.RateLimitProviderRequestsPerMinute = next.RateLimitProviderRequestsPerMinute
+	})
+	apply("rate_limit_provider_tokens_per_minute", current.RateLimitProviderTokensPerMinute, next.RateLimitProviderTokensPerMinute, func() {
+		updated.RateLimitProviderTokensPerMinute = next.RateLimitProviderTokensPerMinute
+	})
+	apply("passive_health", current.PassiveHealth, next.PassiveHealth, func() {
+		updated.PassiveHealth = next.PassiveHealth
+	})
+	apply("faq.match_threshold", current.FAQ.MatchThreshold, next.FAQ.MatchThreshold, func() {
+		updated.FAQ.MatchThreshold = next.FAQ.MatchThreshold
+	})
+	apply("batch", current.Batch, next.Batch, func() {
+		updated.Batch = next.Batch
+	})
+	apply("compression_enabled", current.CompressionEnabled, next.CompressionEnabled, func() {
+		updated.CompressionEnabled = next.CompressionEnabled
+	})
+	apply("max_decompressed_body_bytes", current.MaxDecompressedBodyBytes, next.MaxDecompressedBodyBytes, func() {
+		updated.MaxDecompressedBodyBytes = next.MaxDecompressedBodyBytes
+	})
+	apply("max_messages", current.MaxMessages, next.MaxMessages, func() {
+		updated.MaxMessages = next.MaxMessages
+	})
+	apply("max_content_bytes", current.MaxContentBytes, next.MaxContentBytes, func() {
+		updated.MaxContentBytes = next.MaxContentBytes
+	})
+	apply("tenant_max_tool_rounds", current.TenantMaxToolRounds, next.TenantMaxToolRounds, func() {
+		updated.TenantMaxToolRounds = next.TenantMaxToolRounds
+	})
+	apply("tool_round_idle_ttl", current.ToolRoundIdleTTL, next.ToolRoundIdleTTL, func() {
+		updated.ToolRoundIdleTTL = next.ToolRoundIdleTTL
+	})
+	apply("debug_raw_responses", current.DebugRawResponses, next.DebugRawResponses, func() {
+		updated.DebugRawResponses = next.DebugRawResponses
+	})
+	apply("debug_explain_routing", current.DebugExplainRouting, next.DebugExplainRouting, func() {
+		updated.DebugExplainRouting = next.DebugExplainRouting
+	})
+	apply("cache.ttl", current.Cache.TTL, next.Cache.TTL, func() {
+		updated.Cache.TTL = next.Cache.TTL
+	})
+	apply("cache.stale_grace", current.Cache.StaleGrace, next.Cache.StaleGrace, func() {
+		updated.Cache.StaleGrace = next.Cache.StaleGrace
+	})
+	apply("embedding_fallbacks", current.EmbeddingFallbacks, next.EmbeddingFallbacks, func() {
+		updated.EmbeddingFallbacks = next.EmbeddingFallbacks
+	})
+	apply("embedding_dimensions", current.EmbeddingDimensions, next.EmbeddingDimensions, func() {
+		updated.EmbeddingDimensions = next.EmbeddingDimensions
+	})
+	apply("embedding_batch_size", current.EmbeddingBatchSize, next.EmbeddingBatchSize, func() {
+		updated.EmbeddingBatchSize = next.EmbeddingBatchSize
+	})
+	apply("embedding_batch_concurrency", current.EmbeddingBatchConcurrency, next.EmbeddingBatchConcurrency, func() {
+		updated.EmbeddingBatchConcurrency = next.EmbeddingBatchConcurrency
+	})
+	apply("auto_routing_weights", current.AutoRoutingWeights, next.AutoRoutingWeights, func() {
+		updated.AutoRoutingWeights = next.AutoRoutingWeights
+	})
+	apply("embedding_cost_routing", current.EmbeddingCostRouting, next.EmbeddingCostRouting, func() {
+		updated.EmbeddingCostRouting = next.EmbeddingCostRouting
+	})
+	apply("request_signing_secrets", current.RequestSigningSecrets, next.RequestSigningSecrets, func() {
+		updated.RequestSigningSecrets = next.RequestSigningSecrets
+	})
+	apply("request_signing_window", current.RequestSigningWindow, next.RequestSigningWindow, func() {
+		updated.RequestSigningWindow = next.RequestSigningWindow
+	})
+	apply("kill_switches", current.KillSwitches, next.KillSwitches, func() {
+		updated.KillSwitches = next.KillSwitches
+	})
+	apply("response_signing_tenants", current.ResponseSigningTenants, next.ResponseSigningTenants, func() {
+		updated.ResponseSigningTenants = next.ResponseSigningTenants
+	})
+	apply("param_profiles", current.ParamProfiles, next.ParamProfiles, func() {
+		updated.ParamProfiles = next.ParamProfiles
+	})
+	apply("model_transforms", current.ModelTransforms, next.ModelTransforms, func() {
+		updated.ModelTransforms = next.ModelTransforms
+	})
+	apply("model_deprecation_action", current.ModelDeprecationAction, next.ModelDeprecationAction, func() {
+		updated.ModelDeprecationAction = next.ModelDeprecationAction
+	})
+	apply("model_successors", current.ModelSuccessors, next.ModelSuccessors, func() {
+		updated.ModelSuccessors = next.ModelSuccessors
+	})
+	apply("model_aliases", current.ModelAliases, next.ModelAliases, func() {
+		updated.ModelAliases = next.ModelAliases
+	})
+	apply("model_pricing", current.ModelPricing, next.ModelPricing, func() {
+		updated.ModelPricing = next.ModelPricing
+	})
+	apply("max_cost_usd", current.MaxCostUSD, next.MaxCostUSD, func() {
+		updated.MaxCostUSD = next.MaxCostUSD
+	})
+	apply("stream_usage_sample_rate", current.StreamUsageSampleRate, next.StreamUsageSampleRate, func() {
+		updated.StreamUsageSampleRate = next.StreamUsageSampleRate
+	})
+	apply("stream_usage_billing_source", current.StreamUsageBillingSource, next.StreamUsageBillingSource, func() {
+		updated.StreamUsageBillingSource = next.StreamUsageBillingSource
+	})
+	apply("logging.request_sample_n", current.Logging.RequestSampleN, next.Logging.RequestSampleN, func() {
+		updated.Logging.RequestSampleN = next.Logging.RequestSampleN
+	})
+	apply("logging.max_field_bytes", current.Logging.MaxFieldBytes, next.Logging.MaxFieldBytes, func() {
+		updated.Logging.MaxFieldBytes = next.Logging.MaxFieldBytes
+	})
+	apply("default_provider_order", current.DefaultProviderOrder, next.DefaultProviderOrder, func() {
+		updated.DefaultProviderOrder = next.DefaultProviderOrder
+	})
+
+	// Listeners, auth, logging and cache backends are wired up at startup
+	ignore("environment", current.Environment, next.Environment)
+	ignore("service_name", current.ServiceName, next.ServiceName)
+	ignore("version", current.Version, next.Version)
+	ignore("port", current.Port, next.Port)
+	ignore("auth_enabled", current.AuthEnabled, next.AuthEnabled)
+	ignore("istio_enabled", current.IstioEnabled, next.IstioEnabled)
+	ignore("admin_api_key", current.AdminAPIKey, next.AdminAPIKey)
+	ignore("cache_type", current.CacheType, next.CacheType)
+	ignore("cache.type", current.Cache.Type, next.Cache.Type)
+	ignore("cache.max_size", current.Cache.MaxSize, next.Cache.MaxSize)
+	ignore("logging.level", current.Logging.Level, next.Logging.Level)
+	ignore("logging.format", current.Logging.Format, next.Logging.Format)
+	ignore("logging.structured", current.Logging.Structured, next.Logging.Structured)
+	ignore("provider_keepalive_interval", current.ProviderKeepAliveInterval, next.ProviderKeepAliveInterval)
+	ignore("provider_warmup_timeout", current.ProviderWarmupTimeout, next.ProviderWarmupTimeout)
+	ignore("provider_warmup_connections", current.ProviderWarmupConnections, next.ProviderWarmupConnections)
+	ignore("health_check_interval", current.HealthCheckInterval, next.HealthCheckInterval)
+	ignore("health_check_timeout", current.HealthCheckTimeout, next.HealthCheckTimeout)
+	ignore("health_check_concurrency", current.HealthCheckConcurrency, next.HealthCheckConcurrency)
+	ignore("kill_switch_refresh", current.KillSwitchRefresh, next.KillSwitchRefresh)
+	ignore("tenant_credentials_dir", current.TenantCredentialsDir, next.TenantCredentialsDir)
+	ignore("tenant_credentials_ttl", current.TenantCredentialsTTL, next.TenantCredentialsTTL)
+	ignore("dead_letter_path", current.DeadLetterPath, next.DeadLetterPath)
+	ignore("model_registry_manifest", current.ModelRegistryManifest, next.ModelRegistryManifest)
+	ignore("model_registry_live", current.ModelRegistryLive, next.ModelRegistryLive)
+	ignore("faq.embedding_model", current.FAQ.EmbeddingModel, next.FAQ.EmbeddingModel)
+	ignore("faq.store_path", current.FAQ.StorePath, next.FAQ.StorePath)
+	ignore("alertmanager_url", current.AlertManagerURL, next.AlertManagerURL)
+	ignore("tenant_config_file", current.TenantConfigFile, next.TenantConfigFile)
+
+	updated.Providers, result = reloadProviders(current.Providers, next.Providers, result)
+	return &updated, result
+}
+
+// reloadProviders applies provider enable/disable. Connection settings such
+// as credentials and endpoints are baked into the provider clients, so a
+// change to them is ignored until restart.
+func reloadProviders(current, next map[string]ProviderConfig, result ReloadResult) (map[string]ProviderConfig, ReloadResult) {
+	names := make(map[string]bool, len(current)+len(next))
+	for name := range current {
+		names[name] = true
+	}
+	for name := range next {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	providers := make(map[string]ProviderConfig, len(current))
+	for _, name := range sorted {
+		from, inCurrent := current[name]
+		to, inNext := next[name]
+
+		if inCurrent {
+			providers[name] = from
+		}
+		if !inNext {
+			to = ProviderConfig{}
+		}
+
+		if from.Enabled != to.Enabled {
+			if inCurrent {
+				from.Enabled = to.Enabled
+				providers[name] = from
+				result.Applied = append(result.Applied, "providers."+name+".enabled")
+			} else {
+				// Providers absent at startup have no settings to enable
+				result.Ignored = append(result.Ignored, "providers."+name)
+			}
+		}
+
+		connection := func(p ProviderConfig) ProviderConfig {
+			p.Enabled = false
+			return p
+		}
+		if inCurrent && inNext && !reflect.DeepEqual(connection(from), connection(to)) {
+			result.Ignored = append(result.Ignored, "providers."+name+".connection")
+		}
+	}
+
+	return providers, result
+}
+
+// Reread rebuilds the config from the process environment and CONFIG_FILE,
+// rejecting it if it does not validate
+func Reread() (*Config, error) {
+	if path := os.Getenv("CONFIG_FILE"); path != "" {
+		if err := LoadConfigFile(path); err != nil {
+			return nil, err
+		}
+	}
+	config := DetectEnvironment()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// LoadConfigFile sets environment variables from a file of KEY=VALUE lines,
+// such as a mounted ConfigMap. Blank lines and lines starting with # are
+// skipped. Keys removed from the file keep their last value until restart.
+func LoadConfigFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if key = strings.TrimSpace(key); !ok || key == "" {
+			return fmt.Errorf("config file %s line %d: expected KEY=VALUE", path, lineNumber)
+		}
+		if err := os.Setenv(key, strings.Trim(strings.TrimSpace(value), `"`)); err != nil {
+			return fmt.Errorf("config file %s line %d: %w", path, lineNumber, err)
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,374 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quantum-suite/platform/internal/domain"
+)
+
+func TestReload_SeparatesAppliedAndIgnoredFields(t *testing.T) {
+	current := &Config{
+		Port:                  8080,
+		StreamTokensPerSecond: 10,
+		Cache:                 CacheConfig{TTL: time.Hour, MaxSize: 100},
+		Providers: map[string]ProviderConfig{
+			"azure-openai": {Enabled: true, BaseURL: "https://a.example.com"},
+			"aws-bedrock":  {Enabled: false},
+		},
+	}
+	next := &Config{
+		Port:                  9090,
+		StreamTokensPerSecond: 20,
+		Cache:                 CacheConfig{TTL: time.Minute, MaxSize: 200},
+		Providers: map[string]ProviderConfig{
+			"azure-openai": {Enabled: false, BaseURL: "https://b.example.com"},
+			"aws-bedrock":  {Enabled: true},
+			"openai":       {Enabled: true},
+		},
+	}
+
+	updated, result := Reload(current, next)
+
+	assert.Equal(t, []string{"stream_tokens_per_second", "cache.ttl", "providers.aws-bedrock.enabled", "providers.azure-openai.enabled"}, result.Applied)
+	assert.Equal(t, []string{"port", "cache.max_size", "providers.azure-openai.connection", "providers.openai"}, result.Ignored)
+
+	assert.Equal(t, 20.0, updated.StreamTokensPerSecond)
+	assert.Equal(t, time.Minute, updated.Cache.TTL)
+	assert.Equal(t, 8080, updated.Port)
+	assert.Equal(t, 100, updated.Cache.MaxSize)
+	assert.False(t, updated.Providers["azure-openai"].Enabled)
+	assert.Equal(t, "https://a.example.com", updated.Providers["azure-openai"].BaseURL)
+	assert.True(t, updated.Providers["aws-bedrock"].Enabled)
+	assert.NotContains(t, updated.Providers, "openai")
+
+	// The current config is never modified
+	assert.Equal(t, 10.0, current.StreamTokensPerSecond)
+	assert.True(t, current.Providers["azure-openai"].Enabled)
+}
+
+func TestReload_NoChanges(t *testing.T) {
+	config := &Config{Port: 8080, Providers: map[string]ProviderConfig{"azure-openai": {Enabled: true}}}
+	_, result := Reload(config, config)
+	assert.False(t, result.Changed())
+}
+
+// TestReload_CoversEveryField fails when a Config field is neither applied
+// nor ignored by Reload, so new settings cannot be dropped from reloads
+// silently. Struct fields are checked field by field.
+func TestReload_CoversEveryField(t *testing.T) {
+	var check func(path string, field func(*Config) reflect.Value)
+	check = func(path string, field func(*Config) reflect.Value) {
+		current := &Config{}
+		value := field(current)
+		if value.Kind() == reflect.Struct {
+			for i := 0; i < value.NumField(); i++ {
+				if !value.Type().Field(i).IsExported() {
+					continue
+				}
+				i := i
+				check(path+"."+value.Type().Field(i).Name, func(c *Config) reflect.Value { return field(c).Field(i) })
+			}
+			return
+		}
+
+		next := &Config{}
+		if path == "Providers" {
+			// Providers are compared one by one, so add an enabled one
+			next.Providers = map[string]ProviderConfig{"openai": {Enabled: true}}
+		} else {
+			changeValue(t, path, field(next))
+		}
+		_, result := Reload(current, next)
+		assert.True(t, result.Changed(), "%s is neither applied nor ignored by Reload", path)
+	}
+
+	configType := reflect.TypeOf(Config{})
+	for i := 0; i < configType.NumField(); i++ {
+		if !configType.Field(i).IsExported() {
+			continue
+		}
+		i := i
+		check(configType.Field(i).Name, func(c *Config) reflect.Value { return reflect.ValueOf(c).Elem().Field(i) })
+	}
+}
+
+// changeValue sets value to something other than its zero value
+func changeValue(t *testing.T, path string, value reflect.Value) {
+	switch value.Kind() {
+	case reflect.Bool:
+		value.SetBool(true)
+	case reflect.Int, reflect.Int64:
+		value.SetInt(1)
+	case reflect.Float64:
+		value.SetFloat(1)
+	case reflect.String:
+		value.SetString("changed")
+	case reflect.Slice:
+		value.Set(reflect.MakeSlice(value.Type(), 1, 1))
+	case reflect.Map:
+		changed := reflect.MakeMap(value.Type())
+		changed.SetMapIndex(reflect.New(value.Type().Key()).Elem(), reflect.New(value.Type().Elem()).Elem())
+		value.Set(changed)
+	default:
+		t.Fatalf("%s: cannot change a %s field", path, value.Kind())
+	}
+}
+
+func TestReload_ModelAliasesPricingAndCostLimit(t *testing.T) {
+	current := &Config{}
+	next := &Config{
+		ModelAliases: map[string]string{"fast": "gpt-4o-mini"},
+		ModelPricing: map[string]domain.ModelPricing{"gpt-4o": {InputTokenCost: 0.000002, OutputTokenCost: 0.000008, Unit: "token"}},
+		MaxCostUSD:   0.5,
+	}
+
+	updated, result := Reload(current, next)
+
+	assert.Equal(t, []string{"model_aliases", "model_pricing", "max_cost_usd"}, result.Applied)
+	assert.Empty(t, result.Ignored)
+	assert.Equal(t, next.ModelAliases, updated.ModelAliases)
+	assert.Equal(t, next.ModelPricing, updated.ModelPricing)
+	assert.Equal(t, 0.5, updated.MaxCostUSD)
+}
+
+func TestReread_ModelAliasesPricingAndCostLimit(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("MODEL_ALIASES", "fast=gpt-4o-mini,smart=gpt-4o")
+	t.Setenv("MODEL_PRICING", "gpt-4o=0.0025:0.01,o3=0.002:0.008:0.01")
+	t.Setenv("MAX_COST_USD", "0.25")
+
+	config, err := Reread()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"fast": "gpt-4o-mini", "smart": "gpt-4o"}, config.ModelAliases)
+	assert.InDelta(t, 0.0000025, config.ModelPricing["gpt-4o"].InputTokenCost, 1e-12)
+	assert.InDelta(t, 0.00001, config.ModelPricing["gpt-4o"].OutputTokenCost, 1e-12)
+	assert.InDelta(t, 0.00001, config.ModelPricing["o3"].ReasoningTokenCost, 1e-12)
+	input, output, _ := config.ModelPricing["gpt-4o"].PerThousandTokens()
+	assert.InDelta(t, 0.0025, input, 1e-12)
+	assert.InDelta(t, 0.01, output, 1e-12)
+	assert.Equal(t, 0.25, config.MaxCostUSD)
+
+	for name, value := range map[string]string{
+		"MODEL_ALIASES": "fast=fast",
+		"MODEL_PRICING": "gpt-4o=cheap:0.01",
+		"MAX_COST_USD":  "-1",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			_, err := Reread()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	t.Setenv("QLENS_TEST_LIMIT", "")
+	t.Setenv("QLENS_TEST_NAME", "")
+
+	path := filepath.Join(t.TempDir(), "qlens.env")
+	require.NoError(t, os.WriteFile(path, []byte("# comment\n\nQLENS_TEST_LIMIT = 25\nQLENS_TEST_NAME=\"router\"\n"), 0o600))
+	require.NoError(t, LoadConfigFile(path))
+	assert.Equal(t, "25", os.Getenv("QLENS_TEST_LIMIT"))
+	assert.Equal(t, "router", os.Getenv("QLENS_TEST_NAME"))
+
+	require.NoError(t, os.WriteFile(path, []byte("not a pair\n"), 0o600))
+	assert.Error(t, LoadConfigFile(path))
+}
+
+func TestReread_ParamProfiles(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("PARAM_PROFILES", "precise=temperature:0.1,terse=max_tokens:64|top_p:0.5")
+
+	config, err := Reread()
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, *config.ParamProfiles["creative"].Temperature)
+	assert.Equal(t, 0.95, *config.ParamProfiles["creative"].TopP)
+	// A configured profile replaces the built-in one of the same name
+	assert.Equal(t, 0.1, *config.ParamProfiles["precise"].Temperature)
+	assert.Nil(t, config.ParamProfiles["precise"].TopP)
+	assert.Equal(t, 64, *config.ParamProfiles["terse"].MaxTokens)
+
+	for _, value := range []string{
+		"wild=temperature:3",
+		"odd=top_p:high",
+		"typo=temprature:0.5",
+		"=temperature:0.5",
+		"empty=",
+	} {
+		t.Setenv("PARAM_PROFILES", value)
+		_, err := Reread()
+		assert.Error(t, err, value)
+	}
+}
+
+func TestReread_StreamUsage(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("STREAM_USAGE_SAMPLE_RATE", "0.5")
+	t.Setenv("STREAM_USAGE_BILLING_SOURCE", "estimate")
+
+	config, err := Reread()
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, config.StreamUsageSampleRate)
+	assert.Equal(t, StreamUsageSourceEstimate, config.StreamUsageBillingSource)
+
+	t.Setenv("STREAM_USAGE_BILLING_SOURCE", "tokenizer")
+	_, err = Reread()
+	assert.Error(t, err)
+
+	t.Setenv("STREAM_USAGE_BILLING_SOURCE", "provider")
+	t.Setenv("STREAM_USAGE_SAMPLE_RATE", "2")
+	_, err = Reread()
+	assert.Error(t, err)
+}
+
+func TestReread_ContextUtilizationWarning(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("CONTEXT_UTILIZATION_WARNING", "0.8")
+
+	config, err := Reread()
+	require.NoError(t, err)
+	assert.Equal(t, 0.8, config.ContextUtilizationWarning)
+
+	t.Setenv("CONTEXT_UTILIZATION_WARNING", "80")
+	_, err = Reread()
+	assert.Error(t, err)
+}
+
+func TestReread_PassiveHealth(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("PASSIVE_HEALTH_WINDOW", "30s")
+	t.Setenv("PASSIVE_HEALTH_DEGRADED_ERROR_RATE", "0.1")
+
+	config, err := Reread()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, config.PassiveHealth.Window)
+	assert.Equal(t, 10, config.PassiveHealth.MinRequests)
+	assert.Equal(t, 0.1, config.PassiveHealth.DegradedErrorRate)
+	assert.Equal(t, 0.5, config.PassiveHealth.UnhealthyErrorRate)
+
+	t.Setenv("PASSIVE_HEALTH_DEGRADED_ERROR_RATE", "0.6")
+	_, err = Reread()
+	assert.Error(t, err)
+
+	t.Setenv("PASSIVE_HEALTH_DEGRADED_ERROR_RATE", "0.2")
+	t.Setenv("PASSIVE_HEALTH_WINDOW", "1h")
+	_, err = Reread()
+	assert.Error(t, err)
+}
+
+func TestReload_LoggingSampling(t *testing.T) {
+	current := &Config{Logging: LoggingConfig{Level: "info", RequestSampleN: 1}}
+	next := &Config{Logging: LoggingConfig{Level: "debug", RequestSampleN: 100, MaxFieldBytes: 2048}}
+
+	updated, result := Reload(current, next)
+
+	assert.Equal(t, []string{"logging.request_sample_n", "logging.max_field_bytes"}, result.Applied)
+	assert.Equal(t, []string{"logging.level"}, result.Ignored)
+	assert.Equal(t, "info", updated.Logging.Level)
+	assert.Equal(t, 100, updated.Logging.RequestSampleN)
+	assert.Equal(t, 2048, updated.Logging.MaxFieldBytes)
+}
+
+func TestReread_LoggingSampling(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("LOG_REQUEST_SAMPLE_N", "50")
+
+	config, err := Reread()
+	require.NoError(t, err)
+	assert.Equal(t, 50, config.Logging.RequestSampleN)
+	assert.Zero(t, config.Logging.MaxFieldBytes)
+
+	t.Setenv("LOG_MAX_FIELD_BYTES", "-1")
+	_, err = Reread()
+	assert.Error(t, err)
+}
+
+func TestReread_TenantProviders(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("TENANT_PROVIDERS", "acme=azure-openai|AWS-Bedrock,globex=openai")
+
+	config, err := Reread()
+	require.NoError(t, err)
+	assert.Equal(t, []domain.Provider{domain.ProviderAzureOpenAI, domain.ProviderAWSBedrock}, config.TenantProviders["acme"])
+	assert.True(t, config.ProviderAllowed("acme", domain.ProviderAWSBedrock))
+	assert.False(t, config.ProviderAllowed("acme", domain.ProviderOpenAI))
+	assert.False(t, config.ProviderAllowed("globex", domain.ProviderAzureOpenAI))
+	assert.True(t, config.ProviderAllowed("initech", domain.ProviderOpenAI))
+}
+
+func TestReread_ModelDefaults(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("MODEL_DEFAULT_MAX_TOKENS", "*=1024,gpt-4o=2048")
+	t.Setenv("MODEL_MAX_TOKENS_CEILING", "*=8192")
+
+	config, err := Reread()
+	require.NoError(t, err)
+	assert.Equal(t, ModelDefaults{MaxTokens: 2048, MaxTokensCeiling: 8192}, config.ModelDefaultsFor("gpt-4o"))
+	assert.Equal(t, ModelDefaults{MaxTokens: 1024, MaxTokensCeiling: 8192}, config.ModelDefaultsFor("claude-3-sonnet"))
+}
+
+func TestReread_ToolResultSanitization(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("TOOL_RESULT_SANITIZATION", "strip")
+	t.Setenv("TENANT_TOOL_RESULT_SANITIZATION", "acme=wrap,globex=off")
+
+	config, err := Reread()
+	require.NoError(t, err)
+	assert.Equal(t, ToolSanitizationWrap, config.ToolSanitizationFor("acme"))
+	assert.Equal(t, ToolSanitizationOff, config.ToolSanitizationFor("globex"))
+	assert.Equal(t, ToolSanitizationStrip, config.ToolSanitizationFor("initech"))
+
+	t.Setenv("TENANT_TOOL_RESULT_SANITIZATION", "acme=escape")
+	_, err = Reread()
+	assert.Error(t, err)
+}
+
+func TestReread_ModelDeprecation(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("MODEL_DEPRECATION_ACTION", "migrate")
+	t.Setenv("MODEL_SUCCESSORS", "gpt-35-turbo=gpt-4o-mini")
+
+	config, err := Reread()
+	require.NoError(t, err)
+	assert.Equal(t, ModelDeprecationMigrate, config.ModelDeprecationAction)
+	assert.Equal(t, map[string]string{"gpt-35-turbo": "gpt-4o-mini"}, config.ModelSuccessors)
+
+	t.Setenv("MODEL_DEPRECATION_ACTION", "block")
+	_, err = Reread()
+	assert.Error(t, err)
+
+	t.Setenv("MODEL_DEPRECATION_ACTION", "")
+	t.Setenv("MODEL_SUCCESSORS", "gpt-4o=gpt-4o")
+	_, err = Reread()
+	assert.Error(t, err)
+}
+
+func TestReread_RetryStatusCodes(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+
+	config, err := Reread()
+	require.NoError(t, err)
+	assert.Equal(t, []int{408, 429, 500, 502, 503, 504}, config.RetryStatusCodes)
+
+	t.Setenv("RETRY_STATUS_CODES", "429,529")
+	t.Setenv("RETRY_ERROR_TYPES", "timeout")
+	config, err = Reread()
+	require.NoError(t, err)
+	assert.Equal(t, []int{429, 529}, config.RetryStatusCodes)
+	assert.Equal(t, []string{"timeout"}, config.RetryErrorTypes)
+
+	t.Setenv("RETRY_STATUS_CODES", "5xx")
+	_, err = Reread()
+	assert.Error(t, err)
+
+	t.Setenv("RETRY_STATUS_CODES", "700")
+	_, err = Reread()
+	assert.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -18,6 +19,7 @@ const (
 	ErrorTypeNotFound       ErrorType = "not_found"
 	ErrorTypeConflict      ErrorType = "conflict"
 	ErrorTypeTooManyRequests ErrorType = "too_many_requests"
+	ErrorTypeCancelled       ErrorType = "request_cancelled"
 	
 	// Business logic errors
 	ErrorTypeBusiness      ErrorType = "business_error"
@@ -39,6 +41,10 @@ const (
 	ErrorTypeInvalidModel        ErrorType = "invalid_model"
 )
 
+// StatusClientClosedRequest is the non-standard status used when the client
+// disconnects before a response is complete
+const StatusClientClosedRequest = 499
+
 // ErrorSeverity indicates how critical the error is
 type ErrorSeverity string
 
@@ -70,6 +76,10 @@ type QLensError struct {
 	Retryable  bool                   `json:"-"`
 	StatusCode int                    `json:"-"`
 	Internal   error                  `json:"-"` // Never exposed to clients
+
+	// ProviderStatus is the HTTP status the provider answered a failed call
+	// with, or 0 when the call failed before a response
+	ProviderStatus int `json:"-"`
 	Context    map[string]interface{} `json:"-"` // Internal context
 	
 	// Tracing information
@@ -79,6 +89,72 @@ type QLensError struct {
 	UserID    string `json:"-"`
 }
 
+// Detail keys for the error a provider reported, passed through to clients
+// so they can tell which part of their request was rejected
+const (
+	DetailProviderParam = "param"
+	DetailProviderCode  = "provider_code"
+	DetailProviderType  = "provider_type"
+
+	// DetailProviderRequestID is the provider's ID for the failed call, for
+	// matching it up in the provider's logs
+	DetailProviderRequestID = "provider_request_id"
+)
+
+// WithProviderDetails records the param, code and type from a provider's
+// error response. Empty values are skipped.
+func (e *QLensError) WithProviderDetails(param, code, errorType string) *QLensError {
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	for key, value := range map[string]string{
+		DetailProviderParam: param,
+		DetailProviderCode:  code,
+		DetailProviderType:  errorType,
+	} {
+		if value != "" {
+			e.Details[key] = value
+		}
+	}
+	return e
+}
+
+// WithProviderRequestID records the provider's ID for the failed call, which
+// its support needs to trace the request
+func (e *QLensError) WithProviderRequestID(requestID string) *QLensError {
+	if requestID == "" {
+		return e
+	}
+	if e.Details == nil {
+		e.Details = make(map[string]interface{})
+	}
+	e.Details[DetailProviderRequestID] = requestID
+	return e
+}
+
+// WithProviderStatus records the HTTP status the provider answered with,
+// which retry policies can match on
+func (e *QLensError) WithProviderStatus(statusCode int) *QLensError {
+	e.ProviderStatus = statusCode
+	return e
+}
+
+// WithRequestContext attributes the error to the tenant, user and request it
+// occurred for. Values already set are kept, so the layer closest to the
+// failure wins.
+func (e *QLensError) WithRequestContext(tenantID, userID, requestID string) *QLensError {
+	if e.TenantID == "" {
+		e.TenantID = tenantID
+	}
+	if e.UserID == "" {
+		e.UserID = userID
+	}
+	if e.RequestID == "" {
+		e.RequestID = requestID
+	}
+	return e
+}
+
 // Error implements the error interface
 func (e *QLensError) Error() string {
 	if e.Internal != nil {
@@ -115,7 +191,8 @@ func (e *QLensError) PublicError() *QLensError {
 	if e.Details != nil {
 		for key, value := range e.Details {
 			switch key {
-			case "field", "parameter", "model", "provider", "tenant_id", "validation_errors":
+			case "field", "parameter", "model", "provider", "tenant_id", "validation_errors", "limit",
+				DetailProviderParam, DetailProviderCode, DetailProviderType, DetailProviderRequestID:
 				public.Details[key] = value
 			}
 		}
@@ -145,6 +222,8 @@ func (e *QLensError) HTTPStatusCode() int {
 		return http.StatusTooManyRequests
 	case ErrorTypeTimeout:
 		return http.StatusRequestTimeout
+	case ErrorTypeCancelled:
+		return StatusClientClosedRequest
 	case ErrorTypeUnavailable, ErrorTypeProviderUnavailable:
 		return http.StatusServiceUnavailable
 	case ErrorTypeExternal, ErrorTypeProviderError:
@@ -380,6 +459,16 @@ func TimeoutError(operation string, timeout time.Duration) *QLensError {
 		Build()
 }
 
+// CancelledError creates an error for a request the client abandoned
+func CancelledError(operation string, err error) *QLensError {
+	return NewError(ErrorTypeCancelled, fmt.Sprintf("Operation %s was cancelled by the client", operation)).
+		WithDetail("operation", operation).
+		WithInternal(err).
+		WithSeverity(SeverityLow).
+		WithRetryable(false).
+		Build()
+}
+
 // InternalError creates an internal server error
 func InternalError(message string, err error) *QLensError {
 	return NewError(ErrorTypeInternal, message).
@@ -400,6 +489,15 @@ func IsRetryable(err error) bool {
 	return false
 }
 
+// IsCancellation reports whether an error stems from the client cancelling
+// the request rather than from a failure
+func IsCancellation(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return true
+	}
+	return IsType(err, ErrorTypeCancelled)
+}
+
 // IsType checks if an error is of a specific type
 func IsType(err error, errorType ErrorType) bool {
 	var qlensErr *QLensError
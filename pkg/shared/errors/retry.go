@@ -0,0 +1,57 @@
+package errors
+
+import "net/http"
+
+// DefaultRetryStatusCodes are the provider HTTP statuses retried unless
+// configured otherwise
+var DefaultRetryStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy decides which failed provider calls are retried, since
+// providers set the retryable flag on their errors inconsistently. In order
+// of precedence:
+//
+//  1. A call whose provider status or error type is listed is retried.
+//  2. A call the provider answered with any other status is not retried,
+//     whatever its error's flag says.
+//  3. A call that failed before a response, such as on a network error, is
+//     retried when its error is flagged retryable.
+type RetryPolicy struct {
+	StatusCodes []int
+	ErrorTypes  []string
+}
+
+// Retryable applies the policy to a failed call. providerStatus is 0 when
+// the provider never answered.
+func (p RetryPolicy) Retryable(providerStatus int, errorType string, flagged bool) bool {
+	for _, code := range p.StatusCodes {
+		if providerStatus != 0 && code == providerStatus {
+			return true
+		}
+	}
+	for _, listed := range p.ErrorTypes {
+		if listed == errorType {
+			return true
+		}
+	}
+	if providerStatus != 0 {
+		return false
+	}
+	return flagged
+}
+
+// RetryableError applies the policy to an error from a provider call. Errors
+// other than a QLensError are retried.
+func (p RetryPolicy) RetryableError(err error) bool {
+	qlensErr, ok := err.(*QLensError)
+	if !ok {
+		return true
+	}
+	return p.Retryable(qlensErr.ProviderStatus, string(qlensErr.Type), qlensErr.Retryable)
+}
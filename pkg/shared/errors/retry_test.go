@@ -0,0 +1,51 @@
+package errors
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryPolicy_DefaultStatusCodes(t *testing.T) {
+	policy := RetryPolicy{StatusCodes: DefaultRetryStatusCodes}
+
+	for _, code := range []int{408, 429, 500, 502, 503, 504} {
+		t.Run(fmt.Sprint(code), func(t *testing.T) {
+			// A listed status is retried even when the error says otherwise
+			assert.True(t, policy.Retryable(code, string(ErrorTypeValidation), false))
+		})
+	}
+	for _, code := range []int{400, 401, 403, 404, 501} {
+		t.Run(fmt.Sprint(code), func(t *testing.T) {
+			// Any other status is not, even when the error says otherwise
+			assert.False(t, policy.Retryable(code, string(ErrorTypeProviderError), true))
+		})
+	}
+}
+
+func TestRetryPolicy_Precedence(t *testing.T) {
+	policy := RetryPolicy{StatusCodes: []int{http.StatusServiceUnavailable}, ErrorTypes: []string{string(ErrorTypeTimeout)}}
+
+	// Listed error types are retried whatever the status
+	assert.True(t, policy.Retryable(http.StatusBadRequest, string(ErrorTypeTimeout), false))
+	assert.True(t, policy.Retryable(0, string(ErrorTypeTimeout), false))
+
+	// Without a status the error's flag decides
+	assert.True(t, policy.Retryable(0, string(ErrorTypeProviderError), true))
+	assert.False(t, policy.Retryable(0, string(ErrorTypeProviderError), false))
+
+	// An empty policy leaves everything to the flag, except answered calls
+	assert.False(t, RetryPolicy{}.Retryable(http.StatusServiceUnavailable, string(ErrorTypeProviderError), true))
+	assert.True(t, RetryPolicy{}.Retryable(0, string(ErrorTypeProviderError), true))
+}
+
+func TestRetryPolicy_RetryableError(t *testing.T) {
+	policy := RetryPolicy{StatusCodes: DefaultRetryStatusCodes}
+
+	assert.True(t, policy.RetryableError(fmt.Errorf("connection reset")))
+	assert.True(t, policy.RetryableError(ProviderError("azure-openai", "overloaded", nil).WithProviderStatus(http.StatusTooManyRequests)))
+	assert.False(t, policy.RetryableError(ProviderError("azure-openai", "not implemented", nil).WithProviderStatus(http.StatusNotImplemented)))
+	assert.False(t, policy.RetryableError(ValidationError("bad request", "messages")))
+}
@@ -0,0 +1,158 @@
+package errors
+
+import "net/http"
+
+// Categories group the error types the way clients usually handle them
+const (
+	CategoryRequest  = "request"
+	CategoryBusiness = "business"
+	CategorySystem   = "system"
+	CategoryProvider = "provider"
+)
+
+// Codes that narrow an error type down. Some are returned with a status of
+// their own rather than their type's.
+const (
+	CodeAmbiguousModel           = "ambiguous_model"
+	CodeDataResidencyUnavailable = "data_residency_unavailable"
+	CodeInvalidToolCallArguments = "invalid_tool_call_arguments"
+	CodeKillSwitch               = "kill_switch"
+	CodeRequestBodyTooLarge      = "request_body_too_large"
+	CodeToolRoundLimitExceeded   = "tool_round_limit_exceeded"
+)
+
+// ErrorTypeInfo describes an error type for clients building their error
+// handling
+type ErrorTypeInfo struct {
+	Type        ErrorType `json:"type"`
+	Category    string    `json:"category"`
+	StatusCode  int       `json:"status_code"`
+	Retryable   bool      `json:"retryable"`
+	Description string    `json:"description"`
+	// Codes lists the codes errors of this type may carry, each with the
+	// status it is actually returned with
+	Codes []ErrorCodeInfo `json:"codes,omitempty"`
+}
+
+// ErrorCodeInfo describes a code narrowing an error type down
+type ErrorCodeInfo struct {
+	Code        string `json:"code"`
+	StatusCode  int    `json:"status_code"`
+	Retryable   bool   `json:"retryable"`
+	Description string `json:"description"`
+}
+
+// errorTypeInfos documents every error type, in the order they are declared.
+// Status codes are filled in from HTTPStatusCode, and the tests fail when an
+// error type is declared without an entry or a predefined constructor
+// disagrees with its retryability. A code's status is its type's unless the
+// entry sets one, and the services' tests check their errors against it.
+var errorTypeInfos = []ErrorTypeInfo{
+	{Type: ErrorTypeValidation, Category: CategoryRequest,
+		Description: "The request is malformed or a parameter is invalid; details.field names the parameter.",
+		Codes: []ErrorCodeInfo{
+			{Code: CodeAmbiguousModel,
+				Description: "Several providers serve the model; set provider to choose one."},
+			{Code: CodeDataResidencyUnavailable, StatusCode: http.StatusUnprocessableEntity,
+				Description: "No provider serves the model in the requested data_residency region."},
+			{Code: CodeRequestBodyTooLarge, StatusCode: http.StatusRequestEntityTooLarge,
+				Description: "The decompressed request body is larger than the gateway accepts."},
+			{Code: CodeToolRoundLimitExceeded, StatusCode: http.StatusUnprocessableEntity,
+				Description: "The conversation has used up its tool-call rounds; details.limit is the limit."},
+		}},
+	{Type: ErrorTypeAuthentication, Category: CategoryRequest,
+		Description: "The API key is missing or invalid."},
+	{Type: ErrorTypeAuthorization, Category: CategoryRequest,
+		Description: "The API key may not perform this request, or the tenant is not entitled to the model."},
+	{Type: ErrorTypeNotFound, Category: CategoryRequest,
+		Description: "The requested resource does not exist."},
+	{Type: ErrorTypeConflict, Category: CategoryRequest,
+		Description: "The request conflicts with the resource's current state."},
+	{Type: ErrorTypeTooManyRequests, Category: CategoryRequest, Retryable: true,
+		Description: "The tenant's rate limit was hit; retry after the Retry-After header."},
+	{Type: ErrorTypeCancelled, Category: CategoryRequest,
+		Description: "The client disconnected before the response was complete."},
+
+	{Type: ErrorTypeBusiness, Category: CategoryBusiness,
+		Description: "The request breaks a business rule of the platform."},
+	{Type: ErrorTypeQuotaExceeded, Category: CategoryBusiness, Retryable: true,
+		Description: "The tenant's usage quota is spent; retry once it resets."},
+	{Type: ErrorTypeBudgetExceeded, Category: CategoryBusiness,
+		Description: "The tenant's budget is spent; requests fail until it is raised."},
+	{Type: ErrorTypeProviderLimit, Category: CategoryBusiness, Retryable: true,
+		Description: "The platform's usage limit at the provider is reached; retry later."},
+
+	{Type: ErrorTypeInternal, Category: CategorySystem,
+		Description: "An unexpected failure in the platform; report it with the request ID."},
+	{Type: ErrorTypeConfiguration, Category: CategorySystem,
+		Description: "The platform or a provider is misconfigured."},
+	{Type: ErrorTypeTimeout, Category: CategorySystem, Retryable: true,
+		Description: "The request took longer than its timeout."},
+	{Type: ErrorTypeUnavailable, Category: CategorySystem, Retryable: true,
+		Description: "A platform service is unavailable, for example while starting up."},
+	{Type: ErrorTypeExternal, Category: CategorySystem, Retryable: true,
+		Description: "A service the platform depends on failed."},
+
+	{Type: ErrorTypeProviderError, Category: CategoryProvider, Retryable: true,
+		Description: "The provider rejected or failed the call; details carry the provider's own error code and type.",
+		Codes: []ErrorCodeInfo{
+			{Code: CodeInvalidToolCallArguments,
+				Description: "A streamed tool call's arguments are not valid JSON, usually because the stream was cut off; the stream ends with this error."},
+		}},
+	{Type: ErrorTypeProviderUnavailable, Category: CategoryProvider, Retryable: true,
+		Description: "The provider cannot be reached, or traffic to it is stopped by a kill switch.",
+		Codes: []ErrorCodeInfo{
+			{Code: CodeKillSwitch, Retryable: true,
+				Description: "Traffic to the provider or model is stopped by a kill switch."},
+		}},
+	{Type: ErrorTypeModelUnavailable, Category: CategoryProvider, Retryable: true,
+		Description: "The model is temporarily unavailable at every provider serving it."},
+	{Type: ErrorTypeInvalidModel, Category: CategoryProvider,
+		Description: "The model is unknown or not served by the requested provider."},
+}
+
+// ErrorTypes lists every error type with its HTTP status, retryability and
+// description, for clients to build their error handling from
+func ErrorTypes() []ErrorTypeInfo {
+	infos := make([]ErrorTypeInfo, len(errorTypeInfos))
+	for i, info := range errorTypeInfos {
+		info.StatusCode = (&QLensError{Type: info.Type}).HTTPStatusCode()
+		if info.Codes != nil {
+			codes := make([]ErrorCodeInfo, len(info.Codes))
+			for j, code := range info.Codes {
+				if code.StatusCode == 0 {
+					code.StatusCode = info.StatusCode
+				}
+				codes[j] = code
+			}
+			info.Codes = codes
+		}
+		infos[i] = info
+	}
+	return infos
+}
+
+// DescribeErrorType returns the description of an error type
+func DescribeErrorType(errorType ErrorType) (ErrorTypeInfo, bool) {
+	for _, info := range ErrorTypes() {
+		if info.Type == errorType {
+			return info, true
+		}
+	}
+	return ErrorTypeInfo{}, false
+}
+
+// DescribeErrorCode returns the description of a code an error type may
+// carry, with the status the error is returned with
+func DescribeErrorCode(errorType ErrorType, code string) (ErrorCodeInfo, bool) {
+	info, ok := DescribeErrorType(errorType)
+	if !ok {
+		return ErrorCodeInfo{}, false
+	}
+	for _, codeInfo := range info.Codes {
+		if codeInfo.Code == code {
+			return codeInfo, true
+		}
+	}
+	return ErrorCodeInfo{}, false
+}
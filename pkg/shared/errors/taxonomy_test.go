@@ -0,0 +1,112 @@
+package errors
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// declaredErrorTypes finds the ErrorType constants declared in errors.go
+func declaredErrorTypes(t *testing.T) []string {
+	file, err := parser.ParseFile(token.NewFileSet(), "errors.go", nil, 0)
+	require.NoError(t, err)
+
+	var names []string
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+			if ident, ok := value.Type.(*ast.Ident); ok && ident.Name == "ErrorType" {
+				for _, name := range value.Names {
+					names = append(names, name.Name)
+				}
+			}
+		}
+	}
+	return names
+}
+
+func TestErrorTypes_CoverEveryDeclaredType(t *testing.T) {
+	declared := declaredErrorTypes(t)
+	require.NotEmpty(t, declared)
+
+	infos := ErrorTypes()
+	assert.Len(t, infos, len(declared), "every ErrorType needs an entry in errorTypeInfos")
+
+	seen := make(map[ErrorType]bool)
+	for _, info := range infos {
+		assert.False(t, seen[info.Type], "%s is listed twice", info.Type)
+		seen[info.Type] = true
+		assert.NotEmpty(t, info.Description, info.Type)
+		assert.NotEmpty(t, info.Category, info.Type)
+		assert.NotZero(t, info.StatusCode, info.Type)
+		for _, code := range info.Codes {
+			assert.NotEmpty(t, code.Description, code.Code)
+			assert.NotZero(t, code.StatusCode, code.Code)
+		}
+	}
+}
+
+func TestErrorTypes_CodesKeepTheirOwnStatus(t *testing.T) {
+	info, ok := DescribeErrorCode(ErrorTypeValidation, CodeDataResidencyUnavailable)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusUnprocessableEntity, info.StatusCode)
+
+	info, ok = DescribeErrorCode(ErrorTypeValidation, CodeAmbiguousModel)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusBadRequest, info.StatusCode, "codes without a status of their own get their type's")
+
+	info, ok = DescribeErrorCode(ErrorTypeProviderError, CodeInvalidToolCallArguments)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusBadGateway, info.StatusCode)
+	assert.False(t, info.Retryable)
+
+	_, ok = DescribeErrorCode(ErrorTypeValidation, CodeKillSwitch)
+	assert.False(t, ok, "codes belong to one type")
+}
+
+func TestErrorTypes_StatusCodes(t *testing.T) {
+	info, ok := DescribeErrorType(ErrorTypeTooManyRequests)
+	require.True(t, ok)
+	assert.Equal(t, http.StatusTooManyRequests, info.StatusCode)
+	assert.True(t, info.Retryable)
+
+	info, ok = DescribeErrorType(ErrorTypeCancelled)
+	require.True(t, ok)
+	assert.Equal(t, StatusClientClosedRequest, info.StatusCode)
+
+	_, ok = DescribeErrorType("nope")
+	assert.False(t, ok)
+}
+
+func TestErrorTypes_MatchPredefinedConstructors(t *testing.T) {
+	for _, err := range []*QLensError{
+		ValidationError("bad", "field"),
+		AuthenticationError("bad"),
+		AuthorizationError("bad"),
+		NotFoundError("model", "gpt"),
+		RateLimitError(10, time.Now()),
+		QuotaExceededError(10, 10, time.Now()),
+		BudgetExceededError(1, 1),
+		ProviderError("openai", "bad", nil),
+		ProviderUnavailableError("openai"),
+		ModelUnavailableError("gpt", "openai"),
+		TimeoutError("completion", time.Second),
+		CancelledError("completion", nil),
+		InternalError("bad", nil),
+	} {
+		info, ok := DescribeErrorType(err.Type)
+		require.True(t, ok, err.Type)
+		assert.Equal(t, err.Retryable, info.Retryable, err.Type)
+		assert.Equal(t, err.HTTPStatusCode(), info.StatusCode, err.Type)
+	}
+}
@@ -141,6 +141,14 @@ func NewFromEnv() Logger {
 	return NewLogger(cfg)
 }
 
+// NewNoop creates a logger that discards all output
+func NewNoop() Logger {
+	return &zapLogger{
+		zap:    zap.NewNop(),
+		fields: make([]zap.Field, 0),
+	}
+}
+
 // Context methods
 
 func (l *zapLogger) WithCorrelationID(id string) Logger {
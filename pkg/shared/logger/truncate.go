@@ -0,0 +1,130 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// truncatingLogger caps the length of string values before they reach the
+// underlying logger, so a large prompt or provider error cannot flood logs
+type truncatingLogger struct {
+	Logger
+	maxBytes int
+}
+
+// WithMaxFieldBytes returns a logger that truncates string, byte slice and
+// error values longer than maxBytes, including those in fields added later.
+// A maxBytes of 0 or less returns the logger unchanged.
+func WithMaxFieldBytes(l Logger, maxBytes int) Logger {
+	if maxBytes <= 0 {
+		return l
+	}
+	if t, ok := l.(*truncatingLogger); ok {
+		l = t.Logger
+	}
+	return &truncatingLogger{Logger: l, maxBytes: maxBytes}
+}
+
+// Truncate shortens s to at most maxBytes, without splitting a UTF-8
+// sequence, and notes how many bytes were dropped
+func Truncate(s string, maxBytes int) string {
+	if maxBytes <= 0 || len(s) <= maxBytes {
+		return s
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return fmt.Sprintf("%s...[truncated %d bytes]", s[:cut], len(s)-cut)
+}
+
+func (l *truncatingLogger) wrap(next Logger) Logger {
+	return &truncatingLogger{Logger: next, maxBytes: l.maxBytes}
+}
+
+func (l *truncatingLogger) value(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return Truncate(v, l.maxBytes)
+	case []byte:
+		return Truncate(string(v), l.maxBytes)
+	case error:
+		return Truncate(v.Error(), l.maxBytes)
+	}
+	return v
+}
+
+func (l *truncatingLogger) fields(fields []Field) []Field {
+	truncated := make([]Field, len(fields))
+	for i, field := range fields {
+		truncated[i] = Field{Key: field.Key, Value: l.value(field.Value)}
+	}
+	return truncated
+}
+
+func (l *truncatingLogger) WithCorrelationID(id string) Logger {
+	return l.wrap(l.Logger.WithCorrelationID(Truncate(id, l.maxBytes)))
+}
+
+func (l *truncatingLogger) WithTenant(tenantID string) Logger {
+	return l.wrap(l.Logger.WithTenant(Truncate(tenantID, l.maxBytes)))
+}
+
+func (l *truncatingLogger) WithUser(userID string) Logger {
+	return l.wrap(l.Logger.WithUser(Truncate(userID, l.maxBytes)))
+}
+
+func (l *truncatingLogger) WithProvider(provider string) Logger {
+	return l.wrap(l.Logger.WithProvider(Truncate(provider, l.maxBytes)))
+}
+
+func (l *truncatingLogger) WithError(err error) Logger {
+	if err != nil && len(err.Error()) > l.maxBytes {
+		err = errors.New(Truncate(err.Error(), l.maxBytes))
+	}
+	return l.wrap(l.Logger.WithError(err))
+}
+
+func (l *truncatingLogger) WithField(key string, value interface{}) Logger {
+	return l.wrap(l.Logger.WithField(key, l.value(value)))
+}
+
+func (l *truncatingLogger) WithFields(fields map[string]interface{}) Logger {
+	truncated := make(map[string]interface{}, len(fields))
+	for key, value := range fields {
+		truncated[key] = l.value(value)
+	}
+	return l.wrap(l.Logger.WithFields(truncated))
+}
+
+func (l *truncatingLogger) Debug(msg string, fields ...Field) {
+	l.Logger.Debug(msg, l.fields(fields)...)
+}
+
+func (l *truncatingLogger) Info(msg string, fields ...Field) {
+	l.Logger.Info(msg, l.fields(fields)...)
+}
+
+func (l *truncatingLogger) Warn(msg string, fields ...Field) {
+	l.Logger.Warn(msg, l.fields(fields)...)
+}
+
+func (l *truncatingLogger) Error(msg string, fields ...Field) {
+	l.Logger.Error(msg, l.fields(fields)...)
+}
+
+func (l *truncatingLogger) Fatal(msg string, fields ...Field) {
+	l.Logger.Fatal(msg, l.fields(fields)...)
+}
+
+func (l *truncatingLogger) StartRequest(method, path string) Logger {
+	return l.wrap(l.Logger.StartRequest(Truncate(method, l.maxBytes), Truncate(path, l.maxBytes)))
+}
+
+func (l *truncatingLogger) LogProviderError(provider, model string, errorType string, err error) {
+	if err != nil && len(err.Error()) > l.maxBytes {
+		err = errors.New(Truncate(err.Error(), l.maxBytes))
+	}
+	l.Logger.LogProviderError(provider, model, errorType, err)
+}
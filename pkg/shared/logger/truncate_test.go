@@ -0,0 +1,65 @@
+package logger
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// recordingLogger captures the fields passed to it
+type recordingLogger struct {
+	Logger
+	fields map[string]interface{}
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{Logger: NewNoop(), fields: make(map[string]interface{})}
+}
+
+func (r *recordingLogger) WithField(key string, value interface{}) Logger {
+	r.fields[key] = value
+	return r
+}
+
+func (r *recordingLogger) WithError(err error) Logger {
+	r.fields["error"] = err.Error()
+	return r
+}
+
+func (r *recordingLogger) Info(msg string, fields ...Field) {
+	for _, field := range fields {
+		r.fields[field.Key] = field.Value
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	assert.Equal(t, "short", Truncate("short", 10))
+	assert.Equal(t, "unlimited", Truncate("unlimited", 0))
+	assert.Equal(t, "abcd...[truncated 6 bytes]", Truncate("abcdefghij", 4))
+	// Multi-byte characters are not split
+	assert.Equal(t, "a...[truncated 4 bytes]", Truncate("aéé", 2))
+}
+
+func TestWithMaxFieldBytes(t *testing.T) {
+	recorder := newRecordingLogger()
+	assert.Same(t, Logger(recorder), WithMaxFieldBytes(recorder, 0))
+
+	log := WithMaxFieldBytes(recorder, 8)
+	log = log.WithField("prompt", strings.Repeat("x", 100)).
+		WithField("tokens", 100).
+		WithError(errors.New(strings.Repeat("e", 20)))
+	log.Info("done", F("body", []byte(strings.Repeat("y", 10))), F("short", "ok"))
+
+	assert.Equal(t, "xxxxxxxx...[truncated 92 bytes]", recorder.fields["prompt"])
+	assert.Equal(t, 100, recorder.fields["tokens"])
+	assert.Equal(t, "eeeeeeee...[truncated 12 bytes]", recorder.fields["error"])
+	assert.Equal(t, "yyyyyyyy...[truncated 2 bytes]", recorder.fields["body"])
+	assert.Equal(t, "ok", recorder.fields["short"])
+
+	// Wrapping again replaces the limit rather than stacking
+	rewrapped := WithMaxFieldBytes(log, 4).(*truncatingLogger)
+	assert.Equal(t, 4, rewrapped.maxBytes)
+	assert.Same(t, Logger(recorder), rewrapped.Logger)
+}
@@ -0,0 +1,63 @@
+package version
+
+import (
+	"runtime/debug"
+	"strings"
+)
+
+// modulePath is the module whose version the build reports
+const modulePath = "github.com/quantum-suite/platform"
+
+// Version is the release the binary was built from. Release builds stamp it
+// with -ldflags "-X github.com/quantum-suite/platform/pkg/shared/version.Version=<version>";
+// otherwise it is read from the Go build info.
+var Version = ""
+
+// userAgentVersionPlaceholder is replaced with the version in configured
+// user agents, e.g. "AcmeProxy QLens/{version}"
+const userAgentVersionPlaceholder = "{version}"
+
+// Current returns the version the binary was built from: the stamped
+// Version, else the module version recorded in the build info, whether the
+// platform is the main module or a dependency of an application using the
+// SDK. Builds from a source tree report "dev".
+func Current() string {
+	if Version != "" {
+		return Version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "dev"
+	}
+	return fromBuildInfo(info)
+}
+
+// fromBuildInfo finds the platform module's version in the build info
+func fromBuildInfo(info *debug.BuildInfo) string {
+	module := &info.Main
+	if module.Path != modulePath {
+		module = nil
+		for _, dep := range info.Deps {
+			if dep.Path == modulePath {
+				module = dep
+				if dep.Replace != nil {
+					module = dep.Replace
+				}
+				break
+			}
+		}
+	}
+	if module == nil || module.Version == "" || module.Version == "(devel)" {
+		return "dev"
+	}
+	return strings.TrimPrefix(module.Version, "v")
+}
+
+// UserAgent returns the configured user agent with any {version} placeholder
+// filled in, or QLens/<version> when none is configured
+func UserAgent(configured string) string {
+	if configured == "" {
+		return "QLens/" + Current()
+	}
+	return strings.ReplaceAll(configured, userAgentVersionPlaceholder, Current())
+}
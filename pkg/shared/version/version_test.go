@@ -0,0 +1,37 @@
+package version
+
+import (
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserAgent(t *testing.T) {
+	original := Version
+	Version = "2.3.1"
+	defer func() { Version = original }()
+
+	assert.Equal(t, "QLens/2.3.1", UserAgent(""))
+	assert.Equal(t, "AcmeProxy QLens/2.3.1", UserAgent("AcmeProxy QLens/{version}"))
+	assert.Equal(t, "custom-agent", UserAgent("custom-agent"))
+}
+
+func TestFromBuildInfo(t *testing.T) {
+	// The platform built as the main module
+	assert.Equal(t, "1.4.0", fromBuildInfo(&debug.BuildInfo{
+		Main: debug.Module{Path: modulePath, Version: "v1.4.0"},
+	}))
+	assert.Equal(t, "dev", fromBuildInfo(&debug.BuildInfo{
+		Main: debug.Module{Path: modulePath, Version: "(devel)"},
+	}))
+
+	// An application using the SDK reports the version it depends on
+	assert.Equal(t, "1.2.0", fromBuildInfo(&debug.BuildInfo{
+		Main: debug.Module{Path: "example.com/app"},
+		Deps: []*debug.Module{{Path: "golang.org/x/net", Version: "v0.1.0"}, {Path: modulePath, Version: "v1.2.0"}},
+	}))
+	assert.Equal(t, "dev", fromBuildInfo(&debug.BuildInfo{
+		Main: debug.Module{Path: "example.com/app"},
+	}))
+}